@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <dir>",
+	Short: "Recover from a bare backup directory by rebuilding its index and config",
+	Long: `Take over an existing vault copied from another machine when nothing but the
+backup files survived.
+
+This command will:
+• Check the vault header and refuse vaults from incompatible versions
+• Rebuild index.json from the nft_data.json files
+• Infer the wallets and collections the vault protects
+• Write a fresh .env pointing at the vault, using the cluster it was created for
+
+Example:
+  solvault adopt /mnt/usb/SolVaultBackups
+  solvault adopt ./restored-vault --wallet <address> --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdopt,
+}
+
+var (
+	adoptWallet string
+	adoptForce  bool
+)
+
+// adoptReport summarizes what adopt found in the vault
+type adoptReport struct {
+	Dir         string         `json:"dir"`
+	NFTs        int            `json:"nfts"`
+	Wallets     []walletCount  `json:"wallets"`
+	Collections map[string]int `json:"collections"`
+	Cluster     string         `json:"cluster"`
+	ConfigFile  string         `json:"config_file"`
+}
+
+// walletCount is a wallet and the number of NFTs backed up for it
+type walletCount struct {
+	Wallet string `json:"wallet"`
+	NFTs   int    `json:"nfts"`
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+	}
+	fmt.Printf("🔎 Inspecting backup directory: %s\n", dir)
+
+	if _, err := os.Stat(filepath.Join(dir, "wallets")); err != nil {
+		return fmt.Errorf("no SolVault backup found in %s (missing wallets/ directory)", dir)
+	}
+	if err := storage.CheckVault(dir); err != nil {
+		return err
+	}
+
+	// Refuse before doing any work rather than silently keep the old config
+	if _, err := os.Stat(".env"); err == nil && !adoptForce {
+		return fmt.Errorf(".env already exists in this directory; use --force to overwrite it")
+	}
+
+	vault, err := storage.NewFileStorage(dir)
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	fmt.Printf("🗂️  Rebuilding index...\n")
+	count, err := vault.RebuildIndex(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to rebuild index: %w", err)
+	}
+	fmt.Printf("✅ Indexed %d NFT record(s)\n", count)
+
+	report := &adoptReport{
+		Dir:         dir,
+		NFTs:        count,
+		Collections: make(map[string]int),
+		Cluster:     vault.Header().Cluster,
+		ConfigFile:  ".env",
+	}
+	report.Wallets = inferWallets(vault)
+	for _, entry := range vault.Index().List() {
+		if entry.PrimaryWallet != "" {
+			continue // Linked custody records share the primary's backup
+		}
+		wallet, err1 := solanago.PublicKeyFromBase58(entry.Wallet)
+		mint, err2 := solanago.PublicKeyFromBase58(entry.Mint)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stored, err := vault.GetNFT(cmd.Context(), wallet, mint)
+		if err != nil || stored.NFTInfo == nil || stored.NFTInfo.Metadata == nil {
+			continue
+		}
+		if name := stored.NFTInfo.Metadata.Collection.Name; name != "" {
+			report.Collections[name]++
+		}
+	}
+
+	// Pick the primary wallet: explicit flag, otherwise the busiest wallet
+	primary := adoptWallet
+	var extra []string
+	for _, w := range report.Wallets {
+		if primary == "" {
+			primary = w.Wallet
+		} else if w.Wallet != primary {
+			extra = append(extra, w.Wallet)
+		}
+	}
+	if primary == "" {
+		return fmt.Errorf("could not infer any wallet from %s; pass --wallet <address>", dir)
+	}
+	if _, err := solanago.PublicKeyFromBase58(primary); err != nil {
+		return fmt.Errorf("invalid wallet address %s: %w", primary, err)
+	}
+
+	cluster := report.Cluster
+	if cluster == "" {
+		cluster = "mainnet-beta"
+		fmt.Printf("⚠️  Vault does not record its cluster; assuming %s\n", cluster)
+		if err := vault.RecordCluster(cluster); err != nil {
+			return err
+		}
+		report.Cluster = cluster
+	}
+	rpcURL, wsURL := solana.ClusterEndpoints(cluster)
+
+	if err := createEnvFile(envSettings{
+		Wallet:       primary,
+		ExtraWallets: extra,
+		BackupDir:    dir,
+		RPCURL:       rpcURL,
+		WebSocketURL: wsURL,
+		Force:        adoptForce,
+	}); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(report)
+	}
+	printAdoptReport(report, primary)
+	return nil
+}
+
+// inferWallets counts the NFTs recorded for each wallet, busiest first.
+// Wallets that only appear in custody history are included too.
+func inferWallets(vault *storage.FileStorage) []walletCount {
+	counts := make(map[string]int)
+	for _, entry := range vault.Index().List() {
+		counts[entry.Wallet]++
+	}
+
+	wallets := make([]walletCount, 0, len(counts))
+	for wallet, n := range counts {
+		wallets = append(wallets, walletCount{Wallet: wallet, NFTs: n})
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		if wallets[i].NFTs != wallets[j].NFTs {
+			return wallets[i].NFTs > wallets[j].NFTs
+		}
+		return wallets[i].Wallet < wallets[j].Wallet
+	})
+	return wallets
+}
+
+// printAdoptReport shows what was recovered and what to run next
+func printAdoptReport(report *adoptReport, primary string) {
+	fmt.Printf("\n👛 Wallets\n")
+	for _, w := range report.Wallets {
+		marker := "  "
+		if w.Wallet == primary {
+			marker = "⭐"
+		}
+		fmt.Printf("%s %s  %d NFT(s)\n", marker, w.Wallet, w.NFTs)
+	}
+
+	if len(report.Collections) > 0 {
+		names := make([]string, 0, len(report.Collections))
+		for name := range report.Collections {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if report.Collections[names[i]] != report.Collections[names[j]] {
+				return report.Collections[names[i]] > report.Collections[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		fmt.Printf("\n📚 Collections\n")
+		for _, name := range names {
+			fmt.Printf("   %-40s %d\n", truncateString(name, 40), report.Collections[name])
+		}
+	}
+
+	fmt.Printf("\n✅ Adopted vault %s (%s)\n", report.Dir, report.Cluster)
+	fmt.Printf("   Configuration: %s\n", report.ConfigFile)
+	fmt.Println("")
+	fmt.Println("Next steps:")
+	fmt.Println("  solvault list         # browse the recovered backups")
+	fmt.Println("  solvault verify --all # check media integrity")
+	fmt.Println("  solvault sync         # catch up with the chain")
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().StringVar(&adoptWallet, "wallet", "", "primary wallet to configure (default: the wallet with the most NFTs)")
+	adoptCmd.Flags().BoolVar(&adoptForce, "force", false, "overwrite an existing .env file")
+}
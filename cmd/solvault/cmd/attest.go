@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// attestCmd represents the attest command
+var attestCmd = &cobra.Command{
+	Use:   "attest <mint-address>",
+	Short: "Co-sign a proof for an NFT and send it to another vault",
+	Long: `Hash your backed-up copy of an NFT, sign the result with your Solana
+keypair and post it to another SolVault's serve endpoint, where it is
+attached to that NFT's proof chain.
+
+Example:
+  solvault attest 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU --endpoint https://vault.example.com
+  solvault attest <mint> --endpoint http://127.0.0.1:8787 --keypair ./verifier.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAttest,
+}
+
+var (
+	attestEndpoint string
+	attestKeypair  string
+)
+
+func runAttest(cmd *cobra.Command, args []string) error {
+	mint, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid mint address: %w", err)
+	}
+	if attestEndpoint == "" {
+		return fmt.Errorf("--endpoint is required")
+	}
+
+	key, err := loadKeypair(attestKeypair)
+	if err != nil {
+		return err
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	fmt.Printf("🔐 Hashing local copy of %s...\n", mint.String())
+	att, err := proof.FromVault(ctx, vault, mint)
+	if err != nil {
+		return err
+	}
+	if err := att.Sign(key); err != nil {
+		return err
+	}
+
+	fmt.Printf("📤 Sending attestation to %s...\n", attestEndpoint)
+	entry, err := proof.Submit(ctx, attestEndpoint, att)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(entry)
+	}
+
+	fmt.Println("✅ Attestation accepted")
+	fmt.Printf("   Verifier:      %s\n", att.Verifier)
+	fmt.Printf("   Media hash:    %s\n", att.MediaHash)
+	fmt.Printf("   Chain entry:   %s\n", entry.Hash)
+	if entry.MatchesLocal {
+		fmt.Println("   Media match:   ✅ matches the receiving vault's copy")
+	} else {
+		fmt.Println("   Media match:   ⚠️  differs from the receiving vault's copy")
+	}
+	return nil
+}
+
+// loadKeypair reads a solana-keygen JSON keypair, defaulting to the Solana CLI's
+func loadKeypair(path string) (solanago.PrivateKey, error) {
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, ".config", "solana", "id.json")
+	}
+
+	key, err := solanago.PrivateKeyFromSolanaKeygenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keypair %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func init() {
+	rootCmd.AddCommand(attestCmd)
+
+	attestCmd.Flags().StringVar(&attestEndpoint, "endpoint", "", "base URL of the receiving vault's 'solvault serve'")
+	attestCmd.Flags().StringVar(&attestKeypair, "keypair", envOrDefault("SOLVAULT_KEYPAIR", ""), "signing keypair file (default ~/.config/solana/id.json)")
+}
@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -19,42 +17,61 @@ This command will:
 • Fetch collections and NFTs
 • Let you select which NFT to back up
 • Initiate the backup workflow
+
+Every configured wallet is backed up unless --wallet selects one. With
+--dry-run nothing is written.
+
+Example:
+  solvault backup
+  solvault backup --wallet 5QfQ...ZsLk
 `,
 	RunE: runBackup,
 }
 
+var backupWallet string
+
 func runBackup(cmd *cobra.Command, args []string) error {
-	// Read wallet address from .env credential cache
-	envPath := ".env"
-	data, err := os.ReadFile(envPath)
+	wallets, err := selectWallets(backupWallet)
 	if err != nil {
-		fmt.Println("❌ Could not read .env file. Please run 'solvault init' first.")
+		fmt.Printf("❌ %v\n", err)
 		return nil
 	}
-	lines := strings.Split(string(data), "\n")
-	var walletAddr string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "WALLET_ADDRESS=") {
-			walletAddr = strings.TrimPrefix(line, "WALLET_ADDRESS=")
-			walletAddr = strings.TrimSpace(walletAddr)
-			break
-		}
-	}
-	if walletAddr == "" {
-		fmt.Println("❌ Wallet address not found in .env. Please run 'solvault init' and enter your wallet address.")
-		return nil
+
+	results := make([]map[string]interface{}, 0, len(wallets))
+	for _, wallet := range wallets {
+		// TODO: Fetch collections for wallet
+		fmt.Printf("Fetching collections for wallet %s...\n", wallet.String())
+		// collections := fetchCollections(wallet)
+		// TODO: Fetch NFTs in collection
+		// TODO: Initiate backup workflow
+
+		results = append(results, map[string]interface{}{
+			"wallet":    wallet.String(),
+			"backed_up": []string{},
+			"status":    "not_implemented",
+			"dry_run":   dryRunMode(),
+		})
 	}
 
-	// TODO: Fetch collections for walletAddr
-	fmt.Printf("Fetching collections for wallet %s...\n", walletAddr)
-	// collections := fetchCollections(walletAddr)
-	// TODO: Fetch NFTs in collection
-	// TODO: Initiate backup workflow
+	// The stub writes nothing yet; 'solvault sync --dry-run' plans real backups
+	if dryRunMode() {
+		fmt.Println("🧪 Dry run: nothing would be written. Use 'solvault sync --dry-run' to see what a backup would fetch and write.")
+	}
 
 	fmt.Println("✅ (Stub) Backup command initialized. Next: integrate collection/NFT selection and backup logic.")
+
+	if jsonOutput() {
+		if len(results) == 1 {
+			return printJSON(results[0])
+		}
+		return printJSON(results)
+	}
 	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(backupCmd)
+	supportDryRun(backupCmd)
+
+	backupCmd.Flags().StringVar(&backupWallet, "wallet", "", "only back up this wallet (default: all configured wallets)")
 }
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/linkcheck"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// checkLinksCmd probes the URIs backed-up NFTs point at
+var checkLinksCmd = &cobra.Command{
+	Use:   "check-links",
+	Short: "Report which metadata and media URIs of backed-up NFTs are dead or changed",
+	Long: `Probe every metadata and media URI of the backed-up NFTs and report which
+are dead, redirected or serving something of a different size than the
+backup holds: the assets that now survive only in this vault.
+
+Each URI is requested once with HEAD (a one-byte GET for hosts that refuse
+HEAD), no more than METADATA_RATE times a second per host. ipfs:// and ar://
+URIs are asked of the first IPFS_GATEWAYS or ARWEAVE_GATEWAYS gateway, and
+FETCH_PROXY and the other FETCH_* settings apply as for backups.
+
+  dead          404 or 410, or the host no longer exists
+  changed       served with a different size than the backed-up copy
+  redirected    served from another URL
+  unreachable   timeouts, server errors and rate limits; try again later
+
+The report is also written to <vault>/link_report.json (--report). The
+command fails when any link is dead, so it can run from cron.
+
+Example:
+  solvault check-links
+  solvault check-links --collection "Mad Lads" --rate 2
+  solvault check-links --output json`,
+	Args: cobra.NoArgs,
+	RunE: runCheckLinks,
+}
+
+var (
+	checkLinksWallet     string
+	checkLinksCollection string
+	checkLinksRate       float64
+	checkLinksReport     string
+)
+
+func runCheckLinks(cmd *cobra.Command, args []string) error {
+	solana.LoadEnvFiles()
+	var wallet string
+	if checkLinksWallet != "" {
+		wallets, err := selectWallets(checkLinksWallet)
+		if err != nil {
+			return err
+		}
+		wallet = wallets[0].String()
+	}
+	httpOpts, err := fetcher.HTTPOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	rate := float64(fetcher.DefaultMetadataRate)
+	if value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("METADATA_RATE"))); err == nil && value >= 0 {
+		rate = float64(value)
+	}
+	if cmd.Flags().Changed("rate") {
+		rate = checkLinksRate
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	var entries []*storage.IndexEntry
+	for _, entry := range vault.Index().List() {
+		if wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		if checkLinksCollection != "" {
+			var stored storage.StoredNFT
+			data, err := vault.ReadFile(filepath.Join(vault.EntryDir(entry), "nft_data.json"))
+			if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil || !inCollection(&stored, checkLinksCollection) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	links, nfts := linkcheck.Links(vault, entries)
+	if len(links) == 0 {
+		fmt.Println("📭 No NFTs matched; no links to check")
+		return nil
+	}
+
+	opts := linkcheck.Options{
+		Client:            fetcher.NewHTTPClient(httpOpts, fetcher.DefaultMetadataTimeout),
+		RequestsPerSecond: rate,
+	}
+	if !jsonOutput() {
+		fmt.Printf("🔗 Checking %d link(s) of %d NFT(s)...\n", len(links), nfts)
+		opts.Progress = func(done, total int) {
+			if done%50 == 0 || done == total {
+				fmt.Printf("   %d/%d URL(s) probed\n", done, total)
+			}
+		}
+	}
+	ctx := cmd.Context()
+	report := linkcheck.Check(ctx, links, opts)
+	report.NFTs = nfts
+	linkcheck.Sort(report.Links)
+
+	reportPath := checkLinksReport
+	if reportPath == "" {
+		backupDir, err := getBackupDirectory()
+		if err != nil {
+			return err
+		}
+		reportPath = filepath.Join(backupDir, "link_report.json")
+	}
+	if err := writeLinkReport(reportPath, report); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printLinkReport(report, reportPath)
+	}
+
+	cmd.SilenceUsage = true
+	if ctx.Err() != nil {
+		return fmt.Errorf("link check interrupted; %d link(s) were not checked", report.Count(""))
+	}
+	if dead := report.Count(linkcheck.StatusDead); dead > 0 {
+		return fmt.Errorf("%d link(s) are dead", dead)
+	}
+	return nil
+}
+
+func writeLinkReport(path string, report *linkcheck.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal link report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write link report: %w", err)
+	}
+	return nil
+}
+
+// printLinkReport lists every link that is not ok, worst first, and a
+// summary
+func printLinkReport(report *linkcheck.Report, path string) {
+	fmt.Println()
+	for _, link := range report.Links {
+		switch {
+		case link.Lost():
+			fmt.Printf("💀 %s: dead and not backed up%s\n", link, linkDetail(link))
+		case link.Status == linkcheck.StatusDead:
+			fmt.Printf("🛟 %s: dead, survives only in this vault%s\n", link, linkDetail(link))
+		case link.Status == linkcheck.StatusChanged:
+			fmt.Printf("✏️  %s: now %s, backed up at %s\n", link, formatBytes(link.RemoteSize), formatBytes(link.Size))
+		case link.Status == linkcheck.StatusUnreachable:
+			fmt.Printf("⚠️  %s: unreachable%s\n", link, linkDetail(link))
+		case link.Status == linkcheck.StatusRedirected:
+			fmt.Printf("↪️  %s: redirected to %s\n", link, link.FinalURL)
+		}
+	}
+
+	fmt.Printf("\n📊 %d link(s) of %d NFT(s): %d ok, %d redirected, %d changed, %d unreachable, %d dead\n",
+		len(report.Links), report.NFTs, report.Count(linkcheck.StatusOK), report.Count(linkcheck.StatusRedirected),
+		report.Count(linkcheck.StatusChanged), report.Count(linkcheck.StatusUnreachable), report.Count(linkcheck.StatusDead))
+	if dead := report.Count(linkcheck.StatusDead); dead > 0 {
+		fmt.Printf("🛟 %d dead link(s); URI_REWRITES can point future backups at a mirror if one exists\n", dead)
+	}
+	fmt.Printf("📄 Report written to %s\n", path)
+}
+
+// linkDetail is the HTTP status or error of a failed probe
+func linkDetail(link *linkcheck.Link) string {
+	switch {
+	case link.Error != "":
+		return " (" + link.Error + ")"
+	case link.HTTPStatus != 0:
+		return fmt.Sprintf(" (HTTP %d)", link.HTTPStatus)
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(checkLinksCmd)
+	checkLinksCmd.Flags().StringVar(&checkLinksWallet, "wallet", "", "only check NFTs backed up for this wallet")
+	checkLinksCmd.Flags().StringVar(&checkLinksCollection, "collection", "", "only check NFTs in this collection")
+	checkLinksCmd.Flags().Float64Var(&checkLinksRate, "rate", 0, "requests per second to one host, 0 for no limit (default METADATA_RATE)")
+	checkLinksCmd.Flags().StringVar(&checkLinksReport, "report", "", "report path (default <vault>/link_report.json)")
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/NazWright/solvault/internal/progress"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// collectionCmd represents the collection command
+var collectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Archive whole NFT collections",
+	Long: `Archive every NFT in a collection, not only the ones your wallets hold.
+
+Example:
+  solvault collection snapshot J1S9H3QjnRtBbbuD4HjPV6RpRhwuk4zKbxsnCHuTgh9w`,
+}
+
+var collectionSnapshotCmd = &cobra.Command{
+	Use:   "snapshot <collection-mint>",
+	Short: "Back up the metadata and media of every NFT in a collection",
+	Long: `Enumerate every NFT in a verified collection with the DAS getAssetsByGroup
+API and back up its metadata and media, whoever holds it. This is meant for
+creators archiving their own project, or anyone preserving a collection.
+
+The archive is a vault of its own under collections/<collection-mint>/ in
+your vault, so it never mixes with your wallets' backups. Browse it with
+--vault, e.g. 'solvault --vault <vault>/collections/<mint> list'.
+
+Like 'sync --das', the scan is checkpointed after every page: a run limited
+with --das-max-pages, interrupted, or stopped by rate limits resumes on the next
+run, and later runs only re-back up NFTs whose metadata changed. Only NFTs
+verified as members of the collection are listed; compressed NFTs are
+skipped. Once the scan completes, NFTs no longer in the collection are
+marked burned or transferred, and rarity ranks are saved with each backup.
+Every run also saves who holds each NFT to snapshots/<collection-mint>/ in
+the archive.
+
+To back up new mints as they happen, use 'solvault watch --collection' or
+'solvault watch --candy-machine'.
+
+A DAS-enabled RPC is required: --das-url, DAS_RPC_URL, or NFT_PROVIDER.
+
+Example:
+  solvault collection snapshot J1S9H3QjnRtBbbuD4HjPV6RpRhwuk4zKbxsnCHuTgh9w
+  solvault collection snapshot J1S9...gh9w --das-max-pages 5 --das-rate 2
+  solvault collection snapshot J1S9...gh9w --new-only --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCollectionSnapshot,
+}
+
+var (
+	collectionDASURL   string
+	collectionDASRate  float64
+	collectionMaxPages int
+	collectionReset    bool
+	collectionNewOnly  bool
+)
+
+func runCollectionSnapshot(cmd *cobra.Command, args []string) error {
+	collection, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid collection mint %q: %w", args[0], err)
+	}
+
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	endpoint, err := dasEndpoint(collectionDASURL, config.RPCURL)
+	if err != nil {
+		return err
+	}
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	chainSource := backup.NewChainSource(client)
+	defer chainSource.Close()
+
+	vault, err := openCollectionVault(collection)
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(chainSource, vault); err != nil {
+		return err
+	}
+
+	stateDir := filepath.Join(vault.BaseDir(), ".checkpoints", "das")
+	if dryRunMode() {
+		// Pages go to a scratch directory so the saved checkpoints are untouched
+		if stateDir, err = os.MkdirTemp("", "solvault-das-*"); err != nil {
+			return fmt.Errorf("failed to create scratch directory: %w", err)
+		}
+		defer os.RemoveAll(stateDir)
+	}
+	enumerator := das.NewCollectionEnumerator(das.NewClient(endpoint, collectionDASRate), stateDir)
+	if collectionReset {
+		if err := enumerator.Reset(collection.String()); err != nil {
+			return err
+		}
+	}
+	if cp, err := enumerator.Status(collection.String()); err == nil && cp.Pages > 0 && !cp.Complete {
+		fmt.Printf("⏯️  Resuming the collection scan at page %d (%d assets so far)\n", cp.Pages+1, cp.Assets)
+	}
+	source := backup.NewCollectionSource(chainSource, enumerator, das.Options{
+		MaxPages: collectionMaxPages,
+		Progress: func(cp das.Checkpoint) {
+			if cp.Total > 0 {
+				fmt.Printf("📄 Page %d: %d/%d NFTs\n", cp.Pages, cp.Assets, cp.Total)
+			} else {
+				fmt.Printf("📄 Page %d: %d NFTs\n", cp.Pages, cp.Assets)
+			}
+		},
+	})
+
+	ctx := cmd.Context()
+
+	fmt.Printf("📚 Archiving collection %s into %s\n", collection.String(), vault.BaseDir())
+	opts := backup.Options{
+		DryRun:     dryRunMode(),
+		NewOnly:    collectionNewOnly,
+		Thumbnails: thumbnail.SizesFromEnv(),
+	}
+	bar := progress.New("📚 "+truncateString(collection.String(), 12), 0)
+	opts.Progress = func(msg string) { bar.Println("%s", msg) }
+	opts.Advance = bar.Set
+	summary, err := backup.Sync(ctx, vault, source, collection, opts)
+	bar.Finish()
+	if err != nil {
+		return fmt.Errorf("snapshot failed for collection %s: %w", collection.String(), err)
+	}
+	if !jsonOutput() {
+		printSyncSummary(summary)
+	}
+	if !dryRunMode() {
+		snapshot := backup.NewHolderSnapshot(collection, source.Holders(), !summary.Partial)
+		if path, err := backup.SaveHolderSnapshot(vault, snapshot); err != nil {
+			fmt.Printf("⚠️  Failed to save the holder snapshot: %v\n", err)
+		} else if !jsonOutput() {
+			fmt.Printf("👥 Holders: %d NFT(s) across %d wallet(s), saved to %s\n", len(snapshot.Holders), snapshot.Owners, path)
+		}
+	}
+
+	// A complete collection is the best sample rarity can be ranked over
+	if !dryRunMode() && backupsChanged([]*backup.Summary{summary}) {
+		if ranked, err := analytics.RecordRarity(ctx, vault, raritySample()); err != nil {
+			fmt.Printf("⚠️  Failed to update rarity: %v\n", err)
+		} else if ranked > 0 && !jsonOutput() {
+			fmt.Printf("💎 Rarity updated on %d backup(s)\n", ranked)
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(summary)
+	}
+	return nil
+}
+
+// openCollectionVault opens the vault a collection is archived in, under
+// collections/<collection-mint>/ in the backup directory
+func openCollectionVault(collection solanago.PublicKey) (*storage.FileStorage, error) {
+	if usingMemoryVault() {
+		return memoryVault()
+	}
+	backupDir, err := getBackupDirectory()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(backupDir, backup.CollectionsDir, collection.String())
+	// A dry run must not create a vault that does not exist yet
+	if _, err := os.Stat(dir); dryRunMode() && os.IsNotExist(err) {
+		fmt.Printf("🧪 No archive of this collection yet; planning against an empty one\n")
+		return memoryVault()
+	}
+	return storage.NewFileStorage(dir)
+}
+
+// collectionWatch is a collection whose new mints the watcher backs up
+type collectionWatch struct {
+	collection   solanago.PublicKey
+	candyMachine *solanago.PublicKey // Minting into the collection; nil when watched by collection
+	redeemed     uint64              // Candy machine mints at the last scan
+	scanned      bool
+	vault        *storage.FileStorage
+	source       *backup.CollectionSource
+	holders      []backup.Holder // Holders at the last snapshot
+}
+
+// newCollectionWatches sets up creator mode for the given collection mints
+// and candy machines, each archived in its collection's vault
+func newCollectionWatches(ctx context.Context, client *solana.Client, chain *backup.ChainSource, rpcURL string, collections, candyMachines []string) ([]*collectionWatch, error) {
+	endpoint, err := dasEndpoint("", rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	dasClient := das.NewClient(endpoint, 5)
+
+	var watches []*collectionWatch
+	seen := make(map[string]bool)
+	add := func(collection solanago.PublicKey, candyMachine *solanago.PublicKey) error {
+		if seen[collection.String()] {
+			return nil
+		}
+		seen[collection.String()] = true
+		vault, err := openCollectionVault(collection)
+		if err != nil {
+			return err
+		}
+		stateDir := filepath.Join(vault.BaseDir(), ".checkpoints", "das")
+		watches = append(watches, &collectionWatch{
+			collection:   collection,
+			candyMachine: candyMachine,
+			vault:        vault,
+			source:       backup.NewCollectionSource(chain, das.NewCollectionEnumerator(dasClient, stateDir), das.Options{}),
+		})
+		return nil
+	}
+
+	for _, id := range candyMachines {
+		candyMachine, err := solanago.PublicKeyFromBase58(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid candy machine %q: %w", id, err)
+		}
+		cm, err := readCandyMachine(ctx, client, candyMachine)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(cm.CollectionMint, &candyMachine); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range collections {
+		collection, err := solanago.PublicKeyFromBase58(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection mint %q: %w", id, err)
+		}
+		if err := add(collection, nil); err != nil {
+			return nil, err
+		}
+	}
+	return watches, nil
+}
+
+// readCandyMachine loads and decodes a Candy Machine Core account
+func readCandyMachine(ctx context.Context, client *solana.Client, id solanago.PublicKey) (*fetcher.CandyMachine, error) {
+	account, err := client.GetAccountInfo(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read candy machine %s: %w", id.String(), err)
+	}
+	cm, err := fetcher.ParseCandyMachine(account.Owner, account.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("candy machine %s: %w", id.String(), err)
+	}
+	return cm, nil
+}
+
+// checkCollection backs up NFTs new to a watched collection and snapshots
+// its holders when they changed
+//
+// Explanation: Listing a collection costs a DAS page per thousand NFTs, so
+// a candy machine's items-redeemed counter, one account read, is checked
+// first and the collection is only listed once it moves. A collection
+// watched by its mint alone is listed on every tick.
+func checkCollection(ctx context.Context, watch *collectionWatch, client *solana.Client, events *journal.Journal) error {
+	fmt.Printf("⏰ [%s] Checking collection %s for new mints...\n", time.Now().Format("15:04:05"), watch.collection.String())
+
+	var redeemed uint64
+	if watch.candyMachine != nil {
+		cm, err := readCandyMachine(ctx, client, *watch.candyMachine)
+		if err != nil {
+			return err
+		}
+		if watch.scanned && cm.ItemsRedeemed == watch.redeemed {
+			return nil
+		}
+		if watch.scanned {
+			fmt.Printf("🎰 %d new mint(s) from the candy machine (%d of %d minted)\n", cm.ItemsRedeemed-watch.redeemed, cm.ItemsRedeemed, cm.ItemsAvailable)
+		}
+		redeemed = cm.ItemsRedeemed
+	}
+
+	summary, err := backup.Sync(ctx, watch.vault, watch.source, watch.collection, backup.Options{
+		NewOnly:    true,
+		Thumbnails: thumbnail.SizesFromEnv(),
+		Journal:    events,
+		Progress:   func(msg string) { fmt.Println(msg) },
+	})
+	if err != nil {
+		return err
+	}
+	for _, change := range summary.Changes {
+		if change.Kind == backup.ChangeFailed {
+			fmt.Printf("⚠️  %s: %s\n", change.Mint, change.Error)
+		}
+	}
+	if summary.Partial {
+		return nil // Holders and the redeemed count wait for a complete listing
+	}
+	watch.scanned, watch.redeemed = true, redeemed
+
+	if holders := watch.source.Holders(); !backup.SameHolders(holders, watch.holders) {
+		snapshot := backup.NewHolderSnapshot(watch.collection, holders, true)
+		path, err := backup.SaveHolderSnapshot(watch.vault, snapshot)
+		if err != nil {
+			return err
+		}
+		watch.holders = holders
+		fmt.Printf("👥 Holders: %d NFT(s) across %d wallet(s), saved to %s\n", len(snapshot.Holders), snapshot.Owners, path)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(collectionCmd)
+	collectionCmd.AddCommand(collectionSnapshotCmd)
+	supportDryRun(collectionSnapshotCmd)
+
+	collectionSnapshotCmd.Flags().StringVar(&collectionDASURL, "das-url", "", "DAS-enabled RPC URL (default $DAS_RPC_URL, then NFT_PROVIDER's DAS API, then SOLANA_RPC_URL)")
+	collectionSnapshotCmd.Flags().Float64Var(&collectionDASRate, "das-rate", 5, "maximum DAS requests per second")
+	collectionSnapshotCmd.Flags().IntVar(&collectionMaxPages, "das-max-pages", 0, "stop after this many pages and resume next run (0 = no limit)")
+	collectionSnapshotCmd.Flags().BoolVar(&collectionReset, "das-reset", false, "discard the saved scan and start from the first page")
+	collectionSnapshotCmd.Flags().BoolVar(&collectionNewOnly, "new-only", false, "skip change detection for NFTs already archived")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate the shell completion script",
+	Long: `Print a script that completes SolVault's commands, flags and flag values
+in your shell. Load it once to try it, or install it to have it in every new
+shell.
+
+Bash (needs the bash-completion package):
+  source <(solvault completion bash)
+  solvault completion bash > /etc/bash_completion.d/solvault            # Linux
+  solvault completion bash > $(brew --prefix)/etc/bash_completion.d/solvault  # macOS
+
+Zsh:
+  echo "autoload -U compinit; compinit" >> ~/.zshrc   # if not enabled yet
+  solvault completion zsh > "${fpath[1]}/_solvault"
+
+Fish:
+  solvault completion fish > ~/.config/fish/completions/solvault.fish
+
+PowerShell:
+  solvault completion powershell | Out-String | Invoke-Expression
+  # add that line to your $PROFILE to load it in every session`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	// Explanation: Generating a script must work before 'solvault init' and
+	// with a broken config, so skip the root's config and vault checks
+	PersistentPreRunE: setupOutput,
+	RunE:              runCompletion,
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	root := cmd.Root()
+	var err error
+	switch args[0] {
+	case "bash":
+		err = root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = root.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = root.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		err = root.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate %s completion: %w", args[0], err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true // Replaced by completionCmd
+	rootCmd.AddCommand(completionCmd)
+}
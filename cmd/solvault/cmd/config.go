@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configFile is the --config flag
+var configFile string
+
+var configShowAll bool
+
+// flagSettings are the persistent flags that override a setting
+var flagSettings = map[string]string{
+	"BACKUP_DIRECTORY": "vault",
+	"SOLVAULT_BACKEND": "backend",
+}
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change SolVault settings",
+	Long: `SolVault settings come from, in order of precedence:
+
+  1. command-line flags (--vault, --backend)
+  2. environment variables
+  3. ./.env in the current directory
+  4. the config file, ~/.solvault.yaml (--config or SOLVAULT_CONFIG to move it)
+  5. ~/.solvault.env
+  6. built-in defaults
+
+The config file holds the same settings as the environment, named in lower
+case, with lists as YAML sequences:
+
+  solana_rpc_url: https://api.mainnet-beta.solana.com
+  wallet_address: 9sdfe1xA3s...
+  wallet_addresses:
+    - 7Yk2...
+    - 3Fq9...
+  nft_provider: helius
+  helius_api_key: ...
+
+Example:
+  solvault config show
+  solvault config show --all
+  solvault config set nft_provider helius
+  solvault config set SOLANA_RPC_URL https://api.devnet.solana.com`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective settings and where each comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := config.Resolve()
+		if err != nil {
+			return err
+		}
+		for i, setting := range settings {
+			if flag := cmd.Flags().Lookup(flagSettings[setting.Env]); flag != nil && flag.Changed {
+				settings[i].Value, settings[i].Source = flag.Value.String(), "--"+flag.Name
+			}
+			settings[i].Value = setting.Redact(settings[i].Value)
+		}
+
+		if jsonOutput() {
+			return printJSON(struct {
+				File     string           `json:"file"`
+				Settings []config.Setting `json:"settings"`
+			}{config.Path(), settings})
+		}
+
+		fmt.Printf("⚙️  Config file: %s\n\n", config.Path())
+		shown := 0
+		for _, setting := range settings {
+			if setting.Source == "" && !configShowAll {
+				continue
+			}
+			shown++
+			value, source := setting.Value, setting.Source
+			if source == "" {
+				value, source = "-", "unset"
+			}
+			fmt.Printf("%-28s %-44s (%s)\n", setting.Name, truncateString(value, 44), source)
+			if configShowAll {
+				fmt.Printf("%-28s %s\n", "", setting.Description)
+			}
+		}
+		if shown == 0 {
+			fmt.Println("No settings configured. Run 'solvault init' or 'solvault config set'.")
+		}
+		if !configShowAll {
+			fmt.Printf("\n💡 Use --all to list every setting with its description\n")
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Write a setting to the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		key, ok := config.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown setting %q; see 'solvault config show --all'", args[0])
+		}
+		value := strings.TrimSpace(args[1])
+
+		path := config.Path()
+		if err := config.Set(path, key, value); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Set %s = %s in %s\n", key.Name, key.Redact(value), path)
+
+		shadowed, err := config.ShadowedBy(key)
+		if err != nil {
+			return err
+		}
+		if len(shadowed) > 0 {
+			fmt.Printf("⚠️  %s is also set in %s, which takes precedence\n", key.Env, strings.Join(shadowed, " and "))
+		}
+		return nil
+	},
+}
+
+// loadConfig selects the --config file and checks that every config source
+// parses, so a typo is reported once instead of silently ignored
+func loadConfig(cmd *cobra.Command, args []string) error {
+	config.SetPath(configFile)
+	if err := config.Load(); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configSetCmd)
+
+	configShowCmd.Flags().BoolVar(&configShowAll, "all", false, "list unset settings and descriptions too")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultBulkThreshold is how many items a command may delete or overwrite
+// before it has to show its plan and get confirmation
+const defaultBulkThreshold = 10
+
+// destructiveAnnotation marks commands guarded by guardDestructive
+const destructiveAnnotation = "solvault.destructive"
+
+// bulkPlan describes what a destructive command is about to do
+type bulkPlan struct {
+	Action string   // Verb shown in the plan and typed to confirm, e.g. "overwrite"
+	Items  []string // One line per backup that will be deleted or overwritten
+}
+
+// guardDestructive gives a command that can delete or overwrite backups in
+// bulk the shared --yes flag and opts it into the global --dry-run. Its RunE
+// must call confirmBulk with the plan before changing anything.
+//
+// Explanation: The plan can only be known once the command has done its
+// read-only work (scanning an archive, diffing the index), so the guard is a
+// flag set plus a check the command calls, rather than a pre-run hook.
+func guardDestructive(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[destructiveAnnotation] = "true"
+
+	cmd.Flags().Bool("yes", false, "apply bulk deletes or overwrites without asking")
+	supportDryRun(cmd)
+}
+
+// bulkThreshold reads SOLVAULT_CONFIRM_THRESHOLD, falling back to the default
+func bulkThreshold() int {
+	if value, err := strconv.Atoi(os.Getenv("SOLVAULT_CONFIRM_THRESHOLD")); err == nil && value >= 0 {
+		return value
+	}
+	return defaultBulkThreshold
+}
+
+// confirmBulk reports whether the command may carry out plan. Small plans go
+// ahead; larger ones are printed and need --yes or the typed phrase. Without
+// a terminal to ask, a large plan is treated as a dry run.
+func confirmBulk(cmd *cobra.Command, plan bulkPlan) bool {
+	if len(plan.Items) == 0 {
+		if dryRunMode() {
+			fmt.Printf("🧪 Dry run: nothing to %s\n", plan.Action)
+			return false
+		}
+		return true
+	}
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if !dryRunMode() && len(plan.Items) <= bulkThreshold() {
+		return true
+	}
+
+	printBulkPlan(plan)
+	if dryRunMode() {
+		fmt.Printf("🧪 Dry run: nothing was changed\n")
+		return false
+	}
+	if yes {
+		return true
+	}
+	if jsonOutput() || quietOutput() || !isTerminal(os.Stdin) {
+		fmt.Printf("🧪 Dry run: nothing was changed. Re-run with --yes to %s %d item(s)\n", plan.Action, len(plan.Items))
+		return false
+	}
+
+	phrase := fmt.Sprintf("%s %d", plan.Action, len(plan.Items))
+	fmt.Printf("Type '%s' to continue: ", phrase)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(line) != phrase {
+		fmt.Printf("🚫 Cancelled; nothing was changed\n")
+		return false
+	}
+	return true
+}
+
+// printBulkPlan lists the first items of a plan
+func printBulkPlan(plan bulkPlan) {
+	const shown = 10
+
+	fmt.Printf("\n⚠️  This will %s %d item(s):\n", plan.Action, len(plan.Items))
+	for i, item := range plan.Items {
+		if i == shown {
+			fmt.Printf("   … and %d more\n", len(plan.Items)-shown)
+			break
+		}
+		fmt.Printf("   • %s\n", item)
+	}
+	fmt.Println()
+}
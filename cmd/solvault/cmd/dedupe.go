@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// dedupeCmd represents the dedupe command
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge duplicate backups of NFTs held by several vault wallets",
+	Long: `Fold separate copies of the same mint stored under different wallets into a
+single record with a combined custody history.
+
+New backups are linked automatically; this command cleans up vaults written
+before custody linking existed. The earliest backup is kept and the other
+wallets link to it.
+
+Example:
+  solvault dedupe
+  solvault dedupe --dry-run`,
+	RunE: runDedupe,
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	if !confirmBulk(cmd, dedupePlan(vault.Index())) {
+		return nil
+	}
+
+	fmt.Printf("🔗 Merging duplicate wallet backups...\n")
+	merged, err := vault.MergeDuplicates(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to merge duplicates: %w", err)
+	}
+
+	if merged == 0 {
+		fmt.Printf("✅ No duplicate backups found\n")
+		return nil
+	}
+	fmt.Printf("✅ Merged %d duplicate backup(s) into shared custody records\n", merged)
+	return nil
+}
+
+// dedupePlan lists the duplicate copies merging would delete: every copy of
+// a mint beyond the one that is kept
+func dedupePlan(index *storage.Index) bulkPlan {
+	plan := bulkPlan{Action: "delete"}
+	copies := make(map[string][]*storage.IndexEntry)
+	for _, entry := range index.List() {
+		if entry.PrimaryWallet == "" {
+			copies[entry.Mint] = append(copies[entry.Mint], entry)
+		}
+	}
+
+	for _, entry := range index.List() {
+		found := copies[entry.Mint]
+		if len(found) < 2 || found[0] != entry {
+			continue
+		}
+		name := entry.Name
+		if name == "" {
+			name = entry.Mint
+		}
+		for range found[1:] {
+			plan.Items = append(plan.Items, fmt.Sprintf("%s (one of %d copies)", name, len(found)))
+		}
+	}
+	return plan
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	guardDestructive(dedupeCmd)
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <mint-address> [from-version] [to-version]",
+	Short: "Show how an NFT's metadata changed between backed-up versions",
+	Long: `Show what changed in an NFT's metadata between two versions in the vault.
+
+NFT metadata is mutable. Whenever sync notices that the metadata URI or the
+off-chain JSON changed, the backup gets a new version and the old metadata
+is kept as metadata.v<N>.json instead of being overwritten. metadata.json
+always holds the current version.
+
+Without versions the latest change is shown; with one version, that version
+is compared against the current one.
+
+Example:
+  solvault diff 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault diff 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU 1
+  solvault diff 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU 1 3 --output json`,
+	Args: cobra.RangeArgs(1, 3),
+	RunE: runDiff,
+}
+
+// fieldChange is one metadata field that differs between versions
+type fieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// metadataDiff is the result of comparing two versions
+type metadataDiff struct {
+	Mint     string        `json:"mint"`
+	From     int           `json:"from_version"`
+	To       int           `json:"to_version"`
+	Versions []int         `json:"versions"`
+	Changes  []fieldChange `json:"changes"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	mint, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid mint address %s: %w", args[0], err)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	entries := vault.Index().ByMint(mint.String())
+	if len(entries) == 0 {
+		return fmt.Errorf("NFT %s is not in the vault", mint.String())
+	}
+	wallet, err := solanago.PublicKeyFromBase58(entries[0].Wallet)
+	if err != nil {
+		return fmt.Errorf("invalid wallet address in index: %w", err)
+	}
+
+	stored, err := vault.GetNFT(cmd.Context(), wallet, mint)
+	if err != nil {
+		return err
+	}
+	versions := make(map[int]storage.MetadataVersion)
+	numbers := []int{}
+	for _, version := range stored.Versions {
+		versions[version.Version] = version
+		numbers = append(numbers, version.Version)
+	}
+
+	from, to := stored.Version-1, stored.Version
+	if len(args) > 1 {
+		if from, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+	}
+	if len(args) > 2 {
+		if to, err = strconv.Atoi(args[2]); err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[2], err)
+		}
+	}
+	if len(args) == 1 && len(stored.Versions) < 2 {
+		if jsonOutput() {
+			return printJSON(metadataDiff{Mint: mint.String(), From: to, To: to, Versions: numbers, Changes: []fieldChange{}})
+		}
+		fmt.Printf("ℹ️  %s has a single metadata version; nothing to compare\n", shortAddress(mint.String()))
+		return nil
+	}
+
+	before, err := vault.MetadataAtVersion(wallet, mint, from)
+	if err != nil {
+		return err
+	}
+	after, err := vault.MetadataAtVersion(wallet, mint, to)
+	if err != nil {
+		return err
+	}
+
+	result := metadataDiff{Mint: mint.String(), From: from, To: to, Versions: numbers}
+	if uriFrom, uriTo := versions[from].URI, versions[to].URI; uriFrom != uriTo {
+		result.Changes = append(result.Changes, fieldChange{Field: "uri", From: uriFrom, To: uriTo})
+	}
+	result.Changes = append(result.Changes, diffMetadata(before, after)...)
+
+	if jsonOutput() {
+		if result.Changes == nil {
+			result.Changes = []fieldChange{}
+		}
+		return printJSON(result)
+	}
+	displayMetadataDiff(result, versions)
+	return nil
+}
+
+// diffMetadata compares two metadata documents field by field. Nested
+// objects are flattened to dotted paths and attributes are keyed by trait.
+func diffMetadata(before, after *fetcher.NFTMetadata) []fieldChange {
+	old, current := flattenMetadata(before), flattenMetadata(after)
+
+	fields := make(map[string]bool)
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range current {
+		fields[field] = true
+	}
+	sorted := make([]string, 0, len(fields))
+	for field := range fields {
+		sorted = append(sorted, field)
+	}
+	sort.Strings(sorted)
+
+	var changes []fieldChange
+	for _, field := range sorted {
+		if old[field] != current[field] {
+			changes = append(changes, fieldChange{Field: field, From: old[field], To: current[field]})
+		}
+	}
+	return changes
+}
+
+// flattenMetadata turns metadata into a map of field path to display value
+func flattenMetadata(metadata *fetcher.NFTMetadata) map[string]string {
+	flat := make(map[string]string)
+	if metadata == nil {
+		return flat
+	}
+
+	for _, attribute := range metadata.Attributes {
+		flat["attributes."+attribute.TraitType] = fmt.Sprint(attribute.Value)
+	}
+	withoutAttributes := *metadata
+	withoutAttributes.Attributes = nil
+
+	data, err := json.Marshal(withoutAttributes)
+	if err != nil {
+		return flat
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return flat
+	}
+	flattenValue("", fields, flat)
+	return flat
+}
+
+func flattenValue(prefix string, value interface{}, flat map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenValue(path, nested, flat)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", prefix, i), nested, flat)
+		}
+	case nil:
+	default:
+		if s := fmt.Sprint(v); s != "" && s != "0" && s != "false" {
+			flat[prefix] = s
+		}
+	}
+}
+
+func displayMetadataDiff(result metadataDiff, versions map[int]storage.MetadataVersion) {
+	fmt.Printf("\n🔀 Metadata of %s: v%d → v%d\n", result.Mint, result.From, result.To)
+	fmt.Printf("═══════════════════════════════════════════════════════════\n")
+	for _, number := range []int{result.From, result.To} {
+		if version, ok := versions[number]; ok {
+			fmt.Printf("v%-3d saved %s\n", number, version.SavedAt.Local().Format("2006-01-02 15:04"))
+		}
+	}
+	fmt.Println()
+
+	if len(result.Changes) == 0 {
+		fmt.Printf("No differences\n")
+		return
+	}
+	for _, change := range result.Changes {
+		switch {
+		case change.From == "":
+			fmt.Printf("+ %-28s %s\n", change.Field, truncateString(change.To, 60))
+		case change.To == "":
+			fmt.Printf("- %-28s %s\n", change.Field, truncateString(change.From, 60))
+		default:
+			fmt.Printf("~ %-28s %s → %s\n", change.Field, truncateString(change.From, 40), truncateString(change.To, 40))
+		}
+	}
+	fmt.Printf("\n%d field(s) changed\n", len(result.Changes))
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
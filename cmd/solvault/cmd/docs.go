@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate reference documentation for every command",
+	// Explanation: Like completion, docs only describe the CLI itself
+	PersistentPreRunE: setupOutput,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Write a man page for every command",
+	Long: `Write one man page per command (solvault.1, solvault-verify.1,
+solvault-proof-anchor.1, ...) into --dir, generated from the same help text
+as --help.
+
+Example:
+  solvault docs man
+  man ./man/solvault-verify.1
+  sudo solvault docs man --dir /usr/local/share/man/man1`,
+	Args: cobra.NoArgs,
+	RunE: runDocsMan,
+}
+
+var docsDir string
+
+func runDocsMan(cmd *cobra.Command, args []string) error {
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", docsDir, err)
+	}
+
+	root := cmd.Root()
+	root.DisableAutoGenTag = true // Keep regenerated pages identical
+	header := &doc.GenManHeader{
+		Title:   "SOLVAULT",
+		Section: "1",
+		Source:  "SolVault " + Version,
+		Manual:  "SolVault Manual",
+	}
+	if err := doc.GenManTree(root, header, docsDir); err != nil {
+		return fmt.Errorf("failed to generate man pages: %w", err)
+	}
+
+	pages, _ := filepath.Glob(filepath.Join(docsDir, "*.1"))
+	if jsonOutput() {
+		return printJSON(struct {
+			Dir   string `json:"dir"`
+			Pages int    `json:"pages"`
+		}{docsDir, len(pages)})
+	}
+	fmt.Printf("📖 Wrote %d man page(s) to %s\n", len(pages), docsDir)
+	fmt.Printf("   Read one with 'man %s'\n", filepath.Join(docsDir, "solvault.1"))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd)
+
+	docsManCmd.Flags().StringVar(&docsDir, "dir", "man", "directory the man pages are written to")
+	_ = docsManCmd.MarkFlagDirname("dir")
+}
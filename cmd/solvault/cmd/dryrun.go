@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// dryRun is the global --dry-run flag
+var dryRun bool
+
+// dryRunAnnotation marks commands that honour --dry-run
+const dryRunAnnotation = "solvault.dry-run"
+
+// dryRunMode reports whether --dry-run was given
+func dryRunMode() bool {
+	return dryRun
+}
+
+// supportDryRun marks a command as able to report what it would do without
+// doing it. Its RunE must check dryRunMode before every write, delete or
+// upload.
+func supportDryRun(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[dryRunAnnotation] = "true"
+}
+
+// checkDryRun refuses --dry-run on commands that have not been taught it.
+//
+// Explanation: --dry-run is global so scripts can add it to any command
+// line, but a command that ignored it would go ahead and change things. A
+// command only runs in dry-run mode once it has opted in with
+// supportDryRun.
+func checkDryRun(cmd *cobra.Command, args []string) error {
+	if !dryRun || cmd.Annotations[dryRunAnnotation] == "true" {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return fmt.Errorf("'%s' does not support --dry-run", cmd.CommandPath())
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/phash"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// dupesCmd finds visually identical NFTs
+var dupesCmd = &cobra.Command{
+	Use:   "dupes",
+	Short: "Find backed-up NFTs whose images look the same",
+	Long: `Group NFTs whose images show the same picture, even when the files differ
+because they were re-encoded, resized or recompressed: copymints, the same
+art minted into several collections, or placeholder images of unrevealed
+mints.
+
+Images are compared by the perceptual hashes recorded at backup time.
+Backups made before hashes were recorded are hashed from their files as
+they are read; the next sync records them. --threshold sets how many of the
+128 hash bits may differ (default 16); lower is stricter.
+
+Different mints are grouped only; the copies of one mint held by linked
+wallets are one backup and are not duplicates (see 'solvault dedupe').
+
+Example:
+  solvault dupes
+  solvault dupes --cross-collection
+  solvault dupes --wallet 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU --output json`,
+	Args: cobra.NoArgs,
+	RunE: runDupes,
+}
+
+var (
+	dupesWallet          string
+	dupesThreshold       int
+	dupesCrossCollection bool
+)
+
+// dupeImage is one image of a backed-up NFT
+type dupeImage struct {
+	Wallet     string `json:"wallet"`
+	Mint       string `json:"mint"`
+	Name       string `json:"name,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	File       string `json:"file"` // Relative to the NFT's backup directory
+	hash       phash.Hash
+}
+
+// dupeGroup is a set of NFTs showing the same picture
+type dupeGroup struct {
+	Collections int          `json:"collections"` // How many collections the group spans
+	Images      []*dupeImage `json:"images"`
+}
+
+func runDupes(cmd *cobra.Command, args []string) error {
+	var wallet string
+	if dupesWallet != "" {
+		wallets, err := selectWallets(dupesWallet)
+		if err != nil {
+			return err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	images, computed := dupeImages(vault, wallet)
+	if len(images) == 0 {
+		fmt.Println("📭 No backed-up images to compare")
+		return nil
+	}
+	if computed > 0 && !jsonOutput() {
+		fmt.Printf("🎨 Hashed %d image(s) backed up before perceptual hashes were recorded; run sync to record them\n", computed)
+	}
+
+	hashes := make([]phash.Hash, len(images))
+	for i, image := range images {
+		hashes[i] = image.hash
+	}
+	groups := []*dupeGroup{}
+	for _, members := range phash.Cluster(hashes, dupesThreshold) {
+		group := &dupeGroup{}
+		mints := make(map[string]bool)
+		collections := make(map[string]bool)
+		for _, i := range members {
+			group.Images = append(group.Images, images[i])
+			mints[images[i].Mint] = true
+			collections[images[i].Collection] = true
+		}
+		group.Collections = len(collections)
+		// An NFT repeating its own image is not a duplicate of anything
+		if len(mints) < 2 || (dupesCrossCollection && group.Collections < 2) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	if jsonOutput() {
+		return printJSON(groups)
+	}
+	if len(groups) == 0 {
+		fmt.Printf("✅ No visually identical NFTs among %d image(s)\n", len(images))
+		return nil
+	}
+	for i, group := range groups {
+		fmt.Printf("\n🪞 Group %d: %d image(s) across %d collection(s)\n", i+1, len(group.Images), group.Collections)
+		for _, image := range group.Images {
+			name := image.Name
+			if name == "" {
+				name = image.Mint
+			}
+			collection := image.Collection
+			if collection == "" {
+				collection = "no collection"
+			}
+			fmt.Printf("   • %s (%s) %s %s\n", name, collection, truncateString(image.Mint, 12), image.File)
+		}
+	}
+	fmt.Printf("\n📊 %d group(s) of visually identical NFTs among %d image(s)\n", len(groups), len(images))
+	return nil
+}
+
+// dupeImages loads the perceptual hash of every backed-up image, hashing
+// images whose backups predate the hashes. It returns the images and how
+// many had to be hashed.
+func dupeImages(vault *storage.FileStorage, wallet string) ([]*dupeImage, int) {
+	var images []*dupeImage
+	seen := make(map[string]bool)
+	computed := 0
+	for _, entry := range vault.Index().List() {
+		if wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		dir := vault.EntryDir(entry)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		var stored storage.StoredNFT
+		data, err := vault.ReadFile(filepath.Join(dir, "nft_data.json"))
+		if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil {
+			continue
+		}
+		collection := entry.Collection
+		if metadata := stored.NFTInfo.Metadata; collection == "" && metadata != nil {
+			collection = metadata.Collection.Name
+		}
+		for _, media := range stored.NFTInfo.MediaFiles {
+			image := &dupeImage{
+				Wallet:     entry.Wallet,
+				Mint:       entry.Mint,
+				Name:       entry.Name,
+				Collection: collection,
+				File:       "media/" + media.Filename,
+			}
+			if media.PerceptualHash != nil {
+				image.hash = *media.PerceptualHash
+				images = append(images, image)
+				continue
+			}
+			if media.MediaType != fetcher.MediaTypeImage {
+				continue
+			}
+			data, err := vault.ReadFile(filepath.Join(dir, "media", media.Filename))
+			if err != nil {
+				continue
+			}
+			hash, err := phash.Compute(data)
+			if err != nil {
+				continue // SVG, or not an image after all
+			}
+			image.hash = *hash
+			images = append(images, image)
+			computed++
+		}
+	}
+	return images, computed
+}
+
+func init() {
+	rootCmd.AddCommand(dupesCmd)
+	dupesCmd.Flags().StringVar(&dupesWallet, "wallet", "", "only compare NFTs backed up for this wallet")
+	dupesCmd.Flags().IntVar(&dupesThreshold, "threshold", phash.Threshold, "hash bits of 128 that may differ between the same picture")
+	dupesCmd.Flags().BoolVar(&dupesCrossCollection, "cross-collection", false, "only show groups that span more than one collection")
+}
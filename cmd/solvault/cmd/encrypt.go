@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// encryptCmd represents the encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the vault's backups at rest",
+	Long: `Encrypt every backup in the vault with AES-256-GCM, for vaults kept on shared
+drives or synced folders.
+
+nft_data.json, metadata.json (and its versioned snapshots), the media
+manifest and all media are encrypted in place; new backups are encrypted as
+they are saved. info, verify and restore decrypt transparently.
+
+The key comes from a passphrase (SOLVAULT_PASSPHRASE, or asked for in a
+terminal) or from a key file (--key-file, generated if it does not exist).
+Set SOLVAULT_PASSPHRASE or SOLVAULT_KEY_FILE in ~/.solvault.env so later
+commands can unlock the vault. There is no recovery: losing the passphrase
+or key file loses the backups.
+
+index.json, vault.json and directory names stay readable so the vault can
+be listed without the key; use the default mint layout if NFT names are
+sensitive.
+
+Example:
+  solvault encrypt
+  solvault encrypt --key-file ~/.solvault.key
+  solvault encrypt --off`,
+	Args: cobra.NoArgs,
+	RunE: runEncrypt,
+}
+
+var (
+	encryptKeyFile string
+	encryptOff     bool
+)
+
+func runEncrypt(cmd *cobra.Command, args []string) error {
+	if encryptOff {
+		return runDecrypt()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if vault.Encrypted() {
+		fmt.Printf("🔒 Vault %s is already encrypted\n", vault.BaseDir())
+		return nil
+	}
+
+	var params *crypt.Params
+	var cipher *crypt.Cipher
+	if encryptKeyFile != "" {
+		if _, err := os.Stat(encryptKeyFile); os.IsNotExist(err) {
+			if err := crypt.GenerateKeyFile(encryptKeyFile); err != nil {
+				return err
+			}
+			fmt.Printf("🔑 Generated key file %s; keep a copy somewhere safe\n", encryptKeyFile)
+		}
+		if params, cipher, err = crypt.FromKeyFile(encryptKeyFile); err != nil {
+			return err
+		}
+	} else {
+		passphrase, err := newPassphrase()
+		if err != nil {
+			return err
+		}
+		if params, cipher, err = crypt.FromPassphrase(passphrase); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("🔐 Encrypting backups in %s...\n", vault.BaseDir())
+	converted, err := vault.EnableEncryption(params, cipher)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt vault: %w", err)
+	}
+	fmt.Printf("✅ Encrypted %d file(s)\n", converted)
+
+	if encryptKeyFile != "" {
+		fmt.Printf("💡 Add SOLVAULT_KEY_FILE=%s to ~/.solvault.env so SolVault can unlock the vault\n", encryptKeyFile)
+	} else if os.Getenv(crypt.EnvPassphrase) == "" {
+		fmt.Printf("💡 Set SOLVAULT_PASSPHRASE (e.g. in ~/.solvault.env) so SolVault can unlock the vault\n")
+	}
+	return nil
+}
+
+// runDecrypt decrypts every backup and turns encryption off
+func runDecrypt() error {
+	backupDir, err := getBackupDirectory()
+	if err != nil {
+		return err
+	}
+	solana.LoadEnvFiles()
+	if header, err := storage.ReadHeader(backupDir); err == nil && header.Encryption != nil &&
+		header.Encryption.KDF == crypt.KDFScrypt && os.Getenv(crypt.EnvPassphrase) == "" && term.IsTerminal(int(os.Stdin.Fd())) {
+		passphrase, err := readPassphrase("Passphrase: ")
+		if err != nil {
+			return err
+		}
+		os.Setenv(crypt.EnvPassphrase, passphrase)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if !vault.Encrypted() {
+		fmt.Printf("🔓 Vault %s is not encrypted\n", vault.BaseDir())
+		return nil
+	}
+
+	fmt.Printf("🔓 Decrypting backups in %s...\n", vault.BaseDir())
+	converted, err := vault.DisableEncryption()
+	if err != nil {
+		return fmt.Errorf("failed to decrypt vault: %w", err)
+	}
+	fmt.Printf("✅ Decrypted %d file(s); the vault is no longer encrypted\n", converted)
+	return nil
+}
+
+// newPassphrase takes the passphrase from SOLVAULT_PASSPHRASE, or asks for
+// it twice in a terminal
+func newPassphrase() (string, error) {
+	if passphrase := os.Getenv(crypt.EnvPassphrase); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set %s or pass --key-file to encrypt without a terminal", crypt.EnvPassphrase)
+	}
+
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) < 8 {
+		return "", errors.New("passphrase must be at least 8 characters")
+	}
+	again, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// readPassphrase prompts for a passphrase without echoing it
+func readPassphrase(prompt string) (string, error) {
+	fmt.Print(prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(data), nil
+}
+
+// vaultKey caches the cipher used by commands that read backup files
+// directly rather than through storage
+var vaultKey struct {
+	once   sync.Once
+	cipher *crypt.Cipher
+	err    error
+}
+
+// readVaultFile reads a backup file, decrypting and decompressing it as
+// needed
+func readVaultFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || !crypt.IsSealed(data) {
+		return decompressVaultFile(data, err)
+	}
+
+	vaultKey.once.Do(func() {
+		backupDir, err := getBackupDirectory()
+		if err != nil {
+			vaultKey.err = err
+			return
+		}
+		solana.LoadEnvFiles()
+		header, err := storage.ReadHeader(backupDir)
+		if err != nil || header.Encryption == nil {
+			vaultKey.err = fmt.Errorf("%s is encrypted but vault %s has no encryption settings", path, backupDir)
+			return
+		}
+		vaultKey.cipher, vaultKey.err = header.Encryption.FromEnv()
+	})
+	if vaultKey.err != nil {
+		return nil, vaultKey.err
+	}
+	return decompressVaultFile(vaultKey.cipher.Open(data))
+}
+
+// decompressVaultFile undoes vault compression, passing errors through
+func decompressVaultFile(data []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return storage.Decompress(data)
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+
+	encryptCmd.Flags().StringVar(&encryptKeyFile, "key-file", "", "encrypt with the key in this file instead of a passphrase (generated if missing)")
+	encryptCmd.Flags().BoolVar(&encryptOff, "off", false, "decrypt every backup and turn encryption off")
+}
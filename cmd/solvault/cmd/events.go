@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+// eventsCmd represents the events command
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show what the watcher did",
+	Long: `Show the watcher's event journal: when it started and stopped, every new
+mint it detected, every backup it started, completed or failed, and every
+scheduled job and verification it ran.
+
+Events are appended to events.jsonl at the vault root as they happen and are
+never rewritten, so the journal is an audit trail of the daemon. --since and
+--until take an age (90m, 24h, 7d, 2w), a date (2026-01-31) or an RFC 3339
+time.
+
+Example:
+  solvault events --since 24h
+  solvault events --since 7d --kind backup_failed
+  solvault events --mint 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault events --since 2026-01-01 --until 2026-02-01 --output json`,
+	Args: cobra.NoArgs,
+	RunE: runEvents,
+}
+
+var (
+	eventsSince string
+	eventsUntil string
+	eventsKinds []string
+	eventsMint  string
+)
+
+// eventIcons prefixes each kind of event in the text output
+var eventIcons = map[journal.Kind]string{
+	journal.WatchStarted:    "👀",
+	journal.WatchStopped:    "🛑",
+	journal.MintDetected:    "🆕",
+	journal.NFTDeparted:     "📤",
+	journal.DrainDetected:   "🚨",
+	journal.BackupStarted:   "💾",
+	journal.BackupCompleted: "✅",
+	journal.BackupFailed:    "❌",
+	journal.VerificationRun: "🔍",
+	journal.ScheduledJobRun: "⏰",
+}
+
+func runEvents(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	filter := journal.Filter{Mint: eventsMint}
+	var err error
+	if filter.Since, err = parseEventTime("since", eventsSince, now); err != nil {
+		return err
+	}
+	if filter.Until, err = parseEventTime("until", eventsUntil, now); err != nil {
+		return err
+	}
+	for _, kind := range eventsKinds {
+		if _, ok := eventIcons[journal.Kind(kind)]; !ok {
+			return fmt.Errorf("invalid --kind %q: use one of %s", kind, strings.Join(eventKindNames(), ", "))
+		}
+		filter.Kinds = append(filter.Kinds, journal.Kind(kind))
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	events, skipped, err := journal.Open(vault.BaseDir()).Read(filter)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		fmt.Printf("⚠️  Skipped %d unreadable line(s) in the journal\n", skipped)
+	}
+
+	if jsonOutput() {
+		if events == nil {
+			events = []journal.Event{}
+		}
+		return printJSON(map[string]interface{}{"count": len(events), "events": events})
+	}
+
+	if len(events) == 0 {
+		fmt.Println("📭 No events found matching criteria")
+		return nil
+	}
+	for _, event := range events {
+		fmt.Println(formatEvent(event))
+	}
+	fmt.Printf("\n📊 %d event(s)\n", len(events))
+	return nil
+}
+
+// formatEvent renders one event as a line of text
+func formatEvent(event journal.Event) string {
+	line := fmt.Sprintf("%s %s %-17s", event.Time.Local().Format("2006-01-02 15:04:05"), eventIcons[event.Kind], event.Kind)
+	switch {
+	case event.Name != "":
+		line += " " + event.Name
+	case event.Mint != "":
+		line += " " + event.Mint
+	}
+	if event.Detail != "" {
+		line += " (" + event.Detail + ")"
+	}
+	if event.Duration > 0 {
+		line += fmt.Sprintf(" in %s", (time.Duration(event.Duration * float64(time.Second))).Round(100*time.Millisecond))
+	}
+	if event.Error != "" {
+		line += ": " + event.Error
+	}
+	if event.Signature != "" {
+		line += " [tx " + event.Signature + "]"
+	}
+	return line
+}
+
+// parseEventTime reads --since or --until as an age before now (with d and w
+// for days and weeks), or as a date or time like list's --after
+func parseEventTime(flag, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if age, ok := parseAge(value); ok {
+		return now.Add(-age), nil
+	}
+	t, err := parseListDate(flag, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: use an age like 24h or 7d, a date like 2026-01-31 or an RFC 3339 time", flag, value)
+	}
+	return t, nil
+}
+
+// parseAge reads a Go duration, or a whole number of days or weeks
+func parseAge(value string) (time.Duration, bool) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, suffix)); err == nil && strings.HasSuffix(value, suffix) && n >= 0 {
+			return time.Duration(n) * unit, true
+		}
+	}
+	age, err := time.ParseDuration(value)
+	return age, err == nil && age >= 0
+}
+
+func eventKindNames() []string {
+	names := make([]string, len(journal.Kinds))
+	for i, kind := range journal.Kinds {
+		names[i] = string(kind)
+	}
+	return names
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().StringVar(&eventsSince, "since", "", "only show events from this long ago (24h, 7d) or this date on")
+	eventsCmd.Flags().StringVar(&eventsUntil, "until", "", "only show events before this age or date")
+	eventsCmd.Flags().StringSliceVar(&eventsKinds, "kind", nil, "only show these kinds of event (repeatable or comma-separated)")
+	eventsCmd.Flags().StringVar(&eventsMint, "mint", "", "only show events for this mint address")
+	_ = eventsCmd.RegisterFlagCompletionFunc("kind", cobra.FixedCompletions(eventKindNames(), cobra.ShellCompDirectiveNoFileComp))
+}
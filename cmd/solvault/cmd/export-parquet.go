@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/spf13/cobra"
+)
+
+// exportParquetCmd represents the export-parquet command
+var exportParquetCmd = &cobra.Command{
+	Use:   "export-parquet <output-dir>",
+	Short: "Export the vault as Parquet files for analytics",
+	Long: `Flatten every stored NFT into Parquet files that load directly into
+DuckDB, Pandas, Polars or Spark:
+
+• nfts.parquet        one row per NFT (mint, collection, sizes, prices, timestamps)
+• attributes.parquet  one row per trait, exploded from the metadata
+• media.parquet       one row per downloaded media file
+
+Example:
+  solvault export-parquet ./analytics
+  solvault export-parquet ./analytics --wallet h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP
+  duckdb -c "SELECT collection, count(*) FROM './analytics/nfts.parquet' GROUP BY 1"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportParquet,
+}
+
+var exportParquetWallet string
+
+func runExportParquet(cmd *cobra.Command, args []string) error {
+	outputDir := args[0]
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	ctx := cmd.Context()
+	wallets, err := vault.ListWallets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dataset analytics.Dataset
+	for _, wallet := range wallets {
+		if exportParquetWallet != "" && wallet.String() != exportParquetWallet {
+			continue
+		}
+
+		nfts, err := vault.ListNFTs(ctx, wallet)
+		if err != nil {
+			return err
+		}
+		for _, nft := range nfts {
+			dataset.Add(wallet.String(), nft)
+		}
+	}
+
+	fmt.Printf("📊 Writing %d NFT(s), %d attribute(s), %d media file(s)...\n",
+		len(dataset.NFTs), len(dataset.Attributes), len(dataset.Media))
+
+	files, err := dataset.WriteParquet(outputDir)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]interface{}{
+			"nfts":       len(dataset.NFTs),
+			"attributes": len(dataset.Attributes),
+			"media":      len(dataset.Media),
+			"files":      files,
+		})
+	}
+
+	for _, file := range files {
+		fmt.Printf("   • %s\n", file)
+	}
+	fmt.Println("✅ Parquet export complete")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportParquetCmd)
+
+	exportParquetCmd.Flags().StringVar(&exportParquetWallet, "wallet", "", "only export NFTs held by this wallet")
+}
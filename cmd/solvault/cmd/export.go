@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/archive"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export <output.tar.gz|output.zip|output.xlsx|output.csv>",
+	Short: "Package backups into a portable archive or an inventory",
+	Long: `Export one NFT, a collection, a wallet, or the whole vault into a .tar.gz or
+.zip archive that includes a manifest with SHA-256 checksums for every file.
+
+Use 'solvault import' on another machine to validate and ingest the archive.
+
+With an .xlsx or .csv file, or --format xlsx|csv, the selected NFTs are written
+as a portfolio inventory instead, for insurance and accounting: mint, name,
+collection, backup date, status, last proof result, media size and the last
+sale and floor prices in SOL. No backup files are included.
+
+Example:
+  solvault export vault.tar.gz
+  solvault export lion.zip --mint 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault export cats.tar.gz --collection "Cool Cats"
+  solvault export wallet.zip --wallet h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP
+  solvault export inventory.xlsx
+  solvault export cats-inventory --format csv --collection "Cool Cats"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+var (
+	exportMint       string
+	exportCollection string
+	exportWallet     string
+	exportFormat     string
+)
+
+func runExport(cmd *cobra.Command, args []string) error {
+	outputPath := args[0]
+	kind := exportFormat
+	if kind == "" {
+		kind = analytics.InventoryFormat(outputPath)
+	}
+	var format archive.Format
+	switch kind {
+	case "csv", "xlsx":
+	case string(archive.FormatTarGz), string(archive.FormatZip):
+		format = archive.Format(kind)
+	case "":
+		detected, err := archive.DetectFormat(outputPath)
+		if err != nil {
+			return err
+		}
+		format = detected
+	default:
+		return fmt.Errorf("invalid --format %q: use tar.gz, zip, xlsx or csv", kind)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	ctx := cmd.Context()
+	nfts, err := selectExportNFTs(ctx, vault)
+	if err != nil {
+		return err
+	}
+	if len(nfts) == 0 {
+		fmt.Println("📭 No NFTs found matching criteria")
+		return nil
+	}
+	if format == "" {
+		return exportInventory(vault, nfts, kind, outputPath)
+	}
+
+	// Gather every file in each selected NFT directory, relative to the vault root
+	var files []string
+	for _, nft := range nfts {
+		nftDir := vault.NFTDir(nft.NFTInfo.Owner, nft.NFTInfo.MintAddress)
+		err := filepath.WalkDir(nftDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(vault.BaseDir(), path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to collect files for %s: %w", nft.NFTInfo.MintAddress, err)
+		}
+	}
+
+	fmt.Printf("🔐 Hashing %d file(s) from %d NFT(s)...\n", len(files), len(nfts))
+	manifest, err := archive.BuildManifest(vault.BaseDir(), files, exportScope(), fmt.Sprintf("SolVault %s", Version))
+	if err != nil {
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	fmt.Printf("📦 Writing %s archive: %s\n", format, outputPath)
+	extra := map[string][]byte{archive.ManifestName: manifestData}
+	if err := archive.Create(outputPath, format, vault.BaseDir(), files, extra); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Exported %d NFT(s) to %s\n", len(nfts), outputPath)
+	return nil
+}
+
+// exportInventory writes the selected NFTs as a csv or xlsx inventory
+func exportInventory(vault *storage.FileStorage, nfts []*storage.StoredNFT, format, outputPath string) error {
+	var listed []NFTInfo
+	for _, nft := range nfts {
+		wallet, mint := nft.NFTInfo.Owner.String(), nft.NFTInfo.MintAddress.String()
+		name := mint
+		if nft.NFTInfo.Metadata != nil && nft.NFTInfo.Metadata.Name != "" {
+			name = nft.NFTInfo.Metadata.Name
+		}
+		info, err := analyzeNFTDirectory(name, vault.NFTDir(nft.NFTInfo.Owner, nft.NFTInfo.MintAddress))
+		if err != nil {
+			return fmt.Errorf("failed to analyze %s: %w", name, err)
+		}
+		info.Wallet, info.Mint = wallet, mint
+		addIntegritySizes(vault, &info)
+		info.BackupDate = time.Time{} // Taken from nft_data.json instead of the directory
+		if entry := vault.Index().Get(wallet, mint); entry != nil &&
+			(entry.Status == storage.StatusTransferred || entry.Status == storage.StatusBurned) {
+			info.Status = string(entry.Status)
+		}
+		listed = append(listed, info)
+	}
+
+	fmt.Printf("📊 Writing %s inventory: %s\n", format, outputPath)
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputPath, err)
+	}
+	if err := writeInventory(file, format, listed); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	fmt.Printf("✅ Exported an inventory of %d NFT(s) to %s\n", len(listed), outputPath)
+	return nil
+}
+
+// selectExportNFTs returns the stored NFTs matching the export flags
+func selectExportNFTs(ctx context.Context, vault *storage.FileStorage) ([]*storage.StoredNFT, error) {
+	wallets, err := vault.ListWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*storage.StoredNFT
+	for _, wallet := range wallets {
+		if exportWallet != "" && wallet.String() != exportWallet {
+			continue
+		}
+
+		nfts, err := vault.ListNFTs(ctx, wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, nft := range nfts {
+			if nft.NFTInfo == nil {
+				continue
+			}
+			if exportMint != "" && nft.NFTInfo.MintAddress.String() != exportMint {
+				continue
+			}
+			if exportCollection != "" && !inCollection(nft, exportCollection) {
+				continue
+			}
+			selected = append(selected, nft)
+		}
+	}
+
+	return selected, nil
+}
+
+// inCollection reports whether a stored NFT's metadata names the collection
+func inCollection(nft *storage.StoredNFT, name string) bool {
+	metadata := nft.NFTInfo.Metadata
+	if metadata == nil {
+		return false
+	}
+	return strings.EqualFold(metadata.Collection.Name, name) || strings.EqualFold(metadata.Collection.Family, name)
+}
+
+// exportScope describes the export selection for the manifest
+func exportScope() string {
+	switch {
+	case exportMint != "":
+		return "mint:" + exportMint
+	case exportCollection != "":
+		return "collection:" + exportCollection
+	case exportWallet != "":
+		return "wallet:" + exportWallet
+	default:
+		return "vault"
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportMint, "mint", "", "export a single NFT by mint address")
+	exportCmd.Flags().StringVar(&exportCollection, "collection", "", "export every NFT in a collection")
+	exportCmd.Flags().StringVar(&exportWallet, "wallet", "", "export every NFT held by a wallet")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "tar.gz or zip archive, or xlsx or csv inventory (default from the file name)")
+	_ = exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"tar.gz", "zip", "xlsx", "csv"}, cobra.ShellCompDirectiveNoFileComp))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fsckRepair     bool
+	fsckReportPath string
+)
+
+// fsckCmd represents the fsck command
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the integrity of every backup in the vault",
+	Long: `Walk the vault and check that:
+
+  • every JSON file parses
+  • every NFT record and media file matches the checksum stored for it
+  • directory names match the mint addresses inside them
+  • index.json agrees with the backups on disk
+
+The findings are written to fsck_report.json in the vault (or --report).
+With --repair, fsck also fixes what it can without the network: it rewrites
+metadata.json and media_manifest.json from nft_data.json, finishes
+interrupted saves, renames misnamed directories and rebuilds the index.
+Damaged records and media are left for a fresh backup.
+
+Exits non-zero while problems remain, so it can run from cron.
+
+Example:
+  solvault fsck
+  solvault fsck --repair
+  solvault fsck --json > fsck.json`,
+	RunE: runFsck,
+}
+
+func runFsck(cmd *cobra.Command, args []string) error {
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	if !jsonOutput() {
+		fmt.Printf("🩺 Checking vault at %s...\n", vault.BaseDir())
+	}
+	report, err := vault.Fsck(cmd.Context(), fsckRepair)
+	if err != nil {
+		return fmt.Errorf("failed to check vault: %w", err)
+	}
+
+	reportPath := fsckReportPath
+	if reportPath == "" {
+		reportPath = filepath.Join(vault.BaseDir(), "fsck_report.json")
+	}
+	if err := writeFsckReport(reportPath, report); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printFsckReport(report, reportPath)
+	}
+
+	if !report.OK() {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d problem(s) remain in the vault", report.Unrepaired)
+	}
+	return nil
+}
+
+func writeFsckReport(path string, report *storage.FsckReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fsck report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fsck report: %w", err)
+	}
+	return nil
+}
+
+func printFsckReport(report *storage.FsckReport, path string) {
+	fmt.Printf("\n📊 Vault Health Report\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("Checked:      %d NFT(s), %d file(s)\n", report.NFTs, report.Files)
+	fmt.Printf("Problems:     %d\n", len(report.Issues))
+	if report.Repair {
+		fmt.Printf("Repaired:     %d 🔧\n", report.Repaired)
+	}
+
+	if len(report.Issues) > 0 {
+		fmt.Printf("\n⚠️  Problems\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, issue := range report.Issues {
+			mark := "❌"
+			if issue.Repaired {
+				mark = "✅"
+			}
+			fmt.Printf("%s [%s] %s\n", mark, issue.Kind, issue.Path)
+			fmt.Printf("   %s\n", issue.Message)
+			switch {
+			case issue.Error != "":
+				fmt.Printf("   🚫 Repair failed: %s\n", issue.Error)
+			case issue.Repaired:
+				fmt.Printf("   🔧 Repaired: %s\n", issue.Repair)
+			case issue.Repair != "":
+				fmt.Printf("   💡 Fix: %s\n", issue.Repair)
+			}
+		}
+	}
+
+	if report.OK() {
+		fmt.Printf("\n✅ Vault is healthy\n")
+	} else if !report.Repair {
+		fmt.Printf("\n💡 Run 'solvault fsck --repair' to fix what can be fixed locally\n")
+	}
+	fmt.Printf("\n📄 Report saved to: %s\n", path)
+}
+
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+
+	fsckCmd.Flags().BoolVar(&fsckRepair, "repair", false, "fix what can be fixed without the network")
+	fsckCmd.Flags().StringVar(&fsckReportPath, "report", "", "report path (default <vault>/fsck_report.json)")
+}
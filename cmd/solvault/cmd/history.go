@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/NazWright/solvault/internal/provenance"
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history <mint-address>",
+	Short: "Show the ownership and sale history of an NFT",
+	Long: `Show every mint, transfer, sale and burn of an NFT, reconstructed from its
+on-chain transactions.
+
+For NFTs in the vault the history is saved as provenance.json next to the
+backup, and later runs only fetch transactions newer than the last one seen.
+Sales through Magic Eden and Tensor are labelled with the marketplace; the
+price is what the buyer paid, including royalties and marketplace fees.
+
+Example:
+  solvault history 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault history --offline 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault history --format json 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+var (
+	historyFormat  string
+	historyOffline bool
+)
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyFormat == "json" {
+		enableJSONOutput()
+	}
+
+	mint, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid mint address %s: %w", args[0], err)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	// Linked custody records share the primary's directory, so any entry
+	// leads to the same provenance file
+	var nftDir string
+	if entries := vault.Index().ByMint(mint.String()); len(entries) > 0 {
+		wallet, err := solanago.PublicKeyFromBase58(entries[0].Wallet)
+		if err == nil {
+			nftDir = vault.NFTDir(wallet, mint)
+		}
+	}
+
+	history := &provenance.History{Mint: mint.String(), Events: []provenance.Event{}}
+	if nftDir != "" {
+		if history, err = provenance.Load(nftDir, mint.String()); err != nil {
+			return err
+		}
+	} else if historyOffline {
+		return fmt.Errorf("NFT %s is not in the vault, so there is no saved history to show offline", mint.String())
+	} else {
+		fmt.Printf("ℹ️  %s is not in the vault; its history will not be saved\n", shortAddress(mint.String()))
+	}
+
+	if !historyOffline {
+		config, err := solana.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		client, err := solana.NewClient(config)
+		if err != nil {
+			return fmt.Errorf("failed to create Solana client: %w", err)
+		}
+
+		fmt.Printf("🔗 Reading transactions for %s...\n", shortAddress(mint.String()))
+		added, updateErr := provenance.Update(cmd.Context(), provenance.NewChainSource(client), history)
+		if nftDir != "" && (added > 0 || updateErr == nil) {
+			// Keep partial progress so the next run resumes where this one stopped
+			if err := provenance.Save(nftDir, history); err != nil {
+				return err
+			}
+		}
+		if updateErr != nil {
+			fmt.Printf("⚠️  History may be incomplete: %v\n", updateErr)
+		} else {
+			fmt.Printf("✅ Found %d new event(s)\n", added)
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(history)
+	}
+	displayHistory(history)
+	return nil
+}
+
+// displayHistory prints the events oldest first
+func displayHistory(history *provenance.History) {
+	fmt.Printf("\n📜 Provenance of %s\n", history.Mint)
+	fmt.Printf("═══════════════════════════════════════════════════════════\n")
+	if len(history.Events) == 0 {
+		fmt.Printf("No ownership changes recorded\n")
+		return
+	}
+
+	fmt.Printf("%-17s %-9s %-14s %-12s %s\n", "DATE", "EVENT", "PRICE", "MARKETPLACE", "FROM → TO")
+	sales := 0
+	for _, event := range history.Events {
+		date := "unknown"
+		if event.Time != nil {
+			date = event.Time.Local().Format("2006-01-02 15:04")
+		}
+		parties := fmt.Sprintf("%s → %s", historyParty(event.From), historyParty(event.To))
+		price := "-"
+		if event.Kind == provenance.KindSale {
+			price = fmt.Sprintf("%.4f SOL", event.PriceSOL())
+			sales++
+		}
+		marketplace := event.Marketplace
+		if marketplace == "" {
+			marketplace = "-"
+		}
+		fmt.Printf("%-17s %-9s %-14s %-12s %s\n", date, event.Kind, price, marketplace, parties)
+	}
+
+	fmt.Printf("\n%d event(s), %d sale(s)\n", len(history.Events), sales)
+	if !history.UpdatedAt.IsZero() {
+		fmt.Printf("Last updated %s\n", history.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// historyParty shortens an owner address, showing a dash for the missing
+// side of mints and burns
+func historyParty(address string) string {
+	if address == "" {
+		return "-"
+	}
+	return shortAddress(address)
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historyFormat, "format", "table", "output format (table, json)")
+	historyCmd.Flags().BoolVar(&historyOffline, "offline", false, "show the saved history without contacting the RPC")
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// holdersCmd represents the holders command
+var holdersCmd = &cobra.Command{
+	Use:   "holders <collection-mint>",
+	Short: "Snapshot the current holders of every NFT in a collection",
+	Long: `List who holds every NFT in a verified collection right now and write it
+to a timestamped CSV or JSON file, e.g. for an airdrop to your holders.
+
+The CSV has one row per NFT (mint, name, owner); with --by-owner it has one
+row per wallet with the number of NFTs it holds, largest holders first. The
+JSON file has both the per-NFT list and the snapshot time. Burned NFTs are
+left out. Nothing is read from or written to the vault.
+
+The collection is listed with the DAS getAssetsByGroup API, from the first
+page every time so the snapshot is current: --das-url, DAS_RPC_URL, or
+NFT_PROVIDER.
+
+Example:
+  solvault holders J1S9H3QjnRtBbbuD4HjPV6RpRhwuk4zKbxsnCHuTgh9w
+  solvault holders J1S9...gh9w --by-owner --out airdrop.csv
+  solvault holders J1S9...gh9w --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHolders,
+}
+
+var (
+	holdersOut     string
+	holdersFormat  string
+	holdersByOwner bool
+	holdersDASURL  string
+)
+
+func runHolders(cmd *cobra.Command, args []string) error {
+	collection, err := solanago.PublicKeyFromBase58(strings.TrimSpace(args[0]))
+	if err != nil {
+		return fmt.Errorf("invalid collection mint %q: %w", args[0], err)
+	}
+	format := holdersFormat
+	if format == "" {
+		format = "csv"
+		if strings.EqualFold(filepath.Ext(holdersOut), ".json") {
+			format = "json"
+		}
+	}
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("invalid --format %q: use csv or json", format)
+	}
+
+	solana.LoadEnvFiles()
+	endpoint, err := dasEndpoint(holdersDASURL, solana.PrimaryRPCURL())
+	if err != nil {
+		return err
+	}
+	if endpoint == "" {
+		return fmt.Errorf("no DAS-enabled RPC configured: set DAS_RPC_URL or NFT_PROVIDER, or pass --das-url")
+	}
+
+	ctx := cmd.Context()
+
+	fmt.Printf("🔍 Listing the holders of collection %s...\n", collection.String())
+	assets, err := scanCollection(ctx, das.NewClient(endpoint, 0), collection.String())
+	if err != nil {
+		return fmt.Errorf("failed to list collection: %w (holders needs an RPC provider that supports the DAS API)", err)
+	}
+	snapshot := backup.NewHolderSnapshot(collection, backup.HoldersOf(assets), true)
+
+	path := holdersOut
+	if path == "" {
+		path = fmt.Sprintf("holders-%s-%s.%s", collection.String(), snapshot.TakenAt.Format("20060102T150405Z"), format)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(snapshot)
+	} else {
+		err = snapshot.WriteCSV(file, holdersByOwner)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]interface{}{
+			"collection": snapshot.Collection,
+			"taken_at":   snapshot.TakenAt,
+			"nfts":       len(snapshot.Holders),
+			"owners":     snapshot.Owners,
+			"file":       path,
+		})
+	}
+	fmt.Printf("👥 %d NFT(s) held by %d wallet(s)\n", len(snapshot.Holders), snapshot.Owners)
+	for i, owner := range snapshot.ByOwner() {
+		if i == 5 {
+			break
+		}
+		fmt.Printf("   %-44s %5d\n", owner.Owner, owner.Count)
+	}
+	fmt.Printf("✅ Holder snapshot written to %s\n", path)
+	return nil
+}
+
+// scanCollection pages through every NFT in a verified collection
+func scanCollection(ctx context.Context, client *das.Client, collection string) ([]das.Asset, error) {
+	var assets []das.Asset
+	var at das.Position
+	for pages := 1; ; pages++ {
+		page, err := client.GetAssetsByGroup(ctx, "collection", collection, at, das.MaxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, page.Items...)
+		if len(assets) > 0 {
+			fmt.Printf("   %d NFT(s) found\n", len(assets))
+		}
+		next, more := das.Next(at, page, das.MaxPageSize, pages)
+		if !more {
+			return assets, nil
+		}
+		at = next
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(holdersCmd)
+
+	holdersCmd.Flags().StringVar(&holdersOut, "out", "", "file to write (default holders-<collection>-<time>.csv or .json in the current directory)")
+	holdersCmd.Flags().StringVar(&holdersFormat, "format", "", "csv or json (default from the --out extension, then csv)")
+	holdersCmd.Flags().BoolVar(&holdersByOwner, "by-owner", false, "CSV: one row per wallet with its NFT count instead of one row per NFT")
+	holdersCmd.Flags().StringVar(&holdersDASURL, "das-url", "", "DAS-enabled RPC URL (default $DAS_RPC_URL, then NFT_PROVIDER's DAS API, then SOLANA_RPC_URL)")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/archive"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Validate and ingest an archive created by 'solvault export'",
+	Long: `Import a .tar.gz or .zip archive produced by 'solvault export' into the vault.
+
+Every file is checked against the SHA-256 checksums in the archive manifest
+before anything is written; a single mismatch aborts the import.
+
+Example:
+  solvault import vault.tar.gz
+  solvault import lion.zip --overwrite
+  solvault import vault.tar.gz --overwrite --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var importOverwrite bool
+
+func runImport(cmd *cobra.Command, args []string) error {
+	source := args[0]
+
+	tempDir, err := os.MkdirTemp("", "solvault_import_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	fmt.Printf("📦 Extracting %s...\n", source)
+	if err := archive.Extract(source, tempDir); err != nil {
+		return err
+	}
+
+	manifest, err := archive.LoadManifest(tempDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔐 Validating %d file checksum(s)...\n", len(manifest.Files))
+	if problems := manifest.Verify(tempDir); len(problems) > 0 {
+		fmt.Printf("\n🚫 Integrity check failed\n")
+		for _, problem := range problems {
+			fmt.Printf("• %s\n", problem)
+		}
+		return fmt.Errorf("archive failed validation (%d problem(s)); nothing was imported", len(problems))
+	}
+	fmt.Println("✅ All checksums match")
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	if dryRunMode() {
+		printImportPlan(vault.BaseDir(), manifest)
+		return nil
+	}
+	if importOverwrite && !confirmBulk(cmd, importOverwritePlan(vault.BaseDir(), manifest)) {
+		return nil
+	}
+
+	// Copy files NFT by NFT so an existing backup is either kept or fully replaced
+	imported, skipped := make(map[string]bool), make(map[string]bool)
+	for _, entry := range manifest.Files {
+		nftKey := nftDirKey(entry.Path)
+		if nftKey == "" {
+			continue // Only wallets/<wallet>/nfts/<dir>/... is ingested
+		}
+		if skipped[nftKey] {
+			continue
+		}
+
+		if !imported[nftKey] {
+			existing := filepath.Join(vault.BaseDir(), filepath.FromSlash(nftKey))
+			if _, err := os.Stat(existing); err == nil {
+				if !importOverwrite {
+					fmt.Printf("⏭️  Skipping %s (already backed up, use --overwrite to replace)\n", nftKey)
+					skipped[nftKey] = true
+					continue
+				}
+				if err := os.RemoveAll(existing); err != nil {
+					return fmt.Errorf("failed to replace %s: %w", nftKey, err)
+				}
+			}
+			imported[nftKey] = true
+		}
+
+		src := filepath.Join(tempDir, filepath.FromSlash(entry.Path))
+		dst := filepath.Join(vault.BaseDir(), filepath.FromSlash(entry.Path))
+		if err := copyImportedFile(src, dst); err != nil {
+			return fmt.Errorf("failed to import %s: %w", entry.Path, err)
+		}
+	}
+
+	count, err := vault.RebuildIndex(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Imported %d NFT(s), skipped %d (vault now indexes %d)\n", len(imported), len(skipped), count)
+	return nil
+}
+
+// importOverwritePlan lists the backups in the vault the archive would replace
+func importOverwritePlan(vaultDir string, manifest *archive.Manifest) bulkPlan {
+	plan := bulkPlan{Action: "overwrite"}
+	seen := make(map[string]bool)
+	for _, entry := range manifest.Files {
+		nftKey := nftDirKey(entry.Path)
+		if nftKey == "" || seen[nftKey] {
+			continue
+		}
+		seen[nftKey] = true
+		if _, err := os.Stat(filepath.Join(vaultDir, filepath.FromSlash(nftKey))); err == nil {
+			plan.Items = append(plan.Items, nftKey)
+		}
+	}
+	return plan
+}
+
+// printImportPlan reports what an import would write, without writing it
+func printImportPlan(vaultDir string, manifest *archive.Manifest) {
+	existing := importOverwritePlan(vaultDir, manifest).Items
+	present := make(map[string]bool, len(existing))
+	for _, nftKey := range existing {
+		present[nftKey] = true
+	}
+
+	seen := make(map[string]bool)
+	added, files := 0, 0
+	for _, entry := range manifest.Files {
+		nftKey := nftDirKey(entry.Path)
+		if nftKey == "" || present[nftKey] && !importOverwrite {
+			continue
+		}
+		files++
+		if !seen[nftKey] {
+			seen[nftKey] = true
+			if !present[nftKey] {
+				added++
+			}
+		}
+	}
+
+	if importOverwrite && len(existing) > 0 {
+		printBulkPlan(bulkPlan{Action: "overwrite", Items: existing})
+	}
+	skipped := len(existing)
+	if importOverwrite {
+		skipped = 0
+	}
+	fmt.Printf("🧪 Dry run: would import %d new NFT(s), overwrite %d and skip %d (%d file(s)); nothing was changed\n",
+		added, len(existing)-skipped, skipped, files)
+}
+
+// nftDirKey returns the wallets/<wallet>/nfts/<dir> prefix of an archive path
+func nftDirKey(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 5 || parts[0] != "wallets" || parts[2] != "nfts" {
+		return ""
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return ""
+		}
+	}
+	if _, err := solanago.PublicKeyFromBase58(parts[1]); err != nil {
+		return ""
+	}
+	return strings.Join(parts[:4], "/")
+}
+
+func copyImportedFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "replace NFTs that are already backed up")
+	guardDestructive(importCmd)
+}
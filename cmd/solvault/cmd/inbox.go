@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/inbox"
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// inboxCmd represents the inbox command
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Show the drop folder that queues mint addresses for backup",
+	Long: `Drop a text file containing mint addresses (one per line, or separated by
+commas) into the inbox and the watcher backs each one up under the configured
+wallet that holds it. Handled files are moved to processed/ together with a
+<file>.result.json describing what happened to every mint.
+
+The inbox defaults to ~/.solvault/inbox; set INBOX_DIR or --inbox to change it.
+
+Example:
+  solvault inbox
+  echo 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU > ~/.solvault/inbox/today.txt
+  solvault inbox process`,
+	RunE: runInbox,
+}
+
+var inboxProcessCmd = &cobra.Command{
+	Use:   "process",
+	Short: "Process the inbox once without running the watcher",
+	RunE:  runInboxProcess,
+}
+
+var inboxDir string
+
+// openInbox resolves the inbox from --inbox, INBOX_DIR, then the default
+func openInbox() (*inbox.Inbox, error) {
+	solana.LoadEnvFiles()
+
+	dir := inboxDir
+	if dir == "" {
+		dir = os.Getenv("INBOX_DIR")
+	}
+	if dir == "" {
+		var err error
+		if dir, err = inbox.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return inbox.New(dir)
+}
+
+func runInbox(cmd *cobra.Command, args []string) error {
+	box, err := openInbox()
+	if err != nil {
+		return err
+	}
+
+	pending, err := box.Pending()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]interface{}{
+			"inbox":     box.Dir(),
+			"processed": box.ProcessedDir(),
+			"pending":   len(pending),
+		})
+	}
+
+	fmt.Printf("📥 Inbox:     %s\n", box.Dir())
+	fmt.Printf("   Processed: %s\n", box.ProcessedDir())
+	fmt.Printf("   Pending:   %d file(s)\n", len(pending))
+	return nil
+}
+
+func runInboxProcess(cmd *cobra.Command, args []string) error {
+	box, err := openInbox()
+	if err != nil {
+		return err
+	}
+
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	source := backup.NewChainSource(client)
+	defer source.Close()
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(source, vault); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	results, err := processInbox(ctx, box, vault, source, config.Wallets, nil)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if results == nil {
+			results = []*inbox.Result{}
+		}
+		return printJSON(results)
+	}
+	if len(results) == 0 {
+		fmt.Println("📭 No files waiting in the inbox")
+	}
+	return nil
+}
+
+// processInbox backs up every pending drop file under the configured wallet
+// holding each mint, printing one line per file. Backups are journaled to
+// events when it is set.
+func processInbox(ctx context.Context, box *inbox.Inbox, vault *storage.FileStorage, source backup.Source, wallets []solanago.PublicKey, events *journal.Journal) ([]*inbox.Result, error) {
+	// Holdings are listed at most once per wallet per pass
+	holdings := make(map[solanago.PublicKey]map[solanago.PublicKey]bool)
+	holderOf := func(ctx context.Context, mint solanago.PublicKey) (solanago.PublicKey, error) {
+		for _, wallet := range wallets {
+			if holdings[wallet] == nil {
+				mints, err := source.ListMints(ctx, wallet)
+				if err != nil {
+					return solanago.PublicKey{}, fmt.Errorf("failed to list NFTs for %s: %w", wallet.String(), err)
+				}
+				holdings[wallet] = make(map[solanago.PublicKey]bool, len(mints))
+				for _, m := range mints {
+					holdings[wallet][m] = true
+				}
+			}
+			if holdings[wallet][mint] {
+				return wallet, nil
+			}
+		}
+		return solanago.PublicKey{}, fmt.Errorf("not held by any configured wallet")
+	}
+
+	opts := backup.Options{Market: market.FromEnv(), Mirror: backupMirror(vault), Thumbnails: thumbnail.SizesFromEnv(), Journal: events, Progress: func(msg string) { fmt.Println(msg) }}
+	results, err := box.Process(ctx, func(ctx context.Context, mint solanago.PublicKey) inbox.MintResult {
+		wallet, err := holderOf(ctx, mint)
+		if err != nil {
+			return inbox.MintResult{Mint: mint.String(), Status: string(backup.ChangeFailed), Error: err.Error()}
+		}
+
+		change := backup.BackupMint(ctx, vault, source, wallet, mint, opts)
+		return inbox.MintResult{
+			Mint:   change.Mint,
+			Wallet: wallet.String(),
+			Name:   change.Name,
+			Status: string(change.Kind),
+			Error:  change.Error,
+		}
+	})
+
+	for _, wallet := range wallets {
+		if len(results) == 0 {
+			break
+		}
+		if _, err := vault.UpdateMerkle(wallet.String()); err != nil {
+			fmt.Printf("⚠️  Failed to update the Merkle root of %s: %v\n", wallet.String(), err)
+		}
+	}
+
+	for _, result := range results {
+		icon := "✅"
+		if result.Failed > 0 || len(result.Invalid) > 0 {
+			icon = "⚠️ "
+		}
+		fmt.Printf("%s Inbox file %s: %d backed up, %d failed, %d invalid\n",
+			icon, result.File, result.Succeeded, result.Failed, len(result.Invalid))
+	}
+	return results, err
+}
+
+func init() {
+	rootCmd.AddCommand(inboxCmd)
+	inboxCmd.AddCommand(inboxProcessCmd)
+
+	inboxCmd.PersistentFlags().StringVar(&inboxDir, "inbox", "", "inbox directory (default $INBOX_DIR, then ~/.solvault/inbox)")
+}
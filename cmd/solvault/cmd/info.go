@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +23,23 @@ file hashes, verification status, and proof information.
 
 This command will:
 • Show NFT metadata (name, description, attributes)
+• Show the NFT's rarity rank within your backups of its collection
+• Show the custody timeline when several vault wallets held the NFT
+• Show whether the NFT is staked, listed or held in escrow for the wallet
 • Display file hashes and verification status
 • Show backup location and file sizes
 • Display proof information if available
+• With --owner, look up which wallet holds the NFT on chain right now
+
+The NFT can be named by its mint address (or its start), its name, symbol or
+collection. Case, punctuation and small typos are forgiven; when several NFTs
+match, they are listed and you are asked which one you meant.
 
 Example:
   solvault info "Cool Cat #1234"
+  solvault info "cool cat 1234"
   solvault info 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault info "Cool Cat #1234" --owner
   solvault info --format json "Midnight Lion #01"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInfo,
@@ -33,20 +48,19 @@ Example:
 var (
 	infoFormat string
 	showFiles  bool
+	infoOwner  bool
 )
 
 func runInfo(cmd *cobra.Command, args []string) error {
+	if infoFormat == "json" {
+		enableJSONOutput()
+	}
+
 	identifier := args[0]
 	fmt.Printf("🔍 Looking up NFT: %s\n", identifier)
 
-	// Get backup directory
-	backupDir, err := getBackupDirectory()
-	if err != nil {
-		return err
-	}
-
 	// Find NFT directory
-	nftPath, err := findNFTDirectory(backupDir, identifier)
+	nftPath, err := resolveNFTPath(identifier, "")
 	if err != nil {
 		return err
 	}
@@ -57,100 +71,64 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if infoOwner {
+		if mint, err := storedMint(nftPath); err != nil {
+			fmt.Printf("⚠️  Cannot look up the current owner: %v\n", err)
+		} else if nftInfo.CurrentOwner, err = lookupCurrentOwner(cmd.Context(), mint); err != nil {
+			fmt.Printf("⚠️  Failed to look up the current owner: %v\n", err)
+		}
+	}
+
 	// Display information
-	switch infoFormat {
-	case "json":
+	if jsonOutput() {
 		return displayNFTInfoJSON(nftInfo)
-	default:
-		return displayNFTInfoTable(nftInfo)
 	}
+	return displayNFTInfoTable(nftInfo)
 }
 
 type DetailedNFTInfo struct {
 	NFTInfo
-	Metadata  map[string]interface{}
-	Hash      string
-	ProofData map[string]interface{}
-	Files     []FileInfo
-	TotalSize int64
+	Metadata  map[string]interface{}  `json:"metadata,omitempty"`
+	Hash      string                  `json:"hash,omitempty"` // Legacy hash.txt
+	Integrity *storage.Integrity      `json:"integrity,omitempty"`
+	ProofData map[string]interface{}  `json:"proof,omitempty"`
+	Files     []FileInfo              `json:"files"`
+	TotalSize int64                   `json:"total_size_bytes"`
+	Custody   []storage.CustodyPeriod `json:"custody,omitempty"`
+	Royalties *royaltyInfo            `json:"royalties,omitempty"`
+	Market    *market.Quote           `json:"market,omitempty"` // Prices recorded at backup time
+	Mint      *mintInfo               `json:"mint,omitempty"`
+	// Comparison of the metadata with a second gateway's copy at backup time
+	MetadataCheck *fetcher.MetadataCheck `json:"metadata_check,omitempty"`
+	// Mirror the metadata was fetched from when URI_REWRITES redirected it
+	MetadataSource string `json:"metadata_source,omitempty"`
+	// Rank among the vault's backups of the collection, from the last sync
+	Rarity *storage.Rarity `json:"rarity,omitempty"`
+	// Staking or escrow lock, or the program holding the NFT, from the last sync
+	Escrow *fetcher.Escrow `json:"escrow,omitempty"`
+	// Holder on chain when looked up with --owner
+	CurrentOwner *currentOwner `json:"current_owner,omitempty"`
 }
 
-type FileInfo struct {
-	Name string
-	Size int64
-	Path string
+// mintInfo is the state of the NFT's mint account at backup time
+type mintInfo struct {
+	Supply          uint64   `json:"supply"`
+	MintAuthority   string   `json:"mint_authority,omitempty"`
+	FreezeAuthority string   `json:"freeze_authority,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"` // Scarcity or custody risks
 }
 
-func findNFTDirectory(backupDir, identifier string) (string, error) {
-	// First try exact match by directory name
-	exactPath := filepath.Join(backupDir, identifier)
-	if _, err := os.Stat(exactPath); err == nil {
-		return exactPath, nil
-	}
-
-	// Scan all directories for matches
-	entries, err := os.ReadDir(backupDir)
-	if err != nil {
-		return "", fmt.Errorf("failed to read backup directory: %w", err)
-	}
-
-	var matches []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		// Check if directory name contains identifier (case-insensitive)
-		name := entry.Name()
-		if contains(name, identifier) {
-			matches = append(matches, filepath.Join(backupDir, name))
-		}
-	}
-
-	if len(matches) == 0 {
-		return "", fmt.Errorf("NFT not found: %s", identifier)
-	}
-
-	if len(matches) > 1 {
-		fmt.Printf("⚠️  Multiple matches found:\n")
-		for i, match := range matches {
-			fmt.Printf("  %d. %s\n", i+1, filepath.Base(match))
-		}
-		return "", fmt.Errorf("multiple matches found, please be more specific")
-	}
-
-	return matches[0], nil
-}
-
-func contains(s, substr string) bool {
-	// Simple case-insensitive contains check
-	s = toLower(s)
-	substr = toLower(substr)
-	return len(s) >= len(substr) && findSubstring(s, substr)
-}
-
-func toLower(s string) string {
-	result := make([]byte, len(s))
-	for i, b := range []byte(s) {
-		if b >= 'A' && b <= 'Z' {
-			result[i] = b + 32
-		} else {
-			result[i] = b
-		}
-	}
-	return string(result)
+// royaltyInfo is the on-chain royalty configuration of an NFT
+type royaltyInfo struct {
+	SellerFeeBasisPoints int               `json:"seller_fee_basis_points"`
+	Creators             []fetcher.Creator `json:"creators"`
+	Warnings             []string          `json:"warnings,omitempty"` // Off-chain metadata disagrees
 }
 
-func findSubstring(s, substr string) bool {
-	if len(substr) == 0 {
-		return true
-	}
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
+type FileInfo struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Path string `json:"path"`
 }
 
 func loadNFTInfo(nftPath string) (*DetailedNFTInfo, error) {
@@ -170,11 +148,22 @@ func loadNFTInfo(nftPath string) (*DetailedNFTInfo, error) {
 	if detailed.HasMetadata {
 		if metadata, err := loadJSONFile(filepath.Join(nftPath, "metadata.json")); err == nil {
 			detailed.Metadata = metadata
+			if name, ok := metadata["name"].(string); ok && name != "" {
+				detailed.Name = name
+			}
+		} else if errors.Is(err, crypt.ErrLocked) {
+			fmt.Printf("🔒 %v\n", err)
 		}
 	}
 
-	// Load hash if available
+	// Load hashes if available
 	if detailed.HasHash {
+		if data, err := readVaultFile(filepath.Join(nftPath, storage.IntegrityFile)); err == nil {
+			var integrity storage.Integrity
+			if err := json.Unmarshal(data, &integrity); err == nil {
+				detailed.Integrity = &integrity
+			}
+		}
 		if hashBytes, err := os.ReadFile(filepath.Join(nftPath, "hash.txt")); err == nil {
 			detailed.Hash = string(hashBytes)
 		}
@@ -187,14 +176,64 @@ func loadNFTInfo(nftPath string) (*DetailedNFTInfo, error) {
 		}
 	}
 
+	// Load custody history for vault-managed backups
+	if data, err := readVaultFile(filepath.Join(nftPath, "nft_data.json")); err == nil {
+		var stored storage.StoredNFT
+		if err := json.Unmarshal(data, &stored); err == nil {
+			detailed.Custody = stored.Custody
+			detailed.Royalties = royaltiesFor(stored.NFTInfo)
+			detailed.Rarity = stored.Rarity
+			if stored.NFTInfo != nil {
+				detailed.Market = stored.NFTInfo.Market
+				detailed.MetadataCheck = stored.NFTInfo.MetadataCheck
+				detailed.MetadataSource = stored.NFTInfo.MetadataSource
+				detailed.Escrow = stored.NFTInfo.Escrow
+				detailed.Mint = &mintInfo{
+					Supply:          stored.NFTInfo.Supply,
+					MintAuthority:   stored.NFTInfo.MintAuthority,
+					FreezeAuthority: stored.NFTInfo.FreezeAuthority,
+					Warnings:        fetcher.AuthenticityWarnings(stored.NFTInfo),
+				}
+			}
+		}
+	}
+
 	// Get file information
 	detailed.Files, detailed.TotalSize = getFileInfo(nftPath)
 
 	return detailed, nil
 }
 
+// royaltiesFor builds the royalty section from the on-chain metadata saved
+// with the backup, flagging off-chain metadata that claims otherwise
+func royaltiesFor(nftInfo *fetcher.NFTInfo) *royaltyInfo {
+	if nftInfo == nil || nftInfo.OnChain == nil {
+		return nil
+	}
+
+	royalties := &royaltyInfo{
+		SellerFeeBasisPoints: nftInfo.OnChain.SellerFeeBasisPoints,
+		Creators:             nftInfo.OnChain.Creators,
+	}
+	if metadata := nftInfo.Metadata; metadata != nil {
+		royalties.Warnings = fetcher.CreatorMismatches(nftInfo.OnChain.Creators, metadata.Properties.Creators)
+		if metadata.SellerFeeBasisPoints != 0 && metadata.SellerFeeBasisPoints != royalties.SellerFeeBasisPoints {
+			royalties.Warnings = append(royalties.Warnings, fmt.Sprintf("off-chain seller fee is %d bps but on-chain is %d bps",
+				metadata.SellerFeeBasisPoints, royalties.SellerFeeBasisPoints))
+		}
+	}
+	return royalties
+}
+
+func authorityOrNone(authority string) string {
+	if authority == "" {
+		return "none"
+	}
+	return authority
+}
+
 func loadJSONFile(path string) (map[string]interface{}, error) {
-	data, err := os.ReadFile(path)
+	data, err := readVaultFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +282,9 @@ func displayNFTInfoTable(info *DetailedNFTInfo) error {
 	fmt.Printf("Backup Date:  %s\n", info.BackupDate.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Location:     %s\n", info.Path)
 	fmt.Printf("Total Size:   %s\n", formatBytes(info.TotalSize))
+	if info.CurrentOwner != nil {
+		fmt.Printf("Owned By:     %s\n", describeOwner(info.CurrentOwner))
+	}
 
 	// Metadata section
 	if info.Metadata != nil {
@@ -258,13 +300,122 @@ func displayNFTInfoTable(info *DetailedNFTInfo) error {
 		if image, ok := info.Metadata["image"].(string); ok {
 			fmt.Printf("Image URI:    %s\n", image)
 		}
+		if info.MetadataSource != "" {
+			fmt.Printf("Fetched From: %s (URI_REWRITES)\n", info.MetadataSource)
+		}
 	}
 
 	// Hash section
-	if info.Hash != "" {
+	if info.Hash != "" || info.Integrity != nil || info.MetadataCheck != nil {
 		fmt.Printf("\n🔐 Verification\n")
 		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
-		fmt.Printf("Hash:         %s\n", info.Hash)
+		if info.Integrity != nil {
+			fmt.Printf("Integrity:    %d file(s) hashed %s\n", len(info.Integrity.Files), info.Integrity.GeneratedAt.Format("2006-01-02 15:04:05"))
+		}
+		if info.Hash != "" {
+			fmt.Printf("Hash:         %s\n", info.Hash)
+		}
+		if check := info.MetadataCheck; check != nil {
+			switch check.Status {
+			case fetcher.CheckMatch:
+				fmt.Printf("Cross-check:  ✅ metadata matches %s\n", check.URL)
+			case fetcher.CheckMismatch:
+				fmt.Printf("Cross-check:  🚨 metadata differs from %s; a gateway may have served tampered content\n", check.URL)
+			default:
+				fmt.Printf("Cross-check:  ⚠️  not verified: %s\n", check.Error)
+			}
+		}
+	}
+
+	// Mint section
+	if mint := info.Mint; mint != nil {
+		fmt.Printf("\n🪙 Mint\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		fmt.Printf("Supply:       %d\n", mint.Supply)
+		fmt.Printf("Mint Auth:    %s\n", authorityOrNone(mint.MintAuthority))
+		fmt.Printf("Freeze Auth:  %s\n", authorityOrNone(mint.FreezeAuthority))
+		for _, warning := range mint.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+	}
+
+	// Rarity section
+	if rarity := info.Rarity; rarity != nil {
+		fmt.Printf("\n💎 Rarity\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		fmt.Printf("Rank:         #%d of %d backed up in %s\n", rarity.Rank, rarity.Of, rarity.Collection)
+		fmt.Printf("Score:        %.1f (computed %s)\n", rarity.Score, rarity.ComputedAt.Local().Format("2006-01-02 15:04"))
+		for _, trait := range rarity.Traits {
+			fmt.Printf("   %-20s %-24s %5d  %5.1f%%\n", truncateString(trait.Trait, 20), truncateString(trait.Value, 24), trait.Count, trait.Percent)
+		}
+	}
+
+	// Escrow section
+	if escrow := info.Escrow; escrow != nil {
+		fmt.Printf("\n🔒 Escrow\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		fmt.Printf("State:        %s\n", escrow.Describe())
+		fmt.Printf("Checked:      %s\n", escrow.CheckedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	// Custody section
+	if len(info.Custody) > 0 {
+		fmt.Printf("\n🔗 Custody\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, period := range info.Custody {
+			until := "now"
+			if period.To != nil {
+				until = period.To.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%s → %-16s %-12s %s\n", period.From.Format("2006-01-02 15:04"), until, period.Status, period.Wallet)
+			if period.Signature != "" {
+				fmt.Printf("   ↳ left in %s", period.Signature)
+				if period.Recipient != "" {
+					fmt.Printf(" to %s", period.Recipient)
+				}
+				fmt.Println()
+			}
+		}
+	}
+
+	// Royalties section
+	if info.Royalties != nil {
+		fmt.Printf("\n💰 Royalties\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		fmt.Printf("Seller Fee:   %.2f%% (%d bps)\n", float64(info.Royalties.SellerFeeBasisPoints)/100, info.Royalties.SellerFeeBasisPoints)
+		if len(info.Royalties.Creators) > 0 {
+			fmt.Printf("%-44s %6s  %s\n", "Creator", "Share", "Verified")
+			for _, creator := range info.Royalties.Creators {
+				verified := "❌"
+				if creator.Verified {
+					verified = "✅"
+				}
+				fmt.Printf("%-44s %5d%%  %s\n", creator.Address, creator.Share, verified)
+			}
+		}
+		for _, warning := range info.Royalties.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+	}
+
+	// Market section
+	if quote := info.Market; quote != nil {
+		fmt.Printf("\n📈 Market (%s, as of %s)\n", quote.Marketplace, quote.FetchedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		if quote.LastSaleLamports > 0 {
+			fmt.Printf("Last Sale:    %.4f SOL", quote.LastSaleSOL())
+			if quote.LastSaleAt != nil {
+				fmt.Printf(" on %s", quote.LastSaleAt.Local().Format("2006-01-02"))
+			}
+			fmt.Println()
+		}
+		if quote.FloorLamports > 0 {
+			fmt.Printf("Floor Price:  %.4f SOL", quote.FloorSOL())
+			if quote.Collection != "" {
+				fmt.Printf(" (%s)", quote.Collection)
+			}
+			fmt.Println()
+		}
 	}
 
 	// Files section
@@ -292,9 +443,7 @@ func displayNFTInfoTable(info *DetailedNFTInfo) error {
 }
 
 func displayNFTInfoJSON(info *DetailedNFTInfo) error {
-	// TODO: Implement JSON output
-	fmt.Println("📋 JSON output not yet implemented")
-	return displayNFTInfoTable(info)
+	return printJSON(info)
 }
 
 func formatBytes(bytes int64) string {
@@ -315,4 +464,5 @@ func init() {
 
 	infoCmd.Flags().StringVar(&infoFormat, "format", "table", "output format (table, json)")
 	infoCmd.Flags().BoolVar(&showFiles, "show-files", false, "show detailed file information")
+	infoCmd.Flags().BoolVar(&infoOwner, "owner", false, "look up the wallet currently holding the NFT on chain")
 }
@@ -1,101 +1,408 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/config"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
 	"github.com/spf13/cobra"
 )
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
-	Use:   "init",
+	Use:   "init [wallet-address]",
 	Short: "Initialize SolVault configuration and backup directories",
-	Long: `Initialize SolVault by creating configuration files and backup directories.
+	Long: `Initialize SolVault with a short setup wizard.
 
 This command will:
-• Create a .env configuration file with default settings
-• Set up the backup directory structure (~/SolVaultBackups)
-• Validate configuration and connectivity
-• Guide you through the setup process
+• Ask for your Solana RPC URL, wallet address and backup directory,
+  suggesting what is already configured
+• Offer to import the wallet from the Solana CLI's keypair
+  (~/.config/solana/cli/config.yml), or take a keypair file path in place
+  of an address; only the public key is saved
+• Check the wallet address format and test the connection to the RPC
+• Optionally count the NFTs the wallet holds, as a sanity check
+• Create the backup directory and write the validated settings to .env
+  (or to ~/.solvault.yaml with --global)
+
+Without a terminal, or with --yes, nothing is asked: flags, the existing
+configuration and the defaults are used. --offline skips the network checks.
 
 Example:
   solvault init
-  solvault init --backup-dir /custom/backup/path`,
+  solvault init 5QfQ...ZsLk --yes
+  solvault init ~/.config/solana/id.json
+  solvault init --from-solana-cli --yes
+  solvault init --rpc-url https://api.devnet.solana.com --backup-dir /custom/backup/path
+  solvault init --global --check-nfts`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
 
 var (
-	backupDir  string
-	force      bool // force overwrite existing .env
-	walletAddr string
+	backupDir     string
+	force         bool // force overwrite existing .env
+	walletAddr    string
+	initRPCURL    string
+	initYes       bool
+	initOffline   bool
+	initCheckNFTs bool
+	initGlobal    bool
+	initFromCLI   bool
 )
 
+// initRPCTimeout bounds each network check the wizard makes
+const initRPCTimeout = 10 * time.Second
+
 func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println("🚀 Initializing SolVault...")
-	// Accept wallet address as positional argument, fallback to flag, then prompt
-	var inputWallet string
-	if len(args) > 0 {
-		inputWallet = strings.TrimSpace(args[0])
-	} else if walletAddr != "" {
-		inputWallet = strings.TrimSpace(walletAddr)
-	}
-	if inputWallet == "" {
-		fmt.Print("Enter your Solana wallet address: ")
-		fmt.Scanln(&inputWallet)
-		inputWallet = strings.TrimSpace(inputWallet)
-		if inputWallet == "" {
-			fmt.Println("❌ Wallet address is required.")
-			return nil
+	solana.LoadEnvFiles() // Suggest what is already configured
+
+	var reader *bufio.Reader
+	if !initYes && !quietOutput() && isTerminal(os.Stdin) {
+		reader = bufio.NewReader(os.Stdin)
+	}
+
+	if !initGlobal && !force {
+		if _, err := os.Stat(".env"); err == nil {
+			if reader == nil || !askYesNo(reader, "⚠️  .env already exists. Overwrite it?", false) {
+				fmt.Printf("⚠️  .env file already exists. Use --force to overwrite\n")
+				return nil
+			}
 		}
 	}
 
-	// Set default backup directory if not specified
-	if backupDir == "" {
-		homeDir, err := os.UserHomeDir()
+	// Solana RPC
+	rpcURL := firstNonEmpty(initRPCURL, solana.PrimaryRPCURL())
+	if rpcURL == "" {
+		rpcURL, _ = solana.ClusterEndpoints("mainnet-beta")
+	}
+	rpcURL, err := askRPCURL(cmd.Context(), reader, rpcURL)
+	if err != nil {
+		return err
+	}
+
+	// Wallet, from the argument, --wallet, the configuration or a prompt
+	wallet := ""
+	if len(args) > 0 {
+		wallet = args[0]
+	}
+	wallet = firstNonEmpty(wallet, walletAddr, os.Getenv("WALLET_ADDRESS"))
+	if wallet == "your_wallet_address_here" {
+		wallet = ""
+	}
+	if wallet == "" || initFromCLI {
+		imported, err := solanaCLIWallet(reader, initFromCLI)
 		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+			return err
 		}
-		backupDir = filepath.Join(homeDir, "SolVaultBackups")
+		wallet = firstNonEmpty(imported, wallet)
+	}
+	walletKey, err := askWallet(reader, wallet)
+	if err != nil {
+		return err
 	}
 
-	// Create backup directory
-	if err := createBackupDirectory(); err != nil {
+	// Backup directory
+	if backupDir, err = getBackupDirectory(); err != nil {
 		return err
 	}
+	if reader != nil {
+		backupDir = askString(reader, "📁 Backup directory", backupDir)
+	}
+	if backupDir, err = expandHome(backupDir); err != nil {
+		return err
+	}
+
+	if !initOffline && (initCheckNFTs || (reader != nil && askYesNo(reader, "🖼️  Count the NFTs this wallet holds now?", true))) {
+		countWalletNFTs(cmd.Context(), rpcURL, walletKey)
+	}
 
-	// Create .env file with wallet address
-	if err := createEnvFile(inputWallet); err != nil {
+	// Write back the validated settings
+	if err := createBackupDirectory(rpcURL); err != nil {
+		return err
+	}
+	settings := envSettings{
+		Wallet:       walletKey.String(),
+		BackupDir:    backupDir,
+		RPCURL:       rpcURL,
+		WebSocketURL: webSocketURL(rpcURL),
+		Force:        true, // Checked above
+	}
+	where := ".env"
+	if initGlobal {
+		where = config.Path()
+		err = writeConfigFile(settings)
+	} else {
+		err = createEnvFile(settings)
+	}
+	if err != nil {
 		return err
 	}
 
 	fmt.Println("✅ SolVault initialized successfully!")
 	fmt.Printf("   Backup directory: %s\n", backupDir)
-	fmt.Println("   Configuration: .env")
+	fmt.Printf("   Configuration: %s\n", where)
+	fmt.Printf("   RPC: %s\n", rpcURL)
 	fmt.Println("")
-	fmt.Printf("SolVault configured for wallet address: %s\n", inputWallet)
-	// TODO: Add next steps or further instructions here
+	fmt.Printf("SolVault configured for wallet address: %s\n", walletKey.String())
+	fmt.Println("💡 Next: 'solvault sync' backs up the wallet, 'solvault watch' keeps it backed up")
 
 	return nil
 }
 
-func createBackupDirectory() error {
+// askRPCURL asks for the RPC URL until it is valid and answers a health
+// check, unless --offline
+func askRPCURL(ctx context.Context, reader *bufio.Reader, rpcURL string) (string, error) {
+	for {
+		if reader != nil {
+			rpcURL = askString(reader, "🔗 Solana RPC URL", rpcURL)
+		}
+		err := checkRPCURL(ctx, rpcURL)
+		if err == nil {
+			return rpcURL, nil
+		}
+		fmt.Printf("❌ %v\n", err)
+		if reader == nil {
+			return "", fmt.Errorf("RPC check failed: %w (pass --offline to skip it)", err)
+		}
+		if askYesNo(reader, "   Use this RPC URL anyway?", false) {
+			return rpcURL, nil
+		}
+	}
+}
+
+// checkRPCURL validates the URL and, unless --offline, asks the endpoint for
+// its health
+func checkRPCURL(ctx context.Context, rpcURL string) error {
+	pool, err := solana.NewPool([]string{rpcURL}, initRPCTimeout)
+	if err != nil {
+		return err
+	}
+	if initOffline {
+		return nil
+	}
+
+	fmt.Printf("🔌 Testing connection to %s...\n", rpcURL)
+	ctx, cancel := context.WithTimeout(ctx, initRPCTimeout)
+	defer cancel()
+	status := pool.CheckHealth(ctx)[0]
+	if !status.Healthy {
+		return fmt.Errorf("cannot reach %s: %s", rpcURL, status.LastError)
+	}
+	cluster := solana.ClusterFromURL(rpcURL)
+	if cluster == "" {
+		cluster = "custom endpoint"
+	}
+	fmt.Printf("✅ Connected (%s, %dms)\n", cluster, status.Latency.Milliseconds())
+	return nil
+}
+
+// solanaCLIWallet returns the keypair path from the Solana CLI config when
+// the user accepts it, or always when required (--from-solana-cli). Without
+// a usable CLI config it returns "", unless required.
+func solanaCLIWallet(reader *bufio.Reader, required bool) (string, error) {
+	cliConfig, err := solana.LoadCLIConfig("")
+	if err == nil && cliConfig.KeypairPath == "" {
+		err = fmt.Errorf("no keypair_path in the Solana CLI config")
+	}
+	if err != nil {
+		if required {
+			return "", err
+		}
+		return "", nil
+	}
+
+	key, err := solana.ResolveWallet(cliConfig.KeypairPath)
+	if err != nil {
+		if required {
+			return "", err
+		}
+		fmt.Printf("⚠️  Solana CLI keypair not usable: %v\n", err)
+		return "", nil
+	}
+	if !required && (reader == nil || !askYesNo(reader, fmt.Sprintf("🔑 Use the Solana CLI wallet %s (%s)?", key, cliConfig.KeypairPath), true)) {
+		return "", nil
+	}
+	fmt.Printf("🔑 Imported wallet %s from %s\n", key, cliConfig.KeypairPath)
+	return key.String(), nil
+}
+
+// askWallet asks for the wallet until it is a valid public key. A keypair
+// file path is accepted too and replaced by its public key.
+func askWallet(reader *bufio.Reader, wallet string) (solanago.PublicKey, error) {
+	for {
+		if reader != nil {
+			wallet = askString(reader, "👛 Solana wallet address or keypair file", wallet)
+		}
+		wallet = strings.TrimSpace(wallet)
+		if wallet == "" {
+			if reader == nil {
+				return solanago.PublicKey{}, fmt.Errorf("wallet address is required: pass it as an argument or with --wallet")
+			}
+			fmt.Println("❌ Wallet address is required.")
+			continue
+		}
+		key, err := solana.ResolveWallet(wallet)
+		if err == nil && key.IsZero() {
+			err = fmt.Errorf("the zero address is not a wallet")
+		}
+		if err == nil {
+			return key, nil
+		}
+		if reader == nil {
+			return solanago.PublicKey{}, err
+		}
+		fmt.Printf("❌ %v\n", err)
+		wallet = ""
+	}
+}
+
+// countWalletNFTs reports how many NFTs the wallet holds, so a typo in the
+// address or a wrong cluster shows up before the first sync. Failures are
+// only reported; they do not stop init.
+func countWalletNFTs(ctx context.Context, rpcURL string, wallet solanago.PublicKey) {
+	client, err := solana.NewClient(&solana.Config{
+		RPCURL:         rpcURL,
+		WalletAddress:  wallet,
+		Wallets:        []solanago.PublicKey{wallet},
+		PollInterval:   30 * time.Second,
+		MaxRetries:     3,
+		TimeoutSeconds: 30,
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Could not count NFTs: %v\n", err)
+		return
+	}
+	defer client.Close()
+	source := backup.NewChainSource(client)
+	defer source.Close()
+
+	fmt.Println("🔍 Counting NFTs...")
+	ctx, cancel := context.WithTimeout(ctx, 3*initRPCTimeout)
+	defer cancel()
+	mints, err := source.ListMints(ctx, wallet)
+	if err != nil {
+		fmt.Printf("⚠️  Could not count NFTs: %v\n", err)
+		return
+	}
+	if len(mints) == 0 {
+		fmt.Println("⚠️  This wallet holds no NFTs on this cluster. Check the address and the RPC URL.")
+		return
+	}
+	fmt.Printf("✅ Found %d NFT(s) in the wallet\n", len(mints))
+}
+
+// askString prompts for a value, returning def on an empty answer
+func askString(reader *bufio.Reader, question, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, _ := reader.ReadString('\n')
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// expandHome resolves a leading ~ and makes the path absolute
+func expandHome(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+	}
+	return filepath.Abs(path)
+}
+
+// webSocketURL is the websocket endpoint matching an RPC URL: the public
+// one for the public clusters, else the same host over ws(s)
+func webSocketURL(rpcURL string) string {
+	if public, ws := solana.ClusterEndpoints(solana.ClusterFromURL(rpcURL)); public == rpcURL {
+		return ws
+	}
+	return strings.Replace(rpcURL, "http", "ws", 1)
+}
+
+func createBackupDirectory(rpcURL string) error {
 	fmt.Printf("📁 Creating backup directory: %s\n", backupDir)
 
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
 		return fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
+	// The vault records the cluster of the RPC it was set up with
+	if rpcURL == "" {
+		rpcURL, _ = solana.ClusterEndpoints("mainnet-beta")
+	}
+	cluster := solana.ClusterFromURL(rpcURL)
+	if _, err := storage.EnsureHeader(backupDir, cluster); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeConfigFile writes the settings init asks for to the config file,
+// leaving the rest of it alone
+func writeConfigFile(settings envSettings) error {
+	path := config.Path()
+	fmt.Printf("📝 Writing configuration file: %s\n", path)
+	values := []struct{ name, value string }{
+		{"SOLANA_RPC_URL", settings.RPCURL},
+		{"SOLANA_WEBSOCKET_URL", settings.WebSocketURL},
+		{"WALLET_ADDRESS", settings.Wallet},
+		{"BACKUP_DIRECTORY", settings.BackupDir},
+	}
+	for _, v := range values {
+		key, _ := config.Lookup(v.name)
+		if err := config.Set(path, key, v.value); err != nil {
+			return fmt.Errorf("failed to write %s: %w", key.Name, err)
+		}
+	}
 	return nil
 }
 
-func createEnvFile(wallet string) error {
+// envSettings are the values written into a generated .env file
+type envSettings struct {
+	Wallet       string
+	ExtraWallets []string
+	BackupDir    string
+	RPCURL       string // Defaults to mainnet
+	WebSocketURL string
+	Force        bool // Overwrite an existing .env
+}
+
+func createEnvFile(settings envSettings) error {
 	envPath := ".env"
+	if settings.RPCURL == "" {
+		settings.RPCURL, settings.WebSocketURL = solana.ClusterEndpoints("mainnet-beta")
+	}
 
 	// Check if .env already exists
-	if _, err := os.Stat(envPath); err == nil && !force {
+	if _, err := os.Stat(envPath); err == nil && !settings.Force {
 		fmt.Printf("⚠️  .env file already exists. Use --force to overwrite\n")
 		return nil
 	}
@@ -106,12 +413,70 @@ func createEnvFile(wallet string) error {
 # Edit these values according to your setup
 
 # Solana RPC Configuration
-SOLANA_RPC_URL=https://api.mainnet-beta.solana.com
-SOLANA_WEBSOCKET_URL=wss://api.mainnet-beta.solana.com
+SOLANA_RPC_URL=%s
+SOLANA_WEBSOCKET_URL=%s
+
+# Optional: fallback RPC endpoints, tried in order when the one before fails
+# or times out (SOLANA_RPC_URL also accepts a comma-separated list)
+SOLANA_RPC_URL_2=
+# Optional: spread large batch lookups over every RPC endpoint
+RPC_LOAD_BALANCE=false
+
+# Optional: DAS-enabled RPC for 'solvault sync --das' on very large wallets
+DAS_RPC_URL=
+
+# Optional: enhanced NFT provider (rpc, helius, shyft or quicknode) for DAS
+# asset listings and webhooks; set the matching key or endpoint
+NFT_PROVIDER=rpc
+HELIUS_API_KEY=
+SHYFT_API_KEY=
+QUICKNODE_URL=
+
+# Optional: marketplace prices recorded with each backup (set MARKET_PRICES=off
+# to disable); Magic Eden needs no key, Tensor is used when a key is set
+MARKET_PRICES=
+TENSOR_API_KEY=
+
+# Optional: unlock a vault encrypted with 'solvault encrypt' (one or the other)
+SOLVAULT_PASSPHRASE=
+SOLVAULT_KEY_FILE=
+
+# Optional: storage backends, the vault first and then replicas that receive
+# every save, e.g. file,file:/mnt/usb/SolVault (default: file)
+SOLVAULT_BACKEND=
+
+# Optional: mirror backups to Google Drive and/or Dropbox (see 'solvault mirror');
+# refresh tokens keep unattended syncs working after access tokens expire
+GOOGLE_DRIVE_TOKEN=
+GOOGLE_DRIVE_REFRESH_TOKEN=
+GOOGLE_DRIVE_CLIENT_ID=
+GOOGLE_DRIVE_CLIENT_SECRET=
+GOOGLE_DRIVE_FOLDER_ID=
+DROPBOX_TOKEN=
+DROPBOX_REFRESH_TOKEN=
+DROPBOX_APP_KEY=
+DROPBOX_APP_SECRET=
+DROPBOX_PATH=
+
+# Optional: drop folder for mint lists (default ~/.solvault/inbox)
+INBOX_DIR=
+
+# Optional: email alerts for tampered media and watcher outages
+SMTP_HOST=
+SMTP_PORT=587
+SMTP_TLS=starttls
+SMTP_USERNAME=
+SMTP_PASSWORD=
+SMTP_FROM=
+SMTP_TO=
+NOTIFY_OFFLINE_MINUTES=10
 
 # Your Solana wallet address to monitor
 WALLET_ADDRESS=%s
 
+# Optional: additional wallets to protect (comma separated)
+WALLET_ADDRESSES=%s
+
 # Backup Settings
 BACKUP_DIRECTORY=%s
 
@@ -119,11 +484,19 @@ BACKUP_DIRECTORY=%s
 PUBLISH_ENDPOINT=
 PUBLISH_API_KEY=
 
+# Optional: Community verification registry for 'register' and 'lookup'
+REGISTRY_ENDPOINT=
+REGISTRY_API_KEY=
+
+# Optional: RFC 3161 timestamp authority for 'solvault proof anchor'
+TSA_URL=
+TSA_CA_FILE=
+
 # Monitoring Settings
 POLL_INTERVAL_SECONDS=30
 MAX_RETRIES=3
 TIMEOUT_SECONDS=60
-`, wallet, backupDir)
+`, settings.RPCURL, settings.WebSocketURL, settings.Wallet, strings.Join(settings.ExtraWallets, ","), settings.BackupDir)
 
 	if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
 		return fmt.Errorf("failed to create .env file: %w", err)
@@ -135,7 +508,12 @@ TIMEOUT_SECONDS=60
 func init() {
 	rootCmd.AddCommand(initCmd)
 
-	initCmd.Flags().StringVar(&backupDir, "backup-dir", "", "custom backup directory path")
 	initCmd.Flags().BoolVar(&force, "force", false, "overwrite existing .env file")
-	initCmd.Flags().StringVar(&walletAddr, "wallet", "", "Solana wallet address to use for initialization")
+	initCmd.Flags().StringVar(&walletAddr, "wallet", "", "Solana wallet address or keypair file to use for initialization")
+	initCmd.Flags().BoolVar(&initFromCLI, "from-solana-cli", false, "use the wallet of the Solana CLI's keypair (~/.config/solana/cli/config.yml)")
+	initCmd.Flags().StringVar(&initRPCURL, "rpc-url", "", "Solana RPC URL (default SOLANA_RPC_URL or mainnet)")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "accept flags, the existing configuration and defaults without prompting")
+	initCmd.Flags().BoolVar(&initOffline, "offline", false, "skip the RPC connection test and NFT count")
+	initCmd.Flags().BoolVar(&initCheckNFTs, "check-nfts", false, "count the wallet's NFTs without asking")
+	initCmd.Flags().BoolVar(&initGlobal, "global", false, "write the settings to the config file (~/.solvault.yaml) instead of .env")
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptContext returns the context every command runs under, cancelled
+// by the first Ctrl+C or SIGTERM. stop releases it once the command is done.
+//
+// Explanation: Commands pass this context down to every RPC call, download
+// and worker, so cancelling it abandons in-flight requests and stops
+// pipelines between items. Files are only ever replaced whole and a backup
+// being written stays marked in progress, so the vault is left consistent
+// and the next sync redoes what was cut short. A command that is stuck can
+// still be killed: the handler is removed after the first signal, so a
+// second one ends the process at once.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "\n⏹️  Interrupted: stopping after the current step (press Ctrl+C again to quit now)")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// inventoryRow describes a listed NFT for the CSV and XLSX inventory, adding
+// market prices from nft_data.json and the result of its last proof. Media
+// size comes from integrity.json when recorded there, as the bytes actually
+// kept on disk.
+func inventoryRow(nft NFTInfo) analytics.InventoryRow {
+	row := analytics.InventoryRow{Wallet: nft.Wallet, Mint: nft.Mint}
+	if data, err := readVaultFile(filepath.Join(nft.Path, "nft_data.json")); err == nil {
+		var stored storage.StoredNFT
+		if json.Unmarshal(data, &stored) == nil && stored.NFTInfo != nil {
+			row = analytics.NewInventoryRow(nft.Wallet, &stored)
+		}
+	}
+
+	// What list shows wins, so the inventory matches 'solvault list'
+	if row.Name == "" {
+		row.Name = nft.Name
+	}
+	if nft.Collection != "" {
+		row.Collection = nft.Collection
+	}
+	if !nft.BackupDate.IsZero() {
+		row.BackedUpAt = nft.BackupDate
+	}
+	if nft.MediaSize > 0 {
+		row.MediaBytes = nft.MediaSize
+	}
+	row.Status = nft.Status
+
+	if data, err := readVaultFile(filepath.Join(nft.Path, proof.FileName)); err == nil {
+		var doc proofDocument
+		if json.Unmarshal(data, &doc) == nil {
+			row.Verification = doc.Status
+			row.VerifiedAt, _ = time.Parse(time.RFC3339, doc.VerifiedAt)
+		}
+	}
+	return row
+}
+
+// writeInventory writes the inventory of nfts to w as csv or xlsx
+func writeInventory(w io.Writer, format string, nfts []NFTInfo) error {
+	rows := make([]analytics.InventoryRow, 0, len(nfts))
+	for _, nft := range nfts {
+		rows = append(rows, inventoryRow(nft))
+	}
+	switch format {
+	case "csv":
+		return analytics.WriteInventoryCSV(w, rows)
+	case "xlsx":
+		return analytics.WriteInventoryXLSX(w, rows)
+	default:
+		return fmt.Errorf("unsupported inventory format: %s (expected csv or xlsx)", format)
+	}
+}
@@ -9,15 +9,20 @@ import (
 	"github.com/NazWright/solvault/internal/fetcher"
 	"github.com/NazWright/solvault/internal/solana"
 	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/spf13/cobra"
 )
 
+var (
+	prettyOutput     bool
+	listTokensWallet string
+)
+
 // listTokensCmd represents the list-tokens command
-var prettyOutput bool
 var listTokensCmd = &cobra.Command{
 	Use:   "list-tokens",
 	Short: "List all NFTs in your wallet",
-	Long: `List all NFTs in your configured wallet.
+	Long: `List all NFTs in your configured wallets.
 
 This will show you only the NFTs (tokens with supply=1 and decimals=0) that your wallet owns,
 along with their mint addresses that you can use for testing.`,
@@ -30,7 +35,11 @@ along with their mint addresses that you can use for testing.`,
 			return fmt.Errorf("❌ Failed to load config: %w", err)
 		}
 
-		fmt.Printf("📋 Wallet: %s\n", config.WalletAddress.String())
+		wallets, err := selectWallets(listTokensWallet)
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+
 		fmt.Printf("🌐 RPC: %s\n\n", config.RPCURL)
 
 		// Create Solana client
@@ -41,153 +50,36 @@ along with their mint addresses that you can use for testing.`,
 		defer client.Close()
 
 		// Test connection
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 		defer cancel()
 
 		if err := client.TestConnection(ctx); err != nil {
 			return fmt.Errorf("❌ Failed to connect to Solana: %w", err)
 		}
 
-		// Get token accounts
-		fmt.Println("🔗 Fetching token accounts...")
-		tokenAccounts, err := client.GetTokenAccountsByOwner(ctx)
-		if err != nil {
-			return fmt.Errorf("❌ Failed to get token accounts: %w", err)
-		}
-
-		if len(tokenAccounts) == 0 {
-			fmt.Println("📭 No token accounts found in this wallet.")
-			return nil
-		}
+		fetcherObj := fetcher.NewFetcher(client)
+		var results []walletTokens
+		totalNFTs := 0
 
-		fmt.Printf("🔍 Found %d token account(s), filtering for NFTs...\n\n", len(tokenAccounts))
+		for _, wallet := range wallets {
+			fmt.Printf("📋 Wallet: %s\n", wallet.String())
 
-		nftCount := 0
-		fetcherObj := fetcher.NewFetcher(client)
+			result, err := listWalletTokens(cmd.Context(), client, fetcherObj, wallet)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+			totalNFTs += result.Count
+		}
 
-		for _, account := range tokenAccounts {
-			rawJSON := account.Account.Data.GetRawJSON()
-			if len(rawJSON) > 0 {
-				var parsed map[string]interface{}
-				if err := json.Unmarshal(rawJSON, &parsed); err == nil {
-					var tokenInfo map[string]interface{}
-					var ok bool
-					if parsedData, exists := parsed["parsed"].(map[string]interface{}); exists {
-						tokenInfo, ok = parsedData["info"].(map[string]interface{})
-					} else {
-						tokenInfo, ok = parsed["info"].(map[string]interface{})
-					}
-					if ok {
-						var mint string
-						var decimals float64
-						var amount string
-						var uiAmount float64
-						if m, ok := tokenInfo["mint"].(string); ok {
-							mint = m
-						}
-						if tokenAmount, ok := tokenInfo["tokenAmount"].(map[string]interface{}); ok {
-							if a, ok := tokenAmount["amount"].(string); ok {
-								amount = a
-							}
-							if d, ok := tokenAmount["decimals"].(float64); ok {
-								decimals = d
-							}
-							if ua, ok := tokenAmount["uiAmount"].(float64); ok {
-								uiAmount = ua
-							}
-						}
-						if decimals == 0 && amount == "1" && uiAmount == 1 {
-							nftCount++
-							mintPubkey, err := solanago.PublicKeyFromBase58(mint)
-							if err == nil {
-								ctxMeta, cancelMeta := context.WithTimeout(context.Background(), 10*time.Second)
-								defer cancelMeta()
-								nftInfo, err := fetcherObj.FetchNFTInfo(ctxMeta, mintPubkey)
-								if prettyOutput {
-									fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-									fmt.Printf("🖼️  NFT #%d\n", nftCount)
-									if nftInfo.Metadata != nil {
-										if nftInfo.Metadata.Name != "" {
-											fmt.Printf("🏷️  Name: %s\n", nftInfo.Metadata.Name)
-											fmt.Println("   The name of your NFT.")
-										}
-										if nftInfo.Metadata.Collection.Name != "" {
-											fmt.Printf("📚 Collection: %s\n", nftInfo.Metadata.Collection.Name)
-											fmt.Println("   The collection or series this NFT belongs to.")
-										}
-										if nftInfo.Metadata.Description != "" {
-											fmt.Printf("📝 Description: %s\n", nftInfo.Metadata.Description)
-											fmt.Println("   What this NFT is about.")
-										}
-										if nftInfo.Metadata.Image != "" {
-											fmt.Printf("🖼️  Image URL: %s\n", nftInfo.Metadata.Image)
-											fmt.Println("   Link to the NFT's image.")
-										}
-										fmt.Printf("🆔 NFT ID: %s\n", mint)
-										fmt.Println("   Unique identifier for this NFT.")
-										if len(nftInfo.Metadata.Attributes) > 0 {
-											fmt.Printf("🔖 Attributes: ")
-											for _, attr := range nftInfo.Metadata.Attributes {
-												fmt.Printf("[%s: %v] ", attr.TraitType, attr.Value)
-											}
-											fmt.Println()
-											fmt.Println("   Special traits or properties.")
-										}
-										fmt.Printf("🔗 Metadata URI: %s\n", nftInfo.MetadataURI)
-										fmt.Println("   Link to full NFT details.")
-									} else {
-										fmt.Printf("🆔 NFT ID: %s\n", mint)
-										fmt.Printf("🔗 Metadata URI: %s\n", nftInfo.MetadataURI)
-										fmt.Printf("⚠️  Metadata not found\n")
-									}
-									fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-								} else {
-									// Default technical output
-									fmt.Printf("NFT #%d:\n", nftCount)
-									fmt.Printf("  Account Address: %s\n", account.Pubkey.String())
-									fmt.Printf("  Mint Address:    %s\n", mint)
-									if err == nil && nftInfo.Metadata != nil {
-										fmt.Printf("  Name:            %s\n", nftInfo.Metadata.Name)
-										fmt.Printf("  Symbol:          %s\n", nftInfo.Metadata.Symbol)
-										fmt.Printf("  Description:     %s\n", nftInfo.Metadata.Description)
-										fmt.Printf("  Image:           %s\n", nftInfo.Metadata.Image)
-										if nftInfo.Metadata.Collection.Name != "" {
-											fmt.Printf("  Collection:      %s\n", nftInfo.Metadata.Collection.Name)
-										}
-										if len(nftInfo.Metadata.Attributes) > 0 {
-											fmt.Printf("  Attributes:      ")
-											for _, attr := range nftInfo.Metadata.Attributes {
-												fmt.Printf("[%s: %v] ", attr.TraitType, attr.Value)
-											}
-											fmt.Println()
-										}
-										fmt.Printf("  Metadata URI:    %s\n", nftInfo.MetadataURI)
-									} else if err == nil {
-										fmt.Printf("  Metadata URI:    %s\n", nftInfo.MetadataURI)
-									} else {
-										fmt.Printf("  Metadata:        (not found)\n")
-									}
-									fmt.Printf("  Amount:          %s (Supply: 1)\n", amount)
-									fmt.Printf("  Decimals:        %.0f (NFT characteristic)\n", decimals)
-									if state, ok := tokenInfo["state"].(string); ok {
-										fmt.Printf("  State:           %s\n", state)
-									}
-									fmt.Println()
-								}
-							} else {
-								fmt.Printf("  Metadata:        (invalid mint pubkey)\n")
-							}
-						}
-					}
-				}
+		if jsonOutput() {
+			if len(results) == 1 {
+				return printJSON(results[0])
 			}
+			return printJSON(results)
 		}
 
-		if nftCount == 0 {
-			fmt.Println("📭 No NFTs found in this wallet.")
-			fmt.Println("💡 NFTs are tokens with exactly 1 supply and 0 decimals.")
-		} else {
-			fmt.Printf("✅ Found %d NFT(s) in your wallet!\n\n", nftCount)
+		if totalNFTs > 0 {
 			fmt.Println("💡 To test the NFT fetcher, use any of the mint addresses above:")
 			fmt.Println("   solvault test <mint-address>")
 		}
@@ -196,16 +88,227 @@ along with their mint addresses that you can use for testing.`,
 	},
 }
 
-// getKeys returns the keys of a map for debugging
-func getKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+// walletTokens holds the NFTs found in one wallet
+type walletTokens struct {
+	Wallet string        `json:"wallet"`
+	Count  int           `json:"count"`
+	NFTs   []walletToken `json:"nfts"`
+}
+
+// listWalletTokens fetches and prints the NFT token accounts held by wallet
+func listWalletTokens(ctx context.Context, client *solana.Client, fetcherObj *fetcher.Fetcher, wallet solanago.PublicKey) (walletTokens, error) {
+	result := walletTokens{Wallet: wallet.String(), NFTs: []walletToken{}}
+
+	listCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Get token accounts
+	fmt.Println("🔗 Fetching token accounts...")
+	tokenAccounts, err := client.GetTokenAccountsForOwner(listCtx, wallet)
+	if err != nil {
+		return result, fmt.Errorf("❌ Failed to get token accounts: %w", err)
+	}
+
+	if len(tokenAccounts) == 0 {
+		fmt.Println("📭 No token accounts found in this wallet.")
+		fmt.Println()
+		return result, nil
+	}
+
+	fmt.Printf("🔍 Found %d token account(s), filtering for NFTs...\n\n", len(tokenAccounts))
+
+	for _, account := range tokenAccounts {
+		token, ok := parseTokenAccount(account)
+		if !ok || token.Decimals != 0 || token.Amount != "1" || token.UIAmount != 1 {
+			continue
+		}
+		result.Count++
+
+		mintPubkey, err := solanago.PublicKeyFromBase58(token.Mint)
+		if err != nil {
+			fmt.Printf("  Metadata:        (invalid mint pubkey)\n")
+			continue
+		}
+
+		ctxMeta, cancelMeta := context.WithTimeout(ctx, 10*time.Second)
+		nftInfo, err := fetcherObj.FetchNFTInfoForOwner(ctxMeta, wallet, mintPubkey)
+		cancelMeta()
+
+		if jsonOutput() {
+			if err == nil {
+				token.MetadataURI = nftInfo.MetadataURI
+				token.Metadata = nftInfo.Metadata
+				token.Extensions = nftInfo.Extensions
+			}
+			result.NFTs = append(result.NFTs, token)
+		} else if prettyOutput {
+			printTokenPretty(result.Count, token, nftInfo, err)
+		} else {
+			printTokenTechnical(result.Count, token, nftInfo, err)
+		}
+	}
+
+	if result.Count == 0 {
+		fmt.Println("📭 No NFTs found in this wallet.")
+		fmt.Println("💡 NFTs are tokens with exactly 1 supply and 0 decimals.")
+	} else {
+		fmt.Printf("✅ Found %d NFT(s) in this wallet!\n", result.Count)
+	}
+	fmt.Println()
+
+	return result, nil
+}
+
+// walletToken is an NFT token account found in the wallet
+type walletToken struct {
+	Account     string                   `json:"account"`
+	Mint        string                   `json:"mint"`
+	Amount      string                   `json:"amount"`
+	Decimals    float64                  `json:"decimals"`
+	UIAmount    float64                  `json:"ui_amount"`
+	State       string                   `json:"state,omitempty"`
+	Program     string                   `json:"program,omitempty"`
+	MetadataURI string                   `json:"metadata_uri,omitempty"`
+	Metadata    *fetcher.NFTMetadata     `json:"metadata,omitempty"`
+	Extensions  *fetcher.TokenExtensions `json:"token_extensions,omitempty"`
+}
+
+// parseTokenAccount extracts mint and amount details from a jsonParsed token account
+func parseTokenAccount(account *rpc.TokenAccount) (walletToken, bool) {
+	token := walletToken{Account: account.Pubkey.String()}
+
+	rawJSON := account.Account.Data.GetRawJSON()
+	if len(rawJSON) == 0 {
+		return token, false
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(rawJSON, &parsed); err != nil {
+		return token, false
+	}
+
+	if program, ok := parsed["program"].(string); ok {
+		token.Program = program
+	}
+
+	var tokenInfo map[string]interface{}
+	var ok bool
+	if parsedData, exists := parsed["parsed"].(map[string]interface{}); exists {
+		tokenInfo, ok = parsedData["info"].(map[string]interface{})
+	} else {
+		tokenInfo, ok = parsed["info"].(map[string]interface{})
+	}
+	if !ok {
+		return token, false
+	}
+
+	if m, ok := tokenInfo["mint"].(string); ok {
+		token.Mint = m
+	}
+	if tokenAmount, ok := tokenInfo["tokenAmount"].(map[string]interface{}); ok {
+		if a, ok := tokenAmount["amount"].(string); ok {
+			token.Amount = a
+		}
+		if d, ok := tokenAmount["decimals"].(float64); ok {
+			token.Decimals = d
+		}
+		if ua, ok := tokenAmount["uiAmount"].(float64); ok {
+			token.UIAmount = ua
+		}
+	}
+	if state, ok := tokenInfo["state"].(string); ok {
+		token.State = state
+	}
+
+	return token, true
+}
+
+// printTokenPretty shows an NFT in the visually friendly --pretty format
+func printTokenPretty(index int, token walletToken, nftInfo *fetcher.NFTInfo, err error) {
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("🖼️  NFT #%d\n", index)
+	if err == nil && nftInfo.Metadata != nil {
+		if nftInfo.Metadata.Name != "" {
+			fmt.Printf("🏷️  Name: %s\n", nftInfo.Metadata.Name)
+			fmt.Println("   The name of your NFT.")
+		}
+		if nftInfo.Metadata.Collection.Name != "" {
+			fmt.Printf("📚 Collection: %s\n", nftInfo.Metadata.Collection.Name)
+			fmt.Println("   The collection or series this NFT belongs to.")
+		}
+		if nftInfo.Metadata.Description != "" {
+			fmt.Printf("📝 Description: %s\n", nftInfo.Metadata.Description)
+			fmt.Println("   What this NFT is about.")
+		}
+		if nftInfo.Metadata.Image != "" {
+			fmt.Printf("🖼️  Image URL: %s\n", nftInfo.Metadata.Image)
+			fmt.Println("   Link to the NFT's image.")
+		}
+		fmt.Printf("🆔 NFT ID: %s\n", token.Mint)
+		fmt.Println("   Unique identifier for this NFT.")
+		if len(nftInfo.Metadata.Attributes) > 0 {
+			fmt.Printf("🔖 Attributes: ")
+			for _, attr := range nftInfo.Metadata.Attributes {
+				fmt.Printf("[%s: %v] ", attr.TraitType, attr.Value)
+			}
+			fmt.Println()
+			fmt.Println("   Special traits or properties.")
+		}
+		fmt.Printf("🔗 Metadata URI: %s\n", nftInfo.MetadataURI)
+		fmt.Println("   Link to full NFT details.")
+	} else {
+		fmt.Printf("🆔 NFT ID: %s\n", token.Mint)
+		if err == nil {
+			fmt.Printf("🔗 Metadata URI: %s\n", nftInfo.MetadataURI)
+		}
+		fmt.Printf("⚠️  Metadata not found\n")
+	}
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println()
+}
+
+// printTokenTechnical shows an NFT in the default technical format
+func printTokenTechnical(index int, token walletToken, nftInfo *fetcher.NFTInfo, err error) {
+	fmt.Printf("NFT #%d:\n", index)
+	fmt.Printf("  Account Address: %s\n", token.Account)
+	fmt.Printf("  Mint Address:    %s\n", token.Mint)
+	if err == nil && nftInfo.Metadata != nil {
+		fmt.Printf("  Name:            %s\n", nftInfo.Metadata.Name)
+		fmt.Printf("  Symbol:          %s\n", nftInfo.Metadata.Symbol)
+		fmt.Printf("  Description:     %s\n", nftInfo.Metadata.Description)
+		fmt.Printf("  Image:           %s\n", nftInfo.Metadata.Image)
+		if nftInfo.Metadata.Collection.Name != "" {
+			fmt.Printf("  Collection:      %s\n", nftInfo.Metadata.Collection.Name)
+		}
+		if len(nftInfo.Metadata.Attributes) > 0 {
+			fmt.Printf("  Attributes:      ")
+			for _, attr := range nftInfo.Metadata.Attributes {
+				fmt.Printf("[%s: %v] ", attr.TraitType, attr.Value)
+			}
+			fmt.Println()
+		}
+		fmt.Printf("  Metadata URI:    %s\n", nftInfo.MetadataURI)
+	} else if err == nil {
+		fmt.Printf("  Metadata URI:    %s\n", nftInfo.MetadataURI)
+	} else {
+		fmt.Printf("  Metadata:        (not found)\n")
+	}
+	fmt.Printf("  Amount:          %s (Supply: 1)\n", token.Amount)
+	fmt.Printf("  Decimals:        %.0f (NFT characteristic)\n", token.Decimals)
+	if token.State != "" {
+		fmt.Printf("  State:           %s\n", token.State)
+	}
+	if token.Program == "spl-token-2022" {
+		fmt.Printf("  Program:         Token-2022\n")
+	}
+	if err == nil && nftInfo.Extensions != nil && nftInfo.Extensions.PermanentDelegate != "" {
+		fmt.Printf("  ⚠️  Permanent Delegate: %s (can move or burn this NFT)\n", nftInfo.Extensions.PermanentDelegate)
 	}
-	return keys
+	fmt.Println()
 }
 
 func init() {
 	rootCmd.AddCommand(listTokensCmd)
 	listTokensCmd.Flags().BoolVar(&prettyOutput, "pretty", false, "Show NFTs in a visually friendly format")
+	listTokensCmd.Flags().StringVar(&listTokensWallet, "wallet", "", "Only list NFTs held by this wallet (default: all configured wallets)")
 }
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
 	"github.com/spf13/cobra"
 )
 
@@ -17,108 +23,391 @@ var listCmd = &cobra.Command{
 	Long: `List all NFTs that have been backed up locally by SolVault.
 
 This command will:
-• Scan the backup directory for NFT folders
-• Display NFT names, backup dates, and verification status
+• Read every backup recorded in the vault index
+• Display NFT names, collections, backup dates, sizes and verification status
 • Show summary statistics
-• Filter results by collection or status
+• Filter results by collection, trait, backup date or status
+• Sort by name, date or size, a page at a time
+
+--attribute takes Trait=Value and can be repeated; an NFT must have every
+trait given. --after and --before take a date (2026-01-31) or a full RFC 3339
+time and compare with when the NFT was last backed up: --after includes the
+day given and --before excludes it.
+
+--format csv prints a portfolio inventory for insurance and accounting: mint,
+name, collection, backup date, status, last proof result, media size and the
+last sale and floor prices in SOL. 'solvault export inventory.xlsx' writes
+the same inventory as a spreadsheet.
 
 Example:
   solvault list
   solvault list --collection "Cool Cats"
+  solvault list --attribute Background=Blue --attribute Eyes=Laser
+  solvault list --after 2026-01-01 --before 2026-02-01
+  solvault list --sort size --reverse --limit 20
+  solvault list --limit 50 --page 3
   solvault list --status verified
-  solvault list --format json`,
+  solvault list --status burned
+  solvault list --format json
+  solvault list --format csv > inventory.csv
+  solvault list --wallet 5QfQ...ZsLk`,
 	RunE: runList,
 }
 
 var (
-	collection string
-	status     string
-	format     string
-	showHashes bool
+	collection     string
+	status         string
+	format         string
+	showHashes     bool
+	listWallet     string
+	listAttributes []string
+	listAfter      string
+	listBefore     string
+	listSort       string
+	listReverse    bool
+	listLimit      int
+	listPageNumber int
 )
 
+// listFilter is the selection made with list's flags
+type listFilter struct {
+	Wallet     string
+	Collection string
+	Attributes map[string]string // Lower-case trait to lower-case value
+	After      time.Time         // Inclusive; zero when unset
+	Before     time.Time         // Exclusive; zero when unset
+	Status     string
+}
+
+// parseListFilter checks list's filter flags
+func parseListFilter() (*listFilter, error) {
+	filter := &listFilter{Collection: collection, Status: status}
+	if listWallet != "" {
+		wallets, err := selectWallets(listWallet)
+		if err != nil {
+			return nil, err
+		}
+		filter.Wallet = wallets[0].String()
+	}
+
+	for _, attribute := range listAttributes {
+		trait, value, ok := strings.Cut(attribute, "=")
+		if !ok || strings.TrimSpace(trait) == "" {
+			return nil, fmt.Errorf("invalid --attribute %q: expected Trait=Value", attribute)
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[strings.ToLower(strings.TrimSpace(trait))] = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	var err error
+	if filter.After, err = parseListDate("after", listAfter); err != nil {
+		return nil, err
+	}
+	if filter.Before, err = parseListDate("before", listBefore); err != nil {
+		return nil, err
+	}
+	if !filter.After.IsZero() && !filter.Before.IsZero() && !filter.After.Before(filter.Before) {
+		return nil, fmt.Errorf("--after must be earlier than --before")
+	}
+
+	switch listSort {
+	case "name", "date", "size":
+	default:
+		return nil, fmt.Errorf("invalid --sort %q: use name, date or size", listSort)
+	}
+	if listLimit < 0 || listPageNumber < 1 {
+		return nil, fmt.Errorf("--limit must be 0 or more and --page 1 or more")
+	}
+	return filter, nil
+}
+
+// parseListDate reads a --after or --before date, in local time when no
+// time of day is given
+func parseListDate(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: use a date like 2026-01-31 or an RFC 3339 time", flag, value)
+	}
+	return t, nil
+}
+
 func runList(cmd *cobra.Command, args []string) error {
+	switch format {
+	case "table":
+	case "json":
+		enableJSONOutput()
+	case "csv":
+		reserveStdout()
+	default:
+		return fmt.Errorf("invalid --format %q: use table, json or csv", format)
+	}
+
 	fmt.Println("📋 Listing backed-up NFTs...")
 
-	// Get backup directory from config or default
-	backupDir, err := getBackupDirectory()
+	filter, err := parseListFilter()
 	if err != nil {
 		return err
 	}
 
-	// Scan for NFT directories
-	nfts, err := scanNFTDirectories(backupDir)
+	vault, err := openVault()
 	if err != nil {
 		return err
 	}
+	defer vault.Close()
+
+	nfts := listVaultNFTs(vault, filter)
+	sortNFTs(nfts, listSort, listReverse)
+	page := paginateNFTs(nfts, listLimit, listPageNumber)
+	if len(page.NFTs) == 0 && listPageNumber > 1 {
+		return fmt.Errorf("page %d is past the last page (%d)", listPageNumber, page.Pages)
+	}
 
-	// Apply filters
-	filteredNFTs := filterNFTs(nfts)
+	if format == "csv" {
+		return writeInventory(stdout, "csv", page.NFTs)
+	}
+	if jsonOutput() {
+		return displayJSON(nfts, page)
+	}
 
-	if len(filteredNFTs) == 0 {
+	if len(nfts) == 0 {
 		fmt.Println("📭 No NFTs found matching criteria")
 		return nil
 	}
 
-	// Display results
-	switch format {
-	case "json":
-		return displayJSON(filteredNFTs)
-	default:
-		return displayTable(filteredNFTs)
-	}
+	return displayTable(nfts, page)
 }
 
 type NFTInfo struct {
-	Name        string
-	Path        string
-	BackupDate  time.Time
-	HasMetadata bool
-	HasImage    bool
-	HasHash     bool
-	HasProof    bool
-	Status      string
+	Name        string    `json:"name"`
+	Mint        string    `json:"mint,omitempty"`
+	Wallet      string    `json:"wallet,omitempty"`
+	Collection  string    `json:"collection,omitempty"`
+	Path        string    `json:"path"`
+	BackupDate  time.Time `json:"backup_date"`
+	Size        int64     `json:"size_bytes"`  // Files recorded in integrity.json
+	MediaSize   int64     `json:"media_bytes"` // Downloaded media recorded in integrity.json
+	HasMetadata bool      `json:"has_metadata"`
+	HasImage    bool      `json:"has_image"`
+	HasHash     bool      `json:"has_hash"`
+	HasProof    bool      `json:"has_proof"`
+	Status      string    `json:"status"`
+	Thumbnail   string    `json:"thumbnail,omitempty"` // Smallest preview image, for galleries
 }
 
+// getBackupDirectory resolves the vault directory every command uses: --vault
+// (or --backup-dir), the primary --backend location, BACKUP_DIRECTORY from the
+// environment, .env or config file, then ~/SolVaultBackups. A leading ~ is
+// expanded, since config files and quoted flags pass it through unexpanded.
+// With --tenant it is the tenant's vault inside that directory.
 func getBackupDirectory() (string, error) {
-	// TODO: Load from .env configuration
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+	baseDir, err := vaultRootDirectory()
+	if err != nil || tenantID == "" || usingMemoryVault() {
+		return baseDir, err
+	}
+	if _, err := selectedTenant(); err != nil {
+		return "", err
 	}
-	return filepath.Join(homeDir, "SolVaultBackups"), nil
+	return storage.TenantDir(baseDir, tenantID), nil
 }
 
-func scanNFTDirectories(backupDir string) ([]NFTInfo, error) {
-	var nfts []NFTInfo
-
-	// Check if backup directory exists
-	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
-		return nfts, fmt.Errorf("backup directory not found: %s. Run 'solvault init' first", backupDir)
+// vaultRootDirectory is the configured vault directory, the one holding
+// the tenants' vaults when --tenant is used
+func vaultRootDirectory() (string, error) {
+	if usingMemoryVault() {
+		vault, err := memoryVault()
+		if err != nil {
+			return "", err
+		}
+		return vault.BaseDir(), nil
+	}
+	if vaultPath != "" {
+		return expandHome(vaultPath)
+	}
+	if specs, err := vaultBackends(); err == nil && specs[0].Location != "" {
+		return expandHome(specs[0].Location)
 	}
 
-	// Scan directories
-	entries, err := os.ReadDir(backupDir)
-	if err != nil {
-		return nfts, fmt.Errorf("failed to read backup directory: %w", err)
+	solana.LoadEnvFiles()
+	if dir := os.Getenv("BACKUP_DIRECTORY"); dir != "" {
+		return expandHome(dir)
 	}
+	return expandHome("~/SolVaultBackups")
+}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
+// listVaultNFTs describes the NFTs in the vault index that match filter. A
+// wallet linked to another wallet's backup is only listed when asked for, so
+// the shared backup appears once.
+//
+// Explanation: Wallets can hold thousands of NFTs, so everything the index
+// knows (wallet, backup date, collection) is filtered first, and
+// nft_data.json is only read when a trait or an unindexed collection has to
+// be checked.
+func listVaultNFTs(vault *storage.FileStorage, filter *listFilter) []NFTInfo {
+	var nfts []NFTInfo
+	for _, entry := range vault.Index().List() {
+		if filter.Wallet != "" && entry.Wallet != filter.Wallet || filter.Wallet == "" && entry.PrimaryWallet != "" {
+			continue
+		}
+		if !filter.After.IsZero() && entry.UpdatedAt.Before(filter.After) ||
+			!filter.Before.IsZero() && !entry.UpdatedAt.Before(filter.Before) {
+			continue
+		}
+		name := entry.Name
+		if name == "" {
+			name = entry.Mint
+		}
+		path := vault.EntryDir(entry)
+
+		labels := listLabels{Collection: entry.Collection}
+		if len(filter.Attributes) > 0 || filter.Collection != "" && labels.Collection == "" {
+			labels = readListLabels(path)
+		}
+		if filter.Collection != "" && !labels.inCollection(filter.Collection, name) || !labels.hasAttributes(filter.Attributes) {
 			continue
 		}
 
-		nftPath := filepath.Join(backupDir, entry.Name())
-		nftInfo, err := analyzeNFTDirectory(entry.Name(), nftPath)
+		info, err := analyzeNFTDirectory(name, path)
 		if err != nil {
-			fmt.Printf("⚠️  Warning: Failed to analyze %s: %v\n", entry.Name(), err)
+			fmt.Printf("⚠️  Warning: Failed to analyze %s: %v\n", name, err)
 			continue
 		}
+		info.Mint = entry.Mint
+		info.Wallet = entry.Wallet
+		info.Collection = labels.Collection
+		if !entry.UpdatedAt.IsZero() {
+			info.BackupDate = entry.UpdatedAt
+		}
+		addIntegritySizes(vault, &info)
+		// A backup interrupted before its media finished is redone by the next sync
+		if entry.InProgress {
+			info.Status = "incomplete"
+		}
+		// NFTs that left the wallet show how they left instead of file status
+		if entry.Status == storage.StatusTransferred || entry.Status == storage.StatusBurned {
+			info.Status = string(entry.Status)
+		}
+		if filter.Status != "" && info.Status != filter.Status {
+			continue
+		}
+		nfts = append(nfts, info)
+	}
+	return nfts
+}
+
+// addIntegritySizes adds up the files recorded in the NFT's integrity.json,
+// all of them and the downloaded media alone. Thumbnails are left out of the
+// media, as they are previews SolVault made rather than the NFT's own files.
+func addIntegritySizes(vault *storage.FileStorage, info *NFTInfo) {
+	integrity, err := vault.ReadIntegrity(info.Path)
+	if err != nil {
+		return
+	}
+	for name, digest := range integrity.Files {
+		info.Size += digest.Size
+		if strings.HasPrefix(name, "media/") && !strings.HasPrefix(name, "media/"+thumbnail.Dir+"/") {
+			info.MediaSize += digest.Size
+		}
+	}
+}
+
+// listLabels is what list filters on beyond the index
+type listLabels struct {
+	Collection string
+	Family     string
+	Attributes map[string]string // Lower-case trait to lower-case value
+}
 
-		nfts = append(nfts, nftInfo)
+// readListLabels reads an NFT's collection and traits from nft_data.json
+func readListLabels(path string) listLabels {
+	var labels listLabels
+	data, err := readVaultFile(filepath.Join(path, "nft_data.json"))
+	if err != nil {
+		return labels
+	}
+	var stored storage.StoredNFT
+	if json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil || stored.NFTInfo.Metadata == nil {
+		return labels
+	}
+	md := stored.NFTInfo.Metadata
+	labels.Collection, labels.Family = md.Collection.Name, md.Collection.Family
+	labels.Attributes = make(map[string]string, len(md.Attributes))
+	for _, attr := range md.Attributes {
+		labels.Attributes[strings.ToLower(attr.TraitType)] = strings.ToLower(fmt.Sprint(attr.Value))
+	}
+	return labels
+}
+
+// inCollection matches a collection name or family, ignoring case.
+//
+// Explanation: Older NFTs often carry no collection in their metadata; for
+// those the name is searched instead, as list always did, so --collection
+// "Cool Cats" still finds "Cool Cats #12".
+func (l listLabels) inCollection(collection, name string) bool {
+	if l.Collection == "" && l.Family == "" {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(collection))
+	}
+	return strings.EqualFold(l.Collection, collection) || strings.EqualFold(l.Family, collection)
+}
+
+// hasAttributes reports whether the NFT has every wanted trait value
+func (l listLabels) hasAttributes(wanted map[string]string) bool {
+	for trait, value := range wanted {
+		if got, ok := l.Attributes[trait]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// sortNFTs orders NFTs by name, backup date or size, ties broken by name
+func sortNFTs(nfts []NFTInfo, by string, reverse bool) {
+	less := func(a, b NFTInfo) bool {
+		switch by {
+		case "date":
+			if !a.BackupDate.Equal(b.BackupDate) {
+				return a.BackupDate.Before(b.BackupDate)
+			}
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
 	}
+	sort.SliceStable(nfts, func(i, j int) bool {
+		if reverse {
+			return less(nfts[j], nfts[i])
+		}
+		return less(nfts[i], nfts[j])
+	})
+}
+
+// listPage is the slice of the sorted NFTs shown by --limit and --page
+type listPage struct {
+	NFTs   []NFTInfo
+	Offset int // Index of the first NFT shown
+	Limit  int // 0 shows everything
+	Page   int
+	Pages  int
+}
 
-	return nfts, nil
+// paginateNFTs picks one page of nfts; a limit of 0 shows them all
+func paginateNFTs(nfts []NFTInfo, limit, page int) listPage {
+	if limit == 0 {
+		return listPage{NFTs: nfts, Page: 1, Pages: 1}
+	}
+	pages := (len(nfts) + limit - 1) / limit
+	offset := min((page-1)*limit, len(nfts))
+	end := min(offset+limit, len(nfts))
+	return listPage{NFTs: nfts[offset:end], Offset: offset, Limit: limit, Page: page, Pages: max(pages, 1)}
 }
 
 func analyzeNFTDirectory(name, path string) (NFTInfo, error) {
@@ -134,17 +423,13 @@ func analyzeNFTDirectory(name, path string) (NFTInfo, error) {
 
 	// Check for required files
 	info.HasMetadata = fileExists(filepath.Join(path, "metadata.json"))
-	info.HasHash = fileExists(filepath.Join(path, "hash.txt"))
+	info.HasHash = fileExists(filepath.Join(path, storage.IntegrityFile)) || fileExists(filepath.Join(path, "hash.txt"))
 	info.HasProof = fileExists(filepath.Join(path, "proof.json"))
 
-	// Check for image files
-	imageExtensions := []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp"}
-	for _, ext := range imageExtensions {
-		if fileExists(filepath.Join(path, "image"+ext)) {
-			info.HasImage = true
-			break
-		}
-	}
+	// Check for image files, in media/ or beside the metadata
+	info.HasImage = findImageFile(path) != ""
+
+	info.Thumbnail = smallestThumbnail(filepath.Join(path, "media", thumbnail.Dir))
 
 	// Determine status
 	if info.HasMetadata && info.HasImage && info.HasHash {
@@ -160,6 +445,27 @@ func analyzeNFTDirectory(name, path string) (NFTInfo, error) {
 	return info, nil
 }
 
+// smallestThumbnail returns the path of the smallest thumbnail in dir, or
+// "" when there is none. Thumbnails are named <media>-<size>.jpg.
+func smallestThumbnail(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	best, bestSize := "", 0
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".jpg")
+		size, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if best == "" || size < bestSize {
+			best, bestSize = filepath.Join(dir, entry.Name()), size
+		}
+	}
+	return best
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -185,20 +491,29 @@ func filterNFTs(nfts []NFTInfo) []NFTInfo {
 	return filtered
 }
 
-func displayTable(nfts []NFTInfo) error {
+func displayTable(nfts []NFTInfo, page listPage) error {
 	fmt.Printf("\n📊 Found %d NFTs:\n\n", len(nfts))
-	fmt.Printf("%-30s %-12s %-20s %s\n", "NAME", "STATUS", "BACKUP DATE", "FILES")
-	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-30s %-20s %-12s %-17s %9s %s\n", "NAME", "COLLECTION", "STATUS", "BACKUP DATE", "SIZE", "FILES")
+	fmt.Println(strings.Repeat("-", 100))
 
-	for _, nft := range nfts {
+	for _, nft := range page.NFTs {
 		files := buildFileStatus(nft)
-		date := nft.BackupDate.Format("2006-01-02 15:04")
-		fmt.Printf("%-30s %-12s %-20s %s\n",
+		date := nft.BackupDate.Local().Format("2006-01-02 15:04")
+		fmt.Printf("%-30s %-20s %-12s %-17s %9s %s\n",
 			truncateString(nft.Name, 28),
+			truncateString(nft.Collection, 20),
 			nft.Status,
 			date,
+			formatBytes(nft.Size),
 			files)
 	}
+	if page.Limit > 0 {
+		fmt.Printf("\n📄 Showing %d-%d of %d (page %d of %d)", min(page.Offset+1, len(nfts)), page.Offset+len(page.NFTs), len(nfts), page.Page, page.Pages)
+		if page.Page < page.Pages {
+			fmt.Printf("; next: --page %d", page.Page+1)
+		}
+		fmt.Println()
+	}
 
 	// Summary
 	fmt.Printf("\n📈 Summary:\n")
@@ -214,10 +529,29 @@ func displayTable(nfts []NFTInfo) error {
 	return nil
 }
 
-func displayJSON(nfts []NFTInfo) error {
-	// TODO: Implement JSON output
-	fmt.Println("📋 JSON output not yet implemented")
-	return displayTable(nfts)
+func displayJSON(nfts []NFTInfo, page listPage) error {
+	statusCounts := make(map[string]int)
+	for _, nft := range nfts {
+		statusCounts[nft.Status]++
+	}
+
+	shown := page.NFTs
+	if shown == nil {
+		shown = []NFTInfo{} // Encode as [] rather than null
+	}
+
+	result := map[string]interface{}{
+		"count":   len(shown),
+		"total":   len(nfts),
+		"summary": statusCounts,
+		"nfts":    shown,
+	}
+	if page.Limit > 0 {
+		result["page"] = page.Page
+		result["pages"] = page.Pages
+		result["limit"] = page.Limit
+	}
+	return printJSON(result)
 }
 
 func buildFileStatus(nft NFTInfo) string {
@@ -250,7 +584,16 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().StringVar(&collection, "collection", "", "filter by collection name")
-	listCmd.Flags().StringVar(&status, "status", "", "filter by status (verified, backed-up, incomplete)")
-	listCmd.Flags().StringVar(&format, "format", "table", "output format (table, json)")
+	listCmd.Flags().StringVar(&status, "status", "", "filter by status (verified, backed-up, incomplete, transferred, burned)")
+	listCmd.Flags().StringVar(&format, "format", "table", "output format (table, json, csv)")
 	listCmd.Flags().BoolVar(&showHashes, "show-hashes", false, "display file hashes")
+	listCmd.Flags().StringVar(&listWallet, "wallet", "", "only list NFTs backed up for this wallet")
+	listCmd.Flags().StringArrayVar(&listAttributes, "attribute", nil, "only list NFTs with this trait, as Trait=Value (repeatable)")
+	listCmd.Flags().StringVar(&listAfter, "after", "", "only list NFTs backed up on or after this date (2026-01-31 or RFC 3339)")
+	listCmd.Flags().StringVar(&listBefore, "before", "", "only list NFTs backed up before this date (2026-01-31 or RFC 3339)")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "sort by name, date or size")
+	listCmd.Flags().BoolVar(&listReverse, "reverse", false, "reverse the sort order")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "show at most this many NFTs per page (0 shows all)")
+	listCmd.Flags().IntVar(&listPageNumber, "page", 1, "page to show with --limit")
+	_ = listCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"name", "date", "size"}, cobra.ShellCompDirectiveNoFileComp))
 }
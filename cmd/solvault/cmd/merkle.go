@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// merkleRootCmd represents the root command
+var merkleRootCmd = &cobra.Command{
+	Use:   "root",
+	Short: "Show the Merkle root that proves a wallet's whole backup",
+	Long: `Show the Merkle root over every NFT backed up for a wallet.
+
+Each leaf is one NFT's integrity digest (a hash over the file hashes in its
+integrity.json), so the root changes whenever a backup is saved with
+different files, and one hash is enough to prove the state of the whole
+wallet's backup at a point in time. Sync saves the root to
+wallets/<wallet>/merkle.json after every run, keeping earlier roots as
+history; verify puts it in proof.json with the audit path proving the NFT
+is part of it.
+
+This command recomputes the root from the integrity.json files as they are
+now and compares it with the root saved by the last backup. It does not
+rehash the files themselves; 'solvault verify --all' checks they still
+match.
+
+Example:
+  solvault root
+  solvault root --wallet 5QfQ...ZsLk
+  solvault root --proof 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault root --update`,
+	Args: cobra.NoArgs,
+	RunE: runMerkleRoot,
+}
+
+var (
+	merkleWallet string
+	merkleProof  string
+	merkleUpdate bool
+)
+
+// merkleStatus is the current and saved root of one wallet
+type merkleStatus struct {
+	Wallet   string                  `json:"wallet"`
+	Root     string                  `json:"root"`
+	Leaves   int                     `json:"leaves"`
+	Unhashed []string                `json:"unhashed,omitempty"`
+	Saved    *storage.MerkleRecord   `json:"saved,omitempty"` // Root saved by the last backup, if any
+	Matches  bool                    `json:"matches_saved"`
+	Proof    *storage.InclusionProof `json:"proof,omitempty"`
+}
+
+func runMerkleRoot(cmd *cobra.Command, args []string) error {
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	var wallets []solanago.PublicKey
+	if merkleWallet != "" {
+		if wallets, err = selectWallets(merkleWallet); err != nil {
+			return err
+		}
+	} else if wallets, err = vault.ListWallets(cmd.Context()); err != nil {
+		return err
+	}
+
+	statuses := []*merkleStatus{}
+	for _, wallet := range wallets {
+		saved, err := vault.LoadMerkle(wallet.String())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		var tree *storage.WalletMerkle
+		if merkleUpdate {
+			tree, err = vault.UpdateMerkle(wallet.String())
+		} else {
+			tree, err = vault.ComputeMerkle(wallet.String())
+		}
+		if err != nil {
+			return err
+		}
+		if len(tree.Leaves) == 0 && len(tree.Unhashed) == 0 && saved == nil {
+			continue // Only linked records, or nothing backed up
+		}
+
+		status := &merkleStatus{
+			Wallet:   tree.Wallet,
+			Root:     tree.Root,
+			Leaves:   len(tree.Leaves),
+			Unhashed: tree.Unhashed,
+		}
+		if saved != nil {
+			status.Saved = &storage.MerkleRecord{Root: saved.Root, Leaves: len(saved.Leaves), ComputedAt: saved.ComputedAt}
+			status.Matches = saved.Root == tree.Root
+		}
+		if merkleProof != "" {
+			if status.Proof, err = tree.Prove(merkleProof); err != nil && merkleWallet != "" {
+				return err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	if merkleProof != "" && !hasProof(statuses) {
+		return fmt.Errorf("mint %s is not in any wallet's Merkle tree; run 'solvault fsck --repair' if its backup has no integrity.json", merkleProof)
+	}
+
+	if jsonOutput() {
+		return printJSON(statuses)
+	}
+	if len(statuses) == 0 {
+		fmt.Println("📭 Nothing backed up yet")
+		return nil
+	}
+	for _, status := range statuses {
+		displayMerkleStatus(status)
+	}
+	return nil
+}
+
+func hasProof(statuses []*merkleStatus) bool {
+	for _, status := range statuses {
+		if status.Proof != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func displayMerkleStatus(status *merkleStatus) {
+	fmt.Printf("\n🌳 Merkle root for %s\n", status.Wallet)
+	fmt.Printf("═══════════════════════════════════════════════════════════\n")
+	fmt.Printf("Root:        %s\n", status.Root)
+	fmt.Printf("NFTs:        %d\n", status.Leaves)
+	if len(status.Unhashed) > 0 {
+		fmt.Printf("⚠️  %d backup(s) have no integrity.json and are left out; run 'solvault fsck --repair'\n", len(status.Unhashed))
+	}
+
+	switch {
+	case merkleUpdate:
+		fmt.Printf("Saved:       ✅ just now\n")
+	case status.Saved == nil:
+		fmt.Printf("Saved:       never; run 'solvault sync' or 'solvault root --update'\n")
+	case status.Matches:
+		fmt.Printf("Saved:       ✅ matches the root saved %s\n", status.Saved.ComputedAt.Local().Format(time.RFC3339))
+	default:
+		fmt.Printf("Saved:       ⚠️  backups changed since %s (saved root %s)\n",
+			status.Saved.ComputedAt.Local().Format(time.RFC3339), status.Saved.Root)
+	}
+
+	if proof := status.Proof; proof != nil {
+		fmt.Printf("\n🔎 Inclusion proof for %s\n", proof.Mint)
+		fmt.Printf("Digest:      %s\n", proof.Digest)
+		fmt.Printf("Leaf:        %d of %d\n", proof.Index+1, proof.Size)
+		for i, sibling := range proof.Path {
+			fmt.Printf("Path %-6d %s\n", i+1, sibling)
+		}
+		if proof.Verify() {
+			fmt.Printf("✅ Audit path leads to the root\n")
+		} else {
+			fmt.Printf("❌ Audit path does not lead to the root\n")
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(merkleRootCmd)
+
+	merkleRootCmd.Flags().StringVar(&merkleWallet, "wallet", "", "only show this wallet (default: every wallet in the vault)")
+	merkleRootCmd.Flags().StringVar(&merkleProof, "proof", "", "also print the inclusion proof of this mint")
+	merkleRootCmd.Flags().BoolVar(&merkleUpdate, "update", false, "save the recomputed root to merkle.json")
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite backup records written by older versions in the current layout",
+	Long: `Upgrade every nft_data.json in the vault to the record layout this version
+writes, and refresh the integrity manifests and Merkle roots that cover them.
+
+Records are upgraded on the fly whenever they are read, so running this is
+never required; it saves redoing the upgrade on every read and lets tools
+that read the JSON directly see the current layout. Records written by a
+newer version of SolVault are refused rather than rewritten.
+
+Example:
+  solvault migrate --dry-run
+  solvault migrate`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	if !jsonOutput() {
+		fmt.Printf("🗄️  Checking backup records against schema version %d...\n", storage.RecordSchemaVersion)
+	}
+	report, err := vault.MigrateRecords(cmd.Context(), dryRunMode())
+	if err != nil {
+		return fmt.Errorf("failed to migrate records: %w", err)
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		versions := make([]int, 0, len(report.ByVersion))
+		for version := range report.ByVersion {
+			versions = append(versions, version)
+		}
+		sort.Ints(versions)
+		for _, version := range versions {
+			fmt.Printf("   v%d: %d record(s)\n", version, report.ByVersion[version])
+		}
+		for _, migration := range storage.RecordMigrations {
+			if report.ByVersion[migration.From] > 0 {
+				fmt.Printf("   v%d → v%d: %s\n", migration.From, migration.From+1, migration.Description)
+			}
+		}
+		for path, reason := range report.Failed {
+			fmt.Printf("❌ %s: %s\n", path, reason)
+		}
+		switch {
+		case report.Migrated == 0:
+			fmt.Printf("✅ All %d record(s) are current\n", report.Checked)
+		case dryRunMode():
+			fmt.Printf("🧪 Dry run: %d of %d record(s) would be rewritten\n", report.Migrated, report.Checked)
+		default:
+			fmt.Printf("✅ Rewrote %d of %d record(s)\n", report.Migrated, report.Checked)
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d record(s) could not be migrated", len(report.Failed))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	supportDryRun(migrateCmd)
+}
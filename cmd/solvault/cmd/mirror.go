@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/mirror"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Mirror backups to Google Drive or Dropbox",
+	Long: `Keep an off-site copy of the vault in Google Drive and/or Dropbox.
+
+Once a provider is configured in ~/.solvault.env, sync, watch, onboard,
+inbox and reconcile upload each NFT right after it is saved locally. Files
+are uploaded exactly as stored, so an encrypted vault stays encrypted in
+the cloud, and only files that changed since the last upload are sent.
+
+Google Drive: GOOGLE_DRIVE_TOKEN, or GOOGLE_DRIVE_REFRESH_TOKEN with
+  GOOGLE_DRIVE_CLIENT_ID and GOOGLE_DRIVE_CLIENT_SECRET for unattended use;
+  GOOGLE_DRIVE_FOLDER_ID picks the parent of the SolVault folder.
+Dropbox: DROPBOX_TOKEN, or DROPBOX_REFRESH_TOKEN with DROPBOX_APP_KEY and
+  DROPBOX_APP_SECRET; DROPBOX_PATH picks the folder (default /SolVault).
+
+Example:
+  solvault mirror status
+  solvault mirror status --local-only
+  solvault mirror push`,
+}
+
+var mirrorStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which NFTs are mirrored and which are only local",
+	Args:  cobra.NoArgs,
+	RunE:  runMirrorStatus,
+}
+
+var mirrorPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload every backup that is missing or out of date in a mirror",
+	Args:  cobra.NoArgs,
+	RunE:  runMirrorPush,
+}
+
+var (
+	mirrorWallet    string
+	mirrorLocalOnly bool
+)
+
+// backupMirror returns the mirror configured in the environment, or nil
+// when no provider is set up
+func backupMirror(vault *storage.FileStorage) *mirror.Mirror {
+	providers := mirror.FromEnv()
+	if len(providers) == 0 {
+		return nil
+	}
+	return mirror.New(vault, providers...)
+}
+
+// openMirror opens the vault and its mirror, failing if none is configured
+func openMirror() (*storage.FileStorage, *mirror.Mirror, error) {
+	vault, err := openVault()
+	if err != nil {
+		return nil, nil, err
+	}
+	m := backupMirror(vault)
+	if m == nil {
+		vault.Close()
+		return nil, nil, errors.New("no mirror configured; set GOOGLE_DRIVE_TOKEN or DROPBOX_TOKEN (see 'solvault mirror --help')")
+	}
+	return vault, m, nil
+}
+
+// mirrorStatuses lists the mirror state of every NFT, limited to --wallet
+func mirrorStatuses(ctx context.Context, m *mirror.Mirror) ([]*mirror.NFTStatus, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mirror status: %w", err)
+	}
+	if mirrorWallet == "" {
+		return statuses, nil
+	}
+	filtered := []*mirror.NFTStatus{}
+	for _, status := range statuses {
+		if status.Wallet == mirrorWallet {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered, nil
+}
+
+func runMirrorStatus(cmd *cobra.Command, args []string) error {
+	vault, m, err := openMirror()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	statuses, err := mirrorStatuses(cmd.Context(), m)
+	if err != nil {
+		return err
+	}
+	if mirrorLocalOnly {
+		localOnly := []*mirror.NFTStatus{}
+		for _, status := range statuses {
+			if status.LocalOnly() {
+				localOnly = append(localOnly, status)
+			}
+		}
+		statuses = localOnly
+	}
+
+	if jsonOutput() {
+		return printJSON(statuses)
+	}
+	if len(statuses) == 0 {
+		fmt.Println("📭 No NFTs found matching criteria")
+		return nil
+	}
+
+	names := m.Names()
+	fmt.Printf("\n☁️  Mirror status (%s)\n", strings.Join(names, ", "))
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
+	fmt.Printf("%-30s %-12s", "NFT", "WALLET")
+	for _, name := range names {
+		fmt.Printf(" %-10s", strings.ToUpper(name))
+	}
+	fmt.Println()
+
+	icons := map[mirror.State]string{
+		mirror.StateMirrored: "✅ current",
+		mirror.StateStale:    "🔄 stale",
+		mirror.StateMissing:  "💻 local",
+	}
+	synced, localOnly := 0, 0
+	for _, status := range statuses {
+		name := status.Name
+		if name == "" {
+			name = status.Mint
+		}
+		fmt.Printf("%-30s %-12s", truncateString(name, 30), shortAddress(status.Wallet))
+		if status.Error != "" {
+			fmt.Printf(" ❌ %s\n", status.Error)
+			continue
+		}
+		for _, provider := range names {
+			fmt.Printf(" %-10s", icons[status.Providers[provider]])
+		}
+		fmt.Println()
+
+		if status.Synced() {
+			synced++
+		}
+		if status.LocalOnly() {
+			localOnly++
+		}
+	}
+
+	fmt.Printf("\n📊 %d NFT(s): %d fully mirrored, %d only local\n", len(statuses), synced, localOnly)
+	if synced < len(statuses) {
+		fmt.Println("💡 Run 'solvault mirror push' to upload the rest")
+	}
+	return nil
+}
+
+func runMirrorPush(cmd *cobra.Command, args []string) error {
+	vault, m, err := openMirror()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	ctx := cmd.Context()
+
+	statuses, err := mirrorStatuses(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	pushed, uploaded, failed := 0, 0, 0
+	for _, status := range statuses {
+		if ctx.Err() != nil {
+			fmt.Println("⏹️  Stopped; run mirror push again to finish")
+			break
+		}
+		if status.Synced() {
+			continue
+		}
+		name := status.Name
+		if name == "" {
+			name = status.Mint
+		}
+
+		wallet, err := solanago.PublicKeyFromBase58(status.Wallet)
+		if err == nil {
+			var mint solanago.PublicKey
+			if mint, err = solanago.PublicKeyFromBase58(status.Mint); err == nil {
+				var count int
+				count, err = m.Push(ctx, wallet, mint)
+				uploaded += count
+			}
+		}
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("☁️  %s\n", name)
+		pushed++
+	}
+
+	fmt.Printf("\n✅ Mirrored %d NFT(s), %d file(s) uploaded to %s\n", pushed, uploaded, strings.Join(m.Names(), ", "))
+	if failed > 0 {
+		return fmt.Errorf("%d NFT(s) could not be mirrored", failed)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.AddCommand(mirrorStatusCmd)
+	mirrorCmd.AddCommand(mirrorPushCmd)
+
+	mirrorCmd.PersistentFlags().StringVar(&mirrorWallet, "wallet", "", "only NFTs backed up for this wallet")
+	mirrorStatusCmd.Flags().BoolVar(&mirrorLocalOnly, "local-only", false, "only list NFTs no mirror holds yet")
+}
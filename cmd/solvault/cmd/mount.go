@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NazWright/solvault/internal/vaultfs"
+	"github.com/spf13/cobra"
+)
+
+// mountCmd represents the mount command
+var mountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the vault as a read-only filesystem (experimental)",
+	Long: `Expose the vault as a read-only FUSE filesystem organized by collection and
+NFT name, so the archive can be browsed in any file manager or fed to other
+tools without exporting it first.
+
+Layout:
+  <mountpoint>/<collection>/<nft name>/nft_data.json
+                                      /metadata.json
+                                      /proof.json
+                                      /media/...
+
+The mount is a snapshot of the vault when it was mounted. Press Ctrl+C (or
+unmount it) to stop. Requires FUSE (libfuse on Linux, macFUSE on macOS).
+
+Example:
+  mkdir -p ~/SolVaultMount
+  solvault mount ~/SolVaultMount`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMount,
+}
+
+func runMount(cmd *cobra.Command, args []string) error {
+	if !vaultfs.Supported {
+		return fmt.Errorf("mounting the vault is not supported on this platform")
+	}
+
+	mountpoint := args[0]
+	if info, err := os.Stat(mountpoint); err != nil || !info.IsDir() {
+		return fmt.Errorf("mountpoint %s must be an existing directory", mountpoint)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	fmt.Println("📚 Building vault tree...")
+	root, err := vaultfs.Build(vault)
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	ctx := cmd.Context()
+
+	fmt.Printf("🗂️  Vault mounted read-only at %s (%d collection(s))\n", mountpoint, len(root.Children))
+	fmt.Println("   Press Ctrl+C to unmount.")
+	if err := vaultfs.Mount(ctx, root, mountpoint); err != nil {
+		return err
+	}
+
+	fmt.Println("\n🛑 Vault unmounted")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mountCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NazWright/solvault/internal/notify"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/spf13/cobra"
+)
+
+// notifyCmd represents the notify command
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage alert notifications",
+	Long: `SolVault sends alerts when verification detects tampered media and when
+the watcher cannot reach the Solana RPC for longer than NOTIFY_OFFLINE_MINUTES.
+
+Email alerts are enabled by setting SMTP_HOST, SMTP_FROM and SMTP_TO in .env:
+  SMTP_HOST=smtp.example.com
+  SMTP_PORT=587
+  SMTP_TLS=starttls            (starttls, tls or none)
+  SMTP_USERNAME=alerts@example.com
+  SMTP_PASSWORD=...
+  SMTP_FROM=alerts@example.com
+  SMTP_TO=me@example.com,team@example.com
+  SMTP_SUBJECT_TEMPLATE=/path/to/subject.tmpl   (optional, Go text/template)
+  SMTP_BODY_TEMPLATE=/path/to/body.tmpl         (optional)
+
+Example:
+  solvault notify test`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test alert to every configured sink",
+	RunE:  runNotifyTest,
+}
+
+// loadNotifier returns the configured sinks, or nil if none are configured
+func loadNotifier() (notify.Multi, error) {
+	solana.LoadEnvFiles()
+	return notify.FromEnv(os.Getenv)
+}
+
+// sendNotification delivers an alert, reporting but not failing on errors
+func sendNotification(ctx context.Context, event notify.Event) {
+	sinks, err := loadNotifier()
+	if err != nil {
+		fmt.Printf("⚠️  Notifications misconfigured: %v\n", err)
+		return
+	}
+	if len(sinks) == 0 {
+		return
+	}
+
+	if err := sinks.Notify(ctx, event); err != nil {
+		fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+		return
+	}
+	fmt.Printf("📣 Alert sent via %s\n", sinks.Name())
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	sinks, err := loadNotifier()
+	if err != nil {
+		return fmt.Errorf("notifications misconfigured: %w", err)
+	}
+	if len(sinks) == 0 {
+		return fmt.Errorf("no notification sinks configured (set SMTP_HOST, SMTP_FROM and SMTP_TO)")
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	fmt.Printf("📣 Sending test alert via %s...\n", sinks.Name())
+	err = sinks.Notify(ctx, notify.Event{
+		Kind:    notify.EventTest,
+		Title:   "Test alert",
+		Message: "Notifications from SolVault are working.",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send test alert: %w", err)
+	}
+
+	fmt.Println("✅ Test alert sent")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
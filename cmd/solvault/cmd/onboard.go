@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/onboard"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// onboardCmd represents the onboard command
+var onboardCmd = &cobra.Command{
+	Use:   "onboard <wallet-address>",
+	Short: "Scan a wallet and choose what to back up, with a size and time estimate",
+	Long: `Take a first look at a wallet before backing it up.
+
+This command will:
+• Scan the wallet read-only (nothing is signed or written during the scan)
+• Sort its assets into NFTs, programmable NFTs, compressed NFTs and suspected spam
+• Estimate how much media each group holds and how long a backup would take
+• Ask which groups to back up, then back them up
+
+Suspected spam (airdrops that link to websites or promise rewards) is left
+out unless you choose it. Compressed NFTs are counted but cannot be backed up
+yet.
+
+The scan uses the DAS getAssetsByOwner API, so it needs a DAS-enabled RPC
+provider: set DAS_RPC_URL or NFT_PROVIDER, pass --das-url, or point
+SOLANA_RPC_URL at one.
+
+Example:
+  solvault onboard 5QfQ...ZsLk
+  solvault onboard 5QfQ...ZsLk --scan-only
+  solvault onboard 5QfQ...ZsLk --yes
+  solvault onboard 5QfQ...ZsLk --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runOnboard,
+}
+
+var (
+	onboardDASURL   string
+	onboardSample   int
+	onboardScanOnly bool
+	onboardYes      bool
+)
+
+func runOnboard(cmd *cobra.Command, args []string) error {
+	wallet, err := solanago.PublicKeyFromBase58(strings.TrimSpace(args[0]))
+	if err != nil {
+		return fmt.Errorf("invalid wallet address %s: %w", args[0], err)
+	}
+
+	solana.LoadEnvFiles()
+	endpoint, err := dasEndpoint(onboardDASURL, solana.PrimaryRPCURL())
+	if err != nil {
+		return err
+	}
+	if endpoint == "" {
+		return fmt.Errorf("no DAS-enabled RPC configured: set DAS_RPC_URL or NFT_PROVIDER, or pass --das-url")
+	}
+
+	ctx := cmd.Context()
+
+	fmt.Printf("🔍 Scanning %s (read-only)...\n", wallet.String())
+	assets, err := scanWallet(ctx, das.NewClient(endpoint, 0), wallet.String())
+	if err != nil {
+		return fmt.Errorf("failed to scan wallet: %w (onboard needs an RPC provider that supports the DAS API)", err)
+	}
+
+	scan := onboard.NewScan(wallet.String(), assets)
+	fmt.Printf("📏 Estimating media sizes from up to %d sample(s) per group...\n", onboardSample)
+	onboard.Estimate(ctx, scan, onboard.NewHTTPSizer(), onboardSample)
+
+	if jsonOutput() {
+		return printJSON(scan)
+	}
+	printOnboardScan(scan)
+
+	if onboardScanOnly || scan.Total == 0 {
+		return nil
+	}
+	interactive := !onboardYes && !quietOutput() && isTerminal(os.Stdin)
+	if !onboardYes && !interactive {
+		fmt.Println("\nRun again with --yes to back up the suggested groups without prompts.")
+		return nil
+	}
+
+	var reader *bufio.Reader
+	if interactive {
+		reader = bufio.NewReader(os.Stdin)
+	}
+	groups := chooseOnboardGroups(scan, reader)
+	mints, skipped := scan.BackupMints(groups)
+	if skipped > 0 {
+		fmt.Printf("ℹ️  Skipping %d compressed asset(s); they cannot be backed up yet\n", skipped)
+	}
+	if len(mints) == 0 {
+		fmt.Println("Nothing selected to back up.")
+		return nil
+	}
+
+	return backupOnboarded(ctx, wallet, mints, reader)
+}
+
+// scanWallet pages through everything the wallet holds
+func scanWallet(ctx context.Context, client *das.Client, owner string) ([]das.Asset, error) {
+	var assets []das.Asset
+	var at das.Position
+	for pages := 1; ; pages++ {
+		page, err := client.GetAssetsByOwner(ctx, owner, at, das.MaxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, page.Items...)
+		if len(assets) > 0 {
+			fmt.Printf("   %d asset(s) found\n", len(assets))
+		}
+		next, more := das.Next(at, page, das.MaxPageSize, pages)
+		if !more {
+			return assets, nil
+		}
+		at = next
+	}
+}
+
+func printOnboardScan(scan *onboard.Scan) {
+	fmt.Printf("\n🧭 What's in %s\n", scan.Wallet)
+	fmt.Printf("───────────────────────────────────────────────────────────────────\n")
+	if scan.Total == 0 {
+		fmt.Println("This wallet holds no assets.")
+		return
+	}
+
+	fmt.Printf("%-20s %7s %12s %12s\n", "GROUP", "COUNT", "MEDIA", "TIME")
+	for _, summary := range scan.Groups {
+		if !summary.Backupable {
+			fmt.Printf("%-20s %7d %12s %12s\n", summary.Label, summary.Count, "-", "not backed up")
+			continue
+		}
+		size := "~" + formatBytes(summary.EstimatedBytes)
+		if summary.Sampled == 0 {
+			size = "?"
+		}
+		fmt.Printf("%-20s %7d %12s %12s\n", summary.Label, summary.Count, size, "~"+formatEstimate(summary.EstimatedTime))
+	}
+
+	if spam := scan.Group(onboard.GroupSpam); spam != nil {
+		fmt.Printf("\n⚠️  %d asset(s) look like spam, for example:\n", spam.Count)
+		shown := 0
+		for _, asset := range scan.Assets {
+			if asset.Group != onboard.GroupSpam {
+				continue
+			}
+			fmt.Printf("   • %s (%s)\n", truncateString(asset.Name, 40), asset.SpamReason)
+			if shown++; shown == 3 {
+				break
+			}
+		}
+		fmt.Println("   Never visit links found in NFTs you did not expect to receive.")
+	}
+}
+
+// chooseOnboardGroups asks about each group that can be backed up, or takes
+// the suggested groups when there is no reader to ask with
+func chooseOnboardGroups(scan *onboard.Scan, reader *bufio.Reader) []onboard.Group {
+	var chosen []onboard.Group
+	var total int64
+	var totalTime time.Duration
+
+	fmt.Println()
+	for _, summary := range scan.Groups {
+		if !summary.Backupable {
+			continue
+		}
+		take := summary.Group.DefaultSelected()
+		if reader != nil {
+			question := fmt.Sprintf("Back up %d %s (~%s, ~%s)?", summary.Count, strings.ToLower(summary.Label),
+				formatBytes(summary.EstimatedBytes), formatEstimate(summary.EstimatedTime))
+			take = askYesNo(reader, question, take)
+		}
+		if take {
+			chosen = append(chosen, summary.Group)
+			total += summary.EstimatedBytes
+			totalTime += summary.EstimatedTime
+		}
+	}
+
+	if len(chosen) > 0 {
+		fmt.Printf("\n📦 Selected about %s of media, roughly %s of backup time\n", formatBytes(total), formatEstimate(totalTime))
+	}
+	return chosen
+}
+
+// backupOnboarded backs up the chosen mints, creating a configuration for
+// the wallet first when this is the very first run
+func backupOnboarded(ctx context.Context, wallet solanago.PublicKey, mints []string, reader *bufio.Reader) error {
+	config, err := solana.LoadConfig()
+	if err != nil {
+		if reader != nil && !askYesNo(reader, "No SolVault configuration found. Create one for this wallet?", true) {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := setupOnboardConfig(wallet); err != nil {
+			return err
+		}
+		if config, err = solana.LoadConfig(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	if !config.HasWallet(wallet) {
+		fmt.Printf("ℹ️  %s is not in your configured wallets, so sync will not keep this backup up to date\n", wallet.String())
+	}
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	source := backup.NewChainSource(client)
+	defer source.Close()
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(source, vault); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n💾 Backing up %d NFT(s) to %s\n", len(mints), vault.BaseDir())
+	opts := backup.Options{Market: market.FromEnv(), Mirror: backupMirror(vault), Thumbnails: thumbnail.SizesFromEnv(), Progress: func(msg string) { fmt.Println(msg) }}
+	counts := make(map[backup.ChangeKind]int)
+	for i, mint := range mints {
+		if ctx.Err() != nil {
+			fmt.Println("⏹️  Stopped; run onboard or sync again to finish")
+			break
+		}
+		mintKey, err := solanago.PublicKeyFromBase58(mint)
+		if err != nil {
+			continue
+		}
+		change := backup.BackupMint(ctx, vault, source, wallet, mintKey, opts)
+		counts[change.Kind]++
+		if change.Kind == backup.ChangeFailed {
+			fmt.Printf("❌ [%d/%d] %s: %s\n", i+1, len(mints), shortAddress(mint), change.Error)
+			continue
+		}
+		name := change.Name
+		if name == "" {
+			name = mint
+		}
+		fmt.Printf("✅ [%d/%d] %s\n", i+1, len(mints), truncateString(name, 50))
+	}
+
+	if tree, err := vault.UpdateMerkle(wallet.String()); err != nil {
+		fmt.Printf("⚠️  Failed to update the Merkle root: %v\n", err)
+	} else {
+		fmt.Printf("🌳 Merkle root: %s\n", tree.Root)
+	}
+
+	fmt.Printf("\n🎉 Onboarding finished: %d added, %d updated, %d unchanged, %d failed\n",
+		counts[backup.ChangeAdded], counts[backup.ChangeUpdated], counts[backup.ChangeUnchanged], counts[backup.ChangeFailed])
+	fmt.Println("   Run 'solvault sync' from now on to keep the backup current.")
+	return nil
+}
+
+// setupOnboardConfig writes a default .env and vault for wallet, as init would
+func setupOnboardConfig(wallet solanago.PublicKey) error {
+	var err error
+	if backupDir, err = getBackupDirectory(); err != nil {
+		return err
+	}
+	if err := createBackupDirectory(solana.PrimaryRPCURL()); err != nil {
+		return err
+	}
+	settings := envSettings{Wallet: wallet.String(), BackupDir: backupDir}
+	if rpcURL := os.Getenv("SOLANA_RPC_URL"); rpcURL != "" {
+		settings.RPCURL = rpcURL
+		settings.WebSocketURL = envOrDefault("SOLANA_WEBSOCKET_URL", webSocketURL(solana.PrimaryRPCURL()))
+	}
+	return createEnvFile(settings)
+}
+
+// askYesNo asks a question, returning def on an empty answer or read error
+func askYesNo(reader *bufio.Reader, question string, def bool) bool {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	fmt.Printf("%s %s ", question, hint)
+
+	line, err := reader.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	if err != nil && answer == "" {
+		return def
+	}
+	switch answer {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
+
+// formatEstimate rounds a duration for display
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		return d.Round(time.Minute).String()
+	default:
+		return d.Round(10 * time.Minute).String()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(onboardCmd)
+
+	onboardCmd.Flags().StringVar(&onboardDASURL, "das-url", "", "DAS-enabled RPC endpoint (default: DAS_RPC_URL, then NFT_PROVIDER's DAS API, then SOLANA_RPC_URL)")
+	onboardCmd.Flags().IntVar(&onboardSample, "sample", 20, "assets per group whose media is measured for the size estimate")
+	onboardCmd.Flags().BoolVar(&onboardScanOnly, "scan-only", false, "show the scan and estimate without backing anything up")
+	onboardCmd.Flags().BoolVar(&onboardYes, "yes", false, "back up the suggested groups without asking")
+}
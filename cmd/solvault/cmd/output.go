@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/NazWright/solvault/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// outputFormat is the global --output flag (text or json)
+	outputFormat string
+
+	// quiet is the global --quiet flag
+	quiet bool
+
+	// stdout is where command results are written. In JSON mode it is the
+	// only writer attached to the real standard output.
+	stdout io.Writer = os.Stdout
+)
+
+// jsonOutput reports whether structured JSON output was requested
+func jsonOutput() bool {
+	return outputFormat == "json"
+}
+
+// quietOutput reports whether --quiet was given
+func quietOutput() bool {
+	return quiet
+}
+
+// setupOutput validates --output and prepares the process for JSON and
+// quiet modes
+func setupOutput(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case "text", "":
+		outputFormat = "text"
+	case "json":
+		enableJSONOutput()
+	default:
+		return fmt.Errorf("invalid --output %q (expected text or json)", outputFormat)
+	}
+	if quiet {
+		return enableQuietOutput()
+	}
+	return nil
+}
+
+// enableQuietOutput silences progress bars and the messages printed to
+// os.Stdout, leaving errors on stderr and, with --output json, the JSON
+// document on the real stdout. Interactive prompts are skipped as if there
+// were no terminal, so cron jobs never block on one.
+func enableQuietOutput() error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", os.DevNull, err)
+	}
+	progress.SetQuiet(true)
+	os.Stdout = devNull
+	return nil
+}
+
+// enableJSONOutput switches to JSON mode.
+func enableJSONOutput() {
+	outputFormat = "json"
+	reserveStdout()
+}
+
+// reserveStdout keeps the real stdout for a command's document (JSON, CSV)
+// and sends every other message to stderr.
+// Explanation: Progress messages are printed all over the cmd and internal
+// packages with fmt.Print*. Rather than threading a writer through every
+// call, we point os.Stdout at stderr so those messages stay visible to a
+// human, while the document alone goes to the real stdout and can be piped
+// straight into jq or a file.
+func reserveStdout() {
+	if stdout != os.Stdout || os.Stdout == os.Stderr {
+		return // Already reserved
+	}
+	stdout = os.Stdout
+	os.Stdout = os.Stderr
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// currentOwner is the wallet holding a backed-up NFT right now
+type currentOwner struct {
+	Wallet    string    `json:"wallet,omitempty"` // "" when no wallet holds it
+	InVault   bool      `json:"in_vault"`         // One of the configured wallets
+	Burned    bool      `json:"burned,omitempty"` // Mint supply is zero or the mint is closed
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// lookupCurrentOwner asks the chain who holds mint, whether or not it is
+// still in one of the configured wallets
+func lookupCurrentOwner(ctx context.Context, mint solanago.PublicKey) (*currentOwner, error) {
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	owner := &currentOwner{CheckedAt: time.Now()}
+	holder, err := client.GetCurrentHolder(ctx, mint)
+	if errors.Is(err, solana.ErrNoHolder) {
+		// Metaplex burns close the token and metadata accounts but leave
+		// the mint with a supply of zero; Token-2022 mints may be closed
+		supply, err := client.GetMintSupply(ctx, mint)
+		if err != nil {
+			return nil, err
+		}
+		owner.Burned = supply == 0
+		return owner, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	owner.Wallet = holder.Owner.String()
+	owner.InVault = config.HasWallet(holder.Owner)
+	return owner, nil
+}
+
+// storedMint returns the mint address recorded in a vault backup's
+// nft_data.json
+func storedMint(nftPath string) (solanago.PublicKey, error) {
+	data, err := readVaultFile(filepath.Join(nftPath, "nft_data.json"))
+	if err != nil {
+		return solanago.PublicKey{}, fmt.Errorf("failed to read NFT data: %w", err)
+	}
+	var stored storage.StoredNFT
+	if err := json.Unmarshal(data, &stored); err != nil || stored.NFTInfo == nil {
+		return solanago.PublicKey{}, fmt.Errorf("failed to parse NFT data in %s", nftPath)
+	}
+	return stored.NFTInfo.MintAddress, nil
+}
+
+// markBurned records a burned NFT on every vault wallet's backup of it.
+// The backups themselves are kept as the historical record.
+func markBurned(ctx context.Context, mint solanago.PublicKey) (int, error) {
+	vault, err := openVault()
+	if err != nil {
+		return 0, err
+	}
+	defer vault.Close()
+
+	marked := 0
+	for _, entry := range vault.Index().List() {
+		if entry.Mint != mint.String() || entry.Status == storage.StatusBurned {
+			continue
+		}
+		wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			continue
+		}
+		if err := vault.SetStatus(ctx, wallet, mint, storage.StatusBurned); err != nil {
+			return marked, fmt.Errorf("failed to mark %s as burned: %w", mint.String(), err)
+		}
+		marked++
+	}
+	return marked, nil
+}
+
+// describeOwner renders a currentOwner for the table views
+func describeOwner(owner *currentOwner) string {
+	switch {
+	case owner.Burned:
+		return "none - the NFT was burned"
+	case owner.Wallet == "":
+		return "none - no wallet holds it"
+	case owner.InVault:
+		return owner.Wallet + " (vault wallet)"
+	default:
+		return owner.Wallet + " ⚠️  no longer in your wallets"
+	}
+}
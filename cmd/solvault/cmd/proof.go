@@ -0,0 +1,487 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/tsa"
+	"github.com/spf13/cobra"
+)
+
+// proofCmd represents the proof command
+var proofCmd = &cobra.Command{
+	Use:   "proof",
+	Short: "Generate, anchor, publish and check proofs of authenticity",
+	Long: `Manage the proof of authenticity kept with each NFT backup.
+
+A proof is proof.json in the NFT's directory: the outcome of verifying the
+backup against its integrity.json, its hashes, and the audit path to the
+wallet's Merkle root. Its lifecycle is:
+
+  generate  verify the backup and write a new proof.json
+  anchor    have an RFC 3161 timestamp authority sign proof.json's hash,
+            saved as proof.json.tsr
+  publish   upload proof.json (and its timestamp) to PUBLISH_ENDPOINT
+  show      show an NFT's proof, its timestamp, publications and history
+  verify    check a proof.json file, e.g. one someone sent you
+
+Once a proof has been anchored or published, others can hold you to those
+exact bytes, so it is never overwritten: the next generate moves it with its
+timestamp into the NFT's proofs/ directory and the new proof names it as
+previous_proof.
+
+Example:
+  solvault proof generate "Cool Cat #1234"
+  solvault proof anchor 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault proof publish 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault proof show "Cool Cat #1234"
+  solvault proof verify ./proof.json`,
+}
+
+var proofGenerateCmd = &cobra.Command{
+	Use:   "generate <mint-address-or-name>",
+	Short: "Verify an NFT backup and write its proof.json",
+	Long: `Verify an NFT backup and write its proof.json. This runs the same checks
+as 'solvault verify'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProofGenerate,
+}
+
+var proofAnchorCmd = &cobra.Command{
+	Use:   "anchor <mint-address-or-name>",
+	Short: "Timestamp an NFT's proof.json with an RFC 3161 authority",
+	Long: `Have an RFC 3161 timestamp authority sign the SHA-256 of an NFT's proof.json
+together with the time, proving the proof existed then without an on-chain
+transaction. The authority's reply is saved as proof.json.tsr and checked by
+every later verify.
+
+The authority is --tsa, TSA_URL or FreeTSA. Tokens are standard, so they can
+also be checked with:
+  openssl ts -verify -in proof.json.tsr -data proof.json -CAfile <ca.pem>
+
+Set TSA_CA_FILE to the authority's CA certificate for the signer to be
+reported as trusted; most authorities, FreeTSA included, use their own CA.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProofAnchor,
+}
+
+var proofPublishCmd = &cobra.Command{
+	Use:   "publish <mint-address-or-name>",
+	Short: "Upload an NFT's proof to the proof page endpoint",
+	Long: `Upload an NFT's proof.json, with its timestamp token when it has one, to
+the proof page endpoint (--endpoint or PUBLISH_ENDPOINT, authenticated with
+PUBLISH_API_KEY) and record the page URL in the NFT's proofs/published.json.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProofPublish,
+}
+
+var proofShowCmd = &cobra.Command{
+	Use:   "show <mint-address-or-name>",
+	Short: "Show an NFT's proof, timestamp, publications and history",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProofShow,
+}
+
+var proofVerifyCmd = &cobra.Command{
+	Use:   "verify <proof.json>",
+	Short: "Check a proof.json file",
+	Long: `Check a proof.json file on its own: its audit path must lead to the wallet
+Merkle root it names, its timestamp token (--token, or the .tsr file next to
+it) must cover it, and when the NFT is in this vault, the hashes it states
+must match the backup as it is now. Exits non-zero if any check fails.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProofVerify,
+}
+
+var (
+	proofWallet          string
+	proofPublishEndpoint string
+	proofToken           string
+)
+
+func runProofGenerate(cmd *cobra.Command, args []string) error {
+	fmt.Printf("🔍 Verifying NFT: %s\n", args[0])
+	nftPath, err := resolveNFTPath(args[0], proofWallet)
+	if err != nil {
+		return err
+	}
+	result, err := verifyNFT(cmd.Context(), nftPath)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(struct {
+			*VerificationResult
+			ProofPath string `json:"proof_path"`
+		}{result, filepath.Join(nftPath, proof.FileName)})
+	}
+	return nil
+}
+
+func runProofAnchor(cmd *cobra.Command, args []string) error {
+	nftPath, err := resolveNFTPath(args[0], proofWallet)
+	if err != nil {
+		return err
+	}
+	if !fileExists(filepath.Join(nftPath, proof.FileName)) {
+		return fmt.Errorf("no proof for %s yet; run 'solvault proof generate' first", args[0])
+	}
+
+	if stamp := checkProofTimestamp(nftPath); stamp != nil {
+		if !stamp.Valid {
+			return fmt.Errorf("proof.json no longer matches its timestamp (%s); run 'solvault proof generate' to write a new proof", stamp.Error)
+		}
+		if jsonOutput() {
+			return printJSON(stamp)
+		}
+		fmt.Printf("✅ proof.json is already anchored: %s\n", describeTimestamp(stamp))
+		return nil
+	}
+
+	info, err := anchorProof(cmd.Context(), nftPath)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(&proofTimestamp{Info: info, Valid: true})
+	}
+	return nil
+}
+
+func runProofPublish(cmd *cobra.Command, args []string) error {
+	nftPath, err := resolveNFTPath(args[0], proofWallet)
+	if err != nil {
+		return err
+	}
+	publication, err := publishNFTProof(cmd.Context(), nftPath)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(publication)
+	}
+	return nil
+}
+
+// publishNFTProof uploads an NFT's proof.json, with its timestamp token when
+// that still matches, and records where it was published
+func publishNFTProof(ctx context.Context, nftPath string) (*proof.Publication, error) {
+	solana.LoadEnvFiles()
+	endpoint := proofPublishEndpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("PUBLISH_ENDPOINT")
+	}
+	if endpoint == "" {
+		return nil, errors.New("no proof page endpoint; set PUBLISH_ENDPOINT or pass --endpoint to 'solvault proof publish'")
+	}
+
+	doc, err := os.ReadFile(filepath.Join(nftPath, proof.FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no proof for %s yet; run 'solvault proof generate' first", filepath.Base(nftPath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof: %w", err)
+	}
+
+	var token []byte
+	if stamp := checkProofTimestamp(nftPath); stamp != nil {
+		if stamp.Valid {
+			if token, err = os.ReadFile(filepath.Join(nftPath, proof.TimestampFileName)); err != nil {
+				return nil, fmt.Errorf("failed to read proof timestamp: %w", err)
+			}
+		} else {
+			fmt.Printf("⚠️  Publishing without the timestamp, which no longer matches proof.json: %s\n", stamp.Error)
+		}
+	}
+	var mint string
+	if key, err := storedMint(nftPath); err == nil {
+		mint = key.String()
+	}
+
+	fmt.Printf("🌐 Publishing proof to %s...\n", endpoint)
+	publication, err := proof.Publish(ctx, endpoint, os.Getenv("PUBLISH_API_KEY"), mint, doc, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := proof.RecordPublication(nftPath, *publication); err != nil {
+		return nil, err
+	}
+	fmt.Printf("✅ Proof published: %s\n", publication.URL)
+	return publication, nil
+}
+
+// proofSummary is everything kept about an NFT's proofs
+type proofSummary struct {
+	NFTName      string                 `json:"nft_name"`
+	NFTPath      string                 `json:"nft_path"`
+	Proof        map[string]interface{} `json:"proof,omitempty"`
+	Timestamp    *proofTimestamp        `json:"timestamp,omitempty"`
+	Publications []proof.Publication    `json:"publications,omitempty"`
+	History      []string               `json:"history,omitempty"` // Earlier proofs, oldest first
+	Attestations int                    `json:"attestations"`      // Entries in proof_chain.json
+}
+
+func runProofShow(cmd *cobra.Command, args []string) error {
+	nftPath, err := resolveNFTPath(args[0], proofWallet)
+	if err != nil {
+		return err
+	}
+
+	summary := &proofSummary{NFTName: filepath.Base(nftPath), NFTPath: nftPath}
+	if doc, err := loadJSONFile(filepath.Join(nftPath, proof.FileName)); err == nil {
+		summary.Proof = doc
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read proof: %w", err)
+	}
+	summary.Timestamp = checkProofTimestamp(nftPath)
+	if summary.Publications, err = proof.LoadPublications(nftPath); err != nil {
+		return err
+	}
+	if summary.History, err = proof.History(nftPath); err != nil {
+		return err
+	}
+	var mint string
+	if key, err := storedMint(nftPath); err == nil {
+		mint = key.String()
+	}
+	chain, err := proof.LoadChain(nftPath, mint)
+	if err != nil {
+		return err
+	}
+	summary.Attestations = len(chain.Entries)
+
+	if jsonOutput() {
+		return printJSON(summary)
+	}
+	displayProofSummary(summary, args[0])
+	return nil
+}
+
+func displayProofSummary(summary *proofSummary, identifier string) {
+	fmt.Printf("\n🧾 Proof for %s\n", summary.NFTName)
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
+	if summary.Proof == nil {
+		fmt.Printf("📭 No proof yet; run 'solvault proof generate %s'\n", identifier)
+	} else {
+		for _, field := range []struct{ label, key string }{
+			{"Status:       ", "status"},
+			{"Verified At:  ", "verified_at"},
+			{"Verified By:  ", "verified_by"},
+			{"Image Hash:   ", "image_hash"},
+			{"Metadata Hash:", "metadata_hash"},
+		} {
+			if value, ok := summary.Proof[field.key]; ok && value != "" {
+				fmt.Printf("%s %v\n", field.label, value)
+			}
+		}
+		if root, ok := summary.Proof["wallet_merkle_root"].(map[string]interface{}); ok {
+			fmt.Printf("Merkle Root:   🌳 %v\n", root["root"])
+		}
+		if summary.Timestamp != nil {
+			fmt.Printf("Proof Stamp:   %s\n", describeTimestamp(summary.Timestamp))
+		} else {
+			fmt.Printf("Proof Stamp:   not anchored; run 'solvault proof anchor %s'\n", identifier)
+		}
+	}
+
+	if len(summary.Publications) > 0 {
+		fmt.Printf("\n🌐 Published\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, publication := range summary.Publications {
+			fmt.Printf("• %s  %s\n", publication.PublishedAt.Local().Format("2006-01-02 15:04:05"), publication.URL)
+		}
+	}
+	if len(summary.History) > 0 {
+		fmt.Printf("\n📚 Earlier proofs\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, name := range summary.History {
+			fmt.Printf("• %s\n", name)
+		}
+	}
+	if summary.Attestations > 0 {
+		fmt.Printf("\n🤝 %d attestation(s) from other verifiers in %s\n", summary.Attestations, proof.ChainFileName)
+	}
+}
+
+// proofDocument is the part of proof.json that proof verify checks
+type proofDocument struct {
+	NFTName      string                  `json:"nft_name"`
+	Mint         string                  `json:"mint_address"`
+	VerifiedBy   string                  `json:"verified_by"`
+	VerifiedAt   string                  `json:"verified_at"`
+	ImageHash    string                  `json:"image_hash"`
+	MetadataHash string                  `json:"metadata_hash"`
+	Status       string                  `json:"status"`
+	WalletRoot   *storage.InclusionProof `json:"wallet_merkle_root"`
+}
+
+// proofCheck is one check of a proof file
+type proofCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// proofFileReport is the outcome of checking a proof file
+type proofFileReport struct {
+	File   string       `json:"file"`
+	Mint   string       `json:"mint,omitempty"`
+	Checks []proofCheck `json:"checks"`
+	Valid  bool         `json:"valid"`
+}
+
+func runProofVerify(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read proof: %w", err)
+	}
+	var doc proofDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse proof: %w", err)
+	}
+	if doc.Status == "" || doc.VerifiedAt == "" {
+		return fmt.Errorf("%s is not a SolVault proof (no status or verified_at)", args[0])
+	}
+
+	report := &proofFileReport{File: args[0], Mint: doc.Mint}
+	add := func(name string, ok bool, detail string) {
+		report.Checks = append(report.Checks, proofCheck{Name: name, OK: ok, Detail: detail})
+	}
+
+	add("status", doc.Status == "authentic", fmt.Sprintf("%s when verified by %s at %s", doc.Status, doc.VerifiedBy, doc.VerifiedAt))
+
+	if root := doc.WalletRoot; root != nil {
+		switch {
+		case doc.Mint != "" && root.Mint != doc.Mint:
+			add("merkle", false, fmt.Sprintf("audit path is for %s, not %s", root.Mint, doc.Mint))
+		case root.Verify():
+			add("merkle", true, fmt.Sprintf("audit path leads to %s's root %s", root.Wallet, root.Root))
+		default:
+			add("merkle", false, "audit path does not lead to the root it names")
+		}
+	}
+
+	tokenPath := proofToken
+	if tokenPath == "" {
+		tokenPath = args[0] + ".tsr"
+	}
+	if token, err := os.ReadFile(tokenPath); err == nil {
+		stamp := &proofTimestamp{}
+		roots, err := tsaRoots()
+		if err == nil {
+			digest := sha256.Sum256(data)
+			stamp.Info, err = tsa.Verify(token, digest[:], roots)
+		}
+		if err != nil {
+			stamp.Error = err.Error()
+		}
+		stamp.Valid = err == nil
+		add("timestamp", stamp.Valid, describeTimestamp(stamp))
+	} else if proofToken != "" {
+		return fmt.Errorf("failed to read timestamp token: %w", err)
+	}
+
+	// Compare with this vault's copy when it has one
+	if doc.Mint != "" {
+		if nftPath, err := resolveNFTPath(doc.Mint, ""); err == nil {
+			ok, detail := compareProofHashes(nftPath, &doc)
+			add("local copy", ok, detail)
+		}
+	}
+
+	report.Valid = true
+	for _, check := range report.Checks {
+		report.Valid = report.Valid && check.OK
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		displayProofFileReport(report, &doc)
+	}
+	if !report.Valid {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("proof %s does not hold", args[0])
+	}
+	return nil
+}
+
+// compareProofHashes checks the hashes a proof states against the backup
+// as it is now
+func compareProofHashes(nftPath string, doc *proofDocument) (bool, string) {
+	if doc.ImageHash != "" {
+		imageFile := findImageFile(nftPath)
+		if imageFile == "" {
+			return false, "the backup has no image"
+		}
+		hash, err := computeFileHash(imageFile)
+		if err != nil {
+			return false, fmt.Sprintf("failed to hash the image: %v", err)
+		}
+		if proof.NormalizeHash(hash) != proof.NormalizeHash(doc.ImageHash) {
+			return false, "the backed-up image no longer matches the proof"
+		}
+	}
+	if doc.MetadataHash != "" {
+		hash, err := computeMetadataHash(nftPath)
+		if err != nil {
+			return false, fmt.Sprintf("failed to hash the metadata: %v", err)
+		}
+		if proof.NormalizeHash(hash) != proof.NormalizeHash(doc.MetadataHash) {
+			return false, "the backed-up metadata no longer matches the proof"
+		}
+	}
+	return true, "the backup in this vault matches the proof"
+}
+
+func displayProofFileReport(report *proofFileReport, doc *proofDocument) {
+	fmt.Printf("\n🧾 Proof %s\n", report.File)
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
+	if doc.NFTName != "" {
+		fmt.Printf("NFT:          %s\n", doc.NFTName)
+	}
+	if doc.Mint != "" {
+		fmt.Printf("Mint:         %s\n", doc.Mint)
+	}
+	for _, check := range report.Checks {
+		mark := "✅"
+		if !check.OK {
+			mark = "❌"
+		}
+		fmt.Printf("%s %-11s %s\n", mark, check.Name, check.Detail)
+	}
+	if report.Valid {
+		fmt.Println("\n✅ Proof holds")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(proofCmd)
+	proofCmd.AddCommand(proofGenerateCmd)
+	proofCmd.AddCommand(proofAnchorCmd)
+	proofCmd.AddCommand(proofPublishCmd)
+	proofCmd.AddCommand(proofShowCmd)
+	proofCmd.AddCommand(proofVerifyCmd)
+
+	proofCmd.PersistentFlags().StringVar(&proofWallet, "wallet", "", "only search NFTs backed up for this wallet")
+
+	// generate shares verify's options
+	proofGenerateCmd.Flags().BoolVar(&skipOnChain, "skip-onchain", false, "skip on-chain verification (local only)")
+	proofGenerateCmd.Flags().BoolVar(&forceRecompute, "force-recompute", false, "rewrite integrity.json from the files as they are now")
+	proofGenerateCmd.Flags().BoolVar(&verifyMetadata, "check-metadata", false, "re-fetch the metadata and save a new version if it changed")
+
+	proofAnchorCmd.Flags().StringVar(&verifyTSA, "tsa", "", "timestamp authority URL (default $TSA_URL or "+tsa.DefaultURL+")")
+	proofPublishCmd.Flags().StringVar(&proofPublishEndpoint, "endpoint", "", "proof page endpoint (default $PUBLISH_ENDPOINT)")
+	proofVerifyCmd.Flags().StringVar(&proofToken, "token", "", "RFC 3161 timestamp token for the proof (default <proof.json>.tsr if present)")
+}
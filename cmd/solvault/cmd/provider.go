@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NazWright/solvault/internal/provider"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/spf13/cobra"
+)
+
+var webhookAuth string
+
+// providerCmd represents the provider command
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Show and use the enhanced APIs of your RPC provider",
+	Long: `SolVault works with plain Solana RPC. Set NFT_PROVIDER to use the richer
+APIs of an enhanced provider as well:
+
+  NFT_PROVIDER=helius     HELIUS_API_KEY
+  NFT_PROVIDER=shyft      SHYFT_API_KEY
+  NFT_PROVIDER=quicknode  QUICKNODE_URL (endpoint with the DAS add-on)
+
+With a provider, 'sync --das' and 'onboard' list assets (including compressed
+NFTs) through its DAS API without setting DAS_RPC_URL, and 'provider webhook'
+can register webhooks that push wallet activity.
+
+Example:
+  solvault provider status
+  solvault provider webhook add https://example.com/solvault-hook
+  solvault provider webhook remove 2d1c4b8e`,
+}
+
+var providerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the configured provider and its capabilities",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		solana.LoadEnvFiles()
+		p, err := provider.FromEnv()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			return printJSON(struct {
+				Name         string                `json:"name"`
+				Capabilities provider.Capabilities `json:"capabilities"`
+			}{p.Name(), p.Capabilities()})
+		}
+
+		caps := p.Capabilities()
+		fmt.Printf("🔌 NFT provider: %s\n", p.Name())
+		fmt.Printf("   %s Asset listings (DAS)\n", checkMark(caps.AssetListing))
+		fmt.Printf("   %s Compressed NFTs\n", checkMark(caps.CompressedAssets))
+		fmt.Printf("   %s Webhooks\n", checkMark(caps.Webhooks))
+		if p.Name() == "rpc" {
+			fmt.Printf("\n💡 Set NFT_PROVIDER (helius, shyft or quicknode) to use an enhanced provider\n")
+		}
+		return nil
+	},
+}
+
+var providerWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Register or remove provider webhooks for your wallets",
+}
+
+var providerWebhookAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Have the provider POST activity of every configured wallet to url",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks, name, err := providerWebhooks()
+		if err != nil {
+			return err
+		}
+		wallets, err := solana.LoadWallets()
+		if err != nil {
+			return err
+		}
+
+		hook := provider.Webhook{URL: args[0], AuthHeader: webhookAuth}
+		for _, wallet := range wallets {
+			hook.Wallets = append(hook.Wallets, wallet.String())
+		}
+
+		ctx := cmd.Context()
+		id, err := hooks.CreateWebhook(ctx, hook)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Registered %s webhook %s for %d wallet(s)\n", name, id, len(wallets))
+		fmt.Printf("💡 Remove it with: solvault provider webhook remove %s\n", id)
+		return nil
+	},
+}
+
+var providerWebhookRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a webhook registered with the provider",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks, name, err := providerWebhooks()
+		if err != nil {
+			return err
+		}
+		ctx := cmd.Context()
+		if err := hooks.DeleteWebhook(ctx, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed %s webhook %s\n", name, args[0])
+		return nil
+	},
+}
+
+// providerWebhooks returns the configured provider's webhook API
+func providerWebhooks() (provider.Webhooks, string, error) {
+	solana.LoadEnvFiles()
+	p, err := provider.FromEnv()
+	if err != nil {
+		return nil, "", err
+	}
+	hooks, ok := p.(provider.Webhooks)
+	if !ok {
+		return nil, "", fmt.Errorf("webhooks are %w (%s); use helius or shyft", provider.ErrUnsupported, p.Name())
+	}
+	return hooks, p.Name(), nil
+}
+
+// dasEndpoint picks the DAS API to use: the flag, then DAS_RPC_URL, then the
+// provider's DAS API, then fallback (usually SOLANA_RPC_URL)
+func dasEndpoint(flag, fallback string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if endpoint := os.Getenv("DAS_RPC_URL"); endpoint != "" {
+		return endpoint, nil
+	}
+	p, err := provider.FromEnv()
+	if err != nil {
+		return "", err
+	}
+	if endpoint := p.DASEndpoint(); endpoint != "" {
+		return endpoint, nil
+	}
+	return fallback, nil
+}
+
+func checkMark(ok bool) string {
+	if ok {
+		return "✅"
+	}
+	return "➖"
+}
+
+func init() {
+	rootCmd.AddCommand(providerCmd)
+	providerCmd.AddCommand(providerStatusCmd)
+	providerCmd.AddCommand(providerWebhookCmd)
+	providerWebhookCmd.AddCommand(providerWebhookAddCmd)
+	providerWebhookCmd.AddCommand(providerWebhookRemoveCmd)
+
+	providerWebhookAddCmd.Flags().StringVar(&webhookAuth, "auth", "", "value the provider sends in the Authorization header")
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneKeepVersions    int
+	pruneKeepTransferred int
+	pruneBurned          bool
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Reclaim space from orphaned media, partial downloads and old versions",
+	Long: `Remove what the vault no longer needs and report the space reclaimed:
+
+  • media files no manifest lists
+  • partial downloads, leftover temp files and saves that never committed
+  • metadata versions beyond --keep-versions (the current one is always kept)
+  • media of NFTs transferred more than --transferred-days ago (their
+    records are kept, and sync downloads the media again if they return)
+  • backups of burned NFTs, with --burned
+
+--keep-versions and --transferred-days default to the retention policy in
+RETENTION_KEEP_VERSIONS and RETENTION_TRANSFERRED_DAYS. With RETENTION_SCHEDULE
+set (e.g. @daily), 'solvault watch' applies the policy on that schedule too.
+
+Use --dry-run to see what would be removed.
+
+Example:
+  solvault prune --dry-run
+  solvault prune --keep-versions 3
+  solvault prune --transferred-days 90
+  solvault prune --burned --yes`,
+	RunE: runPrune,
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	opts, err := retentionOptions()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("keep-versions") {
+		if pruneKeepVersions < 0 {
+			return fmt.Errorf("--keep-versions must be 0 (keep all) or more")
+		}
+		opts.KeepVersions = pruneKeepVersions
+	}
+	if cmd.Flags().Changed("transferred-days") {
+		if pruneKeepTransferred < 0 {
+			return fmt.Errorf("--transferred-days must be 0 (keep all) or more")
+		}
+		opts.KeepTransferred = time.Duration(pruneKeepTransferred) * 24 * time.Hour
+	}
+	opts.Burned, opts.DryRun = pruneBurned, true
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	plan, err := vault.Prune(cmd.Context(), opts)
+	if err != nil {
+		return fmt.Errorf("failed to scan vault: %w", err)
+	}
+
+	if dryRunMode() || len(plan.Items) == 0 {
+		return printPruneReport(plan)
+	}
+
+	items := make([]string, len(plan.Items))
+	for i, item := range plan.Items {
+		items[i] = fmt.Sprintf("%s (%s, %s)", item.Path, item.Kind, formatBytes(item.Bytes))
+	}
+	if !confirmBulk(cmd, bulkPlan{Action: "delete", Items: items}) {
+		return nil
+	}
+
+	opts.DryRun = false
+	report, err := vault.Prune(cmd.Context(), opts)
+	if report != nil {
+		if printErr := printPruneReport(report); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune vault: %w", err)
+	}
+	return nil
+}
+
+func printPruneReport(report *storage.PruneReport) error {
+	if jsonOutput() {
+		return printJSON(report)
+	}
+	if len(report.Items) == 0 {
+		fmt.Printf("✅ Nothing to prune\n")
+		return nil
+	}
+
+	counts := make(map[storage.PruneKind]int)
+	for _, item := range report.Items {
+		counts[item.Kind]++
+		if report.DryRun {
+			fmt.Printf("   • %-15s %-10s %s\n", item.Kind, formatBytes(item.Bytes), item.Path)
+		}
+	}
+	fmt.Println()
+	for _, kind := range []storage.PruneKind{storage.PruneOrphanedMedia, storage.PrunePartial, storage.PruneOldVersion, storage.PruneTransferred, storage.PruneBurned} {
+		if counts[kind] > 0 {
+			fmt.Printf("   %-15s %d\n", kind, counts[kind])
+		}
+	}
+
+	if report.DryRun {
+		fmt.Printf("🧪 Dry run: would reclaim %s from %d item(s)\n", formatBytes(report.Reclaimed), len(report.Items))
+		return nil
+	}
+	fmt.Printf("🧹 Reclaimed %s from %d item(s)\n", formatBytes(report.Reclaimed), len(report.Items))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().IntVar(&pruneKeepVersions, "keep-versions", 0, "metadata versions to keep per NFT, newest first (0 keeps all)")
+	pruneCmd.Flags().IntVar(&pruneKeepTransferred, "transferred-days", 0, "days to keep the media of transferred NFTs (0 keeps it)")
+	pruneCmd.Flags().BoolVar(&pruneBurned, "burned", false, "also delete the backups of burned NFTs")
+	guardDestructive(pruneCmd)
+}
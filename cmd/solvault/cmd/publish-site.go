@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/site"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// publishSiteCmd represents the publish-site command
+var publishSiteCmd = &cobra.Command{
+	Use:   "publish-site",
+	Short: "Render the vault's proofs as a static website",
+	Long: `Render a static HTML site with one proof page per NFT: its image, metadata
+and traits, the hashes from integrity.json and proof.json, the verification
+status of its last proof, and a QR code linking to the mint on Solana
+Explorer. An index page lists every NFT.
+
+Pages show the proof as it was last written, with the time it was verified;
+run 'solvault verify --all' first to refresh them. NFTs that were never
+verified get a page marked "Not verified". proof.json and its timestamp
+token, when it still matches, are copied next to each page for download.
+
+All links are relative, so the directory can be published as it is with
+GitHub Pages, any static web server, or 'ipfs add -r'. Pages show the
+largest thumbnail of each NFT; --originals also copies the original media.
+The output directory must be empty or a site written by an earlier run,
+whose pages are replaced.
+
+Example:
+  solvault publish-site
+  solvault publish-site --out docs --title "Lion Collection proofs"
+  solvault publish-site --wallet 5QfQ...ZsLk --originals`,
+	Args: cobra.NoArgs,
+	RunE: runPublishSite,
+}
+
+var (
+	siteOut       string
+	siteTitle     string
+	siteWallet    string
+	siteCluster   string
+	siteOriginals bool
+)
+
+func runPublishSite(cmd *cobra.Command, args []string) error {
+	var wallet string
+	if siteWallet != "" {
+		wallets, err := selectWallets(siteWallet)
+		if err != nil {
+			return err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	s := &site.Site{Title: siteTitle, Cluster: siteCluster, GeneratedAt: time.Now().UTC()}
+	unverified := 0
+	for _, indexed := range vault.Index().List() {
+		if indexed.PrimaryWallet != "" || (wallet != "" && indexed.Wallet != wallet) {
+			continue // Linked custody records share the primary's backup
+		}
+		walletKey, err1 := solanago.PublicKeyFromBase58(indexed.Wallet)
+		mintKey, err2 := solanago.PublicKeyFromBase58(indexed.Mint)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stored, err := vault.GetNFT(cmd.Context(), walletKey, mintKey)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping %s: %v\n", indexed.Mint, err)
+			continue
+		}
+
+		page, err := buildProofPage(vault, vault.NFTDir(walletKey, mintKey), stored)
+		if err != nil {
+			return fmt.Errorf("failed to build page for %s: %w", indexed.Mint, err)
+		}
+		page.Wallet = indexed.Wallet
+		if page.Name == "" {
+			page.Name = indexed.Name
+		}
+		if page.Status == "" {
+			unverified++
+		}
+		s.Pages = append(s.Pages, page)
+	}
+	sort.Slice(s.Pages, func(i, j int) bool {
+		return strings.ToLower(s.Pages[i].Name) < strings.ToLower(s.Pages[j].Name)
+	})
+
+	fmt.Printf("🌐 Rendering %d proof page(s)...\n", len(s.Pages))
+	if err := site.Write(siteOut, s); err != nil {
+		return err
+	}
+
+	abs, _ := filepath.Abs(siteOut)
+	if jsonOutput() {
+		return printJSON(struct {
+			Out        string `json:"out"`
+			Pages      int    `json:"pages"`
+			Unverified int    `json:"unverified"`
+		}{abs, len(s.Pages), unverified})
+	}
+	fmt.Printf("✅ Wrote %d proof page(s) to %s\n", len(s.Pages), abs)
+	if unverified > 0 {
+		fmt.Printf("⚠️  %d NFT(s) have no proof yet; run 'solvault verify --all' and publish again\n", unverified)
+	}
+	fmt.Printf("   Publish it with GitHub Pages, any static web server, or 'ipfs add -r %s'\n", siteOut)
+	return nil
+}
+
+// buildProofPage collects what one NFT's proof page shows
+func buildProofPage(vault *storage.FileStorage, nftDir string, stored *storage.StoredNFT) (*site.Page, error) {
+	info := stored.NFTInfo
+	page := &site.Page{Mint: info.MintAddress.String()}
+	if md := info.Metadata; md != nil {
+		page.Name = md.Name
+		page.Symbol = md.Symbol
+		page.Description = md.Description
+		page.Collection = md.Collection.Name
+		for _, attr := range md.Attributes {
+			page.Attributes = append(page.Attributes, site.Attribute{Trait: attr.TraitType, Value: fmt.Sprint(attr.Value)})
+		}
+	}
+
+	// The last proof, with its timestamp only while it still matches
+	if data, err := os.ReadFile(filepath.Join(nftDir, proof.FileName)); err == nil {
+		var doc proofDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse proof: %w", err)
+		}
+		page.Proof = data
+		page.Status = doc.Status
+		page.VerifiedAt = doc.VerifiedAt
+		page.VerifiedBy = doc.VerifiedBy
+		page.ImageHash = doc.ImageHash
+		page.MetadataHash = doc.MetadataHash
+		if doc.WalletRoot != nil {
+			page.MerkleRoot = doc.WalletRoot.Root
+		}
+		if stamp := checkProofTimestamp(nftDir); stamp != nil && stamp.Valid {
+			if page.ProofToken, err = os.ReadFile(filepath.Join(nftDir, proof.TimestampFileName)); err != nil {
+				return nil, fmt.Errorf("failed to read proof timestamp: %w", err)
+			}
+			page.Timestamp = fmt.Sprintf("%s by %s", stamp.Time.UTC().Format(time.RFC3339), stamp.Authority)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read proof: %w", err)
+	}
+
+	if integrity, err := vault.ReadIntegrity(nftDir); err == nil {
+		for path, digest := range integrity.Files {
+			page.Files = append(page.Files, site.File{Path: path, SHA256: digest.SHA256, Size: digest.Size})
+		}
+		sort.Slice(page.Files, func(i, j int) bool { return page.Files[i].Path < page.Files[j].Path })
+	}
+	if metadata, err := vault.ReadFile(filepath.Join(nftDir, "metadata.json")); err == nil {
+		page.Metadata = metadata
+	}
+
+	if err := addPageMedia(vault, nftDir, info, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// addPageMedia picks the image shown on a page: the largest thumbnail of the
+// first media file that has one (which covers video posters), or else the
+// first image itself
+func addPageMedia(vault *storage.FileStorage, nftDir string, info *fetcher.NFTInfo, page *site.Page) error {
+	read := func(name string) ([]byte, error) {
+		data, err := vault.ReadFile(filepath.Join(nftDir, "media", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read media %s: %w", name, err)
+		}
+		return data, nil
+	}
+
+	var shown *fetcher.MediaFile
+	for _, media := range info.MediaFiles {
+		if len(media.Thumbnails) > 0 {
+			largest := media.Thumbnails[0]
+			for _, thumb := range media.Thumbnails {
+				if thumb.Size > largest.Size {
+					largest = thumb
+				}
+			}
+			data, err := read(largest.Filename)
+			if err != nil {
+				return err
+			}
+			page.Image, page.ImageName = data, "image"+filepath.Ext(largest.Filename)
+			shown = media
+			break
+		}
+	}
+	if shown == nil {
+		for _, media := range info.MediaFiles {
+			if media.MediaType == fetcher.MediaTypeImage {
+				data, err := read(media.Filename)
+				if err != nil {
+					return err
+				}
+				page.Image, page.ImageName = data, "image"+filepath.Ext(media.Filename)
+				shown = media
+				break
+			}
+		}
+	}
+
+	// Backups from before media_files recorded types, as verify finds them
+	if page.ImageName == "" {
+		if image := findImageFile(nftDir); image != "" {
+			data, err := vault.ReadFile(image)
+			if err != nil {
+				return fmt.Errorf("failed to read image: %w", err)
+			}
+			page.Image, page.ImageName = data, "image"+filepath.Ext(image)
+		}
+	}
+
+	switch {
+	case !siteOriginals || shown == nil:
+	case len(shown.Thumbnails) == 0:
+		page.Original, page.OriginalName = page.Image, page.ImageName // Already the original
+	default:
+		data, err := read(shown.Filename)
+		if err != nil {
+			return err
+		}
+		page.Original, page.OriginalName = data, "original"+filepath.Ext(shown.Filename)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(publishSiteCmd)
+
+	publishSiteCmd.Flags().StringVar(&siteOut, "out", "solvault-site", "directory the site is written to")
+	publishSiteCmd.Flags().StringVar(&siteTitle, "title", "SolVault proofs of authenticity", "site title")
+	publishSiteCmd.Flags().StringVar(&siteWallet, "wallet", "", "only include NFTs backed up for this wallet")
+	publishSiteCmd.Flags().StringVar(&siteCluster, "cluster", "mainnet-beta", "Solana cluster for explorer links (mainnet-beta, devnet, testnet)")
+	publishSiteCmd.Flags().BoolVar(&siteOriginals, "originals", false, "also copy each NFT's original media next to its page")
+	_ = publishSiteCmd.MarkFlagDirname("out")
+}
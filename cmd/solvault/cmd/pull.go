@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/pull"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/spf13/cobra"
+)
+
+// pullCmd copies another vault's backups into this one
+var pullCmd = &cobra.Command{
+	Use:   "pull [source]",
+	Short: "Copy new and changed backups from another vault into this one",
+	Long: `Keep this vault a copy of one maintained on another machine, e.g. a
+cold-storage laptop following an always-on server that runs sync or watch.
+
+Only NFTs that are missing here or changed at the source are fetched: each
+backup's integrity.json is compared first, and only files whose hash differs
+are downloaded. Every file is checked against the source's manifest before
+it replaces anything, so a transfer error never reaches the vault. Backups
+that exist only here are left alone, and backups still being written at the
+source are skipped until the next pull.
+
+The source (default SOLVAULT_PULL_SOURCE) is one of:
+  https://server:8080     a vault served by 'solvault serve'; set
+                          SOLVAULT_PULL_TOKEN to a viewer token if it
+                          requires one
+  ssh://user@host/path    a vault directory read with the system ssh client
+                          (keys, agent and ~/.ssh/config apply)
+  s3://bucket/prefix      a vault copied to S3, e.g. with 'aws s3 sync';
+                          AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+                          AWS_SESSION_TOKEN and AWS_REGION apply, and
+                          AWS_ENDPOINT_URL selects an S3-compatible store
+  /mnt/server/SolVault    a vault directory, e.g. a network mount
+
+Files are copied as stored: an encrypted source stays encrypted, and this
+vault needs the same SOLVAULT_PASSPHRASE or SOLVAULT_KEY_FILE to read it.
+Proofs are not copied; run 'solvault verify' here to write this vault's own.
+
+Example:
+  solvault pull https://vault.home.lan:8080
+  solvault pull ssh://me@vault.home.lan/home/me/SolVaultBackups
+  solvault pull s3://my-backups/SolVault
+  solvault pull --wallet 5QfQ...ZsLk
+  solvault pull --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPull,
+}
+
+var pullWallet string
+
+func runPull(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	solana.LoadEnvFiles()
+	location := strings.TrimSpace(os.Getenv("SOLVAULT_PULL_SOURCE"))
+	if len(args) == 1 {
+		location = args[0]
+	}
+	if location == "" {
+		return errors.New("no source vault; pass one or set SOLVAULT_PULL_SOURCE")
+	}
+	src, err := pull.Open(location, os.Getenv("SOLVAULT_PULL_TOKEN"))
+	if err != nil {
+		return err
+	}
+
+	var wallet string
+	if pullWallet != "" {
+		address, err := solana.ResolveWallet(pullWallet)
+		if err != nil {
+			return err
+		}
+		wallet = address.String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	opts := pull.Options{Wallet: wallet, DryRun: dryRunMode()}
+	if !jsonOutput() {
+		fmt.Printf("⬇️  Pulling from %s...\n", src)
+		opts.Logf = func(format string, args ...interface{}) {
+			fmt.Printf(format+"\n", args...)
+		}
+	}
+	summary, err := pull.Pull(cmd.Context(), src, vault, opts)
+	if err != nil && summary.NFTs == 0 {
+		return err
+	}
+	if jsonOutput() {
+		if jsonErr := printJSON(summary); jsonErr != nil {
+			return jsonErr
+		}
+	} else {
+		printPullSummary(summary)
+	}
+	if err != nil {
+		return err
+	}
+	if summary.Interrupted {
+		return errors.New("pull interrupted; run it again to finish")
+	}
+	if len(summary.Failed) > 0 {
+		return fmt.Errorf("%d NFT(s) could not be pulled", len(summary.Failed))
+	}
+	return nil
+}
+
+func printPullSummary(summary *pull.Summary) {
+	for _, skipped := range summary.Skipped {
+		fmt.Printf("⏭️  Skipped %s\n", skipped)
+	}
+	if summary.DryRun {
+		fmt.Printf("\n🧪 Dry run: would add %d NFT(s) and update %d (%d file(s), %s); %d unchanged; nothing was changed\n",
+			summary.Added, summary.Updated, summary.Files, formatBytes(summary.Bytes), summary.Unchanged)
+		return
+	}
+	fmt.Printf("\n✅ Pulled %d new and %d updated NFT(s) (%d file(s), %s); %d unchanged\n",
+		summary.Added, summary.Updated, summary.Files, formatBytes(summary.Bytes), summary.Unchanged)
+	if summary.Interrupted {
+		fmt.Println("⏹️  Stopped; run pull again to finish")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+	pullCmd.Flags().StringVar(&pullWallet, "wallet", "", "only pull NFTs this wallet holds or held")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/notify"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// defaultMinFree is the free disk space backups leave unless SOLVAULT_MIN_FREE
+// says otherwise
+const defaultMinFree = "1GB"
+
+// vaultLimits combines a selected tenant's limits with the vault size limit
+// (SOLVAULT_MAX_SIZE) and free-space threshold (SOLVAULT_MIN_FREE)
+func vaultLimits(tenant *storage.Tenant) (storage.Limits, error) {
+	solana.LoadEnvFiles()
+	var limits storage.Limits
+	if tenant != nil {
+		limits = tenant.Limits
+	}
+
+	// A tenant's own max_bytes is the limit of their vault when set
+	if value := strings.TrimSpace(os.Getenv("SOLVAULT_MAX_SIZE")); value != "" && limits.MaxBytes == 0 {
+		maxSize, err := parseBytes(value)
+		if err != nil {
+			return limits, fmt.Errorf("invalid SOLVAULT_MAX_SIZE: %w", err)
+		}
+		limits.MaxBytes = maxSize
+	}
+	minFree, err := parseBytes(envOrDefault("SOLVAULT_MIN_FREE", defaultMinFree))
+	if err != nil {
+		return limits, fmt.Errorf("invalid SOLVAULT_MIN_FREE: %w", err)
+	}
+	limits.MinFreeBytes = minFree
+	return limits, nil
+}
+
+// storageAlert describes a sync that was stopped by low disk space or that
+// left the vault at its size limit, or returns nil when neither happened
+func storageAlert(wallet string, summary *backup.Summary, err error) *notify.Event {
+	switch {
+	case errors.Is(err, storage.ErrLowDiskSpace):
+		return &notify.Event{
+			Kind:     notify.EventStorage,
+			Priority: notify.PriorityHigh,
+			Title:    "Backups stopped: the vault's disk is almost full",
+			Message: "SolVault stopped backing up before the disk filled. Free up space or move the vault, " +
+				"or lower SOLVAULT_MIN_FREE, and backups resume on the next sync.",
+			Fields: map[string]string{"Wallet": wallet, "Reason": err.Error()},
+		}
+	case summary != nil && summary.VaultFull != "":
+		return &notify.Event{
+			Kind:    notify.EventStorage,
+			Title:   "The vault has reached its size limit",
+			Message: "New NFTs are no longer backed up; existing backups are still updated. Raise SOLVAULT_MAX_SIZE or prune the vault.",
+			Fields:  map[string]string{"Wallet": wallet, "Reason": summary.VaultFull},
+		}
+	}
+	return nil
+}
+
+// alertStorage sends the storage alert of one watch poll, once until a poll
+// finds the vault healthy again, and reports whether an alert stands
+func alertStorage(ctx context.Context, sinks notify.Multi, alerted bool, wallet string, summary *backup.Summary, err error) bool {
+	event := storageAlert(wallet, summary, err)
+	if event == nil {
+		// A poll that failed for another reason says nothing about the disk
+		return alerted && err != nil
+	}
+	if alerted || len(sinks) == 0 {
+		return true
+	}
+	if err := sinks.Notify(ctx, *event); err != nil {
+		fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+		return false
+	}
+	fmt.Printf("📣 Storage alert sent via %s\n", sinks.Name())
+	return true
+}
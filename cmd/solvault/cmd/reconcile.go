@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	"github.com/spf13/cobra"
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Compare on-chain holdings with the vault and fix differences",
+	Long: `Check every configured wallet's current on-chain holdings against the
+vault's held records and list three kinds of discrepancy:
+
+• Missing     held on-chain but not backed up
+• Gone        backed up as held but no longer in the wallet
+• Mismatched  held by a different configured wallet than the vault records
+
+In a terminal you can then fix a bucket with a single key. Use --fix to
+apply fixes non-interactively (e.g. from cron).
+
+Example:
+  solvault reconcile
+  solvault reconcile --wallet 5QfQ...ZsLk
+  solvault reconcile --fix missing,gone
+  solvault reconcile --fix all --output json`,
+	RunE: runReconcile,
+}
+
+var (
+	reconcileWallet string
+	reconcileFix    []string
+)
+
+// reconcileActions maps the one-key prompt to buckets
+var reconcileActions = map[string]backup.Bucket{
+	"b": backup.BucketMissing,
+	"m": backup.BucketGone,
+	"o": backup.BucketMismatched,
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	buckets, err := parseReconcileFix(reconcileFix)
+	if err != nil {
+		return err
+	}
+
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	wallets, err := selectWallets(reconcileWallet)
+	if err != nil {
+		return err
+	}
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	source := backup.NewChainSource(client)
+	defer source.Close()
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(source, vault); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	fmt.Printf("🔍 Reconciling %d wallet(s) against the vault...\n", len(wallets))
+	report, err := backup.Reconcile(ctx, vault, source, wallets)
+	if err != nil {
+		return err
+	}
+
+	opts := backup.Options{Market: market.FromEnv(), Mirror: backupMirror(vault), Thumbnails: thumbnail.SizesFromEnv(), Progress: func(msg string) { fmt.Println(msg) }}
+
+	// Non-interactive: apply the requested fixes and report the result
+	if len(buckets) > 0 || jsonOutput() || quietOutput() || !isTerminal(os.Stdin) {
+		fixes := make(map[backup.Bucket][]backup.Change)
+		for _, bucket := range buckets {
+			changes, err := backup.Fix(ctx, vault, source, report, bucket, opts)
+			if err != nil {
+				return err
+			}
+			fixes[bucket] = changes
+		}
+
+		if jsonOutput() {
+			return printJSON(map[string]interface{}{
+				"report": report,
+				"fixes":  fixes,
+			})
+		}
+		printReconcileReport(report)
+		for _, bucket := range buckets {
+			printReconcileFixes(bucket, fixes[bucket])
+		}
+		return nil
+	}
+
+	// Interactive: one key per bucket until everything is fixed or the user quits
+	reader := bufio.NewReader(os.Stdin)
+	fixed := make(map[backup.Bucket]bool)
+	for {
+		printReconcileReport(report)
+		if report.Clean() {
+			return nil
+		}
+
+		var options []string
+		if len(report.Missing) > 0 && !fixed[backup.BucketMissing] {
+			options = append(options, "[b] back up missing")
+		}
+		if len(report.Gone) > 0 && !fixed[backup.BucketGone] {
+			options = append(options, "[m] mark gone as transferred/burned")
+		}
+		if len(report.Mismatched) > 0 && !fixed[backup.BucketMismatched] {
+			options = append(options, "[o] move mismatched to their current wallet")
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		fmt.Printf("\n%s  [a] all  [q] quit: ", strings.Join(options, "  "))
+
+		line, err := reader.ReadString('\n')
+		key := strings.ToLower(strings.TrimSpace(line))
+		if err != nil || key == "q" || key == "" {
+			return nil
+		}
+
+		var selected []backup.Bucket
+		if key == "a" {
+			selected = []backup.Bucket{backup.BucketMissing, backup.BucketGone, backup.BucketMismatched}
+		} else if bucket, ok := reconcileActions[key]; ok {
+			selected = []backup.Bucket{bucket}
+		} else {
+			fmt.Printf("⚠️  Unknown option %q\n", key)
+			continue
+		}
+
+		for _, bucket := range selected {
+			if fixed[bucket] {
+				continue
+			}
+			changes, err := backup.Fix(ctx, vault, source, report, bucket, opts)
+			if err != nil {
+				return err
+			}
+			fixed[bucket] = true
+			printReconcileFixes(bucket, changes)
+		}
+
+		// Re-check so the next prompt reflects the fixes
+		if report, err = backup.Reconcile(ctx, vault, source, wallets); err != nil {
+			return err
+		}
+	}
+}
+
+// parseReconcileFix turns --fix values into buckets
+func parseReconcileFix(values []string) ([]backup.Bucket, error) {
+	var buckets []backup.Bucket
+	for _, value := range values {
+		switch backup.Bucket(strings.ToLower(strings.TrimSpace(value))) {
+		case "all":
+			return []backup.Bucket{backup.BucketMissing, backup.BucketGone, backup.BucketMismatched}, nil
+		case backup.BucketMissing:
+			buckets = append(buckets, backup.BucketMissing)
+		case backup.BucketGone:
+			buckets = append(buckets, backup.BucketGone)
+		case backup.BucketMismatched, "owners":
+			buckets = append(buckets, backup.BucketMismatched)
+		default:
+			return nil, fmt.Errorf("unknown --fix value %q (use missing, gone, mismatched or all)", value)
+		}
+	}
+	return buckets, nil
+}
+
+func printReconcileReport(report *backup.Report) {
+	fmt.Printf("\n📊 Reconciliation: %d NFT(s) held on-chain across %d wallet(s)\n", report.Held, len(report.Wallets))
+	fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+
+	if report.Clean() {
+		fmt.Println("✅ Vault matches on-chain holdings")
+	}
+
+	printDiscrepancies("🆕 On-chain but not backed up", report.Missing, func(d backup.Discrepancy) string {
+		return d.Wallet
+	})
+	printDiscrepancies("📤 Backed up but no longer held", report.Gone, func(d backup.Discrepancy) string {
+		return d.Wallet
+	})
+	printDiscrepancies("🔀 Held by a different wallet", report.Mismatched, func(d backup.Discrepancy) string {
+		return fmt.Sprintf("%s → %s", shortAddress(d.Wallet), shortAddress(d.ChainWallet))
+	})
+
+	for _, wallet := range report.Partial {
+		fmt.Printf("⏸️  Listing for %s was incomplete; gone NFTs were not checked\n", wallet)
+	}
+}
+
+func printDiscrepancies(title string, list []backup.Discrepancy, where func(backup.Discrepancy) string) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("\n%s (%d)\n", title, len(list))
+	for _, d := range list {
+		name := d.Name
+		if name == "" {
+			name = d.Mint
+		}
+		fmt.Printf("   • %-40s %s\n", truncateString(name, 40), where(d))
+	}
+}
+
+func printReconcileFixes(bucket backup.Bucket, changes []backup.Change) {
+	failed := 0
+	for _, change := range changes {
+		if change.Kind == backup.ChangeFailed {
+			failed++
+			fmt.Printf("❌ %s: %s\n", change.Mint, change.Error)
+		}
+	}
+	fmt.Printf("✅ Fixed %d %s NFT(s)", len(changes)-failed, bucket)
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+}
+
+// shortAddress abbreviates a base58 address to its first and last four characters
+func shortAddress(address string) string {
+	if len(address) <= 10 {
+		return address
+	}
+	return address[:4] + "…" + address[len(address)-4:]
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+
+	reconcileCmd.Flags().StringVar(&reconcileWallet, "wallet", "", "only reconcile this wallet (default: all configured wallets)")
+	reconcileCmd.Flags().StringSliceVar(&reconcileFix, "fix", nil, "fix these buckets without prompting: missing, gone, mismatched or all")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/registry"
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// registerCmd represents the register command
+var registerCmd = &cobra.Command{
+	Use:   "register <mint-address>",
+	Short: "Publish a signed attestation for an NFT to the verification registry",
+	Long: `Hash your backed-up copy of an NFT, sign (mint, media hash, proof hash,
+timestamp) with your Solana keypair and submit it to a community
+verification registry.
+
+The registry is configured with REGISTRY_ENDPOINT (and optionally
+REGISTRY_API_KEY) in .env, or with --registry.
+
+Example:
+  solvault register 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault register <mint> --registry https://registry.example.com --keypair ./verifier.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegister,
+}
+
+// lookupCmd represents the lookup command
+var lookupCmd = &cobra.Command{
+	Use:   "lookup <mint-address>",
+	Short: "Check which independent parties have attested an NFT",
+	Long: `Query the verification registry for every attestation of a mint.
+
+Each signature is checked locally and attestations are grouped by media hash,
+so buyers can see whether independent verifiers saw the same artwork bytes.
+
+Example:
+  solvault lookup 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault lookup <mint> --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLookup,
+}
+
+var (
+	registryEndpoint string
+	registryAPIKey   string
+	registerKeypair  string
+)
+
+// newRegistryClient resolves the registry from flags, then .env / environment
+func newRegistryClient() *registry.Client {
+	solana.LoadEnvFiles()
+
+	endpoint := registryEndpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("REGISTRY_ENDPOINT")
+	}
+	apiKey := registryAPIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("REGISTRY_API_KEY")
+	}
+	return registry.NewClient(endpoint, apiKey)
+}
+
+func runRegister(cmd *cobra.Command, args []string) error {
+	mint, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid mint address: %w", err)
+	}
+
+	key, err := loadKeypair(registerKeypair)
+	if err != nil {
+		return err
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	fmt.Printf("🔐 Hashing local copy of %s...\n", mint.String())
+	att, err := proof.FromVault(ctx, vault, mint)
+	if err != nil {
+		return err
+	}
+	if err := att.Sign(key); err != nil {
+		return err
+	}
+
+	fmt.Println("📤 Publishing to verification registry...")
+	record, err := newRegistryClient().Publish(ctx, att)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(record)
+	}
+
+	fmt.Println("✅ Attestation published")
+	fmt.Printf("   Verifier:    %s\n", att.Verifier)
+	fmt.Printf("   Media hash:  %s\n", att.MediaHash)
+	fmt.Printf("   Proof hash:  %s\n", att.ProofHash)
+	if record.ID != "" {
+		fmt.Printf("   Registry ID: %s\n", record.ID)
+	}
+	return nil
+}
+
+func runLookup(cmd *cobra.Command, args []string) error {
+	mint, err := solanago.PublicKeyFromBase58(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid mint address: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+	defer cancel()
+
+	fmt.Printf("🔍 Looking up attestations for %s...\n", mint.String())
+	records, err := newRegistryClient().Lookup(ctx, mint.String())
+	if err != nil {
+		return err
+	}
+	summaries := registry.Summarize(records)
+
+	if jsonOutput() {
+		return printJSON(map[string]interface{}{
+			"mint":         mint.String(),
+			"attestations": records,
+			"media":        summaries,
+		})
+	}
+
+	if len(records) == 0 {
+		fmt.Println("📭 No attestations found for this mint")
+		return nil
+	}
+
+	fmt.Printf("\n📜 Attestations (%d)\n", len(records))
+	fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+	for _, record := range records {
+		mark := "✅"
+		if !record.Verified {
+			mark = "❌"
+		}
+		fmt.Printf("%s %s  %s  media %s\n", mark, record.Timestamp.Format("2006-01-02 15:04"),
+			truncateString(record.Verifier, 14), truncateString(proof.NormalizeHash(record.MediaHash), 18))
+		if record.VerifyError != "" {
+			fmt.Printf("   invalid signature: %s\n", record.VerifyError)
+		}
+	}
+
+	fmt.Printf("\n🖼️  Media agreement\n")
+	fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+	for _, summary := range summaries {
+		fmt.Printf("%d independent verifier(s) attested media %s\n", len(summary.Verifiers), summary.MediaHash)
+	}
+	if len(summaries) > 1 {
+		fmt.Println("⚠️  Verifiers disagree on the artwork bytes for this mint")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(registerCmd)
+	rootCmd.AddCommand(lookupCmd)
+
+	for _, c := range []*cobra.Command{registerCmd, lookupCmd} {
+		c.Flags().StringVar(&registryEndpoint, "registry", "", "verification registry URL (default $REGISTRY_ENDPOINT)")
+		c.Flags().StringVar(&registryAPIKey, "registry-key", "", "registry API key (default $REGISTRY_API_KEY)")
+	}
+	registerCmd.Flags().StringVar(&registerKeypair, "keypair", envOrDefault("SOLVAULT_KEYPAIR", ""), "signing keypair file (default ~/.config/solana/id.json)")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/restore"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <backup-dir-or-archive>",
+	Short: "Re-materialize NFT metadata and media from a backup",
+	Long: `Restore NFTs from a SolVault backup directory or archive into a plain,
+human-browsable folder, optionally re-uploading everything to IPFS so the
+NFTs get fresh URIs when the original hosting has disappeared.
+
+This command will:
+• Read the vault (or extract a .tar.gz/.zip archive of one)
+• Write <target>/<wallet>/<nft name>/metadata.json and media/
+• Optionally upload media, rewrite metadata URIs, and upload the new metadata
+• Save a restore_report.json with the original and new URIs
+
+IPFS (a local node or a pinning service with the same API) is the only
+upload target; Arweave is not supported.
+
+Example:
+  solvault restore ~/SolVaultBackups --target ./restored
+  solvault restore vault-backup.tar.gz --target ./restored --wallet <address>
+  solvault restore ~/SolVaultBackups --target ./restored --upload ipfs`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+var (
+	restoreTarget   string
+	restoreWallet   string
+	restoreUpload   string
+	restoreIPFSAPI  string
+	restoreIPFSAuth string
+)
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	source := args[0]
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("backup source not found: %s", source)
+	}
+
+	if restoreTarget == "" {
+		restoreTarget = filepath.Join(".", "solvault-restore")
+	}
+
+	opts := restore.Options{
+		TargetDir: restoreTarget,
+		Wallet:    restoreWallet,
+		Progress:  func(msg string) { fmt.Println(msg) },
+	}
+
+	switch restoreUpload {
+	case "":
+	case "ipfs":
+		opts.Uploader = restore.NewIPFSUploader(restoreIPFSAPI, restoreIPFSAuth)
+	default:
+		return fmt.Errorf("unknown upload target %q (supported: ipfs)", restoreUpload)
+	}
+
+	// An encrypted vault is unlocked with the key configured there
+	solana.LoadEnvFiles()
+
+	ctx := cmd.Context()
+
+	fmt.Printf("♻️  Restoring from %s into %s...\n", source, restoreTarget)
+	report, err := restore.Restore(ctx, source, opts)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range report.Results {
+		if len(result.Errors) > 0 || len(result.MissingMedia) > 0 {
+			failed++
+			fmt.Printf("⚠️  %s:\n", result.Name)
+			for _, missing := range result.MissingMedia {
+				fmt.Printf("   • missing media file: %s\n", missing)
+			}
+			for _, msg := range result.Errors {
+				fmt.Printf("   • %s\n", msg)
+			}
+		}
+		if result.NewMetadataURI != "" {
+			fmt.Printf("🌐 %s → %s\n", result.Name, result.NewMetadataURI)
+		}
+	}
+
+	fmt.Printf("\n✅ Restored %d NFT(s) (%d with warnings)\n", len(report.Results), failed)
+	fmt.Printf("   Report: %s\n", filepath.Join(restoreTarget, "restore_report.json"))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVar(&restoreTarget, "target", "", "directory to restore into (default ./solvault-restore)")
+	restoreCmd.Flags().StringVar(&restoreWallet, "wallet", "", "only restore NFTs from this wallet")
+	restoreCmd.Flags().StringVar(&restoreUpload, "upload", "", "re-upload media and metadata (ipfs)")
+	restoreCmd.Flags().StringVar(&restoreIPFSAPI, "ipfs-api", envOrDefault("IPFS_API_URL", "http://127.0.0.1:5001"), "IPFS HTTP API endpoint")
+	restoreCmd.Flags().StringVar(&restoreIPFSAuth, "ipfs-token", os.Getenv("IPFS_API_TOKEN"), "bearer token for the IPFS API (pinning services)")
+}
+
+// envOrDefault returns the environment variable's value, or fallback when unset
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/schedule"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// retentionOptions reads the retention policy 'prune' and the watcher
+// enforce: RETENTION_KEEP_VERSIONS metadata versions per NFT, and the media
+// of transferred NFTs for RETENTION_TRANSFERRED_DAYS. Unset keeps everything.
+func retentionOptions() (storage.PruneOptions, error) {
+	solana.LoadEnvFiles()
+	var opts storage.PruneOptions
+	versions, err := retentionSetting("RETENTION_KEEP_VERSIONS")
+	if err != nil {
+		return opts, err
+	}
+	days, err := retentionSetting("RETENTION_TRANSFERRED_DAYS")
+	if err != nil {
+		return opts, err
+	}
+	opts.KeepVersions = versions
+	opts.KeepTransferred = time.Duration(days) * 24 * time.Hour
+	return opts, nil
+}
+
+func retentionSetting(key string) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected 0 (keep all) or more", key, value)
+	}
+	return n, nil
+}
+
+// retentionSchedule is when the watcher applies the retention policy
+// (RETENTION_SCHEDULE), or nil when it leaves that to 'prune'
+func retentionSchedule() (*schedule.Expr, error) {
+	solana.LoadEnvFiles()
+	value := strings.TrimSpace(os.Getenv("RETENTION_SCHEDULE"))
+	if value == "" {
+		return nil, nil
+	}
+	expr, err := schedule.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETENTION_SCHEDULE: %w", err)
+	}
+	if expr.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid RETENTION_SCHEDULE %q: it never matches", value)
+	}
+	return expr, nil
+}
+
+// enforceRetention prunes the vault under the retention policy, for the
+// watcher. Burned backups are only ever deleted by an explicit prune.
+func enforceRetention(ctx context.Context, vault *storage.FileStorage) {
+	opts, err := retentionOptions()
+	if err != nil {
+		fmt.Printf("⚠️  Retention skipped: %v\n", err)
+		return
+	}
+	fmt.Printf("⏰ [%s] Applying the retention policy...\n", time.Now().Format("15:04:05"))
+	report, err := vault.Prune(ctx, opts)
+	if err != nil {
+		fmt.Printf("❌ Retention failed: %v\n", err)
+		return
+	}
+	if len(report.Items) > 0 {
+		fmt.Printf("🧹 Reclaimed %s from %d item(s)\n", formatBytes(report.Reclaimed), len(report.Items))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -25,16 +26,54 @@ verifies authenticity through on-chain hashes, and optionally publishes proof pa
 
 Built with clarity. Verified with truth. Leave nothing unbacked.`,
 	Version: fmt.Sprintf("%s (built %s, commit %s)", Version, BuildTime, GitCommit),
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupOutput(cmd, args); err != nil {
+			return err
+		}
+		if err := checkDryRun(cmd, args); err != nil {
+			return err
+		}
+		if err := loadConfig(cmd, args); err != nil {
+			return err
+		}
+		startTracing()
+		return checkVaultHeader(cmd, args)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	defer discardSessionVault()
+	defer flushTracing()
+	ctx, stop := interruptContext()
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
 	// Global flags can be added here
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose output")
-	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/.solvault.env)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default $SOLVAULT_CONFIG or $HOME/.solvault.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "output format (text, json)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print only errors (and the --output json result), for cron and CI")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "show what would be fetched, written, deleted or uploaded without changing anything (commands that cannot plan refuse it)")
+	rootCmd.PersistentFlags().StringVar(&vaultPath, "vault", "", "vault directory, or :memory: for a throwaway vault (default BACKUP_DIRECTORY or ~/SolVaultBackups); --backup-dir is the same flag")
+	rootCmd.PersistentFlags().StringVar(&backendSpec, "backend", "", "storage backends as name[:location], the vault first and then replicas, e.g. file,file:/mnt/usb/SolVault (default $SOLVAULT_BACKEND or file)")
+	rootCmd.PersistentFlags().StringVar(&tenantID, "tenant", "", "use this tenant's vault inside the vault directory, with the tenant's quotas (see 'solvault tenant')")
+
+	// Explanation: --backup-dir is what 'init' called the vault directory, so
+	// it is accepted on every command as another name for --vault
+	rootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "backup-dir" {
+			name = "vault"
+		}
+		return pflag.NormalizedName(name)
+	})
+
+	// Values offered by 'solvault completion'
+	_ = rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = rootCmd.MarkPersistentFlagDirname("vault")
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml")
 }
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/NazWright/solvault/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run backups and verifications on a cron schedule inside the watcher",
+	Long: `Manage recurring SolVault jobs without an external cron.
+
+Schedules are stored in ~/.solvault/schedules.json and run by the watcher
+(solvault watch, usually with --daemon). Changes take effect on the next
+minute without restarting it.
+
+Example:
+  solvault schedule add "0 3 * * *" sync
+  solvault schedule add @weekly -- verify --all
+  solvault schedule list
+  solvault schedule remove 3f9a`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <cron> <command> [args...]",
+	Short: "Add a scheduled command",
+	Long: `Schedule a solvault command using a five-field cron expression
+(minute hour day-of-month month day-of-week) or @hourly/@daily/@weekly/@monthly.
+
+Put -- before the command when it has its own flags.
+
+Example:
+  solvault schedule add "0 3 * * *" sync
+  solvault schedule add "*/30 * * * *" -- sync --wallet <address> --new-only`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runScheduleAdd,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled commands and their next run",
+	RunE:  runScheduleList,
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a scheduled command",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runScheduleRemove,
+}
+
+// unschedulable commands would block forever or recurse into the scheduler
+var unschedulable = map[string]bool{
+	"watch":    true,
+	"serve":    true,
+	"schedule": true,
+}
+
+func openScheduleStore() (*schedule.Store, error) {
+	path, err := schedule.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return schedule.NewStore(path), nil
+}
+
+func runScheduleAdd(cmd *cobra.Command, args []string) error {
+	cronExpr, command := args[0], args[1:]
+
+	target, _, err := rootCmd.Find(command)
+	if err != nil || target == rootCmd {
+		return fmt.Errorf("unknown command %q", command[0])
+	}
+	if unschedulable[target.Name()] || (target.Parent() != nil && unschedulable[target.Parent().Name()]) {
+		return fmt.Errorf("%q cannot be scheduled", target.CommandPath())
+	}
+
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+
+	sched, err := store.Add(cronExpr, command)
+	if err != nil {
+		return fmt.Errorf("failed to add schedule: %w", err)
+	}
+
+	if jsonOutput() {
+		return printJSON(sched)
+	}
+
+	expr, _ := schedule.Parse(sched.Cron)
+	fmt.Printf("✅ Scheduled %s\n", sched.Command())
+	fmt.Printf("   ID:       %s\n", sched.ID)
+	fmt.Printf("   Cron:     %s\n", sched.Cron)
+	fmt.Printf("   Next run: %s\n", expr.Next(time.Now()).Format("2006-01-02 15:04"))
+	fmt.Println("   Schedules run while the watcher is running (solvault watch --daemon)")
+	return nil
+}
+
+func runScheduleList(cmd *cobra.Command, args []string) error {
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+
+	schedules, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if schedules == nil {
+			schedules = []*schedule.Schedule{}
+		}
+		return printJSON(schedules)
+	}
+
+	if len(schedules) == 0 {
+		fmt.Println("📭 No schedules. Add one with: solvault schedule add \"0 3 * * *\" sync")
+		return nil
+	}
+
+	fmt.Printf("⏰ %d schedule(s)\n\n", len(schedules))
+	fmt.Printf("%-10s %-16s %-18s %-18s %s\n", "ID", "CRON", "NEXT RUN", "LAST RUN", "COMMAND")
+	for _, sched := range schedules {
+		next := "never"
+		if expr, err := schedule.Parse(sched.Cron); err == nil {
+			if at := expr.Next(time.Now()); !at.IsZero() {
+				next = at.Format("2006-01-02 15:04")
+			}
+		}
+
+		last := "-"
+		if !sched.LastRun.IsZero() {
+			last = sched.LastRun.Local().Format("2006-01-02 15:04")
+			if sched.LastStatus != "ok" {
+				last += " ❌"
+			}
+		}
+		fmt.Printf("%-10s %-16s %-18s %-18s %s\n", sched.ID, sched.Cron, next, last, sched.Command())
+	}
+	return nil
+}
+
+func runScheduleRemove(cmd *cobra.Command, args []string) error {
+	store, err := openScheduleStore()
+	if err != nil {
+		return err
+	}
+
+	removed, err := store.Remove(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🗑️  Removed schedule %s (%s)\n", removed.ID, removed.Command())
+	return nil
+}
+
+// runScheduledCommand runs a schedule as a separate solvault process so each
+// job gets fresh flags and its output lands in the watcher's log
+func runScheduledCommand(ctx context.Context, sched *schedule.Schedule) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate solvault executable: %w", err)
+	}
+
+	job := exec.CommandContext(ctx, executable, sched.Args...)
+	job.Stdout = os.Stdout
+	job.Stderr = os.Stderr
+	return job.Run()
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Find backups by trait and summarize trait rarity",
+	Long: `Find backed-up NFTs by the traits in their metadata, and see how rare each
+one is among your backups of its collection.
+
+--trait takes Trait=Value and can be repeated; a backup must have every
+trait. Traits, values and the collection are matched case-insensitively, and
+--collection takes a collection name or symbol. Each match shows its rarity
+rank within the backups of its collection.
+
+--rarity prints the trait distribution of each collection instead: every
+trait value with how many backups have it, and the rarest NFTs by rarity
+score. Rarity is measured over the backups in the vault, not over the whole
+collection on chain. With --save each NFT's rank is also saved to its backup
+record, for collections with at least RARITY_MIN_SAMPLE backups (default
+20); sync does this by itself whenever backups change. 'solvault info'
+shows the saved rank.
+
+Traits are kept in the vault index. Backups made before traits were indexed
+are read once and added on the first search.
+
+Example:
+  solvault search --trait "Hat=Crown" --collection "DeGods"
+  solvault search --trait Background=Blue --trait Eyes=Laser
+  solvault search --rarity --collection DeGods --limit 5
+  solvault search --rarity --save
+  solvault search --trait Hat=Crown --output json`,
+	Args: cobra.NoArgs,
+	RunE: runSearch,
+}
+
+var (
+	searchTraits     []string
+	searchCollection string
+	searchWallet     string
+	searchRarity     bool
+	searchSave       bool
+	searchLimit      int
+)
+
+// traitMatch is a backup found by trait, with its rarity among the backups
+// of its collection
+type traitMatch struct {
+	Mint       string            `json:"mint"`
+	Name       string            `json:"name,omitempty"`
+	Collection string            `json:"collection"`
+	Wallet     string            `json:"wallet"`
+	Status     string            `json:"status"`
+	Traits     map[string]string `json:"traits"`
+	RarityRank int               `json:"rarity_rank"`
+	RarityOf   int               `json:"rarity_of"` // Backups in the collection
+	Score      float64           `json:"rarity_score"`
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	filter := storage.TraitFilter{Collection: strings.TrimSpace(searchCollection)}
+	for _, criterion := range searchTraits {
+		trait, value, err := storage.ParseTrait(criterion)
+		if err != nil {
+			return err
+		}
+		if filter.Traits == nil {
+			filter.Traits = make(map[string]string)
+		}
+		filter.Traits[trait] = value
+	}
+	if len(filter.Traits) == 0 && filter.Collection == "" && !searchRarity {
+		return fmt.Errorf("give at least one --trait, a --collection or --rarity")
+	}
+	if searchSave && !searchRarity {
+		return fmt.Errorf("--save is used with --rarity")
+	}
+	if searchWallet != "" {
+		wallet, err := solana.ResolveWallet(searchWallet)
+		if err != nil {
+			return err
+		}
+		filter.Wallet = wallet.String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	if added, err := vault.IndexTraits(cmd.Context()); err != nil {
+		return fmt.Errorf("failed to index traits: %w", err)
+	} else if added > 0 && !jsonOutput() {
+		fmt.Printf("🗂️  Indexed the traits of %d earlier backup(s)\n", added)
+	}
+
+	// Rarity is measured over every backup of the collection in the
+	// searched wallets, whatever traits were asked for
+	population := vault.Index().SearchTraits(storage.TraitFilter{Collection: filter.Collection, Wallet: filter.Wallet})
+	rarities := analytics.Rarity(population)
+	byCollection := make(map[string]*analytics.CollectionRarity, len(rarities))
+	for _, rarity := range rarities {
+		byCollection[rarity.Collection] = rarity
+	}
+
+	if searchSave {
+		ranked, err := analytics.RecordRarity(cmd.Context(), vault, raritySample())
+		if err != nil {
+			return err
+		}
+		if !jsonOutput() {
+			fmt.Printf("💎 Rarity saved to %d backup record(s) (collections with at least %d backups)\n", ranked, raritySample())
+		}
+	}
+
+	if searchRarity {
+		for _, rarity := range rarities {
+			if searchLimit > 0 && len(rarity.Ranked) > searchLimit {
+				rarity.Ranked = rarity.Ranked[:searchLimit]
+			}
+		}
+		if jsonOutput() {
+			return printJSON(map[string]interface{}{"collections": rarities})
+		}
+		printRarity(rarities)
+		return nil
+	}
+
+	matches := []traitMatch{}
+	for _, entry := range vault.Index().SearchTraits(filter) {
+		match := traitMatch{
+			Mint:       entry.Mint,
+			Name:       entry.Name,
+			Collection: entry.Collection,
+			Wallet:     entry.Wallet,
+			Status:     string(entry.Status),
+			Traits:     entry.Attributes,
+		}
+		if match.Status == "" {
+			match.Status = string(storage.StatusHeld)
+		}
+		if rarity := byCollection[analytics.CollectionOf(entry)]; rarity != nil {
+			match.RarityOf = rarity.NFTs
+			if ranked := rarity.Lookup(entry.Mint); ranked != nil {
+				match.RarityRank, match.Score = ranked.Rank, ranked.Score
+			}
+		}
+		matches = append(matches, match)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Collection != matches[j].Collection {
+			return matches[i].Collection < matches[j].Collection
+		}
+		return matches[i].RarityRank < matches[j].RarityRank
+	})
+	if searchLimit > 0 && len(matches) > searchLimit {
+		matches = matches[:searchLimit]
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]interface{}{"count": len(matches), "matches": matches})
+	}
+	if len(matches) == 0 {
+		fmt.Println("📭 No backups match those traits")
+		return nil
+	}
+	fmt.Printf("🔎 %d backup(s) found\n\n", len(matches))
+	for _, match := range matches {
+		name := match.Name
+		if name == "" {
+			name = match.Mint
+		}
+		fmt.Printf("🖼️  %s", name)
+		if match.Collection != "" {
+			fmt.Printf(" (%s)", match.Collection)
+		}
+		if match.Status != string(storage.StatusHeld) {
+			fmt.Printf(" [%s]", match.Status)
+		}
+		fmt.Println()
+		fmt.Printf("   Mint:   %s\n", match.Mint)
+		fmt.Printf("   Rarity: #%d of %d backed up (score %.1f)\n", match.RarityRank, match.RarityOf, match.Score)
+		if traits := describeTraits(match.Traits); traits != "" {
+			fmt.Printf("   Traits: %s\n", traits)
+		}
+	}
+	return nil
+}
+
+// printRarity prints each collection's trait distribution and its rarest
+// backups
+func printRarity(rarities []*analytics.CollectionRarity) {
+	if len(rarities) == 0 {
+		fmt.Println("📭 No backups found")
+		return
+	}
+	for _, rarity := range rarities {
+		fmt.Printf("\n📚 %s (%d backed up)\n", rarity.Collection, rarity.NFTs)
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		if len(rarity.Traits) == 0 {
+			fmt.Println("   No traits recorded")
+			continue
+		}
+		for _, trait := range rarity.Traits {
+			fmt.Printf("%s\n", trait.Trait)
+			for _, value := range trait.Values {
+				fmt.Printf("   %-32s %5d  %5.1f%%\n", truncateString(value.Value, 32), value.Count, value.Percent)
+			}
+		}
+		fmt.Printf("\n💎 Rarest\n")
+		for _, ranked := range rarity.Ranked {
+			name := ranked.Name
+			if name == "" {
+				name = ranked.Mint
+			}
+			fmt.Printf("   #%-4d %-40s %8.1f\n", ranked.Rank, truncateString(name, 40), ranked.Score)
+		}
+	}
+}
+
+// raritySample reads RARITY_MIN_SAMPLE, the fewest backups of a collection
+// that are ranked in backup records
+func raritySample() int {
+	if n, err := strconv.Atoi(os.Getenv("RARITY_MIN_SAMPLE")); err == nil && n >= 0 {
+		return n
+	}
+	return analytics.DefaultRaritySample
+}
+
+// describeTraits renders traits as "Trait: Value" pairs in trait order
+func describeTraits(traits map[string]string) string {
+	names := make([]string, 0, len(traits))
+	for trait := range traits {
+		names = append(names, trait)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, trait := range names {
+		pairs[i] = trait + ": " + traits[trait]
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringArrayVar(&searchTraits, "trait", nil, "only show backups with this trait, as Trait=Value (repeatable)")
+	searchCmd.Flags().StringVar(&searchCollection, "collection", "", "only search this collection (name or symbol)")
+	searchCmd.Flags().StringVar(&searchWallet, "wallet", "", "only search this wallet's backups")
+	searchCmd.Flags().BoolVar(&searchRarity, "rarity", false, "summarize trait rarity per collection instead of listing matches")
+	searchCmd.Flags().BoolVar(&searchSave, "save", false, "with --rarity, also save each NFT's rank to its backup record (shown by info)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 0, "show at most this many matches, or rarest NFTs per collection with --rarity (0 for all)")
+}
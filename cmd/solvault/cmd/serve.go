@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/server"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the vault over HTTP and gRPC for peer attestations and remote backups",
+	Long: `Run an HTTP server that lets other SolVault installations post co-signed
+proofs about NFTs in this vault.
+
+Posted attestations are signature-checked against the verifier's Solana key
+and appended to the NFT's proof_chain.json, building a distributed network of
+independent attestations.
+
+Endpoints:
+• POST /api/v1/attestations          submit a signed attestation
+• GET  /api/v1/attestations/{mint}   read the proof chain for a mint
+• GET  /api/v1/nfts/{mint}/media     list an NFT's media files
+• GET  /api/v1/nfts/{mint}/media/{file}
+                                     stream a media file (Range, ETag and
+                                     Last-Modified supported)
+• GET  /api/v1/nfts/{mint}/thumbnails/{file}
+                                     a JPEG thumbnail listed with the media
+• GET  /api/v1/usage                 the vault's NFT count, disk usage and quotas
+• POST /api/v1/backups               sync {"wallet": "<address>"} into the vault
+• DELETE /api/v1/nfts/{mint}         delete a mint's backups (?wallet= for one)
+• GET  /healthz                      liveness check
+
+gRPC: the same address serves the solvault.v1.Vault service defined in
+proto/solvault/v1/vault.proto over cleartext HTTP/2, for Go services that
+embed SolVault as a backend. ListNFTs lists the vault; Verify and Backup
+stream one event per NFT checked and Backup ends with the sync summary.
+Send credentials as "authorization: Bearer <token>" metadata; Backup needs
+an operator.
+
+Authentication: set SERVE_TOKENS to name:role:secret entries, or
+SERVE_JWT_SECRET to accept HS256 JWTs with "sub" and "role" claims, and every
+/api/v1 request needs "Authorization: Bearer <token>". A viewer may only read;
+an operator may also post attestations, trigger backups and delete. Without
+either setting the API is open to anyone who can reach it. Every request
+that could change the vault is appended to audit.jsonl in the vault, whether
+it was allowed or not. 'solvault serve token' issues tokens.
+
+With --tenants the server is shared between the tenants added with 'solvault
+tenant add'. Every /api/v1 request then needs a tenant's API key, as
+"Authorization: Bearer <key>" or "X-API-Key: <key>", and only sees that
+tenant's vault. Attestations are refused with 507 once a tenant is over
+their disk quota. Tenant keys carry the role given to 'tenant add --role';
+SERVE_TOKENS and SERVE_JWT_SECRET are not accepted in this mode. Restart the
+server after adding or removing tenants.
+
+Example:
+  solvault serve
+  solvault serve --addr 0.0.0.0:8787 --trusted-verifier <pubkey>
+  solvault serve --tenants --addr 0.0.0.0:8787`,
+	RunE: runServe,
+}
+
+var (
+	serveAddr             string
+	serveTrustedVerifiers []string
+	serveTenants          bool
+	serveTokenRole        string
+	serveTokenJWT         bool
+	serveTokenTTL         time.Duration
+)
+
+var serveTokenCmd = &cobra.Command{
+	Use:   "token <name>",
+	Short: "Issue an API token for 'serve'",
+	Long: `Generate a static API token to add to SERVE_TOKENS, or with --jwt sign a
+JWT with SERVE_JWT_SECRET. The name is recorded as the caller in the audit
+log.
+
+Example:
+  solvault serve token dashboard --role viewer
+  solvault serve token ci --role operator --jwt --ttl 720h`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, err := server.ParseRole(serveTokenRole)
+		if err != nil {
+			return err
+		}
+		name := args[0]
+		if strings.ContainsAny(name, ":,") {
+			return fmt.Errorf("token name %q cannot contain ':' or ','", name)
+		}
+
+		if serveTokenJWT {
+			auth, err := serveAuth()
+			if err != nil {
+				return err
+			}
+			if len(auth.JWTSecret) == 0 {
+				return fmt.Errorf("set SERVE_JWT_SECRET to sign JWTs")
+			}
+			token, err := server.SignJWT(auth.JWTSecret, name, role, serveTokenTTL)
+			if err != nil {
+				return fmt.Errorf("failed to sign JWT: %w", err)
+			}
+			fmt.Println(token)
+			return nil
+		}
+
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+		token := hex.EncodeToString(secret)
+		fmt.Printf("🔑 Token: %s\n", token)
+		fmt.Printf("   Add to SERVE_TOKENS: %s:%s:%s\n", name, role, token)
+		return nil
+	},
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	var trusted []solanago.PublicKey
+	for _, verifier := range serveTrustedVerifiers {
+		pubkey, err := solanago.PublicKeyFromBase58(verifier)
+		if err != nil {
+			return fmt.Errorf("invalid trusted verifier %q: %w", verifier, err)
+		}
+		trusted = append(trusted, pubkey)
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	var tenants *storage.Tenants
+	if serveTenants {
+		if tenantID != "" {
+			return fmt.Errorf("--tenants serves every tenant; drop --tenant")
+		}
+		if tenants, err = storage.LoadTenants(vault.BaseDir()); err != nil {
+			return err
+		}
+		if len(tenants.Tenants) == 0 {
+			return fmt.Errorf("no tenants to serve. Add one with 'solvault tenant add <id>'")
+		}
+	}
+
+	auth, err := serveAuth()
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(vault, server.Options{
+		TrustedVerifiers: trusted,
+		Tenants:          tenants,
+		Auth:             auth,
+		Backup:           serveBackup,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+		},
+	})
+
+	ctx := cmd.Context()
+
+	fmt.Printf("🌐 SolVault serving %s on http://%s\n", vault.BaseDir(), serveAddr)
+	switch {
+	case tenants != nil:
+		fmt.Printf("👥 Multi-tenant mode: %d tenant(s), API key required\n", len(tenants.Tenants))
+	case auth.Enabled():
+		jwt := ""
+		if len(auth.JWTSecret) > 0 {
+			jwt = " and JWTs"
+		}
+		fmt.Printf("🔐 API authentication on: %d static token(s)%s\n", len(auth.Tokens), jwt)
+	default:
+		fmt.Println("⚠️  API authentication off: set SERVE_TOKENS or SERVE_JWT_SECRET before exposing this server")
+	}
+	if len(trusted) > 0 {
+		fmt.Printf("🔐 Accepting attestations from %d trusted verifier(s)\n", len(trusted))
+	} else {
+		fmt.Println("🔓 Accepting attestations from any valid signer")
+	}
+
+	if err := srv.ListenAndServe(ctx, serveAddr); err != nil {
+		return err
+	}
+
+	fmt.Println("\n🛑 Server stopped")
+	return nil
+}
+
+// serveAuth reads the API credentials from SERVE_TOKENS and SERVE_JWT_SECRET
+func serveAuth() (*server.Auth, error) {
+	solana.LoadEnvFiles()
+	tokens, err := server.ParseTokens(strings.Split(os.Getenv("SERVE_TOKENS"), ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVE_TOKENS: %w", err)
+	}
+	auth := &server.Auth{Tokens: tokens}
+	if secret := os.Getenv("SERVE_JWT_SECRET"); secret != "" {
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("SERVE_JWT_SECRET must be at least 32 characters")
+		}
+		auth.JWTSecret = []byte(secret)
+	}
+	return auth, nil
+}
+
+// serveBackup syncs a wallet for POST /api/v1/backups and the gRPC Backup
+// call, adding the options 'sync' uses by default to the request's
+func serveBackup(ctx context.Context, vault *storage.FileStorage, wallet solanago.PublicKey, opts backup.Options) (*backup.Summary, error) {
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	source := backup.NewChainSource(client)
+	defer source.Close()
+	if err := configureSource(source, vault); err != nil {
+		return nil, err
+	}
+	opts.Market = market.FromEnv()
+	opts.Mirror = backupMirror(vault)
+	opts.Thumbnails = thumbnail.SizesFromEnv()
+	return backup.Sync(ctx, vault, source, wallet, opts)
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.AddCommand(serveTokenCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8787", "address to listen on")
+	serveCmd.Flags().BoolVar(&serveTenants, "tenants", false, "serve each tenant's vault to their API key only (see 'solvault tenant')")
+	serveCmd.Flags().StringSliceVar(&serveTrustedVerifiers, "trusted-verifier", nil, "only accept attestations signed by these public keys (repeatable)")
+
+	serveTokenCmd.Flags().StringVar(&serveTokenRole, "role", "viewer", "role the token grants: viewer or operator")
+	serveTokenCmd.Flags().BoolVar(&serveTokenJWT, "jwt", false, "sign a JWT with SERVE_JWT_SECRET instead of generating a static token")
+	serveTokenCmd.Flags().DurationVar(&serveTokenTTL, "ttl", 24*time.Hour, "how long a JWT stays valid (0 for no expiry)")
+}
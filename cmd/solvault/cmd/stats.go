@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd reports how much room the vault has left
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show vault usage against its size limit and free disk space",
+	Long: `Show how many NFTs the vault holds and the space it takes, against the
+vault size limit (SOLVAULT_MAX_SIZE) and the free disk space backups always
+leave (SOLVAULT_MIN_FREE, 1GB by default).
+
+Once the vault reaches its size limit, new NFTs are no longer backed up but
+existing backups are still updated. Once the disk is down to the free-space
+threshold, sync and watch stop backing up altogether and send an alert.
+
+Example:
+  solvault stats
+  solvault stats --output json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+// vaultStats is the usage 'stats' reports, with whether backups still fit
+type vaultStats struct {
+	*storage.Usage
+	Status  string `json:"status"`            // ok, vault_full or low_disk_space
+	Problem string `json:"problem,omitempty"` // Why backups are refused, when they are
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	stats := vaultStats{Usage: vault.Usage(), Status: "ok"}
+	if err := vault.CheckQuota(); errors.Is(err, storage.ErrLowDiskSpace) {
+		stats.Status, stats.Problem = "low_disk_space", err.Error()
+	} else if err != nil {
+		stats.Status, stats.Problem = "vault_full", err.Error()
+	}
+	if jsonOutput() {
+		return printJSON(stats)
+	}
+
+	usage := stats.Usage
+	fmt.Printf("📊 Vault %s\n", vault.BaseDir())
+	fmt.Printf("   NFTs:      %d%s\n", usage.NFTs, quotaSuffix(usage.MaxNFTs > 0, fmt.Sprint(usage.MaxNFTs)))
+	fmt.Printf("   Storage:   %s%s\n", formatBytes(usage.Bytes), quotaSuffix(usage.MaxBytes > 0, formatBytes(usage.MaxBytes)+percentOf(usage.Bytes, usage.MaxBytes)))
+	if usage.FreeBytes > 0 {
+		fmt.Printf("   Disk free: %s", formatBytes(usage.FreeBytes))
+		if usage.MinFreeBytes > 0 {
+			fmt.Printf(" (backups stop at %s)", formatBytes(usage.MinFreeBytes))
+		}
+		fmt.Println()
+	}
+
+	switch stats.Status {
+	case "low_disk_space":
+		fmt.Printf("❌ Backups are stopped: %s\n", stats.Problem)
+	case "vault_full":
+		fmt.Printf("📦 New NFTs are not backed up: %s\n", stats.Problem)
+	default:
+		fmt.Println("✅ Backups have room")
+	}
+	return nil
+}
+
+// percentOf describes used as a share of limit, e.g. " (42%)"
+func percentOf(used, limit int64) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%.0f%%)", float64(used)*100/float64(limit))
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/progress"
+	"github.com/NazWright/solvault/internal/render"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	"github.com/NazWright/solvault/internal/webarchive"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Back up only new or changed NFTs and detect ones that left the wallet",
+	Long: `Compare what each wallet holds right now against the vault index and
+do the minimum work to bring the backup up to date.
+
+This command will:
+• Back up NFTs that are new since the last run
+• Re-back up NFTs whose metadata changed (e.g. reveals)
+• Mark NFTs no longer held as transferred or burned (backups are kept)
+• Print a change summary
+
+It is safe to run repeatedly, which makes it a good fit for cron.
+
+For very large wallets, --das enumerates holdings with the DAS
+getAssetsByOwner API using cursor pagination. Progress is checkpointed after
+every page, so a scan limited with --das-max-pages (or interrupted by rate
+limits) resumes where it stopped on the next run. NFTs are only marked as
+transferred once a scan has completed.
+
+--compress switches the vault to zstd-compressed metadata and media,
+compressing existing backups first; the setting is kept for later runs and
+--compress=false turns it off again. Reads decompress automatically and
+verify hashes the original content.
+
+--archive-external saves a browsable copy of each NFT's external_url site
+(the page, its images, scripts and stylesheets, and same-site pages up to
+--archive-depth links away) under external/ in the NFT's backup, and
+records it in nft_data.json. NFTs already backed up are archived on the
+next run. The copy is stored as downloaded, without encryption.
+
+--render-html opens HTML animations (interactive and on-chain generative
+art) in headless Chromium and saves a PNG screenshot, plus an MP4 of
+--render-video seconds when ffmpeg is installed, alongside the page in
+media/. It needs a build made with -tags chromedp and a Chromium or Chrome
+on PATH (or CHROME_PATH).
+
+--cross-check (or METADATA_CROSSCHECK=true) fetches IPFS and Arweave
+metadata a second time through an independent gateway (IPFS_GATEWAYS,
+ARWEAVE_GATEWAYS) and compares the two. When they differ, one gateway may be
+serving tampered metadata: an existing backup is kept rather than
+overwritten, and a new one is saved but flagged. The result is recorded in
+nft_data.json.
+
+--include-fungibles also backs up the metadata and logo of every fungible
+SPL token and SFT (an indivisible token held more than once) in the wallet,
+which are otherwise skipped. They are kept apart from NFTs under
+tokens/<wallet>/<mint>/ with the balance held, do not appear in the index,
+listings or Merkle root, and are not mirrored. A token that leaves the
+wallet keeps its backup.
+
+Example:
+  solvault sync
+  solvault sync --wallet 5QfQ...ZsLk
+  solvault sync --dry-run
+  solvault sync --include-fungibles
+  solvault sync --new-only --output json
+  solvault sync --das --das-max-pages 20 --das-rate 2
+  solvault sync --compress
+  solvault sync --archive-external --archive-max-mb 20
+  solvault sync --render-html --render-video 10
+  solvault sync --cross-check`,
+	RunE: runSync,
+}
+
+var (
+	syncWallet       string
+	syncNewOnly      bool
+	syncFungibles    bool
+	syncDAS          bool
+	syncDASURL       string
+	syncDASRate      float64
+	syncDASMaxPages  int
+	syncDASPageSize  int
+	syncDASReset     bool
+	syncNoPrices     bool
+	syncCompress     bool
+	syncNoMirror     bool
+	syncNoThumbnails bool
+	syncCrossCheck   bool
+	syncArchive      bool
+	syncArchiveDepth int
+	syncArchiveMaxMB int
+	syncRender       bool
+	syncRenderDelay  time.Duration
+	syncRenderVideo  int
+)
+
+func runSync(cmd *cobra.Command, args []string) error {
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	wallets, err := selectWallets(syncWallet)
+	if err != nil {
+		return err
+	}
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	chainSource := backup.NewChainSource(client)
+	defer chainSource.Close()
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(chainSource, vault); err != nil {
+		return err
+	}
+	if syncCrossCheck {
+		chainSource.SetCrossCheck(fetcher.NewCrossCheck())
+	}
+
+	if cmd.Flags().Changed("compress") && !dryRunMode() {
+		if err := setVaultCompression(vault, syncCompress); err != nil {
+			return err
+		}
+	}
+
+	var source backup.Source = chainSource
+	if syncDAS {
+		stateDir := filepath.Join(vault.BaseDir(), ".checkpoints", "das")
+		if dryRunMode() {
+			// Pages go to a scratch directory so the saved checkpoints are untouched
+			if stateDir, err = os.MkdirTemp("", "solvault-das-*"); err != nil {
+				return fmt.Errorf("failed to create scratch directory: %w", err)
+			}
+			defer os.RemoveAll(stateDir)
+			fmt.Printf("🧪 Dry run: scanning from the first page without saving DAS checkpoints\n")
+		}
+		source, err = newDASSource(chainSource, config, stateDir, wallets)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := cmd.Context()
+
+	opts := backup.Options{
+		DryRun:    dryRunMode(),
+		NewOnly:   syncNewOnly,
+		Fungibles: syncFungibles,
+	}
+	if !syncNoPrices {
+		opts.Market = market.FromEnv()
+	}
+	if !syncNoMirror {
+		opts.Mirror = backupMirror(vault)
+	}
+	if !syncNoThumbnails {
+		opts.Thumbnails = thumbnail.SizesFromEnv()
+	}
+	if syncArchive {
+		if syncArchiveDepth < 0 || syncArchiveMaxMB <= 0 {
+			return fmt.Errorf("--archive-depth must be at least 0 and --archive-max-mb above 0")
+		}
+		limits := webarchive.DefaultLimits
+		limits.MaxDepth = syncArchiveDepth
+		limits.MaxBytes = int64(syncArchiveMaxMB) << 20
+		opts.Archive = webarchive.New(limits)
+	}
+	if syncRender {
+		if syncRenderDelay < 0 || syncRenderVideo < 0 {
+			return fmt.Errorf("--render-delay and --render-video cannot be negative")
+		}
+		renderOpts := render.DefaultOptions
+		renderOpts.Delay = syncRenderDelay
+		renderOpts.VideoSeconds = syncRenderVideo
+		renderOpts.Timeout += syncRenderDelay + time.Duration(syncRenderVideo)*time.Second
+		renderer, err := render.New(renderOpts)
+		if err != nil {
+			return fmt.Errorf("--render-html: %w", err)
+		}
+		opts.Render = renderer
+	}
+
+	var summaries []*backup.Summary
+	for _, wallet := range wallets {
+		bar := progress.New("🔄 "+truncateString(wallet.String(), 12), 0)
+		opts.Progress = func(msg string) { bar.Println("%s", msg) }
+		opts.Advance = bar.Set
+		summary, err := backup.Sync(ctx, vault, source, wallet, opts)
+		bar.Finish()
+		if alert := storageAlert(wallet.String(), summary, err); alert != nil {
+			sendNotification(ctx, *alert)
+		}
+		if err != nil {
+			return fmt.Errorf("sync failed for %s: %w", wallet.String(), err)
+		}
+		summaries = append(summaries, summary)
+
+		if !jsonOutput() {
+			printSyncSummary(summary)
+		}
+	}
+
+	if stats := chainSource.MetadataStats(); stats.NotModified+stats.Downloaded > 0 && !jsonOutput() {
+		fmt.Printf("📡 Metadata: %d unchanged (answered from cache), %d downloaded\n", stats.NotModified, stats.Downloaded)
+	}
+
+	// New and changed backups shift their collections' trait frequencies
+	if !dryRunMode() && backupsChanged(summaries) {
+		if ranked, err := analytics.RecordRarity(ctx, vault, raritySample()); err != nil {
+			fmt.Printf("⚠️  Failed to update rarity: %v\n", err)
+		} else if ranked > 0 && !jsonOutput() {
+			fmt.Printf("💎 Rarity updated on %d backup(s)\n", ranked)
+		}
+	}
+
+	if jsonOutput() {
+		if len(summaries) == 1 {
+			return printJSON(summaries[0])
+		}
+		return printJSON(summaries)
+	}
+	return nil
+}
+
+// backupsChanged reports whether a sync added or re-saved any backup
+func backupsChanged(summaries []*backup.Summary) bool {
+	for _, summary := range summaries {
+		for _, kind := range []backup.ChangeKind{backup.ChangeAdded, backup.ChangeUpdated, backup.ChangeReturned, backup.ChangeResumed} {
+			if summary.Counts[string(kind)] > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newDASSource sets up resumable DAS enumeration with checkpoints kept in stateDir
+func newDASSource(chain *backup.ChainSource, config *solana.Config, stateDir string, wallets []solanago.PublicKey) (*backup.DASSource, error) {
+	endpoint, err := dasEndpoint(syncDASURL, config.RPCURL)
+	if err != nil {
+		return nil, err
+	}
+
+	enumerator := das.NewEnumerator(das.NewClient(endpoint, syncDASRate), stateDir)
+	if syncDASReset {
+		for _, wallet := range wallets {
+			if err := enumerator.Reset(wallet.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, wallet := range wallets {
+		if cp, err := enumerator.Status(wallet.String()); err == nil && cp.Pages > 0 && !cp.Complete {
+			fmt.Printf("⏯️  Resuming DAS scan of %s at page %d (%d assets so far)\n", wallet.String(), cp.Pages+1, cp.Assets)
+		}
+	}
+
+	return backup.NewDASSource(chain, enumerator, das.Options{
+		PageSize: syncDASPageSize,
+		MaxPages: syncDASMaxPages,
+		Progress: func(cp das.Checkpoint) {
+			if cp.Total > 0 {
+				fmt.Printf("📄 Page %d: %d/%d assets\n", cp.Pages, cp.Assets, cp.Total)
+			} else {
+				fmt.Printf("📄 Page %d: %d assets\n", cp.Pages, cp.Assets)
+			}
+		},
+	}), nil
+}
+
+func printSyncSummary(summary *backup.Summary) {
+	title := "Sync summary"
+	if summary.DryRun {
+		title += " (dry run, nothing written)"
+	}
+
+	fmt.Printf("\n📊 %s for %s\n", title, summary.Wallet)
+	fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+
+	icons := map[backup.ChangeKind]string{
+		backup.ChangeAdded:       "🆕",
+		backup.ChangeUpdated:     "🔄",
+		backup.ChangeReturned:    "↩️ ",
+		backup.ChangeResumed:     "♻️ ",
+		backup.ChangeTransferred: "📤",
+		backup.ChangeBurned:      "🔥",
+		backup.ChangeEscrowed:    "🔒",
+		backup.ChangeFailed:      "❌",
+	}
+	for _, change := range summary.Changes {
+		icon, ok := icons[change.Kind]
+		if !ok && len(change.Plan) == 0 {
+			continue // Unchanged NFTs only show up in the counts
+		}
+		if !ok {
+			icon = "🧩" // Unchanged, but missing extras this run would add
+		}
+		name := change.Name
+		if name == "" {
+			name = change.Mint
+		}
+		if change.Token != "" {
+			name += fmt.Sprintf(" (%s token)", change.Token)
+		}
+		fmt.Printf("%s %-12s %s\n", icon, change.Kind, name)
+		if change.Error != "" {
+			fmt.Printf("   %s\n", change.Error)
+		}
+		if change.Escrow != nil && !summary.DryRun {
+			fmt.Printf("   %s\n", change.Escrow.Describe())
+		}
+		for _, step := range change.Plan {
+			fmt.Printf("   • would %s\n", step)
+		}
+	}
+
+	fmt.Printf("Added: %d  Updated: %d  Unchanged: %d  Transferred: %d  Burned: %d  Failed: %d  (%s)\n",
+		summary.Counts[string(backup.ChangeAdded)]+summary.Counts[string(backup.ChangeReturned)]+summary.Counts[string(backup.ChangeResumed)],
+		summary.Counts[string(backup.ChangeUpdated)],
+		summary.Counts[string(backup.ChangeUnchanged)],
+		summary.Counts[string(backup.ChangeTransferred)],
+		summary.Counts[string(backup.ChangeBurned)],
+		summary.Counts[string(backup.ChangeFailed)],
+		summary.FinishedAt.Sub(summary.StartedAt).Round(time.Millisecond))
+	if escrowed := summary.Counts[string(backup.ChangeEscrowed)]; escrowed > 0 {
+		fmt.Printf("🔒 %d NFT(s) staked or in escrow are still kept as held\n", escrowed)
+	}
+	if summary.MerkleRoot != "" {
+		fmt.Printf("🌳 Merkle root: %s\n", summary.MerkleRoot)
+	}
+	if summary.Partial {
+		fmt.Println("⏸️  Wallet scan incomplete; transfers are checked once it finishes. Run sync again to continue.")
+	}
+	if summary.VaultFull != "" {
+		fmt.Printf("📦 Vault is at its size limit (%s); new NFTs are not backed up until space is freed\n", summary.VaultFull)
+	}
+}
+
+// setVaultCompression turns compression on or off, rewriting existing backups
+func setVaultCompression(vault *storage.FileStorage, on bool) error {
+	if on == vault.Compressed() {
+		return nil
+	}
+	if on {
+		fmt.Printf("🗜️  Compressing existing backups...\n")
+		converted, err := vault.EnableCompression()
+		if err != nil {
+			return fmt.Errorf("failed to compress vault: %w", err)
+		}
+		fmt.Printf("✅ Compressed %d file(s); new backups will be compressed too\n", converted)
+		return nil
+	}
+
+	fmt.Printf("📂 Decompressing existing backups...\n")
+	converted, err := vault.DisableCompression()
+	if err != nil {
+		return fmt.Errorf("failed to decompress vault: %w", err)
+	}
+	fmt.Printf("✅ Decompressed %d file(s); new backups will not be compressed\n", converted)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	supportDryRun(syncCmd)
+
+	syncCmd.Flags().StringVar(&syncWallet, "wallet", "", "only sync this wallet (default: all configured wallets)")
+	syncCmd.Flags().BoolVar(&syncNewOnly, "new-only", false, "skip change detection for NFTs already backed up")
+	syncCmd.Flags().BoolVar(&syncFungibles, "include-fungibles", false, "also back up fungible SPL tokens and SFTs under tokens/")
+	syncCmd.Flags().BoolVar(&syncDAS, "das", false, "enumerate holdings with the DAS getAssetsByOwner API (large wallets)")
+	syncCmd.Flags().StringVar(&syncDASURL, "das-url", "", "DAS-enabled RPC URL (default $DAS_RPC_URL, then NFT_PROVIDER's DAS API, then SOLANA_RPC_URL)")
+	syncCmd.Flags().Float64Var(&syncDASRate, "das-rate", 5, "maximum DAS requests per second")
+	syncCmd.Flags().IntVar(&syncDASMaxPages, "das-max-pages", 0, "stop after this many pages and resume next run (0 = no limit)")
+	syncCmd.Flags().IntVar(&syncDASPageSize, "das-page-size", das.MaxPageSize, "assets per DAS page")
+	syncCmd.Flags().BoolVar(&syncDASReset, "das-reset", false, "discard saved DAS checkpoints and start a fresh scan")
+	syncCmd.Flags().BoolVar(&syncCompress, "compress", false, "store metadata and media zstd-compressed from now on (--compress=false to stop)")
+	syncCmd.Flags().BoolVar(&syncNoPrices, "no-prices", false, "skip recording marketplace sale and floor prices")
+	syncCmd.Flags().BoolVar(&syncNoMirror, "no-mirror", false, "keep this run's backups local even if a cloud mirror is configured")
+	syncCmd.Flags().BoolVar(&syncCrossCheck, "cross-check", false, "compare IPFS and Arweave metadata with a second gateway's copy (default METADATA_CROSSCHECK)")
+	syncCmd.Flags().BoolVar(&syncNoThumbnails, "no-thumbnails", false, "skip making image and video thumbnails")
+	syncCmd.Flags().BoolVar(&syncArchive, "archive-external", false, "save a copy of each NFT's external_url site under external/")
+	syncCmd.Flags().IntVar(&syncArchiveDepth, "archive-depth", webarchive.DefaultLimits.MaxDepth, "same-site links to follow from the external page")
+	syncCmd.Flags().IntVar(&syncArchiveMaxMB, "archive-max-mb", int(webarchive.DefaultLimits.MaxBytes>>20), "size limit of each site archive in MB")
+	syncCmd.Flags().BoolVar(&syncRender, "render-html", false, "screenshot HTML animations in headless Chromium (needs a chromedp build)")
+	syncCmd.Flags().DurationVar(&syncRenderDelay, "render-delay", render.DefaultOptions.Delay, "how long an HTML animation runs before it is captured")
+	syncCmd.Flags().IntVar(&syncRenderVideo, "render-video", 0, "also record this many seconds of MP4 (needs ffmpeg)")
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/server"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tenantWallets  []string
+	tenantMaxNFTs  int
+	tenantMaxBytes string
+	tenantRole     string
+)
+
+// tenantCmd represents the tenant command
+var tenantCmd = &cobra.Command{
+	Use:   "tenant",
+	Short: "Manage the tenants of a shared vault and their API keys",
+	Long: `Split one SolVault deployment between several users. Each tenant gets an
+API key and a vault of their own under tenants/<id>/ in the vault directory,
+with its own wallets, index and quotas.
+
+'serve --tenants' requires a tenant's API key on every /api/v1 request and
+serves it from that tenant's vault only. '--tenant <id>' runs any other
+command (sync, list, verify, ...) against a tenant's vault with the
+tenant's quotas enforced, and syncs the tenant's wallets by default.
+
+Quotas: --wallet restricts which wallets may be backed up, --max-nfts caps
+the number of backups and --max-bytes the disk space. Saves that would add a
+backup past a quota fail; updates to existing backups always go through.
+
+Example:
+  solvault tenant add alice --wallet <address> --max-nfts 500 --max-bytes 2GB
+  solvault tenant list
+  solvault --tenant alice sync
+  solvault tenant remove alice`,
+}
+
+var tenantAddCmd = &cobra.Command{
+	Use:   "add <id>",
+	Short: "Create a tenant and print their API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limits := storage.Limits{MaxNFTs: tenantMaxNFTs}
+		for _, wallet := range tenantWallets {
+			address, err := solana.ResolveWallet(wallet)
+			if err != nil {
+				return err
+			}
+			limits.Wallets = append(limits.Wallets, address.String())
+		}
+		if tenantMaxBytes != "" {
+			maxBytes, err := parseBytes(tenantMaxBytes)
+			if err != nil {
+				return fmt.Errorf("invalid --max-bytes: %w", err)
+			}
+			limits.MaxBytes = maxBytes
+		}
+
+		role, err := server.ParseRole(tenantRole)
+		if err != nil {
+			return err
+		}
+
+		tenants, err := loadTenants()
+		if err != nil {
+			return err
+		}
+		tenant, key, err := tenants.Add(args[0], limits)
+		if err != nil {
+			return err
+		}
+		tenant.Role = string(role)
+		if dryRunMode() {
+			fmt.Printf("🧪 Would create tenant %s (nothing written)\n", tenant.ID)
+			return nil
+		}
+		if err := tenants.Save(); err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			return printJSON(struct {
+				*storage.Tenant
+				APIKey string `json:"api_key"`
+			}{tenant, key})
+		}
+		fmt.Printf("✅ Created tenant %s\n", tenant.ID)
+		fmt.Printf("🔑 API key: %s\n", key)
+		fmt.Println("   Store it now: only a hash is kept, so it cannot be shown again.")
+		fmt.Println("💡 Restart 'solvault serve --tenants' for the key to be accepted")
+		return nil
+	},
+}
+
+var tenantListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tenants with their quotas and usage",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tenants, err := loadTenants()
+		if err != nil {
+			return err
+		}
+		baseDir, err := vaultRootDirectory()
+		if err != nil {
+			return err
+		}
+
+		type tenantUsage struct {
+			*storage.Tenant
+			Usage *storage.Usage `json:"usage"`
+		}
+		var list []tenantUsage
+		for _, tenant := range tenants.List() {
+			vault, err := storage.OpenTenantVault(baseDir, tenant)
+			if err != nil {
+				return err
+			}
+			list = append(list, tenantUsage{tenant, vault.Usage()})
+			vault.Close()
+		}
+
+		if jsonOutput() {
+			return printJSON(list)
+		}
+		if len(list) == 0 {
+			fmt.Println("📭 No tenants. Add one with: solvault tenant add <id>")
+			return nil
+		}
+		fmt.Printf("👥 %d tenant(s)\n\n", len(list))
+		for _, entry := range list {
+			role := entry.Role
+			if role == "" {
+				role = string(server.RoleOperator)
+			}
+			fmt.Printf("• %s, %s (created %s)\n", entry.ID, role, entry.CreatedAt.Format("2006-01-02"))
+			fmt.Printf("   NFTs:    %d%s\n", entry.Usage.NFTs, quotaSuffix(entry.Limits.MaxNFTs > 0, strconv.Itoa(entry.Limits.MaxNFTs)))
+			fmt.Printf("   Storage: %s%s\n", formatBytes(entry.Usage.Bytes), quotaSuffix(entry.Limits.MaxBytes > 0, formatBytes(entry.Limits.MaxBytes)))
+			if len(entry.Limits.Wallets) > 0 {
+				fmt.Printf("   Wallets: %s\n", strings.Join(entry.Limits.Wallets, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+var tenantRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Revoke a tenant's API key, keeping their vault on disk",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tenants, err := loadTenants()
+		if err != nil {
+			return err
+		}
+		if err := tenants.Remove(args[0]); err != nil {
+			return err
+		}
+		baseDir, err := vaultRootDirectory()
+		if err != nil {
+			return err
+		}
+		if dryRunMode() {
+			fmt.Printf("🧪 Would remove tenant %s (nothing written)\n", args[0])
+			return nil
+		}
+		if err := tenants.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed tenant %s; their vault stays at %s\n", args[0], storage.TenantDir(baseDir, args[0]))
+		return nil
+	},
+}
+
+// loadTenants reads the tenants of the vault directory
+func loadTenants() (*storage.Tenants, error) {
+	baseDir, err := vaultRootDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return storage.LoadTenants(baseDir)
+}
+
+// selectedTenant returns the tenant chosen with --tenant, or nil
+func selectedTenant() (*storage.Tenant, error) {
+	if tenantID == "" {
+		return nil, nil
+	}
+	tenants, err := loadTenants()
+	if err != nil {
+		return nil, err
+	}
+	tenant := tenants.Get(tenantID)
+	if tenant == nil {
+		return nil, fmt.Errorf("%w %s. Add it with 'solvault tenant add %s'", storage.ErrUnknownTenant, tenantID, tenantID)
+	}
+	return tenant, nil
+}
+
+func quotaSuffix(limited bool, limit string) string {
+	if !limited {
+		return ""
+	}
+	return " of " + limit
+}
+
+// parseBytes reads a size such as 500MB or 2GB; units are powers of 1024,
+// like formatBytes prints them
+func parseBytes(value string) (int64, error) {
+	number := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "I")
+	multiplier := int64(1)
+	if n := len(number); n > 0 {
+		if exp := strings.IndexByte("KMGT", number[n-1]); exp >= 0 {
+			multiplier, number = int64(1)<<(10*(exp+1)), number[:n-1]
+		}
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%q is not a size like 500MB or 2GB", value)
+	}
+	return int64(size * float64(multiplier)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(tenantCmd)
+	tenantCmd.AddCommand(tenantAddCmd)
+	tenantCmd.AddCommand(tenantListCmd)
+	tenantCmd.AddCommand(tenantRemoveCmd)
+
+	supportDryRun(tenantAddCmd)
+	supportDryRun(tenantRemoveCmd)
+
+	tenantAddCmd.Flags().StringSliceVar(&tenantWallets, "wallet", nil, "wallet the tenant may back up (repeatable; default any)")
+	tenantAddCmd.Flags().IntVar(&tenantMaxNFTs, "max-nfts", 0, "most NFT backups the tenant may hold (0 for no limit)")
+	tenantAddCmd.Flags().StringVar(&tenantRole, "role", "operator", "what the tenant's key may do in 'serve': viewer or operator")
+	tenantAddCmd.Flags().StringVar(&tenantMaxBytes, "max-bytes", "", "most disk space the tenant may use, e.g. 500MB or 2GB (default no limit)")
+}
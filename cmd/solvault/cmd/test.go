@@ -54,13 +54,16 @@ Example:
 		defer client.Close()
 
 		// Test connection
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
 		defer cancel()
 
 		if err := client.TestConnection(ctx); err != nil {
 			return fmt.Errorf("❌ Failed to connect to Solana: %w", err)
 		}
 		fmt.Println("✅ Connected to Solana RPC")
+		if endpoints := client.Endpoints(); len(endpoints) > 1 {
+			printEndpoints(endpoints)
+		}
 
 		// Create NFT fetcher
 		fmt.Println("🚀 Creating NFT fetcher...")
@@ -69,7 +72,7 @@ Example:
 
 		// Fetch NFT info
 		fmt.Println("🔍 Fetching NFT information...")
-		ctx2, cancel2 := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx2, cancel2 := context.WithTimeout(cmd.Context(), 30*time.Second)
 		defer cancel2()
 
 		nftInfo, err := nftFetcher.FetchNFTInfo(ctx2, mintAddress)
@@ -121,6 +124,21 @@ Example:
 	},
 }
 
+// printEndpoints shows the health of each endpoint in the RPC pool
+func printEndpoints(endpoints []solana.EndpointStatus) {
+	for i, endpoint := range endpoints {
+		role := "fallback"
+		if i == 0 {
+			role = "primary"
+		}
+		if endpoint.Healthy {
+			fmt.Printf("   ✅ %-8s %s (%s)\n", role, endpoint.URL, endpoint.Latency.Round(time.Millisecond))
+		} else {
+			fmt.Printf("   ❌ %-8s %s: %s\n", role, endpoint.URL, truncateString(endpoint.LastError, 80))
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(testCmd)
 }
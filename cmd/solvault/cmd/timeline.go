@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/timeline"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// timelineCmd represents the timeline command
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Show your collecting history as a timeline",
+	Long: `Order the vault's NFTs by when they were minted into a timeline of your
+collecting history.
+
+Mint dates come from the block time of each mint's first transaction and are
+cached in the vault. NFTs without a known mint date fall back to the creation
+date embedded in their media (EXIF or PNG metadata), then to the backup date.
+
+Example:
+  solvault timeline
+  solvault timeline --format html            # writes timeline.html
+  solvault timeline --format json --offline`,
+	RunE: runTimeline,
+}
+
+var (
+	timelineFormat  string
+	timelineOut     string
+	timelineWallet  string
+	timelineOffline bool
+)
+
+func runTimeline(cmd *cobra.Command, args []string) error {
+	if timelineFormat == "json" {
+		enableJSONOutput()
+	}
+
+	var wallet string
+	if timelineWallet != "" {
+		wallets, err := selectWallets(timelineWallet)
+		if err != nil {
+			return err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	fmt.Printf("🗓️  Building timeline...\n")
+	var entries []*timeline.Entry
+	for _, indexed := range vault.Index().List() {
+		if indexed.PrimaryWallet != "" || (wallet != "" && indexed.Wallet != wallet) {
+			continue // Linked custody records share the primary's backup
+		}
+		walletKey, err1 := solanago.PublicKeyFromBase58(indexed.Wallet)
+		mintKey, err2 := solanago.PublicKeyFromBase58(indexed.Mint)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stored, err := vault.GetNFT(cmd.Context(), walletKey, mintKey)
+		if err != nil {
+			fmt.Printf("⚠️  Skipping %s: %v\n", indexed.Mint, err)
+			continue
+		}
+
+		entry := &timeline.Entry{
+			Mint:       indexed.Mint,
+			Name:       indexed.Name,
+			Wallet:     indexed.Wallet,
+			BackedUpAt: stored.StoredAt,
+		}
+		if stored.NFTInfo != nil && stored.NFTInfo.Metadata != nil {
+			entry.Collection = stored.NFTInfo.Metadata.Collection.Name
+		}
+		if image := findImageFile(vault.NFTDir(walletKey, mintKey)); image != "" {
+			if created, err := timeline.MediaCreated(image); err == nil {
+				entry.MediaCreatedAt = &created
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := addMintTimes(cmd.Context(), vault.BaseDir(), entries); err != nil {
+		return err
+	}
+
+	tl := timeline.Build(entries)
+	if jsonOutput() {
+		if timelineOut == "" {
+			return printJSON(tl)
+		}
+		return writeTimeline(tl, func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(tl)
+		})
+	}
+	switch timelineFormat {
+	case "ascii", "":
+		return writeTimeline(tl, tl.RenderASCII)
+	case "html":
+		// A page on stdout would be mixed with progress messages
+		if timelineOut == "" {
+			timelineOut = "timeline.html"
+		}
+		return writeTimeline(tl, tl.RenderHTML)
+	default:
+		return fmt.Errorf("invalid --format %q (expected ascii, html or json)", timelineFormat)
+	}
+}
+
+// addMintTimes fills in mint block times from the vault cache, looking up
+// any missing ones on chain unless --offline is set
+func addMintTimes(ctx context.Context, vaultDir string, entries []*timeline.Entry) error {
+	cache, err := timeline.LoadMintTimes(vaultDir)
+	if err != nil {
+		return err
+	}
+
+	var client *solana.Client
+	if !timelineOffline {
+		if config, err := solana.LoadConfig(); err != nil {
+			fmt.Printf("⚠️  No RPC configured (%v); using cached mint dates only\n", err)
+		} else if client, err = solana.NewClient(config); err != nil {
+			return fmt.Errorf("failed to create Solana client: %w", err)
+		}
+	}
+
+	looked := 0
+	for _, entry := range entries {
+		if minted, ok := cache.Get(entry.Mint); ok {
+			entry.MintedAt = &minted
+			continue
+		}
+		if client == nil {
+			continue
+		}
+
+		mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			continue
+		}
+		first, err := client.GetOldestSignature(ctx, mint)
+		if err != nil || first.BlockTime == nil {
+			fmt.Printf("⚠️  Could not find mint date for %s\n", shortAddress(entry.Mint))
+			continue
+		}
+		minted := first.BlockTime.Time().UTC()
+		entry.MintedAt = &minted
+		cache.Put(entry.Mint, minted)
+		looked++
+	}
+
+	if looked > 0 {
+		fmt.Printf("🔗 Looked up %d mint date(s) on chain\n", looked)
+		return cache.Save()
+	}
+	return nil
+}
+
+// writeTimeline renders to --out, or to stdout when no file is given
+func writeTimeline(tl *timeline.Timeline, render func(io.Writer) error) error {
+	if timelineOut == "" {
+		return render(os.Stdout)
+	}
+
+	f, err := os.Create(timelineOut)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", timelineOut, err)
+	}
+	defer f.Close()
+	if err := render(f); err != nil {
+		return fmt.Errorf("failed to write timeline: %w", err)
+	}
+
+	abs, _ := filepath.Abs(timelineOut)
+	fmt.Printf("✅ Wrote timeline of %d NFT(s) to %s\n", len(tl.Entries), abs)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+
+	timelineCmd.Flags().StringVar(&timelineFormat, "format", "ascii", "output format (ascii, html, json)")
+	timelineCmd.Flags().StringVar(&timelineOut, "out", "", "write the timeline to this file (html defaults to timeline.html)")
+	timelineCmd.Flags().StringVar(&timelineWallet, "wallet", "", "only include NFTs backed up for this wallet")
+	timelineCmd.Flags().BoolVar(&timelineOffline, "offline", false, "skip on-chain mint date lookups and use cached dates only")
+}
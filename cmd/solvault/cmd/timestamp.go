@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/tsa"
+)
+
+// proofTimestamp is the state of the timestamp kept next to proof.json
+type proofTimestamp struct {
+	*tsa.Info
+	Valid    bool   `json:"valid"`
+	Error    string `json:"error,omitempty"`
+	Archived string `json:"archived,omitempty"` // Where the stamped proof was moved before proof.json was rewritten
+}
+
+// tsaURL returns the timestamp authority from --tsa, TSA_URL or the default
+func tsaURL() string {
+	if verifyTSA != "" {
+		return verifyTSA
+	}
+	solana.LoadEnvFiles()
+	if url := os.Getenv("TSA_URL"); url != "" {
+		return url
+	}
+	return tsa.DefaultURL
+}
+
+// tsaRoots returns the certificates in TSA_CA_FILE, or nil to trust the
+// system pool
+func tsaRoots() (*x509.CertPool, error) {
+	solana.LoadEnvFiles()
+	path := os.Getenv("TSA_CA_FILE")
+	if path == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TSA_CA_FILE: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("TSA_CA_FILE %s has no PEM certificates", path)
+	}
+	return roots, nil
+}
+
+// checkProofTimestamp validates the timestamp kept for proof.json against
+// proof.json as it is now. It returns nil when the proof was never stamped.
+func checkProofTimestamp(nftPath string) *proofTimestamp {
+	response, err := os.ReadFile(filepath.Join(nftPath, proof.TimestampFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	stamp := &proofTimestamp{}
+	if err != nil {
+		stamp.Error = fmt.Sprintf("failed to read %s: %v", proof.TimestampFileName, err)
+		return stamp
+	}
+	doc, err := os.ReadFile(filepath.Join(nftPath, proof.FileName))
+	if err != nil {
+		stamp.Error = fmt.Sprintf("failed to read the timestamped proof.json: %v", err)
+		return stamp
+	}
+	roots, err := tsaRoots()
+	if err != nil {
+		stamp.Error = err.Error()
+		return stamp
+	}
+
+	digest := sha256.Sum256(doc)
+	if stamp.Info, err = tsa.Verify(response, digest[:], roots); err != nil {
+		stamp.Error = err.Error()
+		return stamp
+	}
+	stamp.Valid = true
+	return stamp
+}
+
+// stampProof requests a timestamp for proof.json as just written and saves
+// the token next to it
+func stampProof(ctx context.Context, nftPath string) (*tsa.Info, error) {
+	doc, err := os.ReadFile(filepath.Join(nftPath, proof.FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof: %w", err)
+	}
+	digest := sha256.Sum256(doc)
+	response, info, err := tsa.NewClient(tsaURL()).Stamp(ctx, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to timestamp proof: %w", err)
+	}
+
+	// Report trust the same way a later verify will
+	if roots, err := tsaRoots(); err == nil && roots != nil {
+		if checked, err := tsa.Verify(response, digest[:], roots); err == nil {
+			info = checked
+		}
+	}
+	if err := os.WriteFile(filepath.Join(nftPath, proof.TimestampFileName), response, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save proof timestamp: %w", err)
+	}
+	return info, nil
+}
+
+// anchorProof timestamps an NFT's proof.json and reports the token
+func anchorProof(ctx context.Context, nftPath string) (*tsa.Info, error) {
+	fmt.Printf("⏱️  Requesting a timestamp from %s...\n", tsaURL())
+	info, err := stampProof(ctx, nftPath)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("✅ Timestamp saved to: %s\n", filepath.Join(nftPath, proof.TimestampFileName))
+	fmt.Printf("Proof Stamp:  %s\n", describeTimestamp(&proofTimestamp{Info: info, Valid: true}))
+	return info, nil
+}
+
+// describeTimestamp summarizes a proof timestamp on one line
+func describeTimestamp(stamp *proofTimestamp) string {
+	if !stamp.Valid {
+		return "❌ " + stamp.Error
+	}
+	line := fmt.Sprintf("✅ %s by %s", stamp.Time.Local().Format(time.RFC3339), stamp.Authority)
+	if !stamp.Trusted {
+		line += fmt.Sprintf(" (⚠️  %s; set TSA_CA_FILE to the authority's CA)", stamp.TrustNote)
+	}
+	return line
+}
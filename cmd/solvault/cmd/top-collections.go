@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/NazWright/solvault/internal/analytics"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/spf13/cobra"
+)
+
+// topCollectionsCmd represents the top-collections command
+var topCollectionsCmd = &cobra.Command{
+	Use:     "top-collections",
+	Aliases: []string{"portfolio"},
+	Short:   "Summarize backed-up NFTs by collection and estimate their value",
+	Long: `Group every backed-up NFT still held by collection, count them, and value
+each collection at its current floor price, with the estimated value of the
+whole portfolio.
+
+Floors are looked up from the marketplaces (MARKET_PRICES), one request per
+collection. Collections the marketplaces cannot price, or every collection
+with --offline, fall back to the floor recorded at the newest backup. NFTs
+without a collection have no shared floor and are counted as unpriced.
+
+Example:
+  solvault top-collections
+  solvault top-collections --limit 0 --offline
+  solvault portfolio --wallet 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU --output json`,
+	Args: cobra.NoArgs,
+	RunE: runTopCollections,
+}
+
+var (
+	topCollectionsWallet  string
+	topCollectionsLimit   int
+	topCollectionsOffline bool
+)
+
+func runTopCollections(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	wallets, err := selectWallets(topCollectionsWallet)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		selected[wallet.String()] = true
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	nfts, err := heldNFTs(ctx, vault, selected)
+	if err != nil {
+		return err
+	}
+	portfolio := analytics.BuildPortfolio(nfts)
+
+	// Explanation: Live floors are best effort. A marketplace outage leaves
+	// the recorded floors in place rather than failing the summary, and the
+	// warning says how many collections could not be refreshed.
+	if provider := market.FromEnv(); provider != nil && !topCollectionsOffline && len(portfolio.Collections) > 0 {
+		var progress func(done, total int)
+		if !jsonOutput() {
+			fmt.Printf("💹 Looking up floor prices from %s...\n", provider.Name())
+			progress = func(done, total int) {
+				fmt.Printf("\r   %d/%d collections", done, total)
+				if done == total {
+					fmt.Println()
+				}
+			}
+		}
+		failed, err := portfolio.PriceLive(ctx, provider, progress)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if failed > 0 && !jsonOutput() {
+			fmt.Printf("⚠️  Failed to look up %d floor(s), using recorded prices: %v\n", failed, err)
+		}
+	}
+
+	top := portfolio.Top(topCollectionsLimit)
+	if jsonOutput() {
+		portfolio.Collections = top
+		return printJSON(portfolio)
+	}
+
+	if portfolio.NFTs == 0 {
+		fmt.Println("📭 No backed-up NFTs found. Run 'solvault backup' first")
+		return nil
+	}
+	fmt.Printf("\n🏆 Top collections (%d NFTs in %d collections)\n\n", portfolio.NFTs, len(portfolio.Collections))
+	fmt.Printf("   %-32s %6s %12s %14s  %s\n", "COLLECTION", "COUNT", "FLOOR (SOL)", "VALUE (SOL)", "PRICED")
+	for i, summary := range top {
+		floor, value, priced := "-", "-", "-"
+		if summary.FloorSOL > 0 {
+			floor = fmt.Sprintf("%.3f", summary.FloorSOL)
+			value = fmt.Sprintf("%.3f", summary.ValueSOL)
+			priced = summary.FloorSource
+			if summary.Marketplace != "" {
+				priced = summary.Marketplace + ", " + priced
+			}
+			if summary.FloorSource == analytics.FloorBackup && summary.PricedAt != nil {
+				priced += " " + summary.PricedAt.Local().Format("2006-01-02")
+			}
+		}
+		fmt.Printf("%2d. %-32s %6d %12s %14s  %s\n", i+1, truncateString(summary.Collection, 32), summary.Count, floor, value, priced)
+	}
+	if hidden := len(portfolio.Collections) - len(top); hidden > 0 {
+		fmt.Printf("    … and %d more (--limit 0 shows all)\n", hidden)
+	}
+
+	fmt.Printf("\n💰 Estimated portfolio value: %.3f SOL\n", portfolio.TotalSOL)
+	if portfolio.Unpriced > 0 {
+		fmt.Printf("   %d NFT(s) without a floor price are not included\n", portfolio.Unpriced)
+	}
+	return nil
+}
+
+// heldNFTs loads the backups of every NFT the selected wallets still hold.
+// Linked records share the primary wallet's backup and are counted once,
+// under the wallet whose backup it is.
+func heldNFTs(ctx context.Context, vault *storage.FileStorage, wallets map[string]bool) ([]*storage.StoredNFT, error) {
+	var nfts []*storage.StoredNFT
+	for _, entry := range vault.Index().List() {
+		if !wallets[entry.Wallet] || entry.PrimaryWallet != "" || entry.InProgress {
+			continue
+		}
+		if entry.Status == storage.StatusTransferred || entry.Status == storage.StatusBurned {
+			continue
+		}
+		wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			continue
+		}
+		mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			continue
+		}
+		stored, err := vault.GetNFT(ctx, wallet, mint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", entry.Mint, err)
+			continue
+		}
+		nfts = append(nfts, stored)
+	}
+	return nfts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(topCollectionsCmd)
+
+	topCollectionsCmd.Flags().StringVar(&topCollectionsWallet, "wallet", "", "only summarize this wallet (default: all configured wallets)")
+	topCollectionsCmd.Flags().IntVar(&topCollectionsLimit, "limit", 10, "number of collections to show (0 for all)")
+	topCollectionsCmd.Flags().BoolVar(&topCollectionsOffline, "offline", false, "use the floor prices recorded at backup instead of looking them up")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/telemetry"
+)
+
+// stopTracing flushes and stops the tracer provider, when tracing is on
+var stopTracing func(context.Context) error
+
+// tracingFlushTimeout bounds how long the last spans are given to upload
+// when a command exits
+const tracingFlushTimeout = 5 * time.Second
+
+// startTracing exports spans to the OTLP endpoint in
+// OTEL_EXPORTER_OTLP_ENDPOINT, if one is configured. A bad setting is
+// reported but does not stop the command: tracing is for debugging it.
+func startTracing() {
+	solana.LoadEnvFiles()
+	cfg, err := telemetry.ConfigFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Tracing disabled: %v\n", err)
+		return
+	}
+	if cfg == nil {
+		return
+	}
+	stop, err := telemetry.Setup(cfg, Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Tracing disabled: %v\n", err)
+		return
+	}
+	stopTracing = stop
+}
+
+// flushTracing uploads the spans still buffered when the command ends
+func flushTracing() {
+	if stopTracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancel()
+	if err := stopTracing(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to export traces: %v\n", err)
+	}
+	stopTracing = nil
+}
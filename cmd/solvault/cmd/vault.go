@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	_ "github.com/NazWright/solvault/internal/storage/sftp" // Registers the sftp backend
+	"github.com/spf13/cobra"
+)
+
+// vaultPath overrides the vault location; storage.MemoryVault selects a
+// throwaway in-memory vault for the current command
+var vaultPath string
+
+// backendSpec chains storage backends (--backend, or SOLVAULT_BACKEND): the
+// backend holding the vault, then replicas that receive every save
+var backendSpec string
+
+// tenantID selects a tenant's vault inside the vault directory (--tenant)
+var tenantID string
+
+// sessionVault is the in-memory vault shared by everything in one run
+var sessionVault *storage.FileStorage
+
+// vaultBackends parses the configured backend chain, a file vault by default
+func vaultBackends() ([]storage.BackendSpec, error) {
+	spec := backendSpec
+	if spec == "" {
+		solana.LoadEnvFiles()
+		spec = envOrDefault("SOLVAULT_BACKEND", "file")
+	}
+	return storage.ParseBackends(spec)
+}
+
+// usingMemoryVault reports whether this run uses a throwaway vault, chosen
+// with --vault :memory: or a memory primary backend
+func usingMemoryVault() bool {
+	if vaultPath == storage.MemoryVault {
+		return true
+	}
+	specs, err := vaultBackends()
+	return err == nil && specs[0].Name == "memory"
+}
+
+// openVault opens the configured backend chain with the vault in the
+// backup directory
+func openVault() (*storage.FileStorage, error) {
+	if usingMemoryVault() {
+		return memoryVault()
+	}
+
+	specs, err := vaultBackends()
+	if err != nil {
+		return nil, err
+	}
+	backupDir, err := getBackupDirectory()
+	if err != nil {
+		return nil, err
+	}
+	// A dry run must not create a vault that does not exist yet
+	if _, err := os.Stat(backupDir); dryRunMode() && os.IsNotExist(err) {
+		fmt.Printf("🧪 No vault at %s yet; planning against an empty one\n", backupDir)
+		return memoryVault()
+	}
+	solana.LoadEnvFiles() // An encrypted vault's key may be configured there
+	specs[0].Location = backupDir
+	vault, err := storage.OpenVault(specs, backupDir)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := selectedTenant()
+	if err != nil {
+		vault.Close()
+		return nil, err
+	}
+	limits, err := vaultLimits(tenant)
+	if err != nil {
+		vault.Close()
+		return nil, err
+	}
+	vault.SetLimits(limits)
+	checkVaultCluster(vault)
+	return vault, nil
+}
+
+// metadataCache is the vault's cache of off-chain metadata validators, or
+// nil when METADATA_CACHE=off. Dry runs read it without updating it.
+func metadataCache(vault *storage.FileStorage) *fetcher.MetadataCache {
+	// Cached bodies are stored as served, so an encrypted vault keeps none
+	if vault.Encrypted() {
+		return nil
+	}
+	return fetcher.MetadataCacheFromEnv(filepath.Join(vault.BaseDir(), ".cache", "metadata"), dryRunMode())
+}
+
+// configureSource applies the configured proxy, timeouts and TLS options,
+// the vault's metadata cache, the metadata cross-check and URI rewrites to
+// source
+func configureSource(source *backup.ChainSource, vault *storage.FileStorage) error {
+	opts, err := fetcher.HTTPOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	source.SetHTTPOptions(opts)
+	source.SetMetadataCache(metadataCache(vault))
+	source.SetCrossCheck(fetcher.CrossCheckFromEnv())
+	rewrites, err := fetcher.URIRewritesFromEnv()
+	if err != nil {
+		return err
+	}
+	source.SetURIRewrites(rewrites)
+	return nil
+}
+
+// checkVaultHeader refuses to run any command against a vault written by an
+// incompatible SolVault, before the command reads or writes anything
+func checkVaultHeader(cmd *cobra.Command, args []string) error {
+	if _, err := vaultBackends(); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if usingMemoryVault() {
+		return nil
+	}
+	backupDir, err := getBackupDirectory()
+	if err != nil {
+		return err
+	}
+	if err := storage.CheckVault(backupDir); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
+// checkVaultCluster records which cluster the vault backs up, and warns when
+// the configured RPC points at a different one
+func checkVaultCluster(vault *storage.FileStorage) {
+	cluster := solana.ClusterFromURL(solana.PrimaryRPCURL())
+	if cluster == "" {
+		return
+	}
+
+	recorded := vault.Header().Cluster
+	if recorded == "" {
+		if err := vault.RecordCluster(cluster); err != nil {
+			fmt.Printf("⚠️  Could not record cluster in vault header: %v\n", err)
+		}
+		return
+	}
+	if recorded != cluster {
+		fmt.Printf("⚠️  This vault backs up %s NFTs but SOLANA_RPC_URL points at %s\n", recorded, cluster)
+	}
+}
+
+// memoryVault creates the session's in-memory vault on first use
+func memoryVault() (*storage.FileStorage, error) {
+	if sessionVault == nil {
+		vault, err := storage.NewMemoryStorage()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("🧪 Using an in-memory vault; nothing will be saved to your archive\n")
+		sessionVault = vault
+	}
+	return sessionVault, nil
+}
+
+// discardSessionVault removes the in-memory vault, if one was used
+func discardSessionVault() {
+	if sessionVault == nil {
+		return
+	}
+	if err := sessionVault.Discard(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	sessionVault = nil
+}
@@ -1,109 +1,314 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/canonjson"
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/notify"
+	"github.com/NazWright/solvault/internal/phash"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/tsa"
 	"github.com/spf13/cobra"
 )
 
 // verifyCmd represents the verify command
 var verifyCmd = &cobra.Command{
-	Use:   "verify <mint-address-or-name>",
-	Short: "Verify NFT authenticity and optionally publish proof JSON",
+	Use:   "verify [mint-address-or-name]",
+	Short: "Verify NFT authenticity and write its proof JSON",
 	Long: `Verify the authenticity of a backed-up NFT by comparing hashes and 
 generating or updating proof documentation.
 
 This command will:
-• Recalculate image and metadata hashes
-• Compare against stored hash values
-• Generate or update proof.json with verification results
-• Optionally publish proof to web endpoint
+• Rehash every file in the backup (records, metadata, media, thumbnails)
+• Compare against integrity.json, written with the backup; backups made
+  before it existed get one on their first verify
+• Generate or update proof.json with verification results (the same as
+  'solvault proof generate'; see 'solvault proof' to anchor and publish it)
+• Check proof.json's timestamp token, if it was anchored, before a new proof
+  is written; anchored or published proofs are kept in proofs/
+• Send an alert (see solvault notify) if tampering is detected
+• Look up the wallet that holds the NFT on chain now, and mark the backup
+  burned if the NFT was burned (skip with --skip-onchain)
+• With --check-metadata, re-fetch the metadata and keep a new version
+  (see solvault diff) if the URI or off-chain JSON changed
+• With --lenient, accept an image whose bytes changed but whose decoded
+  pixels match the backup's, as when a gateway strips EXIF data or
+  recompresses losslessly; the result notes the byte-level difference
 
 Example:
   solvault verify "Cool Cat #1234"
-  solvault verify 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU --publish
-  solvault verify "Midnight Lion #01" --force-recompute`,
-	Args: cobra.ExactArgs(1),
+  solvault verify 7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU
+  solvault verify "Midnight Lion #01" --force-recompute
+  solvault verify "Cool Cat #1234" --wallet 5QfQ...ZsLk
+  solvault verify "Cool Cat #1234" --check-metadata
+  solvault verify "Cool Cat #1234" --lenient
+  solvault verify --all
+  solvault verify --all --collection "Cool Cats" --concurrency 8
+  solvault verify --remote --wallet 5QfQ...ZsLk
+
+With --all every stored NFT (optionally narrowed by --wallet/--collection) is
+verified, a summary is printed, verification_report.json is written to the
+vault root (or --report) and the command exits non-zero if any NFT is
+tampered.
+
+With --remote nothing local is rehashed: every file uploaded to Google Drive
+or Dropbox (see solvault mirror) is downloaded and checked against the
+vault's integrity manifests, so bit rot in the off-site copy is caught before
+it is needed. Copies that only predate a local change are reported as stale;
+the command exits non-zero if any copy is corrupt or missing files.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if verifyAll || verifyRemote {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runVerify,
 }
 
 var (
-	publish        bool
-	forceRecompute bool
-	skipOnChain    bool
+	publish           bool
+	forceRecompute    bool
+	skipOnChain       bool
+	verifyWallet      string
+	verifyAll         bool
+	verifyCollection  string
+	verifyConcurrency int
+	verifyReportPath  string
+	verifyMetadata    bool
+	verifyTimestamp   bool
+	verifyTSA         string
+	verifyRemote      bool
+	verifyLenient     bool
 )
 
 func runVerify(cmd *cobra.Command, args []string) error {
+	// Checking the mirror only reads, so it needs no dry-run guard
+	if verifyRemote {
+		return runVerifyRemote(cmd)
+	}
+	// Verifying writes proof.json, so a dry run can only preview a rehash
+	if dryRunMode() && !(verifyAll && forceRecompute) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--dry-run only previews 'verify --all --force-recompute'; verification itself writes proof.json")
+	}
+	if verifyAll {
+		return runVerifyAll(cmd)
+	}
+
 	identifier := args[0]
 	fmt.Printf("🔍 Verifying NFT: %s\n", identifier)
 
-	// Get backup directory
-	backupDir, err := getBackupDirectory()
+	nftPath, err := resolveNFTPath(identifier, verifyWallet)
 	if err != nil {
 		return err
 	}
-
-	// Find NFT directory
-	nftPath, err := findNFTDirectory(backupDir, identifier)
+	result, err := verifyNFT(cmd.Context(), nftPath)
 	if err != nil {
 		return err
 	}
 
+	// Explanation: --timestamp and --publish predate 'solvault proof' and
+	// are kept working for existing scripts
+	if verifyTimestamp {
+		if result.NewTimestamp, err = anchorProof(cmd.Context(), nftPath); err != nil {
+			return err
+		}
+	}
+	if publish {
+		if _, err := publishNFTProof(cmd.Context(), nftPath); err != nil {
+			fmt.Printf("⚠️  Failed to publish proof: %v\n", err)
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(struct {
+			*VerificationResult
+			ProofPath string `json:"proof_path"`
+		}{result, filepath.Join(nftPath, proof.FileName)})
+	}
+
+	return nil
+}
+
+// resolveNFTPath finds the backup directory of an NFT by mint or name in the
+// vault index, among one wallet's NFTs when wallet is set
+func resolveNFTPath(identifier, wallet string) (string, error) {
+	// Narrow the search to one wallet's NFTs when requested
+	if wallet != "" {
+		wallets, err := selectWallets(wallet)
+		if err != nil {
+			return "", err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return "", err
+	}
+	defer vault.Close()
+
+	matches := vault.Index().Search(identifier, wallet)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("NFT not found: %s", identifier)
+	}
+	entry := matches[0]
+	if len(matches) > 1 {
+		if entry, err = chooseNFT(identifier, matches); err != nil {
+			return "", err
+		}
+	}
+	return vault.EntryDir(entry), nil
+}
+
+// maxChoices is how many matches chooseNFT lists
+const maxChoices = 20
+
+// chooseNFT lists the NFTs matching an ambiguous identifier and, in a
+// terminal, asks which one was meant
+func chooseNFT(identifier string, matches []*storage.IndexEntry) (*storage.IndexEntry, error) {
+	fmt.Printf("⚠️  %d NFTs match %q:\n", len(matches), identifier)
+	shown := matches
+	if len(shown) > maxChoices {
+		shown = shown[:maxChoices]
+	}
+	for i, match := range shown {
+		label := match.Name
+		if match.Collection != "" {
+			label += " (" + match.Collection + ")"
+		}
+		fmt.Printf("  %2d. %-40s %s  wallet %s\n", i+1, truncateString(label, 40), match.Mint, shortAddress(match.Wallet))
+	}
+	if len(matches) > len(shown) {
+		fmt.Printf("      …and %d more\n", len(matches)-len(shown))
+	}
+
+	if jsonOutput() || quietOutput() || !isTerminal(os.Stdin) {
+		return nil, fmt.Errorf("%d NFTs match %q; use the mint address or a more specific name", len(matches), identifier)
+	}
+	fmt.Printf("Choose an NFT [1-%d, Enter to cancel]: ", len(shown))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(shown) {
+		return nil, fmt.Errorf("no NFT chosen")
+	}
+	return shown[choice-1], nil
+}
+
+// verifyNFT verifies one NFT, shows the result and writes its proof.json
+func verifyNFT(ctx context.Context, nftPath string) (*VerificationResult, error) {
+	vault, err := openVault()
+	if err != nil {
+		return nil, err
+	}
+	defer vault.Close()
+
 	// Perform verification
-	result, err := performVerification(nftPath)
+	fmt.Println("🔐 Computing hashes...")
+	result, err := performVerification(vault, nftPath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if verifyMetadata {
+		fmt.Println("🔗 Checking on-chain metadata...")
+		if err := checkMetadataVersion(ctx, nftPath, result); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	// Backups made before the vault format have no mint to look up
+	if !skipOnChain {
+		if mint, err := storedMint(nftPath); err == nil {
+			fmt.Println("🔗 Looking up the current owner...")
+			if result.CurrentOwner, err = lookupCurrentOwner(ctx, mint); err != nil {
+				fmt.Printf("⚠️  Failed to look up the current owner (use --skip-onchain to verify locally): %v\n", err)
+			} else if result.CurrentOwner.Burned {
+				if marked, err := markBurned(ctx, mint); err != nil {
+					result.Errors = append(result.Errors, err.Error())
+				} else if marked > 0 {
+					fmt.Println("🔥 The NFT was burned; its backup is marked burned and kept as a record")
+				}
+			}
+		}
 	}
 
 	// Display results
 	if err := displayVerificationResults(result); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Generate/update proof
 	if err := generateProof(nftPath, result); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Publish if requested
-	if publish {
-		if err := publishProof(nftPath, result); err != nil {
-			fmt.Printf("⚠️  Failed to publish proof: %v\n", err)
-		}
+	if result.Status == "tampered" {
+		notifyTampered(ctx, []*VerificationResult{result})
 	}
-
-	return nil
+	return result, nil
 }
 
 type VerificationResult struct {
-	NFTName      string
-	NFTPath      string
-	Status       string
-	ImageHash    string
-	StoredHash   string
-	MetadataHash string
-	HashMatch    bool
-	HasImage     bool
-	HasMetadata  bool
-	VerifiedAt   time.Time
-	Errors       []string
+	NFTName         string    `json:"nft_name"`
+	NFTPath         string    `json:"nft_path"`
+	Status          string    `json:"status"`
+	ImageHash       string    `json:"image_hash,omitempty"`
+	StoredHash      string    `json:"stored_hash,omitempty"`
+	MetadataHash    string    `json:"metadata_hash,omitempty"`
+	HashMatch       bool      `json:"hash_match"`
+	HasImage        bool      `json:"has_image"`
+	HasMetadata     bool      `json:"has_metadata"`
+	VerifiedAt      time.Time `json:"verified_at"`
+	Errors          []string  `json:"errors,omitempty"`
+	MetadataVersion int       `json:"metadata_version,omitempty"` // Set by --check-metadata
+	MetadataChanged bool      `json:"metadata_changed,omitempty"`
+	Warnings        []string  `json:"warnings,omitempty"` // Mint supply or authority risks
+	// Holder on chain at verification time; skipped by --skip-onchain
+	CurrentOwner *currentOwner `json:"current_owner,omitempty"`
+	// Every file checked against integrity.json
+	Integrity *storage.IntegrityReport `json:"integrity,omitempty"`
+	// Modified images compared with their backed-up perceptual hash
+	VisualChecks []visualCheck `json:"visual_checks,omitempty"`
+	// Authentic only because --lenient accepted images whose bytes changed
+	// but whose pixels did not
+	Lenient bool `json:"lenient,omitempty"`
+	// integrity.json was written by this run, for a backup that had none
+	IntegrityCreated bool `json:"integrity_created,omitempty"`
+	// Audit path to the wallet's Merkle root saved by the last backup
+	WalletRoot *storage.InclusionProof `json:"wallet_root,omitempty"`
+	// RFC 3161 timestamp of the previous proof.json, checked before it was
+	// rewritten
+	Timestamp *proofTimestamp `json:"timestamp,omitempty"`
+	// Timestamp of the new proof.json, requested by --timestamp
+	NewTimestamp *tsa.Info `json:"new_timestamp,omitempty"`
 }
 
-func performVerification(nftPath string) (*VerificationResult, error) {
+func performVerification(vault *storage.FileStorage, nftPath string) (*VerificationResult, error) {
 	result := &VerificationResult{
 		NFTName:    filepath.Base(nftPath),
 		NFTPath:    nftPath,
 		VerifiedAt: time.Now(),
 	}
 
-	fmt.Println("🔐 Computing hashes...")
-
 	// Check for required files
 	result.HasMetadata = fileExists(filepath.Join(nftPath, "metadata.json"))
 	result.HasImage = findImageFile(nftPath) != ""
@@ -127,8 +332,7 @@ func performVerification(nftPath string) (*VerificationResult, error) {
 
 	// Compute metadata hash
 	if result.HasMetadata {
-		metadataFile := filepath.Join(nftPath, "metadata.json")
-		hash, err := computeFileHash(metadataFile)
+		hash, err := computeMetadataHash(nftPath)
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Failed to compute metadata hash: %v", err))
 		} else {
@@ -136,37 +340,335 @@ func performVerification(nftPath string) (*VerificationResult, error) {
 		}
 	}
 
-	// Compare with stored hash
-	hashFile := filepath.Join(nftPath, "hash.txt")
-	if fileExists(hashFile) {
-		if storedHashBytes, err := os.ReadFile(hashFile); err == nil {
-			result.StoredHash = string(storedHashBytes)
-			result.HashMatch = result.ImageHash == result.StoredHash
+	// Check every file against integrity.json
+	if err := checkIntegrity(vault, nftPath, imageFile, result); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.VisualChecks = compareImages(nftPath, result.Integrity)
+	if verifyLenient && !result.HashMatch && pixelIdentical(result) {
+		result.HashMatch, result.Lenient = true, true
+	}
+	if result.HashMatch {
+		proof, err := proveInclusion(vault, nftPath)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.WalletRoot = proof
+	}
+
+	// An earlier proof.json stays evidence only while its token matches it
+	result.Timestamp = checkProofTimestamp(nftPath)
+
+	// Flag mint state that undermines scarcity, as recorded at backup time
+	if data, err := readVaultFile(filepath.Join(nftPath, "nft_data.json")); err == nil {
+		var stored storage.StoredNFT
+		if err := json.Unmarshal(data, &stored); err == nil {
+			result.Warnings = fetcher.AuthenticityWarnings(stored.NFTInfo)
 		}
 	}
 
 	// Determine overall status
 	if len(result.Errors) > 0 {
 		result.Status = "error"
-	} else if result.HashMatch || result.StoredHash == "" {
+	} else if result.HashMatch {
 		result.Status = "authentic"
 	} else {
 		result.Status = "tampered"
 	}
 
-	// Store new hash if none exists or force recompute
-	if result.StoredHash == "" || forceRecompute {
-		if result.ImageHash != "" {
-			if err := os.WriteFile(hashFile, []byte(result.ImageHash), 0644); err != nil {
-				result.Errors = append(result.Errors, fmt.Sprintf("Failed to save hash: %v", err))
-			} else {
-				result.StoredHash = result.ImageHash
-				result.HashMatch = true
+	return result, nil
+}
+
+// checkIntegrity compares the backup with its integrity.json and records
+// the outcome and the image's stored hash on result.
+//
+// Explanation: Backups made before integrity.json existed are migrated
+// here. If they carry the old single-image hash.txt, the image must still
+// match it before the current files are trusted as the new baseline;
+// hash.txt is then removed so there is only one record to tamper with.
+// --force-recompute rewrites the manifest from the files as they are now.
+func checkIntegrity(vault *storage.FileStorage, nftPath, imageFile string, result *VerificationResult) error {
+	legacyHash := filepath.Join(nftPath, "hash.txt")
+	report, err := vault.CheckIntegrity(nftPath)
+	if errors.Is(err, os.ErrNotExist) || forceRecompute {
+		if stored, readErr := os.ReadFile(legacyHash); readErr == nil && !forceRecompute && result.ImageHash != "" {
+			result.StoredHash = strings.TrimSpace(string(stored))
+			if result.StoredHash != result.ImageHash {
+				return nil // Tampered; keep hash.txt as the evidence
 			}
 		}
+		if _, err := vault.WriteIntegrity(nftPath); err != nil {
+			return err
+		}
+		os.Remove(legacyHash)
+		result.IntegrityCreated = true
+		report, err = vault.CheckIntegrity(nftPath)
+	}
+	if err != nil {
+		return err
 	}
 
-	return result, nil
+	result.Integrity = report
+	result.HashMatch = report.OK()
+	if rel, err := filepath.Rel(nftPath, imageFile); err == nil {
+		for _, file := range report.Files {
+			if file.Path == filepath.ToSlash(rel) && file.Expected != "" {
+				result.StoredHash = "sha256:" + file.Expected
+			}
+		}
+	}
+	return nil
+}
+
+// visualCheck is a modified image compared by what it shows
+type visualCheck struct {
+	Path     string `json:"path"`
+	Verdict  string `json:"verdict"`            // "pixel-identical", "re-encoded" or "different"
+	Distance int    `json:"distance,omitempty"` // Perceptual hash bits that differ, of 128
+	Error    string `json:"error,omitempty"`
+}
+
+// compareImages checks each modified image that has a perceptual hash in
+// the backup record, to tell a file that only lost its metadata or was
+// re-encoded losslessly (the same pixels), a re-encoded copy of the same
+// picture and a different picture apart. Either way the bytes changed and
+// the NFT is tampered, unless --lenient accepts the same pixels.
+//
+// Explanation: The stored hashes live in nft_data.json, so they are only
+// trusted while that file still passes its own integrity check; a record
+// edited along with the image would vouch for the new image.
+func compareImages(nftPath string, report *storage.IntegrityReport) []visualCheck {
+	if report == nil || report.OK() {
+		return nil
+	}
+	modified := make(map[string]bool)
+	for _, file := range report.Files {
+		if file.Status == storage.IntegrityModified {
+			modified[file.Path] = true
+		}
+	}
+	if len(modified) == 0 || modified["nft_data.json"] {
+		return nil
+	}
+	var stored storage.StoredNFT
+	data, err := readVaultFile(filepath.Join(nftPath, "nft_data.json"))
+	if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil {
+		return nil
+	}
+
+	var checks []visualCheck
+	for _, media := range stored.NFTInfo.MediaFiles {
+		path := "media/" + media.Filename
+		if media.PerceptualHash == nil || !modified[path] {
+			continue
+		}
+		check := visualCheck{Path: path}
+		current, err := readVaultFile(filepath.Join(nftPath, filepath.FromSlash(path)))
+		var img image.Image
+		if err == nil {
+			img, err = phash.Decode(current)
+		}
+		var hash *phash.Hash
+		if err == nil {
+			hash = phash.FromImage(img)
+		}
+		switch {
+		case err != nil:
+			check.Verdict, check.Error = "different", err.Error()
+		case media.PixelHash != "" && pixelsMatch(current, media.PixelHash):
+			check.Verdict = "pixel-identical"
+		case media.PerceptualHash.Similar(*hash):
+			check.Verdict, check.Distance = "re-encoded", media.PerceptualHash.Distance(*hash)
+		default:
+			check.Verdict, check.Distance = "different", media.PerceptualHash.Distance(*hash)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// pixelsMatch reports whether data decodes to the pixels hashed as want
+func pixelsMatch(data []byte, want string) bool {
+	pixels, err := phash.Pixels(data)
+	return err == nil && pixels == want
+}
+
+// pixelIdentical reports whether every file that failed the integrity check
+// is an image whose pixels are unchanged
+func pixelIdentical(result *VerificationResult) bool {
+	report := result.Integrity
+	if report == nil || report.Count(storage.IntegrityMissing) > 0 {
+		return false
+	}
+	identical := make(map[string]bool)
+	for _, check := range result.VisualChecks {
+		if check.Verdict == "pixel-identical" {
+			identical[check.Path] = true
+		}
+	}
+	for _, file := range report.Files {
+		if file.Status == storage.IntegrityModified && !identical[file.Path] {
+			return false
+		}
+	}
+	return len(identical) > 0
+}
+
+// describeVisualCheck explains a visual check in a sentence
+func describeVisualCheck(check visualCheck) string {
+	switch {
+	case check.Error != "":
+		return fmt.Sprintf("%s is no longer a readable image (%s)", check.Path, check.Error)
+	case check.Verdict == "pixel-identical":
+		return fmt.Sprintf("%s differs byte for byte but has identical pixels (metadata stripped or re-encoded losslessly)", check.Path)
+	case check.Verdict == "re-encoded":
+		return fmt.Sprintf("%s was re-encoded but shows the same picture (%d of 128 bits differ)", check.Path, check.Distance)
+	}
+	return fmt.Sprintf("%s shows a different picture (%d of 128 bits differ)", check.Path, check.Distance)
+}
+
+// integrityProblems describes the files that failed an integrity check
+// savedMerkles caches each wallet's saved Merkle tree, so verify --all
+// reads merkle.json once per wallet rather than once per NFT
+var savedMerkles = struct {
+	sync.Mutex
+	trees map[string]*storage.WalletMerkle
+}{trees: make(map[string]*storage.WalletMerkle)}
+
+// proveInclusion returns the audit path from a backup to the Merkle root
+// its wallet's last backup saved. It returns nil when no root was saved or
+// the backup changed since, as the saved root then does not cover it.
+func proveInclusion(vault *storage.FileStorage, nftPath string) (*storage.InclusionProof, error) {
+	wallet := filepath.Base(filepath.Dir(filepath.Dir(nftPath)))
+	entry := vault.Index().ByDirName(wallet, filepath.Base(nftPath))
+	if entry == nil {
+		return nil, nil
+	}
+
+	savedMerkles.Lock()
+	tree, ok := savedMerkles.trees[wallet]
+	if !ok {
+		var err error
+		if tree, err = vault.LoadMerkle(wallet); err != nil && !errors.Is(err, os.ErrNotExist) {
+			savedMerkles.Unlock()
+			return nil, err
+		}
+		savedMerkles.trees[wallet] = tree
+	}
+	savedMerkles.Unlock()
+	if tree == nil {
+		return nil, nil
+	}
+
+	integrity, err := vault.ReadIntegrity(nftPath)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := tree.Prove(entry.Mint)
+	if err != nil || proof.Digest != integrity.Digest() {
+		return nil, nil
+	}
+	return proof, nil
+}
+
+func integrityProblems(report *storage.IntegrityReport) []string {
+	var problems []string
+	if report == nil {
+		return nil
+	}
+	for _, file := range report.Files {
+		if file.Status == storage.IntegrityModified || file.Status == storage.IntegrityMissing {
+			problems = append(problems, fmt.Sprintf("%s %s", file.Path, file.Status))
+		}
+	}
+	return problems
+}
+
+// checkMetadataVersion re-fetches the NFT and, if its metadata URI or
+// off-chain JSON changed since the backup, saves it as a new version
+func checkMetadataVersion(ctx context.Context, nftPath string, result *VerificationResult) error {
+	var stored storage.StoredNFT
+	data, err := readVaultFile(filepath.Join(nftPath, "nft_data.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read NFT data: %w", err)
+	}
+	if err := json.Unmarshal(data, &stored); err != nil || stored.NFTInfo == nil {
+		return fmt.Errorf("failed to parse NFT data in %s", nftPath)
+	}
+	result.MetadataVersion = stored.Version
+
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+	source := backup.NewChainSource(client)
+	defer source.Close()
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(source, vault); err != nil {
+		return err
+	}
+
+	wallet, mint := stored.NFTInfo.Owner, stored.NFTInfo.MintAddress
+	fresh, err := source.FetchNFT(ctx, wallet, mint)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	result.Warnings = fetcher.AuthenticityWarnings(fresh) // The mint as it is now
+	if storage.MetadataFingerprint(fresh) == storage.MetadataFingerprint(stored.NFTInfo) {
+		return nil
+	}
+
+	// Keep the media and prices recorded with the backup; only the
+	// metadata is being versioned
+	fresh.Owner = wallet
+	fresh.MediaFiles = stored.NFTInfo.MediaFiles
+	fresh.Market = stored.NFTInfo.Market
+
+	if err := vault.SaveNFT(ctx, fresh); err != nil {
+		return fmt.Errorf("failed to save new metadata version: %w", err)
+	}
+	updated, err := vault.GetNFT(ctx, wallet, mint)
+	if err != nil {
+		return err
+	}
+	result.MetadataVersion, result.MetadataChanged = updated.Version, true
+	return nil
+}
+
+// notifyTampered alerts the configured sinks about hash mismatches
+func notifyTampered(ctx context.Context, tampered []*VerificationResult) {
+	if len(tampered) == 0 {
+		return
+	}
+
+	fields := make(map[string]string, len(tampered))
+	for _, result := range tampered {
+		if problems := integrityProblems(result.Integrity); len(problems) > 0 {
+			for _, check := range result.VisualChecks {
+				problems = append(problems, describeVisualCheck(check))
+			}
+			fields[result.NFTName] = strings.Join(problems, ", ")
+		} else {
+			fields[result.NFTName] = fmt.Sprintf("stored %s, computed %s", result.StoredHash, result.ImageHash)
+		}
+	}
+
+	sendNotification(ctx, notify.Event{
+		Kind:    notify.EventTamper,
+		Title:   fmt.Sprintf("%d tampered NFT(s) detected", len(tampered)),
+		Message: "Verification found backup files whose hash no longer matches integrity.json. Restore these NFTs from another backup.",
+		Fields:  fields,
+	})
 }
 
 func findImageFile(nftPath string) string {
@@ -179,22 +681,24 @@ func findImageFile(nftPath string) string {
 		}
 	}
 
-	// Fallback: look for any image file
-	entries, err := os.ReadDir(nftPath)
-	if err != nil {
-		return ""
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
+	// Fallback: look for any image file, including vault-managed media/
+	for _, dir := range []string{nftPath, filepath.Join(nftPath, "media")} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
 			continue
 		}
 
-		name := entry.Name()
-		ext := filepath.Ext(name)
-		switch ext {
-		case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
-			return filepath.Join(nftPath, name)
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			name := entry.Name()
+			ext := strings.ToLower(filepath.Ext(name))
+			switch ext {
+			case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+				return filepath.Join(dir, name)
+			}
 		}
 	}
 
@@ -208,14 +712,44 @@ func computeFileHash(filePath string) (string, error) {
 	}
 	defer file.Close()
 
+	// Encrypted and compressed files are hashed by their original content
+	// so hashes survive turning either on or off
+	reader := bufio.NewReader(file)
+	if head, _ := reader.Peek(max(crypt.MagicSize(), storage.CompressedMagicSize())); crypt.IsSealed(head) || storage.IsCompressed(head) {
+		data, err := readVaultFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+	}
+
 	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
+	if _, err := io.Copy(hasher, reader); err != nil {
 		return "", err
 	}
 
 	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), nil
 }
 
+// computeMetadataHash hashes the canonical JSON form of the metadata as the
+// gateway served it, or of metadata.json for backups without the raw copy,
+// so whitespace and key order do not change the hash
+func computeMetadataHash(nftPath string) (string, error) {
+	metadataFile := filepath.Join(nftPath, storage.RawMetadataFile)
+	if !fileExists(metadataFile) {
+		metadataFile = filepath.Join(nftPath, "metadata.json")
+	}
+	data, err := readVaultFile(metadataFile)
+	if err != nil {
+		return "", err
+	}
+	hash, err := canonjson.Hash(data)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hash, nil
+}
+
 func displayVerificationResults(result *VerificationResult) error {
 	fmt.Printf("\n🔍 Verification Results\n")
 	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
@@ -236,6 +770,9 @@ func displayVerificationResults(result *VerificationResult) error {
 	fmt.Println()
 
 	fmt.Printf("Verified At:  %s\n", result.VerifiedAt.Format("2006-01-02 15:04:05"))
+	if result.CurrentOwner != nil {
+		fmt.Printf("Owned By:     %s\n", describeOwner(result.CurrentOwner))
+	}
 
 	if result.ImageHash != "" {
 		fmt.Printf("\n🔐 Hash Information\n")
@@ -243,7 +780,9 @@ func displayVerificationResults(result *VerificationResult) error {
 		fmt.Printf("Current Hash: %s\n", result.ImageHash)
 		if result.StoredHash != "" {
 			fmt.Printf("Stored Hash:  %s\n", result.StoredHash)
-			if result.HashMatch {
+			if result.Lenient {
+				fmt.Printf("Hash Match:   🧩 Bytes differ, pixels identical (accepted by --lenient)\n")
+			} else if result.HashMatch {
 				fmt.Printf("Hash Match:   ✅ Verified\n")
 			} else {
 				fmt.Printf("Hash Match:   ❌ MISMATCH - Possible tampering detected!\n")
@@ -254,6 +793,60 @@ func displayVerificationResults(result *VerificationResult) error {
 	if result.MetadataHash != "" {
 		fmt.Printf("Metadata Hash: %s\n", result.MetadataHash)
 	}
+	if report := result.Integrity; report != nil {
+		checked := len(report.Files) - report.Count(storage.IntegrityUntracked)
+		if report.OK() {
+			fmt.Printf("Integrity:    ✅ %d file(s) match integrity.json\n", checked)
+		} else if result.Lenient {
+			fmt.Printf("Integrity:    🧩 %d file(s) match integrity.json; %d image(s) differ only in encoding\n", checked-len(integrityProblems(report)), len(integrityProblems(report)))
+			for _, check := range result.VisualChecks {
+				fmt.Printf("              • %s\n", describeVisualCheck(check))
+			}
+		} else {
+			fmt.Printf("Integrity:    ❌ %d of %d file(s) changed since the backup\n", len(integrityProblems(report)), checked)
+			for _, problem := range integrityProblems(report) {
+				fmt.Printf("              • %s\n", problem)
+			}
+			for _, check := range result.VisualChecks {
+				icon := "❌"
+				switch check.Verdict {
+				case "pixel-identical":
+					icon = "🧩"
+				case "re-encoded":
+					icon = "🎨"
+				}
+				fmt.Printf("              %s %s\n", icon, describeVisualCheck(check))
+			}
+			if pixelIdentical(result) {
+				fmt.Printf("              💡 Only encoding changed; 'verify --lenient' accepts it\n")
+			}
+		}
+		if untracked := report.Count(storage.IntegrityUntracked); untracked > 0 {
+			fmt.Printf("              ⚠️  %d file(s) not in integrity.json\n", untracked)
+		}
+		if result.IntegrityCreated {
+			fmt.Printf("              📝 integrity.json written for this backup\n")
+		}
+	}
+	if proof := result.WalletRoot; proof != nil {
+		fmt.Printf("Merkle Root:  🌳 %s (leaf %d of %d)\n", proof.Root, proof.Index+1, proof.Size)
+	}
+	if stamp := result.Timestamp; stamp != nil {
+		fmt.Printf("Proof Stamp:  %s\n", describeTimestamp(stamp))
+	}
+	if result.MetadataChanged {
+		fmt.Printf("Metadata:     🔀 changed on-chain, saved as version %d (see solvault diff)\n", result.MetadataVersion)
+	} else if result.MetadataVersion > 0 {
+		fmt.Printf("Metadata:     ✅ unchanged (version %d)\n", result.MetadataVersion)
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Printf("\n⚠️  Authenticity Warnings\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, warning := range result.Warnings {
+			fmt.Printf("• %s\n", warning)
+		}
+	}
 
 	// Show errors if any
 	if len(result.Errors) > 0 {
@@ -270,55 +863,112 @@ func displayVerificationResults(result *VerificationResult) error {
 func generateProof(nftPath string, result *VerificationResult) error {
 	fmt.Printf("📝 Generating proof document...\n")
 
-	proof := map[string]interface{}{
+	proofPath, err := writeProof(nftPath, result)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Proof saved to: %s\n", proofPath)
+	return nil
+}
+
+// writeProof writes proof.json for a verification result and returns its
+// path. A proof that was timestamped or published is moved into the NFT's
+// proof history first.
+func writeProof(nftPath string, result *VerificationResult) (string, error) {
+	doc := map[string]interface{}{
 		"nft_name":            result.NFTName,
-		"mint_address":        "", // TODO: Extract from metadata or parameter
+		"mint_address":        "",
 		"verified_by":         fmt.Sprintf("SolVault %s", Version),
 		"verified_at":         result.VerifiedAt.Format(time.RFC3339),
 		"image_hash":          result.ImageHash,
 		"metadata_hash":       result.MetadataHash,
+		"metadata_hash_form":  "rfc8785",
 		"status":              result.Status,
 		"hash_match":          result.HashMatch,
 		"verification_method": "local_sha256",
 	}
+	if mint, err := storedMint(nftPath); err == nil {
+		doc["mint_address"] = mint.String()
+	}
+
+	if result.Integrity != nil {
+		doc["verification_method"] = "integrity_manifest_sha256"
+		doc["files_verified"] = len(result.Integrity.Files) - result.Integrity.Count(storage.IntegrityUntracked)
+		if problems := integrityProblems(result.Integrity); len(problems) > 0 {
+			doc["changed_files"] = problems
+		}
+		if result.Lenient {
+			// The changed files are images whose decoded pixels still match
+			doc["verification_method"] = "integrity_manifest_sha256_lenient_pixels"
+		}
+	}
+	if result.WalletRoot != nil {
+		doc["wallet_merkle_root"] = result.WalletRoot
+	}
 
 	// Add error information if present
 	if len(result.Errors) > 0 {
-		proof["errors"] = result.Errors
+		doc["errors"] = result.Errors
+	}
+
+	// Keep a proof others can hold us to rather than overwrite it
+	proofPath := filepath.Join(nftPath, proof.FileName)
+	kept, err := proof.Kept(nftPath)
+	if err != nil {
+		return "", err
+	}
+	if kept {
+		at := time.Now()
+		if info, err := os.Stat(proofPath); err == nil {
+			at = info.ModTime()
+		}
+		if result.Timestamp != nil && result.Timestamp.Info != nil {
+			at = result.Timestamp.Time
+		}
+		archived, err := proof.Archive(nftPath, at)
+		if err != nil {
+			return "", err
+		}
+		if result.Timestamp != nil {
+			result.Timestamp.Archived = archived
+		}
+		doc["previous_proof"] = filepath.ToSlash(archived)
 	}
 
 	// Write proof file
-	proofPath := filepath.Join(nftPath, "proof.json")
-	proofData, err := json.MarshalIndent(proof, "", "  ")
+	proofData, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal proof data: %w", err)
+		return "", fmt.Errorf("failed to marshal proof data: %w", err)
 	}
 
 	if err := os.WriteFile(proofPath, proofData, 0644); err != nil {
-		return fmt.Errorf("failed to write proof file: %w", err)
+		return "", fmt.Errorf("failed to write proof file: %w", err)
 	}
 
-	fmt.Printf("✅ Proof saved to: %s\n", proofPath)
-	return nil
-}
-
-func publishProof(nftPath string, result *VerificationResult) error {
-	fmt.Printf("🌐 Publishing proof...\n")
-
-	// TODO: Implement actual proof publishing
-	// This would upload the proof.json and potentially the image to a web endpoint
-	// and return a shareable URL
-
-	fmt.Printf("⚠️  Proof publishing not yet implemented\n")
-	fmt.Printf("   Proof file available locally at: %s/proof.json\n", nftPath)
-
-	return nil
+	return proofPath, nil
 }
 
 func init() {
 	rootCmd.AddCommand(verifyCmd)
 
 	verifyCmd.Flags().BoolVar(&publish, "publish", false, "publish proof to web endpoint")
-	verifyCmd.Flags().BoolVar(&forceRecompute, "force-recompute", false, "recompute and update stored hashes")
+	verifyCmd.Flags().BoolVar(&forceRecompute, "force-recompute", false, "rewrite integrity.json from the files as they are now")
 	verifyCmd.Flags().BoolVar(&skipOnChain, "skip-onchain", false, "skip on-chain verification (local only)")
+	verifyCmd.Flags().StringVar(&verifyWallet, "wallet", "", "only search NFTs backed up for this wallet")
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "verify every stored NFT and write a report")
+	verifyCmd.Flags().StringVar(&verifyCollection, "collection", "", "with --all or --remote, only verify NFTs in this collection")
+	verifyCmd.Flags().BoolVar(&verifyRemote, "remote", false, "download mirrored copies and check them against the integrity manifests")
+	verifyCmd.Flags().IntVar(&verifyConcurrency, "concurrency", 4, "with --all, number of NFTs verified in parallel")
+	verifyCmd.Flags().BoolVar(&verifyLenient, "lenient", false, "accept images whose bytes changed but whose decoded pixels did not, e.g. stripped EXIF")
+	verifyCmd.Flags().BoolVar(&verifyMetadata, "check-metadata", false, "re-fetch the metadata and save a new version if it changed")
+	verifyCmd.Flags().BoolVar(&verifyTimestamp, "timestamp", false, "have an RFC 3161 timestamp authority sign proof.json (saved as proof.json.tsr)")
+	verifyCmd.Flags().StringVar(&verifyTSA, "tsa", "", "with --timestamp, timestamp authority URL (default $TSA_URL or "+tsa.DefaultURL+")")
+	verifyCmd.Flags().MarkDeprecated("publish", "use 'solvault proof publish'")
+	verifyCmd.Flags().MarkDeprecated("timestamp", "use 'solvault proof anchor'")
+	verifyCmd.Flags().MarkDeprecated("tsa", "use 'solvault proof anchor --tsa'")
+	verifyCmd.Flags().StringVar(&verifyReportPath, "report", "", "with --all, report path (default <vault>/verification_report.json)")
+
+	verifyCmd.MarkFlagsMutuallyExclusive("all", "remote")
+	guardDestructive(verifyCmd)
 }
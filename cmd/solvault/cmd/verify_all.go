@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NazWright/solvault/internal/progress"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// VerificationReport summarizes a verify --all run
+type VerificationReport struct {
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	Wallet     string                `json:"wallet,omitempty"`
+	Collection string                `json:"collection,omitempty"`
+	Total      int                   `json:"total"`
+	Counts     map[string]int        `json:"counts"` // authentic, tampered, incomplete, error
+	Tampered   []string              `json:"tampered"`
+	Warned     []string              `json:"warned,omitempty"` // NFTs with authenticity warnings
+	Results    []*VerificationResult `json:"results"`
+	// Interrupted is set when Ctrl+C stopped the run; Results then holds only
+	// the NFTs verified before it
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// verifyTarget is one NFT directory selected for batch verification
+type verifyTarget struct {
+	Name string
+	Path string
+}
+
+func runVerifyAll(cmd *cobra.Command) error {
+	targets, err := collectVerifyTargets()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("📭 No NFTs matched; nothing to verify")
+		return nil
+	}
+
+	// Recomputing replaces the stored hashes tampering is detected against
+	if forceRecompute {
+		plan := bulkPlan{Action: "rehash"}
+		for _, target := range targets {
+			plan.Items = append(plan.Items, target.Name)
+		}
+		if !confirmBulk(cmd, plan) {
+			return nil
+		}
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	workers := verifyConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	fmt.Printf("🔐 Verifying %d NFT(s) with %d worker(s)...\n", len(targets), workers)
+
+	report := &VerificationReport{
+		StartedAt:  time.Now(),
+		Wallet:     verifyWallet,
+		Collection: verifyCollection,
+		Total:      len(targets),
+		Counts:     map[string]int{"authentic": 0, "tampered": 0, "incomplete": 0, "error": 0},
+		Tampered:   []string{},
+		Results:    make([]*VerificationResult, len(targets)),
+	}
+
+	// Explanation: Hashing is I/O bound, so a small worker pool speeds up
+	// large vaults without opening thousands of files at once
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	bar := progress.New("🔐 Verifying", len(targets))
+	jobs := make(chan int)
+	ctx := cmd.Context()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := verifyTargetDir(ctx, vault, targets[i])
+
+				mu.Lock()
+				report.Results[i] = result
+				done++
+				if result.Status != "authentic" {
+					bar.Println("   [%d/%d] %s: %s", done, len(targets), result.NFTName, result.Status)
+				}
+				bar.Increment()
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range targets {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed // Workers finish the NFT in hand; the rest are skipped
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	bar.Finish()
+
+	var tampered []*VerificationResult
+	verified := report.Results[:0]
+	for _, result := range report.Results {
+		if result == nil {
+			continue
+		}
+		verified = append(verified, result)
+		report.Counts[result.Status]++
+		if result.Status == "tampered" {
+			report.Tampered = append(report.Tampered, result.NFTName)
+			tampered = append(tampered, result)
+		}
+		if len(result.Warnings) > 0 {
+			report.Warned = append(report.Warned, result.NFTName)
+		}
+	}
+	report.Results = verified
+	report.Interrupted = ctx.Err() != nil
+	report.FinishedAt = time.Now()
+
+	reportPath := verifyReportPath
+	if reportPath == "" {
+		reportPath = filepath.Join(backupDir, "verification_report.json")
+	}
+	if err := writeVerificationReport(reportPath, report); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printVerificationReport(report, reportPath)
+	}
+
+	if len(tampered) > 0 {
+		notifyTampered(ctx, tampered)
+		cmd.SilenceUsage = true
+		return fmt.Errorf("tampering detected in %d NFT(s)", len(tampered))
+	}
+	if report.Interrupted {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("verification interrupted after %d of %d NFT(s)", len(verified), report.Total)
+	}
+	return nil
+}
+
+// verifyTargetDir verifies one NFT and refreshes its proof.json, stamping
+// it with --timestamp
+func verifyTargetDir(ctx context.Context, vault *storage.FileStorage, target verifyTarget) *VerificationResult {
+	result, err := performVerification(vault, target.Path)
+	if err != nil {
+		return &VerificationResult{
+			NFTName:    target.Name,
+			NFTPath:    target.Path,
+			Status:     "error",
+			VerifiedAt: time.Now(),
+			Errors:     []string{err.Error()},
+		}
+	}
+	result.NFTName = target.Name
+
+	if _, err := writeProof(target.Path, result); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	if verifyTimestamp {
+		if result.NewTimestamp, err = stampProof(ctx, target.Path); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	return result
+}
+
+// collectVerifyTargets lists vault NFTs matching --wallet and --collection.
+// Backups shared by several wallets are verified once.
+func collectVerifyTargets() ([]verifyTarget, error) {
+	var wallet string
+	if verifyWallet != "" {
+		wallets, err := selectWallets(verifyWallet)
+		if err != nil {
+			return nil, err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, err := openVault()
+	if err != nil {
+		return nil, err
+	}
+	defer vault.Close()
+
+	seen := make(map[string]bool)
+	var targets []verifyTarget
+	for _, entry := range vault.Index().List() {
+		if wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		path := vault.EntryDir(entry)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		if verifyCollection != "" {
+			var stored storage.StoredNFT
+			data, err := readVaultFile(filepath.Join(path, "nft_data.json"))
+			if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil || !inCollection(&stored, verifyCollection) {
+				continue
+			}
+		}
+
+		name := entry.Name
+		if name == "" {
+			name = entry.Mint
+		}
+		targets = append(targets, verifyTarget{Name: name, Path: path})
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Path < targets[j].Path })
+	return targets, nil
+}
+
+func writeVerificationReport(path string, report *VerificationReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verification report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write verification report: %w", err)
+	}
+	return nil
+}
+
+func printVerificationReport(report *VerificationReport, path string) {
+	fmt.Printf("\n📊 Verification Report\n")
+	fmt.Printf("═══════════════════════════════════════════════════════════════════════════════\n")
+	elapsed := report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond)
+	if report.Interrupted {
+		fmt.Printf("Verified:     %d of %d NFT(s) in %s (interrupted)\n", len(report.Results), report.Total, elapsed)
+	} else {
+		fmt.Printf("Verified:     %d NFT(s) in %s\n", report.Total, elapsed)
+	}
+	fmt.Printf("Authentic:    %d ✅\n", report.Counts["authentic"])
+	fmt.Printf("Tampered:     %d ❌\n", report.Counts["tampered"])
+	fmt.Printf("Incomplete:   %d ⚠️\n", report.Counts["incomplete"])
+	if report.Counts["error"] > 0 {
+		fmt.Printf("Errors:       %d 🚫\n", report.Counts["error"])
+	}
+
+	if len(report.Tampered) > 0 {
+		fmt.Printf("\n❌ Tampered NFTs\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, result := range report.Results {
+			if result.Status != "tampered" {
+				continue
+			}
+			fmt.Printf("• %s\n", result.NFTName)
+			for _, check := range result.VisualChecks {
+				fmt.Printf("    %s\n", describeVisualCheck(check))
+			}
+		}
+	}
+
+	var lenient []string
+	for _, result := range report.Results {
+		if result.Lenient {
+			lenient = append(lenient, result.NFTName)
+		}
+	}
+	if len(lenient) > 0 {
+		fmt.Printf("\n🧩 Authentic with byte-level image differences (--lenient)\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, name := range lenient {
+			fmt.Printf("• %s\n", name)
+		}
+	}
+
+	if len(report.Warned) > 0 {
+		fmt.Printf("\n⚠️  NFTs with authenticity warnings (see solvault verify <name>)\n")
+		fmt.Printf("───────────────────────────────────────────────────────────────────────────────\n")
+		for _, name := range report.Warned {
+			fmt.Printf("• %s\n", name)
+		}
+	}
+
+	fmt.Printf("\n📄 Report saved to: %s\n", path)
+}
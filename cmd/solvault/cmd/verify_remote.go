@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/mirror"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// runVerifyRemote downloads every mirrored copy (narrowed by --wallet and
+// --collection) and checks it against the vault's integrity manifests
+func runVerifyRemote(cmd *cobra.Command) error {
+	var wallet string
+	if verifyWallet != "" {
+		wallets, err := selectWallets(verifyWallet)
+		if err != nil {
+			return err
+		}
+		wallet = wallets[0].String()
+	}
+
+	vault, m, err := openMirror()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+
+	var entries []*storage.IndexEntry
+	for _, entry := range vault.Index().List() {
+		if wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		if verifyCollection != "" {
+			var stored storage.StoredNFT
+			data, err := vault.ReadFile(filepath.Join(vault.EntryDir(entry), "nft_data.json"))
+			if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil || !inCollection(&stored, verifyCollection) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		fmt.Println("📭 No NFTs matched; nothing to verify")
+		return nil
+	}
+
+	if !jsonOutput() {
+		fmt.Printf("☁️  Downloading mirrored copies of %d NFT(s) to verify...\n", len(entries))
+	}
+	ctx := cmd.Context()
+	reports, err := m.Verify(ctx, entries)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to verify mirror: %w", err)
+	}
+	if reports == nil {
+		reports = []*mirror.CopyReport{}
+	}
+
+	failed := 0
+	for _, report := range reports {
+		if !report.OK() {
+			failed++
+		}
+	}
+	if jsonOutput() {
+		if err := printJSON(reports); err != nil {
+			return err
+		}
+	} else {
+		printCopyReports(reports)
+	}
+
+	cmd.SilenceUsage = true
+	if ctx.Err() != nil {
+		return fmt.Errorf("remote verification interrupted after %d mirrored copy(ies)", len(reports))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d mirrored copy(ies) are corrupt, missing files or unreadable", failed)
+	}
+	return nil
+}
+
+// printCopyReports lists the problems found in mirrored copies and a summary
+func printCopyReports(reports []*mirror.CopyReport) {
+	intact, stale := 0, 0
+	for _, report := range reports {
+		name := report.Name
+		if name == "" {
+			name = report.Mint
+		}
+		if report.Error != "" {
+			fmt.Printf("❌ %s (%s): %s\n", name, report.Provider, report.Error)
+			continue
+		}
+		for _, file := range report.Files {
+			switch file.State {
+			case mirror.CopyCorrupt:
+				fmt.Printf("❌ %s (%s): %s is corrupt", name, report.Provider, file.Path)
+				if file.Error != "" {
+					fmt.Printf(": %s", file.Error)
+				}
+				fmt.Println()
+			case mirror.CopyMissing:
+				fmt.Printf("❌ %s (%s): %s is missing\n", name, report.Provider, file.Path)
+			}
+		}
+		if report.OK() {
+			intact++
+			if report.Count(mirror.CopyStale) > 0 {
+				stale++
+			}
+		}
+	}
+
+	fmt.Printf("\n📊 %d mirrored copy(ies): %d intact, %d with problems\n", len(reports), intact, len(reports)-intact)
+	if stale > 0 {
+		fmt.Printf("🔄 %d copy(ies) predate local changes; run 'solvault mirror push' to update them\n", stale)
+	}
+}
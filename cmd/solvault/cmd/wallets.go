@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// selectWallets returns the wallet passed with --wallet, or every configured
+// wallet (WALLET_ADDRESS plus WALLET_ADDRESSES) when the flag is empty. With
+// --tenant, a tenant limited to some wallets gets those instead.
+func selectWallets(walletFlag string) ([]solanago.PublicKey, error) {
+	if walletFlag = strings.TrimSpace(walletFlag); walletFlag != "" {
+		wallet, err := solana.ResolveWallet(walletFlag)
+		if err != nil {
+			return nil, err
+		}
+		return []solanago.PublicKey{wallet}, nil
+	}
+
+	// A tenant limited to some wallets syncs those by default
+	tenant, err := selectedTenant()
+	if err != nil {
+		return nil, err
+	}
+	if tenant != nil && len(tenant.Limits.Wallets) > 0 {
+		var wallets []solanago.PublicKey
+		for _, address := range tenant.Limits.Wallets {
+			wallet, err := solanago.PublicKeyFromBase58(address)
+			if err != nil {
+				return nil, fmt.Errorf("invalid wallet %s of tenant %s: %w", address, tenant.ID, err)
+			}
+			wallets = append(wallets, wallet)
+		}
+		return wallets, nil
+	}
+
+	wallets, err := solana.LoadWallets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wallets: %w. Run 'solvault init' first", err)
+	}
+	return wallets, nil
+}
@@ -1,79 +1,385 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
 	"time"
 
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/daemon"
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/notify"
+	"github.com/NazWright/solvault/internal/provenance"
+	"github.com/NazWright/solvault/internal/schedule"
+	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
 	"github.com/spf13/cobra"
 )
 
 // watchCmd represents the watch command
 var watchCmd = &cobra.Command{
 	Use:   "watch",
-	Short: "Monitor wallet for new NFT mints and back them up automatically",
-	Long: `Watch mode monitors your Solana wallet for new NFT mint events and 
+	Short: "Monitor wallet for new NFTs and back them up automatically",
+	Long: `Watch mode monitors your Solana wallet for NFTs arriving in it and
 automatically backs up metadata, images, and generates verification hashes.
 
 This command will:
 • Connect to Solana RPC endpoint
-• Monitor your wallet address for new transactions
-• Detect NFT mint events in real-time
+• Monitor every configured wallet (WALLET_ADDRESS and WALLET_ADDRESSES)
+• Detect every NFT newly appearing in a wallet, whether minted, received
+  by transfer or bought, and tell which from its transaction
+• Detect NFTs leaving a wallet (sold, transferred or drained), record the
+  outgoing transaction and send a high-priority alert, as an early warning
+  of theft
+• Send a critical alert when more than DRAIN_ALERT_COUNT NFTs leave a wallet
+  within DRAIN_ALERT_MINUTES, and with DRAIN_SNAPSHOT=true re-back up and
+  list everything it still holds
 • Automatically download and backup NFT data
 • Generate proof hashes and metadata
+• Back up mint lists dropped into the inbox (see solvault inbox)
+• Run jobs added with solvault schedule
+• Journal every event to the vault (see solvault events)
+
+Creator mode (--collection or --candy-machine) watches your own project
+instead of the wallets: every newly minted NFT of the collection is backed
+up, whoever mints it, into the collection's archive (see solvault collection
+snapshot), and who holds each NFT is saved to a holder snapshot whenever it
+changes. A candy machine is read for its collection and its mint counter,
+and the collection is only listed when the counter moves. The first poll
+archives whatever of the collection is not archived yet. Needs a DAS-enabled
+RPC (DAS_RPC_URL or NFT_PROVIDER).
 
 Example:
   solvault watch
   solvault watch --daemon
-  solvault watch --poll-interval 15`,
+  solvault watch --poll-interval 15
+  solvault watch --candy-machine 8xYz...Cm3v --daemon
+  solvault watch --collection J1S9...gh9w
+  solvault watch status
+  solvault watch stop`,
 	RunE: runWatch,
 }
 
+// watchStopCmd stops a watcher started with --daemon
+var watchStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background watcher",
+	RunE:  runWatchStop,
+}
+
+// watchStatusCmd reports on a watcher started with --daemon
+var watchStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the background watcher is running",
+	RunE:  runWatchStatus,
+}
+
 var (
-	daemon       bool
-	pollInterval int
+	daemonMode          bool
+	pollInterval        int
+	offlineAlertMinutes int
+	watchCollections    []string
+	watchCandyMachines  []string
 )
 
+// inboxPollInterval is how often the watcher looks for new drop files
+const inboxPollInterval = 5 * time.Second
+
 func runWatch(cmd *cobra.Command, args []string) error {
-	fmt.Println("👀 Starting SolVault watcher...")
+	paths, err := daemon.DefaultPaths("watch")
+	if err != nil {
+		return err
+	}
+
+	// The parent only validates and detaches; the child does the watching
+	if daemonMode && !daemon.IsChild() {
+		if err := validateConfig(); err != nil {
+			return err
+		}
+
+		pid, err := daemon.Detach(paths, os.Args[1:])
+		if err != nil {
+			return fmt.Errorf("failed to start daemon: %w", err)
+		}
+
+		fmt.Printf("🔄 SolVault watcher started in the background (PID %d)\n", pid)
+		fmt.Printf("   Logs: %s\n", paths.LogFile)
+		fmt.Println("   Stop it with: solvault watch stop")
+		return nil
+	}
+
+	fmt.Printf("👀 Starting SolVault watcher... [%s]\n", time.Now().Format(time.RFC3339))
 
 	// TODO: Load configuration from .env
 	if err := validateConfig(); err != nil {
 		return err
 	}
 
-	if daemon {
-		fmt.Println("🔄 Running in daemon mode...")
-		// TODO: Implement daemon mode in future version
-		fmt.Println("⚠️  Daemon mode not yet implemented. Running in foreground mode.")
+	if daemon.IsChild() {
+		if err := daemon.WritePIDFile(paths); err != nil {
+			return err
+		}
+		defer daemon.RemovePIDFile(paths)
+		fmt.Printf("🔄 Running in daemon mode (PID %d)\n", os.Getpid())
 	} else {
 		fmt.Println("🖥️  Running in foreground mode. Press Ctrl+C to stop.")
 	}
 
 	// Set up graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx := cmd.Context()
+
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	wallets := config.Wallets
+
+	client, err := solana.NewClient(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	defer client.Close()
+
+	source := backup.NewChainSource(client)
+	defer source.Close()
+	history := provenance.NewChainSource(client)
+
+	vault, err := openVault()
+	if err != nil {
+		return err
+	}
+	defer vault.Close()
+	if err := configureSource(source, vault); err != nil {
+		return err
+	}
+
+	// Creator mode watches collections instead of the wallets
+	var collections []*collectionWatch
+	if len(watchCollections)+len(watchCandyMachines) > 0 {
+		if collections, err = newCollectionWatches(ctx, client, source, config.RPCURL, watchCollections, watchCandyMachines); err != nil {
+			return err
+		}
+		for _, watch := range collections {
+			defer watch.vault.Close()
+		}
+	}
+
+	// Backups a crash cut short are picked up by the first poll
+	interrupted := 0
+	for _, entry := range vault.Index().List() {
+		if entry.InProgress {
+			interrupted++
+		}
+	}
+	if interrupted > 0 {
+		fmt.Printf("♻️  %d interrupted backup(s) will be redone\n", interrupted)
+	}
+
+	// Everything the watcher does is journaled for 'solvault events'
+	events := journal.Open(vault.BaseDir())
+	recordWatchEvent(events, journal.Event{Kind: journal.WatchStarted, Detail: fmt.Sprintf("%d wallet(s) every %ds", len(wallets), pollInterval)})
+	defer recordWatchEvent(events, journal.Event{Kind: journal.WatchStopped})
 
 	// Start monitoring loop
-	fmt.Printf("🔍 Monitoring wallet with %d second intervals...\n", pollInterval)
+	polled := wallets
+	if len(collections) > 0 {
+		polled = nil
+		fmt.Printf("🎨 Creator mode: monitoring %d collection(s) for new mints with %d second intervals...\n", len(collections), pollInterval)
+		for _, watch := range collections {
+			if watch.candyMachine != nil {
+				fmt.Printf("   • %s (candy machine %s) → %s\n", watch.collection.String(), watch.candyMachine.String(), watch.vault.BaseDir())
+			} else {
+				fmt.Printf("   • %s → %s\n", watch.collection.String(), watch.vault.BaseDir())
+			}
+		}
+	} else {
+		fmt.Printf("🔍 Monitoring %d wallet(s) with %d second intervals...\n", len(wallets), pollInterval)
+		for _, wallet := range wallets {
+			fmt.Printf("   • %s\n", wallet.String())
+		}
+	}
 	ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
 	defer ticker.Stop()
 
+	// Drop files are checked more often than the chain is polled
+	box, err := openInbox()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📥 Watching inbox %s for mint lists\n", box.Dir())
+	inboxTicker := time.NewTicker(inboxPollInterval)
+	defer inboxTicker.Stop()
+
+	// Scheduled jobs run alongside the poll loop
+	if store, err := openScheduleStore(); err == nil {
+		if schedules, err := store.List(); err == nil && len(schedules) > 0 {
+			fmt.Printf("⏰ %d scheduled job(s) loaded from %s\n", len(schedules), store.Path())
+		}
+		runner := schedule.NewRunner(store, journaledJob(events, runScheduledCommand), func(format string, args ...interface{}) {
+			fmt.Printf(format, args...)
+		})
+		go runner.Run(ctx)
+	} else {
+		fmt.Printf("⚠️  Scheduler disabled: %v\n", err)
+	}
+
+	// Alert once when the RPC has been unreachable for too long, and
+	// whenever an NFT leaves a wallet
+	watchdog := &notify.Watchdog{Threshold: offlineAlertThreshold(), Subject: "the Solana RPC"}
+	sinks, err := loadNotifier()
+	if err != nil {
+		fmt.Printf("⚠️  Notifications misconfigured: %v\n", err)
+	} else if len(sinks) > 0 {
+		watchdog.Notifier = sinks
+		fmt.Printf("📣 Alerting via %s after %s offline and when an NFT leaves a wallet\n", sinks.Name(), watchdog.Threshold)
+	}
+	drain := drainDetector(sinks)
+	if drain.Limit > 0 {
+		fmt.Printf("🚨 Drain alert when more than %d NFT(s) leave a wallet within %s\n", drain.Limit, drain.Window)
+	}
+
+	// The retention policy is applied between polls, never during a sync
+	var retentionTimer *time.Timer
+	var retentionC <-chan time.Time
+	retention, err := retentionSchedule()
+	if err != nil {
+		fmt.Printf("⚠️  Retention disabled: %v\n", err)
+	} else if retention != nil {
+		next := retention.Next(time.Now())
+		retentionTimer = time.NewTimer(time.Until(next))
+		defer retentionTimer.Stop()
+		retentionC = retentionTimer.C
+		fmt.Printf("🧹 Applying the retention policy on %s, next at %s\n", retention, next.Format("2006-01-02 15:04"))
+	}
+
+	// A full vault or disk is alerted on once, not on every poll
+	storageAlerted := false
+
 	for {
 		select {
 		case <-ticker.C:
-			if err := checkForNewNFTs(); err != nil {
-				fmt.Printf("❌ Error checking for NFTs: %v\n", err)
+			if event, err := watchdog.Observe(ctx, client.TestConnection(ctx), time.Now()); err != nil {
+				fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+			} else if event != nil {
+				fmt.Printf("📣 %s\n", event.Title)
+			}
+
+			for _, watch := range collections {
+				if err := checkCollection(ctx, watch, client, events); err != nil {
+					fmt.Printf("❌ Error checking collection %s: %v\n", watch.collection.String(), err)
+				}
+			}
+			for _, wallet := range polled {
+				summary, err := checkForNewNFTs(ctx, vault, source, history, wallet, events, sinks)
+				storageAlerted = alertStorage(ctx, sinks, storageAlerted, wallet.String(), summary, err)
+				if err != nil {
+					fmt.Printf("❌ Error checking for NFTs in %s: %v\n", wallet.String(), err)
+					continue
+				}
+				checkForDrain(ctx, drain, vault, source, history, wallet, summary, events, sinks)
 			}
-		case <-sigChan:
+		case <-retentionC:
+			enforceRetention(ctx, vault)
+			retentionTimer.Reset(time.Until(retention.Next(time.Now())))
+		case <-inboxTicker.C:
+			if _, err := processInbox(ctx, box, vault, source, wallets, events); err != nil {
+				fmt.Printf("❌ Error processing inbox: %v\n", err)
+			}
+		case <-ctx.Done():
 			fmt.Println("\n🛑 Shutting down SolVault watcher...")
 			return nil
 		}
 	}
 }
 
+func runWatchStop(cmd *cobra.Command, args []string) error {
+	paths, err := daemon.DefaultPaths("watch")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🛑 Stopping SolVault watcher...")
+	pid, err := daemon.Stop(paths, 15*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to stop watcher: %w", err)
+	}
+
+	fmt.Printf("✅ Watcher stopped (PID %d)\n", pid)
+	return nil
+}
+
+func runWatchStatus(cmd *cobra.Command, args []string) error {
+	paths, err := daemon.DefaultPaths("watch")
+	if err != nil {
+		return err
+	}
+
+	status, err := daemon.ReadStatus(paths)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		result := map[string]interface{}{
+			"running":  status.Running,
+			"pid_file": paths.PIDFile,
+			"log_file": paths.LogFile,
+		}
+		if status.Running {
+			result["pid"] = status.PID
+			result["started_at"] = status.StartedAt
+		}
+		return printJSON(result)
+	}
+
+	if !status.Running {
+		fmt.Println("⏹️  Watcher is not running")
+		if status.PID != 0 {
+			fmt.Printf("   Stale PID file for %d found: %s\n", status.PID, paths.PIDFile)
+		}
+		return nil
+	}
+
+	fmt.Printf("✅ Watcher is running (PID %d)\n", status.PID)
+	fmt.Printf("   Started:  %s (%s ago)\n", status.StartedAt.Format("2006-01-02 15:04:05"),
+		time.Since(status.StartedAt).Round(time.Second))
+	fmt.Printf("   Logs:     %s\n", paths.LogFile)
+	return nil
+}
+
+// drainDetector reads DRAIN_ALERT_COUNT and DRAIN_ALERT_MINUTES
+func drainDetector(sinks notify.Multi) *notify.DrainDetector {
+	drain := &notify.DrainDetector{Limit: 3, Window: 10 * time.Minute}
+	if n, err := strconv.Atoi(os.Getenv("DRAIN_ALERT_COUNT")); err == nil && n >= 0 {
+		drain.Limit = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("DRAIN_ALERT_MINUTES")); err == nil && n > 0 {
+		drain.Window = time.Duration(n) * time.Minute
+	}
+	if len(sinks) > 0 {
+		drain.Notifier = sinks
+	}
+	return drain
+}
+
+// offlineAlertThreshold reads --offline-alert, then NOTIFY_OFFLINE_MINUTES
+func offlineAlertThreshold() time.Duration {
+	minutes := offlineAlertMinutes
+	if minutes <= 0 {
+		if n, err := strconv.Atoi(os.Getenv("NOTIFY_OFFLINE_MINUTES")); err == nil && n > 0 {
+			minutes = n
+		} else {
+			minutes = 10
+		}
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 func validateConfig() error {
 	// TODO: Implement configuration validation
 	// Check if .env exists and contains required values
@@ -85,16 +391,171 @@ func validateConfig() error {
 	return nil
 }
 
-func checkForNewNFTs() error {
-	// TODO: Implement actual NFT monitoring logic
-	// This is a placeholder that will be implemented in the listener module
-	fmt.Printf("⏰ [%s] Checking for new NFTs...\n", time.Now().Format("15:04:05"))
-	return nil
+// checkForNewNFTs backs up anything new in the wallet and notes NFTs that left it
+// Explanation: Change detection on existing NFTs re-fetches all metadata, which
+// is too heavy for every tick; 'solvault sync' does the full comparison. New
+// NFTs are found by diffing the wallet's token accounts against the index, so
+// transfers and purchases are caught as well as mints, and so are NFTs that
+// left; history then reads the mint's last transactions to tell how.
+func checkForNewNFTs(ctx context.Context, vault *storage.FileStorage, source backup.Source, history provenance.RecentSource, wallet solanago.PublicKey, events *journal.Journal, sinks notify.Multi) (*backup.Summary, error) {
+	fmt.Printf("⏰ [%s] Checking for new NFTs in %s...\n", time.Now().Format("15:04:05"), wallet.String())
+
+	summary, err := backup.Sync(ctx, vault, source, wallet, backup.Options{
+		NewOnly:    true,
+		Market:     market.FromEnv(),
+		Mirror:     backupMirror(vault),
+		Thumbnails: thumbnail.SizesFromEnv(),
+		Journal:    events,
+		History:    history,
+		Progress:   func(msg string) { fmt.Println(msg) },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range summary.Changes {
+		switch change.Kind {
+		case backup.ChangeFailed:
+			fmt.Printf("⚠️  %s: %s\n", change.Mint, change.Error)
+		case backup.ChangeTransferred, backup.ChangeBurned:
+			alertDeparture(ctx, sinks, wallet, change)
+		}
+	}
+	return summary, nil
+}
+
+// checkForDrain feeds a poll's departures to the drain rule. When it fires,
+// the alert is journaled and, with DRAIN_SNAPSHOT, everything the wallet
+// still holds is backed up again and listed.
+func checkForDrain(ctx context.Context, drain *notify.DrainDetector, vault *storage.FileStorage, source backup.Source, history provenance.RecentSource, wallet solanago.PublicKey, summary *backup.Summary, events *journal.Journal, sinks notify.Multi) {
+	event, err := drain.Observe(ctx, wallet.String(), departedMints(summary), time.Now())
+	if err != nil {
+		fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+	}
+	if event == nil {
+		return
+	}
+	fmt.Printf("🚨 %s\n", event.Title)
+	recordWatchEvent(events, journal.Event{Kind: journal.DrainDetected, Wallet: wallet.String(), Detail: event.Fields["Departed"]})
+
+	if snapshot, _ := strconv.ParseBool(os.Getenv("DRAIN_SNAPSHOT")); !snapshot {
+		return
+	}
+	fmt.Println("📸 Snapshotting what the wallet still holds...")
+	snapshot, path, err := backup.Snapshot(ctx, vault, source, wallet, event.Title, backup.Options{
+		Market:     market.FromEnv(),
+		Mirror:     backupMirror(vault),
+		Thumbnails: thumbnail.SizesFromEnv(),
+		Journal:    events,
+		History:    history,
+		Progress:   func(msg string) { fmt.Println(msg) },
+	})
+	if err != nil {
+		fmt.Printf("❌ Snapshot failed: %v\n", err)
+		return
+	}
+	fmt.Printf("📸 Snapshot of %d remaining NFT(s) saved to %s\n", len(snapshot.Held), path)
+
+	// NFTs taken while the snapshot ran are alerted on like any other
+	for _, change := range snapshot.Summary.Changes {
+		if change.Kind == backup.ChangeTransferred || change.Kind == backup.ChangeBurned {
+			alertDeparture(ctx, sinks, wallet, change)
+		}
+	}
+	if _, err := drain.Observe(ctx, wallet.String(), departedMints(snapshot.Summary), time.Now()); err != nil {
+		fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+	}
+}
+
+// departedMints lists the NFTs a sync found gone from the wallet
+func departedMints(summary *backup.Summary) []string {
+	var mints []string
+	for _, change := range summary.Changes {
+		if change.Kind == backup.ChangeTransferred || change.Kind == backup.ChangeBurned {
+			mints = append(mints, change.Mint)
+		}
+	}
+	return mints
+}
+
+// alertDeparture sends a high-priority alert that an NFT left wallet
+// Explanation: Every departure gets its own alert, sent once the poll that
+// saw it finishes, so a wallet being drained is reported within one poll
+// interval rather than in the next sync summary.
+func alertDeparture(ctx context.Context, sinks notify.Multi, wallet solanago.PublicKey, change backup.Change) {
+	if len(sinks) == 0 {
+		return
+	}
+	name := change.Name
+	if name == "" {
+		name = change.Mint
+	}
+
+	event := notify.Event{
+		Kind:     notify.EventDeparted,
+		Priority: notify.PriorityHigh,
+		Title:    fmt.Sprintf("%s left wallet %s", name, shortAddress(wallet.String())),
+		Message: "An NFT is no longer held by a watched wallet. Its backup is kept and marked as no longer held. " +
+			"If you did not sell or send it, the wallet may be compromised: move what is left to a new wallet now.",
+		Fields: map[string]string{
+			"Mint":   change.Mint,
+			"Wallet": wallet.String(),
+			"Status": string(change.Kind),
+		},
+	}
+	if departure := change.Departure; departure != nil {
+		event.Message = fmt.Sprintf("%s was %s. ", name, departure.DescribeDeparture()) + event.Message
+		event.Fields["Transaction"] = departure.Signature
+		if departure.To != "" {
+			event.Fields["Recipient"] = departure.To
+		}
+	} else {
+		event.Fields["Transaction"] = "not found yet; see 'solvault history " + change.Mint + "'"
+	}
+
+	if err := sinks.Notify(ctx, event); err != nil {
+		fmt.Printf("⚠️  Failed to send notification: %v\n", err)
+		return
+	}
+	fmt.Printf("📣 Departure alert sent via %s\n", sinks.Name())
+}
+
+// recordWatchEvent journals an event, warning rather than stopping the
+// watcher when the journal cannot be written
+func recordWatchEvent(events *journal.Journal, event journal.Event) {
+	if err := events.Record(event); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// journaledJob runs scheduled jobs with run and journals each one, as a
+// verification run when it is 'solvault verify'
+func journaledJob(events *journal.Journal, run schedule.ExecFunc) schedule.ExecFunc {
+	return func(ctx context.Context, sched *schedule.Schedule) error {
+		started := time.Now()
+		err := run(ctx, sched)
+
+		event := journal.Event{Kind: journal.ScheduledJobRun, Detail: sched.Command(), Duration: time.Since(started).Seconds()}
+		if len(sched.Args) > 0 && sched.Args[0] == "verify" {
+			event.Kind = journal.VerificationRun
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		recordWatchEvent(events, event)
+		return err
+	}
 }
 
 func init() {
 	rootCmd.AddCommand(watchCmd)
+	watchCmd.AddCommand(watchStopCmd)
+	watchCmd.AddCommand(watchStatusCmd)
 
-	watchCmd.Flags().BoolVar(&daemon, "daemon", false, "run in background daemon mode")
+	watchCmd.Flags().BoolVar(&daemonMode, "daemon", false, "run in background daemon mode")
 	watchCmd.Flags().IntVar(&pollInterval, "poll-interval", 30, "polling interval in seconds")
+	watchCmd.Flags().IntVar(&offlineAlertMinutes, "offline-alert", 0, "alert after the RPC is unreachable this many minutes (default $NOTIFY_OFFLINE_MINUTES, then 10)")
+	watchCmd.Flags().StringArrayVar(&watchCollections, "collection", nil, "creator mode: back up every new mint of this collection instead of watching wallets (repeatable)")
+	watchCmd.Flags().StringArrayVar(&watchCandyMachines, "candy-machine", nil, "creator mode: back up every NFT this candy machine mints into its collection (repeatable)")
+	watchCmd.Flags().StringVar(&inboxDir, "inbox", "", "drop folder for mint lists (default $INBOX_DIR, then ~/.solvault/inbox)")
 }
@@ -0,0 +1,222 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/xuri/excelize/v2"
+)
+
+// InventoryRow is one NFT in the portfolio inventory kept for insurance and
+// accounting
+type InventoryRow struct {
+	Wallet       string
+	Mint         string
+	Name         string
+	Collection   string
+	BackedUpAt   time.Time
+	Status       string    // Backup status, as shown by 'solvault list'
+	Verification string    // Result of the last proof, e.g. authentic; empty when never proven
+	VerifiedAt   time.Time // Zero when never proven
+	MediaBytes   int64
+	LastSaleSOL  float64 // 0 when never sold
+	LastSaleAt   time.Time
+	FloorSOL     float64 // Collection floor when backed up
+	Marketplace  string
+}
+
+// inventoryColumns are the header of both inventory formats
+var inventoryColumns = []string{
+	"wallet", "mint", "name", "collection", "backed_up_at", "status", "verification",
+	"verified_at", "media_bytes", "last_sale_sol", "last_sale_at", "floor_sol", "marketplace",
+}
+
+// NewInventoryRow fills an inventory row from a stored NFT. Status and the
+// proof result are not part of nft_data.json and are left to the caller.
+func NewInventoryRow(wallet string, stored *storage.StoredNFT) InventoryRow {
+	row := InventoryRow{Wallet: wallet, BackedUpAt: stored.UpdatedAt}
+	if row.BackedUpAt.IsZero() {
+		row.BackedUpAt = stored.StoredAt
+	}
+
+	info := stored.NFTInfo
+	if info == nil {
+		return row
+	}
+	row.Mint = info.MintAddress.String()
+	if metadata := info.Metadata; metadata != nil {
+		row.Name = metadata.Name
+		row.Collection = metadata.Collection.Name
+	}
+	for _, media := range info.MediaFiles {
+		row.MediaBytes += media.Size
+	}
+	if quote := info.Market; quote != nil {
+		row.Marketplace = quote.Marketplace
+		row.LastSaleSOL = quote.LastSaleSOL()
+		row.FloorSOL = quote.FloorSOL()
+		if quote.LastSaleAt != nil {
+			row.LastSaleAt = *quote.LastSaleAt
+		}
+	}
+	return row
+}
+
+// WriteInventoryCSV writes the rows as CSV with a header line. Times are
+// RFC 3339 in UTC and prices are in SOL; unknown values are left empty.
+func WriteInventoryCSV(w io.Writer, rows []InventoryRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(inventoryColumns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Wallet, row.Mint, row.Name, row.Collection,
+			csvTime(row.BackedUpAt), row.Status, row.Verification, csvTime(row.VerifiedAt),
+			strconv.FormatInt(row.MediaBytes, 10), csvSOL(row.LastSaleSOL), csvTime(row.LastSaleAt),
+			csvSOL(row.FloorSOL), row.Marketplace,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", row.Mint, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvSOL(sol float64) string {
+	if sol == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(sol, 'f', -1, 64)
+}
+
+// inventorySheet is the worksheet the XLSX inventory is written to
+const inventorySheet = "Inventory"
+
+// WriteInventoryXLSX writes the rows as an Excel workbook with one
+// "Inventory" sheet.
+//
+// Explanation: Dates, sizes and prices are stored as real dates and numbers
+// rather than text, so a spreadsheet can sum, sort and filter them without
+// conversion. The header row is frozen and carries an autofilter.
+func WriteInventoryXLSX(w io.Writer, rows []InventoryRow) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), inventorySheet); err != nil {
+		return fmt.Errorf("failed to name sheet: %w", err)
+	}
+
+	header, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create header style: %w", err)
+	}
+	dateFormat := "yyyy-mm-dd hh:mm"
+	date, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return fmt.Errorf("failed to create date style: %w", err)
+	}
+	solFormat := "0.000######"
+	sol, err := f.NewStyle(&excelize.Style{CustomNumFmt: &solFormat})
+	if err != nil {
+		return fmt.Errorf("failed to create price style: %w", err)
+	}
+	bytes, err := f.NewStyle(&excelize.Style{NumFmt: 3}) // #,##0
+	if err != nil {
+		return fmt.Errorf("failed to create size style: %w", err)
+	}
+
+	stream, err := f.NewStreamWriter(inventorySheet)
+	if err != nil {
+		return fmt.Errorf("failed to open sheet: %w", err)
+	}
+	widths := []float64{46, 46, 30, 24, 17, 12, 12, 17, 14, 14, 17, 12, 14}
+	for i, width := range widths {
+		if err := stream.SetColWidth(i+1, i+1, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return fmt.Errorf("failed to freeze header: %w", err)
+	}
+
+	cells := make([]interface{}, len(inventoryColumns))
+	for i, column := range inventoryColumns {
+		cells[i] = excelize.Cell{StyleID: header, Value: column}
+	}
+	if err := stream.SetRow("A1", cells); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for i, row := range rows {
+		cells := []interface{}{
+			row.Wallet, row.Mint, row.Name, row.Collection,
+			xlsxTime(row.BackedUpAt, date), row.Status, row.Verification, xlsxTime(row.VerifiedAt, date),
+			excelize.Cell{StyleID: bytes, Value: row.MediaBytes}, xlsxSOL(row.LastSaleSOL, sol),
+			xlsxTime(row.LastSaleAt, date), xlsxSOL(row.FloorSOL, sol), row.Marketplace,
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := stream.SetRow(cell, cells); err != nil {
+			return fmt.Errorf("failed to write row for %s: %w", row.Mint, err)
+		}
+	}
+
+	if err := stream.Flush(); err != nil {
+		return fmt.Errorf("failed to write sheet: %w", err)
+	}
+	last, _ := excelize.CoordinatesToCellName(len(inventoryColumns), len(rows)+1)
+	if err := f.AutoFilter(inventorySheet, "A1:"+last, nil); err != nil {
+		return fmt.Errorf("failed to add filter: %w", err)
+	}
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+// xlsxTime is a date cell, or an empty one for a zero time.
+//
+// Explanation: Excel has no time zones, so times are written in UTC like the
+// CSV, keeping the two formats comparable.
+func xlsxTime(t time.Time, style int) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return excelize.Cell{StyleID: style, Value: t.UTC()}
+}
+
+func xlsxSOL(sol float64, style int) interface{} {
+	if sol == 0 {
+		return nil
+	}
+	return excelize.Cell{StyleID: style, Value: sol}
+}
+
+// InventoryFormat returns "csv" or "xlsx" for an inventory file name, or ""
+// when the extension is neither
+func InventoryFormat(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		return "csv"
+	case strings.HasSuffix(lower, ".xlsx"):
+		return "xlsx"
+	}
+	return ""
+}
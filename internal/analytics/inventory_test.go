@@ -0,0 +1,317 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/xuri/excelize/v2"
+)
+
+func testInventory() []InventoryRow {
+	soldAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stored := &storage.StoredNFT{
+		NFTInfo: &fetcher.NFTInfo{
+			MintAddress: solanago.MustPublicKeyFromBase58("ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3"),
+			Metadata: &fetcher.NFTMetadata{
+				Name:       "Cool Cat #1",
+				Collection: fetcher.Collection{Name: "Cool Cats"},
+			},
+			MediaFiles: []*fetcher.MediaFile{{Size: 1000}, {Size: 24}},
+			Market: &market.Quote{
+				Marketplace:      "magiceden",
+				FloorLamports:    500_000_000,
+				LastSaleLamports: 1_250_000_000,
+				LastSaleAt:       &soldAt,
+			},
+		},
+		UpdatedAt: time.Date(2026, 4, 2, 9, 30, 0, 0, time.UTC),
+	}
+
+	row := NewInventoryRow("wallet1", stored)
+	row.Status = "verified"
+	row.Verification = "authentic"
+	row.VerifiedAt = time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
+
+	// An NFT with no metadata, market data or proof
+	bare := NewInventoryRow("wallet1", &storage.StoredNFT{
+		NFTInfo:  &fetcher.NFTInfo{MintAddress: solanago.MustPublicKeyFromBase58("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")},
+		StoredAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+	})
+	bare.Status = "incomplete"
+	return []InventoryRow{row, bare}
+}
+
+func TestNewInventoryRow(t *testing.T) {
+	rows := testInventory()
+	row := rows[0]
+	if row.Name != "Cool Cat #1" || row.Collection != "Cool Cats" || row.MediaBytes != 1024 {
+		t.Errorf("Unexpected row: %+v", row)
+	}
+	if row.LastSaleSOL != 1.25 || row.FloorSOL != 0.5 || row.Marketplace != "magiceden" || row.LastSaleAt.IsZero() {
+		t.Errorf("Unexpected market data: %+v", row)
+	}
+	if !rows[1].BackedUpAt.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the stored time when never updated, got %v", rows[1].BackedUpAt)
+	}
+}
+
+func TestWriteInventoryCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteInventoryCSV(&buf, testInventory()); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d records", len(records))
+	}
+
+	want := []string{
+		"wallet1", "ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3", "Cool Cat #1", "Cool Cats",
+		"2026-04-02T09:30:00Z", "verified", "authentic", "2026-04-03T00:00:00Z",
+		"1024", "1.25", "2026-03-01T12:00:00Z", "0.5", "magiceden",
+	}
+	for i, value := range want {
+		if records[1][i] != value {
+			t.Errorf("Column %s: expected %q, got %q", records[0][i], value, records[1][i])
+		}
+	}
+	if records[2][6] != "" || records[2][9] != "" || records[2][10] != "" {
+		t.Errorf("Expected empty unknown values, got %q", records[2])
+	}
+}
+
+func TestWriteInventoryXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteInventoryXLSX(&buf, testInventory()); err != nil {
+		t.Fatalf("Failed to write XLSX: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("Failed to open XLSX: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := f.GetRows(inventorySheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatalf("Failed to read sheet: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "wallet" || rows[1][2] != "Cool Cat #1" {
+		t.Fatalf("Unexpected rows: %q", rows)
+	}
+	if rows[1][8] != "1024" || rows[1][9] != "1.25" {
+		t.Errorf("Expected numeric size and price cells, got %q and %q", rows[1][8], rows[1][9])
+	}
+
+	backedUp, err := f.GetCellValue(inventorySheet, "E2")
+	if err != nil {
+		t.Fatalf("Failed to read date cell: %v", err)
+	}
+	if backedUp != "2026-04-02 09:30" {
+		t.Errorf("Expected a formatted date cell, got %q", backedUp)
+	}
+}
+
+func TestInventoryFormat(t *testing.T) {
+	tests := map[string]string{
+		"inventory.csv":  "csv",
+		"Inventory.XLSX": "xlsx",
+		"backup.tar.gz":  "",
+	}
+	for path, want := range tests {
+		if got := InventoryFormat(path); got != want {
+			t.Errorf("InventoryFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// fakeProvider quotes every mint of a collection at one floor
+type fakeProvider struct {
+	floors map[string]uint64 // By mint
+	asked  int
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Quote(ctx context.Context, mint string) (*market.Quote, error) {
+	f.asked++
+	floor, ok := f.floors[mint]
+	if !ok {
+		return nil, market.ErrNotFound
+	}
+	return &market.Quote{Marketplace: "fake", FloorLamports: floor, FetchedAt: time.Now()}, nil
+}
+
+func TestPortfolio(t *testing.T) {
+	recorded := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	nft := func(collection string, floor uint64, at time.Time) *storage.StoredNFT {
+		info := &fetcher.NFTInfo{MintAddress: solanago.NewWallet().PublicKey(), Metadata: &fetcher.NFTMetadata{Collection: fetcher.Collection{Name: collection}}}
+		if floor > 0 {
+			info.Market = &market.Quote{Marketplace: "magiceden", FloorLamports: floor, FetchedAt: at}
+		}
+		return &storage.StoredNFT{NFTInfo: info}
+	}
+	nfts := []*storage.StoredNFT{
+		nft("Cats", 1_000_000_000, recorded),
+		nft("Cats", 2_000_000_000, recorded.Add(time.Hour)), // The newer floor wins
+		nft("Dogs", 5_000_000_000, recorded),
+		nft("Birds", 0, time.Time{}),
+		nft("", 9_000_000_000, recorded), // No shared floor
+	}
+
+	portfolio := BuildPortfolio(nfts)
+	tests := []struct {
+		collection string
+		count      int
+		value      float64
+	}{
+		{"Dogs", 1, 5},
+		{"Cats", 2, 4},
+		{Uncollected, 1, 0},
+		{"Birds", 1, 0},
+	}
+	if len(portfolio.Collections) != len(tests) {
+		t.Fatalf("Expected %d collections, got %+v", len(tests), portfolio.Collections)
+	}
+	for i, tt := range tests {
+		got := portfolio.Collections[i]
+		if got.Collection != tt.collection || got.Count != tt.count || got.ValueSOL != tt.value {
+			t.Errorf("Collection %d: got %s ×%d = %v SOL, want %s ×%d = %v SOL", i, got.Collection, got.Count, got.ValueSOL, tt.collection, tt.count, tt.value)
+		}
+	}
+	if portfolio.NFTs != 5 || portfolio.TotalSOL != 9 || portfolio.Unpriced != 2 {
+		t.Errorf("Unexpected totals: %d NFTs, %v SOL, %d unpriced", portfolio.NFTs, portfolio.TotalSOL, portfolio.Unpriced)
+	}
+
+	// Live floors replace recorded ones; unknown collections keep theirs
+	birds := portfolio.Collections[3].Mints[0]
+	cats := portfolio.Collections[1].Mints[0]
+	provider := &fakeProvider{floors: map[string]uint64{cats: 10_000_000_000, birds: 500_000_000}}
+	if failed, err := portfolio.PriceLive(context.Background(), provider, nil); failed != 0 || err != nil {
+		t.Fatalf("Failed to price live: %d failures, %v", failed, err)
+	}
+	if provider.asked != 3 {
+		t.Errorf("Expected one lookup per named collection, got %d", provider.asked)
+	}
+	if top := portfolio.Top(1); len(top) != 1 || top[0].Collection != "Cats" || top[0].FloorSource != FloorLive || top[0].ValueSOL != 20 {
+		t.Errorf("Expected Cats on top at the live floor, got %+v", top[0])
+	}
+	if portfolio.TotalSOL != 25.5 || portfolio.Unpriced != 1 {
+		t.Errorf("Unexpected live totals: %v SOL, %d unpriced", portfolio.TotalSOL, portfolio.Unpriced)
+	}
+}
+
+func TestRarity(t *testing.T) {
+	entry := func(mint, collection string, traits map[string]string) *storage.IndexEntry {
+		return &storage.IndexEntry{Wallet: "wallet", Mint: mint, Name: mint, Collection: collection, Attributes: traits}
+	}
+	rarities := Rarity([]*storage.IndexEntry{
+		entry("crown", "DeGods", map[string]string{"Hat": "Crown", "Eyes": "Laser"}),
+		entry("cap-1", "DeGods", map[string]string{"Hat": "Cap", "Eyes": "Laser"}),
+		entry("cap-2", "DeGods", map[string]string{"Hat": "Cap", "Eyes": "Laser"}),
+		entry("bare", "DeGods", map[string]string{"Hat": "Cap"}),
+		entry("cat", "", nil),
+	})
+
+	if len(rarities) != 2 || rarities[0].Collection != "DeGods" || rarities[1].Collection != Uncollected {
+		t.Fatalf("Expected DeGods then uncollected, got %+v", rarities)
+	}
+	degods := rarities[0]
+	if degods.NFTs != 4 || len(degods.Traits) != 2 {
+		t.Fatalf("Expected 4 NFTs with 2 trait types, got %d and %d", degods.NFTs, len(degods.Traits))
+	}
+
+	// Eyes sorts first; the missing value counts as the rarest
+	eyes := degods.Traits[0]
+	if eyes.Trait != "Eyes" || eyes.Values[0] != (TraitCount{Value: NoTrait, Count: 1, Percent: 25}) || eyes.Values[1].Count != 3 {
+		t.Errorf("Unexpected Eyes distribution %+v", eyes)
+	}
+
+	tests := []struct {
+		mint  string
+		score float64
+		rank  int
+	}{
+		{"crown", 4 + 4.0/3, 1},     // Rare hat
+		{"bare", 4.0/3 + 4, 1},      // Missing eyes is as rare
+		{"cap-1", 4.0/3 + 4.0/3, 3}, // Common everything, tied
+		{"cap-2", 4.0/3 + 4.0/3, 3},
+	}
+	for _, tt := range tests {
+		got := degods.Lookup(tt.mint)
+		if got == nil || got.Rank != tt.rank || got.Score != tt.score {
+			t.Errorf("%s: got %+v, want rank %d with score %.2f", tt.mint, got, tt.rank, tt.score)
+		}
+	}
+	if rarities[1].Lookup("cat").Score != 0 {
+		t.Errorf("An NFT without traits should score 0")
+	}
+}
+
+func TestRecordRarity(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+
+	save := func(name, collection, hat string) *fetcher.NFTInfo {
+		md := &fetcher.NFTMetadata{Name: name, Collection: fetcher.Collection{Name: collection}}
+		md.Attributes = append(md.Attributes, fetcher.Attribute{TraitType: "Hat", Value: hat})
+		info := &fetcher.NFTInfo{MintAddress: solanago.NewWallet().PublicKey(), Owner: wallet, FetchedAt: time.Now(), Metadata: md}
+		if err := store.SaveNFT(ctx, info); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+		return info
+	}
+	crowned := save("DeGod #1", "DeGods", "Crown")
+	save("DeGod #2", "DeGods", "Cap")
+	save("DeGod #3", "DeGods", "Cap")
+	cat := save("Cat #1", "Cats", "Crown")
+
+	recorded, err := RecordRarity(ctx, store, 3)
+	if err != nil {
+		t.Fatalf("Failed to record rarity: %v", err)
+	}
+	if recorded != 3 {
+		t.Errorf("Expected the 3 DeGods ranked, got %d", recorded)
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, crowned.MintAddress)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	rarity := stored.Rarity
+	if rarity == nil || rarity.Collection != "DeGods" || rarity.Rank != 1 || rarity.Of != 3 || rarity.Score != 3 {
+		t.Fatalf("Unexpected rarity %+v", rarity)
+	}
+	if len(rarity.Traits) != 1 || rarity.Traits[0].Value != "Crown" || rarity.Traits[0].Count != 1 {
+		t.Errorf("Unexpected trait rarity %+v", rarity.Traits)
+	}
+	if stored, err := store.GetNFT(ctx, wallet, cat.MintAddress); err != nil || stored.Rarity != nil {
+		t.Errorf("A collection below the sample size should not be ranked")
+	}
+
+	// Unchanged ranks are not rewritten, and re-saving a backup keeps its rank
+	if err := store.SaveNFT(ctx, crowned); err != nil {
+		t.Fatalf("Failed to re-save NFT: %v", err)
+	}
+	if recorded, err := RecordRarity(ctx, store, 3); err != nil || recorded != 0 {
+		t.Errorf("Expected nothing to record, got %d (%v)", recorded, err)
+	}
+	if recorded, _ := RecordRarity(ctx, store, 0); recorded != 0 {
+		t.Errorf("A sample of 0 should turn ranking off")
+	}
+}
@@ -0,0 +1,189 @@
+package analytics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/parquet-go/parquet-go"
+)
+
+// NFTRow is one NFT flattened for analytics (nfts.parquet)
+type NFTRow struct {
+	Wallet               string    `parquet:"wallet"`
+	Mint                 string    `parquet:"mint"`
+	Name                 string    `parquet:"name"`
+	Symbol               string    `parquet:"symbol"`
+	Collection           string    `parquet:"collection"`
+	CollectionFamily     string    `parquet:"collection_family"`
+	MetadataURI          string    `parquet:"metadata_uri"`
+	Image                string    `parquet:"image"`
+	SellerFeeBasisPoints int32     `parquet:"seller_fee_basis_points"`
+	AttributeCount       int32     `parquet:"attribute_count"`
+	MediaCount           int32     `parquet:"media_count"`
+	MediaBytes           int64     `parquet:"media_bytes"`
+	HasMetadata          bool      `parquet:"has_metadata"`
+	Verified             bool      `parquet:"verified"`
+	Marketplace          string    `parquet:"marketplace"`
+	FloorLamports        int64     `parquet:"floor_lamports"`                  // Collection floor when backed up
+	LastSaleLamports     int64     `parquet:"last_sale_lamports"`              // 0 when never sold
+	LastSaleAt           time.Time `parquet:"last_sale_at,timestamp,optional"` // Null when never sold
+	Version              int32     `parquet:"version"`
+	FetchedAt            time.Time `parquet:"fetched_at,timestamp"`
+	StoredAt             time.Time `parquet:"stored_at,timestamp"`
+	UpdatedAt            time.Time `parquet:"updated_at,timestamp"`
+}
+
+// AttributeRow is one trait of one NFT (attributes.parquet), so traits can be
+// grouped and counted without parsing JSON
+type AttributeRow struct {
+	Wallet       string   `parquet:"wallet"`
+	Mint         string   `parquet:"mint"`
+	Collection   string   `parquet:"collection"`
+	TraitType    string   `parquet:"trait_type"`
+	Value        string   `parquet:"value"`
+	NumericValue *float64 `parquet:"numeric_value,optional"` // Set when the value is a number
+}
+
+// MediaRow is one downloaded media file (media.parquet)
+type MediaRow struct {
+	Wallet       string    `parquet:"wallet"`
+	Mint         string    `parquet:"mint"`
+	URL          string    `parquet:"url"`
+	Filename     string    `parquet:"filename"`
+	MediaType    string    `parquet:"media_type"`
+	ContentType  string    `parquet:"content_type"`
+	Size         int64     `parquet:"size"`
+	Checksum     string    `parquet:"checksum"`
+	DownloadedAt time.Time `parquet:"downloaded_at,timestamp"`
+}
+
+// Dataset holds the flattened rows for a whole vault
+type Dataset struct {
+	NFTs       []NFTRow
+	Attributes []AttributeRow
+	Media      []MediaRow
+}
+
+// Add flattens one stored NFT into the dataset
+func (d *Dataset) Add(wallet string, stored *storage.StoredNFT) {
+	info := stored.NFTInfo
+	if info == nil {
+		return
+	}
+
+	row := NFTRow{
+		Wallet:      wallet,
+		Mint:        info.MintAddress.String(),
+		MetadataURI: info.MetadataURI,
+		MediaCount:  int32(len(info.MediaFiles)),
+		HasMetadata: info.Metadata != nil,
+		Verified:    stored.Verified,
+		Version:     int32(stored.Version),
+		FetchedAt:   info.FetchedAt,
+		StoredAt:    stored.StoredAt,
+		UpdatedAt:   stored.UpdatedAt,
+	}
+
+	if metadata := info.Metadata; metadata != nil {
+		row.Name = metadata.Name
+		row.Symbol = metadata.Symbol
+		row.Collection = metadata.Collection.Name
+		row.CollectionFamily = metadata.Collection.Family
+		row.Image = metadata.Image
+		row.SellerFeeBasisPoints = int32(metadata.SellerFeeBasisPoints)
+		row.AttributeCount = int32(len(metadata.Attributes))
+
+		for _, attr := range metadata.Attributes {
+			d.Attributes = append(d.Attributes, AttributeRow{
+				Wallet:       wallet,
+				Mint:         row.Mint,
+				Collection:   row.Collection,
+				TraitType:    attr.TraitType,
+				Value:        attributeString(attr.Value),
+				NumericValue: attributeNumber(attr.Value),
+			})
+		}
+	}
+
+	if quote := info.Market; quote != nil {
+		row.Marketplace = quote.Marketplace
+		row.FloorLamports = int64(quote.FloorLamports)
+		row.LastSaleLamports = int64(quote.LastSaleLamports)
+		if quote.LastSaleAt != nil {
+			row.LastSaleAt = *quote.LastSaleAt
+		}
+	}
+
+	for _, media := range info.MediaFiles {
+		row.MediaBytes += media.Size
+		d.Media = append(d.Media, MediaRow{
+			Wallet:       wallet,
+			Mint:         row.Mint,
+			URL:          media.URL,
+			Filename:     media.Filename,
+			MediaType:    string(media.MediaType),
+			ContentType:  media.ContentType,
+			Size:         media.Size,
+			Checksum:     media.Checksum,
+			DownloadedAt: media.DownloadedAt,
+		})
+	}
+
+	d.NFTs = append(d.NFTs, row)
+}
+
+// WriteParquet writes nfts.parquet, attributes.parquet and media.parquet into dir
+func (d *Dataset) WriteParquet(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	files := []string{
+		filepath.Join(dir, "nfts.parquet"),
+		filepath.Join(dir, "attributes.parquet"),
+		filepath.Join(dir, "media.parquet"),
+	}
+
+	if err := parquet.WriteFile(files[0], d.NFTs); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", files[0], err)
+	}
+	if err := parquet.WriteFile(files[1], d.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", files[1], err)
+	}
+	if err := parquet.WriteFile(files[2], d.Media); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", files[2], err)
+	}
+
+	return files, nil
+}
+
+// attributeString renders a trait value of any JSON type as text
+func attributeString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// attributeNumber returns the numeric form of a trait value, if it has one
+func attributeNumber(value interface{}) *float64 {
+	switch v := value.(type) {
+	case float64:
+		return &v
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return &f
+		}
+	}
+	return nil
+}
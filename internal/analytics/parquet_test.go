@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestDataset_WriteParquet(t *testing.T) {
+	walletAddr := solanago.MustPublicKeyFromBase58("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")
+
+	stored := &storage.StoredNFT{
+		NFTInfo: &fetcher.NFTInfo{
+			MintAddress: solanago.MustPublicKeyFromBase58("ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3"),
+			Owner:       walletAddr,
+			FetchedAt:   time.Now(),
+			Metadata: &fetcher.NFTMetadata{
+				Name:       "Cool Cat #1",
+				Collection: fetcher.Collection{Name: "Cool Cats"},
+				Attributes: []fetcher.Attribute{
+					{TraitType: "Hat", Value: "Crown"},
+					{TraitType: "Level", Value: float64(7)},
+				},
+			},
+			MediaFiles: []*fetcher.MediaFile{{Filename: "1.png", Size: 1024}},
+		},
+		StoredAt: time.Now(),
+		Version:  1,
+	}
+
+	var dataset Dataset
+	dataset.Add(walletAddr.String(), stored)
+
+	files, err := dataset.WriteParquet(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write parquet: %v", err)
+	}
+
+	nfts, err := parquet.ReadFile[NFTRow](files[0])
+	if err != nil {
+		t.Fatalf("Failed to read nfts.parquet: %v", err)
+	}
+	if len(nfts) != 1 || nfts[0].Collection != "Cool Cats" || nfts[0].MediaBytes != 1024 {
+		t.Errorf("Unexpected NFT rows: %+v", nfts)
+	}
+
+	attrs, err := parquet.ReadFile[AttributeRow](filepath.Clean(files[1]))
+	if err != nil {
+		t.Fatalf("Failed to read attributes.parquet: %v", err)
+	}
+	if len(attrs) != 2 {
+		t.Fatalf("Expected 2 attribute rows, got %d", len(attrs))
+	}
+	if attrs[1].Value != "7" || attrs[1].NumericValue == nil || *attrs[1].NumericValue != 7 {
+		t.Errorf("Expected numeric trait value 7, got %+v", attrs[1])
+	}
+	if attrs[0].NumericValue != nil {
+		t.Errorf("Expected no numeric value for text trait, got %v", *attrs[0].NumericValue)
+	}
+}
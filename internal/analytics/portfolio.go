@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// Uncollected groups NFTs that name no collection
+const Uncollected = "(no collection)"
+
+// Floor price sources
+const (
+	FloorLive   = "live"   // Looked up from the marketplace now
+	FloorBackup = "backup" // Recorded with the newest backup in the collection
+)
+
+// Portfolio summarizes backed-up NFTs by collection with their estimated
+// value at the collection floor
+type Portfolio struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	NFTs        int                  `json:"nfts"`
+	TotalSOL    float64              `json:"total_sol"` // Sum of every priced collection's value
+	Unpriced    int                  `json:"unpriced"`  // NFTs in collections without a floor
+	Collections []*CollectionSummary `json:"collections"`
+}
+
+// CollectionSummary is one collection in the portfolio
+type CollectionSummary struct {
+	Collection  string     `json:"collection"`
+	Count       int        `json:"count"`
+	FloorSOL    float64    `json:"floor_sol"` // 0 when unknown
+	ValueSOL    float64    `json:"value_sol"` // Count × floor
+	Marketplace string     `json:"marketplace,omitempty"`
+	FloorSource string     `json:"floor_source,omitempty"` // FloorLive or FloorBackup
+	PricedAt    *time.Time `json:"priced_at,omitempty"`
+	Mints       []string   `json:"mints"`
+}
+
+// BuildPortfolio groups stored NFTs by collection, valued at the floor
+// recorded with each collection's newest backup. NFTs are grouped by their
+// metadata collection, then by the marketplace's collection symbol; NFTs
+// with neither have no shared floor and are left unpriced.
+func BuildPortfolio(nfts []*storage.StoredNFT) *Portfolio {
+	portfolio := &Portfolio{GeneratedAt: time.Now().UTC(), Collections: []*CollectionSummary{}}
+	byName := make(map[string]*CollectionSummary)
+	for _, stored := range nfts {
+		info := stored.NFTInfo
+		if info == nil {
+			continue
+		}
+		name := ""
+		if info.Metadata != nil {
+			name = info.Metadata.Collection.Name
+		}
+		if name == "" && info.Market != nil {
+			name = info.Market.Collection
+		}
+		if name == "" {
+			name = Uncollected
+		}
+
+		summary := byName[name]
+		if summary == nil {
+			summary = &CollectionSummary{Collection: name, Mints: []string{}}
+			byName[name] = summary
+			portfolio.Collections = append(portfolio.Collections, summary)
+		}
+		summary.Count++
+		summary.Mints = append(summary.Mints, info.MintAddress.String())
+		portfolio.NFTs++
+
+		quote := info.Market
+		if name != Uncollected && quote != nil && quote.FloorLamports > 0 && (summary.PricedAt == nil || !quote.FetchedAt.Before(*summary.PricedAt)) {
+			summary.setFloor(quote, FloorBackup)
+		}
+	}
+	portfolio.total()
+	return portfolio
+}
+
+// PriceLive replaces the recorded floors with current ones from provider,
+// one lookup per collection. Collections the marketplaces cannot price keep
+// their recorded floor; the number of failed lookups is returned with the
+// last error.
+//
+// Explanation: A floor is a property of the collection, so any one of its
+// mints is enough to ask for it, and a vault of thousands of NFTs costs one
+// request per collection rather than per NFT.
+func (p *Portfolio) PriceLive(ctx context.Context, provider market.Provider, progress func(done, total int)) (int, error) {
+	failed, lastErr := 0, error(nil)
+	for i, summary := range p.Collections {
+		if progress != nil {
+			progress(i, len(p.Collections))
+		}
+		if summary.Collection == Uncollected {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return failed, err
+		}
+		quote, err := provider.Quote(ctx, summary.Mints[0])
+		if err != nil {
+			if !errors.Is(err, market.ErrNotFound) {
+				failed, lastErr = failed+1, err
+			}
+			continue
+		}
+		if quote.FloorLamports > 0 {
+			summary.setFloor(quote, FloorLive)
+		}
+	}
+	if progress != nil {
+		progress(len(p.Collections), len(p.Collections))
+	}
+	p.total()
+	return failed, lastErr
+}
+
+// Top keeps the n most valuable collections, or all of them when n is 0
+func (p *Portfolio) Top(n int) []*CollectionSummary {
+	if n <= 0 || n >= len(p.Collections) {
+		return p.Collections
+	}
+	return p.Collections[:n]
+}
+
+func (s *CollectionSummary) setFloor(quote *market.Quote, source string) {
+	s.FloorSOL = quote.FloorSOL()
+	s.Marketplace = quote.Marketplace
+	s.FloorSource = source
+	pricedAt := quote.FetchedAt
+	s.PricedAt = &pricedAt
+}
+
+// total values every collection and sorts them by value, then by count
+func (p *Portfolio) total() {
+	p.TotalSOL, p.Unpriced = 0, 0
+	for _, summary := range p.Collections {
+		summary.ValueSOL = summary.FloorSOL * float64(summary.Count)
+		p.TotalSOL += summary.ValueSOL
+		if summary.FloorSOL == 0 {
+			p.Unpriced += summary.Count
+		}
+	}
+	sort.SliceStable(p.Collections, func(i, j int) bool {
+		a, b := p.Collections[i], p.Collections[j]
+		if a.ValueSOL != b.ValueSOL {
+			return a.ValueSOL > b.ValueSOL
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Collection < b.Collection
+	})
+}
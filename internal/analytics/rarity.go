@@ -0,0 +1,265 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// NoTrait stands for a trait an NFT does not have, counted like a value
+const NoTrait = "(none)"
+
+// CollectionRarity is the trait distribution of the backed-up NFTs of one
+// collection, with each NFT's rarity score within it
+type CollectionRarity struct {
+	Collection string               `json:"collection"`
+	NFTs       int                  `json:"nfts"`
+	Traits     []*TraitDistribution `json:"traits"`
+	Ranked     []RankedNFT          `json:"ranked"` // Rarest first
+
+	byMint map[string]*RankedNFT
+	counts map[string]map[string]int // Trait to value to NFTs
+}
+
+// TraitDistribution counts the values of one trait type, rarest first
+type TraitDistribution struct {
+	Trait  string       `json:"trait"`
+	Values []TraitCount `json:"values"`
+}
+
+// TraitCount is how many NFTs have one value of a trait
+type TraitCount struct {
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// RankedNFT is an NFT's rarity within its collection's backups
+type RankedNFT struct {
+	Mint   string  `json:"mint"`
+	Name   string  `json:"name,omitempty"`
+	Wallet string  `json:"wallet"`
+	Score  float64 `json:"score"`
+	Rank   int     `json:"rank"` // 1 is the rarest
+}
+
+// Rarity groups entries by collection and measures how rare each NFT's
+// traits are among them, collections with the most NFTs first
+//
+// Explanation: The score is the usual rarity score, the sum over every trait
+// type of (NFTs in the collection / NFTs sharing this NFT's value). An NFT
+// lacking a trait the others have counts as the value NoTrait, since missing
+// a common trait is itself rare. The distribution is only of the backups,
+// not of the whole collection on chain, so it ranks what the owner holds
+// rather than the collection's official rarity.
+func Rarity(entries []*storage.IndexEntry) []*CollectionRarity {
+	groups := make(map[string][]*storage.IndexEntry)
+	var order []string
+	for _, entry := range entries {
+		name := CollectionOf(entry)
+		if _, ok := groups[name]; !ok {
+			order = append(order, name)
+		}
+		groups[name] = append(groups[name], entry)
+	}
+
+	rarities := make([]*CollectionRarity, 0, len(order))
+	for _, name := range order {
+		rarities = append(rarities, collectionRarity(name, groups[name]))
+	}
+	sort.SliceStable(rarities, func(i, j int) bool {
+		if rarities[i].NFTs != rarities[j].NFTs {
+			return rarities[i].NFTs > rarities[j].NFTs
+		}
+		return rarities[i].Collection < rarities[j].Collection
+	})
+	return rarities
+}
+
+// Lookup returns the rarity of mint within the collection, or nil
+func (c *CollectionRarity) Lookup(mint string) *RankedNFT {
+	return c.byMint[mint]
+}
+
+// Record is the rarity section saved with entry's backup, or nil when entry
+// is not part of the collection
+func (c *CollectionRarity) Record(entry *storage.IndexEntry, now time.Time) *storage.Rarity {
+	ranked := c.Lookup(entry.Mint)
+	if ranked == nil {
+		return nil
+	}
+	record := &storage.Rarity{
+		Collection: c.Collection,
+		Rank:       ranked.Rank,
+		Of:         c.NFTs,
+		Score:      ranked.Score,
+		Traits:     []storage.TraitRarity{},
+		ComputedAt: now,
+	}
+	for _, trait := range c.Traits {
+		value := traitValue(entry, trait.Trait)
+		count := c.counts[trait.Trait][value]
+		record.Traits = append(record.Traits, storage.TraitRarity{
+			Trait:   trait.Trait,
+			Value:   value,
+			Count:   count,
+			Percent: 100 * float64(count) / float64(c.NFTs),
+			Score:   float64(c.NFTs) / float64(count),
+		})
+	}
+	return record
+}
+
+// CollectionOf names the collection Rarity groups entry under: its
+// collection, else its symbol, else Uncollected
+func CollectionOf(entry *storage.IndexEntry) string {
+	switch {
+	case entry.Collection != "":
+		return entry.Collection
+	case entry.Symbol != "":
+		return entry.Symbol
+	default:
+		return Uncollected
+	}
+}
+
+func collectionRarity(name string, entries []*storage.IndexEntry) *CollectionRarity {
+	rarity := &CollectionRarity{Collection: name, NFTs: len(entries), Traits: []*TraitDistribution{}, Ranked: []RankedNFT{}}
+
+	// Every trait type any NFT of the collection has
+	counts := make(map[string]map[string]int)
+	for _, entry := range entries {
+		for trait := range entry.Attributes {
+			if counts[trait] == nil {
+				counts[trait] = make(map[string]int)
+			}
+		}
+	}
+	for trait, values := range counts {
+		for _, entry := range entries {
+			values[traitValue(entry, trait)]++
+		}
+	}
+
+	traits := make([]string, 0, len(counts))
+	for trait := range counts {
+		traits = append(traits, trait)
+	}
+	sort.Strings(traits)
+	for _, trait := range traits {
+		distribution := &TraitDistribution{Trait: trait}
+		for value, count := range counts[trait] {
+			distribution.Values = append(distribution.Values, TraitCount{
+				Value:   value,
+				Count:   count,
+				Percent: 100 * float64(count) / float64(len(entries)),
+			})
+		}
+		sort.Slice(distribution.Values, func(i, j int) bool {
+			a, b := distribution.Values[i], distribution.Values[j]
+			if a.Count != b.Count {
+				return a.Count < b.Count
+			}
+			return a.Value < b.Value
+		})
+		rarity.Traits = append(rarity.Traits, distribution)
+	}
+
+	for _, entry := range entries {
+		ranked := RankedNFT{Mint: entry.Mint, Name: entry.Name, Wallet: entry.Wallet}
+		for _, trait := range traits {
+			ranked.Score += float64(len(entries)) / float64(counts[trait][traitValue(entry, trait)])
+		}
+		rarity.Ranked = append(rarity.Ranked, ranked)
+	}
+	sort.SliceStable(rarity.Ranked, func(i, j int) bool {
+		a, b := rarity.Ranked[i], rarity.Ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Name < b.Name
+	})
+	rarity.byMint = make(map[string]*RankedNFT, len(rarity.Ranked))
+	for i := range rarity.Ranked {
+		rarity.Ranked[i].Rank = i + 1
+		if i > 0 && rarity.Ranked[i].Score == rarity.Ranked[i-1].Score {
+			rarity.Ranked[i].Rank = rarity.Ranked[i-1].Rank
+		}
+		rarity.byMint[rarity.Ranked[i].Mint] = &rarity.Ranked[i]
+	}
+	rarity.counts = counts
+	return rarity
+}
+
+// traitValue is entry's value for trait, NoTrait when it lacks it
+func traitValue(entry *storage.IndexEntry, trait string) string {
+	if value, ok := entry.Attributes[trait]; ok {
+		return value
+	}
+	return NoTrait
+}
+
+// DefaultRaritySample is the fewest backups of a collection that are ranked
+const DefaultRaritySample = 20
+
+// RecordRarity ranks every collection with at least minSample backups and
+// saves each NFT's rarity section to its backup record, returning how many
+// records changed
+//
+// Explanation: Trait frequencies over a handful of NFTs say little about
+// rarity, so collections are only ranked once a full collection or a large
+// sample of it is backed up. Records whose rank is unchanged are not
+// rewritten, so re-running after every sync costs reads, not writes.
+func RecordRarity(ctx context.Context, store *storage.FileStorage, minSample int) (int, error) {
+	if minSample <= 0 {
+		return 0, nil
+	}
+	if _, err := store.IndexTraits(ctx); err != nil {
+		return 0, err
+	}
+
+	entries := store.Index().SearchTraits(storage.TraitFilter{})
+	byCollection := make(map[string]*CollectionRarity)
+	for _, rarity := range Rarity(entries) {
+		if rarity.Collection != Uncollected && rarity.NFTs >= minSample && len(rarity.Traits) > 0 {
+			byCollection[rarity.Collection] = rarity
+		}
+	}
+
+	now := time.Now().UTC()
+	updated := 0
+	for _, entry := range entries {
+		rarity := byCollection[CollectionOf(entry)]
+		if rarity == nil || entry.InProgress {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+		wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			continue
+		}
+		mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			continue
+		}
+		stored, err := store.GetNFT(ctx, wallet, mint)
+		if err != nil {
+			continue
+		}
+		record := rarity.Record(entry, now)
+		if record.Same(stored.Rarity) {
+			continue
+		}
+		if err := store.SetRarity(ctx, wallet, mint, record); err != nil {
+			return updated, fmt.Errorf("failed to record rarity of %s: %w", entry.Mint, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
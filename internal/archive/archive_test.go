@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateExtract_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatTarGz, FormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			root := t.TempDir()
+			files := []string{"wallets/w/nfts/m/nft_data.json", "wallets/w/nfts/m/media/1.png"}
+			for _, rel := range files {
+				path := filepath.Join(root, filepath.FromSlash(rel))
+				os.MkdirAll(filepath.Dir(path), 0755)
+				if err := os.WriteFile(path, []byte("content of "+rel), 0644); err != nil {
+					t.Fatalf("Failed to write fixture: %v", err)
+				}
+			}
+
+			manifest, err := BuildManifest(root, files, "vault", "test")
+			if err != nil {
+				t.Fatalf("Failed to build manifest: %v", err)
+			}
+
+			archivePath := filepath.Join(t.TempDir(), "export."+string(format))
+			if err := Create(archivePath, format, root, files, map[string][]byte{"extra.txt": []byte("hi")}); err != nil {
+				t.Fatalf("Failed to create archive: %v", err)
+			}
+
+			dest := t.TempDir()
+			if err := Extract(archivePath, dest); err != nil {
+				t.Fatalf("Failed to extract archive: %v", err)
+			}
+
+			if problems := manifest.Verify(dest); len(problems) > 0 {
+				t.Errorf("Expected clean verification, got %v", problems)
+			}
+			if _, err := os.Stat(filepath.Join(dest, "extra.txt")); err != nil {
+				t.Errorf("Expected extra entry: %v", err)
+			}
+
+			// Tampering with a file must be detected
+			os.WriteFile(filepath.Join(dest, "wallets", "w", "nfts", "m", "media", "1.png"), []byte("evil"), 0644)
+			if problems := manifest.Verify(dest); len(problems) != 1 {
+				t.Errorf("Expected 1 problem after tampering, got %v", problems)
+			}
+		})
+	}
+}
+
+func TestExtract_RejectsPathTraversal(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "evil.zip")
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(out)
+	w, _ := zw.Create("../escaped.txt")
+	w.Write([]byte("gotcha"))
+	zw.Close()
+	out.Close()
+
+	if err := Extract(archivePath, t.TempDir()); err == nil {
+		t.Error("Expected path traversal entry to be rejected")
+	}
+}
+
+func TestLoadManifest_RejectsUnsafePaths(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"wallets/abc/nfts/dir/nft_data.json", true},
+		{"/etc/passwd", false},
+		{"../escaped.txt", false},
+		{"wallets/../../escaped.txt", false},
+		{"wallets\\..\\escaped.txt", false},
+		{"wallets/./abc/nft_data.json", false},
+		{"wallets//abc", false},
+		{"wallets/abc/", false},
+		{"..", false},
+		{".", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		data, err := json.Marshal(Manifest{Files: []ManifestEntry{{Path: test.path}}})
+		if err != nil {
+			t.Fatalf("Failed to marshal manifest: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ManifestName), data, 0644); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+		if _, err := LoadManifest(dir); (err == nil) != test.ok {
+			t.Errorf("LoadManifest with path %q: error = %v, want ok = %v", test.path, err, test.ok)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{"a.tar.gz": FormatTarGz, "a.TGZ": FormatTarGz, "a.zip": FormatZip}
+	for name, expected := range tests {
+		if format, err := DetectFormat(name); err != nil || format != expected {
+			t.Errorf("DetectFormat(%q) = %q, %v; want %q", name, format, err, expected)
+		}
+	}
+	if _, err := DetectFormat("a.rar"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
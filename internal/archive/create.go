@@ -0,0 +1,130 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Create packs files (paths relative to root, using forward slashes) into an
+// archive at destPath. Extra in-memory entries such as a manifest are written first.
+func Create(destPath string, format Format, root string, files []string, extra map[string][]byte) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	switch format {
+	case FormatZip:
+		err = writeZip(out, root, files, extra)
+	case FormatTarGz:
+		err = writeTarGz(out, root, files, extra)
+	default:
+		err = fmt.Errorf("unsupported archive format: %s", format)
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(destPath) // Never leave a half-written archive behind
+		return err
+	}
+
+	return nil
+}
+
+func writeTarGz(out io.Writer, root string, files []string, extra map[string][]byte) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	for name, data := range extra {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	for _, rel := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		stat, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", rel, err)
+		}
+
+		header, err := tar.FileInfoHeader(stat, "")
+		if err != nil {
+			return fmt.Errorf("failed to build header for %s: %w", rel, err)
+		}
+		header.Name = rel
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		if err := copyFileTo(tw, path); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize tar: %w", err)
+	}
+	return gz.Close()
+}
+
+func writeZip(out io.Writer, root string, files []string, extra map[string][]byte) error {
+	zw := zip.NewWriter(out)
+
+	for name, data := range extra {
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	for _, rel := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		stat, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", rel, err)
+		}
+
+		header, err := zip.FileInfoHeader(stat)
+		if err != nil {
+			return fmt.Errorf("failed to build header for %s: %w", rel, err)
+		}
+		header.Name = rel
+		header.Method = zip.Deflate
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		if err := copyFileTo(w, path); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func copyFileTo(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestName is the file name of the manifest stored at the archive root
+const ManifestName = "manifest.json"
+
+// Manifest lists every file in an export together with its checksum
+type Manifest struct {
+	FormatVersion int             `json:"format_version"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CreatedBy     string          `json:"created_by"`
+	Scope         string          `json:"scope"` // e.g. "wallet:<address>", "mint:<address>", "vault"
+	Files         []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry records one archived file
+type ManifestEntry struct {
+	Path   string `json:"path"` // Relative, forward slashes
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BuildManifest hashes files (relative to root) into a manifest
+func BuildManifest(root string, files []string, scope, createdBy string) (*Manifest, error) {
+	manifest := &Manifest{
+		FormatVersion: 1,
+		CreatedAt:     time.Now().UTC(),
+		CreatedBy:     createdBy,
+		Scope:         scope,
+	}
+
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	for _, rel := range sorted {
+		size, sum, err := hashFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", rel, err)
+		}
+		manifest.Files = append(manifest.Files, ManifestEntry{Path: rel, Size: size, SHA256: sum})
+	}
+
+	return manifest, nil
+}
+
+// LoadManifest reads manifest.json from an extracted archive directory
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("archive has no %s: %w", ManifestName, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestName, err)
+	}
+	for _, entry := range manifest.Files {
+		if err := validatePath(entry.Path); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+		}
+	}
+	return &manifest, nil
+}
+
+// validatePath rejects manifest paths that could name a file outside the
+// directory they are resolved against
+//
+// Explanation: Import joins these paths onto the vault directory, so a
+// crafted manifest must not be able to reach beyond it. Only the clean,
+// relative, forward-slash form BuildManifest writes is accepted.
+func validatePath(rel string) error {
+	switch {
+	case rel == "":
+		return fmt.Errorf("empty file path")
+	case strings.Contains(rel, "\\"):
+		return fmt.Errorf("file path %q contains a backslash", rel)
+	case path.IsAbs(rel) || filepath.IsAbs(rel) || filepath.VolumeName(rel) != "":
+		return fmt.Errorf("file path %q is absolute", rel)
+	case path.Clean(rel) != rel:
+		return fmt.Errorf("file path %q is not clean", rel)
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if part == "." || part == ".." {
+			return fmt.Errorf("file path %q does not name a file in the archive", rel)
+		}
+	}
+	return nil
+}
+
+// Verify checks every file listed in the manifest against the extracted
+// directory and returns a description of each problem found
+func (m *Manifest) Verify(dir string) []string {
+	var problems []string
+
+	for _, entry := range m.Files {
+		size, sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: missing (%v)", entry.Path, err))
+		case size != entry.Size:
+			problems = append(problems, fmt.Sprintf("%s: size mismatch (expected %d, got %d)", entry.Path, entry.Size, size))
+		case sum != entry.SHA256:
+			problems = append(problems, fmt.Sprintf("%s: checksum mismatch", entry.Path))
+		}
+	}
+
+	return problems
+}
+
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
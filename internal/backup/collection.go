@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// CollectionsDir is where whole-collection archives are kept, under the
+// vault root, one vault per collection mint
+const CollectionsDir = "collections"
+
+// CollectionSource lists every NFT in a verified collection, whoever holds
+// it, so Sync can archive a whole collection. The collection mint takes the
+// place of the wallet: ListMints and FetchNFT are given it as owner.
+//
+// Explanation: Sync stores backups by wallet, so a collection is archived
+// as if it were one wallet holding every NFT in it. NFTs that leave the
+// listing are marked burned or transferred like NFTs that leave a wallet,
+// and everything sync does (change detection, resumed backups, media,
+// thumbnails) works unchanged.
+type CollectionSource struct {
+	*DASSource
+	holders []Holder // From the last listing
+}
+
+// NewCollectionSource wraps chain with a resumable collection enumerator,
+// one made by das.NewCollectionEnumerator
+func NewCollectionSource(chain *ChainSource, enumerator *das.Enumerator, opts das.Options) *CollectionSource {
+	return &CollectionSource{DASSource: NewDASSource(chain, enumerator, opts)}
+}
+
+// ListMints continues the collection's enumeration, noting who holds each
+// NFT for Holders
+func (s *CollectionSource) ListMints(ctx context.Context, collection solanago.PublicKey) ([]solanago.PublicKey, error) {
+	result, err := s.enumerator.Enumerate(ctx, collection.String(), s.opts)
+	if err != nil {
+		return nil, err
+	}
+	s.holders = HoldersOf(result.Assets)
+	return listedMints(result)
+}
+
+// Holders returns the holder of each NFT seen by the last ListMints
+func (s *CollectionSource) Holders() []Holder {
+	return s.holders
+}
+
+// FetchNFT fetches the NFT's metadata without requiring that any particular
+// wallet holds it
+func (s *CollectionSource) FetchNFT(ctx context.Context, collection, mint solanago.PublicKey) (*fetcher.NFTInfo, error) {
+	return s.fetcher.FetchMintInfo(ctx, mint)
+}
+
+// HolderSnapshot records who held each NFT of a collection at one moment
+type HolderSnapshot struct {
+	Collection string    `json:"collection"`
+	TakenAt    time.Time `json:"taken_at"`
+	Complete   bool      `json:"complete"` // False when the listing stopped partway
+	Owners     int       `json:"owners"`   // Distinct wallets
+	Holders    []Holder  `json:"holders"`  // By mint
+}
+
+// Holder is one NFT and the wallet holding it
+type Holder struct {
+	Mint  string `json:"mint"`
+	Name  string `json:"name,omitempty"`
+	Owner string `json:"owner"`
+}
+
+// OwnerCount is how many of a collection's NFTs one wallet holds
+type OwnerCount struct {
+	Owner string `json:"owner"`
+	Count int    `json:"count"`
+}
+
+// HoldersOf returns the holder of each unburnt asset, by mint
+func HoldersOf(assets []das.Asset) []Holder {
+	holders := []Holder{}
+	for _, asset := range assets {
+		if asset.Burnt || asset.Ownership.Owner == "" {
+			continue
+		}
+		holders = append(holders, Holder{Mint: asset.ID, Name: asset.Content.Metadata.Name, Owner: asset.Ownership.Owner})
+	}
+	sort.Slice(holders, func(i, j int) bool { return holders[i].Mint < holders[j].Mint })
+	return holders
+}
+
+// NewHolderSnapshot records holders, as returned by HoldersOf, as held now
+func NewHolderSnapshot(collection solanago.PublicKey, holders []Holder, complete bool) *HolderSnapshot {
+	snapshot := &HolderSnapshot{
+		Collection: collection.String(),
+		TakenAt:    time.Now().UTC(),
+		Complete:   complete,
+		Holders:    holders,
+	}
+	snapshot.Owners = len(snapshot.ByOwner())
+	return snapshot
+}
+
+// ByOwner counts the NFTs each wallet holds, largest holders first, the
+// usual shape of an airdrop list
+func (s *HolderSnapshot) ByOwner() []OwnerCount {
+	counts := make(map[string]int)
+	for _, holder := range s.Holders {
+		counts[holder.Owner]++
+	}
+	owners := make([]OwnerCount, 0, len(counts))
+	for owner, count := range counts {
+		owners = append(owners, OwnerCount{Owner: owner, Count: count})
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Count != owners[j].Count {
+			return owners[i].Count > owners[j].Count
+		}
+		return owners[i].Owner < owners[j].Owner
+	})
+	return owners
+}
+
+// WriteCSV writes one row per NFT, or with byOwner one row per wallet with
+// the number of NFTs it holds
+func (s *HolderSnapshot) WriteCSV(w io.Writer, byOwner bool) error {
+	records := [][]string{{"mint", "name", "owner"}}
+	if byOwner {
+		records = [][]string{{"owner", "count"}}
+		for _, owner := range s.ByOwner() {
+			records = append(records, []string{owner.Owner, strconv.Itoa(owner.Count)})
+		}
+	} else {
+		for _, holder := range s.Holders {
+			records = append(records, []string{holder.Mint, holder.Name, holder.Owner})
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// SaveHolderSnapshot writes snapshot to
+// snapshots/<collection>/holders-<time>.json at the root of store,
+// returning its path
+func SaveHolderSnapshot(store *storage.FileStorage, snapshot *HolderSnapshot) (string, error) {
+	dir := filepath.Join(store.BaseDir(), SnapshotDir, snapshot.Collection)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	path := filepath.Join(dir, "holders-"+snapshot.TakenAt.Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return path, nil
+}
+
+// SameHolders reports whether two holder lists, as returned by HoldersOf,
+// record the same owners
+func SameHolders(a, b []Holder) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Mint != b[i].Mint || a[i].Owner != b[i].Owner {
+			return false
+		}
+	}
+	return true
+}
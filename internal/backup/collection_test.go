@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/das"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestCollectionSource_Holders(t *testing.T) {
+	collection, owner := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	minted, burnt, compressed := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset := func(mint solanago.PublicKey) das.Asset {
+			var a das.Asset
+			a.ID = mint.String()
+			a.Ownership.Owner = owner.String()
+			return a
+		}
+		items := []das.Asset{asset(minted), asset(burnt), asset(compressed)}
+		items[1].Burnt = true
+		items[2].Compression.Compressed = true
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": "solvault", "result": das.Page{Total: 3, Items: items}})
+	}))
+	defer ts.Close()
+
+	source := NewCollectionSource(NewChainSource(nil), das.NewCollectionEnumerator(das.NewClient(ts.URL, 0), t.TempDir()), das.Options{})
+	mints, err := source.ListMints(context.Background(), collection)
+	if err != nil && !errors.Is(err, ErrPartialListing) {
+		t.Fatalf("Failed to list collection: %v", err)
+	}
+	if len(mints) != 1 || !mints[0].Equals(minted) {
+		t.Errorf("Expected only the minted NFT to back up, got %v", mints)
+	}
+	holders := source.Holders()
+	if len(holders) != 2 || holders[0].Owner != owner.String() || holders[1].Owner != owner.String() {
+		t.Errorf("Expected holders of the unburnt NFTs, got %v", holders)
+	}
+
+	dir := t.TempDir()
+	store, err := storage.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	snapshot := NewHolderSnapshot(collection, holders, true)
+	if snapshot.Owners != 1 || len(snapshot.Holders) != 2 {
+		t.Errorf("Expected 2 NFTs held by 1 owner, got %+v", snapshot)
+	}
+	path, err := SaveHolderSnapshot(store, snapshot)
+	if err != nil {
+		t.Fatalf("Failed to save holder snapshot: %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, SnapshotDir, collection.String()) {
+		t.Errorf("Snapshot written to unexpected path %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Failed to find the snapshot file: %v", err)
+	}
+
+	moved := append([]Holder(nil), holders...)
+	if !SameHolders(holders, moved) {
+		t.Errorf("Expected identical holder lists to match")
+	}
+	moved[0].Owner = collection.String()
+	if SameHolders(holders, moved) {
+		t.Errorf("Expected a changed owner to differ")
+	}
+}
+
+func TestHolderSnapshot_WriteCSV(t *testing.T) {
+	snapshot := NewHolderSnapshot(solanago.NewWallet().PublicKey(), []Holder{
+		{Mint: "mint-a", Name: "Cat #1", Owner: "alice"},
+		{Mint: "mint-b", Name: "Cat, #2", Owner: "bob"},
+		{Mint: "mint-c", Name: "Cat #3", Owner: "bob"},
+	}, true)
+	if snapshot.Owners != 2 {
+		t.Errorf("Expected 2 owners, got %d", snapshot.Owners)
+	}
+
+	tests := []struct {
+		byOwner bool
+		want    string
+	}{
+		{false, "mint,name,owner\nmint-a,Cat #1,alice\nmint-b,\"Cat, #2\",bob\nmint-c,Cat #3,bob\n"},
+		{true, "owner,count\nbob,2\nalice,1\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := snapshot.WriteCSV(&buf, tt.byOwner); err != nil {
+			t.Fatalf("Failed to write CSV: %v", err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("byOwner=%v: got %q, want %q", tt.byOwner, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NazWright/solvault/internal/das"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// DASSource lists wallet contents with the DAS getAssetsByOwner API, which
+// scales to wallets with tens of thousands of assets; metadata, media and
+// supply checks still go through the regular RPC client
+type DASSource struct {
+	*ChainSource
+	enumerator *das.Enumerator
+	opts       das.Options
+}
+
+// NewDASSource wraps chain with a resumable DAS enumerator
+func NewDASSource(chain *ChainSource, enumerator *das.Enumerator, opts das.Options) *DASSource {
+	return &DASSource{
+		ChainSource: chain,
+		enumerator:  enumerator,
+		opts:        opts,
+	}
+}
+
+// ListMints continues the owner's enumeration. Until it completes, the mints
+// seen so far are returned together with ErrPartialListing.
+func (s *DASSource) ListMints(ctx context.Context, owner solanago.PublicKey) ([]solanago.PublicKey, error) {
+	result, err := s.enumerator.Enumerate(ctx, owner.String(), s.opts)
+	if err != nil {
+		return nil, err
+	}
+	return listedMints(result)
+}
+
+// listedMints returns the mints of an enumeration that can be backed up,
+// wrapped with ErrPartialListing until the enumeration completes
+func listedMints(result *das.Result) ([]solanago.PublicKey, error) {
+	var mints []solanago.PublicKey
+	for _, asset := range result.Assets {
+		// Compressed NFTs have no mint account or token account to back up yet
+		if asset.Burnt || asset.Compression.Compressed {
+			continue
+		}
+		mint, err := solanago.PublicKeyFromBase58(asset.ID)
+		if err != nil {
+			continue
+		}
+		mints = append(mints, mint)
+	}
+
+	if !result.Checkpoint.Complete {
+		return mints, fmt.Errorf("%w: %d of %d assets after %d page(s)", ErrPartialListing,
+			result.Checkpoint.Assets, result.Checkpoint.Total, result.Checkpoint.Pages)
+	}
+	return mints, nil
+}
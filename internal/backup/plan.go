@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// planBackup lists what backing up info would do, for dry runs. It mirrors
+// the steps of syncHeld without taking any of them.
+func planBackup(store *storage.FileStorage, wallet solanago.PublicKey, info *fetcher.NFTInfo, opts Options) []string {
+	plan := []string{"write " + vaultRelative(store, store.NFTDir(wallet, info.MintAddress))}
+	for _, mediaURL := range fetcher.MediaURLs(info.Metadata) {
+		plan = append(plan, "fetch "+mediaURL)
+	}
+	if opts.Market != nil {
+		plan = append(plan, "look up the market price")
+	}
+	if needsArchive(info, opts) {
+		plan = append(plan, "archive "+info.Metadata.ExternalURL)
+	}
+	if opts.Render != nil {
+		plan = append(plan, "render any HTML media in a browser")
+	}
+	if len(opts.Thumbnails) > 0 {
+		plan = append(plan, "make thumbnails of image and video media")
+	}
+	plan = append(plan, "record perceptual hashes of image media")
+	if opts.Mirror != nil {
+		plan = append(plan, "upload the backup to "+strings.Join(opts.Mirror.Names(), ", "))
+	}
+	return plan
+}
+
+// planBackfill lists the extras a dry run would add to an unchanged backup
+func planBackfill(store *storage.FileStorage, wallet solanago.PublicKey, info *fetcher.NFTInfo, opts Options) []string {
+	var plan []string
+	if needsArchive(info, opts) {
+		plan = append(plan, "archive "+info.Metadata.ExternalURL)
+	}
+	for _, page := range pendingRenders(info, opts) {
+		plan = append(plan, "render "+page.URL)
+	}
+	if len(opts.Thumbnails) > 0 {
+		missing := 0
+		for _, media := range info.MediaFiles {
+			if _, ok := needsThumbnail(media); ok {
+				missing++
+			}
+		}
+		if missing > 0 {
+			plan = append(plan, fmt.Sprintf("make thumbnails for %d media file(s)", missing))
+		}
+	}
+	unhashed := 0
+	for _, media := range info.MediaFiles {
+		if needsPerceptualHash(media) {
+			unhashed++
+		}
+	}
+	if unhashed > 0 {
+		plan = append(plan, fmt.Sprintf("record perceptual hashes for %d image(s)", unhashed))
+	}
+	if len(plan) > 0 {
+		plan = append(plan, "rewrite "+vaultRelative(store, filepath.Join(store.NFTDir(wallet, info.MintAddress), "nft_data.json")))
+		if opts.Mirror != nil {
+			plan = append(plan, "upload the backup to "+strings.Join(opts.Mirror.Names(), ", "))
+		}
+	}
+	return plan
+}
+
+// vaultRelative shortens a path inside the vault for display
+func vaultRelative(store *storage.FileStorage, path string) string {
+	if rel, err := filepath.Rel(store.BaseDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
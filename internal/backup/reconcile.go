@@ -0,0 +1,218 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Bucket names one list in a reconciliation report
+type Bucket string
+
+const (
+	BucketMissing    Bucket = "missing"    // Held on-chain but not backed up
+	BucketGone       Bucket = "gone"       // Backed up as held but no longer on-chain
+	BucketMismatched Bucket = "mismatched" // Held by a different configured wallet than the vault records
+)
+
+// Discrepancy is one NFT whose vault record disagrees with the chain
+type Discrepancy struct {
+	Mint        string `json:"mint"`
+	Name        string `json:"name,omitempty"`
+	Wallet      string `json:"wallet"`                 // Wallet the vault files it under, or that holds it for missing NFTs
+	ChainWallet string `json:"chain_wallet,omitempty"` // Configured wallet holding it on-chain (mismatched only)
+}
+
+// Report lists every disagreement between the vault and current holdings
+type Report struct {
+	Wallets    []string      `json:"wallets"`
+	CheckedAt  time.Time     `json:"checked_at"`
+	Held       int           `json:"held_on_chain"`
+	Partial    []string      `json:"partial,omitempty"` // Wallets whose listing was incomplete
+	Missing    []Discrepancy `json:"missing"`
+	Gone       []Discrepancy `json:"gone"`
+	Mismatched []Discrepancy `json:"mismatched"`
+}
+
+// Clean reports whether the vault matches the chain
+func (r *Report) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Gone) == 0 && len(r.Mismatched) == 0
+}
+
+// Reconcile compares what each wallet holds on-chain with the vault's held
+// records without changing anything
+//
+// Explanation: An NFT that moved between two configured wallets would
+// otherwise show up as both missing and gone, so it is reported once as an
+// owner mismatch instead. Wallets with a partial listing are not checked for
+// gone NFTs, since the NFT may just not have been reached yet.
+func Reconcile(ctx context.Context, store *storage.FileStorage, source Source, wallets []solanago.PublicKey) (*Report, error) {
+	report := &Report{
+		CheckedAt:  time.Now(),
+		Missing:    []Discrepancy{},
+		Gone:       []Discrepancy{},
+		Mismatched: []Discrepancy{},
+	}
+
+	onChain := make(map[string]string) // mint -> wallet holding it
+	partial := make(map[string]bool)
+	for _, wallet := range wallets {
+		report.Wallets = append(report.Wallets, wallet.String())
+
+		mints, err := source.ListMints(ctx, wallet)
+		if errors.Is(err, ErrPartialListing) {
+			partial[wallet.String()] = true
+			report.Partial = append(report.Partial, wallet.String())
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to list NFTs for %s: %w", wallet.String(), err)
+		}
+		for _, mint := range mints {
+			onChain[mint.String()] = wallet.String()
+		}
+	}
+	report.Held = len(onChain)
+
+	// The vault's view: which wallet holds each mint
+	recorded := make(map[string]*storage.IndexEntry)
+	configured := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		configured[wallet.String()] = true
+	}
+	for _, entry := range store.Index().List() {
+		if !configured[entry.Wallet] || (entry.Status != storage.StatusHeld && entry.Status != "") {
+			continue
+		}
+		recorded[indexKeyFor(entry.Wallet, entry.Mint)] = entry
+	}
+
+	for mint, holder := range onChain {
+		if _, ok := recorded[indexKeyFor(holder, mint)]; ok {
+			continue
+		}
+
+		// Recorded as held by another configured wallet?
+		var other *storage.IndexEntry
+		for _, wallet := range report.Wallets {
+			if entry, ok := recorded[indexKeyFor(wallet, mint)]; ok && wallet != holder {
+				other = entry
+				break
+			}
+		}
+		if other != nil {
+			report.Mismatched = append(report.Mismatched, Discrepancy{Mint: mint, Name: other.Name, Wallet: other.Wallet, ChainWallet: holder})
+			continue
+		}
+		report.Missing = append(report.Missing, Discrepancy{Mint: mint, Wallet: holder, Name: nameFor(store, mint)})
+	}
+
+	for _, entry := range recorded {
+		holder, held := onChain[entry.Mint]
+		switch {
+		case held && holder == entry.Wallet:
+			continue
+		case held && recorded[indexKeyFor(holder, entry.Mint)] == nil:
+			continue // Reported as mismatched above
+		case !held && partial[entry.Wallet]:
+			continue
+		}
+		report.Gone = append(report.Gone, Discrepancy{Mint: entry.Mint, Name: entry.Name, Wallet: entry.Wallet})
+	}
+
+	for _, list := range [][]Discrepancy{report.Missing, report.Gone, report.Mismatched} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Wallet != list[j].Wallet {
+				return list[i].Wallet < list[j].Wallet
+			}
+			return list[i].Mint < list[j].Mint
+		})
+	}
+	return report, nil
+}
+
+// Fix resolves every discrepancy in one bucket:
+// missing NFTs are backed up, gone NFTs are marked transferred or burned and
+// mismatched NFTs are marked transferred from the recorded wallet and backed
+// up under the wallet that holds them
+func Fix(ctx context.Context, store *storage.FileStorage, source Source, report *Report, bucket Bucket, opts Options) ([]Change, error) {
+	var changes []Change
+
+	switch bucket {
+	case BucketMissing:
+		for _, d := range report.Missing {
+			wallet, mint, err := parsePair(d.Wallet, d.Mint)
+			if err != nil {
+				return changes, err
+			}
+			changes = append(changes, syncHeld(ctx, store, source, wallet, mint, store.Index().Get(d.Wallet, d.Mint), opts))
+		}
+
+	case BucketGone:
+		for _, d := range report.Gone {
+			entry := store.Index().Get(d.Wallet, d.Mint)
+			if entry == nil {
+				continue
+			}
+			wallet, _, err := parsePair(d.Wallet, d.Mint)
+			if err != nil {
+				return changes, err
+			}
+			changes = append(changes, syncGone(ctx, store, source, wallet, entry, opts))
+		}
+
+	case BucketMismatched:
+		for _, d := range report.Mismatched {
+			from, mint, err := parsePair(d.Wallet, d.Mint)
+			if err != nil {
+				return changes, err
+			}
+			to, _, err := parsePair(d.ChainWallet, d.Mint)
+			if err != nil {
+				return changes, err
+			}
+
+			if !opts.DryRun {
+				if err := store.SetStatus(ctx, from, mint, storage.StatusTransferred); err != nil {
+					changes = append(changes, Change{Mint: d.Mint, Name: d.Name, Kind: ChangeFailed, Error: err.Error()})
+					continue
+				}
+			}
+			changes = append(changes, syncHeld(ctx, store, source, to, mint, store.Index().Get(d.ChainWallet, d.Mint), opts))
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown bucket %q", bucket)
+	}
+
+	return changes, nil
+}
+
+// nameFor finds a name for a mint from any wallet's index entry
+func nameFor(store *storage.FileStorage, mint string) string {
+	for _, entry := range store.Index().ByMint(mint) {
+		if entry.Name != "" {
+			return entry.Name
+		}
+	}
+	return ""
+}
+
+func indexKeyFor(wallet, mint string) string {
+	return wallet + "/" + mint
+}
+
+func parsePair(wallet, mint string) (solanago.PublicKey, solanago.PublicKey, error) {
+	w, err := solanago.PublicKeyFromBase58(wallet)
+	if err != nil {
+		return solanago.PublicKey{}, solanago.PublicKey{}, fmt.Errorf("invalid wallet %q: %w", wallet, err)
+	}
+	m, err := solanago.PublicKeyFromBase58(mint)
+	if err != nil {
+		return solanago.PublicKey{}, solanago.PublicKey{}, fmt.Errorf("invalid mint %q: %w", mint, err)
+	}
+	return w, m, nil
+}
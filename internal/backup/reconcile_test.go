@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestReconcile(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	walletA := solanago.NewWallet().PublicKey()
+	walletB := solanago.NewWallet().PublicKey()
+	kept := solanago.NewWallet().PublicKey()  // Held by A, backed up under A
+	moved := solanago.NewWallet().PublicKey() // Backed up under A, now in B
+	sold := solanago.NewWallet().PublicKey()  // Backed up under A, gone
+	fresh := solanago.NewWallet().PublicKey() // Held by B, never backed up
+
+	source := &fakeSource{
+		byOwner: map[solanago.PublicKey][]solanago.PublicKey{
+			walletA: {kept, moved, sold},
+		},
+		names:  map[solanago.PublicKey]string{},
+		supply: map[solanago.PublicKey]uint64{sold: 1},
+	}
+	if _, err := Sync(ctx, store, source, walletA, Options{}); err != nil {
+		t.Fatalf("Failed to seed vault: %v", err)
+	}
+
+	// Holdings change behind the vault's back
+	source.byOwner = map[solanago.PublicKey][]solanago.PublicKey{
+		walletA: {kept},
+		walletB: {moved, fresh},
+	}
+
+	report, err := Reconcile(ctx, store, source, []solanago.PublicKey{walletA, walletB})
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	if len(report.Missing) != 1 || report.Missing[0].Mint != fresh.String() || report.Missing[0].Wallet != walletB.String() {
+		t.Errorf("Unexpected missing: %+v", report.Missing)
+	}
+	if len(report.Gone) != 1 || report.Gone[0].Mint != sold.String() {
+		t.Errorf("Unexpected gone: %+v", report.Gone)
+	}
+	if len(report.Mismatched) != 1 || report.Mismatched[0].Mint != moved.String() ||
+		report.Mismatched[0].Wallet != walletA.String() || report.Mismatched[0].ChainWallet != walletB.String() {
+		t.Errorf("Unexpected mismatched: %+v", report.Mismatched)
+	}
+
+	for _, bucket := range []Bucket{BucketMissing, BucketGone, BucketMismatched} {
+		changes, err := Fix(ctx, store, source, report, bucket, Options{})
+		if err != nil {
+			t.Fatalf("Failed to fix %s: %v", bucket, err)
+		}
+		for _, change := range changes {
+			if change.Kind == ChangeFailed {
+				t.Errorf("Fix %s failed for %s: %s", bucket, change.Mint, change.Error)
+			}
+		}
+	}
+
+	report, err = Reconcile(ctx, store, source, []solanago.PublicKey{walletA, walletB})
+	if err != nil {
+		t.Fatalf("Failed to reconcile after fixes: %v", err)
+	}
+	if !report.Clean() {
+		t.Errorf("Expected clean report after fixes, got %+v", report)
+	}
+
+	if entry := store.Index().Get(walletA.String(), sold.String()); entry == nil || entry.Status != storage.StatusTransferred {
+		t.Errorf("Expected sold NFT to be marked transferred, got %+v", entry)
+	}
+	if entry := store.Index().Get(walletB.String(), moved.String()); entry == nil || entry.PrimaryWallet != walletA.String() {
+		t.Errorf("Expected moved NFT to link to wallet A's backup, got %+v", entry)
+	}
+
+	if _, err := Fix(ctx, store, source, report, Bucket("bogus"), Options{}); err == nil {
+		t.Error("Expected unknown bucket to fail")
+	}
+}
+
+func TestReconcile_PartialSkipsGone(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	mint := solanago.NewWallet().PublicKey()
+
+	source := &fakeSource{held: []solanago.PublicKey{mint}, names: map[solanago.PublicKey]string{}}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("Failed to seed vault: %v", err)
+	}
+
+	source.held, source.partial = nil, true
+	report, err := Reconcile(ctx, store, source, []solanago.PublicKey{wallet})
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(report.Gone) != 0 || len(report.Partial) != 1 {
+		t.Errorf("Expected partial listing to suppress gone NFTs, got %+v", report)
+	}
+}
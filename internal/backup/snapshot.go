@@ -0,0 +1,79 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// SnapshotDir is where holdings snapshots are kept, under the vault root
+const SnapshotDir = "snapshots"
+
+// HoldingsSnapshot records what a wallet still held at one moment, taken
+// when its holdings are at risk
+type HoldingsSnapshot struct {
+	Wallet  string         `json:"wallet"`
+	TakenAt time.Time      `json:"taken_at"`
+	Reason  string         `json:"reason"`
+	Held    []SnapshotItem `json:"held"`
+	Summary *Summary       `json:"sync"` // The refresh that backed them up
+}
+
+// SnapshotItem is one NFT the wallet held
+type SnapshotItem struct {
+	Mint   string     `json:"mint"`
+	Name   string     `json:"name,omitempty"`
+	Backup ChangeKind `json:"backup"` // What the refresh did with it
+	Error  string     `json:"error,omitempty"`
+}
+
+// Snapshot refreshes the backup of everything wallet still holds and
+// writes the list of holdings to snapshots/<wallet>/<time>.json at the vault
+// root, returning the snapshot and its path
+//
+// Explanation: Unlike the watcher's polls, the refresh re-fetches every held
+// NFT rather than only new ones, so the vault has current metadata and
+// media for whatever a drainer may take next. The holdings list is a plain
+// record of what was still in the wallet, for a police report or an
+// insurance claim.
+func Snapshot(ctx context.Context, store *storage.FileStorage, source Source, wallet solanago.PublicKey, reason string, opts Options) (*HoldingsSnapshot, string, error) {
+	opts.NewOnly, opts.DryRun = false, false
+	summary, err := Sync(ctx, store, source, wallet, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	snapshot := &HoldingsSnapshot{
+		Wallet:  wallet.String(),
+		TakenAt: time.Now().UTC(),
+		Reason:  reason,
+		Held:    []SnapshotItem{},
+		Summary: summary,
+	}
+	for _, change := range summary.Changes {
+		if change.Kind == ChangeTransferred || change.Kind == ChangeBurned {
+			continue
+		}
+		snapshot.Held = append(snapshot.Held, SnapshotItem{Mint: change.Mint, Name: change.Name, Backup: change.Kind, Error: change.Error})
+	}
+
+	dir := filepath.Join(store.BaseDir(), SnapshotDir, wallet.String())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return snapshot, "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return snapshot, "", fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	path := filepath.Join(dir, snapshot.TakenAt.Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return snapshot, "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snapshot, path, nil
+}
@@ -0,0 +1,220 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ErrPartialListing is returned by ListMints, together with the mints found
+// so far, when a wallet could not be enumerated completely in this run
+var ErrPartialListing = errors.New("wallet listing incomplete")
+
+// Source is where sync learns what a wallet currently holds
+// Explanation: Sync only depends on this interface so it can be tested
+// without an RPC endpoint, and so other enumerators (e.g. DAS) can plug in.
+type Source interface {
+	// ListMints returns the mints of every NFT the owner currently holds,
+	// or a partial list wrapped with ErrPartialListing
+	ListMints(ctx context.Context, owner solanago.PublicKey) ([]solanago.PublicKey, error)
+
+	// FetchNFT fetches on-chain and off-chain metadata for a held NFT
+	FetchNFT(ctx context.Context, owner, mint solanago.PublicKey) (*fetcher.NFTInfo, error)
+
+	// DownloadMedia downloads the NFT's media into mediaDir, recording it on info
+	DownloadMedia(ctx context.Context, info *fetcher.NFTInfo, mediaDir string) error
+
+	// MintSupply returns the mint's current supply (0 once burned)
+	MintSupply(ctx context.Context, mint solanago.PublicKey) (uint64, error)
+}
+
+// CustodySource is a Source that can tell when an NFT missing from a wallet
+// is held for it by a staking or escrow program. Sync checks before marking
+// such an NFT transferred.
+type CustodySource interface {
+	// FindCustody returns the program holding the NFT, or nil when a
+	// wallet holds it or nobody does
+	FindCustody(ctx context.Context, mint solanago.PublicKey) (*fetcher.Escrow, error)
+}
+
+// ChainSource reads wallet contents directly from a Solana RPC endpoint
+type ChainSource struct {
+	client  *solana.Client
+	fetcher *fetcher.Fetcher
+}
+
+// NewChainSource creates a Source backed by the given RPC client
+func NewChainSource(client *solana.Client) *ChainSource {
+	return &ChainSource{
+		client:  client,
+		fetcher: fetcher.NewFetcher(client),
+	}
+}
+
+// SetHTTPOptions sets the proxy, timeouts and TLS options of metadata and
+// media fetches
+func (s *ChainSource) SetHTTPOptions(opts fetcher.HTTPOptions) {
+	s.fetcher.SetHTTPOptions(opts)
+}
+
+// SetMetadataCache makes metadata fetches conditional requests against cache
+func (s *ChainSource) SetMetadataCache(cache *fetcher.MetadataCache) {
+	s.fetcher.SetMetadataCache(cache)
+}
+
+// SetCrossCheck has metadata fetches compared with an independent
+// gateway's copy; nil turns the check off
+func (s *ChainSource) SetCrossCheck(check *fetcher.CrossCheck) {
+	s.fetcher.SetCrossCheck(check)
+}
+
+// SetURIRewrites sends metadata and media requests for dead hosts to the
+// configured mirrors; nil turns rewriting off
+func (s *ChainSource) SetURIRewrites(rewrites *fetcher.URIRewrites) {
+	s.fetcher.SetURIRewrites(rewrites)
+}
+
+// MetadataStats returns how the metadata fetches so far were answered
+func (s *ChainSource) MetadataStats() fetcher.CacheStats {
+	return s.fetcher.MetadataStats()
+}
+
+// ListMints returns mints held with amount 1 and 0 decimals
+func (s *ChainSource) ListMints(ctx context.Context, owner solanago.PublicKey) ([]solanago.PublicKey, error) {
+	accounts, err := s.client.GetTokenAccountsForOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var mints []solanago.PublicKey
+	for _, account := range accounts {
+		if mint, ok := nftMint(account); ok {
+			mints = append(mints, mint)
+		}
+	}
+	return mints, nil
+}
+
+// ListTokens returns the owner's fungible tokens and SFTs: every balance
+// that is not a single indivisible NFT, summed per mint
+func (s *ChainSource) ListTokens(ctx context.Context, owner solanago.PublicKey) ([]fetcher.TokenBalance, error) {
+	accounts, err := s.client.GetTokenAccountsForOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []fetcher.TokenBalance
+	byMint := make(map[solanago.PublicKey]int)
+	for _, account := range accounts {
+		balance, ok := tokenBalance(account)
+		if !ok {
+			continue
+		}
+		if i, seen := byMint[balance.Mint]; seen {
+			balances[i].Amount += balance.Amount
+			continue
+		}
+		byMint[balance.Mint] = len(balances)
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// FetchToken fetches the metadata of a fungible token or SFT held by owner
+func (s *ChainSource) FetchToken(ctx context.Context, owner solanago.PublicKey, balance fetcher.TokenBalance) (*fetcher.NFTInfo, error) {
+	return s.fetcher.FetchTokenInfo(ctx, owner, balance)
+}
+
+// FetchNFT fetches NFT information for a mint held by owner
+func (s *ChainSource) FetchNFT(ctx context.Context, owner, mint solanago.PublicKey) (*fetcher.NFTInfo, error) {
+	return s.fetcher.FetchNFTInfoForOwner(ctx, owner, mint)
+}
+
+// DownloadMedia downloads every media file referenced by the metadata
+func (s *ChainSource) DownloadMedia(ctx context.Context, info *fetcher.NFTInfo, mediaDir string) error {
+	return s.fetcher.DownloadMediaFiles(ctx, info, mediaDir)
+}
+
+// MintSupply returns the mint's current supply
+func (s *ChainSource) MintSupply(ctx context.Context, mint solanago.PublicKey) (uint64, error) {
+	return s.client.GetMintSupply(ctx, mint)
+}
+
+// FindCustody checks whether a staking or escrow program holds the NFT
+func (s *ChainSource) FindCustody(ctx context.Context, mint solanago.PublicKey) (*fetcher.Escrow, error) {
+	return s.fetcher.FindCustody(ctx, mint)
+}
+
+// Close releases HTTP resources held by the fetcher
+func (s *ChainSource) Close() error {
+	return s.fetcher.Close()
+}
+
+// parsedTokenAccount is the jsonParsed shape of an SPL token account
+type parsedTokenAccount struct {
+	Parsed struct {
+		Info struct {
+			Mint        string `json:"mint"`
+			TokenAmount struct {
+				Amount   string `json:"amount"`
+				Decimals int    `json:"decimals"`
+			} `json:"tokenAmount"`
+		} `json:"info"`
+	} `json:"parsed"`
+}
+
+// tokenBalance reads a token account holding anything but exactly one
+// indivisible token; empty accounts and NFTs are skipped
+func tokenBalance(account *rpc.TokenAccount) (fetcher.TokenBalance, bool) {
+	rawJSON := account.Account.Data.GetRawJSON()
+	if len(rawJSON) == 0 {
+		return fetcher.TokenBalance{}, false
+	}
+
+	var parsed parsedTokenAccount
+	if err := json.Unmarshal(rawJSON, &parsed); err != nil {
+		return fetcher.TokenBalance{}, false
+	}
+
+	info := parsed.Parsed.Info
+	amount, err := strconv.ParseUint(info.TokenAmount.Amount, 10, 64)
+	if err != nil || amount == 0 || (amount == 1 && info.TokenAmount.Decimals == 0) {
+		return fetcher.TokenBalance{}, false
+	}
+
+	mint, err := solanago.PublicKeyFromBase58(info.Mint)
+	if err != nil {
+		return fetcher.TokenBalance{}, false
+	}
+	return fetcher.TokenBalance{Mint: mint, TokenAccount: account.Pubkey, Amount: amount, Decimals: uint8(info.TokenAmount.Decimals)}, true
+}
+
+// nftMint returns the mint of a token account holding exactly one indivisible token
+func nftMint(account *rpc.TokenAccount) (solanago.PublicKey, bool) {
+	rawJSON := account.Account.Data.GetRawJSON()
+	if len(rawJSON) == 0 {
+		return solanago.PublicKey{}, false
+	}
+
+	var parsed parsedTokenAccount
+	if err := json.Unmarshal(rawJSON, &parsed); err != nil {
+		return solanago.PublicKey{}, false
+	}
+
+	info := parsed.Parsed.Info
+	if info.TokenAmount.Amount != "1" || info.TokenAmount.Decimals != 0 {
+		return solanago.PublicKey{}, false
+	}
+
+	mint, err := solanago.PublicKeyFromBase58(info.Mint)
+	if err != nil {
+		return solanago.PublicKey{}, false
+	}
+	return mint, true
+}
@@ -0,0 +1,789 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/mirror"
+	"github.com/NazWright/solvault/internal/phash"
+	"github.com/NazWright/solvault/internal/provenance"
+	"github.com/NazWright/solvault/internal/render"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/telemetry"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	"github.com/NazWright/solvault/internal/webarchive"
+	solanago "github.com/gagliardetto/solana-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ChangeKind describes what sync did with one NFT
+type ChangeKind string
+
+const (
+	ChangeAdded       ChangeKind = "added"
+	ChangeUpdated     ChangeKind = "updated"
+	ChangeUnchanged   ChangeKind = "unchanged"
+	ChangeReturned    ChangeKind = "returned" // Marked gone earlier, held again now
+	ChangeResumed     ChangeKind = "resumed"  // An interrupted backup, done again
+	ChangeTransferred ChangeKind = "transferred"
+	ChangeBurned      ChangeKind = "burned"
+	ChangeEscrowed    ChangeKind = "escrowed" // Left the wallet for a staking or escrow program
+	ChangeFailed      ChangeKind = "failed"
+)
+
+// Change is one line of the sync summary
+type Change struct {
+	Mint  string     `json:"mint"`
+	Name  string     `json:"name,omitempty"`
+	Kind  ChangeKind `json:"kind"`
+	Error string     `json:"error,omitempty"`
+	Plan  []string   `json:"plan,omitempty"` // Dry runs: what the run would fetch, write and upload
+
+	// Arrival is how a new or returned NFT came into the wallet, and
+	// Departure how a transferred or burned one left it, when
+	// Options.History found the transaction
+	Arrival   *provenance.Event `json:"arrival,omitempty"`
+	Departure *provenance.Event `json:"departure,omitempty"`
+
+	// Escrow is who holds an escrowed NFT for the wallet
+	Escrow *fetcher.Escrow `json:"escrow,omitempty"`
+
+	// Token is set when the change is to a fungible token or SFT backed up
+	// under tokens/ rather than an NFT
+	Token fetcher.TokenStandard `json:"token,omitempty"`
+}
+
+// Summary reports the outcome of syncing one wallet
+type Summary struct {
+	Wallet     string         `json:"wallet"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	DryRun     bool           `json:"dry_run,omitempty"`
+	Partial    bool           `json:"partial,omitempty"` // Listing incomplete; departures not checked
+	Counts     map[string]int `json:"counts"`
+	Changes    []Change       `json:"changes"`
+	MerkleRoot string         `json:"merkle_root,omitempty"` // Root over the wallet's whole backup after the run
+	VaultFull  string         `json:"vault_full,omitempty"`  // Set when the vault ended the run at its size limit, so new NFTs are refused
+}
+
+// Options controls a sync run
+type Options struct {
+	DryRun     bool                    // Report what would change without writing anything
+	NewOnly    bool                    // Skip re-fetching NFTs that are already backed up
+	Fungibles  bool                    // Also back up fungible tokens and SFTs under tokens/, if the source can list them
+	Market     market.Provider         // Records sale and floor prices with each backup; nil skips lookups
+	Mirror     *mirror.Mirror          // Uploads each saved backup to cloud storage; nil keeps backups local
+	Archive    *webarchive.Archiver    // Snapshots each NFT's external_url site; nil skips archiving
+	Render     render.Renderer         // Captures HTML media as it renders in a browser; nil skips rendering
+	Thumbnails []int                   // Longest edges of the thumbnails made for images and videos; nil skips them
+	Journal    *journal.Journal        // Records detected mints and backups for 'solvault events'; nil keeps no journal
+	History    provenance.RecentSource // Finds the transactions NFTs arrived and left in; nil skips the lookups
+	Progress   func(msg string)
+	Advance    func(done, total int) // Called after each NFT is checked
+}
+
+// Sync diffs the NFTs a wallet currently holds against the vault index,
+// backs up new and changed NFTs and marks NFTs that left the wallet
+func Sync(ctx context.Context, store *storage.FileStorage, source Source, wallet solanago.PublicKey, opts Options) (*Summary, error) {
+	ctx, span := telemetry.Start(ctx, "backup sync",
+		attribute.String("solvault.wallet", wallet.String()),
+		attribute.Bool("solvault.dry_run", opts.DryRun))
+	summary, err := syncWallet(ctx, store, source, wallet, opts)
+	if summary != nil {
+		for kind, n := range summary.Counts {
+			span.SetAttributes(attribute.Int("solvault.changes."+kind, n))
+		}
+	}
+	telemetry.End(span, err)
+	return summary, err
+}
+
+func syncWallet(ctx context.Context, store *storage.FileStorage, source Source, wallet solanago.PublicKey, opts Options) (*Summary, error) {
+	summary := &Summary{
+		Wallet:    wallet.String(),
+		StartedAt: time.Now(),
+		DryRun:    opts.DryRun,
+		Counts:    make(map[string]int),
+		Changes:   []Change{},
+	}
+	if err := checkFreeSpace(store, summary, opts); err != nil {
+		return summary, err
+	}
+
+	opts.progress("🔗 Listing NFTs held by %s...", wallet.String())
+	held, err := source.ListMints(ctx, wallet)
+	if errors.Is(err, ErrPartialListing) {
+		// Back up what we have seen, but an NFT missing from a partial list
+		// may simply not have been reached yet
+		summary.Partial = true
+		opts.progress("⏸️  %v; will resume on the next run", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list wallet NFTs: %w", err)
+	}
+
+	// What the vault already knows about this wallet
+	known := make(map[string]*storage.IndexEntry)
+	for _, entry := range store.Index().List() {
+		if entry.Wallet == wallet.String() {
+			known[entry.Mint] = entry
+		}
+	}
+
+	// Explanation: On a wallet's first run every NFT is new, and looking up
+	// how each one arrived would cost a burst of RPC calls for history
+	// nobody is waiting on; 'solvault history' has it when wanted.
+	if len(known) == 0 {
+		opts.History = nil
+	}
+
+	heldSet := make(map[string]bool, len(held))
+	for _, mint := range held {
+		heldSet[mint.String()] = true
+	}
+
+	// Anything indexed but no longer held has left the wallet
+	var gone []*storage.IndexEntry
+	for mint, entry := range known {
+		if summary.Partial {
+			break
+		}
+		if heldSet[mint] || entry.Status == storage.StatusTransferred || entry.Status == storage.StatusBurned {
+			continue
+		}
+		gone = append(gone, entry)
+	}
+
+	total := len(held) + len(gone)
+	for _, mint := range held {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		if err := checkFreeSpace(store, summary, opts); err != nil {
+			return summary, err
+		}
+		summary.add(traceChange(ctx, mint.String(), func(ctx context.Context) Change {
+			return syncHeld(ctx, store, source, wallet, mint, known[mint.String()], opts)
+		}))
+		opts.advance(len(summary.Changes), total)
+	}
+	for _, entry := range gone {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		summary.add(traceChange(ctx, entry.Mint, func(ctx context.Context) Change {
+			return syncGone(ctx, store, source, wallet, entry, opts)
+		}))
+		opts.advance(len(summary.Changes), total)
+	}
+	if opts.Fungibles {
+		if err := syncTokens(ctx, store, source, wallet, summary, opts); err != nil {
+			if ctx.Err() != nil {
+				return summary, err
+			}
+			opts.progress("⚠️  %v", err)
+		}
+	}
+
+	if !opts.DryRun {
+		if tree, err := store.UpdateMerkle(wallet.String()); err != nil {
+			opts.progress("⚠️  Failed to update the Merkle root: %v", err)
+		} else {
+			summary.MerkleRoot = tree.Root
+		}
+		if err := store.CheckQuota(); errors.Is(err, storage.ErrQuotaExceeded) {
+			summary.VaultFull = err.Error()
+		}
+	}
+
+	summary.FinishedAt = time.Now()
+	return summary, nil
+}
+
+// checkFreeSpace stops a run before its next backup once the vault's disk
+// is down to its MinFreeBytes
+//
+// Explanation: Each backup is refused on its own by the storage layer, but
+// every later NFT would fetch its metadata only to be refused too, so the
+// run stops instead and the backups done so far are kept.
+func checkFreeSpace(store *storage.FileStorage, summary *Summary, opts Options) error {
+	if opts.DryRun {
+		return nil
+	}
+	if err := store.CheckFreeSpace(); err != nil {
+		summary.FinishedAt = time.Now()
+		return fmt.Errorf("backup stopped: %w", err)
+	}
+	return nil
+}
+
+// syncHeld backs up a held NFT if it is new or its metadata changed
+func syncHeld(ctx context.Context, store *storage.FileStorage, source Source, wallet, mint solanago.PublicKey, entry *storage.IndexEntry, opts Options) Change {
+	change := Change{Mint: mint.String()}
+	if entry != nil {
+		change.Name = entry.Name
+	}
+
+	returned := entry != nil && (entry.Status == storage.StatusTransferred || entry.Status == storage.StatusBurned)
+	resumed := entry != nil && entry.InProgress
+	if entry != nil && opts.NewOnly && !returned && !resumed {
+		change.Kind = ChangeUnchanged
+		return change
+	}
+	if (entry == nil || returned) && !opts.DryRun {
+		change.Arrival = opts.arrival(ctx, wallet, mint)
+	}
+	if entry == nil && !opts.DryRun {
+		event := journal.Event{Kind: journal.MintDetected, Wallet: wallet.String(), Mint: change.Mint}
+		if change.Arrival != nil {
+			event.Detail, event.Signature = change.Arrival.DescribeArrival(), change.Arrival.Signature
+		}
+		opts.record(event)
+	}
+
+	info, err := source.FetchNFT(ctx, wallet, mint)
+	if err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		if opts.DryRun {
+			return change
+		}
+		return opts.finished(wallet, change, time.Time{})
+	}
+	info.Owner = wallet
+	if info.Metadata != nil {
+		change.Name = info.Metadata.Name
+	}
+
+	switch {
+	case entry == nil:
+		change.Kind = ChangeAdded
+	case returned:
+		change.Kind = ChangeReturned
+	case resumed:
+		change.Kind = ChangeResumed
+	default:
+		stored, err := store.GetNFT(ctx, wallet, mint)
+		if err == nil && storage.MetadataFingerprint(stored.NFTInfo) == storage.MetadataFingerprint(info) {
+			change.Kind = ChangeUnchanged
+			if opts.DryRun {
+				change.Plan = planBackfill(store, wallet, stored.NFTInfo, opts)
+				if !stored.NFTInfo.Escrow.Same(info.Escrow) {
+					change.Plan = append(change.Plan, "record the custody state")
+				}
+			} else {
+				backfill(ctx, store, wallet, stored.NFTInfo, &change, opts)
+				if !stored.NFTInfo.Escrow.Same(info.Escrow) {
+					if err := store.SetEscrow(ctx, wallet, mint, info.Escrow); err != nil {
+						opts.progress("⚠️  Failed to record the custody state of %s: %v", displayName(change), err)
+					}
+				}
+			}
+			return change
+		}
+		change.Kind = ChangeUpdated
+	}
+
+	// Explanation: Metadata two gateways disagree on may be poisoned. An
+	// existing backup is the last copy known to be good, so it is kept
+	// rather than overwritten; a new NFT has nothing better to keep, so it is
+	// saved and flagged for the owner to check.
+	if mismatch := metadataMismatch(info); mismatch != "" {
+		if change.Kind == ChangeUpdated {
+			change.Kind, change.Error = ChangeFailed, mismatch+"; kept the previous backup"
+			if opts.DryRun {
+				return change
+			}
+			return opts.finished(wallet, change, time.Time{})
+		}
+		change.Error = mismatch + "; check it before trusting this backup"
+	}
+
+	if opts.DryRun {
+		change.Plan = planBackup(store, wallet, info, opts)
+		return change
+	}
+
+	opts.progress("💾 Backing up %s (%s)", displayName(change), change.Kind)
+	started := time.Now()
+	opts.record(journal.Event{Kind: journal.BackupStarted, Wallet: wallet.String(), Mint: change.Mint, Name: change.Name, Detail: string(change.Kind)})
+	info.Market = marketQuote(ctx, mint, opts)
+
+	// Save first so the directory exists and is indexed, then fill in media.
+	// Until the backup is complete the index marks it in progress, so a run
+	// interrupted from here on is redone by the next sync or watch tick.
+	if err := store.SaveNFTInProgress(ctx, info); err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return opts.finished(wallet, change, started)
+	}
+	mediaDir := filepath.Join(store.NFTDir(wallet, mint), "media")
+	if err := source.DownloadMedia(ctx, info, mediaDir); err != nil {
+		change.Error = fmt.Sprintf("media download failed: %v", err)
+	}
+	// Explanation: An interrupted backup stays marked in progress rather
+	// than being completed with part of its media, so the next run redoes it
+	if err := ctx.Err(); err != nil {
+		change.Kind, change.Error = ChangeFailed, fmt.Sprintf("interrupted: %v", err)
+		return opts.finished(wallet, change, started)
+	}
+	if caps := store.Capabilities(); caps.MaxObjectSize > 0 {
+		for _, media := range info.MediaFiles {
+			if !caps.Fits(media.Size) {
+				change.Error = fmt.Sprintf("media %s is %d bytes, over the backend limit of %d", media.Filename, media.Size, caps.MaxObjectSize)
+			}
+		}
+	}
+	if needsArchive(info, opts) {
+		archiveExternal(ctx, store, wallet, info, &change, opts)
+	}
+	if len(pendingRenders(info, opts)) > 0 {
+		renderPages(ctx, info, mediaDir, &change, opts)
+	}
+	makeThumbnails(ctx, store, info, mediaDir, opts)
+	hashImages(store, info, mediaDir, opts)
+	if len(info.MediaFiles) > 0 || info.ExternalArchive != nil {
+		err = store.SaveNFT(ctx, info)
+	} else {
+		err = store.CompleteBackup(wallet, mint)
+	}
+	if err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return opts.finished(wallet, change, started)
+	}
+
+	mirrorBackup(ctx, wallet, mint, opts)
+	return opts.finished(wallet, change, started)
+}
+
+// metadataMismatch describes a cross-check that found the metadata served
+// differently by an independent gateway, or is "" when it did not
+func metadataMismatch(info *fetcher.NFTInfo) string {
+	if info.MetadataCheck == nil || info.MetadataCheck.Status != fetcher.CheckMismatch {
+		return ""
+	}
+	return fmt.Sprintf("metadata differs from the copy at %s", info.MetadataCheck.URL)
+}
+
+// backfill adds the extras this run asks for (site archives, page
+// renders, thumbnails, perceptual hashes) to a backup made before they
+// were turned on
+func backfill(ctx context.Context, store *storage.FileStorage, wallet solanago.PublicKey, info *fetcher.NFTInfo, change *Change, opts Options) {
+	archived := info.ExternalArchive != nil
+	mediaCount := len(info.MediaFiles)
+	mediaDir := filepath.Join(store.NFTDir(wallet, info.MintAddress), "media")
+	if needsArchive(info, opts) {
+		archiveExternal(ctx, store, wallet, info, change, opts)
+	}
+	if len(pendingRenders(info, opts)) > 0 {
+		renderPages(ctx, info, mediaDir, change, opts)
+	}
+	thumbnailed := makeThumbnails(ctx, store, info, mediaDir, opts)
+	hashed := hashImages(store, info, mediaDir, opts)
+	if (info.ExternalArchive != nil) == archived && len(info.MediaFiles) == mediaCount && thumbnailed == 0 && hashed == 0 {
+		return
+	}
+	if err := store.SaveNFT(ctx, info); err != nil {
+		change.Error = err.Error()
+		return
+	}
+	mirrorBackup(ctx, wallet, info.MintAddress, opts)
+}
+
+// needsArchive reports whether info has an external site that this run
+// should snapshot
+func needsArchive(info *fetcher.NFTInfo, opts Options) bool {
+	return opts.Archive != nil && info.ExternalArchive == nil && info.Metadata != nil && info.Metadata.ExternalURL != ""
+}
+
+// archiveExternal snapshots the NFT's external_url into its backup's
+// external/ directory and records the snapshot on info. A site that cannot
+// be archived is noted on the change but does not fail the backup.
+//
+// Explanation: The snapshot is built beside the old one and swapped in only
+// once it succeeds, so a site that has since gone offline does not cost
+// the copy taken while it was up.
+func archiveExternal(ctx context.Context, store *storage.FileStorage, wallet solanago.PublicKey, info *fetcher.NFTInfo, change *Change, opts Options) {
+	dir := filepath.Join(store.NFTDir(wallet, info.MintAddress), storage.ExternalDir)
+	staging := dir + ".partial"
+	os.RemoveAll(staging)
+
+	opts.progress("🌐 Archiving %s", info.Metadata.ExternalURL)
+	snapshot, err := opts.Archive.Snapshot(ctx, info.Metadata.ExternalURL, staging)
+	if err == nil {
+		os.RemoveAll(dir)
+		err = os.Rename(staging, dir)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		change.Error = fmt.Sprintf("external site archive failed: %v", err)
+		return
+	}
+	if snapshot.Truncated {
+		opts.progress("⚠️  Archive of %s was cut short by the archive limits", info.Metadata.ExternalURL)
+	}
+	info.ExternalArchive = snapshot
+}
+
+// pendingRenders returns the HTML media of info that has not been rendered
+// yet, when this run renders pages
+func pendingRenders(info *fetcher.NFTInfo, opts Options) []*fetcher.MediaFile {
+	if opts.Render == nil {
+		return nil
+	}
+	rendered := make(map[string]bool)
+	for _, media := range info.MediaFiles {
+		if media.RenderedFrom != "" {
+			rendered[media.RenderedFrom] = true
+		}
+	}
+	var pending []*fetcher.MediaFile
+	for _, media := range info.MediaFiles {
+		if media.MediaType == fetcher.MediaTypeHTML && !rendered[media.URL] {
+			pending = append(pending, media)
+		}
+	}
+	return pending
+}
+
+// renderPages captures what each HTML media file draws in a browser and
+// adds the screenshots and recordings to the NFT's media, next to the
+// page itself. A page that fails to render is noted on the change.
+func renderPages(ctx context.Context, info *fetcher.NFTInfo, mediaDir string, change *Change, opts Options) {
+	for _, page := range pendingRenders(info, opts) {
+		opts.progress("🖼️  Rendering %s", page.URL)
+		sum := sha256.Sum256([]byte(page.URL))
+		captures, err := opts.Render.Capture(ctx, page.URL, mediaDir, fmt.Sprintf("render-%x", sum[:4]))
+		if err != nil {
+			change.Error = fmt.Sprintf("render failed: %v", err)
+		}
+		for _, capture := range captures {
+			media, err := fetcher.NewLocalMediaFile(capture.Path, page.URL, capture.ContentType)
+			if err != nil {
+				change.Error = fmt.Sprintf("render failed: %v", err)
+				continue
+			}
+			info.MediaFiles = append(info.MediaFiles, media)
+		}
+	}
+}
+
+// makeThumbnails previews each image and video of info that has no
+// thumbnails yet, returning how many media files it previewed. Thumbnails
+// are a convenience, so failures are reported without failing the backup.
+func makeThumbnails(ctx context.Context, store *storage.FileStorage, info *fetcher.NFTInfo, mediaDir string, opts Options) int {
+	if len(opts.Thumbnails) == 0 {
+		return 0
+	}
+	made := 0
+	for _, media := range info.MediaFiles {
+		contentType, ok := needsThumbnail(media)
+		if !ok {
+			continue
+		}
+		// Backups made earlier may already be encrypted or compressed
+		data, err := store.ReadFile(filepath.Join(mediaDir, media.Filename))
+		if err != nil {
+			opts.progress("⚠️  No thumbnail for %s: %v", media.Filename, err)
+			continue
+		}
+		thumbnails, err := thumbnail.Generate(ctx, data, contentType, mediaDir, media.Filename, opts.Thumbnails)
+		if err != nil {
+			opts.progress("⚠️  No thumbnail for %s: %v", media.Filename, err)
+			continue
+		}
+		media.Thumbnails = thumbnails
+		made++
+	}
+	return made
+}
+
+// needsThumbnail reports whether media is an image or video without
+// thumbnails, and the content type to generate them from
+func needsThumbnail(media *fetcher.MediaFile) (string, bool) {
+	contentType := media.ContentType
+	if typ := mime.TypeByExtension(filepath.Ext(media.Filename)); !thumbnail.Supported(contentType) && typ != "" {
+		// Gateways often serve media as application/octet-stream
+		contentType = typ
+	}
+	return contentType, len(media.Thumbnails) == 0 && thumbnail.Supported(contentType)
+}
+
+// hashImages records the perceptual and pixel hashes of each image of info
+// that has none yet, returning how many it hashed. Like thumbnails, a hash
+// that cannot be computed is reported without failing the backup.
+func hashImages(store *storage.FileStorage, info *fetcher.NFTInfo, mediaDir string, opts Options) int {
+	hashed := 0
+	for _, media := range info.MediaFiles {
+		if !needsPerceptualHash(media) {
+			continue
+		}
+		data, err := store.ReadFile(filepath.Join(mediaDir, media.Filename))
+		if err != nil {
+			opts.progress("⚠️  No perceptual hash for %s: %v", media.Filename, err)
+			continue
+		}
+		img, err := phash.Decode(data)
+		if errors.Is(err, phash.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			opts.progress("⚠️  No perceptual hash for %s: %v", media.Filename, err)
+			continue
+		}
+		pixels, err := phash.Pixels(data)
+		if err != nil {
+			opts.progress("⚠️  No perceptual hash for %s: %v", media.Filename, err)
+			continue
+		}
+		media.PerceptualHash = phash.FromImage(img)
+		media.PixelHash = pixels
+		hashed++
+	}
+	return hashed
+}
+
+// needsPerceptualHash reports whether media is a raster image without a
+// perceptual or pixel hash
+func needsPerceptualHash(media *fetcher.MediaFile) bool {
+	if media.PerceptualHash != nil && media.PixelHash != "" {
+		return false
+	}
+	contentType := strings.ToLower(media.ContentType)
+	if !strings.HasPrefix(contentType, "image/") {
+		// Gateways often serve media as application/octet-stream
+		contentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(media.Filename)))
+	}
+	return strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg")
+}
+
+// syncGone marks an NFT that is no longer in the wallet as transferred or burned
+func syncGone(ctx context.Context, store *storage.FileStorage, source Source, wallet solanago.PublicKey, entry *storage.IndexEntry, opts Options) Change {
+	change := Change{Mint: entry.Mint, Name: entry.Name}
+
+	mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+	if err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return change
+	}
+
+	status := storage.StatusTransferred
+	change.Kind = ChangeTransferred
+	supply, err := source.MintSupply(ctx, mint)
+	if err != nil {
+		change.Kind, change.Error = ChangeFailed, fmt.Sprintf("failed to check mint supply: %v", err)
+		return change
+	}
+	if supply == 0 {
+		status = storage.StatusBurned
+		change.Kind = ChangeBurned
+	} else if escrow := findCustody(ctx, source, mint, opts); escrow != nil {
+		return syncEscrowed(ctx, store, wallet, mint, change, escrow, opts)
+	}
+
+	if opts.DryRun {
+		change.Plan = []string{fmt.Sprintf("mark %s in the index", status)}
+		if opts.Mirror != nil {
+			change.Plan = append(change.Plan, "upload the updated record to "+strings.Join(opts.Mirror.Names(), ", "))
+		}
+		return change
+	}
+
+	opts.progress("📤 %s is no longer held (%s)", displayName(change), status)
+	var departure storage.Departure
+	event := journal.Event{Kind: journal.NFTDeparted, Wallet: wallet.String(), Mint: change.Mint, Name: change.Name, Detail: string(status)}
+	if change.Departure = opts.departure(ctx, wallet, mint); change.Departure != nil {
+		departure = storage.Departure{Signature: change.Departure.Signature, Recipient: change.Departure.To}
+		event.Detail, event.Signature = change.Departure.DescribeDeparture(), change.Departure.Signature
+	}
+	if err := store.RecordDeparture(ctx, wallet, mint, status, departure); err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return change
+	}
+	opts.record(event)
+	mirrorBackup(ctx, wallet, mint, opts)
+	return change
+}
+
+// findCustody asks the source whether a program holds an NFT that left the
+// wallet. A failed lookup is reported and the NFT treated as transferred, as
+// it was before custody was checked.
+func findCustody(ctx context.Context, source Source, mint solanago.PublicKey, opts Options) *fetcher.Escrow {
+	custody, ok := source.(CustodySource)
+	if !ok {
+		return nil
+	}
+	escrow, err := custody.FindCustody(ctx, mint)
+	if err != nil {
+		opts.progress("⚠️  Could not check who holds %s: %v", mint.String(), err)
+		return nil
+	}
+	return escrow
+}
+
+// syncEscrowed keeps an NFT that a staking or escrow program holds for the
+// wallet as held, recording the custody state on its backup
+func syncEscrowed(ctx context.Context, store *storage.FileStorage, wallet, mint solanago.PublicKey, change Change, escrow *fetcher.Escrow, opts Options) Change {
+	stored, err := store.GetNFT(ctx, wallet, mint)
+	if err == nil && stored.NFTInfo != nil && stored.NFTInfo.Escrow.Same(escrow) {
+		change.Kind = ChangeUnchanged
+		return change
+	}
+
+	change.Kind, change.Escrow = ChangeEscrowed, escrow
+	if opts.DryRun {
+		change.Plan = []string{"record custody: " + escrow.Describe()}
+		return change
+	}
+
+	opts.progress("🔒 %s is %s", displayName(change), escrow.Describe())
+	if err := store.SetEscrow(ctx, wallet, mint, escrow); err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return change
+	}
+	mirrorBackup(ctx, wallet, mint, opts)
+	return change
+}
+
+// marketQuote prices the NFT for the backup record. Prices are informative
+// only, so a marketplace outage never fails the backup.
+func marketQuote(ctx context.Context, mint solanago.PublicKey, opts Options) *market.Quote {
+	if opts.Market == nil {
+		return nil
+	}
+	quote, err := opts.Market.Quote(ctx, mint.String())
+	if err != nil {
+		if !errors.Is(err, market.ErrNotFound) {
+			opts.progress("⚠️  No market price for %s: %v", mint.String(), err)
+		}
+		return nil
+	}
+	return quote
+}
+
+// mirrorBackup uploads a saved backup to the cloud mirrors. Like prices,
+// mirroring never fails the local backup; 'solvault mirror push' uploads
+// anything a sync could not.
+func mirrorBackup(ctx context.Context, wallet, mint solanago.PublicKey, opts Options) {
+	if opts.Mirror == nil {
+		return
+	}
+	uploaded, err := opts.Mirror.Push(ctx, wallet, mint)
+	if err != nil {
+		opts.progress("⚠️  Mirror upload of %s failed: %v", mint.String(), err)
+		return
+	}
+	if uploaded > 0 {
+		opts.progress("☁️  Mirrored %d file(s) to %s", uploaded, strings.Join(opts.Mirror.Names(), ", "))
+	}
+}
+
+func displayName(change Change) string {
+	if change.Name != "" {
+		return change.Name
+	}
+	return change.Mint
+}
+
+// traceChange runs one NFT's step of a sync in its own span, so a slow
+// backup shows which RPC call, fetch or write it waited on
+func traceChange(ctx context.Context, mint string, step func(ctx context.Context) Change) Change {
+	ctx, span := telemetry.Start(ctx, "backup nft", attribute.String("solvault.mint", mint))
+	change := step(ctx)
+	span.SetAttributes(attribute.String("solvault.change", string(change.Kind)))
+	var err error
+	if change.Error != "" {
+		err = errors.New(change.Error)
+	}
+	telemetry.End(span, err)
+	return change
+}
+
+func (s *Summary) add(change Change) {
+	s.Changes = append(s.Changes, change)
+	s.Counts[string(change.Kind)]++
+}
+
+func (o Options) advance(done, total int) {
+	if o.Advance != nil {
+		o.Advance(done, total)
+	}
+}
+
+func (o Options) progress(format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(fmt.Sprintf(format, args...))
+	}
+}
+
+// record adds an event to the journal, if there is one. A journal that
+// cannot be written is reported but never fails the backup.
+func (o Options) record(event journal.Event) {
+	if o.Journal == nil {
+		return
+	}
+	if err := o.Journal.Record(event); err != nil {
+		o.progress("⚠️  %v", err)
+	}
+}
+
+// arrival looks up how mint came into wallet, if History is set. Failures
+// are reported but never hold up the backup.
+func (o Options) arrival(ctx context.Context, wallet, mint solanago.PublicKey) *provenance.Event {
+	if o.History == nil {
+		return nil
+	}
+	event, err := provenance.Arrival(ctx, o.History, mint.String(), wallet.String())
+	if err != nil {
+		o.progress("⚠️  Could not tell how %s arrived: %v", mint, err)
+		return nil
+	}
+	if event != nil {
+		o.progress("📨 %s was %s", mint, event.DescribeArrival())
+	}
+	return event
+}
+
+// departure looks up how mint left wallet, if History is set. Failures are
+// reported but never stop the NFT being marked.
+func (o Options) departure(ctx context.Context, wallet, mint solanago.PublicKey) *provenance.Event {
+	if o.History == nil {
+		return nil
+	}
+	event, err := provenance.Departure(ctx, o.History, mint.String(), wallet.String())
+	if err != nil {
+		o.progress("⚠️  Could not tell how %s left: %v", mint, err)
+		return nil
+	}
+	if event != nil {
+		o.progress("   %s in %s", event.DescribeDeparture(), event.Signature)
+	}
+	return event
+}
+
+// finished journals how a backup begun at started ended, and returns its
+// change. A zero started means it failed before anything was written.
+func (o Options) finished(wallet solanago.PublicKey, change Change, started time.Time) Change {
+	event := journal.Event{Kind: journal.BackupCompleted, Wallet: wallet.String(), Mint: change.Mint, Name: change.Name, Detail: string(change.Kind), Error: change.Error}
+	if change.Kind == ChangeFailed {
+		event.Kind, event.Detail = journal.BackupFailed, ""
+	}
+	if !started.IsZero() {
+		event.Duration = time.Since(started).Seconds()
+	}
+	o.record(event)
+	return change
+}
+
+// BackupMint backs up a single NFT held by wallet, the same way sync treats
+// a held NFT
+func BackupMint(ctx context.Context, store *storage.FileStorage, source Source, wallet, mint solanago.PublicKey, opts Options) Change {
+	return syncHeld(ctx, store, source, wallet, mint, store.Index().Get(wallet.String(), mint.String()), opts)
+}
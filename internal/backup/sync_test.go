@@ -0,0 +1,879 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/fsutil"
+	"github.com/NazWright/solvault/internal/journal"
+	"github.com/NazWright/solvault/internal/market"
+	"github.com/NazWright/solvault/internal/phash"
+	"github.com/NazWright/solvault/internal/provenance"
+	"github.com/NazWright/solvault/internal/render"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/webarchive"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// fakeSource serves a fixed wallet state instead of talking to RPC
+type fakeSource struct {
+	held     []solanago.PublicKey
+	byOwner  map[solanago.PublicKey][]solanago.PublicKey // Overrides held when set
+	names    map[solanago.PublicKey]string
+	supply   map[solanago.PublicKey]uint64
+	fetched  int
+	mediaDir []string
+	partial  bool
+	external string // external_url of every NFT
+	page     string // URL of an HTML animation downloaded with every NFT
+	image    []byte // PNG served as every NFT's image; a placeholder when nil
+	fetchErr map[solanago.PublicKey]error
+	checks   map[solanago.PublicKey]string // Metadata cross-check status per mint
+}
+
+func (f *fakeSource) ListMints(ctx context.Context, owner solanago.PublicKey) ([]solanago.PublicKey, error) {
+	held := f.held
+	if f.byOwner != nil {
+		held = f.byOwner[owner]
+	}
+	if f.partial {
+		return held, ErrPartialListing
+	}
+	return held, nil
+}
+
+func (f *fakeSource) FetchNFT(ctx context.Context, owner, mint solanago.PublicKey) (*fetcher.NFTInfo, error) {
+	f.fetched++
+	if err := f.fetchErr[mint]; err != nil {
+		return nil, err
+	}
+	info := &fetcher.NFTInfo{
+		MintAddress: mint,
+		Owner:       owner,
+		FetchedAt:   time.Now(),
+		MetadataURI: "https://example.com/" + mint.String() + ".json",
+		Metadata:    &fetcher.NFTMetadata{Name: f.names[mint], ExternalURL: f.external},
+	}
+	if status := f.checks[mint]; status != "" {
+		info.MetadataCheck = &fetcher.MetadataCheck{Status: status, URL: "https://dweb.link/ipfs/cid"}
+	}
+	return info, nil
+}
+
+func (f *fakeSource) DownloadMedia(ctx context.Context, info *fetcher.NFTInfo, mediaDir string) error {
+	f.mediaDir = append(f.mediaDir, mediaDir)
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		return err
+	}
+	image := f.image
+	if image == nil {
+		image = []byte("png")
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "image.png"), image, 0644); err != nil {
+		return err
+	}
+	info.MediaFiles = append(info.MediaFiles, &fetcher.MediaFile{Filename: "image.png", ContentType: "image/png", Size: int64(len(image))})
+	if f.page != "" {
+		info.MediaFiles = append(info.MediaFiles, &fetcher.MediaFile{URL: f.page, Filename: "index.html", MediaType: fetcher.MediaTypeHTML})
+	}
+	return nil
+}
+
+func (f *fakeSource) MintSupply(ctx context.Context, mint solanago.PublicKey) (uint64, error) {
+	return f.supply[mint], nil
+}
+
+func TestSync(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	kept, sold, burned := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	source := &fakeSource{
+		held:   []solanago.PublicKey{kept, sold, burned},
+		names:  map[solanago.PublicKey]string{kept: "Kept", sold: "Sold", burned: "Burned"},
+		supply: map[solanago.PublicKey]uint64{sold: 1, burned: 0},
+	}
+
+	summary, err := Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	if summary.Counts[string(ChangeAdded)] != 3 {
+		t.Fatalf("Expected 3 added, got %+v", summary.Counts)
+	}
+	if stored, err := store.GetNFT(ctx, wallet, kept); err != nil || len(stored.NFTInfo.MediaFiles) != 1 {
+		t.Fatalf("Expected media recorded for kept NFT, got %+v (err %v)", stored, err)
+	}
+	if tree, err := store.LoadMerkle(wallet.String()); err != nil || tree.Root != summary.MerkleRoot || len(tree.Leaves) != 3 {
+		t.Fatalf("Expected the Merkle root over 3 NFTs saved, got %+v (err %v)", tree, err)
+	}
+
+	// Second run: two NFTs left the wallet, one metadata changed
+	source.held = []solanago.PublicKey{kept}
+	source.names[kept] = "Kept (revealed)"
+
+	summary, err = Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+
+	expected := map[string]int{string(ChangeUpdated): 1, string(ChangeTransferred): 1, string(ChangeBurned): 1}
+	for kind, count := range expected {
+		if summary.Counts[kind] != count {
+			t.Errorf("Expected %d %s, got %+v", count, kind, summary.Counts)
+		}
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, burned)
+	if err != nil {
+		t.Fatalf("Burned NFT backup should be kept: %v", err)
+	}
+	if stored.Status != storage.StatusBurned {
+		t.Errorf("Expected status %q, got %q", storage.StatusBurned, stored.Status)
+	}
+
+	// Third run changes nothing and, with NewOnly, fetches nothing
+	root := summary.MerkleRoot
+	source.fetched = 0
+	summary, err = Sync(ctx, store, source, wallet, Options{NewOnly: true})
+	if err != nil {
+		t.Fatalf("Third sync failed: %v", err)
+	}
+	if summary.Counts[string(ChangeUnchanged)] != 1 || len(summary.Changes) != 1 || source.fetched != 0 {
+		t.Errorf("Expected a single unchanged NFT and no fetches, got %+v (fetched %d)", summary.Changes, source.fetched)
+	}
+	if summary.MerkleRoot != root {
+		t.Errorf("Expected the Merkle root to stay %s, got %s", root, summary.MerkleRoot)
+	}
+}
+
+func TestSync_DryRun(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	wallet := solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{solanago.NewWallet().PublicKey()}}
+
+	summary, err := Sync(context.Background(), store, source, wallet, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if summary.Counts[string(ChangeAdded)] != 1 {
+		t.Errorf("Expected 1 NFT reported as added, got %+v", summary.Counts)
+	}
+	if len(store.Index().List()) != 0 || len(source.mediaDir) != 0 {
+		t.Errorf("Dry run must not write to the vault")
+	}
+
+	// The change says what a real run would do
+	plan := summary.Changes[0].Plan
+	if len(plan) == 0 || !strings.HasPrefix(plan[0], "write wallets/"+wallet.String()) {
+		t.Errorf("Expected the plan to start with the directory it would write, got %v", plan)
+	}
+}
+
+func TestSync_PartialListing(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	first, second := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{first, second}, supply: map[solanago.PublicKey]uint64{first: 1, second: 1}}
+
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	// A paused enumeration that has only reached the first NFT so far
+	source.held = []solanago.PublicKey{first}
+	source.partial = true
+
+	summary, err := Sync(ctx, store, source, wallet, Options{NewOnly: true})
+	if err != nil {
+		t.Fatalf("Partial sync failed: %v", err)
+	}
+	if !summary.Partial || summary.Counts[string(ChangeTransferred)] != 0 {
+		t.Errorf("Expected no departures from a partial listing, got %+v", summary)
+	}
+}
+
+func TestSync_StorageLimits(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{solanago.NewWallet().PublicKey()}}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	// A vault past its size limit refuses the new NFT and says why
+	source.held = append(source.held, solanago.NewWallet().PublicKey())
+	store.SetLimits(storage.Limits{MaxBytes: 1})
+	summary, err := Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Sync of a full vault failed: %v", err)
+	}
+	if summary.Counts[string(ChangeFailed)] != 1 || summary.VaultFull == "" {
+		t.Errorf("Expected the new NFT refused and the vault reported full, got %+v", summary)
+	}
+
+	if _, err := fsutil.FreeSpace(store.BaseDir()); err != nil {
+		t.Skipf("Free space is not available here: %v", err)
+	}
+	// A disk below the free-space threshold stops the run before any backup
+	store.SetLimits(storage.Limits{MinFreeBytes: math.MaxInt64})
+	summary, err = Sync(ctx, store, source, wallet, Options{})
+	if !errors.Is(err, storage.ErrLowDiskSpace) {
+		t.Fatalf("Expected the run stopped for disk space, got %v", err)
+	}
+	if summary == nil || len(summary.Changes) != 0 {
+		t.Errorf("Expected no backups attempted, got %+v", summary)
+	}
+}
+
+// fakeMarket prices every mint the same, or fails when err is set
+type fakeMarket struct {
+	err error
+}
+
+func (f *fakeMarket) Name() string { return "Fake" }
+
+func (f *fakeMarket) Quote(ctx context.Context, mint string) (*market.Quote, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &market.Quote{Marketplace: "Fake", FloorLamports: 5, LastSaleLamports: 7, FetchedAt: time.Now()}, nil
+}
+
+func TestSync_RecordsMarketQuote(t *testing.T) {
+	tests := []struct {
+		name      string
+		market    *fakeMarket
+		wantQuote bool
+	}{
+		{"quote recorded", &fakeMarket{}, true},
+		{"marketplace down", &fakeMarket{err: errors.New("HTTP 503")}, false},
+		{"not listed", &fakeMarket{err: market.ErrNotFound}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := storage.NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			ctx := context.Background()
+			wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+			source := &fakeSource{held: []solanago.PublicKey{mint}}
+
+			summary, err := Sync(ctx, store, source, wallet, Options{Market: tt.market})
+			if err != nil {
+				t.Fatalf("Failed to sync: %v", err)
+			}
+			if summary.Counts[string(ChangeAdded)] != 1 {
+				t.Fatalf("Expected the NFT to be added despite market errors, got %v", summary.Counts)
+			}
+
+			stored, err := store.GetNFT(ctx, wallet, mint)
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			if got := stored.NFTInfo.Market != nil; got != tt.wantQuote {
+				t.Fatalf("Got quote recorded %v, expected %v", got, tt.wantQuote)
+			}
+			if tt.wantQuote && stored.NFTInfo.Market.LastSaleLamports != 7 {
+				t.Errorf("Unexpected quote %+v", stored.NFTInfo.Market)
+			}
+		})
+	}
+}
+
+func TestSync_MetadataMismatch(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	existing, arrived := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{
+		held:  []solanago.PublicKey{existing},
+		names: map[solanago.PublicKey]string{existing: "Original", arrived: "Arrived"},
+	}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+
+	// The gateways now disagree: the changed metadata may be poisoned
+	source.held = []solanago.PublicKey{existing, arrived}
+	source.names[existing] = "Poisoned"
+	source.checks = map[solanago.PublicKey]string{existing: fetcher.CheckMismatch, arrived: fetcher.CheckMismatch}
+	summary, err := Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+
+	tests := []struct {
+		mint     solanago.PublicKey
+		kind     ChangeKind
+		wantName string
+	}{
+		{existing, ChangeFailed, "Original"}, // The last good copy is kept
+		{arrived, ChangeAdded, "Arrived"},    // Saved, but flagged
+	}
+	for _, tt := range tests {
+		var change *Change
+		for i := range summary.Changes {
+			if summary.Changes[i].Mint == tt.mint.String() {
+				change = &summary.Changes[i]
+			}
+		}
+		if change == nil || change.Kind != tt.kind || !strings.Contains(change.Error, "metadata differs") {
+			t.Errorf("Expected %s %s with a mismatch error, got %+v", tt.wantName, tt.kind, change)
+			continue
+		}
+		stored, err := store.GetNFT(ctx, wallet, tt.mint)
+		if err != nil {
+			t.Fatalf("Failed to get NFT: %v", err)
+		}
+		if stored.NFTInfo.Metadata.Name != tt.wantName {
+			t.Errorf("Expected the backup named %q, got %q", tt.wantName, stored.NFTInfo.Metadata.Name)
+		}
+	}
+}
+
+func TestSync_ArchivesExternalSite(t *testing.T) {
+	requests := 0
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<h1>Project site</h1>"))
+	}))
+	defer site.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		wantArchive bool
+	}{
+		{"site up", "/", true},
+		{"site gone", "/gone", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := storage.NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			ctx := context.Background()
+			wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+			source := &fakeSource{held: []solanago.PublicKey{mint}, external: site.URL + tt.path}
+			opts := Options{Archive: webarchive.New(webarchive.DefaultLimits)}
+
+			summary, err := Sync(ctx, store, source, wallet, opts)
+			if err != nil {
+				t.Fatalf("Failed to sync: %v", err)
+			}
+			if summary.Counts[string(ChangeAdded)] != 1 {
+				t.Fatalf("Expected the NFT to be added whether or not the site archives, got %v", summary.Counts)
+			}
+			if failed := summary.Changes[0].Error != ""; failed == tt.wantArchive {
+				t.Errorf("Unexpected change error %q", summary.Changes[0].Error)
+			}
+
+			stored, err := store.GetNFT(ctx, wallet, mint)
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			if got := stored.NFTInfo.ExternalArchive != nil; got != tt.wantArchive {
+				t.Fatalf("Got archive recorded %v, expected %v", got, tt.wantArchive)
+			}
+			index := filepath.Join(store.NFTDir(wallet, mint), storage.ExternalDir, webarchive.IndexFile)
+			if _, err := os.Stat(index); (err == nil) != tt.wantArchive {
+				t.Errorf("Archived page present = %v, expected %v", err == nil, tt.wantArchive)
+			}
+
+			// An unchanged NFT that is already archived is not fetched again
+			before := requests
+			if _, err := Sync(ctx, store, source, wallet, opts); err != nil {
+				t.Fatalf("Failed to sync: %v", err)
+			}
+			if retried := requests > before; retried == tt.wantArchive {
+				t.Errorf("Site requested again = %v on an unchanged NFT", retried)
+			}
+		})
+	}
+}
+
+// fakeRenderer writes a placeholder screenshot instead of running a browser
+type fakeRenderer struct {
+	rendered []string
+	err      error
+}
+
+func (f *fakeRenderer) Capture(ctx context.Context, pageURL, dir, name string) ([]render.Capture, error) {
+	f.rendered = append(f.rendered, pageURL)
+	if f.err != nil {
+		return nil, f.err
+	}
+	path := filepath.Join(dir, name+".png")
+	if err := os.WriteFile(path, []byte("screenshot"), 0644); err != nil {
+		return nil, err
+	}
+	return []render.Capture{{Path: path, ContentType: "image/png"}}, nil
+}
+
+func TestSync_RendersHTMLMedia(t *testing.T) {
+	const page = "https://art.example/piece/index.html"
+
+	tests := []struct {
+		name       string
+		renderer   *fakeRenderer
+		wantRender bool
+	}{
+		{"rendered", &fakeRenderer{}, true},
+		{"browser missing", &fakeRenderer{err: errors.New("executable file not found")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := storage.NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			ctx := context.Background()
+			wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+			source := &fakeSource{held: []solanago.PublicKey{mint}, page: page}
+
+			summary, err := Sync(ctx, store, source, wallet, Options{Render: tt.renderer})
+			if err != nil {
+				t.Fatalf("Failed to sync: %v", err)
+			}
+			if summary.Counts[string(ChangeAdded)] != 1 {
+				t.Fatalf("Expected the NFT to be added whether or not it renders, got %v", summary.Counts)
+			}
+
+			stored, err := store.GetNFT(ctx, wallet, mint)
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			var capture *fetcher.MediaFile
+			for _, media := range stored.NFTInfo.MediaFiles {
+				if media.RenderedFrom == page {
+					capture = media
+				}
+			}
+			if (capture != nil) != tt.wantRender {
+				t.Fatalf("Got render recorded %v, expected %v", capture != nil, tt.wantRender)
+			}
+			if capture != nil && (capture.MediaType != fetcher.MediaTypeImage || capture.Size != int64(len("screenshot")) || capture.Checksum == "") {
+				t.Errorf("Unexpected capture %+v", capture)
+			}
+
+			// A page already rendered is not rendered again
+			if _, err := Sync(ctx, store, source, wallet, Options{Render: tt.renderer}); err != nil {
+				t.Fatalf("Failed to sync: %v", err)
+			}
+			if again := len(tt.renderer.rendered) > 1; again == tt.wantRender {
+				t.Errorf("Rendered %d times", len(tt.renderer.rendered))
+			}
+		})
+	}
+}
+
+func TestSync_MakesThumbnails(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{mint}, image: img.Bytes()}
+
+	// Backed up without thumbnails, then backfilled by a later run
+	for _, sizes := range [][]int{nil, {64, 256}} {
+		if _, err := Sync(ctx, store, source, wallet, Options{Thumbnails: sizes}); err != nil {
+			t.Fatalf("Failed to sync: %v", err)
+		}
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, mint)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	thumbnails := stored.NFTInfo.MediaFiles[0].Thumbnails
+	if len(thumbnails) != 2 {
+		t.Fatalf("Got %d thumbnails, want 2", len(thumbnails))
+	}
+	if thumbnails[0].Width != 64 || thumbnails[1].Width != 256 {
+		t.Errorf("Unexpected thumbnails %+v %+v", thumbnails[0], thumbnails[1])
+	}
+	for _, thumb := range thumbnails {
+		if _, err := os.Stat(filepath.Join(store.NFTDir(wallet, mint), "media", thumb.Filename)); err != nil {
+			t.Errorf("Thumbnail %s not saved: %v", thumb.Filename, err)
+		}
+	}
+}
+
+func TestSync_PerceptualHash(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	want, err := phash.Compute(img.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to hash image: %v", err)
+	}
+
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{mint}, image: img.Bytes()}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, mint)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	media := stored.NFTInfo.MediaFiles[0]
+	if got := media.PerceptualHash; got == nil || *got != *want {
+		t.Errorf("Recorded perceptual hash %+v, want %+v", got, want)
+	}
+	pixels, _ := phash.Pixels(img.Bytes())
+	if media.PixelHash == "" || media.PixelHash != pixels {
+		t.Errorf("Recorded pixel hash %q, want that of the image", media.PixelHash)
+	}
+}
+
+func TestSync_Journal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, good, bad := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{
+		held:     []solanago.PublicKey{good, bad},
+		fetchErr: map[solanago.PublicKey]error{bad: errors.New("rpc timeout")},
+	}
+	events := journal.Open(dir)
+
+	// The second run retries the failed mint and leaves the backed-up one alone
+	for i := 0; i < 2; i++ {
+		if _, err := Sync(ctx, store, source, wallet, Options{NewOnly: true, Journal: events}); err != nil {
+			t.Fatalf("Failed to sync: %v", err)
+		}
+		source.fetchErr = nil
+	}
+
+	recorded, _, err := events.Read(journal.Filter{})
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	want := map[string][]journal.Kind{
+		good.String(): {journal.MintDetected, journal.BackupStarted, journal.BackupCompleted},
+		bad.String():  {journal.MintDetected, journal.BackupFailed, journal.MintDetected, journal.BackupStarted, journal.BackupCompleted},
+	}
+	got := make(map[string][]journal.Kind)
+	for _, event := range recorded {
+		got[event.Mint] = append(got[event.Mint], event.Kind)
+		if event.Kind == journal.BackupFailed && event.Error != "rpc timeout" {
+			t.Errorf("Expected the failure to be journaled, got %+v", event)
+		}
+	}
+	for mint, kinds := range want {
+		if len(got[mint]) != len(kinds) {
+			t.Fatalf("Mint %s: expected %v, got %v", mint, kinds, got[mint])
+		}
+		for i := range kinds {
+			if got[mint][i] != kinds[i] {
+				t.Errorf("Mint %s event %d: expected %s, got %s", mint, i, kinds[i], got[mint][i])
+			}
+		}
+	}
+}
+
+func TestSync_ResumesInterruptedBackup(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{mint}}
+
+	// A run that died after saving the record, before downloading media
+	info, _ := source.FetchNFT(ctx, wallet, mint)
+	if err := store.SaveNFTInProgress(ctx, info); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	summary, err := Sync(ctx, store, source, wallet, Options{NewOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeResumed)] != 1 {
+		t.Fatalf("Expected the interrupted backup to be resumed, got %+v", summary.Counts)
+	}
+	if len(source.mediaDir) != 1 {
+		t.Errorf("Expected media to be downloaded, got %d downloads", len(source.mediaDir))
+	}
+	if store.Index().Get(wallet.String(), mint.String()).InProgress {
+		t.Errorf("Expected the resumed backup to be complete")
+	}
+
+	// Complete now, so the next new-only run leaves it alone
+	summary, err = Sync(ctx, store, source, wallet, Options{NewOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeUnchanged)] != 1 {
+		t.Errorf("Expected the completed backup to be unchanged, got %+v", summary.Counts)
+	}
+}
+
+// fakeHistory serves one transaction per mint, moving it from one owner to
+// another
+type fakeHistory struct {
+	from, to string
+	lookups  int
+}
+
+func (f *fakeHistory) Recent(ctx context.Context, mint string, limit int) ([]string, error) {
+	f.lookups++
+	return []string{"sig-" + mint}, nil
+}
+
+func (f *fakeHistory) Transaction(ctx context.Context, signature string) (*provenance.Tx, error) {
+	mint := strings.TrimPrefix(signature, "sig-")
+	return &provenance.Tx{
+		Signature:  signature,
+		PreTokens:  []provenance.TokenBalance{{Owner: f.from, Mint: mint, Amount: 1}},
+		PostTokens: []provenance.TokenBalance{{Owner: f.to, Mint: mint, Amount: 1}},
+	}, nil
+}
+
+func TestSync_ArrivalsAndDepartures(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, first, received := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{held: []solanago.PublicKey{first}, supply: map[solanago.PublicKey]uint64{received: 1}}
+	history := &fakeHistory{from: solanago.NewWallet().PublicKey().String(), to: wallet.String()}
+	events := journal.Open(dir)
+	opts := Options{NewOnly: true, Journal: events, History: history}
+
+	// The first run backs up what the wallet already held without lookups
+	if _, err := Sync(ctx, store, source, wallet, opts); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if history.lookups != 0 {
+		t.Fatalf("Expected no arrival lookups on the first run, got %d", history.lookups)
+	}
+
+	// An NFT transferred in later is backed up and described
+	source.held = append(source.held, received)
+	summary, err := Sync(ctx, store, source, wallet, opts)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	var change *Change
+	for i := range summary.Changes {
+		if summary.Changes[i].Mint == received.String() {
+			change = &summary.Changes[i]
+		}
+	}
+	if change == nil || change.Kind != ChangeAdded || change.Arrival == nil || change.Arrival.Kind != provenance.KindTransfer {
+		t.Fatalf("Expected the received NFT added as a transfer, got %+v", change)
+	}
+	if history.lookups != 1 {
+		t.Errorf("Expected one arrival lookup, got %d", history.lookups)
+	}
+
+	recorded, _, err := events.Read(journal.Filter{Mint: received.String(), Kinds: []journal.Kind{journal.MintDetected}})
+	if err != nil || len(recorded) != 1 {
+		t.Fatalf("Failed to read the detection event: %v (%d events)", err, len(recorded))
+	}
+	if want := "received from " + history.from; recorded[0].Detail != want {
+		t.Errorf("Expected detail %q, got %q", want, recorded[0].Detail)
+	}
+
+	// Sending it on is recorded with the outgoing transaction
+	source.held = []solanago.PublicKey{first}
+	history.from, history.to = wallet.String(), solanago.NewWallet().PublicKey().String()
+	summary, err = Sync(ctx, store, source, wallet, opts)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	change = nil
+	for i := range summary.Changes {
+		if summary.Changes[i].Mint == received.String() {
+			change = &summary.Changes[i]
+		}
+	}
+	if change == nil || change.Kind != ChangeTransferred || change.Departure == nil || change.Departure.To != history.to {
+		t.Fatalf("Expected the NFT marked transferred to %s, got %+v", history.to, change)
+	}
+	stored, err := store.GetNFT(ctx, wallet, received)
+	if err != nil {
+		t.Fatalf("Failed to load the backup: %v", err)
+	}
+	last := stored.Custody[len(stored.Custody)-1]
+	if last.Signature != "sig-"+received.String() || last.Recipient != history.to {
+		t.Errorf("Expected the departure on the custody record, got %+v", last)
+	}
+	recorded, _, err = events.Read(journal.Filter{Mint: received.String(), Kinds: []journal.Kind{journal.NFTDeparted}})
+	if err != nil || len(recorded) != 1 || recorded[0].Signature != last.Signature {
+		t.Errorf("Expected one departure event with the signature, got %+v (err %v)", recorded, err)
+	}
+}
+
+// custodySource is a fakeSource that knows which NFTs programs hold
+type custodySource struct {
+	*fakeSource
+	custody map[solanago.PublicKey]*fetcher.Escrow
+}
+
+func (s *custodySource) FindCustody(ctx context.Context, mint solanago.PublicKey) (*fetcher.Escrow, error) {
+	return s.custody[mint], nil
+}
+
+func TestSync_Escrowed(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, staked, sold := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &custodySource{
+		fakeSource: &fakeSource{held: []solanago.PublicKey{staked, sold}, supply: map[solanago.PublicKey]uint64{staked: 1, sold: 1}},
+		custody:    make(map[solanago.PublicKey]*fetcher.Escrow),
+	}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	// One NFT goes into a staking vault and the other is sold
+	vault := &fetcher.Escrow{Custodian: solanago.NewWallet().PublicKey().String(), Program: "bankHHdqMuaaST4qQk6mkzxhujevc1VGT6ufUr1tpcQ", Label: "Gem Bank", CheckedAt: time.Now()}
+	source.held = nil
+	source.custody[staked] = vault
+	summary, err := Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeEscrowed)] != 1 || summary.Counts[string(ChangeTransferred)] != 1 {
+		t.Fatalf("Expected one escrowed and one transferred NFT, got %v", summary.Counts)
+	}
+	if entry := store.Index().Get(wallet.String(), staked.String()); entry == nil || (entry.Status != "" && entry.Status != storage.StatusHeld) {
+		t.Errorf("Expected the staked NFT still held, got %+v", entry)
+	}
+	stored, err := store.GetNFT(ctx, wallet, staked)
+	if err != nil {
+		t.Fatalf("Failed to load the backup: %v", err)
+	}
+	if !stored.NFTInfo.Escrow.Same(vault) {
+		t.Errorf("Expected the custody recorded, got %+v", stored.NFTInfo.Escrow)
+	}
+
+	// Still staked: nothing to report
+	summary, err = Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeEscrowed)] != 0 {
+		t.Errorf("Expected no change while staked, got %v", summary.Counts)
+	}
+
+	// Unstaked: the custody state is cleared
+	source.held = []solanago.PublicKey{staked}
+	delete(source.custody, staked)
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	stored, err = store.GetNFT(ctx, wallet, staked)
+	if err != nil {
+		t.Fatalf("Failed to load the backup: %v", err)
+	}
+	if stored.NFTInfo.Escrow != nil {
+		t.Errorf("Expected the custody cleared once back in the wallet, got %+v", stored.NFTInfo.Escrow)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, kept, taken := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	source := &fakeSource{
+		held:   []solanago.PublicKey{kept, taken},
+		names:  map[solanago.PublicKey]string{kept: "Kept", taken: "Taken"},
+		supply: map[solanago.PublicKey]uint64{taken: 1},
+	}
+	if _, err := Sync(ctx, store, source, wallet, Options{}); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	// A snapshot re-fetches what is left even when asked for new NFTs only
+	source.held, source.fetched = []solanago.PublicKey{kept}, 0
+	snapshot, path, err := Snapshot(ctx, store, source, wallet, "drain", Options{NewOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if source.fetched != 1 || len(snapshot.Held) != 1 || snapshot.Held[0].Mint != kept.String() {
+		t.Fatalf("Expected only the kept NFT, re-fetched, got %+v (fetched %d)", snapshot.Held, source.fetched)
+	}
+	if filepath.Dir(path) != filepath.Join(dir, SnapshotDir, wallet.String()) {
+		t.Errorf("Snapshot written to unexpected path %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Failed to find the snapshot file: %v", err)
+	}
+}
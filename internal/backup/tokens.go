@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// TokenSource is a Source that can also list and fetch the fungible tokens
+// and SFTs a wallet holds, for Options.Fungibles
+type TokenSource interface {
+	// ListTokens returns every balance that is not a single NFT
+	ListTokens(ctx context.Context, owner solanago.PublicKey) ([]fetcher.TokenBalance, error)
+
+	// FetchToken fetches on-chain and off-chain metadata for a held token
+	FetchToken(ctx context.Context, owner solanago.PublicKey, balance fetcher.TokenBalance) (*fetcher.NFTInfo, error)
+}
+
+// syncTokens backs up the wallet's fungible tokens and SFTs under
+// tokens/, adding a change per token to summary
+//
+// Explanation: Tokens are kept out of the index and the wallet's NFT
+// directories, so listings, search, rarity and the Merkle root describe
+// NFTs alone. A token the wallet no longer holds keeps its backup.
+func syncTokens(ctx context.Context, store *storage.FileStorage, source Source, wallet solanago.PublicKey, summary *Summary, opts Options) error {
+	tokens, ok := source.(TokenSource)
+	if !ok {
+		opts.progress("⚠️  This source cannot list fungible tokens; skipping them")
+		return nil
+	}
+
+	opts.progress("🪙 Listing fungible tokens and SFTs held by %s...", wallet.String())
+	balances, err := tokens.ListTokens(ctx, wallet)
+	if err != nil {
+		return fmt.Errorf("failed to list wallet tokens: %w", err)
+	}
+	for _, balance := range balances {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		summary.add(syncToken(ctx, store, tokens, source, wallet, balance, opts))
+	}
+	return nil
+}
+
+// syncToken backs up one token if it is new or its metadata changed, and
+// records its balance
+func syncToken(ctx context.Context, store *storage.FileStorage, tokens TokenSource, source Source, wallet solanago.PublicKey, balance fetcher.TokenBalance, opts Options) Change {
+	change := Change{Mint: balance.Mint.String(), Token: balance.Standard()}
+
+	stored, err := store.GetToken(ctx, wallet, balance.Mint)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return change
+	}
+	if stored != nil {
+		if md := stored.TokenInfo.Metadata; md != nil {
+			change.Name = md.Name
+		}
+		if opts.NewOnly {
+			change.Kind = ChangeUnchanged
+			return change
+		}
+	}
+
+	info, err := tokens.FetchToken(ctx, wallet, balance)
+	if err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+		return change
+	}
+	if info.Metadata != nil {
+		change.Name = info.Metadata.Name
+	}
+
+	// A balance change alone is recorded without fetching the media again
+	download := true
+	switch {
+	case stored == nil:
+		change.Kind = ChangeAdded
+	case storage.MetadataFingerprint(stored.TokenInfo) != storage.MetadataFingerprint(info):
+		change.Kind = ChangeUpdated
+	case stored.Amount != balance.Amount:
+		change.Kind, download = ChangeUpdated, false
+		info.MediaFiles = stored.TokenInfo.MediaFiles
+	default:
+		change.Kind = ChangeUnchanged
+		return change
+	}
+
+	dir := store.TokenDir(wallet, balance.Mint)
+	if opts.DryRun {
+		change.Plan = []string{"write " + vaultRelative(store, dir)}
+		if download {
+			for _, mediaURL := range fetcher.MediaURLs(info.Metadata) {
+				change.Plan = append(change.Plan, "fetch "+mediaURL)
+			}
+		}
+		return change
+	}
+
+	opts.progress("💾 Backing up token %s (%s)", displayName(change), change.Kind)
+	if download {
+		if err := source.DownloadMedia(ctx, info, filepath.Join(dir, "media")); err != nil {
+			change.Error = fmt.Sprintf("media download failed: %v", err)
+		}
+	}
+	token := &storage.StoredToken{TokenInfo: info, Standard: balance.Standard(), Amount: balance.Amount}
+	if err := store.SaveToken(ctx, token); err != nil {
+		change.Kind, change.Error = ChangeFailed, err.Error()
+	}
+	return change
+}
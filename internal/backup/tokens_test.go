@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// tokenSource is a fakeSource whose wallet also holds fungible tokens
+type tokenSource struct {
+	*fakeSource
+	balances []fetcher.TokenBalance
+	version  string // Changes the off-chain metadata of every token
+}
+
+func (s *tokenSource) ListTokens(ctx context.Context, owner solanago.PublicKey) ([]fetcher.TokenBalance, error) {
+	return s.balances, nil
+}
+
+func (s *tokenSource) FetchToken(ctx context.Context, owner solanago.PublicKey, balance fetcher.TokenBalance) (*fetcher.NFTInfo, error) {
+	s.fetched++
+	return &fetcher.NFTInfo{
+		MintAddress: balance.Mint,
+		Owner:       owner,
+		Decimals:    balance.Decimals,
+		FetchedAt:   time.Now(),
+		MetadataURI: "https://example.com/" + balance.Mint.String() + ".json",
+		Metadata:    &fetcher.NFTMetadata{Name: "Token " + s.version, Symbol: "TKN"},
+	}, nil
+}
+
+func TestSync_Fungibles(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, nft := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	coin := fetcher.TokenBalance{Mint: solanago.NewWallet().PublicKey(), Amount: 2_500_000, Decimals: 6}
+	sft := fetcher.TokenBalance{Mint: solanago.NewWallet().PublicKey(), Amount: 3}
+	source := &tokenSource{fakeSource: &fakeSource{held: []solanago.PublicKey{nft}}, balances: []fetcher.TokenBalance{coin, sft}, version: "v1"}
+
+	// Without the option tokens are left alone
+	summary, err := Sync(ctx, store, source, wallet, Options{})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if len(summary.Changes) != 1 {
+		t.Fatalf("Expected only the NFT synced, got %+v", summary.Changes)
+	}
+
+	summary, err = Sync(ctx, store, source, wallet, Options{Fungibles: true})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeAdded)] != 2 {
+		t.Fatalf("Expected both tokens added, got %v", summary.Counts)
+	}
+	for _, change := range summary.Changes {
+		if change.Mint == sft.Mint.String() && change.Token != fetcher.TokenSemiFungible {
+			t.Errorf("Expected the SFT reported as one, got %q", change.Token)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(store.TokenDir(wallet, coin.Mint), "media", "image.png")); err != nil {
+		t.Errorf("Expected the token's media downloaded: %v", err)
+	}
+	if entry := store.Index().Get(wallet.String(), coin.Mint.String()); entry != nil {
+		t.Errorf("Expected tokens kept out of the index, got %+v", entry)
+	}
+	tokens, err := store.ListTokens(ctx, wallet)
+	if err != nil || len(tokens) != 2 {
+		t.Fatalf("Failed to list token backups: %v (%d tokens)", err, len(tokens))
+	}
+
+	// A new balance is recorded without downloading the media again
+	source.balances[0].Amount = 1_000_000
+	downloads := len(source.mediaDir)
+	summary, err = Sync(ctx, store, source, wallet, Options{Fungibles: true})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeUpdated)] != 1 || len(source.mediaDir) != downloads {
+		t.Errorf("Expected one balance update without media downloads, got %v and %d downloads", summary.Counts, len(source.mediaDir)-downloads)
+	}
+	stored, err := store.GetToken(ctx, wallet, coin.Mint)
+	if err != nil {
+		t.Fatalf("Failed to load the token backup: %v", err)
+	}
+	if stored.Amount != 1_000_000 || stored.Standard != fetcher.TokenFungible || len(stored.TokenInfo.MediaFiles) != 1 {
+		t.Errorf("Expected the new balance with the media kept, got %+v", stored)
+	}
+
+	// Changed metadata is backed up again
+	source.version = "v2"
+	summary, err = Sync(ctx, store, source, wallet, Options{Fungibles: true})
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if summary.Counts[string(ChangeUpdated)] != 2 {
+		t.Errorf("Expected both tokens updated, got %v", summary.Counts)
+	}
+}
@@ -0,0 +1,107 @@
+package canonjson
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			// The example from RFC 8785 section 3.2.2
+			name:  "rfc 8785 example",
+			input: `{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}`,
+			want:  `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3: UTF-16 order puts the emoji before U+FB33
+			name:  "key order",
+			input: `{"\u20ac":"Euro","\r":"CR","\ufb33":"Hebrew","1":"One","\ud83d\ude00":"Emoji","\u0080":"Control","\u00f6":"Latin"}`,
+			want:  "{\"\\r\":\"CR\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin\",\"€\":\"Euro\",\"😀\":\"Emoji\",\"\ufb33\":\"Hebrew\"}",
+		},
+		{
+			name:  "whitespace and nesting",
+			input: "{\n  \"b\" : [ 1 , { \"d\" : true, \"c\" : null } ],\n  \"a\" : \"x\"\n}\n",
+			want:  `{"a":"x","b":[1,{"c":null,"d":true}]}`,
+		},
+		{
+			name:  "html characters are not escaped",
+			input: `{"description":"<b>Tom & Jerry</b>"}`,
+			want:  `{"description":"<b>Tom & Jerry</b>"}`,
+		},
+		{name: "trailing data", input: `{"a":1} {"b":2}`, wantErr: true},
+		{name: "invalid", input: `{"a":`, wantErr: true},
+		{name: "out of range", input: `[1e400]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to canonicalize: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{-0.0, "0"},
+		{1, "1"},
+		{-1.5, "-1.5"},
+		{0.000001, "0.000001"},
+		{1e-7, "1e-7"},
+		{1e20, "100000000000000000000"},
+		{1e21, "1e+21"},
+		{123456789012, "123456789012"},
+		{9007199254740992, "9007199254740992"},
+		{5e-324, "5e-324"},
+		{1.7976931348623157e308, "1.7976931348623157e+308"},
+		{1.5e-10, "1.5e-10"},
+	}
+
+	for _, tt := range tests {
+		if got := formatNumber(tt.value); got != tt.want {
+			t.Errorf("formatNumber(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	compact, err := Hash([]byte(`{"name":"Cat #1","attributes":[{"trait_type":"Eyes","value":"Blue"}]}`))
+	if err != nil {
+		t.Fatalf("Failed to hash: %v", err)
+	}
+	reformatted, err := Hash([]byte("{\n  \"attributes\": [ { \"value\": \"Blue\", \"trait_type\": \"Eyes\" } ],\n  \"name\": \"Cat \\u00231\"\n}"))
+	if err != nil {
+		t.Fatalf("Failed to hash: %v", err)
+	}
+	if compact != reformatted {
+		t.Errorf("Semantically identical documents hash differently: %s, %s", compact, reformatted)
+	}
+
+	changed, err := Hash([]byte(`{"name":"Cat #2","attributes":[{"trait_type":"Eyes","value":"Blue"}]}`))
+	if err != nil {
+		t.Fatalf("Failed to hash: %v", err)
+	}
+	if changed == compact {
+		t.Errorf("Different documents hash the same")
+	}
+}
@@ -0,0 +1,229 @@
+// Package config layers SolVault's settings. Every setting is an environment
+// variable, which is how the rest of SolVault reads it; this package fills
+// in the ones left unset from, in order of precedence:
+//
+//	./.env               per-directory overrides
+//	~/.solvault.yaml     the config file (--config or SOLVAULT_CONFIG to move it)
+//	~/.solvault.env      the older per-user file
+//
+// Real environment variables win over all of them, and command-line flags
+// such as --vault win over everything.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	// DefaultFile is the config file name in the home directory
+	DefaultFile = ".solvault.yaml"
+
+	// EnvPath points at a config file elsewhere, like the --config flag
+	EnvPath = "SOLVAULT_CONFIG"
+
+	// legacyFile is the key=value user file read before the YAML one existed
+	legacyFile = ".solvault.env"
+
+	// dotEnvFile is read from the working directory
+	dotEnvFile = ".env"
+)
+
+// Sources a setting can come from, as shown by 'solvault config show'
+const (
+	SourceEnv     = "environment"
+	SourceDefault = "default"
+)
+
+var (
+	mu       sync.Mutex
+	filePath string // Set by --config
+)
+
+// startEnv is the process environment before any file was loaded, so values
+// a file exported are not mistaken for real environment variables
+var startEnv = environ()
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, entry := range os.Environ() {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// SetPath selects the config file, as the --config flag does; "" restores
+// the default
+func SetPath(path string) {
+	mu.Lock()
+	defer mu.Unlock()
+	filePath = path
+}
+
+// Path is the config file in use: --config, then $SOLVAULT_CONFIG, then
+// ~/.solvault.yaml
+func Path() string {
+	mu.Lock()
+	path := filePath
+	mu.Unlock()
+	if path != "" {
+		return path
+	}
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultFile
+	}
+	return filepath.Join(homeDir, DefaultFile)
+}
+
+// Layer is one source of settings, keyed by environment variable
+type Layer struct {
+	Source string
+	Values map[string]string
+}
+
+// Layers reads every config source below the environment, highest
+// precedence first. Missing files are skipped.
+func Layers() ([]Layer, error) {
+	var layers []Layer
+
+	add := func(source string, read func(string) (map[string]string, error), path string) error {
+		values, err := read(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		layers = append(layers, Layer{Source: source, Values: values})
+		return nil
+	}
+
+	if err := add(dotEnvFile, readEnvFile, dotEnvFile); err != nil {
+		return nil, err
+	}
+	path := Path()
+	if err := add(path, ReadFile, path); err != nil {
+		return nil, err
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		legacy := filepath.Join(homeDir, legacyFile)
+		if err := add(legacy, readEnvFile, legacy); err != nil {
+			return nil, err
+		}
+	}
+	return layers, nil
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return godotenv.Read(path)
+}
+
+// Load exports every layer into the environment.
+// Explanation: A variable that is unset or empty takes its value from the
+// first layer that has one. Empty counts as unset because the generated .env
+// lists optional settings with blank values, which must not hide the same
+// settings in the config file.
+func Load() error {
+	layers, err := Layers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		for key, value := range layer.Values {
+			if value != "" && os.Getenv(key) == "" {
+				os.Setenv(key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// Setting is the resolved value of one key and where it came from
+type Setting struct {
+	Key
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"` // "" when unset
+}
+
+// Resolve reports every schema key, plus the numbered fallback RPC URLs
+// that are set, with its effective value and source. Flags are not seen
+// here; the caller overlays them.
+func Resolve() ([]Setting, error) {
+	layers, err := Layers()
+	if err != nil {
+		return nil, err
+	}
+	layers = append([]Layer{{Source: SourceEnv, Values: startEnv}}, layers...)
+
+	keys := append([]Key(nil), Schema...)
+	var numbered []string
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		for env := range layer.Values {
+			if isNumberedRPC(env) && !seen[env] {
+				seen[env] = true
+				numbered = append(numbered, env)
+			}
+		}
+	}
+	sort.Slice(numbered, func(i, j int) bool { return rpcIndex(numbered[i]) < rpcIndex(numbered[j]) })
+	for _, env := range numbered {
+		key, _ := Lookup(env)
+		keys = append(keys, key)
+	}
+
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		setting := Setting{Key: key}
+		for _, layer := range layers {
+			if value := layer.Values[key.Env]; value != "" {
+				setting.Value, setting.Source = value, layer.Source
+				break
+			}
+		}
+		if setting.Source == "" && key.Default != "" {
+			setting.Value, setting.Source = key.Default, SourceDefault
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// ShadowedBy reports the sources above the config file that set key, which
+// hide a value written to the file
+func ShadowedBy(key Key) ([]string, error) {
+	layers, err := Layers()
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	if startEnv[key.Env] != "" {
+		sources = append(sources, SourceEnv)
+	}
+	path := Path()
+	for _, layer := range layers {
+		if layer.Source == path {
+			break
+		}
+		if layer.Values[key.Env] != "" {
+			sources = append(sources, layer.Source)
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configDirs points HOME and the working directory at empty temporary
+// directories and clears the given variables
+func configDirs(t *testing.T, keys ...string) (home, cwd string) {
+	t.Helper()
+	home, cwd = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvPath, "")
+	SetPath("")
+
+	saved := startEnv
+	startEnv = map[string]string{}
+	for _, key := range keys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(cwd); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		startEnv = saved
+		SetPath("")
+	})
+	return home, cwd
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	keys := []string{"SOLANA_RPC_URL", "WALLET_ADDRESS", "WALLET_ADDRESSES", "NFT_PROVIDER", "HELIUS_API_KEY", "MAX_RETRIES"}
+	home, cwd := configDirs(t, keys...)
+
+	writeFile(t, filepath.Join(cwd, ".env"), "WALLET_ADDRESS=dotenv\nHELIUS_API_KEY=\n")
+	writeFile(t, filepath.Join(home, DefaultFile), `# my settings
+wallet_address: yaml
+wallet_addresses:
+  - a
+  - b
+NFT_PROVIDER: helius
+helius_api_key: from-yaml
+max_retries: 5
+`)
+	writeFile(t, filepath.Join(home, legacyFile), "NFT_PROVIDER=shyft\nSOLANA_RPC_URL=https://legacy.example\nMAX_RETRIES=9\n")
+	os.Setenv("MAX_RETRIES", "7")
+
+	if err := Load(); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	want := map[string]string{
+		"WALLET_ADDRESS":   "dotenv",                 // .env beats the config file
+		"WALLET_ADDRESSES": "a,b",                    // YAML lists become comma separated
+		"NFT_PROVIDER":     "helius",                 // Upper-case keys work too
+		"HELIUS_API_KEY":   "from-yaml",              // A blank .env value does not hide it
+		"SOLANA_RPC_URL":   "https://legacy.example", // ~/.solvault.env fills the rest
+		"MAX_RETRIES":      "7",                      // The environment beats every file
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestConfigFlagPath(t *testing.T) {
+	home, cwd := configDirs(t, "NFT_PROVIDER")
+	writeFile(t, filepath.Join(home, DefaultFile), "nft_provider: helius\n")
+	custom := filepath.Join(cwd, "custom.yaml")
+	writeFile(t, custom, "nft_provider: quicknode\n")
+
+	SetPath(custom)
+	if err := Load(); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := os.Getenv("NFT_PROVIDER"); got != "quicknode" {
+		t.Errorf("NFT_PROVIDER = %q, want the --config file's quicknode", got)
+	}
+}
+
+func TestResolveSources(t *testing.T) {
+	home, cwd := configDirs(t, "NFT_PROVIDER", "SOLANA_RPC_URL", "SOLANA_RPC_URL_2", "SHYFT_API_KEY")
+	startEnv = map[string]string{"SOLANA_RPC_URL": "https://env.example"}
+	writeFile(t, filepath.Join(cwd, ".env"), "SOLANA_RPC_URL=https://dotenv.example\n")
+	writeFile(t, filepath.Join(home, DefaultFile), "nft_provider: shyft\nshyft_api_key: secret-key-1234\nsolana_rpc_url_2: https://backup.example\n")
+
+	settings, err := Resolve()
+	if err != nil {
+		t.Fatalf("Failed to resolve config: %v", err)
+	}
+	got := make(map[string]Setting)
+	for _, setting := range settings {
+		got[setting.Env] = setting
+	}
+
+	tests := []struct {
+		env    string
+		value  string
+		source string
+	}{
+		{"SOLANA_RPC_URL", "https://env.example", SourceEnv},
+		{"NFT_PROVIDER", "shyft", filepath.Join(home, DefaultFile)},
+		{"SOLANA_RPC_URL_2", "https://backup.example", filepath.Join(home, DefaultFile)},
+		{"MAX_RETRIES", "3", SourceDefault},
+		{"DAS_RPC_URL", "", ""},
+	}
+	for _, tt := range tests {
+		setting, ok := got[tt.env]
+		if !ok {
+			t.Errorf("%s missing from resolved settings", tt.env)
+			continue
+		}
+		if setting.Value != tt.value || setting.Source != tt.source {
+			t.Errorf("%s = %q from %q, want %q from %q", tt.env, setting.Value, setting.Source, tt.value, tt.source)
+		}
+	}
+
+	if redacted := got["SHYFT_API_KEY"].Redact(got["SHYFT_API_KEY"].Value); strings.Contains(redacted, "secret") {
+		t.Errorf("Secret shown as %q", redacted)
+	}
+}
+
+func TestSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", DefaultFile)
+
+	rpc, _ := Lookup("solana_rpc_url")
+	wallets, _ := Lookup("WALLET_ADDRESSES")
+	if err := Set(path, rpc, "https://api.devnet.solana.com"); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	writeFile(t, path, "# keep me\nsolana_rpc_url: https://old.example # primary\n")
+
+	if err := Set(path, rpc, "https://new.example"); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if err := Set(path, wallets, "a, b"); err != nil {
+		t.Fatalf("Failed to add list: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	for _, want := range []string{"# keep me", "https://new.example # primary", "wallet_addresses:\n  - a\n  - b"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Config lacks %q:\n%s", want, data)
+		}
+	}
+
+	values, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if values["SOLANA_RPC_URL"] != "https://new.example" || values["WALLET_ADDRESSES"] != "a,b" {
+		t.Errorf("Read back %v", values)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Config mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"rpc_load_balance", "true", false},
+		{"rpc_load_balance", "maybe", true},
+		{"max_retries", "5", false},
+		{"max_retries", "five", true},
+		{"das_rpc_url", "https://das.example", false},
+		{"das_rpc_url", "das.example", true},
+		{"solana_rpc_url_4", "not a url", true},
+		{"wallet_address", "anything", false},
+		{"max_retries", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := Lookup(tt.key)
+		if !ok {
+			t.Fatalf("Failed to look up %s", tt.key)
+		}
+		if err := key.Validate(tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s, %q) error = %v, want error %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+
+	for _, name := range []string{"solana_rpc_url_x", "solana_rpc_url_0", "smtp"} {
+		if _, ok := Lookup(name); ok {
+			t.Errorf("Lookup(%q) found a key", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	writeFile(t, path, "- a\n- b\n")
+	if _, err := ReadFile(path); err == nil {
+		t.Errorf("Expected an error for a config file that is a list")
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads a YAML config file into environment variables. Keys may be
+// written in either case; lists may be YAML sequences. Keys outside the
+// schema are exported too, so newer settings work before they are
+// documented.
+func ReadFile(path string) (map[string]string, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	root := mapping(doc)
+	if root == nil {
+		return values, nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name, node := root.Content[i], root.Content[i+1]
+		value, err := scalarValue(node)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name.Value, node.Line, err)
+		}
+		values[strings.ToUpper(name.Value)] = value
+	}
+	return values, nil
+}
+
+func scalarValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("lists may only hold plain values")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("expected a value or a list")
+	}
+}
+
+// Set writes key: value into the config file, creating it if needed.
+// Explanation: The file is edited as a YAML node tree rather than decoded
+// into a map and re-encoded, so the user's comments and key order survive.
+func Set(path string, key Key, value string) error {
+	if err := key.Validate(value); err != nil {
+		return err
+	}
+	doc, err := readDocument(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if doc == nil || mapping(doc) == nil {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := mapping(doc)
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if key.Type == TypeList && strings.Contains(value, ",") {
+		node = &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		}
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if strings.EqualFold(root.Content[i].Value, key.Name) {
+			node.LineComment = root.Content[i+1].LineComment
+			root.Content[i+1] = node
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key.Name}, node)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	// The file holds API keys, so only its owner may read it
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+func readDocument(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if root := mapping(&doc); root == nil && len(doc.Content) > 0 {
+		return nil, fmt.Errorf("expected key: value pairs at the top level")
+	}
+	return &doc, nil
+}
+
+// mapping returns the document's top-level mapping, nil for an empty file
+func mapping(doc *yaml.Node) *yaml.Node {
+	if doc == nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	if root := doc.Content[0]; root.Kind == yaml.MappingNode {
+		return root
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Type says how a value is validated
+type Type string
+
+const (
+	TypeString Type = "string"
+	TypeURL    Type = "url"
+	TypeBool   Type = "bool"
+	TypeInt    Type = "int"
+	TypeList   Type = "list" // Comma separated, or a YAML sequence in the file
+)
+
+// Key is one documented setting
+type Key struct {
+	Name        string `json:"key"` // Key in the config file
+	Env         string `json:"env"` // Environment variable
+	Type        Type   `json:"type"`
+	Default     string `json:"default,omitempty"` // What SolVault uses when unset
+	Secret      bool   `json:"secret,omitempty"`  // Redacted by 'config show'
+	Description string `json:"description"`
+}
+
+func key(env string, typ Type, def string, secret bool, description string) Key {
+	return Key{Name: strings.ToLower(env), Env: env, Type: typ, Default: def, Secret: secret, Description: description}
+}
+
+// Schema lists every setting SolVault reads. A key in the config file is the
+// lower-case name of its environment variable.
+var Schema = []Key{
+	// Solana
+	key("SOLANA_RPC_URL", TypeList, "", false, "RPC endpoint, or a comma-separated list tried in order"),
+	key("SOLANA_WEBSOCKET_URL", TypeURL, "", false, "WebSocket endpoint for 'watch'"),
+	key("RPC_LOAD_BALANCE", TypeBool, "false", false, "Spread large batch lookups over every RPC endpoint"),
+	key("DAS_RPC_URL", TypeURL, "", false, "DAS-enabled RPC for 'sync --das' and 'onboard'"),
+	key("WALLET_ADDRESS", TypeString, "", false, "Primary wallet to back up, as an address or keypair file"),
+	key("WALLET_ADDRESSES", TypeList, "", false, "Additional wallets to protect, as addresses or keypair files"),
+	key("POLL_INTERVAL_SECONDS", TypeInt, "30", false, "How often 'watch' polls for new NFTs"),
+	key("MAX_RETRIES", TypeInt, "3", false, "Retries for failed RPC calls"),
+	key("TIMEOUT_SECONDS", TypeInt, "60", false, "RPC request timeout"),
+
+	// Vault
+	key("BACKUP_DIRECTORY", TypeString, "~/SolVaultBackups", false, "Vault directory (--vault or --backup-dir)"),
+	key("SOLVAULT_BACKEND", TypeString, "file", false, "Storage backends, the vault first and then replicas (--backend)"),
+	key("SOLVAULT_PASSPHRASE", TypeString, "", true, "Unlocks an encrypted vault"),
+	key("SOLVAULT_KEY_FILE", TypeString, "", false, "Key file that unlocks an encrypted vault"),
+	key("SOLVAULT_MAX_SIZE", TypeString, "", false, "Largest the vault may grow, e.g. 50GB; past it new NFTs are not backed up"),
+	key("SOLVAULT_MIN_FREE", TypeString, "1GB", false, "Free disk space backups always leave; 0 turns the guard off"),
+	key("SOLVAULT_CONFIRM_THRESHOLD", TypeInt, "10", false, "Bulk operations larger than this ask for confirmation"),
+	key("SOLVAULT_KEYPAIR", TypeString, "~/.config/solana/id.json", false, "Keypair that signs 'attest' and 'registry register'"),
+	key("RETENTION_KEEP_VERSIONS", TypeInt, "0", false, "Metadata versions 'prune' keeps per NFT, newest first; 0 keeps all"),
+	key("RETENTION_TRANSFERRED_DAYS", TypeInt, "0", false, "Days 'prune' keeps the media of transferred NFTs; 0 keeps it for good"),
+	key("RETENTION_SCHEDULE", TypeString, "", false, "Cron schedule on which 'watch' applies the retention policy, e.g. @daily"),
+	key("INBOX_DIR", TypeString, "~/.solvault/inbox", false, "Drop folder for mint lists"),
+	key("THUMBNAIL_SIZES", TypeList, "128,512", false, "Thumbnail sizes in pixels made during backup; off disables them"),
+	key("METADATA_CACHE", TypeString, "", false, "ETag cache for off-chain metadata in <vault>/.cache/metadata; off disables it"),
+	key("METADATA_RATE", TypeInt, "5", false, "Off-chain metadata requests per second to one host; 0 removes the limit"),
+	key("METADATA_CROSSCHECK", TypeBool, "false", false, "Compare IPFS and Arweave metadata with a second gateway's copy before trusting it"),
+	key("IPFS_GATEWAYS", TypeList, "https://ipfs.io,https://dweb.link", false, "IPFS gateways the metadata cross-check fetches from, in order"),
+	key("ARWEAVE_GATEWAYS", TypeList, "https://arweave.net,https://ar-io.net", false, "Arweave gateways the metadata cross-check fetches from, in order"),
+	key("RARITY_MIN_SAMPLE", TypeInt, "20", false, "Fewest backups of a collection before rarity ranks are saved with them; 0 turns ranking off"),
+	key("STAKING_PROGRAMS", TypeList, "", false, "Extra staking or escrow programs as ProgramID=Name, recognized in addition to the built-in ones"),
+
+	// Network
+	key("FETCH_PROXY", TypeURL, "", true, "http, https or socks5 proxy for metadata and media (default HTTPS_PROXY/HTTP_PROXY)"),
+	key("FETCH_TIMEOUTS", TypeList, "", false, "Per-host timeouts for metadata and media as host=duration, e.g. arweave.net=2m,*.ipfs.io=90s"),
+	key("FETCH_CA_FILE", TypeString, "", false, "PEM roots trusted for metadata and media hosts in addition to the system pool"),
+	key("FETCH_INSECURE_HOSTS", TypeList, "", false, "Hosts whose TLS certificates are not verified, for self-hosted gateways"),
+	key("URI_REWRITES", TypeList, "", false, "Mirrors for dead metadata and media hosts as from=to, where from is a host, URL prefix or mint address"),
+
+	// Providers and marketplaces
+	key("NFT_PROVIDER", TypeString, "rpc", false, "Enhanced NFT provider: rpc, helius, shyft or quicknode"),
+	key("HELIUS_API_KEY", TypeString, "", true, "Helius API key"),
+	key("SHYFT_API_KEY", TypeString, "", true, "Shyft API key"),
+	key("QUICKNODE_URL", TypeURL, "", true, "QuickNode endpoint with the DAS add-on"),
+	key("MARKET_PRICES", TypeString, "", false, "Marketplace prices recorded with backups; off disables them"),
+	key("TENSOR_API_KEY", TypeString, "", true, "Tensor API key for prices"),
+
+	// Publishing
+	key("PUBLISH_ENDPOINT", TypeURL, "", false, "Proof page publishing endpoint"),
+	key("PUBLISH_API_KEY", TypeString, "", true, "Proof page publishing key"),
+	key("REGISTRY_ENDPOINT", TypeURL, "", false, "Public provenance registry"),
+	key("REGISTRY_API_KEY", TypeString, "", true, "Provenance registry key"),
+	key("TSA_URL", TypeURL, "https://freetsa.org/tsr", false, "RFC 3161 timestamp authority for 'proof anchor'"),
+	key("TSA_CA_FILE", TypeString, "", false, "PEM roots trusted for proof timestamps instead of the system pool"),
+	key("IPFS_API_URL", TypeURL, "http://127.0.0.1:5001", false, "IPFS HTTP API for 'restore --upload ipfs'"),
+	key("IPFS_API_TOKEN", TypeString, "", true, "Bearer token for the IPFS API"),
+
+	// Server
+	key("SERVE_TOKENS", TypeList, "", true, "Static API tokens 'serve' accepts, as name:role:secret with role viewer or operator"),
+	key("SERVE_JWT_SECRET", TypeString, "", true, "HMAC secret, 32 characters or more, of the HS256 JWTs 'serve' accepts"),
+
+	// Tracing
+	key("OTEL_EXPORTER_OTLP_ENDPOINT", TypeURL, "", false, "OTLP/HTTP collector traces are sent to, e.g. http://localhost:4318; unset turns tracing off"),
+	key("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", TypeURL, "", false, "Full OTLP/HTTP traces URL, used instead of OTEL_EXPORTER_OTLP_ENDPOINT"),
+	key("OTEL_EXPORTER_OTLP_HEADERS", TypeList, "", true, "Headers sent with each trace export as key=value, e.g. an API key"),
+	key("OTEL_SERVICE_NAME", TypeString, "solvault", false, "Service name traces are reported under"),
+
+	// Mirrors
+	key("GOOGLE_DRIVE_TOKEN", TypeString, "", true, "Google Drive access token"),
+	key("GOOGLE_DRIVE_REFRESH_TOKEN", TypeString, "", true, "Google Drive refresh token"),
+	key("GOOGLE_DRIVE_CLIENT_ID", TypeString, "", false, "Google Drive OAuth client ID"),
+	key("GOOGLE_DRIVE_CLIENT_SECRET", TypeString, "", true, "Google Drive OAuth client secret"),
+	key("GOOGLE_DRIVE_FOLDER_ID", TypeString, "", false, "Google Drive folder for the mirror"),
+	key("DROPBOX_TOKEN", TypeString, "", true, "Dropbox access token"),
+	key("DROPBOX_REFRESH_TOKEN", TypeString, "", true, "Dropbox refresh token"),
+	key("DROPBOX_APP_KEY", TypeString, "", false, "Dropbox app key"),
+	key("DROPBOX_APP_SECRET", TypeString, "", true, "Dropbox app secret"),
+	key("DROPBOX_PATH", TypeString, "", false, "Dropbox folder for the mirror"),
+
+	// Pull
+	key("SOLVAULT_PULL_SOURCE", TypeString, "", false, "Vault 'pull' copies from when no source is given: a 'serve' URL, ssh://host/path or a directory"),
+	key("SOLVAULT_PULL_TOKEN", TypeString, "", true, "Bearer token 'pull' sends to a 'serve' URL, e.g. a viewer token"),
+
+	// SFTP replicas
+	key("SFTP_KNOWN_HOSTS", TypeString, "~/.ssh/known_hosts", false, "known_hosts file the sftp backend checks server host keys against"),
+	key("SFTP_HOST_KEY", TypeList, "", false, "Host key fingerprints (SHA256:...) the sftp backend accepts instead of known_hosts"),
+	key("SFTP_KEY_FILE", TypeString, "", false, "SSH private key for the sftp backend; unset tries ssh-agent and ~/.ssh/id_*"),
+	key("SFTP_KEY_PASSPHRASE", TypeString, "", true, "Passphrase of SFTP_KEY_FILE"),
+	key("SFTP_PASSWORD", TypeString, "", true, "Password for sftp servers without key login"),
+
+	// Alerts
+	key("SMTP_HOST", TypeString, "", false, "SMTP server for email alerts"),
+	key("SMTP_PORT", TypeInt, "587", false, "SMTP port"),
+	key("SMTP_TLS", TypeString, "starttls", false, "SMTP security: starttls, tls or none"),
+	key("SMTP_INSECURE_SKIP_VERIFY", TypeBool, "false", false, "Accept any SMTP certificate"),
+	key("SMTP_USERNAME", TypeString, "", false, "SMTP user"),
+	key("SMTP_PASSWORD", TypeString, "", true, "SMTP password"),
+	key("SMTP_FROM", TypeString, "", false, "Sender address"),
+	key("SMTP_TO", TypeList, "", false, "Recipients"),
+	key("SMTP_SUBJECT_TEMPLATE", TypeString, "", false, "Subject template file"),
+	key("SMTP_BODY_TEMPLATE", TypeString, "", false, "Body template file"),
+	key("NOTIFY_OFFLINE_MINUTES", TypeInt, "10", false, "Alert when the watcher is offline this long"),
+	key("DRAIN_ALERT_COUNT", TypeInt, "3", false, "Critical alert when more NFTs than this leave a wallet within DRAIN_ALERT_MINUTES; 0 disables"),
+	key("DRAIN_ALERT_MINUTES", TypeInt, "10", false, "Window for DRAIN_ALERT_COUNT"),
+	key("DRAIN_SNAPSHOT", TypeBool, "false", false, "On a drain alert, re-back up and list everything the wallet still holds"),
+}
+
+// Lookup finds a key by config file or environment variable name, in any
+// case. SOLANA_RPC_URL_2, _3 and so on name fallback RPC endpoints, tried in
+// numeric order after SOLANA_RPC_URL.
+func Lookup(name string) (Key, bool) {
+	env := strings.ToUpper(strings.TrimSpace(name))
+	for _, k := range Schema {
+		if k.Env == env {
+			return k, true
+		}
+	}
+	if isNumberedRPC(env) {
+		return key(env, TypeURL, "", false, fmt.Sprintf("Fallback RPC endpoint %d", rpcIndex(env))), true
+	}
+	return Key{}, false
+}
+
+const numberedRPCPrefix = "SOLANA_RPC_URL_"
+
+func isNumberedRPC(env string) bool {
+	return rpcIndex(env) > 0
+}
+
+func rpcIndex(env string) int {
+	if !strings.HasPrefix(env, numberedRPCPrefix) {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(env, numberedRPCPrefix))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Validate checks that value suits the key's type; "" is always valid
+func (k Key) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+	switch k.Type {
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", k.Name, value)
+		}
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", k.Name, value)
+		}
+	case TypeURL:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%s must be a URL, got %q", k.Name, value)
+		}
+	}
+	return nil
+}
+
+// Redact hides a secret value, keeping enough to tell keys apart
+func (k Key) Redact(value string) string {
+	if !k.Secret || value == "" {
+		return value
+	}
+	if len(value) <= 8 {
+		return "********"
+	}
+	return "********" + value[len(value)-4:]
+}
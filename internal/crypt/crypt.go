@@ -0,0 +1,255 @@
+// Package crypt encrypts vault files at rest with AES-256-GCM. The key comes
+// from a passphrase (stretched with scrypt) or a 32-byte key file, so a vault
+// kept on a shared drive or cloud folder reveals nothing but its layout.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation methods recorded in Params.KDF
+const (
+	KDFScrypt  = "scrypt"  // Key stretched from a passphrase
+	KDFKeyFile = "keyfile" // Key read verbatim from a file
+)
+
+// Algorithm is the only cipher this build writes
+const Algorithm = "aes-256-gcm"
+
+// KeySize is the AES-256 key length in bytes
+const KeySize = 32
+
+// magic prefixes every sealed file so sealed and plain files can share a
+// vault while it is being converted
+var magic = []byte("SOLVAULT-ENC\x01")
+
+// checkPlaintext is sealed into Params.Check to recognise the right key
+var checkPlaintext = []byte("solvault key check")
+
+// Environment variables that unlock an encrypted vault
+const (
+	EnvPassphrase = "SOLVAULT_PASSPHRASE"
+	EnvKeyFile    = "SOLVAULT_KEY_FILE"
+)
+
+var (
+	// ErrLocked means the vault is encrypted and no key was configured
+	ErrLocked = fmt.Errorf("vault is encrypted; set %s or %s to unlock it", EnvPassphrase, EnvKeyFile)
+
+	// ErrWrongKey means the configured passphrase or key file does not
+	// match the one the vault was encrypted with
+	ErrWrongKey = errors.New("wrong passphrase or key file for this vault")
+)
+
+// Params describes how a vault's key is derived. It is stored in the vault
+// header; none of it is secret.
+type Params struct {
+	Algorithm string `json:"algorithm"`
+	KDF       string `json:"kdf"`
+	Salt      []byte `json:"salt,omitempty"` // scrypt only
+	N         int    `json:"n,omitempty"`
+	R         int    `json:"r,omitempty"`
+	P         int    `json:"p,omitempty"`
+	Check     []byte `json:"check"` // A known value sealed with the key
+}
+
+// Cipher seals and opens vault files with one key
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates a cipher from a 32-byte key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal encrypts plaintext as magic || nonce || ciphertext
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	out := make([]byte, 0, len(magic)+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, magic...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, magic), nil
+}
+
+// Open decrypts data written by Seal
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, errors.New("data is not encrypted")
+	}
+	body := data[len(magic):]
+	if len(body) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+	nonce, ciphertext := body[:c.aead.NonceSize()], body[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: data is corrupt or the key is wrong")
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether data was written by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// MagicSize is how many leading bytes IsSealed needs to see
+func MagicSize() int {
+	return len(magic)
+}
+
+// FromPassphrase sets up encryption for a new vault from a passphrase
+func FromPassphrase(passphrase string) (*Params, *Cipher, error) {
+	if passphrase == "" {
+		return nil, nil, errors.New("passphrase must not be empty")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	// Explanation: N=2^15 costs about 100ms and 32MB per unlock, which is
+	// once per command, and makes guessing a weak passphrase expensive
+	params := &Params{Algorithm: Algorithm, KDF: KDFScrypt, Salt: salt, N: 1 << 15, R: 8, P: 1}
+	return params.seal(passphrase, "")
+}
+
+// FromKeyFile sets up encryption for a new vault from a key file
+func FromKeyFile(path string) (*Params, *Cipher, error) {
+	params := &Params{Algorithm: Algorithm, KDF: KDFKeyFile}
+	return params.seal("", path)
+}
+
+// seal derives the cipher and records the key check value
+func (p *Params) seal(passphrase, keyFile string) (*Params, *Cipher, error) {
+	c, err := p.cipher(passphrase, keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.Check, err = c.Seal(checkPlaintext); err != nil {
+		return nil, nil, err
+	}
+	return p, c, nil
+}
+
+// Unlock derives the cipher for an existing vault and checks that the key
+// is the one it was encrypted with
+func (p *Params) Unlock(passphrase, keyFile string) (*Cipher, error) {
+	c, err := p.cipher(passphrase, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if plaintext, err := c.Open(p.Check); err != nil || !bytes.Equal(plaintext, checkPlaintext) {
+		return nil, ErrWrongKey
+	}
+	return c, nil
+}
+
+// FromEnv unlocks a vault with SOLVAULT_KEY_FILE or SOLVAULT_PASSPHRASE,
+// returning ErrLocked when the one the vault needs is not set
+func (p *Params) FromEnv() (*Cipher, error) {
+	switch p.KDF {
+	case KDFKeyFile:
+		if os.Getenv(EnvKeyFile) == "" {
+			return nil, ErrLocked
+		}
+	default:
+		if os.Getenv(EnvPassphrase) == "" {
+			return nil, ErrLocked
+		}
+	}
+	return p.Unlock(os.Getenv(EnvPassphrase), os.Getenv(EnvKeyFile))
+}
+
+// cipher derives the key described by p
+func (p *Params) cipher(passphrase, keyFile string) (*Cipher, error) {
+	if p.Algorithm != Algorithm {
+		return nil, fmt.Errorf("unsupported encryption algorithm %q", p.Algorithm)
+	}
+
+	switch p.KDF {
+	case KDFScrypt:
+		if passphrase == "" {
+			return nil, ErrLocked
+		}
+		key, err := scrypt.Key([]byte(passphrase), p.Salt, p.N, p.R, p.P, KeySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		return New(key)
+	case KDFKeyFile:
+		if keyFile == "" {
+			return nil, ErrLocked
+		}
+		key, err := ReadKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return New(key)
+	default:
+		return nil, fmt.Errorf("unsupported key derivation %q", p.KDF)
+	}
+}
+
+// ReadKeyFile loads a 32-byte key stored raw, as hex or as base64
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if len(data) == KeySize {
+		return data, nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key file %s must hold a %d-byte key (raw, hex or base64)", path, KeySize)
+}
+
+// GenerateKeyFile writes a new random key as hex, readable only by the owner
+func GenerateKeyFile(path string) error {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCipher_SealOpen(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+
+	plaintext := []byte(`{"name":"Cool Cat #1234"}`)
+	sealed, err := c.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	if !IsSealed(sealed) || IsSealed(plaintext) {
+		t.Fatalf("IsSealed does not tell sealed from plain data")
+	}
+	if bytes.Contains(sealed, []byte("Cool Cat")) {
+		t.Errorf("Sealed data leaks the plaintext")
+	}
+
+	opened, err := c.Open(sealed)
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Got %q, want %q", opened, plaintext)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.Open(sealed); err == nil {
+		t.Errorf("Expected tampered data to fail to open")
+	}
+}
+
+func TestParams_Unlock(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "vault.key")
+	if err := GenerateKeyFile(keyFile); err != nil {
+		t.Fatalf("Failed to generate key file: %v", err)
+	}
+	otherKey := filepath.Join(t.TempDir(), "other.key")
+	if err := GenerateKeyFile(otherKey); err != nil {
+		t.Fatalf("Failed to generate key file: %v", err)
+	}
+	if err := GenerateKeyFile(keyFile); err == nil {
+		t.Errorf("Expected GenerateKeyFile to refuse to overwrite a key")
+	}
+
+	passParams, _, err := FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up passphrase: %v", err)
+	}
+	fileParams, _, err := FromKeyFile(keyFile)
+	if err != nil {
+		t.Fatalf("Failed to set up key file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		params     *Params
+		passphrase string
+		keyFile    string
+		wantErr    error
+	}{
+		{"right passphrase", passParams, "correct horse", "", nil},
+		{"wrong passphrase", passParams, "battery staple", "", ErrWrongKey},
+		{"no passphrase", passParams, "", keyFile, ErrLocked},
+		{"right key file", fileParams, "", keyFile, nil},
+		{"wrong key file", fileParams, "", otherKey, ErrWrongKey},
+		{"no key file", fileParams, "correct horse", "", ErrLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.params.Unlock(tt.passphrase, tt.keyFile)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || c == nil {
+				t.Fatalf("Failed to unlock: %v", err)
+			}
+		})
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0xab}, KeySize)
+
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{"raw", key, false},
+		{"hex", []byte("abababababababababababababababababababababababababababababababab\n"), false},
+		{"base64", []byte("q6urq6urq6urq6urq6urq6urq6urq6urq6urq6urq6s="), false},
+		{"too short", []byte("abab"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.content, 0600); err != nil {
+				t.Fatalf("Failed to write key file: %v", err)
+			}
+			got, err := ReadKeyFile(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, key) {
+				t.Fatalf("Got %x (%v), want %x", got, err, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// childEnv marks a process started by Detach so it does not detach again
+const childEnv = "SOLVAULT_DAEMON_CHILD"
+
+// Paths locates the files a daemon uses to coordinate with the CLI
+type Paths struct {
+	PIDFile string
+	LogFile string
+}
+
+// DefaultPaths returns ~/.solvault/<name>.pid and ~/.solvault/logs/<name>.log
+func DefaultPaths(name string) (Paths, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Paths{}, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	stateDir := filepath.Join(homeDir, ".solvault")
+	return Paths{
+		PIDFile: filepath.Join(stateDir, name+".pid"),
+		LogFile: filepath.Join(stateDir, "logs", name+".log"),
+	}, nil
+}
+
+// IsChild reports whether the current process is a detached daemon
+func IsChild() bool {
+	return os.Getenv(childEnv) == "1"
+}
+
+// Status describes a daemon as seen through its PID file
+type Status struct {
+	Running   bool
+	PID       int
+	StartedAt time.Time // PID file modification time
+	Paths     Paths
+}
+
+// Detach starts the current executable again in the background with args,
+// its output appended to the log file, and returns the child's PID.
+// Explanation: Go cannot safely fork(), so we re-exec ourselves in a new
+// session instead; the child writes its own PID file once it is running.
+func Detach(paths Paths, args []string) (int, error) {
+	if status, err := ReadStatus(paths); err == nil && status.Running {
+		return 0, fmt.Errorf("already running with PID %d (see %s)", status.PID, paths.PIDFile)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(paths.LogFile), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(paths.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	child := exec.Command(executable, args...)
+	child.Env = append(os.Environ(), childEnv+"=1")
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.Stdin = nil
+	child.SysProcAttr = detachAttrs()
+
+	if dir, err := os.Getwd(); err == nil {
+		child.Dir = dir // Keep resolving .env relative to where the user started us
+	}
+
+	if err := child.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start background process: %w", err)
+	}
+	pid := child.Process.Pid
+
+	// Let the child go; we never wait on it
+	if err := child.Process.Release(); err != nil {
+		return 0, fmt.Errorf("failed to release background process: %w", err)
+	}
+
+	return pid, nil
+}
+
+// WritePIDFile records the current process ID
+func WritePIDFile(paths Paths) error {
+	if err := os.MkdirAll(filepath.Dir(paths.PIDFile), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(paths.PIDFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// RemovePIDFile deletes the PID file if it still belongs to this process
+func RemovePIDFile(paths Paths) {
+	if pid, err := readPID(paths.PIDFile); err == nil && pid == os.Getpid() {
+		os.Remove(paths.PIDFile)
+	}
+}
+
+// ReadStatus reports whether the daemon recorded in the PID file is alive
+func ReadStatus(paths Paths) (*Status, error) {
+	status := &Status{Paths: paths}
+
+	pid, err := readPID(paths.PIDFile)
+	if os.IsNotExist(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.PID = pid
+	status.Running = processAlive(pid)
+	if stat, err := os.Stat(paths.PIDFile); err == nil {
+		status.StartedAt = stat.ModTime()
+	}
+
+	return status, nil
+}
+
+// Stop asks the daemon to shut down and waits up to timeout for it to exit
+func Stop(paths Paths, timeout time.Duration) (int, error) {
+	status, err := ReadStatus(paths)
+	if err != nil {
+		return 0, err
+	}
+	if !status.Running {
+		if status.PID != 0 {
+			os.Remove(paths.PIDFile) // Stale PID file from a crashed daemon
+		}
+		return 0, fmt.Errorf("not running")
+	}
+
+	if err := terminate(status.PID); err != nil {
+		return status.PID, fmt.Errorf("failed to signal PID %d: %w", status.PID, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(status.PID) {
+			os.Remove(paths.PIDFile)
+			return status.PID, nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return status.PID, fmt.Errorf("PID %d did not exit within %s", status.PID, timeout)
+}
+
+func readPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file %s", path)
+	}
+	return pid, nil
+}
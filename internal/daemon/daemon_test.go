@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPIDFileStatus(t *testing.T) {
+	tempDir := t.TempDir()
+	paths := Paths{
+		PIDFile: filepath.Join(tempDir, "watch.pid"),
+		LogFile: filepath.Join(tempDir, "logs", "watch.log"),
+	}
+
+	status, err := ReadStatus(paths)
+	if err != nil {
+		t.Fatalf("Failed to read status without PID file: %v", err)
+	}
+	if status.Running {
+		t.Errorf("Expected not running before PID file exists")
+	}
+
+	if err := WritePIDFile(paths); err != nil {
+		t.Fatalf("Failed to write PID file: %v", err)
+	}
+
+	status, err = ReadStatus(paths)
+	if err != nil {
+		t.Fatalf("Failed to read status: %v", err)
+	}
+	if !status.Running || status.PID != os.Getpid() {
+		t.Errorf("Expected running with PID %d, got %+v", os.Getpid(), status)
+	}
+
+	RemovePIDFile(paths)
+	if _, err := os.Stat(paths.PIDFile); !os.IsNotExist(err) {
+		t.Errorf("Expected PID file to be removed, got %v", err)
+	}
+}
+
+func TestStop_NotRunning(t *testing.T) {
+	paths := Paths{PIDFile: filepath.Join(t.TempDir(), "watch.pid")}
+
+	if _, err := Stop(paths, 0); err == nil {
+		t.Errorf("Expected error stopping a daemon that is not running")
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"syscall"
+)
+
+// detachAttrs starts the child in its own session so it survives the terminal closing
+func detachAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive checks for a process using the null signal
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// terminate requests a graceful shutdown
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
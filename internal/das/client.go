@@ -0,0 +1,268 @@
+package das
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxPageSize is the largest page DAS providers accept for getAssetsByOwner
+const MaxPageSize = 1000
+
+// Asset is the subset of a DAS asset that SolVault uses
+type Asset struct {
+	ID        string `json:"id"` // The mint address
+	Interface string `json:"interface"`
+	Burnt     bool   `json:"burnt"`
+	Content   struct {
+		JSONURI  string `json:"json_uri"`
+		Metadata struct {
+			Name        string `json:"name"`
+			Symbol      string `json:"symbol"`
+			Description string `json:"description"`
+		} `json:"metadata"`
+		Files []struct {
+			URI  string `json:"uri"`
+			Mime string `json:"mime"`
+		} `json:"files"`
+		Links struct {
+			Image string `json:"image"`
+		} `json:"links"`
+	} `json:"content"`
+	Compression struct {
+		Compressed bool `json:"compressed"`
+	} `json:"compression"`
+	Ownership struct {
+		Owner string `json:"owner"`
+	} `json:"ownership"`
+}
+
+// Page is one page of getAssetsByOwner results
+type Page struct {
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Cursor string  `json:"cursor"` // Empty when the provider has no more pages
+	Items  []Asset `json:"items"`
+}
+
+// Position is where a listing's next page starts: a cursor from the
+// previous page, or a page number for providers that return no cursors.
+// The zero Position asks for the first page and starts a cursor chain.
+type Position struct {
+	Cursor string `json:"cursor,omitempty"`
+	Page   int    `json:"page,omitempty"`
+}
+
+// Next returns where the page after page starts, given the position page
+// was fetched at, the limit it was fetched with, and how many pages have
+// been fetched so far including it. It returns false once the listing is
+// exhausted.
+//
+// Explanation: Some providers answer a request without page or cursor in
+// page mode and return no cursor. A full page without a cursor is then not
+// the end: the listing continues by page number, which lines up with the
+// pages already fetched because every request sorts by id with the same
+// limit.
+func Next(at Position, page *Page, limit, fetched int) (Position, bool) {
+	if len(page.Items) == 0 {
+		return Position{}, false
+	}
+	if page.Cursor != "" && at.Page == 0 {
+		return Position{Cursor: page.Cursor}, true
+	}
+	if len(page.Items) < limit {
+		return Position{}, false
+	}
+	return Position{Page: fetched + 1}, true
+}
+
+// RateLimitError is returned when the provider keeps answering 429
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("DAS provider rate limit exceeded (retry after %s)", e.RetryAfter)
+}
+
+// Client calls the Digital Asset Standard (DAS) read API
+type Client struct {
+	endpoint    string
+	httpClient  *http.Client
+	minInterval time.Duration // Minimum spacing between requests
+	maxRetries  int
+	lastRequest time.Time
+
+	// sleep is swapped out in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewClient creates a DAS client that sends at most requestsPerSecond requests
+// (0 disables client-side limiting)
+func NewClient(endpoint string, requestsPerSecond float64) *Client {
+	c := &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		maxRetries: 5,
+		sleep:      sleepContext,
+	}
+	if requestsPerSecond > 0 {
+		c.minInterval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return c
+}
+
+// GetAssetsByOwner fetches one page of assets, starting at the given
+// position
+// Explanation: Cursor pagination is stable while the wallet changes, unlike
+// page numbers, and is what makes a scan resumable across runs. Page
+// numbers are only used for providers that return no cursor (see Next).
+func (c *Client) GetAssetsByOwner(ctx context.Context, owner string, at Position, limit int) (*Page, error) {
+	params := map[string]interface{}{
+		"ownerAddress": owner,
+		"limit":        PageLimit(limit),
+		"sortBy":       map[string]string{"sortBy": "id", "sortDirection": "asc"},
+	}
+	at.apply(params)
+
+	var page Page
+	if err := c.call(ctx, "getAssetsByOwner", params, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetAssetsByGroup fetches one page of the assets in a group, such as the
+// NFTs of a verified collection (groupKey "collection"), paginated like
+// GetAssetsByOwner
+func (c *Client) GetAssetsByGroup(ctx context.Context, groupKey, groupValue string, at Position, limit int) (*Page, error) {
+	params := map[string]interface{}{
+		"groupKey":   groupKey,
+		"groupValue": groupValue,
+		"limit":      PageLimit(limit),
+		"sortBy":     map[string]string{"sortBy": "id", "sortDirection": "asc"},
+	}
+	at.apply(params)
+
+	var page Page
+	if err := c.call(ctx, "getAssetsByGroup", params, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// PageLimit returns the page size actually requested for limit
+func PageLimit(limit int) int {
+	if limit <= 0 || limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
+
+// apply adds the position to request params; the first page sends neither
+// a cursor nor a page, so cursor-capable providers start a cursor chain
+func (at Position) apply(params map[string]interface{}) {
+	switch {
+	case at.Cursor != "":
+		params["cursor"] = at.Cursor
+	case at.Page > 0:
+		params["page"] = at.Page
+	}
+}
+
+// call performs one JSON-RPC request, spacing requests and backing off on 429
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "solvault",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		if wait := c.minInterval - time.Since(c.lastRequest); wait > 0 {
+			if err := c.sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+		c.lastRequest = time.Now()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("DAS request failed: %w", err)
+		}
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait := retryAfter(resp.Header.Get("Retry-After"), backoff)
+			if attempt >= c.maxRetries {
+				return &RateLimitError{RetryAfter: wait}
+			}
+			if err := c.sleep(ctx, wait); err != nil {
+				return err
+			}
+			backoff *= 2
+			continue
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read DAS response: %w", readErr)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("DAS provider returned HTTP %d", resp.StatusCode)
+		}
+
+		var envelope struct {
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &envelope); err != nil {
+			return fmt.Errorf("failed to parse DAS response: %w", err)
+		}
+		if envelope.Error != nil {
+			return fmt.Errorf("DAS error %d: %s", envelope.Error.Code, envelope.Error.Message)
+		}
+		if err := json.Unmarshal(envelope.Result, result); err != nil {
+			return fmt.Errorf("failed to parse DAS result: %w", err)
+		}
+		return nil
+	}
+}
+
+// retryAfter parses a Retry-After header in seconds, falling back to the current backoff
+func retryAfter(header string, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,253 @@
+package das
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProvider serves total assets in pages, answering 429 whenever limited
+// reports true for the 1-based call number
+func fakeProvider(t *testing.T, total int, limited func(call int32) bool) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if limited != nil && limited(n) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		var req struct {
+			Params struct {
+				Limit  int    `json:"limit"`
+				Cursor string `json:"cursor"`
+				Page   *int   `json:"page"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Params.Page != nil {
+			t.Errorf("Cursor provider was sent page %d; a page number switches providers to page mode", *req.Params.Page)
+		}
+
+		start := 0
+		if req.Params.Cursor != "" {
+			fmt.Sscanf(req.Params.Cursor, "c%d", &start)
+		}
+
+		page := Page{Total: total, Limit: req.Params.Limit}
+		for i := start; i < start+req.Params.Limit && i < total; i++ {
+			page.Items = append(page.Items, Asset{ID: fmt.Sprintf("mint-%05d", i)})
+		}
+		if next := start + req.Params.Limit; next < total {
+			page.Cursor = fmt.Sprintf("c%d", next)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": "solvault", "result": page})
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+// fakePageProvider serves total assets the way some providers answer in
+// page mode: by page number (page 1 when none is given), never with a cursor
+func fakePageProvider(t *testing.T, total int) *httptest.Server {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params struct {
+				Limit int `json:"limit"`
+				Page  int `json:"page"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Params.Page == 0 {
+			req.Params.Page = 1
+		}
+
+		page := Page{Total: total, Limit: req.Params.Limit}
+		start := (req.Params.Page - 1) * req.Params.Limit
+		for i := start; i < start+req.Params.Limit && i < total; i++ {
+			page.Items = append(page.Items, Asset{ID: fmt.Sprintf("mint-%05d", i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": "solvault", "result": page})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestClient(url string) *Client {
+	client := NewClient(url, 0)
+	client.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return client
+}
+
+func TestEnumerate_ResumesAcrossRuns(t *testing.T) {
+	ts, _ := fakeProvider(t, 25, nil)
+	enum := NewEnumerator(newTestClient(ts.URL), t.TempDir())
+	ctx := context.Background()
+
+	// First run stops after two pages of ten
+	result, err := enum.Enumerate(ctx, "owner", Options{PageSize: 10, MaxPages: 2})
+	if err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if result.Checkpoint.Complete || len(result.Assets) != 20 || result.Checkpoint.Cursor != "c20" {
+		t.Fatalf("Expected a paused scan at 20 assets, got %+v (%d assets)", result.Checkpoint, len(result.Assets))
+	}
+
+	// Second run picks up from the cursor instead of starting over
+	result, err = enum.Enumerate(ctx, "owner", Options{PageSize: 10})
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if !result.Checkpoint.Complete || len(result.Assets) != 25 || result.Checkpoint.Pages != 3 {
+		t.Fatalf("Expected a complete scan of 25 assets in 3 pages, got %+v (%d assets)", result.Checkpoint, len(result.Assets))
+	}
+
+	// A completed scan starts fresh next time
+	result, err = enum.Enumerate(ctx, "owner", Options{PageSize: 10, MaxPages: 1})
+	if err != nil {
+		t.Fatalf("Third run failed: %v", err)
+	}
+	if len(result.Assets) != 10 || result.Checkpoint.Pages != 1 {
+		t.Fatalf("Expected a restarted scan, got %+v (%d assets)", result.Checkpoint, len(result.Assets))
+	}
+}
+
+func TestEnumerate_PageModeProvider(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		pages int // Requests made, including the empty page that ends an exact multiple
+	}{
+		{"partial last page", 25, 3},
+		{"exact multiple", 20, 3},
+		{"one short page", 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := fakePageProvider(t, tt.total)
+			enum := NewEnumerator(newTestClient(ts.URL), t.TempDir())
+			ctx := context.Background()
+
+			// A full page without a cursor is not the end of the listing
+			result, err := enum.Enumerate(ctx, "owner", Options{PageSize: 10, MaxPages: 1})
+			if err != nil {
+				t.Fatalf("First run failed: %v", err)
+			}
+			if tt.total > 10 && (result.Checkpoint.Complete || result.Checkpoint.Page != 2) {
+				t.Fatalf("Expected a paused scan resuming at page 2, got %+v", result.Checkpoint)
+			}
+
+			result, err = enum.Enumerate(ctx, "owner", Options{PageSize: 10})
+			if err != nil {
+				t.Fatalf("Second run failed: %v", err)
+			}
+			if !result.Checkpoint.Complete || len(result.Assets) != tt.total || result.Checkpoint.Pages != tt.pages {
+				t.Fatalf("Expected a complete scan of %d assets in %d pages, got %+v (%d assets)", tt.total, tt.pages, result.Checkpoint, len(result.Assets))
+			}
+			seen := make(map[string]bool)
+			for _, asset := range result.Assets {
+				seen[asset.ID] = true
+			}
+			if len(seen) != tt.total {
+				t.Errorf("Got %d distinct assets, want %d", len(seen), tt.total)
+			}
+		})
+	}
+}
+
+func TestEnumerate_RateLimitPausesScan(t *testing.T) {
+	// Let two pages through, then answer 429 for everything
+	ts, _ := fakeProvider(t, 50, func(call int32) bool { return call > 2 })
+	client := newTestClient(ts.URL)
+	client.maxRetries = 1
+	enum := NewEnumerator(client, t.TempDir())
+
+	result, err := enum.Enumerate(context.Background(), "owner", Options{PageSize: 10})
+	if err != nil {
+		t.Fatalf("Rate limiting should pause, not fail: %v", err)
+	}
+	if result.Checkpoint.Complete || len(result.Assets) != 20 {
+		t.Fatalf("Expected a paused scan at 20 assets, got %+v (%d assets)", result.Checkpoint, len(result.Assets))
+	}
+}
+
+func TestCollectionEnumerator(t *testing.T) {
+	var method, groupKey, groupValue string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params struct {
+				GroupKey   string `json:"groupKey"`
+				GroupValue string `json:"groupValue"`
+			} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		method, groupKey, groupValue = req.Method, req.Params.GroupKey, req.Params.GroupValue
+
+		page := Page{Total: 2, Items: []Asset{{ID: "mint-1"}, {ID: "mint-2"}}}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": "solvault", "result": page})
+	}))
+	t.Cleanup(ts.Close)
+
+	enum := NewCollectionEnumerator(newTestClient(ts.URL), t.TempDir())
+	result, err := enum.Enumerate(context.Background(), "collection-mint", Options{PageSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to enumerate collection: %v", err)
+	}
+	if method != "getAssetsByGroup" || groupKey != "collection" || groupValue != "collection-mint" {
+		t.Errorf("Expected getAssetsByGroup for the collection, got %s(%s=%s)", method, groupKey, groupValue)
+	}
+	if !result.Checkpoint.Complete || len(result.Assets) != 2 {
+		t.Errorf("Expected a complete scan of 2 assets, got %+v (%d assets)", result.Checkpoint, len(result.Assets))
+	}
+}
+
+func TestClient_RetriesOn429(t *testing.T) {
+	ts, calls := fakeProvider(t, 5, func(call int32) bool { return call%2 == 0 })
+	client := newTestClient(ts.URL)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetAssetsByOwner(context.Background(), "owner", Position{}, 10); err != nil {
+			t.Fatalf("Expected retry to succeed, got %v", err)
+		}
+	}
+	if atomic.LoadInt32(calls) < 4 {
+		t.Errorf("Expected rate-limited calls to be retried, got %d calls", atomic.LoadInt32(calls))
+	}
+}
+
+func TestLoadAssets_TornLine(t *testing.T) {
+	dir := t.TempDir()
+	enum := NewEnumerator(nil, dir)
+
+	// Simulate a crash mid-write
+	if err := os.WriteFile(enum.assetsPath("owner"), []byte("{\"id\":\"a\"}\n{\"id\":"), 0644); err != nil {
+		t.Fatalf("Failed to write asset log: %v", err)
+	}
+	file, err := openAssetLog(enum.assetsPath("owner"))
+	if err != nil {
+		t.Fatalf("Failed to open asset log: %v", err)
+	}
+	json.NewEncoder(file).Encode(Asset{ID: "b"})
+	file.Close()
+
+	assets, err := enum.loadAssets("owner")
+	if err != nil {
+		t.Fatalf("Failed to load assets: %v", err)
+	}
+	if len(assets) != 2 || assets[0].ID != "a" || assets[1].ID != "b" {
+		t.Errorf("Expected assets a and b, got %+v", assets)
+	}
+}
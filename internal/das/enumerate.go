@@ -0,0 +1,262 @@
+package das
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint records how far an enumeration of one wallet has progressed
+type Checkpoint struct {
+	Owner     string    `json:"owner"`               // The wallet, or the collection of a collection enumerator
+	Cursor    string    `json:"cursor"`              // Cursor for the next page; empty before the first page
+	Page      int       `json:"page,omitempty"`      // Next page number, for providers that return no cursor
+	PageSize  int       `json:"page_size,omitempty"` // Assets per page, fixed for the whole scan
+	Pages     int       `json:"pages"`
+	Assets    int       `json:"assets"`
+	Total     int       `json:"total,omitempty"` // Provider-reported total, when given
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Complete  bool      `json:"complete"`
+}
+
+// Options controls one enumeration run
+type Options struct {
+	PageSize int // Assets per page (max 1000)
+	MaxPages int // Stop after this many pages in this run; 0 means no limit
+	Progress func(cp Checkpoint)
+}
+
+// Result is what an enumeration run produced
+type Result struct {
+	Assets     []Asset    // Every asset enumerated so far, including earlier runs
+	Checkpoint Checkpoint // Where the next run resumes
+}
+
+// Enumerator walks getAssetsByOwner page by page, persisting its position
+// after every page so a scan of a very large wallet can span several runs
+//
+// Explanation: For each owner two files live in the state directory: a small
+// JSON checkpoint with the cursor, and an append-only JSON-lines file with the
+// assets seen so far. Appending keeps each page write O(page) instead of
+// rewriting a 50k-entry file every time.
+type Enumerator struct {
+	stateDir string
+	list     func(ctx context.Context, key string, at Position, limit int) (*Page, error)
+}
+
+// NewEnumerator creates an enumerator that keeps its checkpoints in stateDir
+func NewEnumerator(client *Client, stateDir string) *Enumerator {
+	return &Enumerator{stateDir: stateDir, list: client.GetAssetsByOwner}
+}
+
+// NewCollectionEnumerator creates an enumerator of the NFTs in verified
+// collections, keyed by collection mint rather than owner
+func NewCollectionEnumerator(client *Client, stateDir string) *Enumerator {
+	list := func(ctx context.Context, collection string, at Position, limit int) (*Page, error) {
+		return client.GetAssetsByGroup(ctx, "collection", collection, at, limit)
+	}
+	return &Enumerator{stateDir: stateDir, list: list}
+}
+
+// Enumerate continues (or starts) the scan for owner. The returned result is
+// complete only when Checkpoint.Complete is true; otherwise call again later.
+func (e *Enumerator) Enumerate(ctx context.Context, owner string, opts Options) (*Result, error) {
+	if err := os.MkdirAll(e.stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	cp, err := e.loadCheckpoint(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	// A finished scan is the starting point of a fresh one
+	if cp.Complete {
+		if err := e.Reset(owner); err != nil {
+			return nil, err
+		}
+		cp = Checkpoint{Owner: owner}
+	}
+	if cp.StartedAt.IsZero() {
+		cp.StartedAt = time.Now()
+	}
+
+	assetsFile, err := openAssetLog(e.assetsPath(owner))
+	if err != nil {
+		return nil, err
+	}
+	defer assetsFile.Close()
+
+	// Page numbers only line up with the pages already fetched at the same
+	// size, so a scan keeps the size it started with
+	if cp.PageSize == 0 {
+		cp.PageSize = PageLimit(opts.PageSize)
+	}
+
+	var runErr error
+	for pages := 0; opts.MaxPages == 0 || pages < opts.MaxPages; pages++ {
+		at := Position{Cursor: cp.Cursor, Page: cp.Page}
+		page, err := e.list(ctx, owner, at, cp.PageSize)
+		if err != nil {
+			runErr = err
+			break
+		}
+
+		encoder := json.NewEncoder(assetsFile)
+		for _, asset := range page.Items {
+			if err := encoder.Encode(asset); err != nil {
+				return nil, fmt.Errorf("failed to append to asset log: %w", err)
+			}
+		}
+		if err := assetsFile.Sync(); err != nil {
+			return nil, fmt.Errorf("failed to flush asset log: %w", err)
+		}
+
+		cp.Pages++
+		cp.Assets += len(page.Items)
+		if page.Total > cp.Total {
+			cp.Total = page.Total
+		}
+		next, more := Next(at, page, cp.PageSize, cp.Pages)
+		cp.Cursor, cp.Page = next.Cursor, next.Page
+		cp.Complete = !more
+		cp.UpdatedAt = time.Now()
+
+		// The checkpoint is written only after the page's assets are durable
+		if err := e.saveCheckpoint(cp); err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(cp)
+		}
+		if cp.Complete {
+			break
+		}
+	}
+
+	assets, err := e.loadAssets(owner)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Assets: assets, Checkpoint: cp}
+
+	// Running out of pages or hitting the rate limit is a normal pause, not a failure
+	var rateErr *RateLimitError
+	if runErr != nil && !errors.As(runErr, &rateErr) && !errors.Is(runErr, context.Canceled) && !errors.Is(runErr, context.DeadlineExceeded) {
+		return result, runErr
+	}
+	return result, nil
+}
+
+// Status returns the saved checkpoint for owner without contacting the provider
+func (e *Enumerator) Status(owner string) (Checkpoint, error) {
+	return e.loadCheckpoint(owner)
+}
+
+// Reset discards the checkpoint and asset log so the next run starts over
+func (e *Enumerator) Reset(owner string) error {
+	for _, path := range []string{e.checkpointPath(owner), e.assetsPath(owner)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset enumeration: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *Enumerator) loadCheckpoint(owner string) (Checkpoint, error) {
+	cp := Checkpoint{Owner: owner}
+
+	data, err := os.ReadFile(e.checkpointPath(owner))
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func (e *Enumerator) saveCheckpoint(cp Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	path := e.checkpointPath(cp.Owner)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+	return nil
+}
+
+// loadAssets reads the asset log, dropping duplicates a retried page may have appended
+func (e *Enumerator) loadAssets(owner string) ([]Asset, error) {
+	file, err := os.Open(e.assetsPath(owner))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open asset log: %w", err)
+	}
+	defer file.Close()
+
+	var assets []Asset
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var asset Asset
+		if err := json.Unmarshal(scanner.Bytes(), &asset); err != nil {
+			continue // A torn final line from a crash is simply re-fetched
+		}
+		if seen[asset.ID] {
+			continue
+		}
+		seen[asset.ID] = true
+		assets = append(assets, asset)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read asset log: %w", err)
+	}
+	return assets, nil
+}
+
+// openAssetLog opens the asset log for appending, terminating a torn final
+// line left by a crash so the next record starts on a line of its own
+func openAssetLog(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open asset log: %w", err)
+	}
+
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := file.Write([]byte{'\n'}); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to repair asset log: %w", err)
+			}
+		}
+	}
+	return file, nil
+}
+
+func (e *Enumerator) checkpointPath(owner string) string {
+	return filepath.Join(e.stateDir, owner+".checkpoint.json")
+}
+
+func (e *Enumerator) assetsPath(owner string) string {
+	return filepath.Join(e.stateDir, owner+".assets.jsonl")
+}
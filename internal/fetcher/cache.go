@@ -0,0 +1,206 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetadataRate is how many metadata requests per second go to one host
+const DefaultMetadataRate = 5.0
+
+// MetadataCacheOptions controls a MetadataCache
+type MetadataCacheOptions struct {
+	RequestsPerSecond float64 // Per host; 0 disables client-side limiting
+	ReadOnly          bool    // Use cached validators but never write the cache (dry runs)
+}
+
+// MetadataCache remembers the ETag and Last-Modified validators of every
+// off-chain metadata URI, with the body they were served with, so later
+// fetches can be conditional requests. It also spaces requests to each host.
+//
+// Explanation: Metadata on Arweave and IPFS rarely changes, but sync and
+// verify --check-metadata re-read it for every NFT on every run. With the
+// validators cached, an unchanged document costs a 304 with no body. The
+// body has to be kept too, since a 304 says "what you have is current"
+// without repeating it. One file per URI, named by its SHA-256, keeps
+// concurrent writers from clobbering each other's entries.
+type MetadataCache struct {
+	dir         string
+	readOnly    bool
+	minInterval time.Duration
+
+	mu          sync.Mutex
+	nextRequest map[string]time.Time // Earliest time of the next request per host
+	stats       CacheStats
+
+	// sleep is swapped out in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// CacheStats counts how metadata fetches were answered
+type CacheStats struct {
+	NotModified int `json:"not_modified"` // 304: the cached body was current
+	Downloaded  int `json:"downloaded"`   // 200: a new or changed body
+}
+
+// cachedMetadata is one cache file
+type cachedMetadata struct {
+	URI          string    `json:"uri"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Body         []byte    `json:"body"`
+}
+
+// NewMetadataCache creates a cache kept in dir. The directory is created on
+// the first write.
+func NewMetadataCache(dir string, opts MetadataCacheOptions) *MetadataCache {
+	c := &MetadataCache{
+		dir:         dir,
+		readOnly:    opts.ReadOnly,
+		nextRequest: make(map[string]time.Time),
+		sleep:       sleepContext,
+	}
+	if opts.RequestsPerSecond > 0 {
+		c.minInterval = time.Duration(float64(time.Second) / opts.RequestsPerSecond)
+	}
+	return c
+}
+
+// MetadataCacheFromEnv returns a cache kept in dir, limited to METADATA_RATE
+// requests per second per host. METADATA_CACHE=off returns nil, for
+// unconditional fetches.
+func MetadataCacheFromEnv(dir string, readOnly bool) *MetadataCache {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("METADATA_CACHE"))) {
+	case "off", "false", "0", "none":
+		return nil
+	}
+	rate := float64(DefaultMetadataRate)
+	if value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("METADATA_RATE"))); err == nil && value >= 0 {
+		rate = float64(value)
+	}
+	return NewMetadataCache(dir, MetadataCacheOptions{RequestsPerSecond: rate, ReadOnly: readOnly})
+}
+
+// Stats returns how the fetches so far were answered. A nil cache has none.
+func (c *MetadataCache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// wait blocks until a request to uri's host is allowed
+func (c *MetadataCache) wait(ctx context.Context, uri string) error {
+	if c.minInterval == 0 {
+		return nil
+	}
+	host := uri
+	if parsed, err := url.Parse(uri); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+
+	// Reserve a slot under the lock, then sleep outside it so other hosts
+	// are not held up
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextRequest[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextRequest[host] = slot.Add(c.minInterval)
+	c.mu.Unlock()
+
+	return c.sleep(ctx, slot.Sub(now))
+}
+
+// prepare adds the cached validators for uri to req and returns the entry
+// they came from, or nil when there is nothing cached
+func (c *MetadataCache) prepare(req *http.Request, uri string) *cachedMetadata {
+	var entry cachedMetadata
+	data, err := os.ReadFile(c.path(uri))
+	if err != nil || json.Unmarshal(data, &entry) != nil || entry.URI != uri {
+		return nil
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+	return &entry
+}
+
+// store records a 200 response for uri. Responses without validators are
+// not cached, since they could never be revalidated.
+func (c *MetadataCache) store(uri string, resp *http.Response, body []byte) error {
+	c.count(false)
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if c.readOnly || etag == "" && lastModified == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cachedMetadata{URI: uri, ETag: etag, LastModified: lastModified, FetchedAt: time.Now().UTC(), Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create metadata cache: %w", err)
+	}
+	path := c.path(uri)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *MetadataCache) count(notModified bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if notModified {
+		c.stats.NotModified++
+	} else {
+		c.stats.Downloaded++
+	}
+}
+
+func (c *MetadataCache) path(uri string) string {
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
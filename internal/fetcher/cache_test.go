@@ -0,0 +1,108 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFetchOffChainMetadata_Cache(t *testing.T) {
+	version := 1
+	var conditional, full int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, version)
+		if r.Header.Get("If-None-Match") == etag {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"name":"Cat v%d","image":"https://example.com/cat.png"}`, version)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		bump            bool // Change the document before fetching
+		readOnly        bool
+		wantName        string
+		wantFull        int
+		wantConditional int
+	}{
+		{name: "first fetch downloads", wantName: "Cat v1", wantFull: 1},
+		{name: "unchanged is answered from cache", wantName: "Cat v1", wantFull: 1, wantConditional: 1},
+		{name: "changed is downloaded again", bump: true, wantName: "Cat v2", wantFull: 2, wantConditional: 1},
+		{name: "read-only cache still revalidates", readOnly: true, wantName: "Cat v2", wantFull: 2, wantConditional: 2},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.bump {
+				version++
+			}
+			f := NewFetcher(nil)
+			f.SetMetadataCache(NewMetadataCache(dir, MetadataCacheOptions{ReadOnly: tt.readOnly}))
+
+			metadata, raw, err := f.fetchOffChainMetadata(context.Background(), server.URL+"/cat.json")
+			if err != nil {
+				t.Fatalf("Failed to fetch metadata: %v", err)
+			}
+			if metadata.Name != tt.wantName || len(raw) == 0 {
+				t.Errorf("Got %q (%d raw bytes), want %q", metadata.Name, len(raw), tt.wantName)
+			}
+			if full != tt.wantFull || conditional != tt.wantConditional {
+				t.Errorf("Got %d full and %d conditional requests, want %d and %d", full, conditional, tt.wantFull, tt.wantConditional)
+			}
+		})
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(entries) != 1 {
+		t.Errorf("Expected one cache file per URI, got %v", entries)
+	}
+}
+
+func TestMetadataCache_NoValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"Plain"}`))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	f := NewFetcher(nil)
+	f.SetMetadataCache(NewMetadataCache(dir, MetadataCacheOptions{}))
+	if _, _, err := f.fetchOffChainMetadata(context.Background(), server.URL+"/plain.json"); err != nil {
+		t.Fatalf("Failed to fetch metadata: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("A response without ETag or Last-Modified should not be cached")
+	}
+}
+
+func TestMetadataCache_Wait(t *testing.T) {
+	cache := NewMetadataCache(t.TempDir(), MetadataCacheOptions{RequestsPerSecond: 2})
+	var slept []time.Duration
+	cache.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+
+	ctx := context.Background()
+	for _, uri := range []string{"https://arweave.net/a", "https://arweave.net/b", "https://ipfs.io/ipfs/c"} {
+		if err := cache.wait(ctx, uri); err != nil {
+			t.Fatalf("Failed to wait: %v", err)
+		}
+	}
+
+	// The second arweave request waits about half a second; the other host does not
+	if slept[0] > 0 || slept[1] < 400*time.Millisecond || slept[2] > 0 {
+		t.Errorf("Unexpected waits %v", slept)
+	}
+}
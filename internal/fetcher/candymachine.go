@@ -0,0 +1,63 @@
+package fetcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Candy Machine Core (v3) account layout:
+//
+//	0    [8]u8   Anchor discriminator
+//	8    u8      account version
+//	9    u8      token standard
+//	10   [6]u8   features
+//	16   Pubkey  authority
+//	48   Pubkey  mint authority (usually the candy guard)
+//	80   Pubkey  collection mint
+//	112  u64     items redeemed
+//	120  u64     items available (start of the candy machine data)
+const candyMachineMinSize = 128
+
+// CandyMachineProgramID owns Candy Machine Core (v3) accounts
+var CandyMachineProgramID = solanago.MustPublicKeyFromBase58("CndyV3LdqHUfDLmE5naZjVN8rBZz4tqhSefbkgYjYgGK")
+
+// candyMachineDiscriminator is the Anchor account discriminator of a
+// CandyMachine, the first 8 bytes of sha256("account:CandyMachine")
+var candyMachineDiscriminator = func() []byte {
+	sum := sha256.Sum256([]byte("account:CandyMachine"))
+	return sum[:8]
+}()
+
+// CandyMachine is a decoded Candy Machine Core account
+type CandyMachine struct {
+	Authority      string
+	MintAuthority  string
+	CollectionMint solanago.PublicKey // Every NFT minted joins this collection
+	ItemsRedeemed  uint64             // NFTs minted so far
+	ItemsAvailable uint64
+}
+
+// ParseCandyMachine decodes a Candy Machine Core account, checking that
+// owner is the Candy Machine program
+func ParseCandyMachine(owner solanago.PublicKey, data []byte) (*CandyMachine, error) {
+	if !owner.Equals(CandyMachineProgramID) {
+		return nil, fmt.Errorf("account is owned by %s, not the Candy Machine program", owner.String())
+	}
+	if len(data) < candyMachineMinSize {
+		return nil, fmt.Errorf("candy machine account is %d bytes, expected at least %d", len(data), candyMachineMinSize)
+	}
+	if !bytes.Equal(data[0:8], candyMachineDiscriminator) {
+		return nil, fmt.Errorf("account is not a candy machine")
+	}
+	return &CandyMachine{
+		Authority:      solanago.PublicKeyFromBytes(data[16:48]).String(),
+		MintAuthority:  solanago.PublicKeyFromBytes(data[48:80]).String(),
+		CollectionMint: solanago.PublicKeyFromBytes(data[80:112]),
+		ItemsRedeemed:  binary.LittleEndian.Uint64(data[112:120]),
+		ItemsAvailable: binary.LittleEndian.Uint64(data[120:128]),
+	}, nil
+}
@@ -0,0 +1,43 @@
+package fetcher
+
+import (
+	"encoding/binary"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestParseCandyMachine(t *testing.T) {
+	authority := solanago.NewWallet().PublicKey()
+	collection := solanago.NewWallet().PublicKey()
+
+	data := make([]byte, 400)
+	copy(data[0:8], candyMachineDiscriminator)
+	copy(data[16:48], authority.Bytes())
+	copy(data[80:112], collection.Bytes())
+	binary.LittleEndian.PutUint64(data[112:120], 42)
+	binary.LittleEndian.PutUint64(data[120:128], 5000)
+
+	cm, err := ParseCandyMachine(CandyMachineProgramID, data)
+	if err != nil {
+		t.Fatalf("Failed to parse candy machine: %v", err)
+	}
+	if cm.Authority != authority.String() || !cm.CollectionMint.Equals(collection) || cm.ItemsRedeemed != 42 || cm.ItemsAvailable != 5000 {
+		t.Errorf("Unexpected candy machine %+v", cm)
+	}
+
+	tests := []struct {
+		name  string
+		owner solanago.PublicKey
+		data  []byte
+	}{
+		{"other program", solanago.TokenProgramID, data},
+		{"truncated", CandyMachineProgramID, data[:100]},
+		{"other account type", CandyMachineProgramID, append([]byte{1, 2, 3, 4, 5, 6, 7, 8}, data[8:]...)},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCandyMachine(tt.owner, tt.data); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
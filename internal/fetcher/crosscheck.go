@@ -0,0 +1,224 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/canonjson"
+)
+
+// Gateways the cross-check fetches content-addressed metadata through when
+// IPFS_GATEWAYS or ARWEAVE_GATEWAYS are not set
+var (
+	DefaultIPFSGateways    = []string{"https://ipfs.io", "https://dweb.link"}
+	DefaultArweaveGateways = []string{"https://arweave.net", "https://ar-io.net"}
+)
+
+// Outcomes of a metadata cross-check
+const (
+	CheckMatch      = "match"      // An independent gateway served the same metadata
+	CheckMismatch   = "mismatch"   // An independent gateway served different metadata
+	CheckUnverified = "unverified" // No independent copy could be fetched
+)
+
+// MetadataCheck records how a backup's off-chain metadata compared with an
+// independent copy of it
+type MetadataCheck struct {
+	Status    string    `json:"status"`
+	URL       string    `json:"url,omitempty"`  // Where the independent copy came from
+	Hash      string    `json:"hash,omitempty"` // Canonical hash of the independent copy
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// CrossCheck fetches content-addressed metadata a second time through an
+// independent gateway and compares the two before the backup trusts it
+//
+// Explanation: A gateway that is compromised, or a network path that is
+// intercepted, can serve any JSON it likes for an ipfs or Arweave URI. The
+// content is identified by its CID or transaction ID rather than by the
+// host, so any other gateway must serve the same document; two gateways
+// agreeing makes poisoning need both. Plain https metadata has only one
+// origin and cannot be checked this way.
+type CrossCheck struct {
+	IPFSGateways    []string // Base URLs such as https://ipfs.io, tried in order
+	ArweaveGateways []string // Base URLs such as https://arweave.net, tried in order
+}
+
+// CrossCheckFromEnv returns the cross-check when METADATA_CROSSCHECK is
+// true, or nil
+func CrossCheckFromEnv() *CrossCheck {
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("METADATA_CROSSCHECK"))); err != nil || !enabled {
+		return nil
+	}
+	return NewCrossCheck()
+}
+
+// NewCrossCheck returns a cross-check through IPFS_GATEWAYS and
+// ARWEAVE_GATEWAYS, or the default gateways when they are not set
+func NewCrossCheck() *CrossCheck {
+	return &CrossCheck{
+		IPFSGateways:    gatewaysFromEnv("IPFS_GATEWAYS", DefaultIPFSGateways),
+		ArweaveGateways: gatewaysFromEnv("ARWEAVE_GATEWAYS", DefaultArweaveGateways),
+	}
+}
+
+func gatewaysFromEnv(name string, defaults []string) []string {
+	var gateways []string
+	for _, gateway := range strings.Split(os.Getenv(name), ",") {
+		if gateway = strings.TrimRight(strings.TrimSpace(gateway), "/"); gateway != "" {
+			gateways = append(gateways, gateway)
+		}
+	}
+	if len(gateways) == 0 {
+		return defaults
+	}
+	return gateways
+}
+
+// arweaveID matches an Arweave transaction ID, 32 bytes in base64url
+var arweaveID = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+// Alternates returns URLs on other gateways serving the same content as
+// uri, excluding uri's own host. A uri that is not content-addressed has
+// none.
+func (c *CrossCheck) Alternates(uri string) []string {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	var gateways []string
+	var path string
+	switch {
+	case parsed.Scheme == "ipfs":
+		// ipfs://<cid>/path, or the older ipfs://ipfs/<cid>/path
+		path = strings.TrimPrefix(strings.TrimPrefix(parsed.Host+parsed.Path, "ipfs/"), "/")
+		gateways, path = c.IPFSGateways, "/ipfs/"+path
+	case parsed.Scheme == "ar":
+		gateways, path = c.ArweaveGateways, "/"+parsed.Host+parsed.Path
+	case parsed.Scheme != "http" && parsed.Scheme != "https":
+		return nil
+	case strings.HasPrefix(parsed.Path, "/ipfs/"):
+		gateways, path = c.IPFSGateways, parsed.Path
+	case strings.Contains(host, ".ipfs."):
+		// Subdomain gateways: https://<cid>.ipfs.dweb.link/path
+		cid, _, _ := strings.Cut(host, ".ipfs.")
+		gateways, path = c.IPFSGateways, "/ipfs/"+cid+parsed.Path
+	case isArweaveHost(host, c.ArweaveGateways):
+		id, _, _ := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+		if !arweaveID.MatchString(id) {
+			return nil
+		}
+		gateways, path = c.ArweaveGateways, parsed.Path
+	default:
+		return nil
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+
+	var alternates []string
+	for _, gateway := range gateways {
+		base, err := url.Parse(gateway)
+		if err != nil || base.Host == "" || strings.EqualFold(base.Hostname(), host) {
+			continue
+		}
+		alternates = append(alternates, strings.TrimRight(gateway, "/")+path)
+	}
+	return alternates
+}
+
+// isArweaveHost reports whether host is an Arweave gateway, a configured
+// one or any arweave.net host
+func isArweaveHost(host string, gateways []string) bool {
+	if host == "arweave.net" || strings.HasSuffix(host, ".arweave.net") {
+		return true
+	}
+	for _, gateway := range gateways {
+		if base, err := url.Parse(gateway); err == nil && strings.EqualFold(base.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossCheckMetadata compares raw, the metadata served for uri, with the
+// first independent copy an alternate gateway serves
+func (f *Fetcher) crossCheckMetadata(ctx context.Context, uri string, raw []byte) *MetadataCheck {
+	check := &MetadataCheck{Status: CheckUnverified, CheckedAt: time.Now().UTC()}
+	alternates := f.crossCheck.Alternates(uri)
+	if len(alternates) == 0 {
+		check.Error = "metadata is not on IPFS or Arweave, so no independent gateway serves it"
+		return check
+	}
+
+	for _, alternate := range alternates {
+		body, err := f.fetchIndependent(ctx, alternate)
+		if err != nil {
+			check.URL, check.Error = alternate, err.Error()
+			continue
+		}
+		check.URL, check.Error = alternate, ""
+		check.Status = CheckMismatch
+		if sameMetadata(raw, body) {
+			check.Status = CheckMatch
+		}
+		if hash, err := canonjson.Hash(body); err == nil {
+			check.Hash = hash
+		}
+		return check
+	}
+	return check
+}
+
+// fetchIndependent downloads uri without the metadata cache, so the copy
+// compared is one this gateway served now
+func (f *Fetcher) fetchIndependent(ctx context.Context, uri string) ([]byte, error) {
+	if f.metadataCache != nil {
+		if err := f.metadataCache.wait(ctx, uri); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d fetching metadata", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
+
+// sameMetadata compares two copies of metadata by their canonical hash,
+// falling back to the exact bytes for a body that is not canonicalizable
+func sameMetadata(a, b []byte) bool {
+	hashA, errA := canonjson.Hash(a)
+	hashB, errB := canonjson.Hash(b)
+	if errA == nil && errB == nil {
+		return hashA == hashB
+	}
+	return bytes.Equal(a, b)
+}
@@ -0,0 +1,118 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCrossCheck_Alternates(t *testing.T) {
+	check := &CrossCheck{
+		IPFSGateways:    []string{"https://ipfs.io", "https://dweb.link"},
+		ArweaveGateways: []string{"https://arweave.net", "https://ar-io.net"},
+	}
+	txID := "bWFpbi1tZXRhZGF0YS10cmFuc2FjdGlvbi1pZC0wMDA"
+
+	tests := []struct {
+		name string
+		uri  string
+		want []string
+	}{
+		{"ipfs scheme", "ipfs://bafycid/1.json", []string{"https://ipfs.io/ipfs/bafycid/1.json", "https://dweb.link/ipfs/bafycid/1.json"}},
+		{"legacy ipfs scheme", "ipfs://ipfs/bafycid", []string{"https://ipfs.io/ipfs/bafycid", "https://dweb.link/ipfs/bafycid"}},
+		{"path gateway skips its own host", "https://ipfs.io/ipfs/bafycid/1.json", []string{"https://dweb.link/ipfs/bafycid/1.json"}},
+		{"other path gateway", "https://nftstorage.link/ipfs/bafycid?format=json", []string{"https://ipfs.io/ipfs/bafycid?format=json", "https://dweb.link/ipfs/bafycid?format=json"}},
+		{"subdomain gateway", "https://bafycid.ipfs.w3s.link/1.json", []string{"https://ipfs.io/ipfs/bafycid/1.json", "https://dweb.link/ipfs/bafycid/1.json"}},
+		{"ar scheme", "ar://" + txID, []string{"https://arweave.net/" + txID, "https://ar-io.net/" + txID}},
+		{"arweave gateway", "https://arweave.net/" + txID, []string{"https://ar-io.net/" + txID}},
+		{"arweave path that is not a transaction", "https://arweave.net/graphql", nil},
+		{"plain https", "https://api.example.com/metadata/1.json", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check.Alternates(tt.uri); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Alternates(%q) = %v, want %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossCheckMetadata(t *testing.T) {
+	genuine := `{"name":"Genuine","image":"ipfs://bafyimage"}`
+	tests := []struct {
+		name       string
+		served     string // What the primary gateway served
+		gateways   []http.HandlerFunc
+		wantStatus string
+		wantError  string
+	}{
+		{
+			name:   "same document reformatted",
+			served: "{\n  \"image\": \"ipfs://bafyimage\",\n  \"name\": \"Genuine\"\n}",
+			gateways: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(genuine))
+			}},
+			wantStatus: CheckMatch,
+		},
+		{
+			name:   "poisoned by the primary gateway",
+			served: `{"name":"Genuine","image":"https://evil.example/drainer.html"}`,
+			gateways: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(genuine))
+			}},
+			wantStatus: CheckMismatch,
+		},
+		{
+			name:   "falls through to the next gateway",
+			served: genuine,
+			gateways: []http.HandlerFunc{
+				func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusGatewayTimeout) },
+				func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(genuine)) },
+			},
+			wantStatus: CheckMatch,
+		},
+		{
+			name:   "no gateway answers",
+			served: genuine,
+			gateways: []http.HandlerFunc{func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}},
+			wantStatus: CheckUnverified,
+			wantError:  "HTTP error 404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &CrossCheck{}
+			for _, handler := range tt.gateways {
+				server := httptest.NewServer(handler)
+				defer server.Close()
+				check.IPFSGateways = append(check.IPFSGateways, server.URL)
+			}
+			f := NewFetcher(nil)
+			f.SetCrossCheck(check)
+
+			// The primary gateway's host differs from the alternates' 127.0.0.1
+			result := f.crossCheckMetadata(context.Background(), "http://localhost:1/ipfs/bafycid", []byte(tt.served))
+			if result.Status != tt.wantStatus {
+				t.Fatalf("Got status %s (%s), want %s", result.Status, result.Error, tt.wantStatus)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("Got error %q, want one containing %q", result.Error, tt.wantError)
+			}
+			if tt.wantStatus != CheckUnverified && (result.Hash == "" || !strings.HasSuffix(result.URL, "/ipfs/bafycid")) {
+				t.Errorf("Expected the independent copy's URL and hash recorded, got %+v", result)
+			}
+		})
+	}
+
+	// Metadata with a single origin cannot be cross-checked
+	f := NewFetcher(nil)
+	f.SetCrossCheck(&CrossCheck{IPFSGateways: DefaultIPFSGateways})
+	if result := f.crossCheckMetadata(context.Background(), "https://api.example.com/1.json", []byte(genuine)); result.Status != CheckUnverified {
+		t.Errorf("Expected plain https metadata unverified, got %+v", result)
+	}
+}
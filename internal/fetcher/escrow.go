@@ -0,0 +1,160 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Escrow records the custody state of an NFT that is locked in its owner's
+// wallet or held on the owner's behalf: a token account frozen or delegated
+// in place (non-custodial staking, marketplace listings), or a staking or
+// escrow program holding the token itself
+type Escrow struct {
+	Frozen    bool      `json:"frozen,omitempty"`    // Token account frozen by someone other than Metaplex
+	Delegate  string    `json:"delegate,omitempty"`  // May move the token without the owner
+	Custodian string    `json:"custodian,omitempty"` // Account holding the token for the owner
+	Program   string    `json:"program,omitempty"`   // Program that owns the custodian
+	Label     string    `json:"label,omitempty"`     // Name of a known staking or escrow program
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// DefaultCustodyPrograms are staking and escrow programs known to hold NFTs
+// for their owners, by program ID
+var DefaultCustodyPrograms = map[string]string{
+	"bankHHdqMuaaST4qQk6mkzxhujevc1VGT6ufUr1tpcQ": "Gem Bank",
+	"farmL4xeBFVXJqtfxCzU9b28QACM7E2W2ctT6epAjvE": "Gem Farm",
+	"stkBL96RZkjY5ine4TvPihGqW8UHJfch2cokjAPzV8i": "Cardinal Staking",
+	"M2mx93ekt1fmXSVkTrUL9xVFHkmME8HTUi5Cyc5aF7K": "Magic Eden",
+	"TSWAPaqyCSx2KABk68Shruf4rp7CxcNi8hAsbdwmHbN": "Tensor Swap",
+}
+
+// CustodyPrograms returns DefaultCustodyPrograms with the programs listed
+// in STAKING_PROGRAMS, as ProgramID=Name pairs, added
+func CustodyPrograms() map[string]string {
+	programs := make(map[string]string, len(DefaultCustodyPrograms))
+	for id, name := range DefaultCustodyPrograms {
+		programs[id] = name
+	}
+	for _, entry := range strings.Split(os.Getenv("STAKING_PROGRAMS"), ",") {
+		id, name, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		id, name = strings.TrimSpace(id), strings.TrimSpace(name)
+		if _, err := solanago.PublicKeyFromBase58(id); err != nil {
+			continue
+		}
+		if name == "" {
+			name = id
+		}
+		programs[id] = name
+	}
+	return programs
+}
+
+// Describe returns a one-line account of the custody state
+func (e *Escrow) Describe() string {
+	var parts []string
+	if e.Custodian != "" {
+		holder := e.Label
+		switch {
+		case holder != "":
+		case e.Program != "":
+			holder = "program " + e.Program
+		default:
+			holder = "a program"
+		}
+		parts = append(parts, fmt.Sprintf("held by %s (%s)", holder, e.Custodian))
+	}
+	if e.Frozen {
+		parts = append(parts, "frozen")
+	}
+	if e.Delegate != "" {
+		delegate := e.Delegate
+		if e.Label != "" && e.Custodian == "" {
+			delegate = e.Label + " (" + e.Delegate + ")"
+		}
+		parts = append(parts, "delegated to "+delegate)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Same reports whether e records the custody state other does, whenever
+// each was checked
+func (e *Escrow) Same(other *Escrow) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	return e.Frozen == other.Frozen && e.Delegate == other.Delegate && e.Custodian == other.Custodian && e.Program == other.Program && e.Label == other.Label
+}
+
+// tokenAccountEscrow reads the lock on a held token account from its
+// jsonParsed info, or returns nil when it can be moved freely
+//
+// Explanation: Programmable NFTs and NFTs staked through Metaplex's freeze
+// delegate are frozen by the master edition, so a frozen account only says
+// something on its own when another authority froze it. A delegate is always
+// recorded: staking programs and marketplace listings both work that way.
+func tokenAccountEscrow(tokenInfo map[string]interface{}, mint solanago.PublicKey, freezeAuthority string, programs map[string]string) *Escrow {
+	escrow := &Escrow{CheckedAt: time.Now().UTC()}
+	if delegate, ok := tokenInfo["delegate"].(string); ok {
+		escrow.Delegate = delegate
+		escrow.Label = programs[delegate]
+	}
+	if state, _ := tokenInfo["state"].(string); state == "frozen" {
+		edition, err := MasterEditionAddress(mint)
+		escrow.Frozen = escrow.Delegate != "" || err != nil || freezeAuthority != edition.String()
+	}
+	if !escrow.Frozen && escrow.Delegate == "" {
+		return nil
+	}
+	return escrow
+}
+
+// holderEscrow is tokenAccountEscrow for a token account read from its
+// binary layout rather than parsed by the RPC node
+func holderEscrow(holder *solana.TokenHolder, mint solanago.PublicKey, freezeAuthority string, programs map[string]string) *Escrow {
+	tokenInfo := make(map[string]interface{})
+	if !holder.Delegate.IsZero() {
+		tokenInfo["delegate"] = holder.Delegate.String()
+	}
+	if holder.Frozen {
+		tokenInfo["state"] = "frozen"
+	}
+	return tokenAccountEscrow(tokenInfo, mint, freezeAuthority, programs)
+}
+
+// FindCustody looks up who holds an NFT that is no longer in its owner's
+// wallet and returns the escrow when a program holds it: a known staking or
+// escrow program, or any program-derived address. It returns nil when a
+// wallet holds it, or nobody does.
+func (f *Fetcher) FindCustody(ctx context.Context, mint solanago.PublicKey) (*Escrow, error) {
+	holder, err := f.client.GetCurrentHolder(ctx, mint)
+	if errors.Is(err, solana.ErrNoHolder) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find holder: %w", err)
+	}
+
+	// Explanation: Staking vaults are usually owned by a PDA that has no
+	// account of its own, so the program is only known when it does. A PDA
+	// cannot sign, though, so whatever holds the NFT is a program either way.
+	escrow := &Escrow{Custodian: holder.Owner.String(), CheckedAt: time.Now().UTC()}
+	account, err := f.client.GetAccountInfo(ctx, holder.Owner)
+	switch {
+	case err == nil && !account.Owner.Equals(solanago.SystemProgramID):
+		escrow.Program = account.Owner.String()
+		escrow.Label = CustodyPrograms()[escrow.Program]
+	case err != nil && !errors.Is(err, solana.ErrAccountNotFound):
+		return nil, err
+	}
+	if escrow.Label == "" && holder.Owner.IsOnCurve() {
+		return nil, nil
+	}
+	return escrow, nil
+}
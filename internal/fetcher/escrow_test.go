@@ -0,0 +1,169 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestTokenAccountEscrow(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey()
+	edition, err := MasterEditionAddress(mint)
+	if err != nil {
+		t.Fatalf("Failed to derive the master edition: %v", err)
+	}
+	freezer := solanago.NewWallet().PublicKey().String()
+	staking := solanago.NewWallet().PublicKey().String()
+	programs := map[string]string{staking: "Test Staking"}
+
+	tests := []struct {
+		name      string
+		info      map[string]interface{}
+		authority string
+		want      *Escrow
+	}{
+		{"free", map[string]interface{}{"state": "initialized"}, edition.String(), nil},
+		{"programmable NFT", map[string]interface{}{"state": "frozen"}, edition.String(), nil},
+		{"frozen by the project", map[string]interface{}{"state": "frozen"}, freezer, &Escrow{Frozen: true}},
+		{"staked in place", map[string]interface{}{"state": "frozen", "delegate": staking}, edition.String(), &Escrow{Frozen: true, Delegate: staking, Label: "Test Staking"}},
+		{"listed", map[string]interface{}{"state": "initialized", "delegate": freezer}, edition.String(), &Escrow{Delegate: freezer}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenAccountEscrow(tt.info, mint, tt.authority, programs)
+			if !got.Same(tt.want) {
+				t.Errorf("Got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustodyPrograms(t *testing.T) {
+	program := solanago.NewWallet().PublicKey().String()
+	unnamed := solanago.NewWallet().PublicKey().String()
+	t.Setenv("STAKING_PROGRAMS", program+"=My Farm, not-a-key=Broken, "+unnamed)
+
+	programs := CustodyPrograms()
+	if programs[program] != "My Farm" {
+		t.Errorf("Expected %s named My Farm, got %q", program, programs[program])
+	}
+	if programs[unnamed] != unnamed {
+		t.Errorf("Expected an unnamed program labelled by its ID, got %q", programs[unnamed])
+	}
+	if _, ok := programs["not-a-key"]; ok {
+		t.Errorf("Expected an invalid program ID to be skipped")
+	}
+	if programs["bankHHdqMuaaST4qQk6mkzxhujevc1VGT6ufUr1tpcQ"] != "Gem Bank" {
+		t.Errorf("Expected the built-in programs kept, got %v", programs)
+	}
+}
+
+// holdingServer answers a mint's getTokenLargestAccounts with one token
+// account and getAccountInfo with accounts, each given as its owning
+// program and data
+func holdingServer(t *testing.T, tokenAccount solanago.PublicKey, accounts map[solanago.PublicKey][]byte) *solana.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		rpcContext := map[string]interface{}{"slot": 1}
+		result := map[string]interface{}{"context": rpcContext, "value": nil}
+		switch req.Method {
+		case "getTokenLargestAccounts":
+			result["value"] = []map[string]interface{}{{"address": tokenAccount.String(), "amount": "1", "decimals": 0, "uiAmountString": "1"}}
+		case "getAccountInfo":
+			var address string
+			json.Unmarshal(req.Params[0], &address)
+			if data, ok := accounts[solanago.MustPublicKeyFromBase58(address)]; ok {
+				result["value"] = map[string]interface{}{
+					"data": []string{base64.StdEncoding.EncodeToString(data), "base64"}, "owner": solanago.TokenProgramID.String(),
+					"lamports": 2039280, "executable": false, "rentEpoch": 0,
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := solana.NewClient(&solana.Config{RPCURL: server.URL, WalletAddress: solanago.NewWallet().PublicKey(), PollInterval: 1, TimeoutSeconds: 5})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestFetchNFTInfoForOwner(t *testing.T) {
+	mint, tokenAccount := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	owner, other, staking := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	// An NFT mint: supply 1, 0 decimals, initialized, freezable by a project
+	mintData := make([]byte, 82)
+	binary.LittleEndian.PutUint64(mintData[36:44], 1)
+	mintData[45] = 1
+	binary.LittleEndian.PutUint32(mintData[46:50], 1)
+	copy(mintData[50:82], solanago.NewWallet().PublicKey().Bytes())
+
+	// tokenData lays out a token account for the mint
+	tokenData := func(holder, delegate solanago.PublicKey, frozen bool) []byte {
+		data := make([]byte, 165)
+		copy(data[0:32], mint.Bytes())
+		copy(data[32:64], holder.Bytes())
+		binary.LittleEndian.PutUint64(data[64:72], 1)
+		if !delegate.IsZero() {
+			binary.LittleEndian.PutUint32(data[72:76], 1)
+			copy(data[76:108], delegate.Bytes())
+		}
+		data[108] = 1
+		if frozen {
+			data[108] = 2
+		}
+		return data
+	}
+
+	tests := []struct {
+		name       string
+		account    []byte
+		wantEscrow *Escrow
+		wantErr    string
+	}{
+		{"held", tokenData(owner, solanago.PublicKey{}, false), nil, ""},
+		{"staked in place", tokenData(owner, staking, true), &Escrow{Frozen: true, Delegate: staking.String()}, ""},
+		{"held by another wallet", tokenData(other, solanago.PublicKey{}, false), nil, "currently owned by " + other.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := holdingServer(t, tokenAccount, map[solanago.PublicKey][]byte{mint: mintData, tokenAccount: tt.account})
+			info, err := NewFetcher(client).FetchNFTInfoForOwner(context.Background(), owner, mint)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to fetch NFT info: %v", err)
+			}
+			if !info.Owner.Equals(owner) || !info.TokenAccount.Equals(tokenAccount) {
+				t.Errorf("Got owner %s and token account %s, want %s and %s", info.Owner, info.TokenAccount, owner, tokenAccount)
+			}
+			if !info.Escrow.Same(tt.wantEscrow) {
+				t.Errorf("Got escrow %+v, want %+v", info.Escrow, tt.wantEscrow)
+			}
+		})
+	}
+}
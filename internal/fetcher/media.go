@@ -3,6 +3,7 @@ package fetcher
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,13 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/NazWright/solvault/internal/fsutil"
+	"github.com/NazWright/solvault/internal/phash"
+	"github.com/NazWright/solvault/internal/progress"
+	"github.com/NazWright/solvault/internal/telemetry"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // MediaType represents the type of media file
@@ -21,38 +29,58 @@ const (
 	MediaTypeVideo     MediaType = "video"
 	MediaTypeAnimation MediaType = "animation"
 	MediaTypeAudio     MediaType = "audio"
+	MediaTypeHTML      MediaType = "html" // Interactive or generative pages
 	MediaTypeUnknown   MediaType = "unknown"
 )
 
 // MediaFile represents a downloaded media file
 type MediaFile struct {
-	URL          string    `json:"url"`
-	LocalPath    string    `json:"local_path"`
-	Filename     string    `json:"filename"`
-	MediaType    MediaType `json:"media_type"`
-	ContentType  string    `json:"content_type"`
-	Size         int64     `json:"size"`
-	Checksum     string    `json:"checksum"`
-	DownloadedAt time.Time `json:"downloaded_at"`
+	URL          string                 `json:"url"`
+	LocalPath    string                 `json:"local_path"`
+	Filename     string                 `json:"filename"`
+	MediaType    MediaType              `json:"media_type"`
+	ContentType  string                 `json:"content_type"`
+	DetectedType string                 `json:"detected_type,omitempty"` // Sniffed from the file's first bytes
+	TypeMismatch bool                   `json:"type_mismatch,omitempty"` // DetectedType contradicts ContentType
+	Size         int64                  `json:"size"`
+	Checksum     string                 `json:"checksum"`
+	DownloadedAt time.Time              `json:"downloaded_at"`
+	RenderedFrom string                 `json:"rendered_from,omitempty"` // Page URL this screenshot or recording was captured from
+	FetchedFrom  string                 `json:"fetched_from,omitempty"`  // Mirror URL downloaded instead of URL (see URIRewrites)
+	Thumbnails   []*thumbnail.Thumbnail `json:"thumbnails,omitempty"`
+	// Fingerprint of what an image shows, to tell re-encodes from changes
+	PerceptualHash *phash.Hash `json:"perceptual_hash,omitempty"`
+	// SHA-256 of an image's decoded pixels, unchanged by stripped metadata
+	PixelHash string `json:"pixel_hash,omitempty"`
 }
 
+// progressThreshold is the size above which a download shows a progress bar
+const progressThreshold = 1 << 20
+
 // MediaDownloader handles downloading and storing NFT media files
 type MediaDownloader struct {
 	client      *http.Client
-	maxFileSize int64 // Maximum file size in bytes (default 100MB)
+	maxFileSize int64         // Maximum file size in bytes (default 100MB)
+	maxAttempts int           // Tries per file when a transfer is cut off
+	retryDelay  time.Duration // Grows with each attempt
 }
 
 // NewMediaDownloader creates a new media downloader
 func NewMediaDownloader() *MediaDownloader {
 	return &MediaDownloader{
 		client: &http.Client{
-			Timeout: 60 * time.Second, // Longer timeout for media downloads
+			Timeout: DefaultMediaTimeout, // Longer timeout for media downloads
 		},
 		maxFileSize: 100 * 1024 * 1024, // 100MB default limit
+		maxAttempts: 3,
+		retryDelay:  2 * time.Second,
 	}
 }
 
-// DownloadMedia downloads media from a URL and stores it locally
+// DownloadMedia downloads media from a URL and stores it locally. A
+// transfer cut off partway is retried from where it stopped, and the
+// partial file is kept so a later run can resume it too. Rate limits and
+// server errors are retried as well.
 func (md *MediaDownloader) DownloadMedia(ctx context.Context, mediaURL, targetDir string) (*MediaFile, error) {
 	// Parse and validate URL
 	parsedURL, err := url.Parse(mediaURL)
@@ -62,14 +90,46 @@ func (md *MediaDownloader) DownloadMedia(ctx context.Context, mediaURL, targetDi
 
 	// Create target directory
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create media directory: %w", err)
+		return nil, fmt.Errorf("failed to create media directory: %w", fsutil.WrapPathError(targetDir, err))
 	}
 
-	// Determine filename from URL
-	filename := md.extractFilename(parsedURL)
-	if filename == "" {
-		filename = fmt.Sprintf("media_%d", time.Now().Unix())
+	ctx, span := telemetry.StartClient(ctx, "media download", attribute.String("url.full", mediaURL))
+	for attempt := 1; ; attempt++ {
+		mediaFile, err := md.download(ctx, parsedURL, targetDir)
+		if err == nil || !retryable(err) || attempt >= md.maxAttempts {
+			span.SetAttributes(attribute.Int("solvault.attempts", attempt))
+			if mediaFile != nil {
+				span.SetAttributes(
+					attribute.Int64("http.response.body.size", mediaFile.Size),
+					attribute.String("solvault.media.type", string(mediaFile.MediaType)))
+			}
+			telemetry.End(span, err)
+			return mediaFile, err
+		}
+		telemetry.Event(ctx, "retry", attribute.Int("solvault.attempt", attempt), attribute.String("error", err.Error()))
+		select {
+		case <-ctx.Done():
+			telemetry.End(span, err)
+			return nil, err
+		case <-time.After(md.retryDelay * time.Duration(attempt)):
+		}
 	}
+}
+
+// errServerBusy marks a rate limited or failing gateway worth retrying
+var errServerBusy = errors.New("media server busy")
+
+// retryable reports whether another attempt at a download may succeed
+func retryable(err error) bool {
+	return errors.Is(err, errInterrupted) || errors.Is(err, errServerBusy)
+}
+
+// download makes one attempt at a media file, resuming a partial file left
+// by an earlier attempt when the server supports range requests
+func (md *MediaDownloader) download(ctx context.Context, parsedURL *url.URL, targetDir string) (*MediaFile, error) {
+	mediaURL := parsedURL.String()
+	part := newPartFile(targetDir, mediaURL)
+	offset := part.resumeOffset()
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
@@ -79,64 +139,143 @@ func (md *MediaDownloader) DownloadMedia(ctx context.Context, mediaURL, targetDi
 
 	// Add user agent to avoid blocking
 	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// The server sends the whole file instead if it changed meanwhile
+		if validator := part.manifest.validator(); validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
 
 	// Execute request
 	resp, err := md.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download media: %w", err)
+		return nil, fmt.Errorf("%w: %v", errInterrupted, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0 && contentRangeStart(resp) == offset:
+		// Resuming
+	case resp.StatusCode == http.StatusOK:
+		offset = 0 // No range support, or the file changed: start over
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		part.remove()
+		return nil, fmt.Errorf("%w: partial download no longer matches the server", errInterrupted)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, fmt.Errorf("%w: HTTP error %d downloading media", errServerBusy, resp.StatusCode)
+	default:
 		return nil, fmt.Errorf("HTTP error %d downloading media", resp.StatusCode)
 	}
 
 	// Check content length
-	if resp.ContentLength > md.maxFileSize {
-		return nil, fmt.Errorf("file too large: %d bytes (max %d)", resp.ContentLength, md.maxFileSize)
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
 	}
-
-	// Determine media type and adjust filename if needed
-	contentType := resp.Header.Get("Content-Type")
-	mediaType := md.determineMediaType(contentType, filename)
-
-	// Add extension if missing
-	if !strings.Contains(filename, ".") {
-		if ext := md.getExtensionForContentType(contentType); ext != "" {
-			filename += ext
-		}
+	if total > md.maxFileSize {
+		part.remove()
+		return nil, fmt.Errorf("file too large: %d bytes (max %d)", total, md.maxFileSize)
 	}
 
-	localPath := filepath.Join(targetDir, filename)
+	// Determine filename from URL
+	filename := md.extractFilename(parsedURL)
+	if filename == "" {
+		filename = fmt.Sprintf("media_%d", time.Now().Unix())
+	}
+	contentType := resp.Header.Get("Content-Type")
 
-	// Create file and download with size limit
-	file, err := os.Create(localPath)
+	// Open the partial file, hashing what an earlier attempt already wrote
+	hash := sha256.New()
+	file, err := part.open(offset, hash, partManifest{
+		URL:          mediaURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Total:        total,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file %s: %w", localPath, err)
+		return nil, err
 	}
 	defer file.Close()
 
 	// Use limited reader to prevent huge downloads
 	limitedReader := &io.LimitedReader{
 		R: resp.Body,
-		N: md.maxFileSize,
+		N: md.maxFileSize - offset,
 	}
 
-	// Copy with checksum calculation
-	hash := sha256.New()
-	multiWriter := io.MultiWriter(file, hash)
+	// Copy with checksum calculation, showing progress for large files
+	writers := []io.Writer{file, hash}
+	if total > progressThreshold {
+		bar := progress.NewBytes("⬇️  "+filename, total)
+		defer bar.Finish()
+		bar.Add(offset)
+		writers = append(writers, bar.Writer())
+	}
+	multiWriter := io.MultiWriter(writers...)
 
 	bytesWritten, err := io.Copy(multiWriter, limitedReader)
+	size := offset + bytesWritten
 	if err != nil {
-		os.Remove(localPath) // Cleanup on error
-		return nil, fmt.Errorf("failed to write media file: %w", err)
+		// Keep what arrived so the next attempt resumes from here
+		return nil, fmt.Errorf("%w after %d bytes: %v", errInterrupted, size, err)
 	}
 
 	// Check if we hit the size limit
 	if limitedReader.N == 0 && resp.ContentLength == -1 {
-		os.Remove(localPath)
+		file.Close()
+		part.remove()
 		return nil, fmt.Errorf("file too large: exceeded %d bytes", md.maxFileSize)
 	}
+	if total >= 0 && size < total {
+		return nil, fmt.Errorf("%w after %d of %d bytes", errInterrupted, size, total)
+	}
+
+	// Check the bytes are what the server said they were. Gateways answer
+	// some failures with an HTML page and a 200 status.
+	head := make([]byte, sniffLen)
+	read, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read media file: %w", err)
+	}
+	detected := DetectContentType(head[:read])
+	expected := md.determineMediaType("", filename)
+	if expected == MediaTypeUnknown {
+		expected = md.determineMediaType(contentType, "")
+	}
+	mismatch, err := checkContentType(contentType, detected, expected)
+	if err != nil {
+		file.Close()
+		part.remove()
+		return nil, fmt.Errorf("%w (claimed %s)", err, describeType(contentType))
+	}
+	if genericContentType(contentType) {
+		// The bytes are the only evidence of what the file is
+		contentType = detected
+	}
+
+	// Determine media type and add an extension if missing
+	mediaType := md.determineMediaType(contentType, filename)
+	if !strings.Contains(filename, ".") {
+		if ext := md.getExtensionForContentType(contentType); ext != "" {
+			filename += ext
+		}
+	}
+
+	// Long URL paths (content hashes, query-string-like names) are shortened
+	// so deep backup directories stay within the platform path limit
+	filename = fsutil.ShortenName(filename, fsutil.MaxMediaFilename)
+	localPath := filepath.Join(targetDir, filename)
+	if err := fsutil.CheckPath(localPath); err != nil {
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := part.commit(localPath); err != nil {
+		return nil, err
+	}
 
 	// Calculate final checksum
 	checksum := fmt.Sprintf("%x", hash.Sum(nil))
@@ -147,7 +286,9 @@ func (md *MediaDownloader) DownloadMedia(ctx context.Context, mediaURL, targetDi
 		Filename:     filename,
 		MediaType:    mediaType,
 		ContentType:  contentType,
-		Size:         bytesWritten,
+		DetectedType: detected,
+		TypeMismatch: mismatch,
+		Size:         size,
 		Checksum:     checksum,
 		DownloadedAt: time.Now(),
 	}
@@ -173,6 +314,27 @@ func (md *MediaDownloader) extractFilename(u *url.URL) string {
 	return filename
 }
 
+// NewLocalMediaFile describes a media file created locally rather than
+// downloaded, such as a capture of a rendered page
+func NewLocalMediaFile(localPath, sourceURL, contentType string) (*MediaFile, error) {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media file: %w", err)
+	}
+	filename := filepath.Base(localPath)
+	return &MediaFile{
+		URL:          sourceURL,
+		LocalPath:    localPath,
+		Filename:     filename,
+		MediaType:    (&MediaDownloader{}).determineMediaType(contentType, filename),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Checksum:     fmt.Sprintf("%x", sha256.Sum256(data)),
+		DownloadedAt: time.Now(),
+		RenderedFrom: sourceURL,
+	}, nil
+}
+
 // determineMediaType determines the media type from content type and filename
 func (md *MediaDownloader) determineMediaType(contentType, filename string) MediaType {
 	contentType = strings.ToLower(contentType)
@@ -186,6 +348,8 @@ func (md *MediaDownloader) determineMediaType(contentType, filename string) Medi
 		return MediaTypeVideo
 	case strings.HasPrefix(contentType, "audio/"):
 		return MediaTypeAudio
+	case strings.HasPrefix(contentType, "text/html"):
+		return MediaTypeHTML
 	case contentType == "application/octet-stream" && strings.Contains(filename, ".gif"):
 		return MediaTypeAnimation
 	}
@@ -202,6 +366,8 @@ func (md *MediaDownloader) determineMediaType(contentType, filename string) Medi
 	case strings.HasSuffix(filename, ".mp3") || strings.HasSuffix(filename, ".wav") ||
 		strings.HasSuffix(filename, ".ogg"):
 		return MediaTypeAudio
+	case strings.HasSuffix(filename, ".html") || strings.HasSuffix(filename, ".htm"):
+		return MediaTypeHTML
 	}
 
 	return MediaTypeUnknown
@@ -209,7 +375,8 @@ func (md *MediaDownloader) determineMediaType(contentType, filename string) Medi
 
 // getExtensionForContentType returns appropriate file extension for content type
 func (md *MediaDownloader) getExtensionForContentType(contentType string) string {
-	contentType = strings.ToLower(contentType)
+	contentType, _, _ = strings.Cut(strings.ToLower(contentType), ";")
+	contentType = strings.TrimSpace(contentType)
 
 	switch contentType {
 	case "image/jpeg":
@@ -232,6 +399,8 @@ func (md *MediaDownloader) getExtensionForContentType(contentType string) string
 		return ".wav"
 	case "audio/ogg":
 		return ".ogg"
+	case "text/html":
+		return ".html"
 	default:
 		return ""
 	}
@@ -2,10 +2,14 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -123,3 +127,88 @@ func TestMediaType_Detection(t *testing.T) {
 		}
 	}
 }
+
+func TestDownloadMediaFiles(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		first := requests[r.URL.Path] == 1
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(20 * time.Millisecond) // Let downloads overlap
+		if r.URL.Path == "/busy.png" && first {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	info := &NFTInfo{Metadata: &NFTMetadata{
+		Image:        server.URL + "/image.png",
+		AnimationURL: server.URL + "/clip.mp4",
+		Properties: Properties{Files: []File{
+			{URI: server.URL + "/image.png"}, // Same as the image
+			{URI: server.URL + "/clip.mp4"},
+			{URI: server.URL + "/busy.png"},
+			{URI: " "},
+		}},
+	}}
+
+	f := NewFetcher(nil)
+	f.mediaDownloader.retryDelay = time.Millisecond
+	if err := f.DownloadMediaFiles(context.Background(), info, t.TempDir()); err != nil {
+		t.Fatalf("Failed to download media: %v", err)
+	}
+
+	var got []string
+	for _, media := range info.MediaFiles {
+		got = append(got, media.Filename)
+	}
+	if want := []string{"image.png", "clip.mp4", "busy.png"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Downloaded %v, want %v in metadata order", got, want)
+	}
+	if requests["/image.png"] != 1 || requests["/clip.mp4"] != 1 {
+		t.Errorf("Duplicate URIs downloaded more than once: %v", requests)
+	}
+	if requests["/busy.png"] != 2 {
+		t.Errorf("Rate limited file requested %d times, want a retry", requests["/busy.png"])
+	}
+	if maxInFlight < 2 {
+		t.Errorf("Downloads ran one at a time")
+	}
+}
+
+func TestDownloadMediaFiles_Interrupted(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	info := &NFTInfo{Metadata: &NFTMetadata{
+		Image:      server.URL + "/image.png",
+		Properties: Properties{Files: []File{{URI: server.URL + "/clip.mp4"}}},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewFetcher(nil).DownloadMediaFiles(ctx, info, t.TempDir())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if n := requests.Load(); n != 0 || len(info.MediaFiles) != 0 {
+		t.Errorf("Expected nothing downloaded after the interrupt, got %d request(s) and %d file(s)", n, len(info.MediaFiles))
+	}
+}
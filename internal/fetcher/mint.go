@@ -0,0 +1,110 @@
+package fetcher
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// SPL mint layout, shared by the Token and Token-2022 programs:
+//
+//	0   COption<Pubkey> mint authority (u32 tag + 32 bytes)
+//	36  u64             supply
+//	44  u8              decimals
+//	45  bool            is initialized
+//	46  COption<Pubkey> freeze authority (u32 tag + 32 bytes)
+const mintSize = 82
+
+// metaplexProgramID owns the metadata and master edition accounts
+var metaplexProgramID = solanago.MustPublicKeyFromBase58("metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s")
+
+// Mint is a decoded SPL mint account
+type Mint struct {
+	MintAuthority   string // "" once minting is disabled
+	Supply          uint64
+	Decimals        uint8
+	Initialized     bool
+	FreezeAuthority string // "" when nobody can freeze holders' tokens
+}
+
+// ParseMint decodes an SPL mint account. Token-2022 extensions after the
+// base layout are ignored here; see ParseTokenExtensions.
+func ParseMint(data []byte) (*Mint, error) {
+	if len(data) < mintSize {
+		return nil, fmt.Errorf("mint account is %d bytes, expected at least %d", len(data), mintSize)
+	}
+	mintAuthority, err := coptionPubkey(data[0:36])
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint authority: %w", err)
+	}
+	freezeAuthority, err := coptionPubkey(data[46:82])
+	if err != nil {
+		return nil, fmt.Errorf("invalid freeze authority: %w", err)
+	}
+	return &Mint{
+		MintAuthority:   mintAuthority,
+		Supply:          binary.LittleEndian.Uint64(data[36:44]),
+		Decimals:        data[44],
+		Initialized:     data[45] == 1,
+		FreezeAuthority: freezeAuthority,
+	}, nil
+}
+
+// coptionPubkey decodes a COption<Pubkey>: a u32 tag (0 none, 1 some)
+// followed by the key
+func coptionPubkey(b []byte) (string, error) {
+	switch binary.LittleEndian.Uint32(b[0:4]) {
+	case 0:
+		return "", nil
+	case 1:
+		return solanago.PublicKeyFromBytes(b[4:36]).String(), nil
+	default:
+		return "", fmt.Errorf("bad option tag %d", binary.LittleEndian.Uint32(b[0:4]))
+	}
+}
+
+// MasterEditionAddress derives the Metaplex master edition account of a
+// mint. Metaplex hands a mint's mint and freeze authority to this account,
+// so an authority equal to it is expected, not a risk.
+func MasterEditionAddress(mintAddress solanago.PublicKey) (solanago.PublicKey, error) {
+	seeds := [][]byte{
+		[]byte("metadata"),
+		metaplexProgramID.Bytes(),
+		mintAddress.Bytes(),
+		[]byte("edition"),
+	}
+	pda, _, err := solanago.FindProgramAddress(seeds, metaplexProgramID)
+	if err != nil {
+		return solanago.PublicKey{}, fmt.Errorf("failed to find master edition PDA: %w", err)
+	}
+	return pda, nil
+}
+
+// AuthenticityWarnings flags mint state that undermines an NFT's scarcity:
+// more than one token in circulation, or a mint authority other than the
+// Metaplex master edition that could mint more. A freeze authority outside
+// Metaplex is reported too, since it can lock the holder's token.
+// Backups made before SolVault recorded mint authorities yield no
+// authority warnings.
+func AuthenticityWarnings(info *NFTInfo) []string {
+	if info == nil {
+		return nil
+	}
+	var warnings []string
+	if info.Supply > 1 {
+		warnings = append(warnings, fmt.Sprintf("supply is %d; a unique NFT has a supply of 1", info.Supply))
+	}
+
+	edition, err := MasterEditionAddress(info.MintAddress)
+	expected := func(authority string) bool {
+		return err == nil && authority == edition.String()
+	}
+	if info.MintAuthority != "" && !expected(info.MintAuthority) {
+		warnings = append(warnings, fmt.Sprintf("mint authority %s is still active and can mint more tokens", info.MintAuthority))
+	}
+	if info.FreezeAuthority != "" && !expected(info.FreezeAuthority) {
+		warnings = append(warnings, fmt.Sprintf("freeze authority %s can freeze the token in its holder's wallet", info.FreezeAuthority))
+	}
+	return warnings
+}
@@ -0,0 +1,97 @@
+package fetcher
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// mintData encodes an SPL mint; zero keys encode as COption::None
+func mintData(mintAuthority solanago.PublicKey, supply uint64, decimals uint8, freezeAuthority solanago.PublicKey) []byte {
+	coption := func(key solanago.PublicKey) []byte {
+		if key.IsZero() {
+			return make([]byte, 36)
+		}
+		return append(binary.LittleEndian.AppendUint32(nil, 1), key.Bytes()...)
+	}
+	data := coption(mintAuthority)
+	data = binary.LittleEndian.AppendUint64(data, supply)
+	data = append(data, decimals, 1)
+	return append(data, coption(freezeAuthority)...)
+}
+
+func TestParseMint(t *testing.T) {
+	authority := solanago.NewWallet().PublicKey()
+	freezer := solanago.NewWallet().PublicKey()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantMint Mint
+		wantErr  bool
+	}{
+		{"no authorities", mintData(solanago.PublicKey{}, 1, 0, solanago.PublicKey{}), Mint{Supply: 1, Initialized: true}, false},
+		{"both authorities", mintData(authority, 5, 0, freezer), Mint{MintAuthority: authority.String(), Supply: 5, Initialized: true, FreezeAuthority: freezer.String()}, false},
+		{"fungible", mintData(solanago.PublicKey{}, 1_000_000, 6, solanago.PublicKey{}), Mint{Supply: 1_000_000, Decimals: 6, Initialized: true}, false},
+		{"token-2022 with extensions", append(mintData(authority, 1, 0, solanago.PublicKey{}), make([]byte, 100)...), Mint{MintAuthority: authority.String(), Supply: 1, Initialized: true}, false},
+		{"truncated", mintData(authority, 1, 0, freezer)[:60], Mint{}, true},
+		{"bad option tag", append([]byte{7, 0, 0, 0}, mintData(authority, 1, 0, freezer)[4:]...), Mint{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mint, err := ParseMint(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", mint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse mint: %v", err)
+			}
+			if *mint != tt.wantMint {
+				t.Errorf("Got %+v, want %+v", *mint, tt.wantMint)
+			}
+		})
+	}
+}
+
+func TestAuthenticityWarnings(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey()
+	edition, err := MasterEditionAddress(mint)
+	if err != nil {
+		t.Fatalf("Failed to derive master edition: %v", err)
+	}
+	stranger := solanago.NewWallet().PublicKey().String()
+
+	tests := []struct {
+		name string
+		info *NFTInfo
+		want []string // Substrings, one per warning
+	}{
+		{"clean", &NFTInfo{MintAddress: mint, Supply: 1}, nil},
+		{"metaplex edition authorities", &NFTInfo{MintAddress: mint, Supply: 1, MintAuthority: edition.String(), FreezeAuthority: edition.String()}, nil},
+		{"supply above one", &NFTInfo{MintAddress: mint, Supply: 3}, []string{"supply is 3"}},
+		{"active mint authority", &NFTInfo{MintAddress: mint, Supply: 1, MintAuthority: stranger}, []string{"can mint more"}},
+		{"foreign freeze authority", &NFTInfo{MintAddress: mint, Supply: 1, FreezeAuthority: stranger}, []string{"can freeze"}},
+		{"everything", &NFTInfo{MintAddress: mint, Supply: 2, MintAuthority: stranger, FreezeAuthority: stranger}, []string{"supply is 2", "can mint more", "can freeze"}},
+		{"no record", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AuthenticityWarnings(tt.info)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got warnings %q, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("Warning %q lacks %q", got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -2,16 +2,23 @@ package fetcher
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/NazWright/solvault/internal/canonjson"
+	"github.com/NazWright/solvault/internal/market"
 	"github.com/NazWright/solvault/internal/solana"
+	"github.com/NazWright/solvault/internal/telemetry"
+	"github.com/NazWright/solvault/internal/webarchive"
 	solanago "github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // NFTMetadata represents the standard Metaplex NFT metadata structure
@@ -62,6 +69,51 @@ type Collection struct {
 	Family string `json:"family"`
 }
 
+// OnChainMetadata is the Metaplex metadata account content, the
+// authoritative source for royalties and creator verification
+type OnChainMetadata struct {
+	Name                 string    `json:"name"`
+	Symbol               string    `json:"symbol"`
+	URI                  string    `json:"uri"`
+	SellerFeeBasisPoints int       `json:"seller_fee_basis_points"`
+	Creators             []Creator `json:"creators,omitempty"`
+}
+
+// maxCreators is the Metaplex limit on creators per NFT
+const maxCreators = 5
+
+// CreatorMismatches compares the creators listed in off-chain metadata with
+// the on-chain record and describes each disagreement. Off-chain creators
+// are self-reported, so only the on-chain list can be trusted for royalties.
+func CreatorMismatches(onChain, offChain []Creator) []string {
+	if len(offChain) == 0 {
+		return nil
+	}
+
+	var mismatches []string
+	chain := make(map[string]Creator, len(onChain))
+	for _, creator := range onChain {
+		chain[creator.Address] = creator
+	}
+	listed := make(map[string]bool, len(offChain))
+	for _, creator := range offChain {
+		listed[creator.Address] = true
+		actual, ok := chain[creator.Address]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s is listed off-chain but is not an on-chain creator", creator.Address))
+		case actual.Share != creator.Share:
+			mismatches = append(mismatches, fmt.Sprintf("%s has a %d%% share off-chain but %d%% on-chain", creator.Address, creator.Share, actual.Share))
+		}
+	}
+	for _, creator := range onChain {
+		if !listed[creator.Address] {
+			mismatches = append(mismatches, fmt.Sprintf("%s is an on-chain creator missing from the off-chain metadata", creator.Address))
+		}
+	}
+	return mismatches
+}
+
 // NFTInfo contains comprehensive information about an NFT
 type NFTInfo struct {
 	MintAddress  solanago.PublicKey `json:"mint_address"`
@@ -73,14 +125,36 @@ type NFTInfo struct {
 	FetchedAt    time.Time          `json:"fetched_at"`
 	Supply       uint64             `json:"supply"`
 	Decimals     uint8              `json:"decimals"`
-	MediaFiles   []*MediaFile       `json:"media_files,omitempty"` // Downloaded media files
+	// Authorities of the mint when fetched; "" when disabled
+	MintAuthority   string               `json:"mint_authority,omitempty"`
+	FreezeAuthority string               `json:"freeze_authority,omitempty"`
+	MediaFiles      []*MediaFile         `json:"media_files,omitempty"` // Downloaded media files
+	TokenProgram    string               `json:"token_program,omitempty"`
+	OnChain         *OnChainMetadata     `json:"on_chain_metadata,omitempty"` // Metaplex metadata account
+	Extensions      *TokenExtensions     `json:"token_extensions,omitempty"`  // Token-2022 mint extensions
+	Market          *market.Quote        `json:"market,omitempty"`            // Marketplace prices when backed up
+	ExternalArchive *webarchive.Snapshot `json:"external_archive,omitempty"`  // Saved copy of the external_url site
+	MetadataHash    string               `json:"metadata_hash,omitempty"`     // SHA-256 of the canonical (RFC 8785) off-chain metadata
+	MetadataCheck   *MetadataCheck       `json:"metadata_check,omitempty"`    // Comparison with an independent gateway's copy
+	MetadataSource  string               `json:"metadata_source,omitempty"`   // Where the metadata was fetched from, when URI_REWRITES sent it away from MetadataURI
+	Escrow          *Escrow              `json:"escrow,omitempty"`            // Staked, listed or held by a program for the owner
+	// Off-chain metadata exactly as served; saved as metadata.raw.json
+	// rather than inside nft_data.json
+	RawMetadata []byte `json:"-"`
 }
 
+// DefaultMediaConcurrency is how many media files of one NFT download at once
+const DefaultMediaConcurrency = 4
+
 // Fetcher handles fetching NFT metadata from various sources
 type Fetcher struct {
-	client          *solana.Client
-	httpClient      *http.Client
-	mediaDownloader *MediaDownloader
+	client           *solana.Client
+	httpClient       *http.Client
+	mediaDownloader  *MediaDownloader
+	mediaConcurrency int            // Media files of one NFT downloaded at once
+	metadataCache    *MetadataCache // Conditional requests for off-chain metadata; nil fetches it whole every time
+	crossCheck       *CrossCheck    // Compares metadata with a second gateway's copy; nil trusts the first
+	rewrites         *URIRewrites   // Mirrors for dead hosts; nil fetches every URI as given
 }
 
 // NewFetcher creates a new NFT metadata fetcher
@@ -88,14 +162,25 @@ func NewFetcher(client *solana.Client) *Fetcher {
 	return &Fetcher{
 		client: client,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultMetadataTimeout,
 		},
-		mediaDownloader: NewMediaDownloader(),
+		mediaDownloader:  NewMediaDownloader(),
+		mediaConcurrency: DefaultMediaConcurrency,
 	}
 }
 
 // FetchNFTInfo retrieves comprehensive NFT information including metadata
+// for an NFT held by the primary configured wallet
 func (f *Fetcher) FetchNFTInfo(ctx context.Context, mintAddress solanago.PublicKey) (*NFTInfo, error) {
+	return f.FetchNFTInfoForOwner(ctx, f.client.Config().WalletAddress, mintAddress)
+}
+
+// FetchNFTInfoForOwner retrieves NFT information for an NFT held by owner
+// Explanation: The holder is found with getTokenLargestAccounts on the mint
+// rather than by listing the owner's token accounts, so the cost does not
+// grow with the wallet and any wallet can be checked. When owner does not
+// hold the NFT, the error names the wallet that does.
+func (f *Fetcher) FetchNFTInfoForOwner(ctx context.Context, owner, mintAddress solanago.PublicKey) (*NFTInfo, error) {
 	info := &NFTInfo{
 		MintAddress: mintAddress,
 		FetchedAt:   time.Now(),
@@ -107,88 +192,173 @@ func (f *Fetcher) FetchNFTInfo(ctx context.Context, mintAddress solanago.PublicK
 		return nil, fmt.Errorf("failed to get mint account info: %w", err)
 	}
 
-	// Parse mint data to get supply and decimals
-	if len(mintAccount.Data.GetBinary()) >= 44 {
-		// Basic mint account structure parsing
-		// This is a simplified version - in production you'd want proper mint account parsing
-		data := mintAccount.Data.GetBinary()
+	if err := readMint(info, mintAccount); err != nil {
+		return nil, err
+	}
 
-		// Extract decimals from mint account (byte 44)
-		if len(data) > 44 {
-			info.Decimals = data[44]
+	// Find the owner among the mint's holders
+	holders, err := f.client.GetTokenHolders(ctx, mintAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token holders: %w", err)
+	}
+	var holder *solana.TokenHolder
+	for i := range holders {
+		if holders[i].Owner.Equals(owner) {
+			holder = &holders[i]
+			break
 		}
+	}
+	if holder == nil {
+		return nil, notHeldError(owner, mintAddress, holders)
+	}
+	info.TokenAccount = holder.TokenAccount
+	info.Owner = owner
+	info.Escrow = holderEscrow(holder, mintAddress, info.FreezeAuthority, CustodyPrograms())
 
-		// For now, assume supply of 1 for NFTs - in production you'd properly parse this
-		info.Supply = 1
+	f.loadMetadata(ctx, info, mintAccount)
+	return info, nil
+}
 
-		// Validate this looks like an NFT (0 decimals is a strong indicator)
-		if info.Decimals != 0 {
-			return nil, fmt.Errorf("this token has %d decimals - NFTs should have 0 decimals", info.Decimals)
-		}
+// FetchMintInfo retrieves NFT information for a mint whoever holds it,
+// without a token account or owner, as when archiving a whole collection
+func (f *Fetcher) FetchMintInfo(ctx context.Context, mintAddress solanago.PublicKey) (*NFTInfo, error) {
+	info := &NFTInfo{
+		MintAddress: mintAddress,
+		FetchedAt:   time.Now(),
 	}
 
-	// Find token accounts for this mint owned by our wallet
-	tokenAccounts, err := f.client.GetTokenAccountsByOwner(ctx)
+	mintAccount, err := f.client.GetAccountInfo(ctx, mintAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token accounts: %w", err)
-	}
-
-	var tokenAccount *rpc.TokenAccount
-	for _, account := range tokenAccounts {
-		// Check if we have parsed data
-		rawJSON := account.Account.Data.GetRawJSON()
-		if len(rawJSON) > 0 {
-			var parsed map[string]interface{}
-			if err := json.Unmarshal(rawJSON, &parsed); err == nil {
-				// Check if data is under "parsed" key
-				var tokenInfo map[string]interface{}
-				var ok bool
-
-				if parsedData, exists := parsed["parsed"].(map[string]interface{}); exists {
-					tokenInfo, ok = parsedData["info"].(map[string]interface{})
-				} else {
-					tokenInfo, ok = parsed["info"].(map[string]interface{})
-				}
-
-				if ok {
-					if mint, ok := tokenInfo["mint"].(string); ok {
-						mintPubkey, err := solanago.PublicKeyFromBase58(mint)
-						if err == nil && mintPubkey.Equals(mintAddress) {
-							tokenAccount = account
-							info.TokenAccount = account.Pubkey
-							info.Owner = f.client.Config().WalletAddress
-							break
-						}
-					}
-				}
-			}
-		}
+		return nil, fmt.Errorf("failed to get mint account info: %w", err)
 	}
-
-	if tokenAccount == nil {
-		return nil, fmt.Errorf("token account not found for mint %s", mintAddress.String())
+	if err := readMint(info, mintAccount); err != nil {
+		return nil, err
 	}
 
-	// Try to find and fetch metadata
-	metadataURI, err := f.findMetadataURI(ctx, mintAddress)
+	f.loadMetadata(ctx, info, mintAccount)
+	return info, nil
+}
+
+// loadMetadata finds the mint's metadata URI and fetches the off-chain
+// metadata it points to
+func (f *Fetcher) loadMetadata(ctx context.Context, info *NFTInfo, mintAccount *rpc.Account) {
+	metadataURI, err := f.resolveMetadataURI(ctx, info, mintAccount)
 	if err != nil {
 		// Log warning but continue - some NFTs might not have standard metadata
-		fmt.Printf("⚠️  Could not find metadata URI for %s: %v\n", mintAddress.String(), err)
+		fmt.Printf("⚠️  Could not find metadata URI for %s: %v\n", info.MintAddress.String(), err)
 	} else if metadataURI != "" {
 		info.MetadataURI = metadataURI
-		metadata, err := f.fetchOffChainMetadata(ctx, metadataURI)
-		if err != nil {
-			fmt.Printf("⚠️  Could not fetch off-chain metadata: %v\n", err)
-		} else {
-			info.Metadata = metadata
+		f.loadOffChainMetadata(ctx, info, metadataURI)
+	}
+}
+
+// notHeldError explains why owner is not among a mint's holders, naming
+// the wallet that holds it now
+func notHeldError(owner, mintAddress solanago.PublicKey, holders []solana.TokenHolder) error {
+	if len(holders) == 0 {
+		return fmt.Errorf("token account not found for mint %s: no wallet holds it", mintAddress.String())
+	}
+	return fmt.Errorf("token account not found for mint %s in wallet %s: it is currently owned by %s", mintAddress.String(), owner.String(), holders[0].Owner.String())
+}
+
+// readMint records the mint's supply, decimals and authorities on info and
+// rejects tokens that are not NFTs
+func readMint(info *NFTInfo, mintAccount *rpc.Account) error {
+	if err := recordMint(info, mintAccount); err != nil {
+		return err
+	}
+
+	// Validate this looks like an NFT (0 decimals is a strong indicator)
+	if info.Decimals != 0 {
+		return fmt.Errorf("this token has %d decimals - NFTs should have 0 decimals (sync --include-fungibles backs up other tokens)", info.Decimals)
+	}
+	return nil
+}
+
+// recordMint records the mint's supply, decimals and authorities on info
+func recordMint(info *NFTInfo, mintAccount *rpc.Account) error {
+	mint, err := ParseMint(mintAccount.Data.GetBinary())
+	if err != nil {
+		return fmt.Errorf("failed to parse mint account: %w", err)
+	}
+	info.Supply = mint.Supply
+	info.Decimals = mint.Decimals
+	info.MintAuthority = mint.MintAuthority
+	info.FreezeAuthority = mint.FreezeAuthority
+	return nil
+}
+
+// resolveMetadataURI records the mint's token program and finds its metadata
+// URI. Token-2022 mints may carry metadata in the token metadata extension,
+// either on the mint itself or on the account named by its metadata pointer;
+// everything else falls back to the Metaplex metadata account.
+func (f *Fetcher) resolveMetadataURI(ctx context.Context, info *NFTInfo, mintAccount *rpc.Account) (string, error) {
+	info.TokenProgram = mintAccount.Owner.String()
+	if !mintAccount.Owner.Equals(solanago.Token2022ProgramID) {
+		return f.metaplexMetadataURI(ctx, info)
+	}
+
+	extensions, err := ParseTokenExtensions(mintAccount.Data.GetBinary())
+	if err != nil {
+		fmt.Printf("⚠️  Could not parse Token-2022 extensions: %v\n", err)
+		return f.metaplexMetadataURI(ctx, info)
+	}
+	info.Extensions = extensions
+
+	if extensions.Metadata == nil && extensions.MetadataPointer != "" && extensions.MetadataPointer != info.MintAddress.String() {
+		if metadata, err := f.fetchPointedMetadata(ctx, extensions.MetadataPointer); err == nil {
+			extensions.Metadata = metadata
 		}
 	}
 
-	return info, nil
+	if extensions.Metadata != nil && extensions.Metadata.URI != "" {
+		fmt.Printf("   🧩 Token-2022 metadata: '%s'\n", extensions.Metadata.Name)
+		return extensions.Metadata.URI, nil
+	}
+
+	return f.metaplexMetadataURI(ctx, info)
+}
+
+// metaplexMetadataURI reads the Metaplex metadata account, recording its
+// royalty and creator details on info
+func (f *Fetcher) metaplexMetadataURI(ctx context.Context, info *NFTInfo) (string, error) {
+	onChain, err := f.findOnChainMetadata(ctx, info.MintAddress)
+	if err != nil {
+		return "", err
+	}
+	info.OnChain = onChain
+	return onChain.URI, nil
+}
+
+// fetchPointedMetadata reads token metadata from an account referenced by a
+// metadata pointer. The account is either another Token-2022 mint carrying
+// the metadata extension or a bare token metadata record.
+func (f *Fetcher) fetchPointedMetadata(ctx context.Context, address string) (*TokenMetadata, error) {
+	pubkey, err := solanago.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata pointer %s: %w", address, err)
+	}
+	account, err := f.client.GetAccountInfo(ctx, pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	data := account.Data.GetBinary()
+	if account.Owner.Equals(solanago.Token2022ProgramID) {
+		extensions, err := ParseTokenExtensions(data)
+		if err != nil {
+			return nil, err
+		}
+		if extensions.Metadata == nil {
+			return nil, fmt.Errorf("account %s has no token metadata", address)
+		}
+		return extensions.Metadata, nil
+	}
+	return ParseTokenMetadata(data)
 }
 
-// findMetadataURI attempts to find the metadata URI for an NFT
-func (f *Fetcher) findMetadataURI(ctx context.Context, mintAddress solanago.PublicKey) (string, error) {
+// findOnChainMetadata attempts to find the Metaplex metadata for an NFT
+func (f *Fetcher) findOnChainMetadata(ctx context.Context, mintAddress solanago.PublicKey) (*OnChainMetadata, error) {
 	// This is a simplified approach. In a full implementation, you would:
 	// 1. Derive the metadata account address using Metaplex program
 	// 2. Fetch the metadata account data
@@ -201,29 +371,24 @@ func (f *Fetcher) findMetadataURI(ctx context.Context, mintAddress solanago.Publ
 	// The actual implementation would use proper PDA derivation
 	metadataPubkey, err := f.deriveMetadataAddress(mintAddress)
 	if err != nil {
-		return "", fmt.Errorf("failed to derive metadata address: %w", err)
+		return nil, fmt.Errorf("failed to derive metadata address: %w", err)
 	}
 
 	account, err := f.client.GetAccountInfo(ctx, metadataPubkey)
 	if err != nil {
-		return "", fmt.Errorf("metadata account not found: %w", err)
+		return nil, fmt.Errorf("metadata account not found: %w", err)
 	}
 
-	// Parse metadata account data (simplified)
-	// In practice, you'd use proper Metaplex metadata deserialization
-	uri, err := f.parseMetadataURI(account.Data.GetBinary())
+	onChain, err := f.parseMetadataAccount(account.Data.GetBinary())
 	if err != nil {
-		return "", fmt.Errorf("failed to parse metadata URI: %w", err)
+		return nil, fmt.Errorf("failed to parse metadata account: %w", err)
 	}
 
-	return uri, nil
+	return onChain, nil
 }
 
 // deriveMetadataAddress derives the metadata account address for a mint
 func (f *Fetcher) deriveMetadataAddress(mintAddress solanago.PublicKey) (solanago.PublicKey, error) {
-	// Metaplex metadata program ID
-	metaplexProgramID := solanago.MustPublicKeyFromBase58("metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s")
-
 	seeds := [][]byte{
 		[]byte("metadata"),
 		metaplexProgramID.Bytes(),
@@ -236,13 +401,16 @@ func (f *Fetcher) deriveMetadataAddress(mintAddress solanago.PublicKey) (solanag
 	}
 
 	return pda, nil
-} // parseMetadataURI extracts the metadata URI from metadata account data
-func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
+}
+
+// parseMetadataAccount decodes a Metaplex metadata account: name, symbol,
+// URI, seller fee and creators
+func (f *Fetcher) parseMetadataAccount(data []byte) (*OnChainMetadata, error) {
 	// Enhanced parser for Metaplex metadata accounts
 	// Based on the Metaplex Token Metadata standard
 
 	if len(data) < 100 {
-		return "", fmt.Errorf("metadata account data too short: %d bytes", len(data))
+		return nil, fmt.Errorf("metadata account data too short: %d bytes", len(data))
 	}
 
 	fmt.Println("\n🔬 Analyzing Metaplex Metadata Account:")
@@ -253,14 +421,14 @@ func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
 		fmt.Println(" ✅ (Valid Metadata Account)")
 	} else {
 		fmt.Printf(" ❌ (Expected 4, got %d)\n", data[0])
-		return "", fmt.Errorf("not a valid metadata account (key = %d, expected 4)", data[0])
+		return nil, fmt.Errorf("not a valid metadata account (key = %d, expected 4)", data[0])
 	}
 
 	// Skip update authority (32 bytes) and mint (32 bytes)
 	offset := 65
 
 	if offset+4 > len(data) {
-		return "", fmt.Errorf("data too short for name length")
+		return nil, fmt.Errorf("data too short for name length")
 	}
 
 	// Read name length (little endian u32)
@@ -269,12 +437,12 @@ func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
 	offset += 4
 
 	if nameLength > 200 {
-		return "", fmt.Errorf("name length too large: %d", nameLength)
+		return nil, fmt.Errorf("name length too large: %d", nameLength)
 	}
 
 	// Skip name
 	if offset+int(nameLength) > len(data) {
-		return "", fmt.Errorf("data too short for name")
+		return nil, fmt.Errorf("data too short for name")
 	}
 	name := string(data[offset : offset+int(nameLength)])
 	fmt.Printf("   🏷️  Name: '%s'\n", name)
@@ -282,19 +450,19 @@ func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
 
 	// Read symbol length
 	if offset+4 > len(data) {
-		return "", fmt.Errorf("data too short for symbol length")
+		return nil, fmt.Errorf("data too short for symbol length")
 	}
 	symbolLength := uint32(data[offset]) | uint32(data[offset+1])<<8 |
 		uint32(data[offset+2])<<16 | uint32(data[offset+3])<<24
 	offset += 4
 
 	if symbolLength > 200 {
-		return "", fmt.Errorf("symbol length too large: %d", symbolLength)
+		return nil, fmt.Errorf("symbol length too large: %d", symbolLength)
 	}
 
 	// Skip symbol
 	if offset+int(symbolLength) > len(data) {
-		return "", fmt.Errorf("data too short for symbol")
+		return nil, fmt.Errorf("data too short for symbol")
 	}
 	symbol := string(data[offset : offset+int(symbolLength)])
 	fmt.Printf("   🔖 Symbol: '%s'\n", symbol)
@@ -302,19 +470,19 @@ func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
 
 	// Read URI length
 	if offset+4 > len(data) {
-		return "", fmt.Errorf("data too short for URI length")
+		return nil, fmt.Errorf("data too short for URI length")
 	}
 	uriLength := uint32(data[offset]) | uint32(data[offset+1])<<8 |
 		uint32(data[offset+2])<<16 | uint32(data[offset+3])<<24
 	offset += 4
 
 	if uriLength > 1000 {
-		return "", fmt.Errorf("URI length too large: %d", uriLength)
+		return nil, fmt.Errorf("URI length too large: %d", uriLength)
 	}
 
 	// Extract URI
 	if offset+int(uriLength) > len(data) {
-		return "", fmt.Errorf("data too short for URI")
+		return nil, fmt.Errorf("data too short for URI")
 	}
 
 	uri := string(data[offset : offset+int(uriLength)])
@@ -332,49 +500,116 @@ func (f *Fetcher) parseMetadataURI(data []byte) (string, error) {
 
 	// Validate URI format
 	if len(uri) < 5 {
-		return "", fmt.Errorf("URI too short: '%s'", uri)
+		return nil, fmt.Errorf("URI too short: '%s'", uri)
 	}
 
 	// Check for common URI prefixes
-	if uri[:4] == "http" || uri[:2] == "ar" || uri[:4] == "ipfs" {
-		return uri, nil
+	if uri[:4] != "http" && uri[:2] != "ar" && uri[:4] != "ipfs" {
+		return nil, fmt.Errorf("URI format not recognized: '%s'", uri)
+	}
+
+	onChain := &OnChainMetadata{
+		Name:   strings.TrimRight(name, "\x00"),
+		Symbol: strings.TrimRight(symbol, "\x00"),
+		URI:    uri,
+	}
+
+	// Royalties follow the URI: seller_fee_basis_points (u16), then an
+	// optional list of creators (32-byte address, verified flag, share)
+	// Explanation: Older or truncated accounts may stop early, which is not
+	// an error for backup purposes; we keep whatever was readable
+	offset += int(uriLength)
+	if offset+2 > len(data) {
+		return onChain, nil
+	}
+	onChain.SellerFeeBasisPoints = int(binary.LittleEndian.Uint16(data[offset:]))
+	offset += 2
+
+	if offset >= len(data) || data[offset] == 0 {
+		return onChain, nil
 	}
+	offset++
+	if offset+4 > len(data) {
+		return onChain, nil
+	}
+	count := int(binary.LittleEndian.Uint32(data[offset:]))
+	offset += 4
+	if count > maxCreators {
+		return nil, fmt.Errorf("too many creators: %d", count)
+	}
+	for i := 0; i < count && offset+34 <= len(data); i++ {
+		onChain.Creators = append(onChain.Creators, Creator{
+			Address:  solanago.PublicKeyFromBytes(data[offset : offset+32]).String(),
+			Verified: data[offset+32] == 1,
+			Share:    int(data[offset+33]),
+		})
+		offset += 34
+	}
+	fmt.Printf("   💰 Royalties: %d bps, %d creator(s)\n", onChain.SellerFeeBasisPoints, len(onChain.Creators))
 
-	return "", fmt.Errorf("URI format not recognized: '%s'", uri)
+	return onChain, nil
 }
 
-// fetchOffChainMetadata retrieves and parses metadata from a URI (Arweave, IPFS, HTTP)
-func (f *Fetcher) fetchOffChainMetadata(ctx context.Context, uri string) (*NFTMetadata, error) {
+// fetchOffChainMetadata retrieves and parses metadata from a URI (Arweave,
+// IPFS, HTTP). It also returns the body as served.
+func (f *Fetcher) fetchOffChainMetadata(ctx context.Context, uri string) (*NFTMetadata, []byte, error) {
+	ctx, span := telemetry.StartClient(ctx, "metadata fetch", attribute.String("url.full", uri))
+	metadata, body, err := f.fetchMetadataDocument(ctx, uri)
+	span.SetAttributes(attribute.Int("http.response.body.size", len(body)))
+	telemetry.End(span, err)
+	return metadata, body, err
+}
+
+// fetchMetadataDocument makes the request for fetchOffChainMetadata
+func (f *Fetcher) fetchMetadataDocument(ctx context.Context, uri string) (*NFTMetadata, []byte, error) {
 	fmt.Printf("   📡 Fetching off-chain metadata from: %s\n", f.getTruncatedURI(uri))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers for better compatibility with Arweave and IPFS gateways
 	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 
+	var cached *cachedMetadata
+	if f.metadataCache != nil {
+		if err := f.metadataCache.wait(ctx, uri); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch metadata: %w", err)
+		}
+		cached = f.metadataCache.prepare(req, uri)
+	}
+
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch metadata: %w", err)
 	}
 	defer resp.Body.Close()
 
 	fmt.Printf("   📊 Response: %d %s\n", resp.StatusCode, resp.Status)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error %d fetching metadata", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		f.metadataCache.count(true)
+		body = cached.Body
+		fmt.Printf("   ♻️  Metadata unchanged since %s, using the cached copy\n", cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("HTTP error %d fetching metadata", resp.StatusCode)
+	default:
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		fmt.Printf("   📄 Metadata size: %d bytes\n", len(body))
+		if f.metadataCache != nil {
+			// A cache that cannot be written only costs bandwidth next time
+			if err := f.metadataCache.store(uri, resp, body); err != nil {
+				fmt.Printf("   ⚠️  %v\n", err)
+			}
+		}
 	}
 
-	fmt.Printf("   📄 Metadata size: %d bytes\n", len(body))
-
 	// Try to parse as standard NFT metadata first
 	var metadata NFTMetadata
 	if err := json.Unmarshal(body, &metadata); err != nil {
@@ -383,13 +618,60 @@ func (f *Fetcher) fetchOffChainMetadata(ctx context.Context, uri string) (*NFTMe
 
 		flexibleMetadata, flexErr := f.parseFlexibleMetadata(body)
 		if flexErr != nil {
-			return nil, fmt.Errorf("failed to parse metadata JSON (standard: %v, flexible: %v)", err, flexErr)
+			return nil, nil, fmt.Errorf("failed to parse metadata JSON (standard: %v, flexible: %v)", err, flexErr)
 		}
 		metadata = *flexibleMetadata
 	}
 
 	fmt.Printf("   ✅ Successfully parsed metadata for: '%s'\n", metadata.Name)
-	return &metadata, nil
+	return &metadata, body, nil
+}
+
+// loadOffChainMetadata fetches the metadata at uri onto info and, when a
+// cross-check is configured, compares it with an independent copy
+func (f *Fetcher) loadOffChainMetadata(ctx context.Context, info *NFTInfo, uri string) {
+	source := f.rewrites.Metadata(info.MintAddress.String(), uri)
+	if source != uri {
+		fmt.Printf("   🔀 Rewriting metadata URI to %s\n", f.getTruncatedURI(source))
+	}
+	metadata, raw, err := f.fetchOffChainMetadata(ctx, source)
+	if err != nil {
+		fmt.Printf("⚠️  Could not fetch off-chain metadata: %v\n", err)
+		return
+	}
+	attachMetadata(info, metadata, raw)
+	info.MetadataSource = ""
+	if source != uri {
+		info.MetadataSource = source
+	}
+	if f.crossCheck == nil {
+		return
+	}
+
+	info.MetadataCheck = f.crossCheckMetadata(ctx, uri, raw)
+	switch info.MetadataCheck.Status {
+	case CheckMatch:
+		fmt.Printf("   🤝 Metadata matches the copy at %s\n", f.getTruncatedURI(info.MetadataCheck.URL))
+	case CheckMismatch:
+		fmt.Printf("   🚨 Metadata differs from the copy at %s: one of the gateways may be serving tampered content\n", f.getTruncatedURI(info.MetadataCheck.URL))
+	default:
+		fmt.Printf("   ⚠️  Metadata not cross-checked: %s\n", info.MetadataCheck.Error)
+	}
+}
+
+// attachMetadata records off-chain metadata on info with the bytes it was
+// parsed from and their canonical hash
+//
+// Explanation: Gateways reformat JSON freely, so the hash is taken over the
+// canonical form; the same metadata served with different whitespace or key
+// order keeps its hash. A body that is not canonicalizable gets no hash.
+func attachMetadata(info *NFTInfo, metadata *NFTMetadata, raw []byte) {
+	info.Metadata = metadata
+	info.RawMetadata = raw
+	info.MetadataHash = ""
+	if hash, err := canonjson.Hash(raw); err == nil {
+		info.MetadataHash = hash
+	}
 }
 
 // getTruncatedURI returns a truncated version of URI for display
@@ -543,22 +825,8 @@ func (f *Fetcher) FetchNFTInfoDemo(ctx context.Context, mintAddress solanago.Pub
 		return nil, fmt.Errorf("failed to get mint account info: %w", err)
 	}
 
-	// Parse mint data to get supply and decimals
-	if len(mintAccount.Data.GetBinary()) >= 44 {
-		data := mintAccount.Data.GetBinary()
-
-		// Extract decimals from mint account (byte 44)
-		if len(data) > 44 {
-			info.Decimals = data[44]
-		}
-
-		// For now, assume supply of 1 for NFTs - in production you'd properly parse this
-		info.Supply = 1
-
-		// Validate this looks like an NFT (0 decimals is a strong indicator)
-		if info.Decimals != 0 {
-			return nil, fmt.Errorf("this token has %d decimals - NFTs should have 0 decimals", info.Decimals)
-		}
+	if err := readMint(info, mintAccount); err != nil {
+		return nil, err
 	}
 
 	// Set demo owner (we don't check actual ownership for demo)
@@ -567,62 +835,137 @@ func (f *Fetcher) FetchNFTInfoDemo(ctx context.Context, mintAddress solanago.Pub
 	info.TokenAccount = demoWallet // Dummy token account for demo
 
 	// Try to find and fetch metadata
-	metadataURI, err := f.findMetadataURI(ctx, mintAddress)
+	metadataURI, err := f.resolveMetadataURI(ctx, info, mintAccount)
 	if err != nil {
 		fmt.Printf("⚠️  Could not find metadata URI for %s: %v\n", mintAddress.String(), err)
 	} else if metadataURI != "" {
 		info.MetadataURI = metadataURI
-		metadata, err := f.fetchOffChainMetadata(ctx, metadataURI)
-		if err != nil {
-			fmt.Printf("⚠️  Could not fetch off-chain metadata: %v\n", err)
-		} else {
-			info.Metadata = metadata
-		}
+		f.loadOffChainMetadata(ctx, info, metadataURI)
 	}
 
 	return info, nil
 }
 
-// DownloadMediaFiles downloads all media files associated with an NFT
+// MediaURLs lists the media a backup downloads for metadata: the image,
+// the animation and every properties.files URI, without duplicates
+func MediaURLs(metadata *NFTMetadata) []string {
+	if metadata == nil {
+		return nil
+	}
+	candidates := []string{metadata.Image, metadata.AnimationURL}
+	for _, file := range metadata.Properties.Files {
+		candidates = append(candidates, file.URI)
+	}
+
+	seen := make(map[string]bool)
+	var mediaURLs []string
+	for _, mediaURL := range candidates {
+		mediaURL = strings.TrimSpace(mediaURL)
+		if mediaURL != "" && !seen[mediaURL] {
+			seen[mediaURL] = true
+			mediaURLs = append(mediaURLs, mediaURL)
+		}
+	}
+	return mediaURLs
+}
+
+// DownloadMediaFiles downloads all media files associated with an NFT,
+// several at a time. A URI listed more than once (the image is usually
+// repeated in properties.files) is downloaded once.
 func (f *Fetcher) DownloadMediaFiles(ctx context.Context, nftInfo *NFTInfo, mediaDir string) error {
 	if nftInfo.Metadata == nil {
 		return nil // No metadata, no media to download
 	}
 
-	var mediaURLs []string
+	mediaURLs := MediaURLs(nftInfo.Metadata)
+
+	// Explanation: Downloads are network bound, so a few run at once. Each
+	// result keeps its URL's slot so MediaFiles stays in metadata order.
+	results := make([]*MediaFile, len(mediaURLs))
+	slots := make(chan struct{}, max(f.mediaConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, mediaURL := range mediaURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return // Interrupted while waiting: start nothing new
+			}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
 
-	// Collect media URLs from metadata
-	if nftInfo.Metadata.Image != "" {
-		mediaURLs = append(mediaURLs, nftInfo.Metadata.Image)
-	}
-	if nftInfo.Metadata.AnimationURL != "" {
-		mediaURLs = append(mediaURLs, nftInfo.Metadata.AnimationURL)
+			source := f.rewrites.Rewrite(mediaURL)
+			mediaFile, err := f.mediaDownloader.DownloadMedia(ctx, source, mediaDir)
+			if err != nil {
+				fmt.Printf("⚠️  Failed to download media %s: %v\n", source, err)
+				return // Skip failed downloads but continue with others
+			}
+			if source != mediaURL {
+				// The backup records the URI the metadata names
+				mediaFile.URL, mediaFile.FetchedFrom = mediaURL, source
+			}
+			results[i] = mediaFile
+			fmt.Printf("✅ Downloaded media: %s (%s, %d bytes)\n",
+				mediaFile.Filename, mediaFile.MediaType, mediaFile.Size)
+			if mediaFile.TypeMismatch {
+				fmt.Printf("⚠️  %s was served as %s but looks like %s\n",
+					mediaFile.Filename, describeType(mediaFile.ContentType), mediaFile.DetectedType)
+			}
+		}()
 	}
+	wg.Wait()
 
-	// Collect URLs from properties.files array
-	if nftInfo.Metadata.Properties.Files != nil {
-		for _, file := range nftInfo.Metadata.Properties.Files {
-			if file.URI != "" {
-				mediaURLs = append(mediaURLs, file.URI)
-			}
+	// Add to NFT info
+	for _, mediaFile := range results {
+		if mediaFile != nil {
+			nftInfo.MediaFiles = append(nftInfo.MediaFiles, mediaFile)
 		}
 	}
 
-	// Download each media file
-	for _, mediaURL := range mediaURLs {
-		mediaFile, err := f.mediaDownloader.DownloadMedia(ctx, mediaURL, mediaDir)
-		if err != nil {
-			fmt.Printf("⚠️  Failed to download media %s: %v\n", mediaURL, err)
-			continue // Skip failed downloads but continue with others
-		}
+	// Files skipped because of an interrupt are not failures of their own,
+	// but the media set is incomplete
+	return ctx.Err()
+}
 
-		// Add to NFT info
-		nftInfo.MediaFiles = append(nftInfo.MediaFiles, mediaFile)
-		fmt.Printf("✅ Downloaded media: %s (%s, %d bytes)\n",
-			mediaFile.Filename, mediaFile.MediaType, mediaFile.Size)
-	}
+// SetHTTPOptions routes metadata and media requests through opts: a proxy,
+// per-host timeouts and TLS settings
+func (f *Fetcher) SetHTTPOptions(opts HTTPOptions) {
+	f.httpClient.CloseIdleConnections()
+	f.httpClient = NewHTTPClient(opts, DefaultMetadataTimeout)
+	f.mediaDownloader.client.CloseIdleConnections()
+	f.mediaDownloader.client = NewHTTPClient(opts, DefaultMediaTimeout)
+}
 
-	return nil
+// SetMetadataCache makes off-chain metadata fetches conditional requests
+// answered from cache, spaced per host. nil turns the cache off.
+func (f *Fetcher) SetMetadataCache(cache *MetadataCache) {
+	f.metadataCache = cache
+}
+
+// SetCrossCheck has every off-chain metadata fetch compared with a copy from
+// an independent gateway. nil turns the check off.
+func (f *Fetcher) SetCrossCheck(check *CrossCheck) {
+	f.crossCheck = check
+}
+
+// SetURIRewrites sends metadata and media requests for dead hosts to the
+// mirrors in rewrites. nil fetches every URI as given.
+func (f *Fetcher) SetURIRewrites(rewrites *URIRewrites) {
+	f.rewrites = rewrites
+}
+
+// MetadataStats returns how the metadata fetches so far were answered
+func (f *Fetcher) MetadataStats() CacheStats {
+	return f.metadataCache.Stats()
+}
+
+// SetMediaConcurrency sets how many of an NFT's media files download at once
+func (f *Fetcher) SetMediaConcurrency(n int) {
+	f.mediaConcurrency = n
 }
 
 // Close cleans up the fetcher resources
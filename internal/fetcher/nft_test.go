@@ -0,0 +1,143 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// metaplexAccount encodes a Metaplex metadata account up to its creators
+func metaplexAccount(name, uri string, feeBps uint16, creators []Creator) []byte {
+	data := []byte{4}
+	data = append(data, make([]byte, 64)...) // update authority + mint
+	data = append(data, borshString(name+"\x00\x00")...)
+	data = append(data, borshString("SYM")...)
+	data = append(data, borshString(uri+"\x00\x00\x00")...)
+	data = binary.LittleEndian.AppendUint16(data, feeBps)
+	if creators == nil {
+		return append(data, 0)
+	}
+	data = append(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(creators)))
+	for _, creator := range creators {
+		data = append(data, solanago.MustPublicKeyFromBase58(creator.Address).Bytes()...)
+		verified := byte(0)
+		if creator.Verified {
+			verified = 1
+		}
+		data = append(data, verified, byte(creator.Share))
+	}
+	return data
+}
+
+func TestParseMetadataAccount(t *testing.T) {
+	artist := solanago.NewWallet().PublicKey().String()
+	label := solanago.NewWallet().PublicKey().String()
+	creators := []Creator{
+		{Address: artist, Share: 80, Verified: true},
+		{Address: label, Share: 20},
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantFee      int
+		wantCreators []Creator
+		wantErr      bool
+	}{
+		{"with creators", metaplexAccount("Lion #1", "https://arweave.net/abc", 500, creators), 500, creators, false},
+		{"no creators", metaplexAccount("Lion #2", "https://arweave.net/def", 0, nil), 0, nil, false},
+		{"stops after uri", metaplexAccount("Lion #3", "ipfs://ghi", 250, nil)[:102], 0, nil, false},
+		{"bad account key", append([]byte{9}, make([]byte, 120)...), 0, nil, true},
+	}
+
+	f := &Fetcher{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onChain, err := f.parseMetadataAccount(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse metadata account: %v", err)
+			}
+			if onChain.SellerFeeBasisPoints != tt.wantFee {
+				t.Errorf("SellerFeeBasisPoints = %d, want %d", onChain.SellerFeeBasisPoints, tt.wantFee)
+			}
+			if len(onChain.Creators) != len(tt.wantCreators) {
+				t.Fatalf("Got %d creators, want %d", len(onChain.Creators), len(tt.wantCreators))
+			}
+			for i, creator := range onChain.Creators {
+				if creator != tt.wantCreators[i] {
+					t.Errorf("Creator %d = %+v, want %+v", i, creator, tt.wantCreators[i])
+				}
+			}
+			if onChain.Name == "" || onChain.Name[len(onChain.Name)-1] == 0 {
+				t.Errorf("Name padding not trimmed: %q", onChain.Name)
+			}
+		})
+	}
+}
+
+func TestCreatorMismatches(t *testing.T) {
+	onChain := []Creator{{Address: "A", Share: 70, Verified: true}, {Address: "B", Share: 30}}
+
+	tests := []struct {
+		name     string
+		offChain []Creator
+		want     int
+	}{
+		{"no off-chain creators", nil, 0},
+		{"matching", []Creator{{Address: "B", Share: 30}, {Address: "A", Share: 70}}, 0},
+		{"share differs", []Creator{{Address: "A", Share: 50}, {Address: "B", Share: 30}}, 1},
+		{"extra and missing creator", []Creator{{Address: "A", Share: 70}, {Address: "C", Share: 30}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreatorMismatches(onChain, tt.offChain); len(got) != tt.want {
+				t.Errorf("Got %d mismatches %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchOffChainMetadata_KeepsRawBody(t *testing.T) {
+	bodies := []string{
+		`{"name":"Lion #1","attributes":[{"trait_type":"Mane","value":"Gold"}],"seller_fee_basis_points":500}`,
+		"{\n  \"seller_fee_basis_points\" : 500.0,\n  \"attributes\" : [ { \"value\" : \"Gold\", \"trait_type\" : \"Mane\" } ],\n  \"name\" : \"Lion \\u00231\"\n}\n",
+	}
+
+	var hashes []string
+	for _, body := range bodies {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		f := NewFetcher(nil)
+		metadata, raw, err := f.fetchOffChainMetadata(context.Background(), server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Failed to fetch metadata: %v", err)
+		}
+		if string(raw) != body {
+			t.Errorf("Got raw body %q, want %q", raw, body)
+		}
+
+		info := &NFTInfo{}
+		attachMetadata(info, metadata, raw)
+		if info.Metadata.Name != "Lion #1" || info.MetadataHash == "" {
+			t.Fatalf("Got metadata %+v with hash %q", info.Metadata, info.MetadataHash)
+		}
+		hashes = append(hashes, info.MetadataHash)
+	}
+	if hashes[0] != hashes[1] {
+		t.Errorf("The same metadata served two ways hashed differently: %v", hashes)
+	}
+}
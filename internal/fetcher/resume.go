@@ -0,0 +1,138 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fsutil"
+)
+
+// errInterrupted marks a download that stopped partway and can be resumed
+var errInterrupted = errors.New("download interrupted")
+
+// partManifest describes a partial download so a later attempt can check
+// it is resuming the same file
+type partManifest struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Total        int64  `json:"total"` // -1 when the server did not say
+}
+
+// validator returns the If-Range value proving the file is unchanged.
+// Weak ETags may not be used with If-Range.
+func (m *partManifest) validator() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// partFile is a media download in progress: a hidden .part file next to
+// the finished media, and a manifest recording where it came from.
+//
+// Explanation: The final filename depends on the response's Content-Type,
+// so the partial file is named after a hash of the URL instead. Hidden
+// names keep it out of encryption and compression until it is complete.
+type partFile struct {
+	path     string
+	manifest *partManifest // Nil when there is nothing to resume
+}
+
+func newPartFile(targetDir, mediaURL string) *partFile {
+	sum := sha256.Sum256([]byte(mediaURL))
+	part := &partFile{path: filepath.Join(targetDir, fmt.Sprintf(".%x.part", sum[:8]))}
+
+	var manifest partManifest
+	if data, err := os.ReadFile(part.manifestPath()); err == nil && json.Unmarshal(data, &manifest) == nil && manifest.URL == mediaURL {
+		part.manifest = &manifest
+	}
+	return part
+}
+
+func (p *partFile) manifestPath() string {
+	return p.path + ".json"
+}
+
+// resumeOffset returns how many bytes an earlier attempt saved
+func (p *partFile) resumeOffset() int64 {
+	if p.manifest == nil {
+		return 0
+	}
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// open returns the partial file positioned at offset, feeding the bytes
+// already on disk to hash. At offset 0 the file is started afresh.
+func (p *partFile) open(offset int64, hash hash.Hash, manifest partManifest) (*os.File, error) {
+	if offset == 0 {
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode download manifest: %w", err)
+		}
+		if err := os.WriteFile(p.manifestPath(), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write download manifest: %w", fsutil.WrapPathError(p.manifestPath(), err))
+		}
+		file, err := os.Create(p.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file %s: %w", p.path, fsutil.WrapPathError(p.path, err))
+		}
+		return file, nil
+	}
+
+	file, err := os.OpenFile(p.path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open partial download: %w", err)
+	}
+	if _, err := io.CopyN(hash, file, offset); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read partial download: %w", err)
+	}
+	return file, nil
+}
+
+// commit moves the finished download into place
+func (p *partFile) commit(localPath string) error {
+	if err := os.Rename(p.path, localPath); err != nil {
+		return fmt.Errorf("failed to save media file %s: %w", localPath, fsutil.WrapPathError(localPath, err))
+	}
+	os.Remove(p.manifestPath())
+	return nil
+}
+
+// remove discards the partial download
+func (p *partFile) remove() {
+	os.Remove(p.path)
+	os.Remove(p.manifestPath())
+}
+
+// contentRangeStart returns the first byte of a 206 response, or -1
+func contentRangeStart(resp *http.Response) int64 {
+	// Content-Range: bytes 100-199/200
+	value, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return -1
+	}
+	start, _, ok := strings.Cut(value, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,102 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer serves content with range support, cutting the first
+// response off after half the body
+type flakyServer struct {
+	mu      sync.Mutex
+	content []byte
+	etag    string
+	cut     bool     // Cut the next response short
+	ranges  []string // Range header of every request
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	content, etag, cut := s.content, s.etag, s.cut
+	s.cut = false
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("ETag", etag)
+	if cut {
+		w.Header().Set("Content-Length", "1000")
+		w.Write(content[:500])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
+
+func TestDownloadMedia_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	changed := bytes.Repeat([]byte("abcdefghij"), 100)
+
+	tests := []struct {
+		name        string
+		maxAttempts int    // 1 leaves the partial file for a second run
+		newETag     string // The file changes between attempts
+		want        []byte
+		wantRange   string // Range header of the second request
+	}{
+		{"retry resumes", 3, "", content, "bytes=500-"},
+		{"next run resumes", 1, "", content, "bytes=500-"},
+		{"changed file restarts", 1, `"v2"`, changed, "bytes=500-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &flakyServer{content: content, etag: `"v1"`, cut: true}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+			dir := t.TempDir()
+
+			downloader := NewMediaDownloader()
+			downloader.maxAttempts, downloader.retryDelay = tt.maxAttempts, time.Millisecond
+			mediaFile, err := downloader.DownloadMedia(context.Background(), ts.URL+"/clip.mp4", dir)
+			if tt.maxAttempts == 1 {
+				if err == nil {
+					t.Fatalf("Expected the cut-off download to fail")
+				}
+				if tt.newETag != "" {
+					server.content, server.etag = changed, tt.newETag
+				}
+				mediaFile, err = downloader.DownloadMedia(context.Background(), ts.URL+"/clip.mp4", dir)
+			}
+			if err != nil {
+				t.Fatalf("Failed to download media: %v", err)
+			}
+
+			data, err := os.ReadFile(mediaFile.LocalPath)
+			if err != nil {
+				t.Fatalf("Failed to read media: %v", err)
+			}
+			if !bytes.Equal(data, tt.want) || mediaFile.Size != int64(len(tt.want)) {
+				t.Errorf("Got %d bytes starting %q, want %d", len(data), data[:10], len(tt.want))
+			}
+			if len(server.ranges) != 2 || server.ranges[1] != tt.wantRange {
+				t.Errorf("Requests had ranges %q, want a second request for %q", server.ranges, tt.wantRange)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), ".") {
+					t.Errorf("Partial file %s left behind", filepath.Join(dir, entry.Name()))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// URIRewrites sends metadata and media requests somewhere other than the
+// URI an NFT names, for hosts that have gone away
+//
+// Explanation: The on-chain URI is what the NFT is, so backups keep
+// recording it as MetadataURI and MediaFile.URL; only the request goes
+// elsewhere, and where it went is recorded alongside. Removing a rewrite
+// later, once the original host is back, does not look like a metadata
+// change.
+type URIRewrites struct {
+	mints    map[string]string // Mint address to the metadata URI fetched instead
+	hosts    []uriRewrite      // Host pattern to the base URL replacing scheme and host
+	prefixes []uriRewrite      // URL prefix to its replacement, longest first
+}
+
+type uriRewrite struct {
+	from string
+	to   string
+}
+
+// ParseURIRewrites parses from=to entries. from is one of:
+//
+//	a mint address          its metadata is fetched from to, a full URI
+//	a host, or *.host       requests to it go to the base URL to, path kept
+//	a URL prefix            URLs starting with it have it replaced by to
+//
+// For example shdw-drive.genesysgo.net=https://mirror.example.com, or
+// https://shdw-drive.genesysgo.net/OldAccount/=https://me.example.com/nfts/.
+func ParseURIRewrites(entries []string) (*URIRewrites, error) {
+	r := &URIRewrites{mints: make(map[string]string)}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(entry, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid URI rewrite %q: expected from=to, e.g. old.host.com=https://mirror.example.com", entry)
+		}
+		target, err := url.Parse(to)
+		if err != nil || target.Scheme == "" || (target.Host == "" && target.Opaque == "") {
+			return nil, fmt.Errorf("invalid URI rewrite %q: %q is not an absolute URL", entry, to)
+		}
+
+		switch {
+		case strings.Contains(from, "://"):
+			r.prefixes = append(r.prefixes, uriRewrite{from: from, to: to})
+		case isMintAddress(from):
+			r.mints[from] = to
+		case !strings.ContainsAny(from, "/:?#"):
+			r.hosts = append(r.hosts, uriRewrite{from: strings.ToLower(from), to: strings.TrimRight(to, "/")})
+		default:
+			return nil, fmt.Errorf("invalid URI rewrite %q: %q is not a mint address, host or URL prefix", entry, from)
+		}
+	}
+	// The most specific prefix wins
+	sort.SliceStable(r.prefixes, func(i, j int) bool { return len(r.prefixes[i].from) > len(r.prefixes[j].from) })
+	return r, nil
+}
+
+// URIRewritesFromEnv reads URI_REWRITES, a comma-separated list of from=to
+// entries (see ParseURIRewrites). It returns nil when none are set.
+func URIRewritesFromEnv() (*URIRewrites, error) {
+	value := strings.TrimSpace(os.Getenv("URI_REWRITES"))
+	if value == "" {
+		return nil, nil
+	}
+	rewrites, err := ParseURIRewrites(strings.Split(value, ","))
+	if err != nil {
+		return nil, fmt.Errorf("URI_REWRITES: %w", err)
+	}
+	return rewrites, nil
+}
+
+// isMintAddress reports whether s is a Solana address rather than a host
+func isMintAddress(s string) bool {
+	if strings.Contains(s, ".") {
+		return false
+	}
+	_, err := solanago.PublicKeyFromBase58(s)
+	return err == nil
+}
+
+// Metadata returns where to fetch mint's off-chain metadata from: the
+// mint's override, else uri with the host and prefix rules applied. A nil
+// URIRewrites returns uri.
+func (r *URIRewrites) Metadata(mint, uri string) string {
+	if r == nil {
+		return uri
+	}
+	if override, ok := r.mints[mint]; ok {
+		return override
+	}
+	return r.Rewrite(uri)
+}
+
+// Rewrite applies the host and prefix rules to uri. A nil URIRewrites, or a
+// uri no rule matches, returns uri unchanged.
+func (r *URIRewrites) Rewrite(uri string) string {
+	if r == nil {
+		return uri
+	}
+	for _, rule := range r.prefixes {
+		if rest, ok := strings.CutPrefix(uri, rule.from); ok {
+			return rule.to + rest
+		}
+	}
+	if len(r.hosts) == 0 {
+		return uri
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Host == "" {
+		return uri
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, rule := range r.hosts {
+		if matchHost([]string{rule.from}, host) {
+			rest := *parsed
+			rest.Scheme, rest.User, rest.Host = "", nil, ""
+			return rule.to + rest.String()
+		}
+	}
+	return uri
+}
@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestURIRewrites(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey().String()
+	rewrites, err := ParseURIRewrites([]string{
+		"shdw-drive.genesysgo.net=https://mirror.example.com/",
+		"*.nftstorage.link=https://gateway.example.com",
+		"https://arweave.net/old/=https://me.example.com/reuploads/",
+		"https://arweave.net/=https://ar-io.net/",
+		mint + "=https://me.example.com/fixed.json",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse rewrites: %v", err)
+	}
+
+	tests := []struct {
+		mint string
+		uri  string
+		want string
+	}{
+		{"", "https://shdw-drive.genesysgo.net/Acct/1.json", "https://mirror.example.com/Acct/1.json"},
+		{"", "https://SHDW-DRIVE.genesysgo.net/Acct/1.json?v=2", "https://mirror.example.com/Acct/1.json?v=2"},
+		{"", "https://bafy.ipfs.nftstorage.link/1.png", "https://gateway.example.com/1.png"},
+		{"", "https://arweave.net/old/1.json", "https://me.example.com/reuploads/1.json"},
+		{"", "https://arweave.net/abc", "https://ar-io.net/abc"},
+		{"", "https://example.org/1.json", "https://example.org/1.json"},
+		{mint, "https://shdw-drive.genesysgo.net/Acct/1.json", "https://me.example.com/fixed.json"},
+		{solanago.NewWallet().PublicKey().String(), "https://example.org/1.json", "https://example.org/1.json"},
+	}
+	for _, test := range tests {
+		if got := rewrites.Metadata(test.mint, test.uri); got != test.want {
+			t.Errorf("Metadata(%q) = %q, want %q", test.uri, got, test.want)
+		}
+	}
+
+	var none *URIRewrites
+	if got := none.Rewrite("https://example.org/1.json"); got != "https://example.org/1.json" {
+		t.Errorf("nil rewrites changed the URI to %q", got)
+	}
+
+	for _, entry := range []string{"no-equals", "=https://x.example.com", "host.example.com=not a url", "host/path=https://x.example.com"} {
+		if _, err := ParseURIRewrites([]string{entry}); err == nil {
+			t.Errorf("ParseURIRewrites(%q): expected an error", entry)
+		}
+	}
+}
+
+func TestURIRewrites_Fetch(t *testing.T) {
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Acct/1.json":
+			fmt.Fprint(w, `{"name":"Rescued","image":"https://dead.example.com/Acct/1.png"}`)
+		case "/Acct/1.png":
+			w.Write([]byte(r.URL.Path))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer mirror.Close()
+
+	rewrites, err := ParseURIRewrites([]string{"dead.example.com=" + mirror.URL})
+	if err != nil {
+		t.Fatalf("Failed to parse rewrites: %v", err)
+	}
+	f := NewFetcher(nil)
+	f.SetURIRewrites(rewrites)
+
+	info := &NFTInfo{MintAddress: solanago.NewWallet().PublicKey(), MetadataURI: "https://dead.example.com/Acct/1.json"}
+	f.loadOffChainMetadata(context.Background(), info, info.MetadataURI)
+	if info.Metadata == nil || info.Metadata.Name != "Rescued" {
+		t.Fatalf("Metadata was not fetched from the mirror: %+v", info.Metadata)
+	}
+	if info.MetadataURI != "https://dead.example.com/Acct/1.json" || info.MetadataSource != mirror.URL+"/Acct/1.json" {
+		t.Errorf("Got URI %q fetched from %q, want the original recorded", info.MetadataURI, info.MetadataSource)
+	}
+
+	if err := f.DownloadMediaFiles(context.Background(), info, t.TempDir()); err != nil {
+		t.Fatalf("Failed to download media: %v", err)
+	}
+	if len(info.MediaFiles) != 1 {
+		t.Fatalf("Expected 1 media file from the mirror, got %d", len(info.MediaFiles))
+	}
+	if media := info.MediaFiles[0]; media.URL != "https://dead.example.com/Acct/1.png" || media.FetchedFrom != mirror.URL+"/Acct/1.png" {
+		t.Errorf("Got media URL %q fetched from %q, want the original recorded", media.URL, media.FetchedFrom)
+	}
+}
@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is how much of a file content sniffing reads
+const sniffLen = 512
+
+// errNotMedia marks a download whose bytes are an error page rather than
+// the media the server claimed to send
+var errNotMedia = errors.New("server returned a web page instead of media")
+
+// DetectContentType identifies a file from its first bytes. It extends
+// http.DetectContentType with formats common in NFT media that the
+// standard sniffer misses: SVG, AVIF, HEIC, QuickTime and binary glTF.
+func DetectContentType(head []byte) string {
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("glTF")):
+		return "model/gltf-binary"
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch brand := string(head[8:12]); brand {
+		case "avif", "avis":
+			return "image/avif"
+		case "heic", "heix", "mif1":
+			return "image/heic"
+		case "qt  ":
+			return "video/quicktime"
+		}
+	}
+
+	detected := http.DetectContentType(head)
+	if mediaType(detected) == "text/xml" || mediaType(detected) == "text/plain" {
+		if bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+			return "image/svg+xml"
+		}
+	}
+	return detected
+}
+
+// checkContentType compares the bytes of a download with what the server
+// claimed, for a file whose name or headers promise media of the expected
+// type. It returns errNotMedia for an HTML page posing as media, and
+// reports whether the detected type differs from a specific claimed type.
+func checkContentType(claimed, detected string, expected MediaType) (mismatch bool, err error) {
+	detected = mediaType(detected)
+	if detected == "text/html" && expected != MediaTypeHTML && expected != MediaTypeUnknown {
+		return true, errNotMedia
+	}
+
+	claimed = mediaType(claimed)
+	switch {
+	case claimed == "" || claimed == "application/octet-stream" || claimed == "binary/octet-stream":
+		return false, nil // Nothing specific was claimed
+	case detected == "application/octet-stream":
+		return false, nil // Unrecognised bytes; trust the server
+	case claimed == "image/jpg" && detected == "image/jpeg":
+		return false, nil
+	}
+	return claimed != detected, nil
+}
+
+// mediaType strips parameters such as charset from a content type
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return parsed
+}
+
+// genericContentType reports whether a server's content type says nothing
+// about the file
+func genericContentType(contentType string) bool {
+	switch mediaType(contentType) {
+	case "", "application/octet-stream", "binary/octet-stream":
+		return true
+	}
+	return false
+}
+
+// describeType names a content type for error messages
+func describeType(contentType string) string {
+	if contentType == "" {
+		return "no content type"
+	}
+	return mediaType(contentType)
+}
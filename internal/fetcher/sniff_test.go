@@ -0,0 +1,133 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+var (
+	pngHead   = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegHead  = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	errorHTML = []byte("<!DOCTYPE html><html><head><title>504 Gateway Time-out</title></head></html>")
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"png", pngHead, "image/png"},
+		{"jpeg", jpegHead, "image/jpeg"},
+		{"html error page", errorHTML, "text/html; charset=utf-8"},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1 1"></svg>`), "image/svg+xml"},
+		{"svg with xml declaration", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`), "image/svg+xml"},
+		{"binary gltf", []byte("glTF\x02\x00\x00\x00"), "model/gltf-binary"},
+		{"avif", []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00"), "image/avif"},
+		{"quicktime", []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), "video/quicktime"},
+		{"unknown", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContentType(tt.head); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	tests := []struct {
+		name         string
+		claimed      string
+		detected     string
+		expected     MediaType
+		wantMismatch bool
+		wantErr      bool
+	}{
+		{"match", "image/png", "image/png", MediaTypeImage, false, false},
+		{"charset ignored", "text/html; charset=UTF-8", "text/html; charset=utf-8", MediaTypeHTML, false, false},
+		{"error page as image", "image/png", "text/html; charset=utf-8", MediaTypeImage, true, true},
+		{"error page with html header", "text/html", "text/html; charset=utf-8", MediaTypeImage, true, true},
+		{"html animation", "text/html", "text/html; charset=utf-8", MediaTypeHTML, false, false},
+		{"unnamed html", "", "text/html; charset=utf-8", MediaTypeUnknown, false, false},
+		{"wrong image format", "image/png", "image/jpeg", MediaTypeImage, true, false},
+		{"jpg alias", "image/jpg", "image/jpeg", MediaTypeImage, false, false},
+		{"generic claim", "application/octet-stream", "image/gif", MediaTypeImage, false, false},
+		{"unrecognised bytes", "video/mp4", "application/octet-stream", MediaTypeVideo, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatch, err := checkContentType(tt.claimed, tt.detected, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Got error %v, want error %v", err, tt.wantErr)
+			}
+			if mismatch != tt.wantMismatch {
+				t.Errorf("Got mismatch %v, want %v", mismatch, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestDownloadMedia_Sniffing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken/image.png":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write(errorHTML)
+		case "/QmUnnamed":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(pngHead)
+		case "/photo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(jpegHead)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		path            string
+		wantErr         bool
+		wantFilename    string
+		wantContentType string
+		wantMismatch    bool
+	}{
+		{"error page rejected", "/broken/image.png", true, "", "", false},
+		{"generic type detected", "/QmUnnamed", false, "QmUnnamed.png", "image/png", false},
+		{"wrong format flagged", "/photo.png", false, "photo.png", "image/png", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			downloader := NewMediaDownloader()
+			media, err := downloader.DownloadMedia(context.Background(), server.URL+tt.path, dir)
+			if tt.wantErr {
+				if !errors.Is(err, errNotMedia) {
+					t.Fatalf("Got %v, want errNotMedia", err)
+				}
+				if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+					t.Errorf("Rejected download left %d files behind", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to download media: %v", err)
+			}
+			if media.Filename != tt.wantFilename || media.ContentType != tt.wantContentType || media.TypeMismatch != tt.wantMismatch {
+				t.Errorf("Got %s (%s, mismatch %v), want %s (%s, mismatch %v)",
+					media.Filename, media.ContentType, media.TypeMismatch, tt.wantFilename, tt.wantContentType, tt.wantMismatch)
+			}
+			if media.DetectedType == "" {
+				t.Error("Detected type not recorded")
+			}
+		})
+	}
+}
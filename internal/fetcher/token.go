@@ -0,0 +1,56 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// TokenStandard tells a fungible token from a semi-fungible one
+type TokenStandard string
+
+const (
+	TokenFungible     TokenStandard = "fungible" // Divisible, e.g. a currency
+	TokenSemiFungible TokenStandard = "sft"      // Indivisible, but more than one held
+)
+
+// TokenBalance is a fungible token or SFT held by a wallet: anything with
+// a balance that is not a single indivisible NFT
+type TokenBalance struct {
+	Mint         solanago.PublicKey
+	TokenAccount solanago.PublicKey
+	Amount       uint64 // In the smallest unit; summed over the wallet's accounts
+	Decimals     uint8
+}
+
+// Standard classifies the balance by its decimals
+func (b TokenBalance) Standard() TokenStandard {
+	if b.Decimals == 0 {
+		return TokenSemiFungible
+	}
+	return TokenFungible
+}
+
+// FetchTokenInfo retrieves the metadata of a fungible token or SFT the way
+// FetchNFTInfoForOwner does for an NFT, without requiring 0 decimals
+func (f *Fetcher) FetchTokenInfo(ctx context.Context, owner solanago.PublicKey, balance TokenBalance) (*NFTInfo, error) {
+	info := &NFTInfo{
+		MintAddress:  balance.Mint,
+		TokenAccount: balance.TokenAccount,
+		Owner:        owner,
+		FetchedAt:    time.Now(),
+	}
+
+	mintAccount, err := f.client.GetAccountInfo(ctx, balance.Mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account info: %w", err)
+	}
+	if err := recordMint(info, mintAccount); err != nil {
+		return nil, err
+	}
+
+	f.loadMetadata(ctx, info, mintAccount)
+	return info, nil
+}
@@ -0,0 +1,174 @@
+package fetcher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Token-2022 mint layout: the 82-byte base mint is padded to the 165-byte
+// token account size, followed by a one-byte account type and a list of
+// TLV-encoded extensions (u16 type, u16 length, value)
+const (
+	token2022BaseSize          = 165
+	token2022MintType          = 1
+	extensionPermanentDelegate = 12
+	extensionMetadataPtr       = 18
+	extensionTokenMetadata     = 19
+)
+
+// TokenMetadata is the on-chain metadata stored by the Token-2022 token
+// metadata extension
+type TokenMetadata struct {
+	UpdateAuthority    string            `json:"update_authority,omitempty"`
+	Mint               string            `json:"mint"`
+	Name               string            `json:"name"`
+	Symbol             string            `json:"symbol"`
+	URI                string            `json:"uri"`
+	AdditionalMetadata map[string]string `json:"additional_metadata,omitempty"`
+}
+
+// TokenExtensions holds the Token-2022 mint extensions relevant to NFTs
+type TokenExtensions struct {
+	// MetadataPointer is the account holding the mint's metadata, often the
+	// mint itself
+	MetadataPointer string `json:"metadata_pointer,omitempty"`
+	// PermanentDelegate can transfer or burn the token from any holder
+	PermanentDelegate string         `json:"permanent_delegate,omitempty"`
+	Metadata          *TokenMetadata `json:"metadata,omitempty"`
+}
+
+// ParseTokenExtensions decodes the extensions of a Token-2022 mint account.
+// Legacy mints without extensions return an empty result.
+func ParseTokenExtensions(data []byte) (*TokenExtensions, error) {
+	extensions := &TokenExtensions{}
+	if len(data) <= token2022BaseSize {
+		return extensions, nil
+	}
+	if data[token2022BaseSize] != token2022MintType {
+		return nil, fmt.Errorf("account type %d is not a mint", data[token2022BaseSize])
+	}
+
+	offset := token2022BaseSize + 1
+	for offset+4 <= len(data) {
+		extType := binary.LittleEndian.Uint16(data[offset:])
+		length := int(binary.LittleEndian.Uint16(data[offset+2:]))
+		offset += 4
+		if extType == 0 {
+			// Uninitialized padding ends the list
+			break
+		}
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("extension %d overruns account data", extType)
+		}
+		value := data[offset : offset+length]
+		offset += length
+
+		switch extType {
+		case extensionMetadataPtr:
+			// authority (32) followed by the metadata address (32)
+			if len(value) >= 64 {
+				extensions.MetadataPointer = optionalPubkey(value[32:64])
+			}
+		case extensionPermanentDelegate:
+			if len(value) >= 32 {
+				extensions.PermanentDelegate = optionalPubkey(value[:32])
+			}
+		case extensionTokenMetadata:
+			metadata, err := ParseTokenMetadata(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse token metadata: %w", err)
+			}
+			extensions.Metadata = metadata
+		}
+	}
+
+	return extensions, nil
+}
+
+// ParseTokenMetadata decodes a token metadata extension value: update
+// authority, mint, name, symbol, uri and a list of key/value pairs
+func ParseTokenMetadata(data []byte) (*TokenMetadata, error) {
+	if len(data) < 64 {
+		return nil, fmt.Errorf("token metadata too short: %d bytes", len(data))
+	}
+	r := borshReader{data: data, offset: 64}
+	metadata := &TokenMetadata{
+		UpdateAuthority: optionalPubkey(data[:32]),
+		Mint:            solanago.PublicKeyFromBytes(data[32:64]).String(),
+	}
+
+	var err error
+	if metadata.Name, err = r.string(); err != nil {
+		return nil, fmt.Errorf("failed to read name: %w", err)
+	}
+	if metadata.Symbol, err = r.string(); err != nil {
+		return nil, fmt.Errorf("failed to read symbol: %w", err)
+	}
+	if metadata.URI, err = r.string(); err != nil {
+		return nil, fmt.Errorf("failed to read uri: %w", err)
+	}
+	metadata.URI = strings.TrimSpace(strings.TrimRight(metadata.URI, "\x00"))
+
+	count, err := r.u32()
+	if err != nil {
+		// Older writers omit the additional metadata list entirely
+		return metadata, nil
+	}
+	if count > 0 {
+		metadata.AdditionalMetadata = make(map[string]string, count)
+	}
+	for i := uint32(0); i < count; i++ {
+		key, err := r.string()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read additional metadata key: %w", err)
+		}
+		value, err := r.string()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read additional metadata value: %w", err)
+		}
+		metadata.AdditionalMetadata[key] = value
+	}
+
+	return metadata, nil
+}
+
+// optionalPubkey returns the base58 key, or "" for the all-zero key Token-2022
+// uses to encode None
+func optionalPubkey(b []byte) string {
+	key := solanago.PublicKeyFromBytes(b)
+	if key.IsZero() {
+		return ""
+	}
+	return key.String()
+}
+
+// borshReader reads the length-prefixed strings used by the SPL programs
+type borshReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *borshReader) u32() (uint32, error) {
+	if r.offset+4 > len(r.data) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	v := binary.LittleEndian.Uint32(r.data[r.offset:])
+	r.offset += 4
+	return v, nil
+}
+
+func (r *borshReader) string() (string, error) {
+	n, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	if int(n) > len(r.data)-r.offset {
+		return "", fmt.Errorf("string length %d exceeds data", n)
+	}
+	s := string(r.data[r.offset : r.offset+int(n)])
+	r.offset += int(n)
+	return s, nil
+}
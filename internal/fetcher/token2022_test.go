@@ -0,0 +1,153 @@
+package fetcher
+
+import (
+	"encoding/binary"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// borshString encodes a u32 length-prefixed string
+func borshString(s string) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(s)))
+	return append(b, s...)
+}
+
+// tlv encodes a Token-2022 extension entry
+func tlv(extType uint16, value []byte) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, extType)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+func tokenMetadataValue(authority, mint solanago.PublicKey, name, symbol, uri string, extra [][2]string) []byte {
+	b := append(authority.Bytes(), mint.Bytes()...)
+	b = append(b, borshString(name)...)
+	b = append(b, borshString(symbol)...)
+	b = append(b, borshString(uri)...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(extra)))
+	for _, kv := range extra {
+		b = append(b, borshString(kv[0])...)
+		b = append(b, borshString(kv[1])...)
+	}
+	return b
+}
+
+func mintWithExtensions(entries ...[]byte) []byte {
+	data := make([]byte, token2022BaseSize)
+	data = append(data, token2022MintType)
+	for _, entry := range entries {
+		data = append(data, entry...)
+	}
+	return data
+}
+
+func TestParseTokenExtensions(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey()
+	authority := solanago.NewWallet().PublicKey()
+	delegate := solanago.NewWallet().PublicKey()
+	pointer := append(authority.Bytes(), mint.Bytes()...)
+
+	tests := []struct {
+		name          string
+		data          []byte
+		wantPointer   string
+		wantDelegate  string
+		wantURI       string
+		wantExtraKeys int
+		wantErr       bool
+	}{
+		{
+			name: "legacy mint",
+			data: make([]byte, 82),
+		},
+		{
+			name: "metadata pointer and embedded metadata",
+			data: mintWithExtensions(
+				tlv(extensionMetadataPtr, pointer),
+				tlv(extensionTokenMetadata, tokenMetadataValue(authority, mint, "Lion #1", "LION", "https://example.com/1.json", [][2]string{{"rarity", "rare"}})),
+			),
+			wantPointer:   mint.String(),
+			wantURI:       "https://example.com/1.json",
+			wantExtraKeys: 1,
+		},
+		{
+			name:         "permanent delegate",
+			data:         mintWithExtensions(tlv(extensionPermanentDelegate, delegate.Bytes())),
+			wantDelegate: delegate.String(),
+		},
+		{
+			name: "unset permanent delegate",
+			data: mintWithExtensions(tlv(extensionPermanentDelegate, make([]byte, 32))),
+		},
+		{
+			name:         "unknown extensions are skipped",
+			data:         mintWithExtensions(tlv(9, nil), tlv(extensionPermanentDelegate, delegate.Bytes())),
+			wantDelegate: delegate.String(),
+		},
+		{
+			name:    "not a mint",
+			data:    append(make([]byte, token2022BaseSize), 2),
+			wantErr: true,
+		},
+		{
+			name:    "truncated extension",
+			data:    mintWithExtensions(tlv(extensionPermanentDelegate, delegate.Bytes())[:20]),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extensions, err := ParseTokenExtensions(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse extensions: %v", err)
+			}
+			if extensions.MetadataPointer != tt.wantPointer {
+				t.Errorf("MetadataPointer = %q, want %q", extensions.MetadataPointer, tt.wantPointer)
+			}
+			if extensions.PermanentDelegate != tt.wantDelegate {
+				t.Errorf("PermanentDelegate = %q, want %q", extensions.PermanentDelegate, tt.wantDelegate)
+			}
+			uri := ""
+			if extensions.Metadata != nil {
+				uri = extensions.Metadata.URI
+				if len(extensions.Metadata.AdditionalMetadata) != tt.wantExtraKeys {
+					t.Errorf("Got %d additional metadata keys, want %d", len(extensions.Metadata.AdditionalMetadata), tt.wantExtraKeys)
+				}
+			}
+			if uri != tt.wantURI {
+				t.Errorf("URI = %q, want %q", uri, tt.wantURI)
+			}
+		})
+	}
+}
+
+func TestParseTokenMetadata(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey()
+	data := tokenMetadataValue(solanago.PublicKey{}, mint, "Name", "SYM", "ipfs://abc\x00\x00", nil)
+
+	metadata, err := ParseTokenMetadata(data)
+	if err != nil {
+		t.Fatalf("Failed to parse token metadata: %v", err)
+	}
+	if metadata.UpdateAuthority != "" {
+		t.Errorf("Zero update authority should be empty, got %q", metadata.UpdateAuthority)
+	}
+	if metadata.Mint != mint.String() || metadata.Name != "Name" || metadata.Symbol != "SYM" {
+		t.Errorf("Unexpected metadata: %+v", metadata)
+	}
+	if metadata.URI != "ipfs://abc" {
+		t.Errorf("URI padding not trimmed: %q", metadata.URI)
+	}
+
+	if _, err := ParseTokenMetadata(data[:70]); err == nil {
+		t.Error("Expected an error for truncated metadata")
+	}
+}
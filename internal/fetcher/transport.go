@@ -0,0 +1,215 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Default timeouts for hosts without an override
+const (
+	DefaultMetadataTimeout = 30 * time.Second
+	DefaultMediaTimeout    = 60 * time.Second
+)
+
+// HTTPOptions configures how the fetcher reaches metadata and media hosts
+type HTTPOptions struct {
+	Proxy         *url.URL                 // http, https or socks5 proxy; nil follows HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	HostTimeouts  map[string]time.Duration // Per host, replacing the default; "*.example.com" also matches subdomains
+	RootCAs       *x509.CertPool           // Trusted for TLS; nil uses the system pool
+	InsecureHosts []string                 // Hosts whose TLS certificates are not verified, e.g. a self-hosted gateway
+}
+
+// HTTPOptionsFromEnv reads FETCH_PROXY, FETCH_TIMEOUTS, FETCH_CA_FILE and
+// FETCH_INSECURE_HOSTS
+func HTTPOptionsFromEnv() (HTTPOptions, error) {
+	var opts HTTPOptions
+
+	if value := strings.TrimSpace(os.Getenv("FETCH_PROXY")); value != "" {
+		proxy, err := url.Parse(value)
+		if err != nil || proxy.Host == "" {
+			return opts, fmt.Errorf("invalid FETCH_PROXY %q: expected a URL like socks5://127.0.0.1:9050", value)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return opts, fmt.Errorf("invalid FETCH_PROXY %q: scheme must be http, https, socks5 or socks5h", value)
+		}
+		opts.Proxy = proxy
+	}
+
+	if value := strings.TrimSpace(os.Getenv("FETCH_TIMEOUTS")); value != "" {
+		opts.HostTimeouts = make(map[string]time.Duration)
+		for _, field := range strings.Split(value, ",") {
+			host, timeout, ok := strings.Cut(strings.TrimSpace(field), "=")
+			d, err := time.ParseDuration(strings.TrimSpace(timeout))
+			if !ok || strings.TrimSpace(host) == "" || err != nil || d <= 0 {
+				return opts, fmt.Errorf("invalid FETCH_TIMEOUTS entry %q: expected host=duration, e.g. arweave.net=2m", field)
+			}
+			opts.HostTimeouts[strings.ToLower(strings.TrimSpace(host))] = d
+		}
+	}
+
+	if path := strings.TrimSpace(os.Getenv("FETCH_CA_FILE")); path != "" {
+		roots, err := loadRoots(path)
+		if err != nil {
+			return opts, err
+		}
+		opts.RootCAs = roots
+	}
+
+	for _, host := range strings.Split(os.Getenv("FETCH_INSECURE_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			opts.InsecureHosts = append(opts.InsecureHosts, host)
+		}
+	}
+	return opts, nil
+}
+
+// loadRoots returns the system pool with the PEM certificates in path added,
+// so a private gateway's CA is trusted without distrusting public hosts
+func loadRoots(path string) (*x509.CertPool, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FETCH_CA_FILE: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil || roots == nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("FETCH_CA_FILE %s has no PEM certificates", path)
+	}
+	return roots, nil
+}
+
+// NewHTTPClient builds a client that applies opts, timing out requests to
+// hosts without an override after fallback
+//
+// Explanation: http.Client.Timeout is one value for every host, so the
+// client has none and a RoundTripper sets a deadline per request from its
+// host instead. The deadline covers reading the body too, like
+// Client.Timeout, and is released when the body is closed. Skipping TLS
+// verification is per transport in net/http, so hosts listed as insecure
+// go through a second transport.
+func NewHTTPClient(opts HTTPOptions, fallback time.Duration) *http.Client {
+	secure := newTransport(opts, false)
+	var insecure *http.Transport
+	if len(opts.InsecureHosts) > 0 {
+		insecure = newTransport(opts, true)
+	}
+	return &http.Client{Transport: &hostTransport{
+		opts:     opts,
+		fallback: fallback,
+		secure:   secure,
+		insecure: insecure,
+	}}
+}
+
+func newTransport(opts HTTPOptions, skipVerify bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.Proxy != nil {
+		transport.Proxy = http.ProxyURL(opts.Proxy)
+	}
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:            opts.RootCAs,
+		InsecureSkipVerify: skipVerify, // Only used for FETCH_INSECURE_HOSTS
+	}
+	return transport
+}
+
+// hostTransport picks the transport and timeout for each request's host
+type hostTransport struct {
+	opts     HTTPOptions
+	fallback time.Duration
+	secure   *http.Transport
+	insecure *http.Transport // nil when no host skips verification
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	transport := t.secure
+	if t.insecure != nil && matchHost(t.opts.InsecureHosts, host) {
+		transport = t.insecure
+	}
+
+	timeout := t.timeoutFor(host)
+	if timeout <= 0 {
+		return transport.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// timeoutFor returns the override for host, preferring an exact entry and
+// then the most specific wildcard, or the fallback
+func (t *hostTransport) timeoutFor(host string) time.Duration {
+	if d, ok := t.opts.HostTimeouts[host]; ok {
+		return d
+	}
+	timeout, matched := t.fallback, ""
+	for pattern, d := range t.opts.HostTimeouts {
+		if strings.HasPrefix(pattern, "*.") && len(pattern) > len(matched) && matchHost([]string{pattern}, host) {
+			timeout, matched = d, pattern
+		}
+	}
+	return timeout
+}
+
+// CloseIdleConnections lets Client.CloseIdleConnections reach both transports
+func (t *hostTransport) CloseIdleConnections() {
+	t.secure.CloseIdleConnections()
+	if t.insecure != nil {
+		t.insecure.CloseIdleConnections()
+	}
+}
+
+// matchHost reports whether host is one of patterns, where "*.example.com"
+// matches example.com and every subdomain
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if host == suffix || strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelOnClose releases a request's deadline once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,129 @@
+package fetcher
+
+import (
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient_HostTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("slow"))
+	}))
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":"):]
+
+	client := NewHTTPClient(HTTPOptions{HostTimeouts: map[string]time.Duration{"127.0.0.1": 50 * time.Millisecond}}, 5*time.Second)
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"override times out", "http://127.0.0.1" + port, true},
+		{"other hosts use the default", "http://localhost" + port, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(tt.url)
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	tests := []struct {
+		name    string
+		opts    HTTPOptions
+		wantErr bool
+	}{
+		{"unknown CA is refused", HTTPOptions{}, true},
+		{"custom CA is trusted", HTTPOptions{RootCAs: roots}, false},
+		{"insecure host skips verification", HTTPOptions{InsecureHosts: []string{"127.0.0.1"}}, false},
+		{"insecure applies only to the listed host", HTTPOptions{InsecureHosts: []string{"gateway.example"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewHTTPClient(tt.opts, 5*time.Second).Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"name":"via proxy"}`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	resp, err := NewHTTPClient(HTTPOptions{Proxy: proxyURL}, 5*time.Second).Get("http://metadata.example/cat.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch through proxy: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://metadata.example/cat.json" {
+		t.Errorf("Expected the request to go through the proxy, proxy saw %q", proxied)
+	}
+}
+
+func TestHTTPOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+		check   func(opts HTTPOptions) bool
+	}{
+		{name: "nothing set", check: func(opts HTTPOptions) bool { return opts.Proxy == nil && opts.HostTimeouts == nil }},
+		{name: "socks proxy", env: map[string]string{"FETCH_PROXY": "socks5://127.0.0.1:9050"}, check: func(opts HTTPOptions) bool { return opts.Proxy.Scheme == "socks5" }},
+		{name: "unsupported proxy scheme", env: map[string]string{"FETCH_PROXY": "ftp://proxy:21"}, wantErr: true},
+		{name: "timeouts", env: map[string]string{"FETCH_TIMEOUTS": "arweave.net=2m, *.IPFS.io=90s"}, check: func(opts HTTPOptions) bool {
+			return opts.HostTimeouts["arweave.net"] == 2*time.Minute && opts.HostTimeouts["*.ipfs.io"] == 90*time.Second
+		}},
+		{name: "bad timeout", env: map[string]string{"FETCH_TIMEOUTS": "arweave.net"}, wantErr: true},
+		{name: "missing CA file", env: map[string]string{"FETCH_CA_FILE": "/nonexistent/ca.pem"}, wantErr: true},
+		{name: "insecure hosts", env: map[string]string{"FETCH_INSECURE_HOSTS": "gateway.lan, *.home"}, check: func(opts HTTPOptions) bool {
+			return len(opts.InsecureHosts) == 2 && matchHost(opts.InsecureHosts, "ipfs.home")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"FETCH_PROXY", "FETCH_TIMEOUTS", "FETCH_CA_FILE", "FETCH_INSECURE_HOSTS"} {
+				t.Setenv(name, tt.env[name])
+			}
+			opts, err := HTTPOptionsFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(opts) {
+				t.Errorf("Unexpected options %+v", opts)
+			}
+		})
+	}
+}
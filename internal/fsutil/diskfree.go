@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+
+package fsutil
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// FreeSpace returns the bytes available to this user on the filesystem
+// holding path
+func FreeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package fsutil
+
+import "errors"
+
+// FreeSpace is not implemented on this platform; callers skip free-space
+// checks when it returns errors.ErrUnsupported
+func FreeSpace(path string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// FreeSpace returns the bytes available to this user on the volume holding
+// path
+func FreeSpace(path string) (int64, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	var available uint64
+	if ok, _, err := getDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&available)), 0, 0); ok == 0 {
+		return 0, fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	return int64(available), nil
+}
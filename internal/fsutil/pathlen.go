@@ -0,0 +1,123 @@
+package fsutil
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// MaxComponentLength is the longest single file or directory name (in bytes)
+// accepted by common filesystems (ext4, APFS, NTFS)
+const MaxComponentLength = 255
+
+// MaxMediaFilename is the longest media file name (in bytes) written into a
+// backup. Storage reserves this much room when checking NFT directory paths,
+// and the media downloader shortens longer names to fit.
+const MaxMediaFilename = 64
+
+// MaxPathLength is the longest full path we will try to create.
+// Explanation: Windows still defaults to MAX_PATH (260 including the NUL)
+// unless long paths are enabled, macOS caps at 1024 and Linux at 4096.
+var MaxPathLength = defaultMaxPathLength()
+
+func defaultMaxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		return 259
+	case "darwin":
+		return 1023
+	default:
+		return 4095
+	}
+}
+
+// PathTooLongError reports a path that cannot be created on this system,
+// along with guidance on how to fix it
+type PathTooLongError struct {
+	Path      string
+	Length    int
+	Limit     int
+	Component string // Set when a single name, not the whole path, is too long
+}
+
+func (e *PathTooLongError) Error() string {
+	if e.Component != "" {
+		return fmt.Sprintf("file name too long (%d bytes, limit %d): %s\n"+
+			"   💡 This usually comes from an unusually long media URL; please report the NFT so we can shorten it automatically",
+			e.Length, e.Limit, e.Component)
+	}
+
+	guidance := "choose a shorter backup directory with 'solvault init --backup-dir <path>'"
+	if runtime.GOOS == "windows" {
+		guidance += ", or enable Win32 long paths (LongPathsEnabled registry setting)"
+	}
+	return fmt.Sprintf("path too long (%d characters, limit %d): %s\n   💡 %s",
+		e.Length, e.Limit, e.Path, guidance)
+}
+
+// CheckPath verifies that path and each of its components fit within the
+// platform limits, returning a *PathTooLongError when they do not
+func CheckPath(path string) error {
+	for _, component := range strings.Split(filepath.ToSlash(path), "/") {
+		if len(component) > MaxComponentLength {
+			return &PathTooLongError{Path: path, Length: len(component), Limit: MaxComponentLength, Component: component}
+		}
+	}
+
+	if length := utf8.RuneCountInString(path); length > MaxPathLength {
+		return &PathTooLongError{Path: path, Length: length, Limit: MaxPathLength}
+	}
+
+	return nil
+}
+
+// IsPathTooLong reports whether err was caused by an over-long path, either
+// detected up front by CheckPath or returned by the operating system
+func IsPathTooLong(err error) bool {
+	var pathErr *PathTooLongError
+	return errors.As(err, &pathErr) || errors.Is(err, syscall.ENAMETOOLONG)
+}
+
+// WrapPathError converts an opaque ENAMETOOLONG from the OS into a
+// *PathTooLongError with guidance; other errors are returned unchanged
+func WrapPathError(path string, err error) error {
+	if err == nil || !errors.Is(err, syscall.ENAMETOOLONG) {
+		return err
+	}
+	return &PathTooLongError{Path: path, Length: utf8.RuneCountInString(path), Limit: MaxPathLength}
+}
+
+// ShortenName trims name to at most maxBytes while keeping its extension and
+// adding a short hash of the full name so shortened names stay unique
+func ShortenName(name string, maxBytes int) string {
+	if len(name) <= maxBytes {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := fmt.Sprintf("~%x", sum[:4])
+
+	ext := filepath.Ext(name)
+	if len(ext) > 16 {
+		ext = "" // Not a real extension, just a dot somewhere in a long name
+	}
+
+	stem := strings.TrimSuffix(name, ext)
+	keep := maxBytes - len(suffix) - len(ext)
+	if keep < 0 {
+		keep = 0
+	}
+	for keep > 0 && keep < len(stem) && !utf8.RuneStart(stem[keep]) {
+		keep--
+	}
+	if keep < len(stem) {
+		stem = stem[:keep]
+	}
+
+	return stem + suffix + ext
+}
@@ -0,0 +1,60 @@
+package fsutil
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestCheckPath(t *testing.T) {
+	original := MaxPathLength
+	MaxPathLength = 60
+	defer func() { MaxPathLength = original }()
+
+	if err := CheckPath(filepath.Join("backups", "wallets", "abc", "nfts")); err != nil {
+		t.Errorf("Expected short path to pass, got %v", err)
+	}
+
+	err := CheckPath(filepath.Join("backups", strings.Repeat("w", 44), "nfts", strings.Repeat("m", 44)))
+	if !IsPathTooLong(err) {
+		t.Fatalf("Expected path too long error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--backup-dir") {
+		t.Errorf("Expected actionable guidance in error, got %q", err.Error())
+	}
+
+	err = CheckPath(filepath.Join("backups", strings.Repeat("x", 300)))
+	if pathErr, ok := err.(*PathTooLongError); !ok || pathErr.Component == "" {
+		t.Errorf("Expected component error, got %v", err)
+	}
+}
+
+func TestShortenName(t *testing.T) {
+	long := strings.Repeat("a", 300) + ".png"
+	short := ShortenName(long, 64)
+
+	if len(short) > 64 {
+		t.Errorf("Expected at most 64 bytes, got %d", len(short))
+	}
+	if !strings.HasSuffix(short, ".png") {
+		t.Errorf("Expected extension to be kept, got %q", short)
+	}
+	if ShortenName(strings.Repeat("a", 299)+"b.png", 64) == short {
+		t.Error("Expected different names to shorten differently")
+	}
+	if ShortenName("image.png", 64) != "image.png" {
+		t.Error("Expected short names to be unchanged")
+	}
+}
+
+func TestWrapPathError(t *testing.T) {
+	err := WrapPathError("/too/long", syscall.ENAMETOOLONG)
+	if _, ok := err.(*PathTooLongError); !ok {
+		t.Errorf("Expected *PathTooLongError, got %T", err)
+	}
+
+	if WrapPathError("/x", syscall.ENOENT) != syscall.ENOENT {
+		t.Error("Expected unrelated errors to pass through")
+	}
+}
@@ -0,0 +1,207 @@
+// Package inbox implements the drop-folder integration: text files with mint
+// addresses placed in the inbox are backed up by the daemon and then moved
+// to processed/ next to a JSON result file.
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// ProcessedDirName is the subdirectory handled files are moved to
+const ProcessedDirName = "processed"
+
+// DefaultSettle is how long a file must be left untouched before it is
+// picked up, so half-written files are not read
+const DefaultSettle = 2 * time.Second
+
+// MintResult is the outcome of backing up one mint from a drop file
+type MintResult struct {
+	Mint   string `json:"mint"`
+	Wallet string `json:"wallet,omitempty"` // Configured wallet it was backed up under
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"` // added, updated, unchanged, failed, ...
+	Error  string `json:"error,omitempty"`
+}
+
+// Result is written to processed/<file>.result.json for every drop file
+type Result struct {
+	File        string       `json:"file"`
+	ProcessedAt time.Time    `json:"processed_at"`
+	Mints       []MintResult `json:"mints"`
+	Invalid     []string     `json:"invalid,omitempty"` // Tokens that are not valid addresses
+	Succeeded   int          `json:"succeeded"`
+	Failed      int          `json:"failed"`
+}
+
+// BackupFunc backs up one mint
+type BackupFunc func(ctx context.Context, mint solanago.PublicKey) MintResult
+
+// Inbox is a drop folder watched for mint lists
+type Inbox struct {
+	dir    string
+	settle time.Duration
+	now    func() time.Time
+}
+
+// New returns an inbox rooted at dir, creating it and processed/ if needed
+func New(dir string) (*Inbox, error) {
+	if err := os.MkdirAll(filepath.Join(dir, ProcessedDirName), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create inbox: %w", err)
+	}
+	return &Inbox{dir: dir, settle: DefaultSettle, now: time.Now}, nil
+}
+
+// DefaultDir returns ~/.solvault/inbox
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".solvault", "inbox"), nil
+}
+
+// Dir returns the inbox directory
+func (i *Inbox) Dir() string {
+	return i.dir
+}
+
+// ProcessedDir returns where handled files are moved
+func (i *Inbox) ProcessedDir() string {
+	return filepath.Join(i.dir, ProcessedDirName)
+}
+
+// Pending returns the drop files ready to process, oldest first. Hidden
+// files and files still being written are skipped.
+func (i *Inbox) Pending() ([]string, error) {
+	entries, err := os.ReadDir(i.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	type pending struct {
+		path    string
+		modTime time.Time
+	}
+	var files []pending
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if i.now().Sub(info.ModTime()) < i.settle {
+			continue
+		}
+		files = append(files, pending{filepath.Join(i.dir, name), info.ModTime()})
+	}
+
+	sort.Slice(files, func(a, b int) bool { return files[a].modTime.Before(files[b].modTime) })
+	paths := make([]string, len(files))
+	for n, f := range files {
+		paths[n] = f.path
+	}
+	return paths, nil
+}
+
+// Process backs up the mints in every pending file and moves each file to
+// processed/ with its result. It stops early if ctx is cancelled, leaving
+// unprocessed files in place.
+func (i *Inbox) Process(ctx context.Context, backup BackupFunc) ([]*Result, error) {
+	files, err := i.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*Result
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result, err := i.processFile(ctx, path, backup)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (i *Inbox) processFile(ctx context.Context, path string, backup BackupFunc) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	mints, invalid := ParseMints(data)
+	result := &Result{File: filepath.Base(path), Mints: []MintResult{}, Invalid: invalid}
+	for _, mint := range mints {
+		outcome := backup(ctx, mint)
+		if outcome.Status == "failed" || outcome.Error != "" {
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Mints = append(result.Mints, outcome)
+	}
+	result.ProcessedAt = i.now().UTC()
+
+	// Explanation: Prefix with a timestamp so dropping the same file name
+	// twice never overwrites an earlier result
+	target := filepath.Join(i.ProcessedDir(), result.ProcessedAt.Format("20060102T150405Z")+"-"+result.File)
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	if err := os.WriteFile(target+".result.json", encoded, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write result file: %w", err)
+	}
+	if err := os.Rename(path, target); err != nil {
+		return nil, fmt.Errorf("failed to move %s to processed: %w", path, err)
+	}
+	return result, nil
+}
+
+// ParseMints extracts mint addresses from a drop file. Addresses may be
+// separated by newlines, commas or whitespace; text after # is a comment.
+// Duplicates are dropped and unparseable tokens are returned as invalid.
+func ParseMints(data []byte) ([]solanago.PublicKey, []string) {
+	var mints []solanago.PublicKey
+	var invalid []string
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		tokens := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\r'
+		})
+		for _, token := range tokens {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+
+			mint, err := solanago.PublicKeyFromBase58(token)
+			if err != nil {
+				invalid = append(invalid, token)
+				continue
+			}
+			mints = append(mints, mint)
+		}
+	}
+	return mints, invalid
+}
@@ -0,0 +1,120 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestParseMints(t *testing.T) {
+	a := solanago.NewWallet().PublicKey().String()
+	b := solanago.NewWallet().PublicKey().String()
+
+	tests := []struct {
+		name        string
+		input       string
+		wantMints   int
+		wantInvalid int
+	}{
+		{"one per line", a + "\n" + b + "\n", 2, 0},
+		{"comma separated", a + ", " + b, 2, 0},
+		{"comments and blanks", "# drop list\n\n" + a + "  # first\n", 1, 0},
+		{"duplicates", a + "\n" + a + "\r\n", 1, 0},
+		{"invalid token", a + " not-a-mint", 1, 1},
+		{"empty", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mints, invalid := ParseMints([]byte(tt.input))
+			if len(mints) != tt.wantMints || len(invalid) != tt.wantInvalid {
+				t.Errorf("ParseMints() = %d mints, %d invalid; want %d, %d", len(mints), len(invalid), tt.wantMints, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestInbox_Process(t *testing.T) {
+	dir := t.TempDir()
+	box, err := New(dir)
+	if err != nil {
+		t.Fatalf("Failed to create inbox: %v", err)
+	}
+
+	ok := solanago.NewWallet().PublicKey()
+	bad := solanago.NewWallet().PublicKey()
+	content := ok.String() + "\n" + bad.String() + "\ngarbage\n"
+
+	dropped := filepath.Join(dir, "mints.txt")
+	if err := os.WriteFile(dropped, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write drop file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".hidden"), []byte(ok.String()), 0644); err != nil {
+		t.Fatalf("Failed to write hidden file: %v", err)
+	}
+
+	// Freshly written files wait until they settle
+	if pending, _ := box.Pending(); len(pending) != 0 {
+		t.Fatalf("Expected unsettled file to be skipped, got %v", pending)
+	}
+	box.now = func() time.Time { return time.Now().Add(time.Minute) }
+
+	var backedUp []string
+	results, err := box.Process(context.Background(), func(ctx context.Context, mint solanago.PublicKey) MintResult {
+		backedUp = append(backedUp, mint.String())
+		if mint == bad {
+			return MintResult{Mint: mint.String(), Status: "failed", Error: "not held"}
+		}
+		return MintResult{Mint: mint.String(), Status: "added"}
+	})
+	if err != nil {
+		t.Fatalf("Failed to process inbox: %v", err)
+	}
+
+	if len(results) != 1 || len(backedUp) != 2 {
+		t.Fatalf("Expected 1 file with 2 mints, got %d results and %v", len(results), backedUp)
+	}
+	result := results[0]
+	if result.Succeeded != 1 || result.Failed != 1 || len(result.Invalid) != 1 {
+		t.Errorf("Unexpected result counts: %+v", result)
+	}
+
+	if _, err := os.Stat(dropped); !os.IsNotExist(err) {
+		t.Error("Expected drop file to be moved out of the inbox")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".hidden")); err != nil {
+		t.Error("Hidden files should be left alone")
+	}
+
+	processed, err := os.ReadDir(box.ProcessedDir())
+	if err != nil {
+		t.Fatalf("Failed to read processed dir: %v", err)
+	}
+	var resultFile string
+	for _, entry := range processed {
+		if strings.HasSuffix(entry.Name(), ".result.json") {
+			resultFile = filepath.Join(box.ProcessedDir(), entry.Name())
+		}
+	}
+	if len(processed) != 2 || resultFile == "" {
+		t.Fatalf("Expected moved file and result file, got %d entries", len(processed))
+	}
+
+	data, err := os.ReadFile(resultFile)
+	if err != nil {
+		t.Fatalf("Failed to read result file: %v", err)
+	}
+	var written Result
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Failed to parse result file: %v", err)
+	}
+	if written.File != "mints.txt" || len(written.Mints) != 2 {
+		t.Errorf("Unexpected result file contents: %+v", written)
+	}
+}
@@ -0,0 +1,189 @@
+// Package journal keeps the append-only event journal of the watcher: every
+// mint it detects, every NFT that leaves a wallet, every backup it starts
+// and finishes, and every verification it runs, one JSON object per line in
+// events.jsonl at the vault root.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the journal kept at the vault root
+const FileName = "events.jsonl"
+
+// Kind identifies what happened
+type Kind string
+
+const (
+	WatchStarted    Kind = "watch_started"
+	WatchStopped    Kind = "watch_stopped"
+	MintDetected    Kind = "mint_detected"
+	NFTDeparted     Kind = "nft_departed"   // A backed-up NFT left the wallet
+	DrainDetected   Kind = "drain_detected" // Too many NFTs left a wallet too quickly
+	BackupStarted   Kind = "backup_started"
+	BackupCompleted Kind = "backup_completed"
+	BackupFailed    Kind = "backup_failed"
+	VerificationRun Kind = "verification_run"
+	ScheduledJobRun Kind = "scheduled_job_run"
+)
+
+// Kinds lists every event kind, for validating filters
+var Kinds = []Kind{
+	WatchStarted, WatchStopped, MintDetected, NFTDeparted, DrainDetected,
+	BackupStarted, BackupCompleted, BackupFailed, VerificationRun,
+	ScheduledJobRun,
+}
+
+// Event is one line of the journal
+type Event struct {
+	Time      time.Time `json:"time"`
+	Kind      Kind      `json:"kind"`
+	Wallet    string    `json:"wallet,omitempty"`
+	Mint      string    `json:"mint,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Detail    string    `json:"detail,omitempty"`    // What ran, or how a backup changed
+	Signature string    `json:"signature,omitempty"` // Transaction an NFT arrived or left in
+	Error     string    `json:"error,omitempty"`
+	PID       int       `json:"pid,omitempty"`        // Process that wrote the event
+	Duration  float64   `json:"duration_s,omitempty"` // Seconds, for finished work
+}
+
+// Journal appends events to a journal file. It is safe for concurrent use.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// Open returns the journal of the vault at baseDir. The file is created on
+// the first event.
+func Open(baseDir string) *Journal {
+	return &Journal{path: filepath.Join(baseDir, FileName), now: time.Now}
+}
+
+// Path returns the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends an event, stamping the time and process when unset.
+//
+// Explanation: Each event is written with a single O_APPEND write and
+// synced, so a crash never loses an event that was reported as written,
+// and a watcher and a scheduled job writing at once cannot interleave
+// within a line. At worst a crash mid-write leaves a torn last line; the
+// next event starts on a new line after it and Read skips it.
+func (j *Journal) Record(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = j.now()
+	}
+	if event.PID == 0 {
+		event.PID = os.Getpid()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event journal: %w", err)
+	}
+	defer file.Close()
+	if torn(file) {
+		data = append([]byte{'\n'}, data...)
+	}
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync event journal: %w", err)
+	}
+	return nil
+}
+
+// torn reports whether the journal ends in a partly written line
+func torn(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil || stat.Size() == 0 {
+		return false
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, stat.Size()-1); err != nil {
+		return false
+	}
+	return last[0] != '\n'
+}
+
+// Filter selects events when reading the journal
+type Filter struct {
+	Since time.Time // Inclusive; zero reads from the start
+	Until time.Time // Exclusive; zero reads to the end
+	Kinds []Kind    // Empty matches every kind
+	Mint  string
+}
+
+func (f Filter) match(event Event) bool {
+	if !f.Since.IsZero() && event.Time.Before(f.Since) || !f.Until.IsZero() && !event.Time.Before(f.Until) {
+		return false
+	}
+	if f.Mint != "" && event.Mint != f.Mint {
+		return false
+	}
+	if len(f.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range f.Kinds {
+		if event.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the events matching filter in the order they were written,
+// and how many lines could not be parsed. A missing journal has no events.
+func (j *Journal) Read(filter Filter) ([]Event, int, error) {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open event journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []Event
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			skipped++
+			continue
+		}
+		if filter.match(event) {
+			events = append(events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return events, skipped, fmt.Errorf("failed to read event journal: %w", err)
+	}
+	return events, skipped, nil
+}
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestJournal_RecordAndRead(t *testing.T) {
+	j := Open(t.TempDir())
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{Time: start, Kind: WatchStarted},
+		{Time: start.Add(time.Minute), Kind: MintDetected, Mint: "mintA"},
+		{Time: start.Add(2 * time.Minute), Kind: BackupStarted, Mint: "mintA"},
+		{Time: start.Add(3 * time.Minute), Kind: BackupCompleted, Mint: "mintA", Duration: 60},
+		{Time: start.Add(4 * time.Minute), Kind: BackupFailed, Mint: "mintB", Error: "rpc timeout"},
+	}
+	for _, event := range events {
+		if err := j.Record(event); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []Kind
+	}{
+		{name: "everything", want: []Kind{WatchStarted, MintDetected, BackupStarted, BackupCompleted, BackupFailed}},
+		{name: "since is inclusive", filter: Filter{Since: start.Add(3 * time.Minute)}, want: []Kind{BackupCompleted, BackupFailed}},
+		{name: "until is exclusive", filter: Filter{Until: start.Add(time.Minute)}, want: []Kind{WatchStarted}},
+		{name: "kinds", filter: Filter{Kinds: []Kind{BackupFailed, MintDetected}}, want: []Kind{MintDetected, BackupFailed}},
+		{name: "mint", filter: Filter{Mint: "mintA"}, want: []Kind{MintDetected, BackupStarted, BackupCompleted}},
+		{name: "nothing", filter: Filter{Since: start.Add(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := j.Read(tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to read journal: %v", err)
+			}
+			if skipped != 0 {
+				t.Errorf("Expected no skipped lines, got %d", skipped)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d events, got %d", len(tt.want), len(got))
+			}
+			for i, event := range got {
+				if event.Kind != tt.want[i] {
+					t.Errorf("Event %d: expected %s, got %s", i, tt.want[i], event.Kind)
+				}
+				if event.PID == 0 {
+					t.Errorf("Event %d: expected the writing process to be recorded", i)
+				}
+			}
+		})
+	}
+}
+
+func TestJournal_TornLine(t *testing.T) {
+	j := Open(t.TempDir())
+	if err := j.Record(Event{Kind: WatchStarted}); err != nil {
+		t.Fatalf("Failed to record event: %v", err)
+	}
+
+	// A crash mid-write leaves half a line behind
+	file, err := os.OpenFile(j.Path(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	file.WriteString(`{"time":"2026-05-01T12:00:00Z","kind":"backup_sta`)
+	file.Close()
+
+	if err := j.Record(Event{Kind: WatchStopped}); err != nil {
+		t.Fatalf("Failed to record event: %v", err)
+	}
+
+	events, skipped, err := j.Read(Filter{})
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("Expected 1 skipped line, got %d", skipped)
+	}
+	if len(events) != 2 || events[0].Kind != WatchStarted || events[1].Kind != WatchStopped {
+		t.Errorf("Expected the events around the torn line, got %+v", events)
+	}
+}
+
+func TestJournal_Missing(t *testing.T) {
+	events, skipped, err := Open(t.TempDir()).Read(Filter{})
+	if err != nil || skipped != 0 || len(events) != 0 {
+		t.Errorf("Expected an empty journal, got %v, %d, %v", events, skipped, err)
+	}
+}
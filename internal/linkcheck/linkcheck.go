@@ -0,0 +1,407 @@
+// Package linkcheck probes the metadata and media URIs of backed-up NFTs to
+// find the assets that no longer exist anywhere but the vault.
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// Status is what a probe found at a URI
+type Status string
+
+const (
+	StatusOK          Status = "ok"          // Served, and the size matches the backup where known
+	StatusRedirected  Status = "redirected"  // Served from another URL after redirects
+	StatusChanged     Status = "changed"     // Served, but with a different size than was backed up
+	StatusDead        Status = "dead"        // Gone: 404 or 410, or the host no longer exists
+	StatusUnreachable Status = "unreachable" // Timeouts, server errors and rate limits; may be temporary
+)
+
+// Kinds of link
+const (
+	KindMetadata = "metadata"
+	KindMedia    = "media"
+)
+
+// Link is one URI of a backed-up NFT and what a probe found there
+type Link struct {
+	Wallet     string `json:"wallet"`
+	Mint       string `json:"mint"`
+	Name       string `json:"name,omitempty"`
+	Kind       string `json:"kind"` // KindMetadata or KindMedia
+	URL        string `json:"url"`
+	BackedUp   bool   `json:"backed_up"`             // The vault holds a copy
+	Size       int64  `json:"size,omitempty"`        // Size of the copy, when known
+	Status     Status `json:"status"`                // Empty until checked
+	HTTPStatus int    `json:"http_status,omitempty"` // Final response status
+	FinalURL   string `json:"final_url,omitempty"`   // Where redirects ended
+	RemoteSize int64  `json:"remote_size,omitempty"` // Size the server reports
+	Error      string `json:"error,omitempty"`
+}
+
+// Lost reports whether the link is dead and the vault has no copy either
+func (l *Link) Lost() bool {
+	return l.Status == StatusDead && !l.BackedUp
+}
+
+// Report is the result of checking every link
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	NFTs      int       `json:"nfts"`
+	Links     []*Link   `json:"links"`
+}
+
+// Count returns how many links have status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, link := range r.Links {
+		if link.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Options controls a check
+type Options struct {
+	Client            *http.Client // Defaults to one with the metadata timeout
+	RequestsPerSecond float64      // Per host; 0 means no limit
+	Concurrency       int          // Probes at once; defaults to 4
+
+	// Progress, when set, is called after each probe
+	Progress func(done, total int)
+}
+
+// DefaultConcurrency is how many probes run at once by default
+const DefaultConcurrency = 4
+
+// Links lists the metadata and media URIs of the NFTs behind entries. A
+// backup shared by linked wallets is listed once.
+func Links(vault *storage.FileStorage, entries []*storage.IndexEntry) ([]*Link, int) {
+	var links []*Link
+	seen := make(map[string]bool)
+	nfts := 0
+	for _, entry := range entries {
+		dir := vault.EntryDir(entry)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		var stored storage.StoredNFT
+		data, err := vault.ReadFile(filepath.Join(dir, "nft_data.json"))
+		if err != nil || json.Unmarshal(data, &stored) != nil || stored.NFTInfo == nil {
+			continue
+		}
+		nfts++
+		links = append(links, nftLinks(vault, entry, dir, stored.NFTInfo)...)
+	}
+	return links, nfts
+}
+
+// nftLinks lists one backup's links
+func nftLinks(vault *storage.FileStorage, entry *storage.IndexEntry, dir string, info *fetcher.NFTInfo) []*Link {
+	newLink := func(kind, uri string) *Link {
+		return &Link{Wallet: entry.Wallet, Mint: entry.Mint, Name: entry.Name, Kind: kind, URL: uri}
+	}
+
+	var links []*Link
+	if info.MetadataURI != "" {
+		link := newLink(KindMetadata, info.MetadataURI)
+		link.BackedUp = info.Metadata != nil
+		if raw, err := vault.ReadFile(filepath.Join(dir, storage.RawMetadataFile)); err == nil {
+			link.Size = int64(len(raw))
+		}
+		links = append(links, link)
+	}
+
+	downloaded := make(map[string]*fetcher.MediaFile)
+	for _, media := range info.MediaFiles {
+		if media != nil {
+			downloaded[media.URL] = media
+		}
+	}
+	for _, uri := range fetcher.MediaURLs(info.Metadata) {
+		link := newLink(KindMedia, uri)
+		if media, ok := downloaded[uri]; ok {
+			link.BackedUp = true
+			// A screenshot of a page has its own size, not the page's
+			if media.RenderedFrom == "" {
+				link.Size = media.Size
+			}
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// Check probes every link, filling in its status. Each distinct URL is
+// requested once, however many NFTs share it.
+func Check(ctx context.Context, links []*Link, opts Options) *Report {
+	report := &Report{CheckedAt: time.Now().UTC(), Links: links}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: fetcher.DefaultMetadataTimeout}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	p := &prober{client: opts.Client, limiter: newHostLimiter(opts.RequestsPerSecond), gateways: fetcher.NewCrossCheck()}
+
+	byURL := make(map[string][]*Link)
+	var urls []string
+	for _, link := range links {
+		if _, ok := byURL[link.URL]; !ok {
+			urls = append(urls, link.URL)
+		}
+		byURL[link.URL] = append(byURL[link.URL], link)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	for i := 0; i < min(opts.Concurrency, max(len(urls), 1)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for uri := range jobs {
+				result := p.probe(ctx, uri)
+				if ctx.Err() != nil {
+					continue // Interrupted: leave the link unchecked
+				}
+				mu.Lock()
+				for _, link := range byURL[uri] {
+					link.apply(result)
+				}
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(urls))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, uri := range urls {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- uri
+	}
+	close(jobs)
+	wg.Wait()
+	return report
+}
+
+// probeResult is what one request found
+type probeResult struct {
+	status     Status
+	httpStatus int
+	finalURL   string
+	size       int64 // -1 when the server did not say
+	err        string
+}
+
+// apply records result on l, comparing sizes with the backup
+func (l *Link) apply(result probeResult) {
+	l.Status, l.HTTPStatus, l.Error = result.status, result.httpStatus, result.err
+	l.FinalURL = result.finalURL
+	if result.size >= 0 {
+		l.RemoteSize = result.size
+	}
+	if l.Status == StatusOK && l.Size > 0 && result.size >= 0 && result.size != l.Size {
+		l.Status = StatusChanged
+	}
+}
+
+// prober makes the requests
+type prober struct {
+	client   *http.Client
+	limiter  *hostLimiter
+	gateways *fetcher.CrossCheck
+}
+
+// probe finds out what is at uri: HEAD first, then a one-byte ranged GET
+// for servers that do not answer HEAD
+func (p *prober) probe(ctx context.Context, uri string) probeResult {
+	target := uri
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return probeResult{status: StatusDead, size: -1, err: "invalid URL"}
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		// ipfs:// and ar:// are content addressed: ask a gateway for them
+		alternates := p.gateways.Alternates(uri)
+		if len(alternates) == 0 {
+			return probeResult{status: StatusDead, size: -1, err: "unsupported URL scheme " + parsed.Scheme}
+		}
+		target = alternates[0]
+	}
+
+	result := p.request(ctx, http.MethodHead, target)
+	if result.httpStatus == http.StatusMethodNotAllowed || result.httpStatus == http.StatusNotImplemented {
+		result = p.request(ctx, http.MethodGet, target)
+	}
+	// A gateway moving content-addressed data around is not the NFT's URI
+	// moving, so only http(s) URIs are reported as redirected
+	if result.finalURL == target || (target != uri && result.status == StatusOK) {
+		result.finalURL = ""
+	}
+	if result.finalURL != "" && result.status == StatusOK {
+		result.status = StatusRedirected
+	}
+	return result
+}
+
+func (p *prober) request(ctx context.Context, method, target string) probeResult {
+	result := probeResult{size: -1}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		result.status, result.err = StatusDead, err.Error()
+		return result
+	}
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	if err := p.limiter.wait(ctx, req.URL.Hostname()); err != nil {
+		result.status, result.err = StatusUnreachable, err.Error()
+		return result
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		result.status, result.err = StatusUnreachable, err.Error()
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			result.status, result.err = StatusDead, "host not found: "+dnsErr.Name
+		}
+		return result
+	}
+	resp.Body.Close()
+
+	result.httpStatus = resp.StatusCode
+	result.finalURL = resp.Request.URL.String()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		result.status, result.size = StatusOK, resp.ContentLength
+	case resp.StatusCode == http.StatusPartialContent:
+		result.status, result.size = StatusOK, rangeTotal(resp.Header.Get("Content-Range"))
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusUnavailableForLegalReasons:
+		result.status = StatusDead
+	case resp.StatusCode == http.StatusTooManyRequests:
+		result.status, result.err = StatusUnreachable, "rate limited"
+	case resp.StatusCode >= 500:
+		result.status, result.err = StatusUnreachable, resp.Status
+	default:
+		result.status, result.err = StatusUnreachable, resp.Status
+	}
+	return result
+}
+
+// rangeTotal returns the full size from a Content-Range header such as
+// "bytes 0-0/1234", or -1
+func rangeTotal(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// hostLimiter spaces requests to each host
+type hostLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time
+}
+
+func newHostLimiter(perSecond float64) *hostLimiter {
+	l := &hostLimiter{next: make(map[string]time.Time)}
+	if perSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return l
+}
+
+// wait blocks until host may be sent another request
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Sort orders links worst first: lost, dead, changed, unreachable,
+// redirected, then ok, and by NFT within each
+func Sort(links []*Link) {
+	rank := func(l *Link) int {
+		switch {
+		case l.Lost():
+			return 0
+		case l.Status == StatusDead:
+			return 1
+		case l.Status == StatusChanged:
+			return 2
+		case l.Status == StatusUnreachable:
+			return 3
+		case l.Status == StatusRedirected:
+			return 4
+		case l.Status == StatusOK:
+			return 5
+		}
+		return 6
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		if ri, rj := rank(links[i]), rank(links[j]); ri != rj {
+			return ri < rj
+		}
+		if links[i].Name != links[j].Name {
+			return links[i].Name < links[j].Name
+		}
+		return links[i].Mint < links[j].Mint
+	})
+}
+
+// String describes l for a report line
+func (l *Link) String() string {
+	name := l.Name
+	if name == "" {
+		name = l.Mint
+	}
+	return fmt.Sprintf("%s %s %s", name, l.Kind, l.URL)
+}
@@ -0,0 +1,184 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok.png", "/new.png":
+			w.Header().Set("Content-Length", "10")
+		case "/moved.png":
+			http.Redirect(w, r, "/new.png", http.StatusMovedPermanently)
+			return
+		case "/gone.png":
+			w.WriteHeader(http.StatusGone)
+			return
+		case "/broken.png":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case "/nohead.png":
+			// Some hosts only answer GET
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("Fallback GET asked for the whole file")
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/42")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("x"))
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheck(t *testing.T) {
+	server := newServer(t)
+
+	tests := []struct {
+		path       string
+		size       int64
+		backedUp   bool
+		want       Status
+		remoteSize int64
+	}{
+		{"/ok.png", 10, true, StatusOK, 10},
+		{"/ok.png", 0, false, StatusOK, 10},
+		{"/moved.png", 10, true, StatusRedirected, 10},
+		{"/gone.png", 10, true, StatusDead, 0},
+		{"/missing.png", 0, false, StatusDead, 0},
+		{"/broken.png", 10, true, StatusUnreachable, 0},
+		{"/nohead.png", 42, true, StatusOK, 42},
+		{"/nohead.png", 41, true, StatusChanged, 42},
+	}
+
+	var links []*Link
+	for _, test := range tests {
+		links = append(links, &Link{Kind: KindMedia, URL: server.URL + test.path, Size: test.size, BackedUp: test.backedUp})
+	}
+	report := Check(context.Background(), links, Options{})
+
+	for i, test := range tests {
+		link := report.Links[i]
+		if link.Status != test.want {
+			t.Errorf("%s: status %q, want %q (%s)", test.path, link.Status, test.want, link.Error)
+		}
+		if link.RemoteSize != test.remoteSize {
+			t.Errorf("%s: remote size %d, want %d", test.path, link.RemoteSize, test.remoteSize)
+		}
+	}
+	if got := report.Links[2].FinalURL; got != server.URL+"/new.png" {
+		t.Errorf("Redirect ended at %q, want /new.png", got)
+	}
+	if !report.Links[4].Lost() || report.Links[3].Lost() {
+		t.Error("Only a dead link without a backup should be lost")
+	}
+}
+
+func TestCheck_RateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	paths := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		paths[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	var links []*Link
+	for i := 0; i < 4; i++ {
+		links = append(links, &Link{URL: server.URL + "/" + strconv.Itoa(i)})
+	}
+	// The same URL on two NFTs is requested once
+	links = append(links, &Link{URL: server.URL + "/0"})
+
+	start := time.Now()
+	report := Check(context.Background(), links, Options{RequestsPerSecond: 20, Concurrency: 4})
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 requests at 20/s took %v; the rate limit was not applied", elapsed)
+	}
+	if len(times) != 4 || paths["/0"] != 1 {
+		t.Errorf("Made %d requests (%d for the shared URL), want 4 and 1", len(times), paths["/0"])
+	}
+	if report.Count(StatusOK) != 5 {
+		t.Errorf("Got %d ok links, want 5", report.Count(StatusOK))
+	}
+}
+
+func TestLinks(t *testing.T) {
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	wallet := solanago.NewWallet().PublicKey()
+	info := &fetcher.NFTInfo{
+		MintAddress: solanago.NewWallet().PublicKey(),
+		Owner:       wallet,
+		FetchedAt:   time.Now(),
+		MetadataURI: "https://meta.example.com/1.json",
+		RawMetadata: []byte(`{"name":"Lion"}`),
+		Metadata: &fetcher.NFTMetadata{
+			Name:         "Lion",
+			Image:        "https://media.example.com/1.png",
+			AnimationURL: "https://media.example.com/1.mp4",
+		},
+		MediaFiles: []*fetcher.MediaFile{{URL: "https://media.example.com/1.png", Size: 1234}},
+	}
+	if err := vault.SaveNFT(context.Background(), info); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	links, nfts := Links(vault, vault.Index().List())
+	if nfts != 1 || len(links) != 3 {
+		t.Fatalf("Got %d links from %d NFTs, want 3 from 1", len(links), nfts)
+	}
+	want := []struct {
+		kind     string
+		url      string
+		size     int64
+		backedUp bool
+	}{
+		{KindMetadata, "https://meta.example.com/1.json", int64(len(info.RawMetadata)), true},
+		{KindMedia, "https://media.example.com/1.png", 1234, true},
+		{KindMedia, "https://media.example.com/1.mp4", 0, false},
+	}
+	for i, w := range want {
+		link := links[i]
+		if link.Kind != w.kind || link.URL != w.url || link.Size != w.size || link.BackedUp != w.backedUp {
+			t.Errorf("Link %d = %+v, want %+v", i, link, w)
+		}
+		if link.Wallet != wallet.String() || link.Name != "Lion" {
+			t.Errorf("Link %d belongs to %s %q", i, link.Wallet, link.Name)
+		}
+	}
+
+	Sort(links)
+	links[2].Status = StatusDead
+	Sort(links)
+	if !strings.HasSuffix(links[0].URL, ".mp4") {
+		t.Errorf("A lost link should sort first, got %s", links[0])
+	}
+}
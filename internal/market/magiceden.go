@@ -0,0 +1,77 @@
+package market
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// magicEdenAPI is the public Magic Eden v2 REST API
+const magicEdenAPI = "https://api-mainnet.magiceden.dev/v2"
+
+// MagicEden quotes NFTs from Magic Eden's public API
+type MagicEden struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMagicEden creates a Magic Eden provider
+func NewMagicEden() *MagicEden {
+	return &MagicEden{baseURL: magicEdenAPI, httpClient: newHTTPClient()}
+}
+
+// Name identifies the marketplace
+func (m *MagicEden) Name() string {
+	return "Magic Eden"
+}
+
+// Quote looks up the mint's collection, the collection floor and the
+// mint's most recent sale
+func (m *MagicEden) Quote(ctx context.Context, mint string) (*Quote, error) {
+	var token struct {
+		Collection string `json:"collection"`
+	}
+	if err := getJSON(ctx, m.httpClient, m.baseURL+"/tokens/"+url.PathEscape(mint), nil, &token); err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{
+		Marketplace: m.Name(),
+		Collection:  token.Collection,
+		FetchedAt:   time.Now().UTC(),
+	}
+
+	if token.Collection != "" {
+		var stats struct {
+			FloorPrice float64 `json:"floorPrice"` // Lamports
+		}
+		if err := getJSON(ctx, m.httpClient, m.baseURL+"/collections/"+url.PathEscape(token.Collection)+"/stats", nil, &stats); err == nil {
+			quote.FloorLamports = uint64(stats.FloorPrice)
+		}
+	}
+
+	// Activities are newest first; the first purchase is the last sale
+	var activities []struct {
+		Type      string  `json:"type"`
+		Price     float64 `json:"price"` // SOL
+		BlockTime int64   `json:"blockTime"`
+	}
+	activitiesURL := m.baseURL + "/tokens/" + url.PathEscape(mint) + "/activities?offset=0&limit=100"
+	if err := getJSON(ctx, m.httpClient, activitiesURL, nil, &activities); err == nil {
+		for _, activity := range activities {
+			if activity.Type != "buyNow" && activity.Type != "acceptBid" {
+				continue
+			}
+			quote.LastSaleLamports = uint64(math.Round(activity.Price * lamportsPerSOL))
+			if activity.BlockTime > 0 {
+				at := time.Unix(activity.BlockTime, 0).UTC()
+				quote.LastSaleAt = &at
+			}
+			break
+		}
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,97 @@
+// Package market looks up what an NFT is worth on secondary marketplaces:
+// the price of its last sale and the current floor of its collection. Quotes
+// are recorded with each backup so the vault doubles as a valuation record,
+// e.g. for insurance.
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// lamportsPerSOL converts prices for display
+const lamportsPerSOL = 1_000_000_000
+
+// ErrNotFound means the marketplace does not know the mint
+var ErrNotFound = errors.New("mint not found on marketplace")
+
+// Quote is a marketplace's view of one NFT at a point in time
+type Quote struct {
+	Marketplace      string     `json:"marketplace"`
+	Collection       string     `json:"collection,omitempty"` // Marketplace's collection symbol or slug
+	FloorLamports    uint64     `json:"floor_lamports,omitempty"`
+	LastSaleLamports uint64     `json:"last_sale_lamports,omitempty"`
+	LastSaleAt       *time.Time `json:"last_sale_at,omitempty"`
+	FetchedAt        time.Time  `json:"fetched_at"`
+}
+
+// FloorSOL returns the collection floor in SOL
+func (q *Quote) FloorSOL() float64 {
+	return float64(q.FloorLamports) / lamportsPerSOL
+}
+
+// LastSaleSOL returns the last sale price in SOL
+func (q *Quote) LastSaleSOL() float64 {
+	return float64(q.LastSaleLamports) / lamportsPerSOL
+}
+
+// Empty reports whether the quote carries no prices at all
+func (q *Quote) Empty() bool {
+	return q.FloorLamports == 0 && q.LastSaleLamports == 0
+}
+
+// Provider quotes NFTs from one marketplace
+type Provider interface {
+	Name() string
+	Quote(ctx context.Context, mint string) (*Quote, error)
+}
+
+// Providers asks each provider in turn and returns the first quote with a
+// price, so a mint unknown to one marketplace can still be priced by another
+type Providers []Provider
+
+// Name lists the marketplaces consulted
+func (p Providers) Name() string {
+	names := make([]string, len(p))
+	for i, provider := range p {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Quote returns the first non-empty quote, or the last error if none had one
+func (p Providers) Quote(ctx context.Context, mint string) (*Quote, error) {
+	err := ErrNotFound
+	for _, provider := range p {
+		quote, qerr := provider.Quote(ctx, mint)
+		if qerr != nil {
+			if !errors.Is(qerr, ErrNotFound) {
+				err = fmt.Errorf("%s: %w", provider.Name(), qerr)
+			}
+			continue
+		}
+		if !quote.Empty() {
+			return quote, nil
+		}
+	}
+	return nil, err
+}
+
+// FromEnv builds the configured providers. Magic Eden's public API needs no
+// key and is always used; Tensor is added when TENSOR_API_KEY is set.
+// MARKET_PRICES=off disables lookups entirely and returns nil.
+func FromEnv() Provider {
+	if setting := strings.ToLower(os.Getenv("MARKET_PRICES")); setting == "off" || setting == "false" || setting == "0" {
+		return nil
+	}
+
+	providers := Providers{NewMagicEden()}
+	if key := os.Getenv("TENSOR_API_KEY"); key != "" {
+		providers = append(providers, NewTensor(key))
+	}
+	return providers
+}
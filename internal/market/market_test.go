@@ -0,0 +1,153 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve answers fixed JSON bodies by request path (including the query)
+func serve(t *testing.T, routes map[string]string, check func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		body, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestMagicEden_Quote(t *testing.T) {
+	ts := serve(t, map[string]string{
+		"/tokens/MINT":                               `{"mintAddress":"MINT","collection":"okay_bears"}`,
+		"/collections/okay_bears/stats":              `{"symbol":"okay_bears","floorPrice":12500000000}`,
+		"/tokens/MINT/activities?offset=0&limit=100": `[{"type":"list","price":99},{"type":"buyNow","price":14.2,"blockTime":1700000000},{"type":"buyNow","price":3}]`,
+	}, nil)
+	me := &MagicEden{baseURL: ts.URL, httpClient: newHTTPClient()}
+
+	quote, err := me.Quote(context.Background(), "MINT")
+	if err != nil {
+		t.Fatalf("Failed to get quote: %v", err)
+	}
+	if quote.Collection != "okay_bears" || quote.FloorLamports != 12_500_000_000 {
+		t.Errorf("Unexpected collection or floor: %+v", quote)
+	}
+	if quote.LastSaleLamports != 14_200_000_000 || quote.LastSaleAt == nil || quote.LastSaleAt.Unix() != 1700000000 {
+		t.Errorf("Unexpected last sale: %+v", quote)
+	}
+	if quote.FloorSOL() != 12.5 {
+		t.Errorf("Got floor %v SOL, expected 12.5", quote.FloorSOL())
+	}
+
+	if _, err := me.Quote(context.Background(), "UNKNOWN"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown mint, got %v", err)
+	}
+}
+
+func TestTensor_Quote(t *testing.T) {
+	ts := serve(t, map[string]string{
+		"/mint?mints=MINT":                  `[{"slug":"abc123","lastSale":{"price":"2000000000","txAt":1700000000000}}]`,
+		"/collections?slugs=abc123&limit=1": `{"collections":[{"stats":{"buyNowPrice":"1500000000"}}]}`,
+		"/mint?mints=NOSALE":                `[{"slug":""}]`,
+		"/mint?mints=EMPTY":                 `[]`,
+	}, func(r *http.Request) {
+		if r.Header.Get("x-tensor-api-key") != "key" {
+			t.Errorf("Missing API key header")
+		}
+	})
+	tensor := &Tensor{baseURL: ts.URL, apiKey: "key", httpClient: newHTTPClient()}
+
+	quote, err := tensor.Quote(context.Background(), "MINT")
+	if err != nil {
+		t.Fatalf("Failed to get quote: %v", err)
+	}
+	if quote.LastSaleLamports != 2_000_000_000 || quote.FloorLamports != 1_500_000_000 {
+		t.Errorf("Unexpected prices: %+v", quote)
+	}
+	if quote.LastSaleAt == nil || quote.LastSaleAt.Unix() != 1700000000 {
+		t.Errorf("Unexpected last sale time: %v", quote.LastSaleAt)
+	}
+
+	if quote, err := tensor.Quote(context.Background(), "NOSALE"); err != nil || !quote.Empty() {
+		t.Errorf("Expected empty quote for unsold mint, got %+v (%v)", quote, err)
+	}
+	if _, err := tensor.Quote(context.Background(), "EMPTY"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown mint, got %v", err)
+	}
+}
+
+// stubProvider returns a fixed quote or error
+type stubProvider struct {
+	name  string
+	quote *Quote
+	err   error
+}
+
+func (s stubProvider) Name() string { return s.name }
+
+func (s stubProvider) Quote(ctx context.Context, mint string) (*Quote, error) {
+	return s.quote, s.err
+}
+
+func TestProviders_Quote(t *testing.T) {
+	priced := stubProvider{name: "B", quote: &Quote{Marketplace: "B", FloorLamports: 1}}
+	empty := stubProvider{name: "E", quote: &Quote{Marketplace: "E"}}
+	missing := stubProvider{name: "M", err: ErrNotFound}
+	broken := stubProvider{name: "X", err: errors.New("HTTP 500")}
+
+	tests := []struct {
+		name       string
+		providers  Providers
+		wantMarket string
+		wantErr    error
+	}{
+		{"first priced wins", Providers{missing, empty, priced}, "B", nil},
+		{"nobody lists it", Providers{missing, empty}, "", ErrNotFound},
+		{"failure is reported", Providers{broken, missing}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := tt.providers.Quote(context.Background(), "MINT")
+			if tt.wantMarket != "" {
+				if err != nil || quote.Marketplace != tt.wantMarket {
+					t.Fatalf("Got %+v (%v), expected quote from %s", quote, err, tt.wantMarket)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error, got %+v", quote)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Got %v, expected %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("MARKET_PRICES", "off")
+	if FromEnv() != nil {
+		t.Errorf("Expected no provider when prices are off")
+	}
+
+	t.Setenv("MARKET_PRICES", "")
+	t.Setenv("TENSOR_API_KEY", "")
+	if got := FromEnv().Name(); got != "Magic Eden" {
+		t.Errorf("Got providers %q, expected Magic Eden only", got)
+	}
+
+	t.Setenv("TENSOR_API_KEY", "key")
+	if got := FromEnv().Name(); got != "Magic Eden, Tensor" {
+		t.Errorf("Got providers %q, expected Magic Eden and Tensor", got)
+	}
+}
@@ -0,0 +1,79 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// tensorAPI is Tensor's REST API, which requires an API key
+const tensorAPI = "https://api.mainnet.tensordev.io/api/v1"
+
+// Tensor quotes NFTs from Tensor
+type Tensor struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewTensor creates a Tensor provider using apiKey
+func NewTensor(apiKey string) *Tensor {
+	return &Tensor{baseURL: tensorAPI, apiKey: apiKey, httpClient: newHTTPClient()}
+}
+
+// Name identifies the marketplace
+func (t *Tensor) Name() string {
+	return "Tensor"
+}
+
+// Quote looks up the mint's last sale and its collection's floor.
+// Tensor reports prices as lamport strings and times in milliseconds.
+func (t *Tensor) Quote(ctx context.Context, mint string) (*Quote, error) {
+	headers := map[string]string{"x-tensor-api-key": t.apiKey}
+
+	var mints []struct {
+		Slug     string `json:"slug"`
+		LastSale *struct {
+			Price string `json:"price"`
+			TxAt  int64  `json:"txAt"`
+		} `json:"lastSale"`
+	}
+	if err := getJSON(ctx, t.httpClient, t.baseURL+"/mint?mints="+url.QueryEscape(mint), headers, &mints); err != nil {
+		return nil, err
+	}
+	if len(mints) == 0 {
+		return nil, ErrNotFound
+	}
+
+	found := mints[0]
+	quote := &Quote{
+		Marketplace: t.Name(),
+		Collection:  found.Slug,
+		FetchedAt:   time.Now().UTC(),
+	}
+	if found.LastSale != nil {
+		quote.LastSaleLamports, _ = strconv.ParseUint(found.LastSale.Price, 10, 64)
+		if found.LastSale.TxAt > 0 {
+			at := time.UnixMilli(found.LastSale.TxAt).UTC()
+			quote.LastSaleAt = &at
+		}
+	}
+
+	if found.Slug != "" {
+		var collections struct {
+			Collections []struct {
+				Stats struct {
+					BuyNowPrice string `json:"buyNowPrice"` // Lowest listing in lamports
+				} `json:"stats"`
+			} `json:"collections"`
+		}
+		collectionsURL := t.baseURL + "/collections?slugs=" + url.QueryEscape(found.Slug) + "&limit=1"
+		if err := getJSON(ctx, t.httpClient, collectionsURL, headers, &collections); err == nil && len(collections.Collections) > 0 {
+			quote.FloorLamports, _ = strconv.ParseUint(collections.Collections[0].Stats.BuyNowPrice, 10, 64)
+		}
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,105 @@
+// Package merkle builds Merkle trees the way RFC 6962 (Certificate
+// Transparency) does, so one root hash commits to a list of entries and any
+// entry can be proven part of it with a short audit path.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Explanation: Leaves and interior nodes are hashed with different prefixes
+// so an interior node can never be passed off as a leaf (a second preimage
+// attack on the tree)
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash returns the hash of one entry as it appears in the tree
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root returns the root of the tree over leaf hashes, in order. The root of
+// an empty tree is the SHA-256 of nothing.
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// Proof returns the audit path for the leaf at index: the sibling hashes
+// needed to recompute the root from that leaf, bottom up
+func Proof(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf %d is outside a tree of %d", index, len(leaves))
+	}
+	return path(leaves, index), nil
+}
+
+func path(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(path(leaves[:k], index), Root(leaves[k:]))
+	}
+	return append(path(leaves[k:], index-k), Root(leaves[:k]))
+}
+
+// Verify reports whether proof shows the leaf hash at index is part of the
+// tree of the given size with the given root
+func Verify(leaf []byte, index, size int, proof [][]byte, root []byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	// The verification algorithm of RFC 9162 section 2.1.3.2
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// split returns the largest power of two smaller than n
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -0,0 +1,76 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func leaves(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = LeafHash([]byte(fmt.Sprintf("leaf %d", i)))
+	}
+	return out
+}
+
+func TestRoot(t *testing.T) {
+	tests := []struct {
+		name   string
+		leaves [][]byte
+		want   string
+	}{
+		{
+			name: "empty",
+			want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			// The empty-leaf hash from RFC 6962's test vectors
+			name:   "one empty leaf",
+			leaves: [][]byte{LeafHash(nil)},
+			want:   "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(Root(tt.leaves)); got != tt.want {
+				t.Errorf("Got root %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Three leaves split as ((0, 1), 2)
+	l := leaves(3)
+	want := nodeHash(nodeHash(l[0], l[1]), l[2])
+	if got := Root(l); !bytes.Equal(got, want) {
+		t.Errorf("Got root %x for three leaves, want %x", got, want)
+	}
+}
+
+func TestProof(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		l := leaves(size)
+		root := Root(l)
+		for index := 0; index < size; index++ {
+			proof, err := Proof(l, index)
+			if err != nil {
+				t.Fatalf("Failed to build proof for leaf %d of %d: %v", index, size, err)
+			}
+			if !Verify(l[index], index, size, proof, root) {
+				t.Errorf("Proof for leaf %d of %d does not verify", index, size)
+			}
+			if Verify(LeafHash([]byte("forged")), index, size, proof, root) {
+				t.Errorf("Forged leaf %d of %d verifies", index, size)
+			}
+			if size > 1 && Verify(l[index], (index+1)%size, size, proof, root) {
+				t.Errorf("Proof for leaf %d of %d verifies at another index", index, size)
+			}
+		}
+	}
+
+	if _, err := Proof(leaves(3), 3); err == nil {
+		t.Errorf("Expected an error for a leaf outside the tree")
+	}
+}
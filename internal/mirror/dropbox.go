@@ -0,0 +1,129 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// dropboxMaxUpload is the largest file a single upload call accepts;
+// anything bigger needs an upload session
+const dropboxMaxUpload = 150 << 20
+
+// Dropbox mirrors backups into a Dropbox folder through the v2 HTTP API
+type Dropbox struct {
+	root       string
+	token      *tokenSource
+	contentURL string
+	client     *http.Client
+}
+
+// NewDropbox creates a provider that uploads below root (default /SolVault)
+func NewDropbox(creds Credentials, root string) *Dropbox {
+	if root == "" {
+		root = "/SolVault"
+	}
+	client := &http.Client{Timeout: 10 * time.Minute} // Media uploads can be large
+	return &Dropbox{
+		root:       "/" + strings.Trim(root, "/"),
+		token:      newTokenSource(creds, "https://api.dropboxapi.com/oauth2/token", client),
+		contentURL: "https://content.dropboxapi.com",
+		client:     client,
+	}
+}
+
+// Name returns "dropbox"
+func (d *Dropbox) Name() string {
+	return "dropbox"
+}
+
+// Upload writes the file at its path, overwriting any earlier upload. Paths
+// identify Dropbox files, so previous is not needed.
+func (d *Dropbox) Upload(ctx context.Context, path string, data io.Reader, size int64, previous string) (string, error) {
+	if size > dropboxMaxUpload {
+		return "", fmt.Errorf("file is %d bytes, over Dropbox's single upload limit of %d", size, dropboxMaxUpload)
+	}
+	token, err := d.token.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	arg, err := headerJSON(map[string]interface{}{
+		"path":       joinPath(d.root, path),
+		"mode":       "overwrite",
+		"autorename": false,
+		"mute":       true,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.contentURL+"/2/files/upload", data)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Dropbox-API-Arg", arg)
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(d.client, req, &uploaded); err != nil {
+		return "", err
+	}
+	if uploaded.ID == "" {
+		return "", fmt.Errorf("Dropbox response did not include a file ID")
+	}
+	return uploaded.ID, nil
+}
+
+// Download reads back the file uploaded at path. Dropbox reports a missing
+// file as HTTP 409 with a path/not_found error.
+func (d *Dropbox) Download(ctx context.Context, path, id string) (io.ReadCloser, error) {
+	token, err := d.token.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := headerJSON(map[string]string{"path": joinPath(d.root, path)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.contentURL+"/2/files/download", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Dropbox-API-Arg", arg)
+
+	body, err := doDownload(d.client, req)
+	if statusErr, ok := err.(*statusError); ok && statusErr.code == http.StatusConflict && strings.Contains(statusErr.body, "not_found") {
+		return nil, ErrRemoteMissing
+	}
+	return body, err
+}
+
+// headerJSON encodes v for an HTTP header. Dropbox requires non-ASCII
+// characters (NFT names in the name layout) to be escaped.
+func headerJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	var b strings.Builder
+	for _, r := range string(data) {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+		for _, unit := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&b, `\u%04x`, unit)
+		}
+	}
+	return b.String(), nil
+}
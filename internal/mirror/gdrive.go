@@ -0,0 +1,274 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// driveFolderType is the MIME type Google Drive uses for folders
+const driveFolderType = "application/vnd.google-apps.folder"
+
+// GoogleDrive mirrors backups into a Google Drive folder through the v3 API
+//
+// Explanation: Drive has no paths, only files with parent IDs, and allows
+// several files with the same name in one folder. Folders are looked up by
+// name (and created when missing) once per run, and files already uploaded
+// are updated by ID so re-uploads never create duplicates.
+type GoogleDrive struct {
+	rootID    string
+	token     *tokenSource
+	apiURL    string
+	uploadURL string
+	client    *http.Client
+
+	mu      sync.Mutex
+	folders map[string]string // Folder path → ID
+}
+
+// NewGoogleDrive creates a provider that uploads into a SolVault folder
+// inside the folder with ID parentID (default: the root of My Drive)
+func NewGoogleDrive(creds Credentials, parentID string) *GoogleDrive {
+	if parentID == "" {
+		parentID = "root"
+	}
+	client := &http.Client{Timeout: 10 * time.Minute} // Media uploads can be large
+	return &GoogleDrive{
+		rootID:    parentID,
+		token:     newTokenSource(creds, "https://oauth2.googleapis.com/token", client),
+		apiURL:    "https://www.googleapis.com/drive/v3",
+		uploadURL: "https://www.googleapis.com/upload/drive/v3",
+		client:    client,
+		folders:   make(map[string]string),
+	}
+}
+
+// Name returns "gdrive"
+func (g *GoogleDrive) Name() string {
+	return "gdrive"
+}
+
+// Upload replaces the contents of the previous file when it still exists,
+// and otherwise creates the file in its folder
+func (g *GoogleDrive) Upload(ctx context.Context, filePath string, data io.Reader, size int64, previous string) (string, error) {
+	token, err := g.token.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if previous != "" {
+		// A failed update has not consumed data, so creating can still read it
+		id, err := g.update(ctx, token, previous, data, size)
+		if !isNotFound(err) {
+			return id, err
+		}
+	}
+
+	folderID, err := g.folder(ctx, token, path.Dir(joinPath("SolVault", filePath)))
+	if err != nil {
+		return "", err
+	}
+	return g.create(ctx, token, folderID, path.Base(filePath), data)
+}
+
+// Download reads back the file with ID id. Trashed files count as missing,
+// as Upload does not reuse them either.
+func (g *GoogleDrive) Download(ctx context.Context, filePath, id string) (io.ReadCloser, error) {
+	if id == "" {
+		return nil, ErrRemoteMissing
+	}
+	token, err := g.token.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := g.request(ctx, token, http.MethodGet, g.apiURL+"/files/"+url.PathEscape(id)+"?fields=trashed", nil)
+	if err != nil {
+		return nil, err
+	}
+	var existing struct {
+		Trashed bool `json:"trashed"`
+	}
+	if err := doJSON(g.client, req, &existing); err != nil {
+		if isNotFound(err) {
+			return nil, ErrRemoteMissing
+		}
+		return nil, err
+	}
+	if existing.Trashed {
+		return nil, ErrRemoteMissing
+	}
+
+	req, err = g.request(ctx, token, http.MethodGet, g.apiURL+"/files/"+url.PathEscape(id)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := doDownload(g.client, req)
+	if isNotFound(err) {
+		return nil, ErrRemoteMissing
+	}
+	return body, err
+}
+
+// update uploads new contents for an existing file
+func (g *GoogleDrive) update(ctx context.Context, token, id string, data io.Reader, size int64) (string, error) {
+	// Check first that the file exists, so the body is only sent once
+	req, err := g.request(ctx, token, http.MethodGet, g.apiURL+"/files/"+url.PathEscape(id)+"?fields=id,trashed", nil)
+	if err != nil {
+		return "", err
+	}
+	var existing struct {
+		ID      string `json:"id"`
+		Trashed bool   `json:"trashed"`
+	}
+	if err := doJSON(g.client, req, &existing); err != nil {
+		return "", err
+	}
+	if existing.Trashed {
+		return "", &statusError{code: http.StatusNotFound}
+	}
+
+	req, err = g.request(ctx, token, http.MethodPatch, g.uploadURL+"/files/"+url.PathEscape(id)+"?uploadType=media&fields=id", data)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	var updated struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(g.client, req, &updated); err != nil {
+		return "", err
+	}
+	return updated.ID, nil
+}
+
+// create uploads a new file with a multipart request: metadata, then content
+func (g *GoogleDrive) create(ctx context.Context, token, folderID, name string, data io.Reader) (string, error) {
+	metadata, err := json.Marshal(map[string]interface{}{"name": name, "parents": []string{folderID}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode file metadata: %w", err)
+	}
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+		if err == nil {
+			_, err = part.Write(metadata)
+		}
+		if err == nil {
+			part, err = form.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/octet-stream"}})
+		}
+		if err == nil {
+			_, err = io.Copy(part, data)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := g.request(ctx, token, http.MethodPost, g.uploadURL+"/files?uploadType=multipart&fields=id", body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+form.Boundary())
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	err = doJSON(g.client, req, &created)
+	body.Close() // Unblocks the writer if the request failed early
+	if err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("Google Drive response did not include a file ID")
+	}
+	return created.ID, nil
+}
+
+// folder returns the ID of the folder at folderPath below the parent
+// folder, creating any that are missing
+func (g *GoogleDrive) folder(ctx context.Context, token, folderPath string) (string, error) {
+	if folderPath == "." || folderPath == "/" || folderPath == "" {
+		return g.rootID, nil
+	}
+	g.mu.Lock()
+	id, ok := g.folders[folderPath]
+	g.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	parentID, err := g.folder(ctx, token, path.Dir(folderPath))
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(folderPath)
+
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and mimeType = '%s' and trashed = false",
+		escapeQuery(name), escapeQuery(parentID), driveFolderType)
+	req, err := g.request(ctx, token, http.MethodGet, g.apiURL+"/files?fields=files(id)&spaces=drive&q="+url.QueryEscape(query), nil)
+	if err != nil {
+		return "", err
+	}
+	var found struct {
+		Files []struct {
+			ID string `json:"id"`
+		} `json:"files"`
+	}
+	if err := doJSON(g.client, req, &found); err != nil {
+		return "", fmt.Errorf("failed to look up folder %s: %w", folderPath, err)
+	}
+
+	if len(found.Files) > 0 {
+		id = found.Files[0].ID
+	} else {
+		metadata, err := json.Marshal(map[string]interface{}{"name": name, "mimeType": driveFolderType, "parents": []string{parentID}})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode folder metadata: %w", err)
+		}
+		req, err := g.request(ctx, token, http.MethodPost, g.apiURL+"/files?fields=id", strings.NewReader(string(metadata)))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		var created struct {
+			ID string `json:"id"`
+		}
+		if err := doJSON(g.client, req, &created); err != nil {
+			return "", fmt.Errorf("failed to create folder %s: %w", folderPath, err)
+		}
+		id = created.ID
+	}
+
+	g.mu.Lock()
+	g.folders[folderPath] = id
+	g.mu.Unlock()
+	return id, nil
+}
+
+func (g *GoogleDrive) request(ctx context.Context, token, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// escapeQuery quotes a value for a Drive search query
+func escapeQuery(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
@@ -0,0 +1,341 @@
+// Package mirror uploads completed NFT backups to cloud storage (Google
+// Drive, Dropbox) so a copy survives the loss of the machine holding the
+// vault. Files are uploaded exactly as stored, so an encrypted vault stays
+// encrypted in the cloud. What each provider holds is recorded in the NFT's
+// StoredNFT, which lets later runs skip unchanged files and lets
+// 'solvault mirror status' show which NFTs only exist locally.
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Provider stores files in one cloud service
+type Provider interface {
+	// Name identifies the provider in StoredNFT and reports (e.g. "dropbox")
+	Name() string
+
+	// Upload stores size bytes from data at path, a slash-separated path
+	// below the provider's SolVault folder. previous is the ID recorded for
+	// the last upload of the same file, empty for a new file. It returns
+	// the file's ID.
+	Upload(ctx context.Context, path string, data io.Reader, size int64, previous string) (string, error)
+}
+
+// State describes how current a provider's copy of an NFT is
+type State string
+
+const (
+	StateMirrored State = "mirrored"
+	StateStale    State = "stale"   // Uploaded, but the local backup changed since
+	StateMissing  State = "missing" // Never uploaded to this provider
+)
+
+// Mirror uploads backups from one vault to a set of providers
+type Mirror struct {
+	store     *storage.FileStorage
+	providers []Provider
+}
+
+// New creates a mirror of store
+func New(store *storage.FileStorage, providers ...Provider) *Mirror {
+	return &Mirror{store: store, providers: providers}
+}
+
+// Names lists the configured providers
+func (m *Mirror) Names() []string {
+	names := make([]string, len(m.providers))
+	for i, provider := range m.providers {
+		names[i] = provider.Name()
+	}
+	return names
+}
+
+// localFile is a backup file as it is on disk now
+type localFile struct {
+	path     string
+	checksum string
+}
+
+// Push uploads whatever changed in an NFT's backup since it was last
+// mirrored and returns how many files were uploaded
+//
+// Explanation: Files deleted locally are dropped from the record but left in
+// the cloud; a mirror only ever adds, so a mistake in the vault cannot
+// reach the off-site copy.
+func (m *Mirror) Push(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (int, error) {
+	stored, files, err := m.localFiles(ctx, walletAddr, mintAddr)
+	if err != nil {
+		return 0, err
+	}
+	remoteDir, err := filepath.Rel(m.store.BaseDir(), m.store.NFTDir(walletAddr, mintAddr))
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate NFT directory: %w", err)
+	}
+
+	uploaded := 0
+	for _, provider := range m.providers {
+		previous := stored.Remote[provider.Name()]
+		remote := &storage.RemoteCopy{Files: make(map[string]storage.RemoteFile, len(files))}
+		var uploadErr error
+		for _, name := range sortedNames(files) {
+			file := files[name]
+			prior, known := storage.RemoteFile{}, false
+			if previous != nil {
+				prior, known = previous.Files[name]
+			}
+			if known && prior.Checksum == file.checksum {
+				remote.Files[name] = prior
+				continue
+			}
+
+			id, size, err := upload(ctx, provider, filepath.ToSlash(filepath.Join(remoteDir, name)), file.path, prior.ID)
+			if err != nil {
+				uploadErr = fmt.Errorf("%s: failed to upload %s: %w", provider.Name(), name, err)
+				break
+			}
+			remote.Files[name] = storage.RemoteFile{ID: id, Checksum: file.checksum, Size: size}
+			uploaded++
+		}
+
+		// Keep what did upload so the next push resumes from there
+		if uploadErr != nil {
+			if previous != nil {
+				for name, file := range previous.Files {
+					if _, done := remote.Files[name]; !done {
+						remote.Files[name] = file
+					}
+				}
+				remote.MirroredAt = previous.MirroredAt
+			}
+		} else {
+			remote.MirroredAt = time.Now()
+		}
+		if err := m.store.SetRemote(ctx, walletAddr, mintAddr, provider.Name(), remote); err != nil {
+			return uploaded, err
+		}
+		if uploadErr != nil {
+			return uploaded, uploadErr
+		}
+	}
+	return uploaded, nil
+}
+
+// upload sends one file, sized as it is now: recording an upload with an
+// earlier provider rewrites nft_data.json
+func upload(ctx context.Context, provider Provider, remotePath, path, previous string) (string, int64, error) {
+	data, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer data.Close()
+	info, err := data.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	id, err := provider.Upload(ctx, remotePath, data, info.Size(), previous)
+	return id, info.Size(), err
+}
+
+// NFTStatus reports how each provider's copy of one NFT compares to the vault
+type NFTStatus struct {
+	Wallet    string           `json:"wallet"`
+	Mint      string           `json:"mint"`
+	Name      string           `json:"name,omitempty"`
+	Providers map[string]State `json:"providers"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// LocalOnly reports whether no provider holds any copy of the NFT
+func (s *NFTStatus) LocalOnly() bool {
+	for _, state := range s.Providers {
+		if state != StateMissing {
+			return false
+		}
+	}
+	return true
+}
+
+// Synced reports whether every provider holds the current backup
+func (s *NFTStatus) Synced() bool {
+	for _, state := range s.Providers {
+		if state != StateMirrored {
+			return false
+		}
+	}
+	return s.Error == ""
+}
+
+// Status compares every backup in the vault with the configured providers.
+// NFTs linked to another wallet's backup are reported once, under the
+// wallet that stores them.
+func (m *Mirror) Status(ctx context.Context) ([]*NFTStatus, error) {
+	var statuses []*NFTStatus
+	for _, entry := range m.store.Index().List() {
+		if err := ctx.Err(); err != nil {
+			return statuses, err
+		}
+		if entry.PrimaryWallet != "" {
+			continue
+		}
+		status := &NFTStatus{Wallet: entry.Wallet, Mint: entry.Mint, Name: entry.Name, Providers: make(map[string]State)}
+		statuses = append(statuses, status)
+
+		walletAddr, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			status.Error = err.Error()
+			continue
+		}
+		mintAddr, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			status.Error = err.Error()
+			continue
+		}
+		stored, files, err := m.localFiles(ctx, walletAddr, mintAddr)
+		if err != nil {
+			status.Error = err.Error()
+			continue
+		}
+		for _, provider := range m.providers {
+			status.Providers[provider.Name()] = compare(stored.Remote[provider.Name()], files)
+		}
+	}
+	return statuses, nil
+}
+
+// compare works out the state of one provider's copy
+func compare(remote *storage.RemoteCopy, files map[string]localFile) State {
+	if remote == nil || len(remote.Files) == 0 {
+		return StateMissing
+	}
+	for name, file := range files {
+		if uploaded, ok := remote.Files[name]; !ok || uploaded.Checksum != file.checksum {
+			return StateStale
+		}
+	}
+	return StateMirrored
+}
+
+// localFiles loads an NFT's record and checksums its backup files, keyed by
+// slash-separated path within the NFT directory
+func (m *Mirror) localFiles(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (*storage.StoredNFT, map[string]localFile, error) {
+	stored, err := m.store.GetNFT(ctx, walletAddr, mintAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := m.store.NFTDir(walletAddr, mintAddr)
+	paths, err := storage.BackupFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string]localFile, len(paths))
+	for _, path := range paths {
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		name = filepath.ToSlash(name)
+
+		file := localFile{path: path}
+		if name == "nft_data.json" {
+			file.checksum, err = recordChecksum(stored)
+		} else {
+			file.checksum, err = fileChecksum(path)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		files[name] = file
+	}
+	return stored, files, nil
+}
+
+// recordChecksum fingerprints nft_data.json without its mirror records
+//
+// Explanation: Recording an upload rewrites nft_data.json, so hashing the
+// file itself would make it look changed after every push. Everything else
+// in the record still counts.
+func recordChecksum(stored *storage.StoredNFT) (string, error) {
+	record := *stored
+	record.Remote = nil
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode NFT data: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func sortedNames(files map[string]localFile) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromEnv builds the providers configured in the environment:
+//
+//	Google Drive: GOOGLE_DRIVE_TOKEN, or GOOGLE_DRIVE_REFRESH_TOKEN with
+//	              GOOGLE_DRIVE_CLIENT_ID and GOOGLE_DRIVE_CLIENT_SECRET;
+//	              GOOGLE_DRIVE_FOLDER_ID picks the parent folder (default: My Drive)
+//	Dropbox:      DROPBOX_TOKEN, or DROPBOX_REFRESH_TOKEN with DROPBOX_APP_KEY
+//	              and DROPBOX_APP_SECRET; DROPBOX_PATH picks the folder
+//	              (default /SolVault)
+func FromEnv() []Provider {
+	var providers []Provider
+
+	drive := Credentials{
+		AccessToken:  os.Getenv("GOOGLE_DRIVE_TOKEN"),
+		RefreshToken: os.Getenv("GOOGLE_DRIVE_REFRESH_TOKEN"),
+		ClientID:     os.Getenv("GOOGLE_DRIVE_CLIENT_ID"),
+		ClientSecret: os.Getenv("GOOGLE_DRIVE_CLIENT_SECRET"),
+	}
+	if drive.configured() {
+		providers = append(providers, NewGoogleDrive(drive, os.Getenv("GOOGLE_DRIVE_FOLDER_ID")))
+	}
+
+	dropbox := Credentials{
+		AccessToken:  os.Getenv("DROPBOX_TOKEN"),
+		RefreshToken: os.Getenv("DROPBOX_REFRESH_TOKEN"),
+		ClientID:     os.Getenv("DROPBOX_APP_KEY"),
+		ClientSecret: os.Getenv("DROPBOX_APP_SECRET"),
+	}
+	if dropbox.configured() {
+		providers = append(providers, NewDropbox(dropbox, os.Getenv("DROPBOX_PATH")))
+	}
+	return providers
+}
+
+// joinPath joins a provider root folder and a relative path
+func joinPath(root, path string) string {
+	return strings.TrimRight(root, "/") + "/" + strings.TrimLeft(path, "/")
+}
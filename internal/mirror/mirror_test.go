@@ -0,0 +1,355 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/storage/storagetest"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// fakeProvider keeps uploads in memory
+type fakeProvider struct {
+	name    string
+	failOn  string // Path suffix that fails to upload
+	files   map[string]string
+	uploads []string
+}
+
+func newFakeProvider(name string) *fakeProvider {
+	return &fakeProvider{name: name, files: make(map[string]string)}
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Upload(ctx context.Context, path string, data io.Reader, size int64, previous string) (string, error) {
+	if p.failOn != "" && strings.HasSuffix(path, p.failOn) {
+		return "", errors.New("quota exceeded")
+	}
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return "", err
+	}
+	if int64(len(content)) != size {
+		return "", fmt.Errorf("got %d bytes, announced %d", len(content), size)
+	}
+	id := previous
+	if id == "" {
+		id = fmt.Sprintf("%s-%d", p.name, len(p.files)+1)
+	}
+	p.files[path] = string(content)
+	p.uploads = append(p.uploads, path)
+	return id, nil
+}
+
+func (p *fakeProvider) Download(ctx context.Context, path, id string) (io.ReadCloser, error) {
+	content, ok := p.files[path]
+	if !ok {
+		return nil, ErrRemoteMissing
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func TestMirror_PushAndStatus(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := storagetest.NewNFT(wallet, "Mirrored")
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	local := storagetest.NewNFT(wallet, "LocalOnly")
+	if err := store.SaveNFT(ctx, local); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	dropbox, drive := newFakeProvider("dropbox"), newFakeProvider("gdrive")
+	m := New(store, dropbox, drive)
+
+	uploaded, err := m.Push(ctx, wallet, nft.MintAddress)
+	if err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if uploaded != 4 { // nft_data.json and metadata.json to each provider
+		t.Errorf("Uploaded %d file(s), want 4", uploaded)
+	}
+	remotePath := "wallets/" + wallet.String() + "/nfts/" + nft.MintAddress.String() + "/metadata.json"
+	if !strings.Contains(dropbox.files[remotePath], "Mirrored") {
+		t.Errorf("Dropbox is missing %s: %v", remotePath, dropbox.uploads)
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, nft.MintAddress)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	if id := stored.Remote["gdrive"].Files["metadata.json"].ID; id == "" {
+		t.Errorf("Remote file ID was not recorded: %+v", stored.Remote)
+	}
+
+	// Recording the upload must not make nft_data.json look changed
+	if uploaded, err := m.Push(ctx, wallet, nft.MintAddress); err != nil || uploaded != 0 {
+		t.Errorf("Second push uploaded %d file(s) (%v), want 0", uploaded, err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	byMint := make(map[string]*NFTStatus)
+	for _, status := range statuses {
+		byMint[status.Mint] = status
+	}
+	if status := byMint[nft.MintAddress.String()]; status == nil || !status.Synced() || status.LocalOnly() {
+		t.Errorf("Pushed NFT should be mirrored: %+v", status)
+	}
+	if status := byMint[local.MintAddress.String()]; status == nil || !status.LocalOnly() {
+		t.Errorf("Unpushed NFT should be local only: %+v", status)
+	}
+
+	// A re-save keeps the record; new media makes the copy stale, and only
+	// the changed files go up again under their existing IDs
+	nftDir := store.NFTDir(wallet, nft.MintAddress)
+	if err := os.MkdirAll(filepath.Join(nftDir, "media"), 0755); err != nil {
+		t.Fatalf("Failed to create media directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(nftDir, "media", "image.png"), []byte("pixels"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to re-save NFT: %v", err)
+	}
+	statuses, _ = m.Status(ctx)
+	for _, status := range statuses {
+		if status.Mint == nft.MintAddress.String() && status.Providers["dropbox"] != StateStale {
+			t.Errorf("Changed NFT should be stale, got %+v", status)
+		}
+	}
+
+	drive.failOn = "image.png"
+	dropbox.uploads = nil
+	if _, err := m.Push(ctx, wallet, nft.MintAddress); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("Expected the Drive upload to fail, got %v", err)
+	}
+	if len(dropbox.uploads) != 2 { // nft_data.json and media/image.png
+		t.Errorf("Dropbox received %v, want only the changed files", dropbox.uploads)
+	}
+	stored, _ = store.GetNFT(ctx, wallet, nft.MintAddress)
+	if _, ok := stored.Remote["dropbox"].Files["media/image.png"]; !ok {
+		t.Errorf("Dropbox upload was not recorded after Drive failed")
+	}
+	if stored.Remote["gdrive"].Files["metadata.json"].ID == "" {
+		t.Errorf("Drive's earlier uploads were forgotten after a failure")
+	}
+
+	drive.failOn = ""
+	if uploaded, err := m.Push(ctx, wallet, nft.MintAddress); err != nil || uploaded != 2 {
+		t.Errorf("Retry uploaded %d file(s) (%v), want the 2 Drive missed", uploaded, err)
+	}
+}
+
+func TestDropbox_Upload(t *testing.T) {
+	var arg map[string]interface{}
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			r.ParseForm()
+			if r.Form.Get("refresh_token") != "refresh" || r.Form.Get("client_id") != "app" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"fresh","expires_in":14400}`))
+		case "/2/files/upload":
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			header := r.Header.Get("Dropbox-API-Arg")
+			for _, c := range header {
+				if c > 127 {
+					t.Errorf("Dropbox-API-Arg is not ASCII: %s", header)
+					break
+				}
+			}
+			json.Unmarshal([]byte(header), &arg)
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Write([]byte(`{"id":"id:abc123","name":"metadata.json"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	d := NewDropbox(Credentials{RefreshToken: "refresh", ClientID: "app", ClientSecret: "secret"}, "Backups/SolVault/")
+	d.contentURL = ts.URL
+	d.token.tokenURL = ts.URL + "/oauth2/token"
+
+	id, err := d.Upload(context.Background(), "wallets/W/nfts/Café/metadata.json", strings.NewReader("{}"), 2, "")
+	if err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+	if id != "id:abc123" || body != "{}" {
+		t.Errorf("Got id %q and body %q", id, body)
+	}
+	if arg["path"] != "/Backups/SolVault/wallets/W/nfts/Café/metadata.json" || arg["mode"] != "overwrite" {
+		t.Errorf("Unexpected upload arguments: %v", arg)
+	}
+
+	if _, err := d.Upload(context.Background(), "big.mp4", strings.NewReader(""), dropboxMaxUpload+1, ""); err == nil {
+		t.Errorf("Expected files over the single upload limit to be rejected")
+	}
+}
+
+// fakeDrive implements the parts of the Drive v3 API the provider uses
+type fakeDrive struct {
+	mu    sync.Mutex
+	files map[string]driveFile // Keyed by ID
+	next  int
+}
+
+type driveFile struct {
+	Name     string   `json:"name"`
+	MimeType string   `json:"mimeType"`
+	Parents  []string `json:"parents"`
+	Content  string   `json:"-"`
+}
+
+func (d *fakeDrive) add(file driveFile) string {
+	d.next++
+	id := fmt.Sprintf("id%d", d.next)
+	d.files[id] = file
+	return id
+}
+
+func (d *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/files":
+		// Only the folder lookups SolVault sends: name and parent
+		var name, parent string
+		fmt.Sscanf(strings.ReplaceAll(r.URL.Query().Get("q"), "'", " "), "name = %s and %s in parents", &name, &parent)
+		matches := []map[string]string{}
+		for id, file := range d.files {
+			if file.Name == name && file.MimeType == driveFolderType && file.Parents[0] == parent {
+				matches = append(matches, map[string]string{"id": id})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": matches})
+	case r.Method == http.MethodPost && r.URL.Path == "/files":
+		var folder driveFile
+		json.NewDecoder(r.Body).Decode(&folder)
+		json.NewEncoder(w).Encode(map[string]string{"id": d.add(folder)})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/files/"):
+		id := strings.TrimPrefix(r.URL.Path, "/files/")
+		file, ok := d.files[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write([]byte(file.Content))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "trashed": false})
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/upload/files/"):
+		id := strings.TrimPrefix(r.URL.Path, "/upload/files/")
+		data, _ := io.ReadAll(r.Body)
+		file := d.files[id]
+		file.Content = string(data)
+		d.files[id] = file
+		json.NewEncoder(w).Encode(map[string]string{"id": id})
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/files":
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		parts := multipart.NewReader(r.Body, params["boundary"])
+		var file driveFile
+		if part, err := parts.NextPart(); err == nil {
+			json.NewDecoder(part).Decode(&file)
+		}
+		if part, err := parts.NextPart(); err == nil {
+			data, _ := io.ReadAll(part)
+			file.Content = string(data)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": d.add(file)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGoogleDrive_Upload(t *testing.T) {
+	fake := &fakeDrive{files: make(map[string]driveFile)}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	g := NewGoogleDrive(Credentials{AccessToken: "token"}, "")
+	g.apiURL, g.uploadURL = ts.URL, ts.URL+"/upload"
+	ctx := context.Background()
+
+	first, err := g.Upload(ctx, "wallets/W/nfts/M/metadata.json", strings.NewReader("v1"), 2, "")
+	if err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+	if _, err := g.Upload(ctx, "wallets/W/nfts/M/media/image.png", strings.NewReader("png"), 3, ""); err != nil {
+		t.Fatalf("Failed to upload: %v", err)
+	}
+
+	// SolVault, wallets, W, nfts, M and media: six folders, created once each
+	folders := 0
+	for _, file := range fake.files {
+		if file.MimeType == driveFolderType {
+			folders++
+		}
+	}
+	if folders != 6 || len(fake.files) != 8 {
+		t.Errorf("Got %d folder(s) and %d file(s), want 6 and 8", folders, len(fake.files))
+	}
+	created := fake.files[first]
+	if created.Name != "metadata.json" || created.Content != "v1" || fake.files[created.Parents[0]].Name != "M" {
+		t.Errorf("Unexpected file: %+v", created)
+	}
+
+	// Re-uploads update the same file; a file deleted from Drive is recreated
+	if id, err := g.Upload(ctx, "wallets/W/nfts/M/metadata.json", strings.NewReader("v2"), 2, first); err != nil || id != first {
+		t.Fatalf("Failed to update %s: got %q (%v)", first, id, err)
+	}
+	if fake.files[first].Content != "v2" {
+		t.Errorf("File was not updated: %+v", fake.files[first])
+	}
+	delete(fake.files, first)
+	id, err := g.Upload(ctx, "wallets/W/nfts/M/metadata.json", strings.NewReader("v3"), 2, first)
+	if err != nil || id == first || fake.files[id].Content != "v3" {
+		t.Errorf("Deleted file was not recreated: got %q (%v)", id, err)
+	}
+
+	// Downloads read files back by ID
+	body, err := g.Download(ctx, "wallets/W/nfts/M/metadata.json", id)
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "v3" {
+		t.Errorf("Downloaded %q, want v3", data)
+	}
+	if _, err := g.Download(ctx, "wallets/W/nfts/M/metadata.json", first); err != ErrRemoteMissing {
+		t.Errorf("Expected ErrRemoteMissing for a deleted file, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials authorize uploads: either a ready access token, or a refresh
+// token with the OAuth client (app key and secret) that issued it
+//
+// Explanation: Google and Dropbox access tokens expire after a few hours, so
+// a token pasted into ~/.solvault.env only suits a one-off push. A refresh
+// token lets unattended syncs mint fresh access tokens as they go.
+type Credentials struct {
+	AccessToken  string
+	RefreshToken string
+	ClientID     string
+	ClientSecret string
+}
+
+func (c Credentials) configured() bool {
+	return c.AccessToken != "" || c.RefreshToken != ""
+}
+
+// tokenSource hands out access tokens, refreshing them when they expire
+type tokenSource struct {
+	creds    Credentials
+	tokenURL string
+	client   *http.Client
+
+	mu      sync.Mutex
+	access  string
+	expires time.Time // Zero for tokens that were given, not refreshed
+}
+
+func newTokenSource(creds Credentials, tokenURL string, client *http.Client) *tokenSource {
+	return &tokenSource{creds: creds, tokenURL: tokenURL, client: client, access: creds.AccessToken}
+}
+
+// token returns a usable access token
+func (t *tokenSource) token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.creds.RefreshToken == "" {
+		return t.access, nil
+	}
+	if t.access != "" && (t.expires.IsZero() || time.Until(t.expires) > time.Minute) {
+		return t.access, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.creds.RefreshToken},
+		"client_id":     {t.creds.ClientID},
+		"client_secret": {t.creds.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var refreshed struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(t.client, req, &refreshed); err != nil {
+		return "", fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	if refreshed.AccessToken == "" {
+		return "", fmt.Errorf("failed to refresh access token: no token in response")
+	}
+	t.access = refreshed.AccessToken
+	t.expires = time.Now().Add(time.Duration(refreshed.ExpiresIn) * time.Second)
+	return t.access, nil
+}
+
+// statusError is an unexpected HTTP response
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	switch e.code {
+	case http.StatusUnauthorized:
+		return "HTTP 401: access token expired or revoked (configure a refresh token for unattended use)"
+	}
+	if e.body != "" {
+		return fmt.Sprintf("HTTP %d: %s", e.code, e.body)
+	}
+	return fmt.Sprintf("HTTP %d", e.code)
+}
+
+// isNotFound reports whether err is an HTTP 404
+func isNotFound(err error) bool {
+	statusErr, ok := err.(*statusError)
+	return ok && statusErr.code == http.StatusNotFound
+}
+
+// doDownload sends req and returns the body of a successful response, which
+// the caller closes
+func doDownload(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		message := strings.TrimSpace(string(body))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return nil, &statusError{code: resp.StatusCode, body: message}
+	}
+	return resp.Body, nil
+}
+
+// doJSON sends req and decodes a successful JSON response into result
+func doJSON(client *http.Client, req *http.Request, result interface{}) error {
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(body))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return &statusError{code: resp.StatusCode, body: message}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
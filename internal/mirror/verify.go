@@ -0,0 +1,266 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Fetcher is a Provider that can read its files back, which lets
+// 'solvault verify --remote' check the copies it holds
+type Fetcher interface {
+	// Download opens the file uploaded at path with ID id. It returns
+	// ErrRemoteMissing when the provider no longer has the file.
+	Download(ctx context.Context, path, id string) (io.ReadCloser, error)
+}
+
+// ErrRemoteMissing is returned by Download for a file the provider no
+// longer holds
+var ErrRemoteMissing = errors.New("file not found in mirror")
+
+// CopyState is the outcome of checking one mirrored file
+type CopyState string
+
+const (
+	CopyIntact  CopyState = "intact"
+	CopyStale   CopyState = "stale"   // As uploaded, but the local backup changed since
+	CopyCorrupt CopyState = "corrupt" // Matches neither the local backup nor what was uploaded
+	CopyMissing CopyState = "missing" // Recorded as uploaded, but gone from the provider
+)
+
+// CopyCheck is the result of checking one mirrored file
+type CopyCheck struct {
+	Path  string    `json:"path"`
+	State CopyState `json:"state"`
+	Error string    `json:"error,omitempty"` // Why the copy could not be decoded
+}
+
+// CopyReport is the result of checking one provider's copy of an NFT
+type CopyReport struct {
+	Wallet   string      `json:"wallet"`
+	Mint     string      `json:"mint"`
+	Name     string      `json:"name,omitempty"`
+	Provider string      `json:"provider"`
+	Files    []CopyCheck `json:"files"`
+	Error    string      `json:"error,omitempty"` // Why the copy could not be checked
+}
+
+// OK reports whether every file the provider should hold is there and
+// readable. Stale files are reported but do not fail the check: 'solvault
+// mirror push' brings them up to date.
+func (r *CopyReport) OK() bool {
+	return r.Error == "" && r.Count(CopyCorrupt) == 0 && r.Count(CopyMissing) == 0
+}
+
+// Count returns how many files are in state
+func (r *CopyReport) Count(state CopyState) int {
+	n := 0
+	for _, file := range r.Files {
+		if file.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Verify downloads each provider's copy of the NFTs in entries and checks it
+// against the vault. NFTs linked to another wallet's backup are skipped, as
+// they are checked under the wallet that stores them.
+func (m *Mirror) Verify(ctx context.Context, entries []*storage.IndexEntry) ([]*CopyReport, error) {
+	var reports []*CopyReport
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return reports, err
+		}
+		if entry.PrimaryWallet != "" {
+			continue
+		}
+		walletAddr, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			return reports, fmt.Errorf("invalid wallet in index: %w", err)
+		}
+		mintAddr, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			return reports, fmt.Errorf("invalid mint in index: %w", err)
+		}
+		nftReports, err := m.VerifyNFT(ctx, walletAddr, mintAddr)
+		if err != nil {
+			return reports, err
+		}
+		for _, report := range nftReports {
+			report.Name = entry.Name
+		}
+		reports = append(reports, nftReports...)
+	}
+	return reports, nil
+}
+
+// VerifyNFT downloads every file each provider holds for an NFT and
+// reports, per provider, whether the copies still match the vault.
+// Providers the NFT was never mirrored to are left out.
+//
+// Explanation: A file is compared with what the vault holds now first:
+// with integrity.json by its decoded content, so encryption does not get in
+// the way, and nft_data.json by the same fingerprint Push records, since
+// recording the upload rewrites it. A copy that does not match may simply
+// predate a later local change, so it is then compared with the checksum
+// recorded at upload; only a copy matching neither has rotted.
+func (m *Mirror) VerifyNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) ([]*CopyReport, error) {
+	stored, err := m.store.GetNFT(ctx, walletAddr, mintAddr)
+	if err != nil {
+		return nil, err
+	}
+	dir := m.store.NFTDir(walletAddr, mintAddr)
+	remoteDir, err := filepath.Rel(m.store.BaseDir(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate NFT directory: %w", err)
+	}
+	integrity, err := m.store.ReadIntegrity(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	current, err := recordChecksum(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*CopyReport
+	for _, provider := range m.providers {
+		remote := stored.Remote[provider.Name()]
+		if remote == nil || len(remote.Files) == 0 {
+			continue
+		}
+		report := &CopyReport{Wallet: walletAddr.String(), Mint: mintAddr.String(), Provider: provider.Name(), Files: []CopyCheck{}}
+		reports = append(reports, report)
+		fetcher, ok := provider.(Fetcher)
+		if !ok {
+			report.Error = "provider cannot read files back"
+			continue
+		}
+
+		names := make([]string, 0, len(remote.Files))
+		for name := range remote.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			uploaded := remote.Files[name]
+			data, err := download(ctx, fetcher, filepath.ToSlash(filepath.Join(remoteDir, name)), uploaded.ID)
+			if errors.Is(err, ErrRemoteMissing) {
+				report.Files = append(report.Files, CopyCheck{Path: name, State: CopyMissing})
+				continue
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return reports, ctx.Err()
+				}
+				report.Error = fmt.Sprintf("failed to download %s: %v", name, err)
+				break
+			}
+
+			check := CopyCheck{Path: name}
+			check.State, err = m.checkCopy(integrity, current, dir, name, data, uploaded)
+			if errors.Is(err, crypt.ErrLocked) {
+				return reports, err
+			}
+			if err != nil {
+				check.Error = err.Error()
+			}
+			report.Files = append(report.Files, check)
+		}
+	}
+	return reports, nil
+}
+
+// download reads a whole mirrored file
+func download(ctx context.Context, fetcher Fetcher, path, id string) ([]byte, error) {
+	body, err := fetcher.Download(ctx, path, id)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download: %w", err)
+	}
+	return data, nil
+}
+
+// checkCopy works out the state of one downloaded file. The error explains
+// a corrupt copy that could not be decoded.
+func (m *Mirror) checkCopy(integrity *storage.Integrity, current, dir, name string, data []byte, uploaded storage.RemoteFile) (CopyState, error) {
+	sum := sha256.Sum256(data)
+	raw := hex.EncodeToString(sum[:])
+
+	if name == "nft_data.json" {
+		checksum, err := m.copyRecordChecksum(data)
+		switch {
+		case errors.Is(err, crypt.ErrLocked):
+			return "", err
+		case err != nil:
+			return CopyCorrupt, err
+		case checksum == current:
+			return CopyIntact, nil
+		case checksum == uploaded.Checksum:
+			return CopyStale, nil
+		}
+		return CopyCorrupt, nil
+	}
+
+	var matches bool
+	var err error
+	if want, ok := integrityEntry(integrity, name); ok {
+		var digest storage.FileDigest
+		digest, err = m.store.DigestCopy(data, want)
+		matches = err == nil && digest.SHA256 == want.SHA256 && (want.Canonical || digest.Size == want.Size)
+	} else {
+		// Not in the manifest: compare with the file on disk as it is now
+		local, localErr := fileChecksum(filepath.Join(dir, filepath.FromSlash(name)))
+		matches = localErr == nil && local == raw
+	}
+	if errors.Is(err, crypt.ErrLocked) {
+		return "", err
+	}
+
+	switch {
+	case matches:
+		return CopyIntact, nil
+	case raw == uploaded.Checksum:
+		return CopyStale, nil
+	default:
+		return CopyCorrupt, err
+	}
+}
+
+// copyRecordChecksum fingerprints a mirrored nft_data.json like
+// recordChecksum
+func (m *Mirror) copyRecordChecksum(data []byte) (string, error) {
+	data, err := m.store.Decode(data)
+	if err != nil {
+		return "", err
+	}
+	var stored storage.StoredNFT
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return "", fmt.Errorf("failed to parse NFT data: %w", err)
+	}
+	return recordChecksum(&stored)
+}
+
+func integrityEntry(integrity *storage.Integrity, name string) (storage.FileDigest, bool) {
+	if integrity == nil {
+		return storage.FileDigest{}, false
+	}
+	want, ok := integrity.Files[name]
+	return want, ok
+}
@@ -0,0 +1,119 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/storage/storagetest"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestMirror_Verify(t *testing.T) {
+	store, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := storagetest.NewNFT(wallet, "Verified")
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	unpushed := storagetest.NewNFT(wallet, "LocalOnly")
+	if err := store.SaveNFT(ctx, unpushed); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	dropbox, drive := newFakeProvider("dropbox"), newFakeProvider("gdrive")
+	m := New(store, dropbox, drive)
+	if _, err := m.Push(ctx, wallet, nft.MintAddress); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+
+	states := func() map[string]map[string]CopyState {
+		t.Helper()
+		reports, err := m.Verify(ctx, store.Index().List())
+		if err != nil {
+			t.Fatalf("Failed to verify: %v", err)
+		}
+		states := make(map[string]map[string]CopyState)
+		for _, report := range reports {
+			if report.Mint != nft.MintAddress.String() || report.Error != "" {
+				t.Fatalf("Unexpected report: %+v", report)
+			}
+			states[report.Provider] = make(map[string]CopyState)
+			for _, file := range report.Files {
+				states[report.Provider][file.Path] = file.State
+			}
+		}
+		return states
+	}
+
+	got := states()
+	for _, provider := range []string{"dropbox", "gdrive"} {
+		for _, name := range []string{"nft_data.json", "metadata.json"} {
+			if got[provider][name] != CopyIntact {
+				t.Errorf("%s %s = %q, want intact", provider, name, got[provider][name])
+			}
+		}
+	}
+
+	// Bit rot in one provider, a lost file in the other
+	remoteDir := "wallets/" + wallet.String() + "/nfts/" + nft.MintAddress.String() + "/"
+	dropbox.files[remoteDir+"metadata.json"] = strings.Replace(dropbox.files[remoteDir+"metadata.json"], "Verified", "Verifjed", 1)
+	delete(drive.files, remoteDir+"nft_data.json")
+	got = states()
+	if got["dropbox"]["metadata.json"] != CopyCorrupt || got["dropbox"]["nft_data.json"] != CopyIntact {
+		t.Errorf("Expected only Dropbox's metadata.json corrupt, got %v", got["dropbox"])
+	}
+	if got["gdrive"]["nft_data.json"] != CopyMissing || got["gdrive"]["metadata.json"] != CopyIntact {
+		t.Errorf("Expected only Drive's nft_data.json missing, got %v", got["gdrive"])
+	}
+
+	// A local change since the push leaves the copy stale, not corrupt
+	nft.Metadata.Description = "Changed after the push"
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to re-save NFT: %v", err)
+	}
+	if got := states(); got["gdrive"]["metadata.json"] != CopyStale || got["dropbox"]["metadata.json"] != CopyCorrupt {
+		t.Errorf("Expected Drive's metadata.json stale after a local change, got %v", got)
+	}
+}
+
+func TestDropbox_Download(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/files/download" || r.Header.Get("Authorization") != "Bearer token" {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Dropbox-API-Arg"), `"/SolVault/wallets/W/metadata.json"`) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_summary":"path/not_found/..","error":{".tag":"path"}}`))
+			return
+		}
+		w.Write([]byte(`{"name":"Cool Cat"}`))
+	}))
+	defer ts.Close()
+
+	d := NewDropbox(Credentials{AccessToken: "token"}, "")
+	d.contentURL = ts.URL
+
+	body, err := d.Download(context.Background(), "wallets/W/metadata.json", "id:abc")
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != `{"name":"Cool Cat"}` {
+		t.Errorf("Downloaded %q", data)
+	}
+
+	if _, err := d.Download(context.Background(), "wallets/W/gone.json", "id:def"); err != ErrRemoteMissing {
+		t.Errorf("Expected ErrRemoteMissing for a deleted file, got %v", err)
+	}
+}
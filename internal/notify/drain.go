@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DrainDetector raises a critical alert when more than Limit NFTs leave one
+// wallet within Window, the pattern of a compromised wallet being emptied
+//
+// Explanation: A single departure is usually a sale the owner made, and is
+// alerted on its own. A drainer moves everything of value within minutes,
+// so a burst of departures is the stronger signal. One alert is sent per
+// burst; the detector re-arms once a whole Window passes without one.
+type DrainDetector struct {
+	Limit    int           // Departures allowed within Window; 0 disables the rule
+	Window   time.Duration // How far back departures are counted
+	Notifier Notifier
+
+	departures map[string][]departure // Per wallet, oldest first, within Window
+	alerted    map[string]bool
+}
+
+type departure struct {
+	mint string
+	at   time.Time
+}
+
+// Observe records the mints that left wallet in one check. It returns the
+// drain alert sent, if this check tipped the wallet over the limit.
+func (d *DrainDetector) Observe(ctx context.Context, wallet string, departed []string, now time.Time) (*Event, error) {
+	if d.Limit <= 0 {
+		return nil, nil
+	}
+	if d.departures == nil {
+		d.departures, d.alerted = make(map[string][]departure), make(map[string]bool)
+	}
+
+	recent := d.departures[wallet]
+	for len(recent) > 0 && now.Sub(recent[0].at) > d.Window {
+		recent = recent[1:]
+	}
+	if len(recent) == 0 {
+		d.alerted[wallet] = false
+	}
+	for _, mint := range departed {
+		recent = append(recent, departure{mint: mint, at: now})
+	}
+	d.departures[wallet] = recent
+
+	if d.alerted[wallet] || len(recent) <= d.Limit {
+		return nil, nil
+	}
+
+	d.alerted[wallet] = true
+	mints := make([]string, len(recent))
+	for i, dep := range recent {
+		mints[i] = dep.mint
+	}
+	event := Event{
+		Kind:     EventDrain,
+		Priority: PriorityCritical,
+		Title:    fmt.Sprintf("Possible wallet drain: %d NFTs left %s", len(recent), wallet),
+		Message: fmt.Sprintf("%d NFTs left the wallet within %s, more than the %d allowed. "+
+			"If you are not moving them yourself, the wallet is likely compromised: revoke its approvals and move what is left to a new wallet now.",
+			len(recent), d.Window, d.Limit),
+		Time: now,
+		Fields: map[string]string{
+			"Wallet":      wallet,
+			"First left":  recent[0].at.Format(time.RFC3339),
+			"Departed":    fmt.Sprintf("%d in %s", len(recent), now.Sub(recent[0].at).Round(time.Second)),
+			"Mints taken": strings.Join(mints, ", "),
+		},
+	}
+	if d.Notifier == nil {
+		return &event, nil
+	}
+	return &event, d.Notifier.Notify(ctx, event)
+}
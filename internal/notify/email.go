@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TLSMode selects how the SMTP connection is secured
+type TLSMode string
+
+const (
+	TLSStartTLS TLSMode = "starttls" // Upgrade a plain connection (usually port 587)
+	TLSImplicit TLSMode = "tls"      // TLS from the first byte (usually port 465)
+	TLSNone     TLSMode = "none"     // Plain text; only for local relays
+)
+
+// DefaultSubjectTemplate and DefaultBodyTemplate render every event unless
+// custom templates are configured
+const (
+	DefaultSubjectTemplate = `[SolVault]{{if eq .Priority "critical"}} CRITICAL:{{else if eq .Priority "high"}} URGENT:{{end}} {{.Title}}`
+	DefaultBodyTemplate    = `{{.Message}}
+{{range .FieldList}}
+{{.Key}}: {{.Value}}{{end}}
+
+Time: {{.Time.Format "2006-01-02 15:04:05 MST"}}
+Event: {{.Kind}}
+`
+)
+
+// EmailConfig configures the SMTP sink
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      TLSMode
+
+	// InsecureSkipVerify disables certificate checks (self-signed relays)
+	InsecureSkipVerify bool
+
+	// Subject and body are text/template sources rendered with the Event
+	SubjectTemplate string
+	BodyTemplate    string
+
+	Timeout time.Duration
+}
+
+// EmailConfigFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD, SMTP_FROM, SMTP_TO, SMTP_TLS, SMTP_INSECURE_SKIP_VERIFY and
+// the optional template files SMTP_SUBJECT_TEMPLATE / SMTP_BODY_TEMPLATE
+func EmailConfigFromEnv(getenv func(string) string) (EmailConfig, error) {
+	cfg := EmailConfig{
+		Host:     getenv("SMTP_HOST"),
+		Username: getenv("SMTP_USERNAME"),
+		Password: getenv("SMTP_PASSWORD"),
+		From:     getenv("SMTP_FROM"),
+		TLS:      TLSMode(strings.ToLower(getenv("SMTP_TLS"))),
+	}
+
+	cfg.Port = 587
+	if port := getenv("SMTP_PORT"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid SMTP_PORT %q: %w", port, err)
+		}
+		cfg.Port = n
+	}
+
+	for _, addr := range strings.FieldsFunc(getenv("SMTP_TO"), func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		cfg.To = append(cfg.To, addr)
+	}
+
+	cfg.InsecureSkipVerify, _ = strconv.ParseBool(getenv("SMTP_INSECURE_SKIP_VERIFY"))
+
+	for _, tmpl := range []struct {
+		env    string
+		target *string
+	}{
+		{"SMTP_SUBJECT_TEMPLATE", &cfg.SubjectTemplate},
+		{"SMTP_BODY_TEMPLATE", &cfg.BodyTemplate},
+	} {
+		path := getenv(tmpl.env)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read %s: %w", tmpl.env, err)
+		}
+		*tmpl.target = string(data)
+	}
+
+	return cfg, nil
+}
+
+// Email sends events as plain-text mail over SMTP
+type Email struct {
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewEmail validates cfg and parses its templates
+func NewEmail(cfg EmailConfig) (*Email, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("SMTP from address is required")
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("at least one SMTP recipient is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.TLS == "" {
+		cfg.TLS = TLSStartTLS
+		if cfg.Port == 465 {
+			cfg.TLS = TLSImplicit
+		}
+	}
+	switch cfg.TLS {
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q (use starttls, tls or none)", cfg.TLS)
+	}
+	if cfg.SubjectTemplate == "" {
+		cfg.SubjectTemplate = DefaultSubjectTemplate
+	}
+	if cfg.BodyTemplate == "" {
+		cfg.BodyTemplate = DefaultBodyTemplate
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	subject, err := template.New("subject").Parse(cfg.SubjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	body, err := template.New("body").Parse(cfg.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return &Email{cfg: cfg, subject: subject, body: body}, nil
+}
+
+// Name identifies the sink
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Notify renders the event and sends it to every recipient
+func (e *Email) Notify(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	message, err := e.render(event)
+	if err != nil {
+		return err
+	}
+	return e.send(ctx, message)
+}
+
+// render builds the full RFC 5322 message
+func (e *Email) render(event Event) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, event); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := e.body.Execute(&body, event); err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	// Subjects must stay on one header line
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.Join(strings.Fields(subject.String()), " "))
+	fmt.Fprintf(&msg, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@solvault>\r\n", hex.EncodeToString(id))
+	if event.Priority == PriorityHigh || event.Priority == PriorityCritical {
+		// Mail clients flag these and some phones let them through do-not-disturb
+		msg.WriteString("X-Priority: 1 (Highest)\r\n")
+		msg.WriteString("Importance: high\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(body.String(), "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+func (e *Email) send(ctx context.Context, message []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: e.cfg.Host, InsecureSkipVerify: e.cfg.InsecureSkipVerify}
+
+	dialer := &net.Dialer{Timeout: e.cfg.Timeout}
+	var conn net.Conn
+	var err error
+	if e.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if e.cfg.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS (set SMTP_TLS=none for plain relays)", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.cfg.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, to := range e.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,113 @@
+// Package notify delivers alerts about vault problems, such as tampered
+// media or a watcher that lost connectivity, to configured sinks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventKind identifies what an alert is about
+type EventKind string
+
+const (
+	EventTest      EventKind = "test"
+	EventTamper    EventKind = "tamper_detected"
+	EventOffline   EventKind = "watcher_offline"
+	EventRecovered EventKind = "watcher_recovered"
+	EventDeparted  EventKind = "nft_departed"
+	EventDrain     EventKind = "wallet_drain"
+	EventStorage   EventKind = "storage_low"
+)
+
+// Priority tells sinks how urgently an event should reach its reader
+type Priority string
+
+const (
+	PriorityNormal   Priority = ""
+	PriorityHigh     Priority = "high"     // Needs action now, e.g. a possible theft
+	PriorityCritical Priority = "critical" // Damage is happening, e.g. a wallet being drained
+)
+
+// Event is one alert
+type Event struct {
+	Kind     EventKind         `json:"kind"`
+	Priority Priority          `json:"priority,omitempty"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Time     time.Time         `json:"time"`
+	Fields   map[string]string `json:"fields,omitempty"` // Extra details, rendered as "key: value" lines
+}
+
+// FieldList returns the event's fields sorted by key, for templates
+func (e Event) FieldList() []Field {
+	fields := make([]Field, 0, len(e.Fields))
+	for key, value := range e.Fields {
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+// Field is one key/value detail of an event
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Notifier delivers events to one destination
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// Multi fans an event out to several notifiers, attempting all of them
+type Multi []Notifier
+
+// Name lists the sinks
+func (m Multi) Name() string {
+	names := make([]string, len(m))
+	for i, n := range m {
+		names[i] = n.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Notify sends to every sink and joins their errors
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FromEnv builds the notifiers configured in the environment. It returns
+// an empty Multi when nothing is configured.
+func FromEnv(getenv func(string) string) (Multi, error) {
+	var sinks Multi
+
+	if getenv("SMTP_HOST") != "" {
+		cfg, err := EmailConfigFromEnv(getenv)
+		if err != nil {
+			return nil, err
+		}
+		email, err := NewEmail(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, email)
+	}
+
+	return sinks, nil
+}
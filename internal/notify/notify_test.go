@@ -0,0 +1,298 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one plain-text SMTP session and returns what it received
+func fakeSMTP(t *testing.T) (host string, port int, received <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+
+		var transcript strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line)
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if data == ".\r\n" {
+						break
+					}
+					transcript.WriteString(data)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- transcript.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, out
+}
+
+func TestEmail_Notify(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+
+	email, err := NewEmail(EmailConfig{
+		Host: host,
+		Port: port,
+		From: "vault@example.com",
+		To:   []string{"me@example.com", "you@example.com"},
+		TLS:  TLSNone,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email sink: %v", err)
+	}
+
+	event := Event{
+		Kind:    EventTamper,
+		Title:   "2 tampered NFTs",
+		Message: "verify --all found hash mismatches.",
+		Fields:  map[string]string{"Tampered": "2"},
+	}
+	if err := email.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{
+			"MAIL FROM:<vault@example.com>",
+			"RCPT TO:<me@example.com>",
+			"RCPT TO:<you@example.com>",
+			"Subject: [SolVault] 2 tampered NFTs",
+			"verify --all found hash mismatches.",
+			"Tampered: 2",
+			"Event: tamper_detected",
+		} {
+			if !strings.Contains(transcript, want) {
+				t.Errorf("Transcript missing %q:\n%s", want, transcript)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the message")
+	}
+}
+
+func TestEmail_HighPriority(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+	email, err := NewEmail(EmailConfig{Host: host, Port: port, From: "vault@example.com", To: []string{"me@example.com"}, TLS: TLSNone})
+	if err != nil {
+		t.Fatalf("Failed to create email sink: %v", err)
+	}
+
+	event := Event{Kind: EventDeparted, Priority: PriorityHigh, Title: "Cat #1 left your wallet", Message: "Check the transaction."}
+	if err := email.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{"Subject: [SolVault] URGENT: Cat #1 left your wallet", "X-Priority: 1", "Importance: high"} {
+			if !strings.Contains(transcript, want) {
+				t.Errorf("Transcript missing %q:\n%s", want, transcript)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the message")
+	}
+}
+
+func TestEmailConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"SMTP_HOST": "smtp.example.com",
+		"SMTP_PORT": "465",
+		"SMTP_FROM": "vault@example.com",
+		"SMTP_TO":   "a@example.com, b@example.com",
+	}
+	cfg, err := EmailConfigFromEnv(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if cfg.Port != 465 || len(cfg.To) != 2 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+
+	email, err := NewEmail(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create email sink: %v", err)
+	}
+	if email.cfg.TLS != TLSImplicit {
+		t.Errorf("Port 465 should default to implicit TLS, got %q", email.cfg.TLS)
+	}
+
+	tests := []struct {
+		name string
+		cfg  EmailConfig
+	}{
+		{"missing host", EmailConfig{From: "a@b", To: []string{"c@d"}}},
+		{"missing recipients", EmailConfig{Host: "h", From: "a@b"}},
+		{"bad tls mode", EmailConfig{Host: "h", From: "a@b", To: []string{"c@d"}, TLS: "ssl3"}},
+		{"bad template", EmailConfig{Host: "h", From: "a@b", To: []string{"c@d"}, BodyTemplate: "{{.Nope"}},
+	}
+	for _, tt := range tests {
+		if _, err := NewEmail(tt.cfg); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	env["SMTP_PORT"] = "not-a-port"
+	if _, err := EmailConfigFromEnv(func(key string) string { return env[key] }); err == nil {
+		t.Error("Expected invalid port to fail")
+	}
+}
+
+// recorder collects events instead of sending them
+type recorder struct{ events []Event }
+
+func (r *recorder) Name() string { return "recorder" }
+func (r *recorder) Notify(ctx context.Context, event Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestWatchdog(t *testing.T) {
+	rec := &recorder{}
+	dog := &Watchdog{Threshold: 10 * time.Minute, Notifier: rec, Subject: "Solana RPC"}
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	down := errors.New("connection refused")
+
+	steps := []struct {
+		offset time.Duration
+		err    error
+		want   EventKind
+	}{
+		{0, down, ""},
+		{5 * time.Minute, down, ""},
+		{10 * time.Minute, down, EventOffline},
+		{15 * time.Minute, down, ""}, // One alert per outage
+		{20 * time.Minute, nil, EventRecovered},
+		{25 * time.Minute, nil, ""},
+		{30 * time.Minute, down, ""}, // New outage starts the clock again
+	}
+
+	for i, step := range steps {
+		event, err := dog.Observe(ctx, step.err, start.Add(step.offset))
+		if err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		var got EventKind
+		if event != nil {
+			got = event.Kind
+		}
+		if got != step.want {
+			t.Errorf("Step %d: event %q, want %q", i, got, step.want)
+		}
+	}
+
+	if len(rec.events) != 2 {
+		t.Errorf("Expected 2 delivered events, got %d", len(rec.events))
+	}
+}
+
+func TestDrainDetector(t *testing.T) {
+	rec := &recorder{}
+	drain := &DrainDetector{Limit: 2, Window: 10 * time.Minute, Notifier: rec}
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		offset   time.Duration
+		wallet   string
+		departed []string
+		want     EventKind
+	}{
+		{0, "walletA", []string{"m1"}, ""},
+		{time.Minute, "walletB", []string{"m2", "m3"}, ""}, // Counted per wallet
+		{2 * time.Minute, "walletA", []string{"m4"}, ""},
+		{3 * time.Minute, "walletA", []string{"m5"}, EventDrain},
+		{4 * time.Minute, "walletA", []string{"m6"}, ""}, // One alert per burst
+		{15 * time.Minute, "walletA", nil, ""},
+		{30 * time.Minute, "walletA", []string{"m7", "m8"}, ""}, // Re-armed after a quiet window
+		{31 * time.Minute, "walletA", []string{"m9"}, EventDrain},
+		{70 * time.Minute, "walletA", []string{"m10", "m11"}, ""}, // Old departures age out
+		{71 * time.Minute, "walletA", []string{"m12"}, EventDrain},
+	}
+
+	for i, step := range steps {
+		event, err := drain.Observe(ctx, step.wallet, step.departed, start.Add(step.offset))
+		if err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		var got EventKind
+		if event != nil {
+			got = event.Kind
+		}
+		if got != step.want {
+			t.Errorf("Step %d: event %q, want %q", i, got, step.want)
+		}
+	}
+
+	if len(rec.events) != 3 || rec.events[0].Priority != PriorityCritical || rec.events[0].Fields["Mints taken"] != "m1, m4, m5" {
+		t.Errorf("Expected 3 critical alerts, the first naming m1, m4 and m5, got %+v", rec.events)
+	}
+
+	if event, _ := (&DrainDetector{Window: time.Minute}).Observe(ctx, "walletA", []string{"m1", "m2"}, start); event != nil {
+		t.Errorf("A zero limit should disable the rule, got %+v", event)
+	}
+}
+
+func TestMulti_JoinsErrors(t *testing.T) {
+	rec := &recorder{}
+	failing := failingNotifier{}
+	err := Multi{failing, rec}.Notify(context.Background(), Event{Kind: EventTest, Title: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "failing") {
+		t.Errorf("Expected joined error naming the sink, got %v", err)
+	}
+	if len(rec.events) != 1 || rec.events[0].Time.IsZero() {
+		t.Errorf("Expected remaining sinks to still receive a timestamped event, got %+v", rec.events)
+	}
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Name() string { return "failing" }
+func (failingNotifier) Notify(ctx context.Context, event Event) error {
+	return errors.New("down")
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Watchdog turns a stream of connectivity checks into at most one offline
+// alert per outage, followed by a recovery alert
+type Watchdog struct {
+	Threshold time.Duration // How long checks must fail before alerting
+	Notifier  Notifier
+	Subject   string // What is being watched, e.g. "Solana RPC"
+
+	failingSince time.Time
+	alerted      bool
+}
+
+// Observe records one check result. It returns the event sent, if any.
+func (w *Watchdog) Observe(ctx context.Context, err error, now time.Time) (*Event, error) {
+	if err == nil {
+		wasAlerted, since := w.alerted, w.failingSince
+		w.failingSince, w.alerted = time.Time{}, false
+		if !wasAlerted {
+			return nil, nil
+		}
+
+		event := Event{
+			Kind:    EventRecovered,
+			Title:   fmt.Sprintf("%s connectivity restored", w.Subject),
+			Message: fmt.Sprintf("The watcher reached %s again after %s offline.", w.Subject, now.Sub(since).Round(time.Second)),
+			Time:    now,
+		}
+		return &event, w.send(ctx, event)
+	}
+
+	if w.failingSince.IsZero() {
+		w.failingSince = now
+	}
+	if w.alerted || now.Sub(w.failingSince) < w.Threshold {
+		return nil, nil
+	}
+
+	w.alerted = true
+	event := Event{
+		Kind:    EventOffline,
+		Title:   fmt.Sprintf("Watcher cannot reach %s", w.Subject),
+		Message: fmt.Sprintf("The watcher has been unable to reach %s for %s. New NFTs are not being backed up.", w.Subject, now.Sub(w.failingSince).Round(time.Second)),
+		Time:    now,
+		Fields: map[string]string{
+			"Offline since": w.failingSince.Format(time.RFC3339),
+			"Last error":    err.Error(),
+		},
+	}
+	return &event, w.send(ctx, event)
+}
+
+func (w *Watchdog) send(ctx context.Context, event Event) error {
+	if w.Notifier == nil {
+		return nil
+	}
+	return w.Notifier.Notify(ctx, event)
+}
@@ -0,0 +1,128 @@
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Assumptions behind the time estimate. Each NFT costs a few RPC calls and a
+// metadata fetch before any media is downloaded.
+const (
+	// PerAssetOverhead is the fixed cost of fetching one NFT's metadata
+	PerAssetOverhead = 1500 * time.Millisecond
+	// DownloadRate is the assumed media throughput in bytes per second
+	DownloadRate = 4 << 20
+	// FallbackMediaSize is assumed per asset when no media could be measured
+	FallbackMediaSize = 2 << 20
+)
+
+// Sizer reports the size of a remote file without downloading it
+type Sizer interface {
+	Size(ctx context.Context, uri string) (int64, error)
+}
+
+// HTTPSizer measures media with HEAD requests
+type HTTPSizer struct {
+	client *http.Client
+}
+
+// NewHTTPSizer creates a sizer with a short per-request timeout, since a
+// slow gateway should not stall the whole estimate
+func NewHTTPSizer() *HTTPSizer {
+	return &HTTPSizer{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Size returns the Content-Length of uri. Only HTTP(S) URIs can be measured.
+func (s *HTTPSizer) Size(ctx context.Context, uri string) (int64, error) {
+	if !strings.HasPrefix(uri, "http://") && !strings.HasPrefix(uri, "https://") {
+		return 0, fmt.Errorf("cannot measure %s", uri)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, uri, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("HEAD request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HEAD request returned HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("server did not report a size for %s", uri)
+	}
+	return resp.ContentLength, nil
+}
+
+// Estimate measures the media of up to sampleSize assets per group and
+// extrapolates each group's total size and backup time from the sample
+//
+// Explanation: Measuring every file of a 10k-item wallet would take longer
+// than some backups. Collections within a group tend to use similar media,
+// so the average of a spread-out sample is a good enough guide for deciding
+// what to back up.
+func Estimate(ctx context.Context, scan *Scan, sizer Sizer, sampleSize int) {
+	assets := make(map[string]Asset, len(scan.Assets))
+	for _, asset := range scan.Assets {
+		assets[asset.Mint] = asset
+	}
+
+	for _, summary := range scan.Groups {
+		var measured int64
+		summary.Sampled = 0
+		for _, mint := range sample(summary.Mints, sampleSize) {
+			if ctx.Err() != nil {
+				break
+			}
+			size, ok := assetSize(ctx, sizer, assets[mint])
+			if ok {
+				measured += size
+				summary.Sampled++
+			}
+		}
+
+		perAsset := int64(FallbackMediaSize)
+		if summary.Sampled > 0 {
+			perAsset = measured / int64(summary.Sampled)
+		}
+		summary.EstimatedBytes = perAsset * int64(summary.Count)
+		summary.EstimatedTime = BackupTime(summary.Count, summary.EstimatedBytes)
+	}
+}
+
+// BackupTime estimates how long backing up count assets holding bytes of media takes
+func BackupTime(count int, bytes int64) time.Duration {
+	download := time.Duration(float64(bytes) / DownloadRate * float64(time.Second))
+	return time.Duration(count)*PerAssetOverhead + download
+}
+
+// assetSize sums an asset's media, reporting false if none could be measured
+func assetSize(ctx context.Context, sizer Sizer, asset Asset) (int64, bool) {
+	var total int64
+	measured := false
+	for _, uri := range asset.MediaURIs {
+		if size, err := sizer.Size(ctx, uri); err == nil {
+			total += size
+			measured = true
+		}
+	}
+	return total, measured
+}
+
+// sample picks up to n items spread evenly across the list
+func sample(items []string, n int) []string {
+	if n <= 0 || len(items) <= n {
+		return items
+	}
+	picked := make([]string, n)
+	for i := range picked {
+		picked[i] = items[i*len(items)/n]
+	}
+	return picked
+}
@@ -0,0 +1,237 @@
+// Package onboard sizes up a wallet before its first backup: it sorts the
+// wallet's assets into groups a collector recognises and estimates how much
+// media each group would add to the vault and how long it would take.
+package onboard
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/das"
+)
+
+// Group is a class of assets that is backed up (or skipped) as a whole
+type Group string
+
+const (
+	GroupNFT        Group = "nft"
+	GroupPNFT       Group = "pnft"
+	GroupCompressed Group = "cnft"
+	GroupSpam       Group = "spam"
+	GroupOther      Group = "other" // Fungible tokens and assets SolVault does not back up
+)
+
+// Groups lists the groups in display order
+var Groups = []Group{GroupNFT, GroupPNFT, GroupCompressed, GroupSpam, GroupOther}
+
+// Label is the group's name for people
+func (g Group) Label() string {
+	switch g {
+	case GroupNFT:
+		return "NFTs"
+	case GroupPNFT:
+		return "Programmable NFTs"
+	case GroupCompressed:
+		return "Compressed NFTs"
+	case GroupSpam:
+		return "Suspected spam"
+	default:
+		return "Other assets"
+	}
+}
+
+// Backupable reports whether SolVault can back up the group's assets.
+// Compressed NFTs have no mint or token account for the backup to read, so
+// compressed spam is skipped even when the spam group is chosen.
+func (g Group) Backupable() bool {
+	return g == GroupNFT || g == GroupPNFT || g == GroupSpam
+}
+
+// DefaultSelected reports whether the group is backed up unless the
+// collector says otherwise
+func (g Group) DefaultSelected() bool {
+	return g == GroupNFT || g == GroupPNFT
+}
+
+// Asset is one classified wallet asset
+type Asset struct {
+	Mint       string   `json:"mint"`
+	Name       string   `json:"name,omitempty"`
+	Group      Group    `json:"group"`
+	Compressed bool     `json:"compressed,omitempty"`
+	SpamReason string   `json:"spam_reason,omitempty"`
+	MediaURIs  []string `json:"media_uris,omitempty"`
+}
+
+// Classify sorts a DAS asset into a group. Spam is checked first, since
+// most spam arrives as otherwise ordinary (often compressed) NFTs.
+func Classify(asset das.Asset) Asset {
+	classified := Asset{
+		Mint:       asset.ID,
+		Name:       asset.Content.Metadata.Name,
+		Compressed: asset.Compression.Compressed,
+		MediaURIs:  mediaURIs(asset),
+	}
+
+	if reason := spamReason(asset); reason != "" && isNFTInterface(asset.Interface) {
+		classified.Group = GroupSpam
+		classified.SpamReason = reason
+		return classified
+	}
+
+	switch {
+	case !isNFTInterface(asset.Interface):
+		classified.Group = GroupOther
+	case asset.Compression.Compressed:
+		classified.Group = GroupCompressed
+	case asset.Interface == "ProgrammableNFT":
+		classified.Group = GroupPNFT
+	default:
+		classified.Group = GroupNFT
+	}
+	return classified
+}
+
+// isNFTInterface reports whether a DAS interface is a token-based NFT
+func isNFTInterface(iface string) bool {
+	switch iface {
+	case "V1_NFT", "V1_PRINT", "LEGACY_NFT", "V2_NFT", "ProgrammableNFT":
+		return true
+	}
+	return false
+}
+
+var (
+	// spamDomain matches a bare web address in a name or description
+	spamDomain = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|io|xyz|net|org|app|site|fun|gg|live|pro|top|click)\b)`)
+
+	// spamPhrases are the lures spam airdrops use to get a wallet to sign
+	spamPhrases = []string{"claim", "reward", "airdrop", "voucher", "giveaway", "redeem", "free mint", "visit", "eligible"}
+)
+
+// spamReason explains why an asset looks like spam, or returns "" if it does not
+func spamReason(asset das.Asset) string {
+	name := asset.Content.Metadata.Name
+	if match := spamDomain.FindString(name); match != "" {
+		return "name links to " + match
+	}
+
+	text := strings.ToLower(name + " " + asset.Content.Metadata.Symbol + " " + asset.Content.Metadata.Description)
+	if !spamDomain.MatchString(text) {
+		return ""
+	}
+	for _, phrase := range spamPhrases {
+		if strings.Contains(text, phrase) {
+			return "asks you to " + phrase + " on a website"
+		}
+	}
+	return ""
+}
+
+// mediaURIs lists the distinct files a backup would download
+func mediaURIs(asset das.Asset) []string {
+	var uris []string
+	seen := make(map[string]bool)
+	add := func(uri string) {
+		if uri != "" && !seen[uri] {
+			seen[uri] = true
+			uris = append(uris, uri)
+		}
+	}
+
+	add(asset.Content.Links.Image)
+	for _, file := range asset.Content.Files {
+		add(file.URI)
+	}
+	return uris
+}
+
+// GroupSummary is the scan result for one group
+type GroupSummary struct {
+	Group          Group         `json:"group"`
+	Label          string        `json:"label"`
+	Count          int           `json:"count"`
+	Backupable     bool          `json:"backupable"`
+	Sampled        int           `json:"sampled"`         // Assets whose media sizes were measured
+	EstimatedBytes int64         `json:"estimated_bytes"` // Extrapolated from the sample
+	EstimatedTime  time.Duration `json:"estimated_time"`
+	Mints          []string      `json:"mints"`
+}
+
+// Scan is the outcome of onboarding a wallet
+type Scan struct {
+	Wallet    string          `json:"wallet"`
+	ScannedAt time.Time       `json:"scanned_at"`
+	Total     int             `json:"total"`
+	Groups    []*GroupSummary `json:"groups"`
+	Assets    []Asset         `json:"assets"`
+}
+
+// Group returns the summary for g, or nil if the wallet holds none
+func (s *Scan) Group(g Group) *GroupSummary {
+	for _, summary := range s.Groups {
+		if summary.Group == g {
+			return summary
+		}
+	}
+	return nil
+}
+
+// NewScan classifies a wallet's assets and groups them in display order
+func NewScan(wallet string, assets []das.Asset) *Scan {
+	scan := &Scan{Wallet: wallet, ScannedAt: time.Now().UTC()}
+	byGroup := make(map[Group]*GroupSummary)
+
+	for _, raw := range assets {
+		if raw.Burnt {
+			continue
+		}
+		asset := Classify(raw)
+		scan.Assets = append(scan.Assets, asset)
+		scan.Total++
+
+		summary := byGroup[asset.Group]
+		if summary == nil {
+			summary = &GroupSummary{
+				Group:      asset.Group,
+				Label:      asset.Group.Label(),
+				Backupable: asset.Group.Backupable(),
+			}
+			byGroup[asset.Group] = summary
+		}
+		summary.Count++
+		summary.Mints = append(summary.Mints, asset.Mint)
+	}
+
+	for _, g := range Groups {
+		if summary, ok := byGroup[g]; ok {
+			sort.Strings(summary.Mints)
+			scan.Groups = append(scan.Groups, summary)
+		}
+	}
+	return scan
+}
+
+// BackupMints returns the mints to back up for the chosen groups and how
+// many chosen assets had to be skipped because they are compressed
+func (s *Scan) BackupMints(groups []Group) (mints []string, skipped int) {
+	chosen := make(map[Group]bool)
+	for _, g := range groups {
+		chosen[g] = g.Backupable()
+	}
+
+	for _, asset := range s.Assets {
+		if !chosen[asset.Group] {
+			continue
+		}
+		if asset.Compressed {
+			skipped++
+			continue
+		}
+		mints = append(mints, asset.Mint)
+	}
+	sort.Strings(mints)
+	return mints, skipped
+}
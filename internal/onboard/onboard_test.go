@@ -0,0 +1,168 @@
+package onboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/das"
+)
+
+func testAsset(id, iface, name string, compressed bool) das.Asset {
+	var asset das.Asset
+	asset.ID = id
+	asset.Interface = iface
+	asset.Content.Metadata.Name = name
+	asset.Compression.Compressed = compressed
+	asset.Content.Links.Image = "https://media.example/" + id + ".png"
+	return asset
+}
+
+func TestClassify(t *testing.T) {
+	withDescription := testAsset("desc", "V1_NFT", "Mystery Box", true)
+	withDescription.Content.Metadata.Description = "You are eligible! Visit solrewards.app to claim."
+	innocentLink := testAsset("link", "V1_NFT", "Degen #12", false)
+	innocentLink.Content.Metadata.Description = "Art by studio, see studio.io"
+
+	tests := []struct {
+		name       string
+		asset      das.Asset
+		wantGroup  Group
+		wantReason bool
+	}{
+		{"legacy nft", testAsset("a", "V1_NFT", "Cool Cat #1", false), GroupNFT, false},
+		{"print edition", testAsset("b", "V1_PRINT", "Edition #4", false), GroupNFT, false},
+		{"programmable nft", testAsset("c", "ProgrammableNFT", "Mad Lad #9", false), GroupPNFT, false},
+		{"compressed nft", testAsset("d", "V1_NFT", "Drip #77", true), GroupCompressed, false},
+		{"fungible token", testAsset("e", "FungibleToken", "USDC", false), GroupOther, false},
+		{"domain in name", testAsset("f", "V1_NFT", "🎁 jup-airdrop.com", true), GroupSpam, true},
+		{"lure in description", withDescription, GroupSpam, true},
+		{"link without lure", innocentLink, GroupNFT, false},
+		{"spammy fungible stays other", testAsset("g", "FungibleToken", "www.free.xyz", false), GroupOther, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.asset)
+			if got.Group != tt.wantGroup {
+				t.Errorf("Got group %s, expected %s", got.Group, tt.wantGroup)
+			}
+			if (got.SpamReason != "") != tt.wantReason {
+				t.Errorf("Got spam reason %q, expected reason: %v", got.SpamReason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewScan_GroupsAndBackupMints(t *testing.T) {
+	burnt := testAsset("burnt", "V1_NFT", "Gone", false)
+	burnt.Burnt = true
+	assets := []das.Asset{
+		testAsset("n2", "V1_NFT", "B", false),
+		testAsset("n1", "V1_NFT", "A", false),
+		testAsset("p1", "ProgrammableNFT", "P", false),
+		testAsset("c1", "V1_NFT", "C", true),
+		testAsset("s1", "V1_NFT", "claim.com", true),
+		testAsset("s2", "V1_NFT", "visit.xyz", false),
+		burnt,
+	}
+
+	scan := NewScan("wallet", assets)
+	if scan.Total != 6 {
+		t.Fatalf("Expected 6 assets, got %d", scan.Total)
+	}
+	var order []Group
+	for _, summary := range scan.Groups {
+		order = append(order, summary.Group)
+	}
+	if fmt.Sprint(order) != fmt.Sprint([]Group{GroupNFT, GroupPNFT, GroupCompressed, GroupSpam}) {
+		t.Errorf("Unexpected group order %v", order)
+	}
+	if nfts := scan.Group(GroupNFT); nfts == nil || nfts.Count != 2 || nfts.Mints[0] != "n1" {
+		t.Errorf("Unexpected NFT group %+v", nfts)
+	}
+
+	mints, skipped := scan.BackupMints([]Group{GroupNFT, GroupCompressed, GroupSpam})
+	if fmt.Sprint(mints) != "[n1 n2 s2]" || skipped != 1 {
+		t.Errorf("Got mints %v with %d skipped, expected [n1 n2 s2] with 1 skipped", mints, skipped)
+	}
+}
+
+// fakeSizer reports fixed sizes, failing for unknown URIs
+type fakeSizer map[string]int64
+
+func (f fakeSizer) Size(ctx context.Context, uri string) (int64, error) {
+	if size, ok := f[uri]; ok {
+		return size, nil
+	}
+	return 0, errors.New("unreachable")
+}
+
+func TestEstimate(t *testing.T) {
+	var assets []das.Asset
+	sizes := fakeSizer{}
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("n%d", i)
+		assets = append(assets, testAsset(id, "V1_NFT", id, false))
+		sizes["https://media.example/"+id+".png"] = 1 << 20
+	}
+	assets = append(assets, testAsset("p0", "ProgrammableNFT", "p0", false)) // Unmeasurable
+
+	scan := NewScan("wallet", assets)
+	Estimate(context.Background(), scan, sizes, 4)
+
+	nfts := scan.Group(GroupNFT)
+	if nfts.Sampled != 4 || nfts.EstimatedBytes != 10<<20 {
+		t.Errorf("Got %d sampled and %d bytes, expected 4 and %d", nfts.Sampled, nfts.EstimatedBytes, 10<<20)
+	}
+	if nfts.EstimatedTime != BackupTime(10, 10<<20) {
+		t.Errorf("Got estimated time %s, expected %s", nfts.EstimatedTime, BackupTime(10, 10<<20))
+	}
+
+	pnfts := scan.Group(GroupPNFT)
+	if pnfts.Sampled != 0 || pnfts.EstimatedBytes != FallbackMediaSize {
+		t.Errorf("Expected fallback size for unmeasured group, got %+v", pnfts)
+	}
+}
+
+func TestBackupTime(t *testing.T) {
+	if got := BackupTime(0, 0); got != 0 {
+		t.Errorf("Expected zero time for nothing, got %s", got)
+	}
+	if got := BackupTime(2, DownloadRate*3); got != 2*PerAssetOverhead+3*time.Second {
+		t.Errorf("Got %s, expected %s", got, 2*PerAssetOverhead+3*time.Second)
+	}
+}
+
+func TestHTTPSizer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD request, got %s", r.Method)
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", "12345")
+	}))
+	defer ts.Close()
+
+	sizer := NewHTTPSizer()
+	size, err := sizer.Size(context.Background(), ts.URL+"/image.png")
+	if err != nil {
+		t.Fatalf("Failed to measure media: %v", err)
+	}
+	if size != 12345 {
+		t.Errorf("Got size %d, expected 12345", size)
+	}
+	if _, err := sizer.Size(context.Background(), ts.URL+"/missing"); err == nil {
+		t.Errorf("Expected error for missing media")
+	}
+	if _, err := sizer.Size(context.Background(), "ipfs://bafy/1.png"); err == nil {
+		t.Errorf("Expected error for non-HTTP URI")
+	}
+}
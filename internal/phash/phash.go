@@ -0,0 +1,305 @@
+// Package phash computes perceptual hashes of images: fingerprints that
+// stay the same when an image is re-encoded, resized or recompressed, and
+// differ when what it shows changes. SHA-256 tells whether the bytes
+// changed; these tell whether the picture did. Pixels sits between the two:
+// it changes with any pixel, but not with the container or its metadata.
+package phash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+
+	// Decoders for the formats NFT images come in
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Threshold is the largest Distance at which two images are taken to be
+// the same picture. Re-encoding and resizing move a hash by a few bits, up
+// to a dozen or so for smooth gradients at low JPEG quality; unrelated
+// pictures differ in about half of them.
+const Threshold = 16
+
+// ErrUnsupported means the data is not an image Go can decode, such as SVG
+var ErrUnsupported = errors.New("not a decodable image")
+
+// Bits is a 64-bit hash, written as 16 hex digits in JSON
+type Bits uint64
+
+func (b Bits) String() string {
+	return fmt.Sprintf("%016x", uint64(b))
+}
+
+// MarshalText writes b as hex
+func (b Bits) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText reads hex written by MarshalText
+func (b *Bits) UnmarshalText(text []byte) error {
+	value, err := strconv.ParseUint(string(text), 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid perceptual hash %q: %w", text, err)
+	}
+	*b = Bits(value)
+	return nil
+}
+
+// Hash is an image's perceptual fingerprint
+//
+// Explanation: Two hashes are kept because they fail differently. pHash
+// (low DCT frequencies) ignores noise and compression but can miss local
+// edits in flat artwork; dHash (neighbouring brightness) catches those but
+// is jumpier on noisy photos. Summing both distances keeps either one
+// from calling two different pictures the same on its own.
+type Hash struct {
+	PHash Bits `json:"phash"`
+	DHash Bits `json:"dhash"`
+}
+
+// Distance is how many of the 128 bits differ between h and other: 0 for
+// the same picture, up to Threshold for re-encodings of it
+func (h Hash) Distance(other Hash) int {
+	return bits.OnesCount64(uint64(h.PHash^other.PHash)) + bits.OnesCount64(uint64(h.DHash^other.DHash))
+}
+
+// Similar reports whether h and other are the same picture
+func (h Hash) Similar(other Hash) bool {
+	return h.Distance(other) <= Threshold
+}
+
+// Compute decodes an image and hashes it. Animated GIFs are hashed by
+// their first frame.
+func Compute(data []byte) (*Hash, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return FromImage(img), nil
+}
+
+// Decode decodes an image in any format this package hashes, returning
+// ErrUnsupported for data that is not one
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Pixels returns the SHA-256, in hex, of an image's dimensions and its
+// pixels as 8-bit non-premultiplied RGBA. Files that decode to the same
+// picture hash alike whatever their encoding: stripped EXIF or ICC data, a
+// different PNG compression level or palette, or a lossless conversion.
+// Animated GIFs hash every frame with its placement, delay and disposal,
+// so changing any frame or the timing changes the hash.
+func Pixels(data []byte) (string, error) {
+	if bytes.HasPrefix(data, []byte("GIF8")) {
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode image: %w", err)
+		}
+		if len(animation.Image) > 1 {
+			return animationPixels(animation), nil
+		}
+	}
+	img, err := Decode(data)
+	if err != nil {
+		return "", err
+	}
+	hasher := sha256.New()
+	writePixels(hasher, img)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// animationPixels hashes the screen size and loop count of an animated GIF,
+// then each frame's delay, disposal, position and pixels
+//
+// Explanation: Frames are hashed as stored rather than composited; where a
+// frame sits and how it is disposed decide what is shown, so hashing them
+// alongside the pixels covers the rendered animation.
+func animationPixels(animation *gif.GIF) string {
+	hasher := sha256.New()
+	header := []int32{int32(animation.Config.Width), int32(animation.Config.Height), int32(animation.LoopCount), int32(len(animation.Image))}
+	binary.Write(hasher, binary.BigEndian, header)
+	for i, frame := range animation.Image {
+		var delay, disposal int32
+		if i < len(animation.Delay) {
+			delay = int32(animation.Delay[i])
+		}
+		if i < len(animation.Disposal) {
+			disposal = int32(animation.Disposal[i])
+		}
+		bounds := frame.Bounds()
+		binary.Write(hasher, binary.BigEndian, []int32{delay, disposal, int32(bounds.Min.X), int32(bounds.Min.Y)})
+		writePixels(hasher, frame)
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// writePixels writes img's dimensions and its pixels as NRGBA to w
+//
+// Explanation: Fully transparent pixels are written as transparent black,
+// since encoders are free to change the color they hide.
+func writePixels(w io.Writer, img image.Image) {
+	bounds := img.Bounds()
+	flat := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Src)
+	for i := 0; i < len(flat.Pix); i += 4 {
+		if flat.Pix[i+3] == 0 {
+			flat.Pix[i], flat.Pix[i+1], flat.Pix[i+2] = 0, 0, 0
+		}
+	}
+
+	var size [8]byte
+	binary.BigEndian.PutUint32(size[:4], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(size[4:], uint32(bounds.Dy()))
+	w.Write(size[:])
+	w.Write(flat.Pix)
+}
+
+// FromImage hashes a decoded image
+func FromImage(img image.Image) *Hash {
+	return &Hash{PHash: pHash(img), DHash: dHash(img)}
+}
+
+// gray scales src to width×height in grayscale. Transparent pixels are
+// drawn over white, so a transparent PNG and its flattened JPEG hash alike.
+func gray(src image.Image, width, height int) []float64 {
+	flat := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.BiLinear.Scale(flat, flat.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	pixels := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels[y*width+x] = float64(color.GrayModel.Convert(flat.At(x, y)).(color.Gray).Y)
+		}
+	}
+	return pixels
+}
+
+// dHash sets a bit for each of 8×8 pixels darker than its right neighbour
+func dHash(img image.Image) Bits {
+	const width, height = 9, 8
+	pixels := gray(img, width, height)
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if pixels[y*width+x] < pixels[y*width+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return Bits(hash)
+}
+
+// pHash sets a bit for each of the 8×8 lowest DCT frequencies of a 32×32
+// grayscale copy that is above their median
+func pHash(img image.Image) Bits {
+	const size, low = 32, 8
+	pixels := gray(img, size, size)
+
+	// Separable 2D DCT-II, rows then columns, keeping the low frequencies
+	var cosines [low][size]float64
+	for u := 0; u < low; u++ {
+		for x := 0; x < size; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * size))
+		}
+	}
+	var rows [size][low]float64
+	for y := 0; y < size; y++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for x := 0; x < size; x++ {
+				sum += pixels[y*size+x] * cosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	var coefficients [low * low]float64
+	for v := 0; v < low; v++ {
+		for u := 0; u < low; u++ {
+			var sum float64
+			for y := 0; y < size; y++ {
+				sum += rows[y][u] * cosines[v][y]
+			}
+			coefficients[v*low+u] = sum
+		}
+	}
+
+	// The DC term is the overall brightness and would swamp the median
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for _, c := range coefficients {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+	return Bits(hash)
+}
+
+// Cluster groups hashes that are within threshold of each other, directly
+// or through a chain of similar hashes, and returns the groups of two or
+// more as indexes into hashes
+func Cluster(hashes []Hash, threshold int) [][]int {
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if hashes[i].Distance(hashes[j]) <= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range hashes {
+		root := find(i)
+		if members[root] == nil {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+	var groups [][]int
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,216 @@
+package phash
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// artwork draws a test picture: a gradient with a disc placed at (cx, cy)
+func artwork(size, cx, cy int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{uint8(x * 255 / size), uint8(y * 255 / size), 120, 255}
+			if dx, dy := x-cx*size/100, y-cy*size/100; dx*dx+dy*dy < size*size/16 {
+				c = color.RGBA{250, 240, 20, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 60})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func TestSimilar(t *testing.T) {
+	original := artwork(400, 30, 30)
+	small := image.NewRGBA(image.Rect(0, 0, 150, 150))
+	draw.CatmullRom.Scale(small, small.Bounds(), original, original.Bounds(), draw.Src, nil)
+
+	base, err := Compute(encode(t, original, "png"))
+	if err != nil {
+		t.Fatalf("Failed to hash image: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		similar bool
+	}{
+		{"same bytes", encode(t, original, "png"), true},
+		{"re-encoded as JPEG", encode(t, original, "jpeg"), true},
+		{"downscaled", encode(t, small, "png"), true},
+		{"disc moved", encode(t, artwork(400, 70, 70), "png"), false},
+		{"inverted", encode(t, invert(original), "png"), false},
+	}
+	for _, test := range tests {
+		hash, err := Compute(test.data)
+		if err != nil {
+			t.Fatalf("%s: failed to hash image: %v", test.name, err)
+		}
+		if got := base.Similar(*hash); got != test.similar {
+			t.Errorf("%s: similar = %v at distance %d, want %v", test.name, got, base.Distance(*hash), test.similar)
+		}
+	}
+
+	if _, err := Compute([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>")); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported for SVG, got %v", err)
+	}
+}
+
+func invert(src image.Image) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := src.At(x, y).RGBA()
+			dst.Set(x, y, color.RGBA{255 - uint8(r>>8), 255 - uint8(g>>8), 255 - uint8(b>>8), 255})
+		}
+	}
+	return dst
+}
+
+func TestHashJSON(t *testing.T) {
+	hash := Hash{PHash: 0xd1c3b7a0_0f1e2d3c, DHash: 42}
+	data, err := json.Marshal(hash)
+	if err != nil {
+		t.Fatalf("Failed to marshal hash: %v", err)
+	}
+	if string(data) != `{"phash":"d1c3b7a00f1e2d3c","dhash":"000000000000002a"}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+	var decoded Hash
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != hash {
+		t.Errorf("Round trip gave %+v: %v", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"phash":"xyz"}`), &decoded); err == nil {
+		t.Error("Expected an error for an invalid hash")
+	}
+}
+
+func TestCluster(t *testing.T) {
+	hashes := []Hash{
+		{PHash: 0x0000_0000_0000_0000},
+		{PHash: 0xffff_0000_ffff_0000},
+		{PHash: 0x0000_0000_0000_0007}, // 3 bits from the first
+		{PHash: 0xffff_0000_ffff_0000},
+		{PHash: 0x0000_0000_0000_07f8}, // 8 bits or more from every other
+		{PHash: 0x0000_0000_0000_003f}, // 6 from the first, 3 from the third
+	}
+	groups := Cluster(hashes, 6)
+	want := [][]int{{0, 2, 5}, {1, 3}}
+	if len(groups) != len(want) {
+		t.Fatalf("Got groups %v, want %v", groups, want)
+	}
+	for i := range want {
+		if len(groups[i]) != len(want[i]) {
+			t.Fatalf("Got groups %v, want %v", groups, want)
+		}
+		for j := range want[i] {
+			if groups[i][j] != want[i][j] {
+				t.Errorf("Got groups %v, want %v", groups, want)
+			}
+		}
+	}
+}
+
+func TestPixels(t *testing.T) {
+	original := artwork(64, 30, 30)
+	paletted := image.NewPaletted(original.Bounds(), nil)
+	for _, c := range []color.Color{color.White, color.Black, color.RGBA{250, 240, 20, 255}} {
+		paletted.Palette = append(paletted.Palette, c)
+	}
+	draw.Draw(paletted, paletted.Bounds(), original, image.Point{}, draw.Src)
+
+	// Transparent pixels may hide any color
+	hidden := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	hiddenRed := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(hiddenRed.Pix); i += 4 {
+		hiddenRed.Pix[i] = 255
+	}
+
+	// Animations that share their first frame
+	changed := image.NewPaletted(paletted.Bounds(), paletted.Palette)
+	copy(changed.Pix, paletted.Pix)
+	changed.SetColorIndex(5, 5, (paletted.ColorIndexAt(5, 5)+1)%uint8(len(paletted.Palette)))
+	trueColor := image.NewNRGBA(paletted.Bounds())
+	draw.Draw(trueColor, trueColor.Bounds(), paletted, image.Point{}, draw.Src)
+	animation := encodeGIF(t, []*image.Paletted{paletted, paletted}, []int{10, 10})
+
+	tests := []struct {
+		name string
+		a, b []byte
+		same bool
+	}{
+		{"PNG round trip", encode(t, original, "png"), encode(t, decode(t, encode(t, original, "png")), "png"), true},
+		{"palette and true color", encode(t, paletted, "png"), encode(t, trueColor, "png"), true},
+		{"still GIF and PNG", encodeGIF(t, []*image.Paletted{paletted}, []int{0}), encode(t, paletted, "png"), true},
+		{"hidden color", encode(t, hidden, "png"), encode(t, hiddenRed, "png"), true},
+		{"same animation", animation, encodeGIF(t, []*image.Paletted{paletted, paletted}, []int{10, 10}), true},
+		{"JPEG", encode(t, original, "png"), encode(t, original, "jpeg"), false},
+		{"one pixel", encode(t, original, "png"), encode(t, withPixel(original, 5, 5, color.RGBA{0, 0, 0, 255}), "png"), false},
+		{"size", encode(t, image.NewNRGBA(image.Rect(0, 0, 4, 8)), "png"), encode(t, image.NewNRGBA(image.Rect(0, 0, 8, 4)), "png"), false},
+		{"second frame", animation, encodeGIF(t, []*image.Paletted{paletted, changed}, []int{10, 10}), false},
+		{"frame delay", animation, encodeGIF(t, []*image.Paletted{paletted, paletted}, []int{10, 50}), false},
+		{"animation and first frame", animation, encodeGIF(t, []*image.Paletted{paletted}, []int{0}), false},
+	}
+	for _, test := range tests {
+		a, err := Pixels(test.a)
+		if err != nil {
+			t.Fatalf("%s: Failed to hash pixels: %v", test.name, err)
+		}
+		b, err := Pixels(test.b)
+		if err != nil {
+			t.Fatalf("%s: Failed to hash pixels: %v", test.name, err)
+		}
+		if same := a == b; same != test.same {
+			t.Errorf("%s: same = %v, want %v", test.name, same, test.same)
+		}
+	}
+}
+
+func encodeGIF(t *testing.T, frames []*image.Paletted, delays []int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{Image: frames, Delay: delays}); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Failed to decode image: %v", err)
+	}
+	return img
+}
+
+func withPixel(src image.Image, x, y int, c color.Color) image.Image {
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, image.Point{}, draw.Src)
+	dst.Set(x, y, c)
+	return dst
+}
@@ -0,0 +1,225 @@
+// Package progress draws progress bars for long operations and holds the
+// process-wide quiet setting used by cron and CI runs.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	barWidth    = 30
+	redrawEvery = 100 * time.Millisecond
+)
+
+var (
+	mu     sync.Mutex
+	quiet  bool
+	out    io.Writer = os.Stderr
+	active []*Bar    // Nested bars; only the innermost is drawn
+
+	// drawable reports whether out can show a bar; tests replace it
+	drawable = func() bool { return isTerminal(out) }
+)
+
+// SetQuiet turns every bar into a no-op
+func SetQuiet(q bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	quiet = q
+}
+
+// Quiet reports whether decorative output is suppressed
+func Quiet() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return quiet
+}
+
+// Bar is a single-line progress bar on standard error. Bars are only drawn
+// when standard error is a terminal, so logs and pipes see no escape codes.
+//
+// Explanation: A media download runs inside a sync, so bars nest. Rather
+// than managing several terminal lines, the innermost bar owns the line and
+// the outer bar is redrawn when it finishes.
+type Bar struct {
+	label    string
+	total    int64
+	done     int64
+	bytes    bool // Show done/total as sizes
+	enabled  bool
+	started  time.Time
+	lastDraw time.Time
+}
+
+// New starts a bar counting total items
+func New(label string, total int) *Bar {
+	return start(label, int64(total), false)
+}
+
+// NewBytes starts a bar counting total bytes
+func NewBytes(label string, total int64) *Bar {
+	return start(label, total, true)
+}
+
+func start(label string, total int64, bytes bool) *Bar {
+	mu.Lock()
+	defer mu.Unlock()
+	bar := &Bar{
+		label:   label,
+		total:   total,
+		bytes:   bytes,
+		enabled: !quiet && drawable(),
+		started: time.Now(),
+	}
+	if bar.enabled {
+		active = append(active, bar)
+		bar.draw()
+	}
+	return bar
+}
+
+// Add advances the bar by n
+func (b *Bar) Add(n int64) {
+	if b == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !b.enabled {
+		return
+	}
+	b.done += n
+	if b.isTop() && (time.Since(b.lastDraw) >= redrawEvery || b.done >= b.total) {
+		b.draw()
+	}
+}
+
+// Increment advances the bar by one
+func (b *Bar) Increment() {
+	b.Add(1)
+}
+
+// Set moves the bar to done of total, for callers that learn the total
+// as they go
+func (b *Bar) Set(done, total int) {
+	if b == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !b.enabled {
+		return
+	}
+	b.done, b.total = int64(done), int64(total)
+	if b.isTop() {
+		b.draw()
+	}
+}
+
+// Println prints a message above the bar. Messages are printed even when
+// the bar is not drawn, unless output is quiet.
+func (b *Bar) Println(format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	if quiet {
+		return
+	}
+	drawn := b != nil && b.enabled && b.isTop()
+	if drawn {
+		fmt.Fprint(out, "\r\033[K")
+	}
+	fmt.Printf(format+"\n", args...)
+	if drawn {
+		b.draw()
+	}
+}
+
+// Writer returns a writer that advances the bar by the bytes written, for
+// use with io.MultiWriter
+func (b *Bar) Writer() io.Writer {
+	return barWriter{b}
+}
+
+type barWriter struct{ bar *Bar }
+
+func (w barWriter) Write(p []byte) (int, error) {
+	w.bar.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Finish clears the bar and hands the line back to the enclosing bar
+func (b *Bar) Finish() {
+	if b == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !b.enabled {
+		return
+	}
+	for i, bar := range active {
+		if bar == b {
+			active = append(active[:i], active[i+1:]...)
+			break
+		}
+	}
+	b.enabled = false
+	fmt.Fprint(out, "\r\033[K")
+	if len(active) > 0 {
+		active[len(active)-1].draw()
+	}
+}
+
+func (b *Bar) isTop() bool {
+	return len(active) > 0 && active[len(active)-1] == b
+}
+
+// draw renders the bar; mu must be held
+func (b *Bar) draw() {
+	b.lastDraw = time.Now()
+	fmt.Fprintf(out, "\r\033[K%s", b.render())
+}
+
+func (b *Bar) render() string {
+	elapsed := time.Since(b.started).Round(time.Second)
+	if b.total <= 0 {
+		return fmt.Sprintf("%s %s %s", b.label, b.count(b.done), elapsed)
+	}
+	done := min(b.done, b.total)
+	filled := int(done * barWidth / b.total)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	if filled > 0 && filled < barWidth {
+		bar = bar[:filled-1] + ">" + bar[filled:]
+	}
+	return fmt.Sprintf("%s [%s] %s/%s %3d%% %s", b.label, bar, b.count(done), b.count(b.total), done*100/b.total, elapsed)
+}
+
+func (b *Bar) count(n int64) string {
+	if !b.bytes {
+		return fmt.Sprintf("%d", n)
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,82 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// captureBars draws bars into a buffer as if it were a terminal
+func captureBars(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	savedOut, savedDrawable := out, drawable
+	out, drawable = &buf, func() bool { return true }
+	t.Cleanup(func() {
+		out, drawable = savedOut, savedDrawable
+		active = nil
+		SetQuiet(false)
+	})
+	return &buf
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		bar  *Bar
+		want string
+	}{
+		{"empty", &Bar{label: "sync", total: 4}, "sync [                              ] 0/4   0%"},
+		{"half", &Bar{label: "sync", total: 4, done: 2}, "sync [==============>               ] 2/4  50%"},
+		{"full", &Bar{label: "sync", total: 4, done: 5}, "sync [==============================] 4/4 100%"},
+		{"unknown total", &Bar{label: "scan", done: 7}, "scan 7 "},
+		{"bytes", &Bar{label: "video.mp4", total: 3 << 20, done: 1536 << 10, bytes: true}, "1.5 MB/3.0 MB  50%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bar.render(); !strings.Contains(got, tt.want) {
+				t.Errorf("Got %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNestedBars(t *testing.T) {
+	buf := captureBars(t)
+
+	outer := New("sync", 2)
+	inner := NewBytes("image.png", 10)
+	if outer.isTop() || !inner.isTop() {
+		t.Fatalf("Failed to hand the line to the inner bar")
+	}
+	inner.Writer().Write(make([]byte, 10))
+	inner.Finish()
+	if !outer.isTop() {
+		t.Fatalf("Failed to hand the line back to the outer bar")
+	}
+	buf.Reset()
+	outer.Increment()
+	outer.Increment()
+	outer.Finish()
+
+	if !strings.Contains(buf.String(), "2/2 100%") {
+		t.Errorf("Outer bar never reached 100%%: %q", buf.String())
+	}
+	if len(active) != 0 {
+		t.Errorf("%d bar(s) still active", len(active))
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	buf := captureBars(t)
+	SetQuiet(true)
+
+	bar := New("sync", 3)
+	bar.Increment()
+	bar.Println("never shown")
+	bar.Finish()
+
+	if buf.Len() != 0 {
+		t.Errorf("Quiet bar drew %q", buf.String())
+	}
+}
@@ -0,0 +1,102 @@
+package proof
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// messagePrefix domain-separates attestation signatures from Solana
+// transactions, so a signed attestation can never be replayed as one
+const messagePrefix = "solvault-attestation:v1"
+
+// Attestation is a verifier's signed statement that it checked an NFT's media
+// bytes and produced a proof document with the given hash
+type Attestation struct {
+	Mint      string    `json:"mint"`
+	MediaHash string    `json:"media_hash"` // sha256 of the primary media file
+	ProofHash string    `json:"proof_hash"` // sha256 of the verifier's proof.json
+	Timestamp time.Time `json:"timestamp"`
+	Verifier  string    `json:"verifier"`  // Base58 ed25519 public key (a Solana address)
+	Signature string    `json:"signature"` // Base58 signature over Message()
+}
+
+// Message returns the exact bytes that are signed
+// Explanation: Hashes are normalized and the timestamp is rendered in UTC so
+// two implementations serializing the same attestation sign the same bytes.
+func (a *Attestation) Message() []byte {
+	return []byte(strings.Join([]string{
+		messagePrefix,
+		a.Mint,
+		NormalizeHash(a.MediaHash),
+		NormalizeHash(a.ProofHash),
+		a.Timestamp.UTC().Format(time.RFC3339),
+	}, "\n"))
+}
+
+// Sign fills in Verifier and Signature using the given keypair
+func (a *Attestation) Sign(key solanago.PrivateKey) error {
+	signature, err := key.Sign(a.Message())
+	if err != nil {
+		return fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	a.Verifier = key.PublicKey().String()
+	a.Signature = signature.String()
+	return nil
+}
+
+// Verify checks that all fields are present and the signature is valid for Verifier
+func (a *Attestation) Verify() error {
+	if _, err := solanago.PublicKeyFromBase58(a.Mint); err != nil {
+		return fmt.Errorf("invalid mint address: %w", err)
+	}
+	if NormalizeHash(a.MediaHash) == "" || NormalizeHash(a.ProofHash) == "" {
+		return fmt.Errorf("media_hash and proof_hash are required")
+	}
+	if a.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+
+	verifier, err := solanago.PublicKeyFromBase58(a.Verifier)
+	if err != nil {
+		return fmt.Errorf("invalid verifier public key: %w", err)
+	}
+	signature, err := solanago.SignatureFromBase58(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	if !signature.Verify(verifier, a.Message()) {
+		return fmt.Errorf("signature does not match verifier %s", a.Verifier)
+	}
+	return nil
+}
+
+// NormalizeHash lowercases a hex digest and strips an optional "sha256:" prefix,
+// so hashes from proof.json and from media checksums compare equal
+func NormalizeHash(hash string) string {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	return strings.TrimPrefix(hash, "sha256:")
+}
+
+// HashFile returns the sha256 of a file in the "sha256:<hex>" form used by proof.json
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), nil
+}
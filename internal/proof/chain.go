@@ -0,0 +1,135 @@
+package proof
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ChainFileName is the proof chain kept next to proof.json in an NFT directory
+const ChainFileName = "proof_chain.json"
+
+// ErrDuplicate is returned when an attestation is already in the chain
+var ErrDuplicate = errors.New("attestation already recorded")
+
+// ChainEntry is one attestation as it was accepted into the chain
+type ChainEntry struct {
+	Attestation  Attestation `json:"attestation"`
+	Source       string      `json:"source"` // Where it came from, e.g. the remote address
+	ReceivedAt   time.Time   `json:"received_at"`
+	MatchesLocal bool        `json:"matches_local_media"` // Media hash equals one of our own media files
+	PrevHash     string      `json:"prev_hash"`
+	Hash         string      `json:"hash"`
+}
+
+// Chain is the append-only list of attestations collected for one NFT
+//
+// Explanation: Each entry hashes the previous entry's hash together with its
+// own signed message, so removing or reordering entries is detectable even
+// though the file itself is plain JSON.
+type Chain struct {
+	path    string
+	Mint    string        `json:"mint"`
+	Entries []*ChainEntry `json:"entries"`
+}
+
+// LoadChain reads the proof chain from an NFT directory, returning an empty
+// chain for the mint if none exists yet
+func LoadChain(nftDir, mint string) (*Chain, error) {
+	chain := &Chain{
+		path: filepath.Join(nftDir, ChainFileName),
+		Mint: mint,
+	}
+
+	data, err := os.ReadFile(chain.path)
+	if os.IsNotExist(err) {
+		return chain, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof chain: %w", err)
+	}
+
+	if err := json.Unmarshal(data, chain); err != nil {
+		return nil, fmt.Errorf("failed to parse proof chain %s: %w", chain.path, err)
+	}
+	return chain, nil
+}
+
+// Append verifies an attestation and links it onto the end of the chain
+func (c *Chain) Append(att Attestation, source string, matchesLocal bool) (*ChainEntry, error) {
+	if att.Mint != c.Mint {
+		return nil, fmt.Errorf("attestation is for mint %s, chain is for %s", att.Mint, c.Mint)
+	}
+	if err := att.Verify(); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range c.Entries {
+		if entry.Attestation.Signature == att.Signature {
+			return nil, ErrDuplicate
+		}
+	}
+
+	entry := &ChainEntry{
+		Attestation:  att,
+		Source:       source,
+		ReceivedAt:   time.Now().UTC(),
+		MatchesLocal: matchesLocal,
+	}
+	if len(c.Entries) > 0 {
+		entry.PrevHash = c.Entries[len(c.Entries)-1].Hash
+	}
+	entry.Hash = entryHash(entry)
+
+	c.Entries = append(c.Entries, entry)
+	return entry, nil
+}
+
+// Verify re-checks every signature and link in the chain
+func (c *Chain) Verify() error {
+	prevHash := ""
+	for i, entry := range c.Entries {
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("entry %d: broken link to previous entry", i)
+		}
+		if entry.Hash != entryHash(entry) {
+			return fmt.Errorf("entry %d: hash mismatch", i)
+		}
+		if err := entry.Attestation.Verify(); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		prevHash = entry.Hash
+	}
+	return nil
+}
+
+// Save writes the chain back to its NFT directory
+func (c *Chain) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal proof chain: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write proof chain: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("failed to replace proof chain: %w", err)
+	}
+	return nil
+}
+
+func entryHash(entry *ChainEntry) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(entry.PrevHash))
+	hasher.Write([]byte{'\n'})
+	hasher.Write(entry.Attestation.Message())
+	hasher.Write([]byte{'\n'})
+	hasher.Write([]byte(entry.Attestation.Signature))
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil))
+}
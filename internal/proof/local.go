@@ -0,0 +1,68 @@
+package proof
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// FromVault builds an unsigned attestation for a mint held in vault by
+// hashing the primary media file and the NFT's proof document
+// Explanation: proof.json is written by 'solvault verify'; when an NFT has not
+// been verified yet, nft_data.json stands in as the proof document.
+func FromVault(ctx context.Context, vault *storage.FileStorage, mint solanago.PublicKey) (*Attestation, error) {
+	entries := vault.Index().ByMint(mint.String())
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("mint %s is not in the vault", mint.String())
+	}
+
+	wallet, err := solanago.PublicKeyFromBase58(entries[0].Wallet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet in index: %w", err)
+	}
+
+	stored, err := vault.GetNFT(ctx, wallet, mint)
+	if err != nil {
+		return nil, err
+	}
+	if stored.NFTInfo == nil || len(stored.NFTInfo.MediaFiles) == 0 {
+		return nil, fmt.Errorf("no media backed up for %s; nothing to attest", mint.String())
+	}
+
+	nftDir := vault.NFTDir(wallet, mint)
+	mediaHash, err := hashVaultFile(vault, filepath.Join(nftDir, "media", stored.NFTInfo.MediaFiles[0].Filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash media: %w", err)
+	}
+
+	proofPath := filepath.Join(nftDir, "proof.json")
+	if _, err := os.Stat(proofPath); err != nil {
+		proofPath = filepath.Join(nftDir, "nft_data.json")
+	}
+	proofHash, err := hashVaultFile(vault, proofPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash proof: %w", err)
+	}
+
+	return &Attestation{
+		Mint:      mint.String(),
+		MediaHash: mediaHash,
+		ProofHash: proofHash,
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
+
+// hashVaultFile hashes a vault file's original contents, so an encrypted or
+// compressed vault attests to the same media hash as a plain one
+func hashVaultFile(vault *storage.FileStorage, path string) (string, error) {
+	data, err := vault.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
@@ -0,0 +1,146 @@
+package proof
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func newSignedAttestation(t *testing.T, key solanago.PrivateKey, mint string) Attestation {
+	t.Helper()
+
+	att := Attestation{
+		Mint:      mint,
+		MediaHash: "sha256:ABCDEF",
+		ProofHash: "sha256:123456",
+		Timestamp: time.Now().Truncate(time.Second),
+	}
+	if err := att.Sign(key); err != nil {
+		t.Fatalf("Failed to sign attestation: %v", err)
+	}
+	return att
+}
+
+func TestAttestation_SignVerify(t *testing.T) {
+	key := solanago.NewWallet().PrivateKey
+	mint := solanago.NewWallet().PublicKey().String()
+
+	att := newSignedAttestation(t, key, mint)
+	if err := att.Verify(); err != nil {
+		t.Fatalf("Expected valid signature, got %v", err)
+	}
+
+	// Hash formatting differences must not break the signature
+	att.MediaHash = "abcdef"
+	if err := att.Verify(); err != nil {
+		t.Errorf("Expected normalized hash to verify, got %v", err)
+	}
+
+	tampered := att
+	tampered.ProofHash = "sha256:654321"
+	if err := tampered.Verify(); err == nil {
+		t.Errorf("Expected tampered attestation to fail verification")
+	}
+
+	impostor := att
+	impostor.Verifier = solanago.NewWallet().PublicKey().String()
+	if err := impostor.Verify(); err == nil {
+		t.Errorf("Expected signature from a different key to fail verification")
+	}
+}
+
+func TestChain_AppendVerify(t *testing.T) {
+	dir := t.TempDir()
+	mint := solanago.NewWallet().PublicKey().String()
+
+	chain, err := LoadChain(dir, mint)
+	if err != nil {
+		t.Fatalf("Failed to load empty chain: %v", err)
+	}
+
+	first := newSignedAttestation(t, solanago.NewWallet().PrivateKey, mint)
+	second := newSignedAttestation(t, solanago.NewWallet().PrivateKey, mint)
+	for _, att := range []Attestation{first, second} {
+		if _, err := chain.Append(att, "test", false); err != nil {
+			t.Fatalf("Failed to append attestation: %v", err)
+		}
+	}
+
+	if _, err := chain.Append(first, "test", false); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+	if err := chain.Save(); err != nil {
+		t.Fatalf("Failed to save chain: %v", err)
+	}
+
+	reloaded, err := LoadChain(dir, mint)
+	if err != nil {
+		t.Fatalf("Failed to reload chain: %v", err)
+	}
+	if len(reloaded.Entries) != 2 || reloaded.Entries[1].PrevHash != reloaded.Entries[0].Hash {
+		t.Fatalf("Expected two linked entries, got %+v", reloaded.Entries)
+	}
+	if err := reloaded.Verify(); err != nil {
+		t.Fatalf("Expected chain to verify, got %v", err)
+	}
+
+	// Dropping the first entry breaks the link
+	reloaded.Entries = reloaded.Entries[1:]
+	if err := reloaded.Verify(); err == nil {
+		t.Errorf("Expected broken chain to fail verification")
+	}
+}
+
+func TestFromVault_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	image := []byte("\x89PNG pixels")
+	nft := &fetcher.NFTInfo{MintAddress: mint, Owner: wallet, FetchedAt: time.Now(),
+		MediaFiles: []*fetcher.MediaFile{{Filename: "image.png", ContentType: "image/png"}}}
+	if err := vault.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	mediaDir := filepath.Join(vault.NFTDir(wallet, mint), "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatalf("Failed to create media dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "image.png"), image, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	plain, err := FromVault(ctx, vault, mint)
+	if err != nil {
+		t.Fatalf("Failed to attest: %v", err)
+	}
+	params, c, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	if _, err := vault.EnableEncryption(params, c); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+	encrypted, err := FromVault(ctx, vault, mint)
+	if err != nil {
+		t.Fatalf("Failed to attest encrypted vault: %v", err)
+	}
+
+	// Attestations vouch for the media, not how the vault stores it
+	if want := hashBytes(image); plain.MediaHash != want || encrypted.MediaHash != want {
+		t.Errorf("Media hashes %s and %s, want %s", plain.MediaHash, encrypted.MediaHash, want)
+	}
+	if plain.ProofHash != encrypted.ProofHash {
+		t.Errorf("Proof hash changed with encryption: %s, then %s", plain.ProofHash, encrypted.ProofHash)
+	}
+}
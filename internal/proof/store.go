@@ -0,0 +1,226 @@
+package proof
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FileName is an NFT's current proof document, written by 'solvault proof generate'
+	FileName = "proof.json"
+	// TimestampFileName holds the RFC 3161 timestamp response for proof.json
+	TimestampFileName = "proof.json.tsr"
+	// HistoryDir keeps earlier proofs that were anchored or published
+	HistoryDir = "proofs"
+	// PublishedFileName records where an NFT's proofs were published, in HistoryDir
+	PublishedFileName = "published.json"
+)
+
+// Publication is one proof document published to a proof page endpoint
+type Publication struct {
+	URL         string    `json:"url"`
+	Endpoint    string    `json:"endpoint"`
+	ProofHash   string    `json:"proof_hash"` // HashFile of proof.json as published
+	Anchored    bool      `json:"anchored"`   // Its timestamp token was published with it
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// LoadPublications lists every publication recorded for an NFT, oldest first
+func LoadPublications(nftDir string) ([]Publication, error) {
+	data, err := os.ReadFile(filepath.Join(nftDir, HistoryDir, PublishedFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read publications: %w", err)
+	}
+	var publications []Publication
+	if err := json.Unmarshal(data, &publications); err != nil {
+		return nil, fmt.Errorf("failed to parse publications: %w", err)
+	}
+	return publications, nil
+}
+
+// RecordPublication appends a publication to the NFT's history
+func RecordPublication(nftDir string, publication Publication) error {
+	publications, err := LoadPublications(nftDir)
+	if err != nil {
+		return err
+	}
+	publications = append(publications, publication)
+
+	data, err := json.MarshalIndent(publications, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal publications: %w", err)
+	}
+	dir := filepath.Join(nftDir, HistoryDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create proof history: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PublishedFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write publications: %w", err)
+	}
+	return nil
+}
+
+// Kept reports whether the current proof.json must be archived rather than
+// overwritten: it has a timestamp token, or it was published as it is.
+//
+// Explanation: A timestamp or a published page vouches for the exact bytes
+// of proof.json, so rewriting it would leave that evidence pointing at a
+// document nobody has any more. Proofs nobody saw outside the vault are
+// simply replaced, so verifying the vault every night does not pile up
+// copies.
+func Kept(nftDir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(nftDir, TimestampFileName)); err == nil {
+		return true, nil
+	}
+	current, err := HashFile(filepath.Join(nftDir, FileName))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to hash proof: %w", err)
+	}
+	publications, err := LoadPublications(nftDir)
+	if err != nil {
+		return false, err
+	}
+	for _, publication := range publications {
+		if NormalizeHash(publication.ProofHash) == NormalizeHash(current) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Archive moves proof.json, and its timestamp token if any, into the NFT's
+// proof history as <HistoryDir>/<at>.json, returning that path relative to
+// the NFT directory
+func Archive(nftDir string, at time.Time) (string, error) {
+	dir := filepath.Join(nftDir, HistoryDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create proof history: %w", err)
+	}
+
+	stem := at.UTC().Format("20060102T150405Z")
+	name := stem + ".json"
+	for n := 2; exists(filepath.Join(dir, name)) || exists(filepath.Join(dir, name+".tsr")); n++ {
+		name = fmt.Sprintf("%s-%d.json", stem, n)
+	}
+
+	archived := filepath.Join(dir, name)
+	if err := os.Rename(filepath.Join(nftDir, FileName), archived); err != nil {
+		return "", fmt.Errorf("failed to archive proof: %w", err)
+	}
+	if err := os.Rename(filepath.Join(nftDir, TimestampFileName), archived+".tsr"); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to archive proof timestamp: %w", err)
+	}
+	return filepath.Join(HistoryDir, name), nil
+}
+
+// History lists the archived proofs of an NFT, oldest first, as paths
+// relative to the NFT directory
+func History(nftDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(nftDir, HistoryDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proof history: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasSuffix(name, ".json") && name != PublishedFileName {
+			names = append(names, filepath.Join(HistoryDir, name))
+		}
+	}
+	// Compare without the extension so 20260501T120000Z.json sorts before
+	// 20260501T120000Z-2.json
+	sort.Slice(names, func(i, j int) bool {
+		return strings.TrimSuffix(names[i], ".json") < strings.TrimSuffix(names[j], ".json")
+	})
+	return names, nil
+}
+
+// hashBytes returns the sha256 of data in the same form as HashFile
+func hashBytes(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// publishRequest is the body posted to a proof page endpoint
+type publishRequest struct {
+	Mint           string          `json:"mint"`
+	Proof          json.RawMessage `json:"proof"`
+	TimestampToken []byte          `json:"timestamp_token,omitempty"` // DER, base64 in JSON
+}
+
+// Publish posts a proof document, with its timestamp token when it has one,
+// to a proof page endpoint and returns where the page was published
+func Publish(ctx context.Context, endpoint, apiKey, mint string, document, token []byte) (*Publication, error) {
+	if !json.Valid(document) {
+		return nil, errors.New("proof document is not valid JSON")
+	}
+	body, err := json.Marshal(publishRequest{Mint: mint, Proof: document, TimestampToken: token})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proof: %w", err)
+	}
+
+	url := strings.TrimRight(endpoint, "/") + "/api/v1/proofs"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish proof: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("endpoint rejected proof (%s): %s", resp.Status, apiErr.Error)
+		}
+		return nil, fmt.Errorf("endpoint rejected proof: %s", resp.Status)
+	}
+
+	var page struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(respBody, &page); err != nil || page.URL == "" {
+		return nil, errors.New("endpoint did not return the proof page URL")
+	}
+	return &Publication{
+		URL:         page.URL,
+		Endpoint:    endpoint,
+		ProofHash:   hashBytes(document),
+		Anchored:    len(token) > 0,
+		PublishedAt: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
@@ -0,0 +1,121 @@
+package proof
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeProofFiles(t *testing.T, dir, document string, token bool) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(document), 0644); err != nil {
+		t.Fatalf("Failed to write proof: %v", err)
+	}
+	if token {
+		if err := os.WriteFile(filepath.Join(dir, TimestampFileName), []byte("token"), 0644); err != nil {
+			t.Fatalf("Failed to write token: %v", err)
+		}
+	}
+}
+
+func TestKeptAndArchive(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// A proof nobody saw outside the vault is simply replaced
+	writeProofFiles(t, dir, `{"status":"authentic"}`, false)
+	if kept, err := Kept(dir); err != nil || kept {
+		t.Fatalf("Got kept %v (%v) for a plain proof, want false", kept, err)
+	}
+
+	// Publishing it as it is means it must be kept
+	if err := RecordPublication(dir, Publication{URL: "https://proofs.example/1", ProofHash: hashBytes([]byte(`{"status":"authentic"}`))}); err != nil {
+		t.Fatalf("Failed to record publication: %v", err)
+	}
+	if kept, err := Kept(dir); err != nil || !kept {
+		t.Fatalf("Got kept %v (%v) for a published proof, want true", kept, err)
+	}
+	first, err := Archive(dir, at)
+	if err != nil {
+		t.Fatalf("Failed to archive proof: %v", err)
+	}
+	if first != filepath.Join(HistoryDir, "20260501T120000Z.json") {
+		t.Errorf("Got archive %s", first)
+	}
+
+	// So must a timestamped one, with its token, without replacing the first
+	writeProofFiles(t, dir, `{"status":"authentic","n":2}`, true)
+	if kept, err := Kept(dir); err != nil || !kept {
+		t.Fatalf("Got kept %v (%v) for a timestamped proof, want true", kept, err)
+	}
+	second, err := Archive(dir, at)
+	if err != nil {
+		t.Fatalf("Failed to archive proof: %v", err)
+	}
+	if second == first || !exists(filepath.Join(dir, second+".tsr")) {
+		t.Errorf("Got archive %s, want a new name with its token", second)
+	}
+	if exists(filepath.Join(dir, FileName)) || exists(filepath.Join(dir, TimestampFileName)) {
+		t.Errorf("Archived files are still in place")
+	}
+
+	history, err := History(dir)
+	if err != nil {
+		t.Fatalf("Failed to list history: %v", err)
+	}
+	if len(history) != 2 || history[0] != first || history[1] != second {
+		t.Errorf("Got history %v, want %s and %s", history, first, second)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	document := []byte(`{"status":"authentic"}`)
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{name: "published", status: http.StatusCreated, body: `{"url":"https://proofs.example/abc"}`},
+		{name: "rejected", status: http.StatusUnauthorized, body: `{"error":"bad key"}`, wantErr: true},
+		{name: "no url", status: http.StatusOK, body: `{}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got publishRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/proofs" || r.Header.Get("Authorization") != "Bearer key" {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			publication, err := Publish(context.Background(), server.URL+"/", "key", "Mint111", document, []byte("token"))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to publish: %v", err)
+			}
+			if publication.URL != "https://proofs.example/abc" || !publication.Anchored || publication.ProofHash != hashBytes(document) {
+				t.Errorf("Got publication %+v", publication)
+			}
+			if got.Mint != "Mint111" || string(got.Proof) != string(document) || string(got.TimestampToken) != "token" {
+				t.Errorf("Endpoint received %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package proof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Submit posts a signed attestation to another vault's serve endpoint and
+// returns the chain entry it recorded
+func Submit(ctx context.Context, endpoint string, att *Attestation) (*ChainEntry, error) {
+	body, err := json.Marshal(att)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	url := strings.TrimRight(endpoint, "/") + "/api/v1/attestations"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit attestation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusCreated {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("endpoint rejected attestation (%s): %s", resp.Status, apiErr.Error)
+		}
+		return nil, fmt.Errorf("endpoint rejected attestation: %s", resp.Status)
+	}
+
+	var entry ChainEntry
+	if err := json.Unmarshal(respBody, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &entry, nil
+}
@@ -0,0 +1,129 @@
+package provenance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ChainSource reads provenance from a Solana RPC endpoint
+type ChainSource struct {
+	client *solana.Client
+}
+
+// NewChainSource creates a Source backed by the given RPC client
+func NewChainSource(client *solana.Client) *ChainSource {
+	return &ChainSource{client: client}
+}
+
+// Signatures returns the mint's signatures newer than until, newest first
+func (s *ChainSource) Signatures(ctx context.Context, mint, until string) ([]string, error) {
+	mintKey, err := solanago.PublicKeyFromBase58(mint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint address %s: %w", mint, err)
+	}
+	var untilSig solanago.Signature
+	if until != "" {
+		if untilSig, err = solanago.SignatureFromBase58(until); err != nil {
+			return nil, fmt.Errorf("invalid signature %s: %w", until, err)
+		}
+	}
+
+	history, err := s.client.GetSignatureHistory(ctx, mintKey, untilSig)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]string, len(history))
+	for i, sig := range history {
+		signatures[i] = sig.Signature.String()
+	}
+	return signatures, nil
+}
+
+// Recent returns up to limit of the mint's newest signatures, newest first
+func (s *ChainSource) Recent(ctx context.Context, mint string, limit int) ([]string, error) {
+	mintKey, err := solanago.PublicKeyFromBase58(mint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint address %s: %w", mint, err)
+	}
+	recent, err := s.client.GetSignaturesForAddress(ctx, mintKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]string, len(recent))
+	for i, sig := range recent {
+		signatures[i] = sig.Signature.String()
+	}
+	return signatures, nil
+}
+
+// Transaction loads one transaction and flattens it into a Tx
+func (s *ChainSource) Transaction(ctx context.Context, signature string) (*Tx, error) {
+	sig, err := solanago.SignatureFromBase58(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %s: %w", signature, err)
+	}
+	result, err := s.client.GetTransaction(ctx, sig)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.Meta == nil || result.Transaction == nil {
+		return nil, fmt.Errorf("transaction %s has no metadata", signature)
+	}
+
+	txn, err := result.Transaction.GetTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction %s: %w", signature, err)
+	}
+
+	meta := result.Meta
+	tx := &Tx{
+		Signature:    signature,
+		Slot:         result.Slot,
+		Failed:       meta.Err != nil,
+		Fee:          meta.Fee,
+		PreBalances:  meta.PreBalances,
+		PostBalances: meta.PostBalances,
+		PreTokens:    tokenBalances(meta.PreTokenBalances),
+		PostTokens:   tokenBalances(meta.PostTokenBalances),
+	}
+	if result.BlockTime != nil {
+		t := result.BlockTime.Time().UTC()
+		tx.BlockTime = &t
+	}
+
+	// Balances are indexed over static keys, then loaded writable, then
+	// loaded read-only addresses
+	keys := append(solanago.PublicKeySlice{}, txn.Message.AccountKeys...)
+	keys = append(keys, meta.LoadedAddresses.Writable...)
+	keys = append(keys, meta.LoadedAddresses.ReadOnly...)
+	for _, key := range keys {
+		tx.AccountKeys = append(tx.AccountKeys, key.String())
+	}
+
+	return tx, nil
+}
+
+// tokenBalances converts RPC token balances, skipping ones without an owner
+func tokenBalances(balances []rpc.TokenBalance) []TokenBalance {
+	var result []TokenBalance
+	for _, balance := range balances {
+		if balance.Owner == nil || balance.UiTokenAmount == nil {
+			continue
+		}
+		amount, err := strconv.ParseUint(balance.UiTokenAmount.Amount, 10, 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, TokenBalance{
+			Owner:  balance.Owner.String(),
+			Mint:   balance.Mint.String(),
+			Amount: amount,
+		})
+	}
+	return result
+}
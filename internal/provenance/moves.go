@@ -0,0 +1,93 @@
+package provenance
+
+import (
+	"context"
+	"fmt"
+)
+
+// ArrivalDepth is how many of a mint's newest transactions Arrival and
+// Departure examine
+const ArrivalDepth = 10
+
+// RecentSource loads a mint's newest transactions
+type RecentSource interface {
+	// Recent returns up to limit of the mint's newest signatures, newest first
+	Recent(ctx context.Context, mint string, limit int) ([]string, error)
+	Transaction(ctx context.Context, signature string) (*Tx, error)
+}
+
+// Arrival finds how wallet came to hold mint: the newest of the mint's
+// recent transactions that moved it to wallet, as a mint, transfer or sale
+// event. It returns nil when none of the last ArrivalDepth transactions
+// did, e.g. when the arrival is not finalized yet.
+//
+// Explanation: Only the newest transactions are read, unlike Update, since
+// the arrival of an NFT that was just detected is almost always among them
+// and a busy mint's whole history can run to thousands of transactions.
+func Arrival(ctx context.Context, source RecentSource, mint, wallet string) (*Event, error) {
+	return lastMove(ctx, source, mint, func(event *Event) bool { return event.To == wallet })
+}
+
+// Departure finds how mint left wallet: the newest of the mint's recent
+// transactions that moved it away, as a transfer, sale or burn event, or
+// nil when none of the last ArrivalDepth transactions did
+func Departure(ctx context.Context, source RecentSource, mint, wallet string) (*Event, error) {
+	return lastMove(ctx, source, mint, func(event *Event) bool { return event.From == wallet })
+}
+
+// lastMove returns the newest event among mint's recent transactions that
+// match accepts
+func lastMove(ctx context.Context, source RecentSource, mint string, match func(event *Event) bool) (*Event, error) {
+	signatures, err := source.Recent(ctx, mint, ArrivalDepth)
+	if err != nil {
+		return nil, err
+	}
+	for _, signature := range signatures {
+		tx, err := source.Transaction(ctx, signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transaction %s: %w", signature, err)
+		}
+		if event := ParseTx(mint, tx); event != nil && match(event) {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+// DescribeArrival tells how the event brought the NFT to its new owner,
+// e.g. "bought from <seller> for 1.5000 SOL on Magic Eden"
+func (e Event) DescribeArrival() string {
+	switch e.Kind {
+	case KindMint:
+		return "minted"
+	case KindSale:
+		description := fmt.Sprintf("bought from %s for %.4f SOL", e.From, e.PriceSOL())
+		if e.Marketplace != "" {
+			description += " on " + e.Marketplace
+		}
+		return description
+	case KindTransfer:
+		return "received from " + e.From
+	default:
+		return string(e.Kind)
+	}
+}
+
+// DescribeDeparture tells how the event took the NFT from its previous
+// owner, e.g. "sold to <buyer> for 1.5000 SOL on Magic Eden"
+func (e Event) DescribeDeparture() string {
+	switch e.Kind {
+	case KindBurn:
+		return "burned"
+	case KindSale:
+		description := fmt.Sprintf("sold to %s for %.4f SOL", e.To, e.PriceSOL())
+		if e.Marketplace != "" {
+			description += " on " + e.Marketplace
+		}
+		return description
+	case KindTransfer:
+		return "transferred to " + e.To
+	default:
+		return string(e.Kind)
+	}
+}
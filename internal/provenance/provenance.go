@@ -0,0 +1,243 @@
+// Package provenance reconstructs the ownership and sale history of a mint
+// from its on-chain transactions and keeps it as provenance.json next to the
+// NFT's backup, so the record survives even if indexers forget it.
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the per-NFT provenance file
+const FileName = "provenance.json"
+
+// lamportsPerSOL converts sale prices for display
+const lamportsPerSOL = 1_000_000_000
+
+// EventKind classifies a provenance event
+type EventKind string
+
+const (
+	KindMint     EventKind = "mint"
+	KindTransfer EventKind = "transfer"
+	KindSale     EventKind = "sale"
+	KindBurn     EventKind = "burn"
+)
+
+// Event is one change of ownership
+type Event struct {
+	Signature     string     `json:"signature"`
+	Slot          uint64     `json:"slot"`
+	Time          *time.Time `json:"time,omitempty"`
+	Kind          EventKind  `json:"kind"`
+	From          string     `json:"from,omitempty"`
+	To            string     `json:"to,omitempty"`
+	PriceLamports uint64     `json:"price_lamports,omitempty"` // What the buyer paid, sales only
+	Marketplace   string     `json:"marketplace,omitempty"`
+}
+
+// PriceSOL returns the sale price in SOL
+func (e Event) PriceSOL() float64 {
+	return float64(e.PriceLamports) / lamportsPerSOL
+}
+
+// History is the provenance of one mint, oldest event first
+type History struct {
+	Mint      string    `json:"mint"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// LastSignature is the newest transaction examined; updates resume
+	// after it instead of re-reading the whole history
+	LastSignature string  `json:"last_signature,omitempty"`
+	Events        []Event `json:"events"`
+}
+
+// TokenBalance is a holder's balance of one mint before or after a transaction
+type TokenBalance struct {
+	Owner  string
+	Mint   string
+	Amount uint64
+}
+
+// Tx is the part of a confirmed transaction provenance needs
+type Tx struct {
+	Signature    string
+	Slot         uint64
+	BlockTime    *time.Time
+	Failed       bool
+	Fee          uint64
+	AccountKeys  []string // Static keys followed by lookup-table keys; [0] pays the fee
+	PreBalances  []uint64 // Lamports, aligned with AccountKeys
+	PostBalances []uint64
+	PreTokens    []TokenBalance
+	PostTokens   []TokenBalance
+}
+
+// Source lists and loads the transactions touching a mint
+type Source interface {
+	// Signatures returns signatures newer than until (all when empty), newest first
+	Signatures(ctx context.Context, mint, until string) ([]string, error)
+	Transaction(ctx context.Context, signature string) (*Tx, error)
+}
+
+// Update extends history with transactions since its last update and
+// returns how many events were added. On error, history keeps the events
+// found so far and resumes from there next time.
+func Update(ctx context.Context, source Source, history *History) (int, error) {
+	signatures, err := source.Signatures(ctx, history.Mint, history.LastSignature)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for i := len(signatures) - 1; i >= 0; i-- {
+		tx, err := source.Transaction(ctx, signatures[i])
+		if err != nil {
+			return added, fmt.Errorf("failed to load transaction %s: %w", signatures[i], err)
+		}
+		if event := ParseTx(history.Mint, tx); event != nil {
+			history.Events = append(history.Events, *event)
+			added++
+		}
+		history.LastSignature = signatures[i]
+	}
+
+	history.UpdatedAt = time.Now().UTC()
+	return added, nil
+}
+
+// ParseTx turns a transaction into a provenance event, or nil when it did
+// not move the mint (failed transactions, metadata updates, listings that
+// only delegate the token)
+func ParseTx(mint string, tx *Tx) *Event {
+	if tx == nil || tx.Failed {
+		return nil
+	}
+
+	// Net token movement per owner
+	deltas := make(map[string]int64)
+	for _, balance := range tx.PreTokens {
+		if balance.Mint == mint {
+			deltas[balance.Owner] -= int64(balance.Amount)
+		}
+	}
+	for _, balance := range tx.PostTokens {
+		if balance.Mint == mint {
+			deltas[balance.Owner] += int64(balance.Amount)
+		}
+	}
+	var from, to string
+	for owner, delta := range deltas {
+		switch {
+		case delta < 0:
+			from = owner
+		case delta > 0:
+			to = owner
+		}
+	}
+
+	event := &Event{
+		Signature: tx.Signature,
+		Slot:      tx.Slot,
+		Time:      tx.BlockTime,
+		From:      from,
+		To:        to,
+	}
+	switch {
+	case from == "" && to == "":
+		return nil
+	case from == "":
+		event.Kind = KindMint
+	case to == "":
+		event.Kind = KindBurn
+	default:
+		event.Kind = KindTransfer
+		event.Marketplace = marketplaceFor(tx.AccountKeys)
+		if price := buyerPaid(tx, to); price > 0 && (event.Marketplace != "" || lamportDelta(tx, from) > 0) {
+			event.Kind = KindSale
+			event.PriceLamports = price
+		}
+	}
+	return event
+}
+
+// buyerPaid is how many lamports the new owner spent, excluding the network
+// fee when they paid it
+func buyerPaid(tx *Tx, buyer string) uint64 {
+	delta := lamportDelta(tx, buyer)
+	if len(tx.AccountKeys) > 0 && tx.AccountKeys[0] == buyer {
+		delta += int64(tx.Fee)
+	}
+	if delta >= 0 {
+		return 0
+	}
+	return uint64(-delta)
+}
+
+// lamportDelta is the change in an account's SOL balance
+func lamportDelta(tx *Tx, account string) int64 {
+	for i, key := range tx.AccountKeys {
+		if key == account && i < len(tx.PreBalances) && i < len(tx.PostBalances) {
+			return int64(tx.PostBalances[i]) - int64(tx.PreBalances[i])
+		}
+	}
+	return 0
+}
+
+// marketplaces maps marketplace program IDs to display names
+var marketplaces = map[string]string{
+	"M2mx93ekt1fmXSVkTrUL9xVFHkmME8HTUi5Cyc5aF7K": "Magic Eden",
+	"mmm3XBJg5gk8XJxEKBvdgptZz6SgK4tXvn36sodowMc": "Magic Eden",
+	"TSWAPaqyCSx2KABk68Shruf4rp7CxcNi8hAsbdwmHbN": "Tensor",
+	"TCMPhJdwDryooaGtiocG1u3xcYbRpiJzb283XfCZsDp": "Tensor",
+}
+
+// marketplaceFor names the marketplace program a transaction invoked
+func marketplaceFor(accountKeys []string) string {
+	for _, key := range accountKeys {
+		if name, ok := marketplaces[key]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// Load reads the provenance stored in an NFT directory, or returns an
+// empty history for mint if there is none yet
+func Load(nftDir, mint string) (*History, error) {
+	data, err := os.ReadFile(filepath.Join(nftDir, FileName))
+	if os.IsNotExist(err) {
+		return &History{Mint: mint, Events: []Event{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provenance: %w", err)
+	}
+
+	var history History
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse provenance: %w", err)
+	}
+	return &history, nil
+}
+
+// Save writes the history into an NFT directory
+func Save(nftDir string, history *History) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal provenance: %w", err)
+	}
+
+	path := filepath.Join(nftDir, FileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package provenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const (
+	testMint   = "MintAddress111111111111111111111111111111111"
+	testSeller = "Seller11111111111111111111111111111111111111"
+	testBuyer  = "Buyer111111111111111111111111111111111111111"
+	testEscrow = "Escrow11111111111111111111111111111111111111"
+)
+
+func holding(owner string, amount uint64) TokenBalance {
+	return TokenBalance{Owner: owner, Mint: testMint, Amount: amount}
+}
+
+func TestParseTx(t *testing.T) {
+	tests := []struct {
+		name       string
+		tx         *Tx
+		wantNil    bool
+		wantKind   EventKind
+		wantFrom   string
+		wantTo     string
+		wantPrice  uint64
+		wantMarket string
+	}{
+		{
+			name: "mint",
+			tx: &Tx{
+				AccountKeys:  []string{testSeller},
+				PreBalances:  []uint64{100},
+				PostBalances: []uint64{90},
+				PostTokens:   []TokenBalance{holding(testSeller, 1)},
+			},
+			wantKind: KindMint,
+			wantTo:   testSeller,
+		},
+		{
+			name: "plain transfer",
+			tx: &Tx{
+				Fee:          5000,
+				AccountKeys:  []string{testSeller, testBuyer},
+				PreBalances:  []uint64{1_000_000, 0},
+				PostBalances: []uint64{995_000, 0},
+				PreTokens:    []TokenBalance{holding(testSeller, 1)},
+				PostTokens:   []TokenBalance{holding(testSeller, 0), holding(testBuyer, 1)},
+			},
+			wantKind: KindTransfer,
+			wantFrom: testSeller,
+			wantTo:   testBuyer,
+		},
+		{
+			name: "marketplace sale excludes network fee",
+			tx: &Tx{
+				Fee: 5000,
+				AccountKeys: []string{
+					testBuyer, testSeller, "M2mx93ekt1fmXSVkTrUL9xVFHkmME8HTUi5Cyc5aF7K",
+				},
+				PreBalances:  []uint64{3_000_000_000, 0, 1},
+				PostBalances: []uint64{1_999_995_000, 950_000_000, 1},
+				PreTokens:    []TokenBalance{holding(testSeller, 1)},
+				PostTokens:   []TokenBalance{holding(testBuyer, 1)},
+			},
+			wantKind:   KindSale,
+			wantFrom:   testSeller,
+			wantTo:     testBuyer,
+			wantPrice:  1_000_000_000,
+			wantMarket: "Magic Eden",
+		},
+		{
+			name: "direct sale paid to seller",
+			tx: &Tx{
+				AccountKeys:  []string{testSeller, testBuyer},
+				PreBalances:  []uint64{0, 500},
+				PostBalances: []uint64{400, 100},
+				PreTokens:    []TokenBalance{holding(testSeller, 1)},
+				PostTokens:   []TokenBalance{holding(testBuyer, 1)},
+			},
+			wantKind:  KindSale,
+			wantFrom:  testSeller,
+			wantTo:    testBuyer,
+			wantPrice: 400,
+		},
+		{
+			name: "escrow pays rent is not a sale",
+			tx: &Tx{
+				AccountKeys:  []string{testEscrow, testSeller, testBuyer},
+				PreBalances:  []uint64{100, 0, 50},
+				PostBalances: []uint64{100, 0, 40},
+				PreTokens:    []TokenBalance{holding(testSeller, 1)},
+				PostTokens:   []TokenBalance{holding(testBuyer, 1)},
+			},
+			wantKind: KindTransfer,
+			wantFrom: testSeller,
+			wantTo:   testBuyer,
+		},
+		{
+			name: "burn",
+			tx: &Tx{
+				PreTokens:  []TokenBalance{holding(testSeller, 1)},
+				PostTokens: []TokenBalance{holding(testSeller, 0)},
+			},
+			wantKind: KindBurn,
+			wantFrom: testSeller,
+		},
+		{
+			name: "failed transaction",
+			tx: &Tx{
+				Failed:     true,
+				PreTokens:  []TokenBalance{holding(testSeller, 1)},
+				PostTokens: []TokenBalance{holding(testBuyer, 1)},
+			},
+			wantNil: true,
+		},
+		{
+			name: "other mint only",
+			tx: &Tx{
+				PreTokens:  []TokenBalance{{Owner: testSeller, Mint: "Other", Amount: 1}},
+				PostTokens: []TokenBalance{{Owner: testBuyer, Mint: "Other", Amount: 1}},
+			},
+			wantNil: true,
+		},
+		{
+			name: "no token movement",
+			tx: &Tx{
+				PreTokens:  []TokenBalance{holding(testSeller, 1)},
+				PostTokens: []TokenBalance{holding(testSeller, 1)},
+			},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := ParseTx(testMint, tt.tx)
+			if tt.wantNil {
+				if event != nil {
+					t.Fatalf("Expected no event, got %+v", event)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatalf("Failed to parse event")
+			}
+			if event.Kind != tt.wantKind || event.From != tt.wantFrom || event.To != tt.wantTo {
+				t.Errorf("Got %s %s -> %s, expected %s %s -> %s",
+					event.Kind, event.From, event.To, tt.wantKind, tt.wantFrom, tt.wantTo)
+			}
+			if event.PriceLamports != tt.wantPrice {
+				t.Errorf("Got price %d, expected %d", event.PriceLamports, tt.wantPrice)
+			}
+			if event.Marketplace != tt.wantMarket {
+				t.Errorf("Got marketplace %q, expected %q", event.Marketplace, tt.wantMarket)
+			}
+		})
+	}
+}
+
+// fakeSource serves transactions from memory, newest signature last in order
+type fakeSource struct {
+	order []string
+	txs   map[string]*Tx
+	fail  string
+}
+
+func (f *fakeSource) Signatures(ctx context.Context, mint, until string) ([]string, error) {
+	var newer []string
+	for i := len(f.order) - 1; i >= 0; i-- {
+		if f.order[i] == until {
+			break
+		}
+		newer = append(newer, f.order[i])
+	}
+	return newer, nil
+}
+
+func (f *fakeSource) Transaction(ctx context.Context, signature string) (*Tx, error) {
+	if signature == f.fail {
+		return nil, errors.New("rpc unavailable")
+	}
+	return f.txs[signature], nil
+}
+
+func TestUpdate_ResumesFromLastSignature(t *testing.T) {
+	source := &fakeSource{
+		order: []string{"sig-mint", "sig-noop", "sig-transfer"},
+		txs: map[string]*Tx{
+			"sig-mint": {Signature: "sig-mint", PostTokens: []TokenBalance{holding(testSeller, 1)}},
+			"sig-noop": {Signature: "sig-noop"},
+			"sig-transfer": {
+				Signature:  "sig-transfer",
+				PreTokens:  []TokenBalance{holding(testSeller, 1)},
+				PostTokens: []TokenBalance{holding(testBuyer, 1)},
+			},
+		},
+		fail: "sig-transfer",
+	}
+	history := &History{Mint: testMint}
+
+	added, err := Update(context.Background(), source, history)
+	if err == nil {
+		t.Fatalf("Expected error from failing transaction")
+	}
+	if added != 1 || history.LastSignature != "sig-noop" {
+		t.Fatalf("Got %d event(s) up to %q, expected 1 up to sig-noop", added, history.LastSignature)
+	}
+
+	source.fail = ""
+	added, err = Update(context.Background(), source, history)
+	if err != nil {
+		t.Fatalf("Failed to update history: %v", err)
+	}
+	if added != 1 || len(history.Events) != 2 {
+		t.Fatalf("Got %d new and %d total event(s), expected 1 and 2", added, len(history.Events))
+	}
+	if history.Events[0].Kind != KindMint || history.Events[1].Kind != KindTransfer {
+		t.Errorf("Events out of order: %s, %s", history.Events[0].Kind, history.Events[1].Kind)
+	}
+
+	added, err = Update(context.Background(), source, history)
+	if err != nil || added != 0 {
+		t.Fatalf("Expected no new events, got %d (%v)", added, err)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+
+	history, err := Load(dir, testMint)
+	if err != nil {
+		t.Fatalf("Failed to load missing provenance: %v", err)
+	}
+	if history.Mint != testMint || len(history.Events) != 0 {
+		t.Fatalf("Expected empty history for %s, got %+v", testMint, history)
+	}
+
+	history.LastSignature = "sig"
+	history.Events = append(history.Events, Event{Signature: "sig", Kind: KindSale, PriceLamports: 2_500_000_000})
+	if err := Save(dir, history); err != nil {
+		t.Fatalf("Failed to save provenance: %v", err)
+	}
+
+	loaded, err := Load(dir, testMint)
+	if err != nil {
+		t.Fatalf("Failed to load provenance: %v", err)
+	}
+	if loaded.LastSignature != "sig" || len(loaded.Events) != 1 || loaded.Events[0].PriceSOL() != 2.5 {
+		t.Errorf("Round trip mismatch: %+v", loaded)
+	}
+}
+
+func (f *fakeSource) Recent(ctx context.Context, mint string, limit int) ([]string, error) {
+	newest, _ := f.Signatures(ctx, mint, "")
+	if len(newest) > limit {
+		newest = newest[:limit]
+	}
+	return newest, nil
+}
+
+func TestArrivalAndDeparture(t *testing.T) {
+	const testOther = "Other1111111111111111111111111111111111111111"
+	source := &fakeSource{
+		order: []string{"sig-mint", "sig-sale", "sig-noop"},
+		txs: map[string]*Tx{
+			"sig-mint": {Signature: "sig-mint", PostTokens: []TokenBalance{holding(testSeller, 1)}},
+			"sig-sale": {
+				Signature:    "sig-sale",
+				AccountKeys:  []string{testBuyer, testSeller, "M2mx93ekt1fmXSVkTrUL9xVFHkmME8HTUi5Cyc5aF7K"},
+				PreBalances:  []uint64{3_000_000_000, 0, 1},
+				PostBalances: []uint64{1_500_000_000, 1_500_000_000, 1},
+				PreTokens:    []TokenBalance{holding(testSeller, 1)},
+				PostTokens:   []TokenBalance{holding(testBuyer, 1)},
+			},
+			"sig-noop": {Signature: "sig-noop"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		departure bool
+		wallet    string
+		wantKind  EventKind
+		wantText  string
+	}{
+		{name: "bought", wallet: testBuyer, wantKind: KindSale, wantText: "bought from " + testSeller + " for 1.5000 SOL on Magic Eden"},
+		{name: "minted", wallet: testSeller, wantKind: KindMint, wantText: "minted"},
+		{name: "never arrived", wallet: testOther},
+		{name: "sold", departure: true, wallet: testSeller, wantKind: KindSale, wantText: "sold to " + testBuyer + " for 1.5000 SOL on Magic Eden"},
+		{name: "never left", departure: true, wallet: testBuyer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			find, describe := Arrival, Event.DescribeArrival
+			if tt.departure {
+				find, describe = Departure, Event.DescribeDeparture
+			}
+			event, err := find(context.Background(), source, testMint, tt.wallet)
+			if err != nil {
+				t.Fatalf("Failed to find the move: %v", err)
+			}
+			if tt.wantKind == "" {
+				if event != nil {
+					t.Errorf("Expected no move, got %+v", event)
+				}
+				return
+			}
+			if event == nil || event.Kind != tt.wantKind || describe(*event) != tt.wantText {
+				t.Errorf("Got %+v, want %s %q", event, tt.wantKind, tt.wantText)
+			}
+		})
+	}
+}
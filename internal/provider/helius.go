@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Helius offers DAS on its RPC hosts and enhanced-transaction webhooks
+type Helius struct {
+	apiKey  string
+	rpcURL  string // DAS endpoint without the key
+	apiBase string // REST API for webhooks
+}
+
+func newHelius(settings Settings) (Provider, error) {
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf("the helius provider needs HELIUS_API_KEY")
+	}
+	h := &Helius{apiKey: settings.APIKey, rpcURL: "https://mainnet.helius-rpc.com/", apiBase: "https://api.helius.xyz"}
+	if settings.Cluster == "devnet" {
+		h.rpcURL, h.apiBase = "https://devnet.helius-rpc.com/", "https://api-devnet.helius.xyz"
+	}
+	return h, nil
+}
+
+func (h *Helius) Name() string { return "helius" }
+
+func (h *Helius) Capabilities() Capabilities {
+	return Capabilities{AssetListing: true, CompressedAssets: true, Webhooks: true}
+}
+
+func (h *Helius) DASEndpoint() string {
+	return h.rpcURL + "?api-key=" + url.QueryEscape(h.apiKey)
+}
+
+// CreateWebhook registers an enhanced webhook for every transaction type
+func (h *Helius) CreateWebhook(ctx context.Context, hook Webhook) (string, error) {
+	body := map[string]interface{}{
+		"webhookURL":       hook.URL,
+		"transactionTypes": []string{"ANY"},
+		"accountAddresses": hook.Wallets,
+		"webhookType":      "enhanced",
+	}
+	if hook.AuthHeader != "" {
+		body["authHeader"] = hook.AuthHeader
+	}
+
+	var created struct {
+		WebhookID string `json:"webhookID"`
+	}
+	if err := doJSON(ctx, http.MethodPost, h.apiURL("/v0/webhooks"), nil, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create Helius webhook: %w", err)
+	}
+	if created.WebhookID == "" {
+		return "", fmt.Errorf("failed to create Helius webhook: no ID in response")
+	}
+	return created.WebhookID, nil
+}
+
+func (h *Helius) DeleteWebhook(ctx context.Context, id string) error {
+	if err := doJSON(ctx, http.MethodDelete, h.apiURL("/v0/webhooks/"+url.PathEscape(id)), nil, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete Helius webhook %s: %w", id, err)
+	}
+	return nil
+}
+
+func (h *Helius) apiURL(path string) string {
+	return h.apiBase + path + "?api-key=" + url.QueryEscape(h.apiKey)
+}
@@ -0,0 +1,174 @@
+// Package provider lets SolVault use the richer NFT APIs of enhanced RPC
+// providers (Helius, Shyft, QuickNode): asset listings through the Digital
+// Asset Standard (DAS) API, which include compressed NFTs, and webhooks that
+// push wallet activity instead of polling. The default provider is plain
+// Solana RPC, which offers none of these, so every caller keeps a raw RPC
+// path and only takes the faster one when the provider has it.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/solana"
+)
+
+// ErrUnsupported is returned for features the configured provider lacks
+var ErrUnsupported = errors.New("not supported by this provider")
+
+// Capabilities lists the enhanced APIs a provider offers
+type Capabilities struct {
+	AssetListing     bool `json:"asset_listing"`     // DAS getAssetsByOwner
+	CompressedAssets bool `json:"compressed_assets"` // Compressed NFTs appear in listings
+	Webhooks         bool `json:"webhooks"`          // Push notifications of wallet activity
+}
+
+// Provider is an RPC provider and the enhanced APIs it offers
+type Provider interface {
+	// Name identifies the provider, as set in NFT_PROVIDER
+	Name() string
+
+	// Capabilities reports which enhanced APIs are available
+	Capabilities() Capabilities
+
+	// DASEndpoint is the URL of the provider's DAS API, "" without one
+	DASEndpoint() string
+}
+
+// Webhook asks a provider to POST a wallet's activity to a URL
+type Webhook struct {
+	URL        string   // Where the provider sends events
+	Wallets    []string // Addresses to watch
+	AuthHeader string   // Sent as the Authorization header, if set
+}
+
+// Webhooks is implemented by providers that can push wallet activity
+type Webhooks interface {
+	// CreateWebhook registers hook and returns the provider's ID for it
+	CreateWebhook(ctx context.Context, hook Webhook) (string, error)
+
+	// DeleteWebhook removes a webhook by ID
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+// Settings configure a provider
+type Settings struct {
+	APIKey   string // Helius and Shyft API key
+	Endpoint string // Full endpoint URL, for QuickNode
+	Cluster  string // "devnet" selects the provider's devnet hosts
+}
+
+type factory func(settings Settings) (Provider, error)
+
+var providers = map[string]factory{
+	"rpc":       func(Settings) (Provider, error) { return RPC{}, nil },
+	"helius":    newHelius,
+	"shyft":     newShyft,
+	"quicknode": newQuickNode,
+}
+
+// Names lists the supported providers
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a provider by name
+func New(name string, settings Settings) (Provider, error) {
+	create, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown NFT provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return create(settings)
+}
+
+// FromEnv creates the provider named by NFT_PROVIDER (default "rpc"):
+//
+//	helius:    HELIUS_API_KEY
+//	shyft:     SHYFT_API_KEY
+//	quicknode: QUICKNODE_URL, an endpoint with the DAS add-on
+//	           (default: SOLANA_RPC_URL)
+//
+// The cluster follows SOLANA_RPC_URL.
+func FromEnv() (Provider, error) {
+	name := os.Getenv("NFT_PROVIDER")
+	if name == "" {
+		name = "rpc"
+	}
+	settings := Settings{Cluster: solana.ClusterFromURL(solana.PrimaryRPCURL())}
+	switch strings.ToLower(name) {
+	case "helius":
+		settings.APIKey = os.Getenv("HELIUS_API_KEY")
+	case "shyft":
+		settings.APIKey = os.Getenv("SHYFT_API_KEY")
+	case "quicknode":
+		settings.Endpoint = os.Getenv("QUICKNODE_URL")
+		if settings.Endpoint == "" {
+			settings.Endpoint = solana.PrimaryRPCURL()
+		}
+	}
+	return New(name, settings)
+}
+
+// RPC is the default provider: plain Solana RPC without enhanced APIs
+type RPC struct{}
+
+func (RPC) Name() string               { return "rpc" }
+func (RPC) Capabilities() Capabilities { return Capabilities{} }
+func (RPC) DASEndpoint() string        { return "" }
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// doJSON sends body as JSON and decodes a successful response into result
+func doJSON(ctx context.Context, method, url string, headers map[string]string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		want     string
+		wantDAS  string
+		wantHook bool
+		wantErr  bool
+	}{
+		{"default", map[string]string{}, "rpc", "", false, false},
+		{"helius", map[string]string{"NFT_PROVIDER": "helius", "HELIUS_API_KEY": "k"}, "helius", "https://mainnet.helius-rpc.com/?api-key=k", true, false},
+		{"helius devnet", map[string]string{"NFT_PROVIDER": "Helius", "HELIUS_API_KEY": "k", "SOLANA_RPC_URL": "https://api.devnet.solana.com"}, "helius", "https://devnet.helius-rpc.com/?api-key=k", true, false},
+		{"helius without key", map[string]string{"NFT_PROVIDER": "helius"}, "", "", false, true},
+		{"shyft", map[string]string{"NFT_PROVIDER": "shyft", "SHYFT_API_KEY": "k"}, "shyft", "https://rpc.shyft.to?api_key=k", true, false},
+		{"quicknode from RPC URL", map[string]string{"NFT_PROVIDER": "quicknode", "SOLANA_RPC_URL": "https://x.solana-mainnet.quiknode.pro/abc/"}, "quicknode", "https://x.solana-mainnet.quiknode.pro/abc/", false, false},
+		{"unknown", map[string]string{"NFT_PROVIDER": "alchemy"}, "", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"NFT_PROVIDER", "HELIUS_API_KEY", "SHYFT_API_KEY", "QUICKNODE_URL", "SOLANA_RPC_URL"} {
+				t.Setenv(key, tt.env[key])
+			}
+			p, err := FromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got provider %s", p.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create provider: %v", err)
+			}
+			if p.Name() != tt.want || p.DASEndpoint() != tt.wantDAS {
+				t.Errorf("Got %s with DAS %q, want %s with %q", p.Name(), p.DASEndpoint(), tt.want, tt.wantDAS)
+			}
+			if _, ok := p.(Webhooks); ok != tt.wantHook || p.Capabilities().Webhooks != tt.wantHook {
+				t.Errorf("Webhooks supported = %v, want %v", ok, tt.wantHook)
+			}
+		})
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	var got struct {
+		method string
+		path   string
+		auth   string
+		body   map[string]interface{}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.path = r.Method, r.URL.Path
+		got.auth = r.URL.Query().Get("api-key") + r.Header.Get("x-api-key")
+		got.body = nil
+		json.NewDecoder(r.Body).Decode(&got.body)
+		switch {
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/v0/"):
+			w.Write([]byte(`{"webhookID":"hook-1"}`))
+		default:
+			w.Write([]byte(`{"success":true,"result":{"id":"hook-1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		hooks      Webhooks
+		createPath string
+		deletePath string
+		urlField   string
+	}{
+		{"helius", &Helius{apiKey: "secret", apiBase: server.URL}, "/v0/webhooks", "/v0/webhooks/hook-1", "webhookURL"},
+		{"shyft", &Shyft{apiKey: "secret", network: "mainnet-beta", apiBase: server.URL}, "/sol/v1/callback/create", "/sol/v1/callback/remove", "callback_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			id, err := tt.hooks.CreateWebhook(ctx, Webhook{URL: "https://example.com/hook", Wallets: []string{"wallet1"}})
+			if err != nil {
+				t.Fatalf("Failed to create webhook: %v", err)
+			}
+			if id != "hook-1" || got.method != http.MethodPost || got.path != tt.createPath || got.auth != "secret" {
+				t.Errorf("Got ID %q from %s %s (key %q)", id, got.method, got.path, got.auth)
+			}
+			if got.body[tt.urlField] != "https://example.com/hook" {
+				t.Errorf("Request body %v lacks %s", got.body, tt.urlField)
+			}
+
+			if err := tt.hooks.DeleteWebhook(ctx, id); err != nil {
+				t.Fatalf("Failed to delete webhook: %v", err)
+			}
+			if got.method != http.MethodDelete || got.path != tt.deletePath {
+				t.Errorf("Delete sent %s %s", got.method, got.path)
+			}
+		})
+	}
+}
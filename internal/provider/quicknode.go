@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// QuickNode serves DAS on the user's own endpoint once the Metaplex DAS
+// add-on is enabled for it. Its Streams product is configured in the
+// QuickNode dashboard, so there is no webhook API here.
+type QuickNode struct {
+	endpoint string
+}
+
+func newQuickNode(settings Settings) (Provider, error) {
+	parsed, err := url.Parse(settings.Endpoint)
+	if settings.Endpoint == "" || err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("the quicknode provider needs QUICKNODE_URL (your endpoint URL with the DAS add-on)")
+	}
+	return &QuickNode{endpoint: settings.Endpoint}, nil
+}
+
+func (q *QuickNode) Name() string { return "quicknode" }
+
+func (q *QuickNode) Capabilities() Capabilities {
+	return Capabilities{AssetListing: true, CompressedAssets: true}
+}
+
+func (q *QuickNode) DASEndpoint() string { return q.endpoint }
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Shyft offers DAS on its RPC hosts and callbacks for wallet events
+type Shyft struct {
+	apiKey  string
+	network string // mainnet-beta or devnet
+	rpcURL  string // DAS endpoint without the key
+	apiBase string // REST API for callbacks
+}
+
+func newShyft(settings Settings) (Provider, error) {
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf("the shyft provider needs SHYFT_API_KEY")
+	}
+	s := &Shyft{apiKey: settings.APIKey, network: "mainnet-beta", rpcURL: "https://rpc.shyft.to", apiBase: "https://api.shyft.to"}
+	if settings.Cluster == "devnet" {
+		s.network, s.rpcURL = "devnet", "https://devnet-rpc.shyft.to"
+	}
+	return s, nil
+}
+
+func (s *Shyft) Name() string { return "shyft" }
+
+func (s *Shyft) Capabilities() Capabilities {
+	return Capabilities{AssetListing: true, CompressedAssets: true, Webhooks: true}
+}
+
+func (s *Shyft) DASEndpoint() string {
+	return s.rpcURL + "?api_key=" + url.QueryEscape(s.apiKey)
+}
+
+// CreateWebhook registers a callback for NFT and token movements
+func (s *Shyft) CreateWebhook(ctx context.Context, hook Webhook) (string, error) {
+	body := map[string]interface{}{
+		"network":      s.network,
+		"addresses":    hook.Wallets,
+		"callback_url": hook.URL,
+		"events":       []string{"NFT_TRANSFER", "NFT_BURN", "NFT_MINT", "NFT_SALE", "TOKEN_TRANSFER", "COMPRESSED_NFT_TRANSFER", "COMPRESSED_NFT_BURN"},
+	}
+	if hook.AuthHeader != "" {
+		body["auth_header"] = hook.AuthHeader
+	}
+
+	var created struct {
+		Success bool `json:"success"`
+		Result  struct {
+			ID string `json:"id"`
+		} `json:"result"`
+		Message string `json:"message"`
+	}
+	if err := doJSON(ctx, http.MethodPost, s.apiBase+"/sol/v1/callback/create", s.headers(), body, &created); err != nil {
+		return "", fmt.Errorf("failed to create Shyft callback: %w", err)
+	}
+	if !created.Success || created.Result.ID == "" {
+		return "", fmt.Errorf("failed to create Shyft callback: %s", created.Message)
+	}
+	return created.Result.ID, nil
+}
+
+func (s *Shyft) DeleteWebhook(ctx context.Context, id string) error {
+	body := map[string]string{"id": id}
+	if err := doJSON(ctx, http.MethodDelete, s.apiBase+"/sol/v1/callback/remove", s.headers(), body, nil); err != nil {
+		return fmt.Errorf("failed to delete Shyft callback %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *Shyft) headers() map[string]string {
+	return map[string]string{"x-api-key": s.apiKey}
+}
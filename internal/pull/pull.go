@@ -0,0 +1,428 @@
+// Package pull copies backups from another SolVault vault into this one, so
+// a second machine (say, a cold-storage laptop) keeps a copy of a vault an
+// always-on server maintains. Only NFTs that are missing or changed are
+// fetched, and every fetched file is checked against the source's integrity
+// manifest before it replaces anything.
+package pull
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// pullSuffix marks a downloaded file waiting to be moved into place
+const pullSuffix = ".pull"
+
+// Options controls a pull
+type Options struct {
+	Wallet string // Only pull backups held by this wallet; empty pulls all
+	DryRun bool   // Work out what would be fetched without writing
+
+	// Logf receives one line per added, updated or failed NFT (optional)
+	Logf func(format string, args ...interface{})
+}
+
+// Summary reports what a pull did
+type Summary struct {
+	Source    string   `json:"source"`
+	NFTs      int      `json:"nfts"` // Backups in the source (for --wallet)
+	Added     int      `json:"added"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Files     int      `json:"files"` // Files fetched
+	Bytes     int64    `json:"bytes"` // Original size of the files fetched
+	Skipped   []string `json:"skipped,omitempty"`
+	Failed    []string `json:"failed,omitempty"`
+	DryRun    bool     `json:"dry_run,omitempty"`
+	// Interrupted is set when the pull was cancelled before every NFT was
+	// compared
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
+// change is what pulling one backup did
+type change int
+
+const (
+	unchanged change = iota
+	added
+	updated
+)
+
+// remoteBackup is one backup directory in the source and the index entries
+// (its holder's and any linked wallets') that point at it
+type remoteBackup struct {
+	dir     string // Slash-separated, from the vault root
+	entries []*storage.IndexEntry
+}
+
+// Pull brings vault up to date with the backups in src. Backups that exist
+// only locally are left alone, and files are copied as stored, so an
+// encrypted source needs the same key in this vault.
+//
+// Explanation: Backups are compared by their integrity.json. An identical
+// manifest means nothing changed, so most runs read one small file per
+// NFT. Otherwise only files whose recorded hash differs are fetched, and
+// the manifest is written last: a pull cut short leaves the old manifest,
+// and the next run fetches the rest.
+func Pull(ctx context.Context, src Source, vault *storage.FileStorage, opts Options) (*Summary, error) {
+	summary := &Summary{Source: src.String(), DryRun: opts.DryRun}
+	backups, err := readIndex(ctx, src, opts.Wallet)
+	if err != nil {
+		return summary, err
+	}
+	summary.NFTs = len(backups)
+
+	for _, backup := range backups {
+		if ctx.Err() != nil {
+			summary.Interrupted = true
+			break
+		}
+		if backup.inProgress() {
+			summary.Skipped = append(summary.Skipped, backup.dir+": backup still in progress at the source")
+			continue
+		}
+		if !opts.DryRun {
+			if err := vault.CheckFreeSpace(); err != nil {
+				return summary, fmt.Errorf("pull stopped: %w", err)
+			}
+		}
+
+		result, files, size, err := pullBackup(ctx, src, vault, backup, opts.DryRun)
+		switch {
+		case errors.Is(err, crypt.ErrLocked):
+			return summary, fmt.Errorf("the source vault is encrypted; set the same SOLVAULT_PASSPHRASE or SOLVAULT_KEY_FILE here: %w", err)
+		case errors.Is(err, errNoManifest):
+			summary.Skipped = append(summary.Skipped, backup.dir+": "+err.Error())
+			continue
+		case err != nil && ctx.Err() != nil:
+			summary.Interrupted = true
+		case err != nil:
+			summary.Failed = append(summary.Failed, fmt.Sprintf("%s: %v", backup.dir, err))
+			logf(opts, "❌ %s: %v", backup.name(), err)
+			continue
+		}
+		if summary.Interrupted {
+			break
+		}
+
+		summary.Files += files
+		summary.Bytes += size
+		switch result {
+		case added:
+			summary.Added++
+			logf(opts, "✅ Added %s (%d file(s))", backup.name(), files)
+		case updated:
+			summary.Updated++
+			logf(opts, "🔄 Updated %s (%d file(s))", backup.name(), files)
+		default:
+			summary.Unchanged++
+		}
+	}
+	return summary, nil
+}
+
+// readIndex lists the backups in the source's index.json, in directory
+// order, limited to those wallet holds or held
+func readIndex(ctx context.Context, src Source, wallet string) ([]*remoteBackup, error) {
+	data, err := readFile(ctx, src, "index.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s is not a SolVault vault (no index.json)", src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source index: %w", err)
+	}
+	var index struct {
+		Entries map[string]*storage.IndexEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse source index: %w", err)
+	}
+
+	byDir := make(map[string]*remoteBackup)
+	for _, entry := range index.Entries {
+		if entry.DirName == "" || strings.ContainsAny(entry.DirName, `/\`) || strings.HasPrefix(entry.DirName, ".") {
+			continue // Never let the source name a path outside the backup
+		}
+		if !validKeys(entry.Wallet, entry.PrimaryWallet, entry.Mint) {
+			continue // Wallets and mints name directories and index keys too
+		}
+		dir := path.Join("wallets", entry.StorageWallet(), "nfts", entry.DirName)
+		if byDir[dir] == nil {
+			byDir[dir] = &remoteBackup{dir: dir}
+		}
+		byDir[dir].entries = append(byDir[dir].entries, entry)
+	}
+
+	var backups []*remoteBackup
+	for _, backup := range byDir {
+		if wallet == "" || backup.heldBy(wallet) {
+			sort.Slice(backup.entries, func(i, j int) bool { return backup.entries[i].Wallet < backup.entries[j].Wallet })
+			backups = append(backups, backup)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].dir < backups[j].dir })
+	return backups, nil
+}
+
+// validKeys reports whether every non-empty key parses as a Solana public
+// key and wallet and mint are present
+func validKeys(wallet, primaryWallet, mint string) bool {
+	if wallet == "" || mint == "" {
+		return false
+	}
+	for _, key := range []string{wallet, primaryWallet, mint} {
+		if key == "" {
+			continue
+		}
+		if _, err := solanago.PublicKeyFromBase58(key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *remoteBackup) heldBy(wallet string) bool {
+	for _, entry := range b.entries {
+		if entry.Wallet == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *remoteBackup) inProgress() bool {
+	for _, entry := range b.entries {
+		if entry.InProgress {
+			return true
+		}
+	}
+	return false
+}
+
+// name is the NFT's name for messages
+func (b *remoteBackup) name() string {
+	for _, entry := range b.entries {
+		if entry.Name != "" {
+			return entry.Name
+		}
+	}
+	return path.Base(b.dir)
+}
+
+// primary is the entry of the wallet whose directory holds the backup
+func (b *remoteBackup) primary() *storage.IndexEntry {
+	for _, entry := range b.entries {
+		if entry.PrimaryWallet == "" {
+			return entry
+		}
+	}
+	return b.entries[0]
+}
+
+// errNoManifest marks a source backup without integrity.json, which cannot
+// be checked in transit
+var errNoManifest = errors.New("no integrity.json at the source (run 'solvault verify' there to write one)")
+
+// pullBackup copies one backup and records it in the index. It returns how
+// many files were fetched and their original size.
+func pullBackup(ctx context.Context, src Source, vault *storage.FileStorage, backup *remoteBackup, dryRun bool) (change, int, int64, error) {
+	primary := backup.primary()
+	localDir := filepath.Join(vault.BaseDir(), filepath.FromSlash(backup.dir))
+	result := added
+	if existing := vault.Index().Get(primary.Wallet, primary.Mint); existing != nil {
+		// Keep the local directory, which may follow another naming layout
+		localDir, result = vault.EntryDir(existing), updated
+	} else if taken := vault.Index().ByDirName(primary.StorageWallet(), primary.DirName); taken != nil {
+		return unchanged, 0, 0, fmt.Errorf("directory is already used here by mint %s", taken.Mint)
+	}
+
+	rawManifest, err := readFile(ctx, src, backup.dir+"/"+storage.IntegrityFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return unchanged, 0, 0, errNoManifest
+	}
+	if err != nil {
+		return unchanged, 0, 0, err
+	}
+	localManifest, _ := os.ReadFile(filepath.Join(localDir, storage.IntegrityFile))
+	if result == updated && bytes.Equal(rawManifest, localManifest) {
+		return unchanged, 0, 0, nil
+	}
+
+	remote, err := decodeIntegrity(vault, rawManifest)
+	if err != nil {
+		return unchanged, 0, 0, err
+	}
+	local, _ := vault.ReadIntegrity(localDir)
+	fetch := changedFiles(remote, local, localDir)
+	if dryRun {
+		return result, len(fetch), fetchSize(remote, fetch), nil
+	}
+
+	// Download everything first, so a bad file leaves the backup untouched
+	staged := make(map[string]string, len(fetch))
+	defer func() {
+		for _, tmpPath := range staged {
+			os.Remove(tmpPath)
+		}
+	}()
+	for _, name := range fetch {
+		if err := checkName(name); err != nil {
+			return unchanged, 0, 0, err
+		}
+		target := filepath.Join(localDir, filepath.FromSlash(name))
+		tmpPath := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+pullSuffix)
+		staged[name] = tmpPath
+		if err := download(ctx, src, backup.dir+"/"+name, tmpPath); err != nil {
+			return unchanged, 0, 0, fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+		ok, err := vault.MatchesDigest(tmpPath, remote.Files[name])
+		if err != nil {
+			return unchanged, 0, 0, fmt.Errorf("failed to check %s: %w", name, err)
+		}
+		if !ok {
+			return unchanged, 0, 0, fmt.Errorf("%s does not match the source's integrity manifest", name)
+		}
+	}
+
+	for _, name := range fetch {
+		if err := os.Rename(staged[name], filepath.Join(localDir, filepath.FromSlash(name))); err != nil {
+			return unchanged, 0, 0, fmt.Errorf("failed to move %s into place: %w", name, err)
+		}
+		delete(staged, name)
+	}
+	// Files the source no longer has (e.g. pruned versions) go too
+	if local != nil {
+		for name := range local.Files {
+			if _, kept := remote.Files[name]; !kept && checkName(name) == nil {
+				os.Remove(filepath.Join(localDir, filepath.FromSlash(name)))
+			}
+		}
+	}
+	if err := writeFile(filepath.Join(localDir, storage.IntegrityFile), rawManifest); err != nil {
+		return unchanged, 0, 0, err
+	}
+
+	for _, entry := range backup.entries {
+		pulled := *entry
+		pulled.DirName = filepath.Base(localDir)
+		vault.Index().Put(&pulled)
+	}
+	if err := vault.Index().Save(); err != nil {
+		return unchanged, 0, 0, err
+	}
+	return result, len(fetch), fetchSize(remote, fetch), nil
+}
+
+// decodeIntegrity parses a source integrity.json, decrypting it with this
+// vault's key if needed
+func decodeIntegrity(vault *storage.FileStorage, data []byte) (*storage.Integrity, error) {
+	data, err := vault.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the source's integrity manifest: %w", err)
+	}
+	var integrity storage.Integrity
+	if err := json.Unmarshal(data, &integrity); err != nil {
+		return nil, fmt.Errorf("failed to parse the source's integrity manifest: %w", err)
+	}
+	if integrity.Algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported integrity algorithm %q at the source", integrity.Algorithm)
+	}
+	return &integrity, nil
+}
+
+// changedFiles lists the files in remote that local does not record with
+// the same hash, or that are gone from disk
+func changedFiles(remote, local *storage.Integrity, localDir string) []string {
+	var names []string
+	for name, want := range remote.Files {
+		if local != nil {
+			if have, ok := local.Files[name]; ok && have == want {
+				if _, err := os.Stat(filepath.Join(localDir, filepath.FromSlash(name))); err == nil {
+					continue
+				}
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fetchSize(integrity *storage.Integrity, names []string) int64 {
+	var size int64
+	for _, name := range names {
+		size += integrity.Files[name].Size
+	}
+	return size
+}
+
+// checkName refuses manifest entries that would write outside the backup
+func checkName(name string) error {
+	if name == "" || path.Clean(name) != name || path.IsAbs(name) || strings.HasPrefix(name, "../") || name == ".." || strings.Contains(name, `\`) {
+		return fmt.Errorf("refusing unsafe file name %q in the source's integrity manifest", name)
+	}
+	return nil
+}
+
+func readFile(ctx context.Context, src Source, name string) ([]byte, error) {
+	body, err := src.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// download streams a source file to filePath
+func download(ctx context.Context, src Source, name, filePath string) error {
+	body, err := src.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, body); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeFile replaces filePath with data through a temporary file, so a
+// crash never leaves it truncated
+func writeFile(filePath string, data []byte) error {
+	tmpPath := filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+pullSuffix)
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(filePath), err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(filePath), err)
+	}
+	return nil
+}
+
+func logf(opts Options, format string, args ...interface{}) {
+	if opts.Logf != nil {
+		opts.Logf(format, args...)
+	}
+}
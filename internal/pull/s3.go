@@ -0,0 +1,203 @@
+package pull
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptySHA256 is the SHA-256 of an empty request body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3Source reads a vault copied to an S3 bucket, e.g. with 'aws s3 sync'
+//
+// Explanation: Only GetObject is needed, so requests are signed here with
+// AWS Signature Version 4 rather than pulling in an SDK. Credentials come
+// from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN;
+// without them a public bucket is read anonymously. AWS_ENDPOINT_URL_S3
+// or AWS_ENDPOINT_URL points at an S3-compatible store (MinIO, R2, B2),
+// which is addressed path-style.
+type s3Source struct {
+	bucket    string
+	prefix    string
+	endpoint  string // Scheme and host, plus /bucket for path-style addressing
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func newS3Source(bucket, prefix string) (*s3Source, error) {
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	source := &s3Source{
+		bucket:    bucket,
+		prefix:    prefix,
+		region:    region,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		client:    &http.Client{Timeout: 30 * time.Minute}, // Media downloads can be large
+		now:       time.Now,
+	}
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		parsed, err := url.Parse(endpoint)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+		}
+		source.endpoint = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	} else if strings.Contains(bucket, ".") {
+		// Dotted bucket names do not match the wildcard certificate
+		source.endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com/%s", region, bucket)
+	} else {
+		source.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	}
+	return source, nil
+}
+
+func (s *s3Source) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := name
+	if s.prefix != "" {
+		key = s.prefix + "/" + name
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+"/"+uriEncode(key, false), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if s.accessKey != "" {
+		if s.token != "" {
+			req.Header.Set("X-Amz-Security-Token", s.token)
+		}
+		s.sign(req)
+	}
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	message := strings.TrimSpace(string(body))
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	case resp.StatusCode == http.StatusForbidden && strings.Contains(message, "<Code>AccessDenied</Code>") && s.accessKey != "":
+		// Without s3:ListBucket, S3 answers 403 rather than 404 for a
+		// missing key, and a backup's optional files may well be missing
+		return nil, fmt.Errorf("HTTP 403 for %s (grant s3:ListBucket so S3 reports missing files as such): %s", name, message)
+	}
+	return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+}
+
+func (s *s3Source) String() string {
+	if s.prefix != "" {
+		return "s3://" + s.bucket + "/" + s.prefix
+	}
+	return "s3://" + s.bucket
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to a bodiless
+// request, covering its host and every header already set
+func (s *s3Source) sign(req *http.Request) {
+	amzDate := s.now().UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+
+	values := map[string]string{"host": req.URL.Host}
+	for name, value := range req.Header {
+		values[strings.ToLower(name)] = strings.TrimSpace(strings.Join(value, ","))
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery sorts and encodes query parameters the way SigV4 expects
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte but the unreserved characters,
+// leaving slashes as they are unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var out strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '.', b == '_', b == '~':
+			out.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			out.WriteByte(b)
+		default:
+			fmt.Fprintf(&out, "%%%02X", b)
+		}
+	}
+	return out.String()
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
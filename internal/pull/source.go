@@ -0,0 +1,233 @@
+package pull
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source reads the files of another vault
+type Source interface {
+	// Open streams the vault file at name, a slash-separated path from the
+	// vault root, exactly as stored. A missing file is reported with an
+	// error wrapping os.ErrNotExist, from Open or at the end of the stream.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// String describes the source for messages, without credentials
+	String() string
+}
+
+// Open parses a source location:
+//
+//	https://host:8080         a vault served by 'solvault serve'; token is
+//	                          sent as a bearer token when the server needs one
+//	ssh://user@host[:port]/dir a vault directory read over the system ssh client
+//	s3://bucket[/prefix]       a vault copied to an S3 bucket, read with the
+//	                          standard AWS_* environment variables
+//	/mnt/server/SolVault       a vault directory, e.g. a network mount
+func Open(location, token string) (Source, error) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return nil, errors.New("no source vault given")
+	}
+	scheme, rest, hasScheme := strings.Cut(location, "://")
+	if !hasScheme {
+		return &dirSource{root: location}, nil
+	}
+
+	switch strings.ToLower(scheme) {
+	case "http", "https":
+		parsed, err := url.Parse(location)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid source URL %q", location)
+		}
+		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+		return &httpSource{
+			base:   parsed.String(),
+			token:  token,
+			client: &http.Client{Timeout: 30 * time.Minute}, // Media downloads can be large
+		}, nil
+	case "ssh":
+		parsed, err := url.Parse(location)
+		if err != nil || parsed.Hostname() == "" || parsed.Path == "" || parsed.Path == "/" {
+			return nil, fmt.Errorf("invalid SSH source %q: use ssh://user@host[:port]/path/to/vault", location)
+		}
+		target := parsed.Hostname()
+		if parsed.User != nil {
+			target = parsed.User.Username() + "@" + target
+		}
+		return &sshSource{target: target, port: parsed.Port(), root: parsed.Path}, nil
+	case "file":
+		return &dirSource{root: "/" + strings.TrimLeft(rest, "/")}, nil
+	case "s3":
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid S3 source %q: use s3://bucket/path/to/vault", location)
+		}
+		return newS3Source(bucket, strings.Trim(prefix, "/"))
+	default:
+		return nil, fmt.Errorf("unsupported source %q: use an http(s)://, ssh:// or s3:// URL or a directory", location)
+	}
+}
+
+// dirSource reads a vault directory on this machine or a mounted share
+type dirSource struct {
+	root string
+}
+
+func (d *dirSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.root, filepath.FromSlash(name)))
+}
+
+func (d *dirSource) String() string {
+	return d.root
+}
+
+// httpSource reads a vault through the vault file API of 'solvault serve'
+type httpSource struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+func (h *httpSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	escaped := make([]string, 0, strings.Count(name, "/")+1)
+	for _, part := range strings.Split(name, "/") {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.base+"/api/v1/vault/files/"+strings.Join(escaped, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	message := strings.TrimSpace(string(body))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("HTTP %d: %s (set SOLVAULT_PULL_TOKEN to a viewer token for the server)", resp.StatusCode, message)
+	}
+	return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, message)
+}
+
+func (h *httpSource) String() string {
+	return h.base
+}
+
+// sshMissing is the exit status the remote command uses for a missing file
+const sshMissing = 3
+
+// sshSource reads a vault directory on another machine through the system
+// ssh client, so its keys, agent and ~/.ssh/config apply
+//
+// Explanation: Every file is one 'cat' over its own connection. Setting
+// ControlMaster in ~/.ssh/config lets ssh reuse one connection for all of
+// them, which matters for vaults with many small files.
+type sshSource struct {
+	target string // [user@]host
+	port   string
+	root   string
+}
+
+func (s *sshSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	remotePath := shellQuote(path.Join(s.root, name))
+	args := []string{"-o", "BatchMode=yes"}
+	if s.port != "" {
+		args = append(args, "-p", s.port)
+	}
+	// "--" keeps a target such as "-oProxyCommand=..." from being read as an option
+	args = append(args, "--", s.target, fmt.Sprintf("test -f %s || exit %d; exec cat -- %s", remotePath, sshMissing, remotePath))
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run ssh: %w", err)
+	}
+	return &sshReader{name: name, cmd: cmd, stdout: stdout, stderr: stderr}, nil
+}
+
+func (s *sshSource) String() string {
+	if s.port != "" {
+		return fmt.Sprintf("ssh://%s:%s%s", s.target, s.port, s.root)
+	}
+	return "ssh://" + s.target + s.root
+}
+
+// sshReader streams a remote file and reports how the command ended once
+// the output is exhausted
+type sshReader struct {
+	name   string
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr *bytes.Buffer
+	done   bool
+}
+
+func (r *sshReader) Read(p []byte) (int, error) {
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := r.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *sshReader) Close() error {
+	r.stdout.Close()
+	if r.done {
+		return nil
+	}
+	r.wait()
+	return nil
+}
+
+// wait reaps ssh and turns its exit status into an error
+func (r *sshReader) wait() error {
+	r.done = true
+	err := r.cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == sshMissing {
+		return fmt.Errorf("%s: %w", r.name, os.ErrNotExist)
+	}
+	if err != nil {
+		if message := strings.TrimSpace(r.stderr.String()); message != "" {
+			return fmt.Errorf("ssh failed: %s", message)
+		}
+		return fmt.Errorf("ssh failed: %w", err)
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+)
+
+// maxResponseBytes caps how much of a registry response we read
+const maxResponseBytes = 4 << 20
+
+// Record is an attestation as stored by the registry
+type Record struct {
+	proof.Attestation
+	ID         string    `json:"id,omitempty"`
+	ReceivedAt time.Time `json:"received_at,omitempty"`
+
+	// Verified is computed locally; the registry's word is never trusted
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verify_error,omitempty"`
+}
+
+// MediaSummary groups the verified attestations that agree on one media hash
+type MediaSummary struct {
+	MediaHash string   `json:"media_hash"`
+	Verifiers []string `json:"verifiers"` // Distinct signers, sorted
+}
+
+// Client talks to a community verification registry
+//
+// The registry protocol is deliberately small:
+//
+//	POST {endpoint}/v1/attestations          body: signed proof.Attestation
+//	GET  {endpoint}/v1/attestations?mint=X   returns {"attestations": [...]}
+type Client struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a registry client; apiKey is sent as a bearer token when set
+func NewClient(endpoint, apiKey string) *Client {
+	return &Client{
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish submits a signed attestation to the registry
+func (c *Client) Publish(ctx context.Context, att *proof.Attestation) (*Record, error) {
+	if err := att.Verify(); err != nil {
+		return nil, fmt.Errorf("refusing to publish invalid attestation: %w", err)
+	}
+
+	body, err := json.Marshal(att)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/attestations", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var record Record
+	if err := c.do(req, &record); err != nil {
+		return nil, err
+	}
+	record.Verified = true
+	return &record, nil
+}
+
+// Lookup fetches every attestation the registry holds for a mint and checks
+// each signature locally
+func (c *Client) Lookup(ctx context.Context, mint string) ([]*Record, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/attestations?mint="+url.QueryEscape(mint), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Attestations []*Record `json:"attestations"`
+	}
+	if err := c.do(req, &response); err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(response.Attestations))
+	for _, record := range response.Attestations {
+		if record.Mint != mint {
+			continue // A registry answering with other mints is ignored, not trusted
+		}
+		record.Verified, record.VerifyError = true, ""
+		if err := record.Attestation.Verify(); err != nil {
+			record.Verified, record.VerifyError = false, err.Error()
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+// Summarize groups verified records by media hash, most-attested first, so a
+// buyer can see whether independent parties saw the same artwork bytes
+func Summarize(records []*Record) []MediaSummary {
+	verifiers := make(map[string]map[string]bool)
+	for _, record := range records {
+		if !record.Verified {
+			continue
+		}
+		hash := proof.NormalizeHash(record.MediaHash)
+		if verifiers[hash] == nil {
+			verifiers[hash] = make(map[string]bool)
+		}
+		verifiers[hash][record.Verifier] = true
+	}
+
+	summaries := make([]MediaSummary, 0, len(verifiers))
+	for hash, signers := range verifiers {
+		summary := MediaSummary{MediaHash: hash}
+		for signer := range signers {
+			summary.Verifiers = append(summary.Verifiers, signer)
+		}
+		sort.Strings(summary.Verifiers)
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if len(summaries[i].Verifiers) != len(summaries[j].Verifiers) {
+			return len(summaries[i].Verifiers) > len(summaries[j].Verifiers)
+		}
+		return summaries[i].MediaHash < summaries[j].MediaHash
+	})
+	return summaries
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if c.endpoint == "" {
+		return nil, fmt.Errorf("no registry endpoint configured (set REGISTRY_ENDPOINT or pass --registry)")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, target interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read registry response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("registry returned %s: %s", resp.Status, apiErr.Error)
+		}
+		return fmt.Errorf("registry returned %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to parse registry response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// fakeRegistry stores whatever is posted and serves it back by mint
+func fakeRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	var stored []*Record
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPost:
+			var record Record
+			if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			record.ID = "rec-1"
+			stored = append(stored, &record)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(record)
+		case http.MethodGet:
+			var matches []*Record
+			for _, record := range stored {
+				if record.Mint == r.URL.Query().Get("mint") {
+					matches = append(matches, record)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"attestations": matches})
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func signed(t *testing.T, mint, mediaHash string) *proof.Attestation {
+	t.Helper()
+
+	att := &proof.Attestation{
+		Mint:      mint,
+		MediaHash: mediaHash,
+		ProofHash: "sha256:0123",
+		Timestamp: time.Now(),
+	}
+	if err := att.Sign(solanago.NewWallet().PrivateKey); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	return att
+}
+
+func TestPublishLookup(t *testing.T) {
+	ts := fakeRegistry(t)
+	client := NewClient(ts.URL+"/", "secret")
+	ctx := context.Background()
+	mint := solanago.NewWallet().PublicKey().String()
+
+	for _, hash := range []string{"sha256:aaaa", "sha256:aaaa", "sha256:bbbb"} {
+		if _, err := client.Publish(ctx, signed(t, mint, hash)); err != nil {
+			t.Fatalf("Failed to publish: %v", err)
+		}
+	}
+
+	// A forged record slipped into the registry must not be counted
+	forged := signed(t, mint, "sha256:cccc")
+	forged.MediaHash = "sha256:bbbb"
+	if _, err := client.Publish(ctx, forged); err == nil {
+		t.Fatalf("Expected publish to refuse an invalid attestation")
+	}
+
+	records, err := client.Lookup(ctx, mint)
+	if err != nil {
+		t.Fatalf("Failed to look up: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	summaries := Summarize(records)
+	if len(summaries) != 2 || summaries[0].MediaHash != "aaaa" || len(summaries[0].Verifiers) != 2 {
+		t.Errorf("Unexpected summary: %+v", summaries)
+	}
+}
+
+func TestLookup_UnverifiedRecord(t *testing.T) {
+	mint := solanago.NewWallet().PublicKey().String()
+	att := signed(t, mint, "sha256:aaaa")
+	att.ProofHash = "sha256:tampered"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"attestations": []*proof.Attestation{att}})
+	}))
+	defer ts.Close()
+
+	records, err := NewClient(ts.URL, "").Lookup(context.Background(), mint)
+	if err != nil {
+		t.Fatalf("Failed to look up: %v", err)
+	}
+	if len(records) != 1 || records[0].Verified || records[0].VerifyError == "" {
+		t.Fatalf("Expected tampered record to be flagged, got %+v", records)
+	}
+	if len(Summarize(records)) != 0 {
+		t.Errorf("Expected unverified records to be left out of the summary")
+	}
+}
+
+func TestClient_NoEndpoint(t *testing.T) {
+	if _, err := NewClient("", "").Lookup(context.Background(), "mint"); err == nil {
+		t.Errorf("Expected error without an endpoint")
+	}
+}
@@ -0,0 +1,127 @@
+//go:build chromedp
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// videoFPS is the frame rate of recordings
+const videoFPS = 10
+
+func init() {
+	newRenderer = func(opts Options) Renderer {
+		return &chromeRenderer{opts: opts}
+	}
+}
+
+// chromeRenderer runs a fresh headless Chromium for each page. Set
+// CHROME_PATH to use a browser that is not on PATH.
+type chromeRenderer struct {
+	opts Options
+}
+
+func (r *chromeRenderer) Capture(ctx context.Context, pageURL, dir, name string) ([]Capture, error) {
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
+
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.WindowSize(r.opts.Width, r.opts.Height))
+	if path := os.Getenv("CHROME_PATH"); path != "" {
+		allocOpts = append(allocOpts, chromedp.ExecPath(path))
+	}
+	if os.Geteuid() == 0 {
+		// Chromium refuses to start its sandbox as root, as in most containers
+		allocOpts = append(allocOpts, chromedp.NoSandbox)
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, allocOpts...)
+	defer cancelAlloc()
+	browser, cancelBrowser := chromedp.NewContext(allocCtx)
+	defer cancelBrowser()
+
+	var screenshot []byte
+	err := chromedp.Run(browser,
+		chromedp.EmulateViewport(int64(r.opts.Width), int64(r.opts.Height)),
+		chromedp.Navigate(pageURL),
+		chromedp.Sleep(r.opts.Delay),
+		chromedp.CaptureScreenshot(&screenshot),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", pageURL, err)
+	}
+	pngPath := filepath.Join(dir, name+".png")
+	if err := os.WriteFile(pngPath, screenshot, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save screenshot: %w", err)
+	}
+	captures := []Capture{{Path: pngPath, ContentType: "image/png"}}
+
+	if r.opts.VideoSeconds > 0 {
+		videoPath := filepath.Join(dir, name+".mp4")
+		if err := r.record(browser, videoPath); err != nil {
+			return captures, err
+		}
+		captures = append(captures, Capture{Path: videoPath, ContentType: "video/mp4"})
+	}
+	return captures, nil
+}
+
+// record screenshots the running page videoFPS times a second and encodes
+// the frames to an MP4 with ffmpeg.
+//
+// Explanation: Screenshots work with every Chromium build, unlike screencast
+// streams, at the cost of timing: a page too heavy to capture at videoFPS
+// plays back faster than it ran.
+func (r *chromeRenderer) record(ctx context.Context, videoPath string) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("recording video needs ffmpeg on PATH")
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpeg, "-y", "-loglevel", "error",
+		"-f", "image2pipe", "-framerate", strconv.Itoa(videoFPS), "-i", "-",
+		"-c:v", "libx264", "-pix_fmt", "yuv420p", "-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		videoPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	frames, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	ticker := time.NewTicker(time.Second / videoFPS)
+	defer ticker.Stop()
+	for i := 0; i < r.opts.VideoSeconds*videoFPS; i++ {
+		var frame []byte
+		if err = chromedp.Run(ctx, chromedp.CaptureScreenshot(&frame)); err != nil {
+			err = fmt.Errorf("failed to record frame: %w", err)
+			break
+		}
+		if _, err = frames.Write(frame); err != nil {
+			err = fmt.Errorf("failed to encode video: %w", err)
+			break
+		}
+		<-ticker.C
+	}
+	frames.Close()
+	if waitErr := cmd.Wait(); err == nil && waitErr != nil {
+		err = fmt.Errorf("failed to encode video: %v: %s", waitErr, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if err != nil {
+		os.Remove(videoPath)
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+// Package render captures what an interactive or generative HTML NFT draws
+// in a browser, so the backup keeps the artwork itself and not only the
+// code that produces it.
+//
+// Rendering drives headless Chromium and is only compiled into builds made
+// with the chromedp build tag:
+//
+//	go build -tags chromedp ./cmd/solvault
+package render
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnavailable is returned by New in builds without headless rendering
+var ErrUnavailable = errors.New("headless rendering is not built in; rebuild with -tags chromedp")
+
+// Options controls a capture
+type Options struct {
+	Width        int
+	Height       int
+	Delay        time.Duration // How long the page runs before the screenshot
+	VideoSeconds int           // Length of an MP4 recording; 0 takes a screenshot only
+	Timeout      time.Duration // Limit for one page, recording included
+}
+
+// DefaultOptions capture a square screenshot after five seconds
+var DefaultOptions = Options{Width: 1024, Height: 1024, Delay: 5 * time.Second, Timeout: 2 * time.Minute}
+
+// Capture is one file written by a Renderer
+type Capture struct {
+	Path        string
+	ContentType string
+}
+
+// Renderer loads a page in a browser and saves what it draws
+type Renderer interface {
+	// Capture renders pageURL and writes <name>.png, and <name>.mp4 when a
+	// recording is requested, into dir. Captures that succeeded are
+	// returned even when a later one fails.
+	Capture(ctx context.Context, pageURL, dir, name string) ([]Capture, error)
+}
+
+// newRenderer is provided by the chromedp build
+var newRenderer func(opts Options) Renderer
+
+// Available reports whether this build can render pages
+func Available() bool {
+	return newRenderer != nil
+}
+
+// New returns a Renderer, or ErrUnavailable in builds without chromedp
+func New(opts Options) (Renderer, error) {
+	if newRenderer == nil {
+		return nil, ErrUnavailable
+	}
+	return newRenderer(opts), nil
+}
@@ -0,0 +1,316 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/archive"
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Options controls a restore run
+type Options struct {
+	TargetDir string   // Where restored NFTs are written
+	Wallet    string   // Only restore this wallet when non-empty
+	Uploader  Uploader // Optional: re-upload media and metadata to produce new URIs
+	Progress  func(msg string)
+}
+
+// Result describes one restored NFT
+type Result struct {
+	Wallet         string            `json:"wallet"`
+	Mint           string            `json:"mint"`
+	Name           string            `json:"name"`
+	Dir            string            `json:"dir"`
+	OriginalURI    string            `json:"original_metadata_uri"`
+	NewMetadataURI string            `json:"new_metadata_uri,omitempty"`
+	RewrittenURIs  map[string]string `json:"rewritten_uris,omitempty"` // Original media URL -> new URI
+	MissingMedia   []string          `json:"missing_media,omitempty"`
+	Errors         []string          `json:"errors,omitempty"`
+}
+
+// Report summarizes a restore run; it is written as restore_report.json in the target
+type Report struct {
+	Source     string    `json:"source"`
+	RestoredAt time.Time `json:"restored_at"`
+	Uploader   string    `json:"uploader,omitempty"`
+	Results    []*Result `json:"results"`
+}
+
+// Restore re-materializes every NFT in source (a vault directory or an archive
+// of one) into opts.TargetDir as <wallet>/<nft name>/{metadata.json,media/}
+func Restore(ctx context.Context, source string, opts Options) (*Report, error) {
+	if opts.TargetDir == "" {
+		return nil, fmt.Errorf("target directory is required")
+	}
+
+	vaultDir := source
+	if archive.IsArchive(source) {
+		tempDir, err := os.MkdirTemp("", "solvault_restore_*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		opts.progress("📦 Extracting %s...", filepath.Base(source))
+		if err := archive.Extract(source, tempDir); err != nil {
+			return nil, err
+		}
+		vaultDir = findVaultRoot(tempDir)
+	}
+
+	if _, err := os.Stat(filepath.Join(vaultDir, "wallets")); err != nil {
+		return nil, fmt.Errorf("no SolVault backup found in %s (missing wallets/ directory)", source)
+	}
+
+	store, err := storage.NewFileStorage(vaultDir)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	if store.Locked() {
+		return nil, crypt.ErrLocked
+	}
+
+	wallets, err := store.ListWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Source:     source,
+		RestoredAt: time.Now(),
+	}
+	if opts.Uploader != nil {
+		report.Uploader = opts.Uploader.Name()
+	}
+
+	for _, wallet := range wallets {
+		if opts.Wallet != "" && wallet.String() != opts.Wallet {
+			continue
+		}
+
+		nfts, err := store.ListNFTs(ctx, wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		taken := make(map[string]bool)
+		for _, nft := range nfts {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+
+			result := restoreNFT(ctx, store, wallet, nft, taken, opts)
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	if err := os.MkdirAll(opts.TargetDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create target directory: %w", err)
+	}
+	if err := writeJSON(filepath.Join(opts.TargetDir, "restore_report.json"), report); err != nil {
+		return nil, fmt.Errorf("failed to write restore report: %w", err)
+	}
+
+	return report, nil
+}
+
+// restoreNFT copies one NFT's metadata and media into the target directory,
+// re-uploading them first when an uploader is configured
+func restoreNFT(ctx context.Context, store *storage.FileStorage, wallet solanago.PublicKey, nft *storage.StoredNFT, taken map[string]bool, opts Options) *Result {
+	info := nft.NFTInfo
+	result := &Result{
+		Wallet:      wallet.String(),
+		Mint:        info.MintAddress.String(),
+		OriginalURI: info.MetadataURI,
+	}
+
+	name := info.MintAddress.String()
+	if info.Metadata != nil && strings.TrimSpace(info.Metadata.Name) != "" {
+		name = info.Metadata.Name
+	}
+	result.Name = name
+
+	dirName := storage.UniqueFilename(name, func(candidate string) bool {
+		return taken[strings.ToLower(candidate)]
+	})
+	taken[strings.ToLower(dirName)] = true
+	result.Dir = filepath.Join(opts.TargetDir, wallet.String(), dirName)
+
+	opts.progress("♻️  Restoring %s", name)
+
+	if err := os.MkdirAll(filepath.Join(result.Dir, "media"), 0755); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to create directory: %v", err))
+		return result
+	}
+
+	// Copy media out of the vault, uploading each file when requested
+	sourceMediaDir := filepath.Join(store.NFTDir(wallet, info.MintAddress), "media")
+	for _, media := range info.MediaFiles {
+		if !validMediaName(media.Filename) {
+			result.Errors = append(result.Errors, fmt.Sprintf("skipped media with unsafe file name %q", media.Filename))
+			continue
+		}
+		src := filepath.Join(sourceMediaDir, media.Filename)
+		dst := filepath.Join(result.Dir, "media", media.Filename)
+
+		if err := copyFile(store, src, dst); err != nil {
+			result.MissingMedia = append(result.MissingMedia, media.Filename)
+			continue
+		}
+
+		if opts.Uploader != nil {
+			newURI, err := uploadFile(ctx, opts.Uploader, dst)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to upload %s: %v", media.Filename, err))
+				continue
+			}
+			if result.RewrittenURIs == nil {
+				result.RewrittenURIs = make(map[string]string)
+			}
+			result.RewrittenURIs[media.URL] = newURI
+		}
+	}
+
+	if info.Metadata == nil {
+		result.Errors = append(result.Errors, "no metadata in backup")
+		return result
+	}
+
+	metadata := rewriteMetadataURIs(*info.Metadata, result.RewrittenURIs)
+	metadataPath := filepath.Join(result.Dir, "metadata.json")
+	if err := writeJSON(metadataPath, metadata); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to write metadata: %v", err))
+		return result
+	}
+
+	// Metadata goes up last so it can reference the new media URIs
+	if opts.Uploader != nil {
+		newURI, err := uploadFile(ctx, opts.Uploader, metadataPath)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to upload metadata: %v", err))
+		} else {
+			result.NewMetadataURI = newURI
+		}
+	}
+
+	return result
+}
+
+// validMediaName reports whether name is a plain file name inside media/
+//
+// Explanation: Names come from nft_data.json in a backup that may not be
+// trusted, and are joined onto both the vault and the target directory,
+// so anything that could name a path elsewhere is refused.
+func validMediaName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`) && name == filepath.Base(name)
+}
+
+// rewriteMetadataURIs returns a copy of metadata with media URIs replaced
+// according to the rewrite map
+func rewriteMetadataURIs(metadata fetcher.NFTMetadata, rewrites map[string]string) fetcher.NFTMetadata {
+	if len(rewrites) == 0 {
+		return metadata
+	}
+
+	if uri, ok := rewrites[metadata.Image]; ok {
+		metadata.Image = uri
+	}
+	if uri, ok := rewrites[metadata.AnimationURL]; ok {
+		metadata.AnimationURL = uri
+	}
+
+	files := make([]fetcher.File, len(metadata.Properties.Files))
+	for i, file := range metadata.Properties.Files {
+		if uri, ok := rewrites[file.URI]; ok {
+			file.URI = uri
+		}
+		files[i] = file
+	}
+	metadata.Properties.Files = files
+
+	return metadata
+}
+
+// findVaultRoot locates the directory containing wallets/ inside an extracted
+// archive, which may wrap the vault in a single top-level folder
+func findVaultRoot(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "wallets")); err == nil {
+		return dir
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return dir
+	}
+	for _, entry := range entries {
+		candidate := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(candidate, "wallets")); err == nil {
+				return candidate
+			}
+		}
+	}
+	return dir
+}
+
+func uploadFile(ctx context.Context, uploader Uploader, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return uploader.Upload(ctx, filepath.Base(path), file)
+}
+
+// copyFile copies a media file out of the vault, decrypting and
+// decompressing it as needed
+func copyFile(store *storage.FileStorage, src, dst string) error {
+	if store.Encrypted() || store.Compressed() {
+		data, err := store.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0644)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func writeJSON(path string, data interface{}) error {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, jsonData, 0644)
+}
+
+func (o Options) progress(format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(fmt.Sprintf(format, args...))
+	}
+}
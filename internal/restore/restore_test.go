@@ -0,0 +1,220 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// newTestVault creates a vault with a single NFT and one media file
+func newTestVault(t *testing.T) (string, solanago.PublicKey) {
+	t.Helper()
+
+	vaultDir := t.TempDir()
+	store, err := storage.NewFileStorage(vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	walletAddr := solanago.MustPublicKeyFromBase58("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")
+	mintAddr := solanago.MustPublicKeyFromBase58("ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3")
+
+	nftInfo := &fetcher.NFTInfo{
+		MintAddress: mintAddr,
+		Owner:       walletAddr,
+		FetchedAt:   time.Now(),
+		MetadataURI: "https://dead.example.com/1.json",
+		Metadata: &fetcher.NFTMetadata{
+			Name:  "Midnight Lion #01",
+			Image: "https://dead.example.com/1.png",
+		},
+		MediaFiles: []*fetcher.MediaFile{
+			{URL: "https://dead.example.com/1.png", Filename: "1.png"},
+		},
+	}
+	if err := store.SaveNFT(context.Background(), nftInfo); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	mediaPath := filepath.Join(store.NFTDir(walletAddr, mintAddr), "media", "1.png")
+	if err := os.WriteFile(mediaPath, []byte("png bytes"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	return vaultDir, walletAddr
+}
+
+func TestRestore_Directory(t *testing.T) {
+	vaultDir, walletAddr := newTestVault(t)
+	targetDir := t.TempDir()
+
+	report, err := Restore(context.Background(), vaultDir, Options{TargetDir: targetDir})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if len(report.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(report.Results))
+	}
+
+	nftDir := filepath.Join(targetDir, walletAddr.String(), "Midnight Lion #01")
+	for _, name := range []string{"metadata.json", filepath.Join("media", "1.png")} {
+		if _, err := os.Stat(filepath.Join(nftDir, name)); err != nil {
+			t.Errorf("Expected restored file %s: %v", name, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(targetDir, "restore_report.json")); err != nil {
+		t.Errorf("Expected restore report: %v", err)
+	}
+}
+
+func TestRestore_RejectsUnsafeMediaNames(t *testing.T) {
+	vaultDir := t.TempDir()
+	store, err := storage.NewFileStorage(vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	walletAddr, mintAddr := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	// A crafted backup whose media names climb out of media/: four levels
+	// up from the NFT's media directory in the target is the target's
+	// parent, and in the vault it is wallets/
+	escape := "../../../../escaped.txt"
+	names := []string{escape, `..\..\escaped.txt`, ".hidden", "", "1.png"}
+	nftInfo := &fetcher.NFTInfo{
+		MintAddress: mintAddr,
+		Owner:       walletAddr,
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: "Crafted"},
+	}
+	for _, name := range names {
+		nftInfo.MediaFiles = append(nftInfo.MediaFiles, &fetcher.MediaFile{URL: "https://example.com/" + name, Filename: name})
+	}
+	if err := store.SaveNFT(context.Background(), nftInfo); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	mediaDir := filepath.Join(store.NFTDir(walletAddr, mintAddr), "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatalf("Failed to create media dir: %v", err)
+	}
+	for _, name := range []string{escape, "1.png"} {
+		if err := os.WriteFile(filepath.Join(mediaDir, name), []byte("bytes"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	root := t.TempDir()
+	targetDir := filepath.Join(root, "target")
+	report, err := Restore(context.Background(), vaultDir, Options{TargetDir: targetDir})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	// Nothing may be written outside the target
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			if rel, _ := filepath.Rel(targetDir, path); strings.HasPrefix(rel, "..") {
+				t.Errorf("Restore wrote %s outside the target", path)
+			}
+		}
+		return nil
+	})
+	if _, err := os.Stat(filepath.Join(targetDir, walletAddr.String(), "Crafted", "media", "1.png")); err != nil {
+		t.Errorf("Expected the safe media file to be restored: %v", err)
+	}
+	if got := len(report.Results[0].Errors); got != len(names)-1 {
+		t.Errorf("Got %d errors %v, want one per unsafe name", got, report.Results[0].Errors)
+	}
+}
+
+func TestRestore_UploadRewritesURIs(t *testing.T) {
+	vaultDir, _ := newTestVault(t)
+
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/add" {
+			http.NotFound(w, r)
+			return
+		}
+		uploads++
+		json.NewEncoder(w).Encode(map[string]string{"Hash": fmt.Sprintf("bafy%d", uploads)})
+	}))
+	defer server.Close()
+
+	report, err := Restore(context.Background(), vaultDir, Options{
+		TargetDir: t.TempDir(),
+		Uploader:  NewIPFSUploader(server.URL, ""),
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	result := report.Results[0]
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+	if result.RewrittenURIs["https://dead.example.com/1.png"] != "ipfs://bafy1" {
+		t.Errorf("Media URI not rewritten: %v", result.RewrittenURIs)
+	}
+	if result.NewMetadataURI != "ipfs://bafy2" {
+		t.Errorf("Expected new metadata URI ipfs://bafy2, got %s", result.NewMetadataURI)
+	}
+
+	data, err := os.ReadFile(filepath.Join(result.Dir, "metadata.json"))
+	if err != nil {
+		t.Fatalf("Failed to read restored metadata: %v", err)
+	}
+	var metadata fetcher.NFTMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("Failed to parse restored metadata: %v", err)
+	}
+	if metadata.Image != "ipfs://bafy1" {
+		t.Errorf("Expected rewritten image URI, got %s", metadata.Image)
+	}
+}
+
+func TestRestore_EncryptedVault(t *testing.T) {
+	vaultDir, walletAddr := newTestVault(t)
+
+	store, err := storage.NewFileStorage(vaultDir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	params, cipher, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	if _, err := store.EnableEncryption(params, cipher); err != nil {
+		t.Fatalf("Failed to encrypt vault: %v", err)
+	}
+
+	t.Setenv(crypt.EnvPassphrase, "")
+	if _, err := Restore(context.Background(), vaultDir, Options{TargetDir: t.TempDir()}); !errors.Is(err, crypt.ErrLocked) {
+		t.Fatalf("Got %v restoring without a key, want %v", err, crypt.ErrLocked)
+	}
+
+	t.Setenv(crypt.EnvPassphrase, "correct horse")
+	targetDir := t.TempDir()
+	if _, err := Restore(context.Background(), vaultDir, Options{TargetDir: targetDir}); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	media, err := os.ReadFile(filepath.Join(targetDir, walletAddr.String(), "Midnight Lion #01", "media", "1.png"))
+	if err != nil || string(media) != "png bytes" {
+		t.Errorf("Media was not decrypted: %q (%v)", media, err)
+	}
+}
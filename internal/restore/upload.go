@@ -0,0 +1,97 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Uploader publishes a restored file to permanent storage and returns its new URI
+type Uploader interface {
+	// Name identifies the uploader in reports (e.g. "ipfs")
+	Name() string
+
+	// Upload stores data under filename and returns the resulting URI
+	Upload(ctx context.Context, filename string, data io.Reader) (string, error)
+}
+
+// IPFSUploader adds files through a Kubo-compatible HTTP API (/api/v0/add).
+// This works with a local IPFS node as well as pinning services exposing the same API.
+type IPFSUploader struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewIPFSUploader creates an uploader for the IPFS API at apiURL.
+// token is sent as a bearer token when non-empty.
+func NewIPFSUploader(apiURL, token string) *IPFSUploader {
+	return &IPFSUploader{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		token:  token,
+		client: &http.Client{
+			Timeout: 5 * time.Minute, // Media uploads can be large
+		},
+	}
+}
+
+// Name returns "ipfs"
+func (u *IPFSUploader) Name() string {
+	return "ipfs"
+}
+
+// Upload adds and pins the file, returning an ipfs:// URI
+func (u *IPFSUploader) Upload(ctx context.Context, filename string, data io.Reader) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+	if _, err := io.Copy(part, data); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize upload form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.apiURL+"/api/v0/add?pin=true&cid-version=1", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+	if u.token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.token)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", filename, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP error %d uploading %s", resp.StatusCode, filename)
+	}
+
+	var added struct {
+		Name string `json:"Name"`
+		Hash string `json:"Hash"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		return "", fmt.Errorf("failed to parse IPFS response: %w", err)
+	}
+	if added.Hash == "" {
+		return "", fmt.Errorf("IPFS response for %s did not include a CID", filename)
+	}
+
+	return "ipfs://" + added.Hash, nil
+}
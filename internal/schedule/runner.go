@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// ExecFunc runs one scheduled command
+type ExecFunc func(ctx context.Context, sched *Schedule) error
+
+// Runner fires stored schedules as their cron expressions come due.
+// Schedules are re-read every tick, so `schedule add/remove` takes effect
+// without restarting the daemon.
+type Runner struct {
+	store *Store
+	exec  ExecFunc
+	logf  func(format string, args ...interface{})
+	now   func() time.Time
+}
+
+// NewRunner returns a runner that executes due schedules with exec
+func NewRunner(store *Store, exec ExecFunc, logf func(format string, args ...interface{})) *Runner {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	return &Runner{store: store, exec: exec, logf: logf, now: time.Now}
+}
+
+// Run blocks until ctx is cancelled, checking for due schedules once a minute
+func (r *Runner) Run(ctx context.Context) {
+	last := r.now().Truncate(time.Minute)
+	for {
+		wait := last.Add(time.Minute).Sub(r.now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		now := r.now().Truncate(time.Minute)
+		r.Tick(ctx, last, now)
+		last = now
+	}
+}
+
+// Tick runs every schedule with a match in (from, to]. Runs happen one at
+// a time so scheduled jobs never write to the vault concurrently.
+func (r *Runner) Tick(ctx context.Context, from, to time.Time) {
+	schedules, err := r.store.List()
+	if err != nil {
+		r.logf("❌ Failed to load schedules: %v\n", err)
+		return
+	}
+
+	for _, sched := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+
+		expr, err := Parse(sched.Cron)
+		if err != nil {
+			r.logf("⚠️  Skipping schedule %s: %v\n", sched.ID, err)
+			continue
+		}
+		next := expr.Next(from)
+		if next.IsZero() || next.After(to) {
+			continue
+		}
+
+		r.logf("⏰ Running schedule %s: %s\n", sched.ID, sched.Command())
+		runErr := r.exec(ctx, sched)
+		if runErr != nil {
+			r.logf("❌ Schedule %s failed: %v\n", sched.ID, runErr)
+		} else {
+			r.logf("✅ Schedule %s finished\n", sched.ID)
+		}
+		if err := r.store.RecordRun(sched.ID, r.now(), runErr); err != nil {
+			r.logf("⚠️  Failed to record run of %s: %v\n", sched.ID, err)
+		}
+	}
+}
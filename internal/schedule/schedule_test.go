@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse_Next(t *testing.T) {
+	// Wednesday 2025-01-15 10:30
+	base := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"daily at 3am", "0 3 * * *", time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{"every 15 minutes", "*/15 * * * *", time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"next minute", "* * * * *", time.Date(2025, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"hourly descriptor", "@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"weekdays range", "0 9 * * 1-5", time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"list of days", "0 12 1,20 * *", time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)},
+		{"month rollover", "0 0 1 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"dom or dow", "0 0 31 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+			}
+			if got := expr.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	}
+
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) should fail", expr)
+		}
+	}
+
+	expr, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Failed to parse Feb 30: %v", err)
+	}
+	if next := expr.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Feb 30 should never match, got %v", next)
+	}
+}
+
+func TestStore_AddListRemove(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedules.json"))
+
+	if _, err := store.Add("bad", []string{"sync"}); err == nil {
+		t.Error("Expected invalid cron to be rejected")
+	}
+
+	first, err := store.Add("0 3 * * *", []string{"sync"})
+	if err != nil {
+		t.Fatalf("Failed to add schedule: %v", err)
+	}
+	if _, err := store.Add("@weekly", []string{"verify", "--all"}); err != nil {
+		t.Fatalf("Failed to add schedule: %v", err)
+	}
+
+	// A fresh store sees the persisted schedules
+	reopened := NewStore(store.Path())
+	schedules, err := reopened.List()
+	if err != nil {
+		t.Fatalf("Failed to list schedules: %v", err)
+	}
+	if len(schedules) != 2 || schedules[0].ID != first.ID {
+		t.Fatalf("Unexpected schedules: %+v", schedules)
+	}
+
+	if _, err := reopened.Remove(first.ID[:4]); err != nil {
+		t.Fatalf("Failed to remove schedule: %v", err)
+	}
+	if _, err := reopened.Remove(first.ID); err == nil {
+		t.Error("Expected removing a missing schedule to fail")
+	}
+
+	schedules, _ = store.List()
+	if len(schedules) != 1 || schedules[0].Command() != "solvault verify --all" {
+		t.Errorf("Unexpected schedules after remove: %+v", schedules)
+	}
+}
+
+func TestRunner_Tick(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedules.json"))
+	store.Add("0 3 * * *", []string{"sync"})
+	failing, _ := store.Add("*/5 * * * *", []string{"verify"})
+
+	var ran []string
+	runner := NewRunner(store, func(ctx context.Context, sched *Schedule) error {
+		ran = append(ran, sched.ID)
+		if sched.ID == failing.ID {
+			return errors.New("boom")
+		}
+		return nil
+	}, nil)
+
+	from := time.Date(2025, 1, 15, 2, 59, 0, 0, time.UTC)
+	runner.Tick(context.Background(), from, from.Add(time.Minute))
+	if len(ran) != 2 {
+		t.Fatalf("Expected both schedules to run at 03:00, ran %v", ran)
+	}
+
+	ran = nil
+	runner.Tick(context.Background(), from.Add(time.Minute), from.Add(2*time.Minute))
+	if len(ran) != 0 {
+		t.Errorf("Expected nothing to run at 03:01, ran %v", ran)
+	}
+
+	schedules, _ := store.List()
+	for _, sched := range schedules {
+		want := "ok"
+		if sched.ID == failing.ID {
+			want = "boom"
+		}
+		if sched.LastStatus != want || sched.LastRun.IsZero() {
+			t.Errorf("Schedule %s: status %q, want %q", sched.ID, sched.LastStatus, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule is a solvault command that runs whenever its cron expression matches
+type Schedule struct {
+	ID         string    `json:"id"`
+	Cron       string    `json:"cron"`
+	Args       []string  `json:"args"` // solvault arguments, e.g. ["sync", "--wallet", "..."]
+	CreatedAt  time.Time `json:"created_at"`
+	LastRun    time.Time `json:"last_run,omitempty"`
+	LastStatus string    `json:"last_status,omitempty"` // "ok" or the error message
+}
+
+// Command renders the schedule's arguments as a solvault command line
+func (s *Schedule) Command() string {
+	return "solvault " + strings.Join(s.Args, " ")
+}
+
+// Store persists schedules as JSON so they survive daemon restarts
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns ~/.solvault/schedules.json
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".solvault", "schedules.json"), nil
+}
+
+// NewStore returns a store backed by the file at path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the backing file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns all schedules ordered by creation time
+func (s *Store) List() ([]*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Add validates cron and stores a new schedule for args
+func (s *Store) Add(cron string, args []string) (*Schedule, error) {
+	if _, err := Parse(cron); err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("schedule needs a command to run")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	sched := &Schedule{ID: id, Cron: cron, Args: args, CreatedAt: time.Now().UTC()}
+	schedules = append(schedules, sched)
+	if err := s.save(schedules); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// Remove deletes the schedule whose ID starts with prefix. The prefix must
+// identify exactly one schedule.
+func (s *Store) Remove(prefix string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, sched := range schedules {
+		if strings.HasPrefix(sched.ID, prefix) {
+			if index >= 0 {
+				return nil, fmt.Errorf("schedule ID %q is ambiguous", prefix)
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("schedule not found: %s", prefix)
+	}
+
+	removed := schedules[index]
+	schedules = append(schedules[:index], schedules[index+1:]...)
+	if err := s.save(schedules); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// RecordRun stores the outcome of a run. Schedules removed in the meantime
+// are ignored.
+func (s *Store) RecordRun(id string, at time.Time, runErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, sched := range schedules {
+		if sched.ID == id {
+			sched.LastRun = at.UTC()
+			sched.LastStatus = "ok"
+			if runErr != nil {
+				sched.LastStatus = runErr.Error()
+			}
+			return s.save(schedules)
+		}
+	}
+	return nil
+}
+
+func (s *Store) load() ([]*Schedule, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+
+	var schedules []*Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+func (s *Store) save(schedules []*Schedule) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+
+	// Explanation: Write to a temp file and rename so a crash never leaves
+	// a half-written schedule file behind
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate schedule ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NazWright/solvault/internal/proof"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// handlePostAttestation accepts a co-signed proof from another verifier,
+// validates its signature and appends it to the NFT's proof chain
+func (s *Server) handlePostAttestation(w http.ResponseWriter, r *http.Request) {
+	var att proof.Attestation
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAttestationBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&att); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid attestation body: %v", err)
+		return
+	}
+
+	if err := att.Verify(); err != nil {
+		s.logf("rejected attestation for %s from %s: %v", att.Mint, r.RemoteAddr, err)
+		writeError(w, http.StatusUnauthorized, "%v", err)
+		return
+	}
+
+	if !s.trusted(att.Verifier) {
+		s.logf("rejected attestation for %s from untrusted verifier %s", att.Mint, att.Verifier)
+		writeError(w, http.StatusForbidden, "verifier %s is not trusted by this vault", att.Verifier)
+		return
+	}
+
+	if skew := time.Since(att.Timestamp); skew > s.opts.MaxClockSkew || -skew > s.opts.MaxClockSkew {
+		writeError(w, http.StatusBadRequest, "attestation timestamp %s is outside the accepted window", att.Timestamp.Format(time.RFC3339))
+		return
+	}
+
+	nftDir, matchesLocal, ok := s.locateNFT(r, att)
+	if !ok {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", att.Mint)
+		return
+	}
+
+	if err := s.vaultFor(r).CheckQuota(); err != nil {
+		s.logf("rejected attestation for %s: %v", att.Mint, err)
+		writeError(w, http.StatusInsufficientStorage, "%v", err)
+		return
+	}
+
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	chain, err := proof.LoadChain(nftDir, att.Mint)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	entry, err := chain.Append(att, r.RemoteAddr, matchesLocal)
+	if errors.Is(err, proof.ErrDuplicate) {
+		writeError(w, http.StatusConflict, "%v", err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	if err := chain.Save(); err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	s.logf("accepted attestation for %s from %s (matches local media: %t)", att.Mint, att.Verifier, matchesLocal)
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// handleGetAttestations returns the proof chain collected for a mint
+func (s *Server) handleGetAttestations(w http.ResponseWriter, r *http.Request) {
+	mint := r.PathValue("mint")
+
+	nftDir, _, ok := s.locateNFT(r, proof.Attestation{Mint: mint})
+	if !ok {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", mint)
+		return
+	}
+
+	s.chainMu.Lock()
+	chain, err := proof.LoadChain(nftDir, mint)
+	s.chainMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	if chain.Entries == nil {
+		chain.Entries = []*proof.ChainEntry{}
+	}
+
+	writeJSON(w, http.StatusOK, chain)
+}
+
+// locateNFT finds the vault directory for the attested mint and reports
+// whether the attested media hash matches one of our own media files
+func (s *Server) locateNFT(r *http.Request, att proof.Attestation) (string, bool, bool) {
+	vault := s.vaultFor(r)
+	entries := vault.Index().ByMint(att.Mint)
+	if len(entries) == 0 {
+		return "", false, false
+	}
+
+	wallet, err := solanago.PublicKeyFromBase58(entries[0].Wallet)
+	if err != nil {
+		return "", false, false
+	}
+	mint, err := solanago.PublicKeyFromBase58(att.Mint)
+	if err != nil {
+		return "", false, false
+	}
+
+	matchesLocal := false
+	if stored, err := vault.GetNFT(r.Context(), wallet, mint); err == nil && stored.NFTInfo != nil {
+		want := proof.NormalizeHash(att.MediaHash)
+		for _, media := range stored.NFTInfo.MediaFiles {
+			if want != "" && proof.NormalizeHash(media.Checksum) == want {
+				matchesLocal = true
+				break
+			}
+		}
+	}
+
+	return vault.NFTDir(wallet, mint), matchesLocal, true
+}
+
+func (s *Server) trusted(verifier string) bool {
+	if len(s.opts.TrustedVerifiers) == 0 {
+		return true
+	}
+	for _, trusted := range s.opts.TrustedVerifiers {
+		if trusted.String() == verifier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// AuditFile is the log of API mutations kept at the root of the vault they
+// were made against (a tenant's own vault in multi-tenant mode)
+const AuditFile = "audit.jsonl"
+
+// AuditEntry is one line of the audit log: a request that could change the
+// vault, whether it was allowed or not
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal,omitempty"` // Empty when authentication failed
+	Role      Role      `json:"role,omitempty"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	GRPCCode  int       `json:"grpc_code,omitempty"` // Set for gRPC calls that failed
+	Remote    string    `json:"remote"`
+}
+
+// audit appends a mutation to the vault's audit log
+//
+// Explanation: A failure to write the log is reported through Logf but
+// does not fail the request, which has already been answered by now.
+func (s *Server) audit(vault *storage.FileStorage, principal *Principal, r *http.Request, recorder *statusRecorder) {
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Status: recorder.status,
+		Remote: r.RemoteAddr,
+	}
+	if strings.HasPrefix(r.URL.Path, grpcPrefix) {
+		entry.GRPCCode = grpcCode(recorder.Header())
+	}
+	if principal != nil {
+		entry.Principal, entry.Role = principal.Name, principal.Role
+		if principal.Tenant != nil {
+			entry.Tenant = principal.Tenant.ID
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.logf("failed to audit %s %s: %v", r.Method, r.URL.Path, err)
+		return
+	}
+
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+
+	file, err := os.OpenFile(filepath.Join(vault.BaseDir(), AuditFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		s.logf("failed to open audit log: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		s.logf("failed to write audit log: %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// Role is what an authenticated caller may do
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Reads attestations, media and usage
+	RoleOperator Role = "operator" // Also posts attestations, triggers backups and deletes them
+)
+
+// ParseRole reads a role name; empty means operator, the role every
+// caller had before roles existed
+func ParseRole(name string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(name))); role {
+	case "":
+		return RoleOperator, nil
+	case RoleViewer, RoleOperator:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown role %q: use viewer or operator", name)
+	}
+}
+
+// allows reports whether the role may make a request that changes the
+// vault (mutates) or only reads it
+func (r Role) allows(mutates bool) bool {
+	return r == RoleOperator || !mutates
+}
+
+// ErrUnauthenticated is returned for a missing, unknown, expired or badly
+// signed credential
+var ErrUnauthenticated = errors.New("a valid API token is required")
+
+// Principal is who a request authenticated as
+type Principal struct {
+	Name   string
+	Role   Role
+	Tenant *storage.Tenant // Set in multi-tenant mode
+}
+
+// anonymous is the principal of requests to a server without authentication
+var anonymous = &Principal{Name: "anonymous", Role: RoleOperator}
+
+// Token is a static API token and the role it grants
+type Token struct {
+	Name   string
+	Role   Role
+	Secret string
+}
+
+// ParseTokens reads tokens configured as name:role:secret, e.g. from
+// SERVE_TOKENS
+func ParseTokens(entries []string) ([]Token, error) {
+	var tokens []Token
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid API token %q: use name:role:secret", redactToken(entry))
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid API token %s: %w", parts[0], err)
+		}
+		tokens = append(tokens, Token{Name: parts[0], Role: role, Secret: parts[2]})
+	}
+	return tokens, nil
+}
+
+// redactToken keeps a malformed token's secret out of error messages
+func redactToken(entry string) string {
+	if name, _, ok := strings.Cut(entry, ":"); ok {
+		return name + ":…"
+	}
+	return "…"
+}
+
+// Auth authenticates API callers by static token or by HS256 JWT
+type Auth struct {
+	Tokens []Token
+
+	// JWTSecret verifies HS256 JWTs whose "role" claim names the caller's
+	// role and "sub" claim their name; "exp" and "nbf" are enforced
+	JWTSecret []byte
+
+	now func() time.Time
+}
+
+// Enabled reports whether any credential is configured
+func (a *Auth) Enabled() bool {
+	return a != nil && (len(a.Tokens) > 0 || len(a.JWTSecret) > 0)
+}
+
+// Authenticate resolves a bearer credential to a principal
+//
+// Explanation: Every static token is compared in constant time, like
+// tenant keys, so response times do not leak how close a guess came.
+func (a *Auth) Authenticate(credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	var match *Token
+	for i, token := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(credential), []byte(token.Secret)) == 1 {
+			match = &a.Tokens[i]
+		}
+	}
+	if match != nil {
+		return &Principal{Name: match.Name, Role: match.Role}, nil
+	}
+	if len(a.JWTSecret) > 0 && strings.Count(credential, ".") == 2 {
+		return a.verifyJWT(credential)
+	}
+	return nil, ErrUnauthenticated
+}
+
+// jwtClaims are the claims SolVault reads from a JWT
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// verifyJWT checks an HS256 JWT's signature and validity window
+func (a *Auth) verifyJWT(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Algorithm != "HS256" {
+		return nil, fmt.Errorf("%w: only HS256 JWTs are accepted", ErrUnauthenticated)
+	}
+
+	mac := hmac.New(sha256.New, a.JWTSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: bad JWT signature", ErrUnauthenticated)
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: bad JWT claims", ErrUnauthenticated)
+	}
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	if claims.ExpiresAt != 0 && !now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, fmt.Errorf("%w: JWT expired", ErrUnauthenticated)
+	}
+	if claims.NotBefore != 0 && now().Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, fmt.Errorf("%w: JWT not valid yet", ErrUnauthenticated)
+	}
+	// A JWT has to name its role; defaulting to operator would let any
+	// token minted for another service in as one
+	if claims.Role == "" {
+		return nil, fmt.Errorf("%w: JWT has no role claim", ErrUnauthenticated)
+	}
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	name := claims.Subject
+	if name == "" {
+		name = "jwt"
+	}
+	return &Principal{Name: name, Role: role}, nil
+}
+
+func decodeJWTPart(part string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// SignJWT issues an HS256 JWT for subject with role, valid for ttl (or
+// without expiry when ttl is 0)
+func SignJWT(secret []byte, subject string, role Role, ttl time.Duration) (string, error) {
+	claims := jwtClaims{Subject: subject, Role: string(role)}
+	if ttl > 0 {
+		claims.ExpiresAt = time.Now().Add(ttl).Unix()
+	}
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signing))
+	return signing + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// principalKey and vaultKey are the context keys of the caller and of the
+// vault their request reads and writes
+type (
+	principalKey struct{}
+	vaultKey     struct{}
+)
+
+// authorize authenticates every /api/v1 request and gRPC call, checks the
+// caller's role allows it and records mutations in the audit log. The
+// health check stays open.
+//
+// Explanation: In multi-tenant mode only tenant API keys are accepted, so
+// a static token or JWT can never reach the shared vault behind the
+// tenants. Without tenants, tokens or a JWT secret every caller is an
+// anonymous operator, as before authentication existed.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rpc, isGRPC := strings.CutPrefix(r.URL.Path, grpcPrefix)
+		if !strings.HasPrefix(r.URL.Path, "/api/") && !isGRPC {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mutates := r.Method != http.MethodGet && r.Method != http.MethodHead
+		if isGRPC {
+			mutates = grpcMutations[rpc]
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		principal, vault, err := s.principal(r)
+		switch {
+		case err != nil:
+			s.logf("rejected %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+			if isGRPC {
+				writeGRPCStatus(recorder, grpcUnauthenticated, err.Error())
+				break
+			}
+			recorder.Header().Set("WWW-Authenticate", `Bearer realm="solvault"`)
+			writeError(recorder, http.StatusUnauthorized, "%v", err)
+		case !principal.Role.allows(mutates):
+			s.logf("denied %s %s to %s (%s)", r.Method, r.URL.Path, principal.Name, principal.Role)
+			if isGRPC {
+				writeGRPCStatus(recorder, grpcPermissionDenied, fmt.Sprintf("role %s may not call %s", principal.Role, rpc))
+				break
+			}
+			writeError(recorder, http.StatusForbidden, "role %s may not %s %s", principal.Role, r.Method, r.URL.Path)
+		default:
+			ctx := context.WithValue(r.Context(), principalKey{}, principal)
+			ctx = context.WithValue(ctx, vaultKey{}, vault)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+		}
+
+		if mutates {
+			if vault == nil {
+				vault = s.vault
+			}
+			s.audit(vault, principal, r, recorder)
+		}
+	})
+}
+
+// principal authenticates a request and picks the vault it is served from
+func (s *Server) principal(r *http.Request) (*Principal, *storage.FileStorage, error) {
+	credential := apiKey(r)
+	if s.opts.Tenants != nil {
+		tenant := s.opts.Tenants.Authenticate(credential)
+		if tenant == nil {
+			return nil, nil, fmt.Errorf("%w: unknown tenant API key", ErrUnauthenticated)
+		}
+		role, err := ParseRole(tenant.Role)
+		if err != nil {
+			return nil, nil, err
+		}
+		vault, err := s.tenantVault(tenant)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &Principal{Name: tenant.ID, Role: role, Tenant: tenant}, vault, nil
+	}
+	if !s.opts.Auth.Enabled() {
+		return anonymous, s.vault, nil
+	}
+	principal, err := s.opts.Auth.Authenticate(credential)
+	if err != nil {
+		return nil, nil, err
+	}
+	return principal, s.vault, nil
+}
+
+// apiKey reads the credential from "Authorization: Bearer <key>" or X-API-Key
+func apiKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, key, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// statusRecorder remembers the status a handler answered with, for the
+// audit log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController flush streamed gRPC responses
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens([]string{"dashboard:viewer:abc", " ci:operator:d:e:f ", ""})
+	if err != nil {
+		t.Fatalf("Failed to parse tokens: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].Role != RoleViewer || tokens[1].Secret != "d:e:f" {
+		t.Errorf("Expected two tokens, got %+v", tokens)
+	}
+
+	for _, entry := range []string{"hunter2", "name:hunter2", "name:admin:hunter2", "name:viewer:"} {
+		_, err := ParseTokens([]string{entry})
+		if err == nil {
+			t.Errorf("Expected %q to be refused", entry)
+		} else if strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("Expected the secret kept out of the error, got %v", err)
+		}
+	}
+}
+
+func TestAuth_JWT(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	auth := &Auth{JWTSecret: secret}
+
+	valid, err := SignJWT(secret, "ci", RoleOperator, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to sign JWT: %v", err)
+	}
+	principal, err := auth.Authenticate(valid)
+	if err != nil || principal.Name != "ci" || principal.Role != RoleOperator {
+		t.Fatalf("Expected the JWT to authenticate ci as operator, got %+v (err %v)", principal, err)
+	}
+
+	expired, _ := SignJWT(secret, "ci", RoleViewer, time.Hour)
+	otherKey, _ := SignJWT([]byte("another secret of thirty-two chars"), "ci", RoleOperator, time.Hour)
+	noRole, _ := SignJWT(secret, "ci", "", 0)
+	tampered := valid[:strings.LastIndex(valid, ".")] + ".AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+		now   time.Time
+	}{
+		{"expired", expired, time.Now().Add(2 * time.Hour)},
+		{"wrong secret", otherKey, time.Now()},
+		{"no role", noRole, time.Now()},
+		{"tampered", tampered, time.Now()},
+		{"not a token", "svk_whatever", time.Now()},
+	}
+	for _, test := range tests {
+		auth.now = func() time.Time { return test.now }
+		if _, err := auth.Authenticate(test.token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", test.name, err)
+		}
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	auth := &Auth{Tokens: []Token{
+		{Name: "dashboard", Role: RoleViewer, Secret: "viewer-secret"},
+		{Name: "ci", Role: RoleOperator, Secret: "operator-secret"},
+	}}
+	ts, mint := newTestServer(t, Options{Auth: auth})
+
+	request := func(method, path, token string) int {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to %s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	nft := "/api/v1/nfts/" + mint.String()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"health check", http.MethodGet, "/healthz", "", http.StatusOK},
+		{"read without token", http.MethodGet, nft + "/media", "", http.StatusUnauthorized},
+		{"read with wrong token", http.MethodGet, nft + "/media", "guess", http.StatusUnauthorized},
+		{"viewer reads", http.MethodGet, nft + "/media", "viewer-secret", http.StatusOK},
+		{"viewer deletes", http.MethodDelete, nft, "viewer-secret", http.StatusForbidden},
+		{"viewer backs up", http.MethodPost, "/api/v1/backups", "viewer-secret", http.StatusForbidden},
+		{"operator backs up without a backup hook", http.MethodPost, "/api/v1/backups", "operator-secret", http.StatusNotImplemented},
+		{"operator deletes", http.MethodDelete, nft, "operator-secret", http.StatusOK},
+		{"deleted", http.MethodGet, nft + "/media", "operator-secret", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		if status := request(test.method, test.path, test.token); status != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, status)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	auth := &Auth{Tokens: []Token{{Name: "dashboard", Role: RoleViewer, Secret: "viewer-secret"}}}
+	ts := httptest.NewServer(New(vault, Options{Auth: auth}).Handler())
+	t.Cleanup(ts.Close)
+
+	send := func(method, path, token string, body []byte) {
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to %s %s: %v", method, path, err)
+		}
+		resp.Body.Close()
+	}
+	mint := solanago.NewWallet().PublicKey().String()
+	send(http.MethodGet, "/api/v1/attestations/"+mint, "viewer-secret", nil)
+	send(http.MethodDelete, "/api/v1/nfts/"+mint, "viewer-secret", nil)
+	send(http.MethodPost, "/api/v1/attestations", "", []byte("{}"))
+
+	file, err := os.Open(filepath.Join(vault.BaseDir(), AuditFile))
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse audit entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected the two mutations audited and the read not, got %+v", entries)
+	}
+	if entries[0].Principal != "dashboard" || entries[0].Method != http.MethodDelete || entries[0].Status != http.StatusForbidden {
+		t.Errorf("Expected the viewer's denied delete, got %+v", entries[0])
+	}
+	if entries[1].Principal != "" || entries[1].Status != http.StatusUnauthorized {
+		t.Errorf("Expected the unauthenticated post, got %+v", entries[1])
+	}
+}
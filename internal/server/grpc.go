@@ -0,0 +1,389 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// The gRPC service of proto/solvault/v1/vault.proto, served on the REST
+// API's address.
+//
+// Explanation: gRPC is HTTP/2 with length-prefixed protobuf messages and a
+// status in the trailers, which net/http serves natively (cleartext HTTP/2
+// is enabled in ListenAndServe). Implementing that framing here keeps the
+// gRPC runtime out of the binary while any generated gRPC client can still
+// call the service.
+
+// grpcPrefix is the path every call of the Vault service starts with
+const grpcPrefix = "/solvault.v1.Vault/"
+
+// maxGRPCMessage caps a request message, as gRPC's default does
+const maxGRPCMessage = 4 << 20
+
+// grpcMutations are the calls that change a vault: they need an operator
+// and are audited, like non-GET REST requests
+var grpcMutations = map[string]bool{"Backup": true}
+
+// gRPC status codes used by the service
+const (
+	grpcOK                = 0
+	grpcCanceled          = 1
+	grpcInvalidArgument   = 3
+	grpcNotFound          = 5
+	grpcPermissionDenied  = 7
+	grpcResourceExhausted = 8
+	grpcAborted           = 10
+	grpcUnimplemented     = 12
+	grpcInternal          = 13
+	grpcUnavailable       = 14
+	grpcUnauthenticated   = 16
+)
+
+const (
+	grpcContentType       = "application/grpc"
+	grpcStatusHeader      = "Grpc-Status"
+	grpcMessageHeader     = "Grpc-Message"
+	grpcStatusTrailerKey  = http.TrailerPrefix + grpcStatusHeader
+	grpcMessageTrailerKey = http.TrailerPrefix + grpcMessageHeader
+)
+
+// grpcError is a failed call's status
+type grpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *grpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+func grpcErrorf(code int, format string, args ...interface{}) error {
+	return &grpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// grpcStream reads a call's request message and writes its response
+// messages and final status
+type grpcStream struct {
+	w    http.ResponseWriter
+	r    *http.Request
+	mu   sync.Mutex // Backup progress may be reported from several goroutines
+	sent bool
+}
+
+// recv reads the request message
+func (st *grpcStream) recv(m message) error {
+	var prefix [5]byte
+	if _, err := io.ReadFull(st.r.Body, prefix[:]); err != nil {
+		return grpcErrorf(grpcInvalidArgument, "failed to read request message: %v", err)
+	}
+	if prefix[0] != 0 {
+		return grpcErrorf(grpcUnimplemented, "compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > maxGRPCMessage {
+		return grpcErrorf(grpcResourceExhausted, "request message of %d bytes exceeds %d", size, maxGRPCMessage)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(st.r.Body, data); err != nil {
+		return grpcErrorf(grpcInvalidArgument, "failed to read request message: %v", err)
+	}
+	if err := m.unmarshal(data); err != nil {
+		return grpcErrorf(grpcInvalidArgument, "%v", err)
+	}
+	return nil
+}
+
+// send writes a response message and flushes it, so streamed events reach
+// the client as they happen
+func (st *grpcStream) send(m message) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	data := m.marshal()
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	if !st.sent {
+		st.w.WriteHeader(http.StatusOK)
+		st.sent = true
+	}
+	if _, err := st.w.Write(append(frame, data...)); err != nil {
+		return err
+	}
+	return http.NewResponseController(st.w).Flush()
+}
+
+// finish ends the call with err's status, OK when nil. A call that failed
+// before sending anything answers "trailers-only", with the status in the
+// headers.
+func (st *grpcStream) finish(err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	code, msg := grpcOK, ""
+	var rpcErr *grpcError
+	switch {
+	case err == nil:
+	case errors.As(err, &rpcErr):
+		code, msg = rpcErr.Code, rpcErr.Message
+	case st.r.Context().Err() != nil:
+		code, msg = grpcCanceled, err.Error()
+	default:
+		code, msg = grpcInternal, err.Error()
+	}
+
+	if !st.sent {
+		writeGRPCStatus(st.w, code, msg)
+		return
+	}
+	st.w.Header().Set(grpcStatusTrailerKey, strconv.Itoa(code))
+	if msg != "" {
+		st.w.Header().Set(grpcMessageTrailerKey, encodeGRPCMessage(msg))
+	}
+}
+
+// writeGRPCStatus answers a call with only a status, for calls refused
+// before they start
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", grpcContentType)
+	w.Header().Set(grpcStatusHeader, strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(grpcMessageHeader, encodeGRPCMessage(msg))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeGRPCMessage percent-encodes a status message as the gRPC spec
+// requires for the grpc-message header
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// grpcCode reads the status a gRPC call ended with from its response
+// headers or trailers, for the audit log
+func grpcCode(header http.Header) int {
+	for _, key := range []string{grpcStatusHeader, grpcStatusTrailerKey} {
+		if values := header[key]; len(values) > 0 {
+			code, _ := strconv.Atoi(values[0])
+			return code
+		}
+	}
+	return grpcOK
+}
+
+// handleGRPC serves POST /solvault.v1.Vault/{method}
+func (s *Server) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), grpcContentType) {
+		writeError(w, http.StatusUnsupportedMediaType, "gRPC calls need Content-Type %s", grpcContentType)
+		return
+	}
+	if encoding := r.Header.Get("Grpc-Encoding"); encoding != "" && encoding != "identity" {
+		writeGRPCStatus(w, grpcUnimplemented, fmt.Sprintf("compression %q is not supported", encoding))
+		return
+	}
+
+	w.Header().Set("Content-Type", grpcContentType)
+	st := &grpcStream{w: w, r: r}
+	var err error
+	switch method := r.PathValue("method"); method {
+	case "ListNFTs":
+		err = s.grpcListNFTs(st)
+	case "Verify":
+		err = s.grpcVerify(st)
+	case "Backup":
+		err = s.grpcBackup(st)
+	default:
+		err = grpcErrorf(grpcUnimplemented, "unknown method solvault.v1.Vault/%s", method)
+	}
+	if err != nil {
+		s.logf("gRPC %s failed for %s: %v", r.URL.Path, principalName(r), err)
+	}
+	st.finish(err)
+}
+
+// grpcListNFTs lists the backups in the caller's vault
+func (s *Server) grpcListNFTs(st *grpcStream) error {
+	var req ListNFTsRequest
+	if err := st.recv(&req); err != nil {
+		return err
+	}
+	if err := validateAddress("wallet", req.Wallet); err != nil {
+		return err
+	}
+
+	resp := &ListNFTsResponse{}
+	for _, entry := range s.vaultFor(st.r).Index().List() {
+		if req.Wallet != "" && entry.Wallet != req.Wallet {
+			continue
+		}
+		resp.NFTs = append(resp.NFTs, &GRPCNFT{
+			Mint:          entry.Mint,
+			Wallet:        entry.Wallet,
+			Name:          entry.Name,
+			Collection:    entry.Collection,
+			Status:        string(entry.Status),
+			UpdatedAtUnix: entry.UpdatedAt.Unix(),
+			InProgress:    entry.InProgress,
+		})
+	}
+	return st.send(resp)
+}
+
+// grpcVerify checks backups against their integrity manifests, one event
+// per NFT
+//
+// Explanation: Unlike 'solvault verify' this only reads: a backup without a
+// manifest is reported rather than given one, since creating a baseline is
+// a decision for the vault's owner, not a remote viewer.
+func (s *Server) grpcVerify(st *grpcStream) error {
+	var req VerifyRequest
+	if err := st.recv(&req); err != nil {
+		return err
+	}
+	if err := validateAddress("wallet", req.Wallet); err != nil {
+		return err
+	}
+	if err := validateAddress("mint", req.Mint); err != nil {
+		return err
+	}
+
+	vault := s.vaultFor(st.r)
+	var entries []*storage.IndexEntry
+	for _, entry := range vault.Index().List() {
+		if (req.Wallet == "" || entry.Wallet == req.Wallet) && (req.Mint == "" || entry.Mint == req.Mint) {
+			entries = append(entries, entry)
+		}
+	}
+	if req.Mint != "" && len(entries) == 0 {
+		return grpcErrorf(grpcNotFound, "mint %s is not in this vault", req.Mint)
+	}
+
+	for i, entry := range entries {
+		if err := st.r.Context().Err(); err != nil {
+			return err
+		}
+		event := &VerifyEvent{Mint: entry.Mint, Wallet: entry.Wallet, Done: int32(i + 1), Total: int32(len(entries))}
+		report, err := vault.CheckIntegrity(vault.EntryDir(entry))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			event.Problems = []string{"no integrity manifest: run 'solvault verify' on the vault to create one"}
+		case err != nil:
+			event.Problems = []string{err.Error()}
+		default:
+			event.OK = report.OK()
+			for _, file := range report.Files {
+				if file.Status == storage.IntegrityModified || file.Status == storage.IntegrityMissing {
+					event.Problems = append(event.Problems, fmt.Sprintf("%s %s", file.Status, file.Path))
+				}
+			}
+		}
+		if entry.InProgress {
+			event.OK = false
+			event.Problems = append(event.Problems, "backup interrupted: the next sync redoes it")
+		}
+		if err := st.send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcBackup syncs a wallet through Options.Backup, streaming its progress
+// and then the summary
+func (s *Server) grpcBackup(st *grpcStream) error {
+	if s.opts.Backup == nil {
+		return grpcErrorf(grpcUnimplemented, "this server cannot run backups")
+	}
+	var req BackupRequest
+	if err := st.recv(&req); err != nil {
+		return err
+	}
+	wallet, err := solanago.PublicKeyFromBase58(req.Wallet)
+	if err != nil {
+		return grpcErrorf(grpcInvalidArgument, "invalid wallet %q: %v", req.Wallet, err)
+	}
+	vault := s.vaultFor(st.r)
+	if !vault.Limits().AllowsWallet(wallet.String()) {
+		return grpcErrorf(grpcPermissionDenied, "%v: %s", storage.ErrWalletNotAllowed, wallet)
+	}
+	if !s.backupMu.TryLock() {
+		return grpcErrorf(grpcAborted, "a backup is already running")
+	}
+	defer s.backupMu.Unlock()
+
+	// A client that goes away cancels the request context, which stops the
+	// sync, so send errors can be ignored here
+	var progressMu sync.Mutex
+	var done, total int
+	opts := backup.Options{
+		DryRun: req.DryRun,
+		Progress: func(msg string) {
+			progressMu.Lock()
+			event := &Progress{Message: msg, Done: int32(done), Total: int32(total)}
+			progressMu.Unlock()
+			_ = st.send(&BackupEvent{Progress: event})
+		},
+		Advance: func(d, t int) {
+			progressMu.Lock()
+			done, total = d, t
+			progressMu.Unlock()
+			_ = st.send(&BackupEvent{Progress: &Progress{Done: int32(d), Total: int32(t)}})
+		},
+	}
+
+	s.logf("backing up %s for %s over gRPC", wallet, principalName(st.r))
+	summary, err := s.opts.Backup(st.r.Context(), vault, wallet, opts)
+	if err != nil {
+		if st.r.Context().Err() != nil {
+			return err
+		}
+		return grpcErrorf(grpcUnavailable, "backup failed: %v", err)
+	}
+
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	result := &BackupSummary{
+		Wallet:      summary.Wallet,
+		Counts:      make(map[string]int32, len(summary.Counts)),
+		Partial:     summary.Partial,
+		DryRun:      summary.DryRun,
+		MerkleRoot:  summary.MerkleRoot,
+		SummaryJSON: string(summaryJSON),
+	}
+	for kind, count := range summary.Counts {
+		result.Counts[kind] = int32(count)
+	}
+	return st.send(&BackupEvent{Summary: result})
+}
+
+// validateAddress checks an optional base58 address filter
+func validateAddress(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := solanago.PublicKeyFromBase58(value); err != nil {
+		return grpcErrorf(grpcInvalidArgument, "invalid %s %q: %v", field, value, err)
+	}
+	return nil
+}
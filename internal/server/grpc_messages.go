@@ -0,0 +1,396 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of proto/solvault/v1/vault.proto, encoded by hand.
+//
+// Explanation: The API has a handful of flat messages, so encoding them with
+// protowire keeps protoc and generated code out of the build. Field numbers
+// here must match the .proto, which is what clients generate from.
+
+// ListNFTsRequest is solvault.v1.ListNFTsRequest
+type ListNFTsRequest struct {
+	Wallet string
+}
+
+// GRPCNFT is solvault.v1.NFT
+type GRPCNFT struct {
+	Mint          string
+	Wallet        string
+	Name          string
+	Collection    string
+	Status        string
+	UpdatedAtUnix int64
+	InProgress    bool
+}
+
+// ListNFTsResponse is solvault.v1.ListNFTsResponse
+type ListNFTsResponse struct {
+	NFTs []*GRPCNFT
+}
+
+// VerifyRequest is solvault.v1.VerifyRequest
+type VerifyRequest struct {
+	Wallet string
+	Mint   string
+}
+
+// VerifyEvent is solvault.v1.VerifyEvent
+type VerifyEvent struct {
+	Mint     string
+	Wallet   string
+	OK       bool
+	Problems []string
+	Done     int32
+	Total    int32
+}
+
+// BackupRequest is solvault.v1.BackupRequest
+type BackupRequest struct {
+	Wallet string
+	DryRun bool
+}
+
+// BackupEvent is solvault.v1.BackupEvent; exactly one field is set
+type BackupEvent struct {
+	Progress *Progress
+	Summary  *BackupSummary
+}
+
+// Progress is solvault.v1.Progress
+type Progress struct {
+	Message string
+	Done    int32
+	Total   int32
+}
+
+// BackupSummary is solvault.v1.BackupSummary
+type BackupSummary struct {
+	Wallet      string
+	Counts      map[string]int32
+	Partial     bool
+	DryRun      bool
+	MerkleRoot  string
+	SummaryJSON string
+}
+
+// message is what the gRPC transport sends and receives
+type message interface {
+	marshal() []byte
+	unmarshal(data []byte) error
+}
+
+func (m *ListNFTsRequest) marshal() []byte {
+	return appendString(nil, 1, m.Wallet)
+}
+
+func (m *ListNFTsRequest) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		if num == 1 && typ == protowire.BytesType {
+			m.Wallet = string(value)
+		}
+	})
+}
+
+func (m *GRPCNFT) marshal() []byte {
+	b := appendString(nil, 1, m.Mint)
+	b = appendString(b, 2, m.Wallet)
+	b = appendString(b, 3, m.Name)
+	b = appendString(b, 4, m.Collection)
+	b = appendString(b, 5, m.Status)
+	b = appendVarint(b, 6, uint64(m.UpdatedAtUnix))
+	return appendBool(b, 7, m.InProgress)
+}
+
+func (m *GRPCNFT) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Mint = string(value)
+		case 2:
+			m.Wallet = string(value)
+		case 3:
+			m.Name = string(value)
+		case 4:
+			m.Collection = string(value)
+		case 5:
+			m.Status = string(value)
+		case 6:
+			m.UpdatedAtUnix = int64(varintValue(typ, value))
+		case 7:
+			m.InProgress = varintValue(typ, value) != 0
+		}
+	})
+}
+
+func (m *ListNFTsResponse) marshal() []byte {
+	var b []byte
+	for _, nft := range m.NFTs {
+		b = appendMessage(b, 1, nft.marshal())
+	}
+	return b
+}
+
+func (m *ListNFTsResponse) unmarshal(data []byte) error {
+	var err error
+	decodeErr := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		if num == 1 && typ == protowire.BytesType {
+			nft := &GRPCNFT{}
+			if e := nft.unmarshal(value); e != nil && err == nil {
+				err = e
+			}
+			m.NFTs = append(m.NFTs, nft)
+		}
+	})
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+func (m *VerifyRequest) marshal() []byte {
+	return appendString(appendString(nil, 1, m.Wallet), 2, m.Mint)
+}
+
+func (m *VerifyRequest) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Wallet = string(value)
+		case 2:
+			m.Mint = string(value)
+		}
+	})
+}
+
+func (m *VerifyEvent) marshal() []byte {
+	b := appendString(nil, 1, m.Mint)
+	b = appendString(b, 2, m.Wallet)
+	b = appendBool(b, 3, m.OK)
+	for _, problem := range m.Problems {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendString(b, problem)
+	}
+	b = appendVarint(b, 5, uint64(m.Done))
+	return appendVarint(b, 6, uint64(m.Total))
+}
+
+func (m *VerifyEvent) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Mint = string(value)
+		case 2:
+			m.Wallet = string(value)
+		case 3:
+			m.OK = varintValue(typ, value) != 0
+		case 4:
+			m.Problems = append(m.Problems, string(value))
+		case 5:
+			m.Done = int32(varintValue(typ, value))
+		case 6:
+			m.Total = int32(varintValue(typ, value))
+		}
+	})
+}
+
+func (m *BackupRequest) marshal() []byte {
+	return appendBool(appendString(nil, 1, m.Wallet), 2, m.DryRun)
+}
+
+func (m *BackupRequest) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Wallet = string(value)
+		case 2:
+			m.DryRun = varintValue(typ, value) != 0
+		}
+	})
+}
+
+func (m *BackupEvent) marshal() []byte {
+	switch {
+	case m.Progress != nil:
+		return appendMessage(nil, 1, m.Progress.marshal())
+	case m.Summary != nil:
+		return appendMessage(nil, 2, m.Summary.marshal())
+	}
+	return nil
+}
+
+func (m *BackupEvent) unmarshal(data []byte) error {
+	var err error
+	decodeErr := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Progress, m.Summary = &Progress{}, nil
+			err = m.Progress.unmarshal(value)
+		case 2:
+			m.Progress, m.Summary = nil, &BackupSummary{}
+			err = m.Summary.unmarshal(value)
+		}
+	})
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+func (m *Progress) marshal() []byte {
+	b := appendString(nil, 1, m.Message)
+	b = appendVarint(b, 2, uint64(m.Done))
+	return appendVarint(b, 3, uint64(m.Total))
+}
+
+func (m *Progress) unmarshal(data []byte) error {
+	return decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Message = string(value)
+		case 2:
+			m.Done = int32(varintValue(typ, value))
+		case 3:
+			m.Total = int32(varintValue(typ, value))
+		}
+	})
+}
+
+func (m *BackupSummary) marshal() []byte {
+	b := appendString(nil, 1, m.Wallet)
+
+	// A map is a repeated entry message with the key as field 1 and the
+	// value as field 2, written in key order so the encoding is stable
+	keys := make([]string, 0, len(m.Counts))
+	for key := range m.Counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := appendString(nil, 1, key)
+		entry = appendVarint(entry, 2, uint64(m.Counts[key]))
+		b = appendMessage(b, 2, entry)
+	}
+
+	b = appendBool(b, 3, m.Partial)
+	b = appendBool(b, 4, m.DryRun)
+	b = appendString(b, 5, m.MerkleRoot)
+	return appendString(b, 6, m.SummaryJSON)
+}
+
+func (m *BackupSummary) unmarshal(data []byte) error {
+	var err error
+	decodeErr := decodeFields(data, func(num protowire.Number, typ protowire.Type, value []byte) {
+		switch num {
+		case 1:
+			m.Wallet = string(value)
+		case 2:
+			var key string
+			var count int32
+			e := decodeFields(value, func(num protowire.Number, typ protowire.Type, value []byte) {
+				switch num {
+				case 1:
+					key = string(value)
+				case 2:
+					count = int32(varintValue(typ, value))
+				}
+			})
+			if e != nil && err == nil {
+				err = e
+			}
+			if m.Counts == nil {
+				m.Counts = make(map[string]int32)
+			}
+			m.Counts[key] = count
+		case 3:
+			m.Partial = varintValue(typ, value) != 0
+		case 4:
+			m.DryRun = varintValue(typ, value) != 0
+		case 5:
+			m.MerkleRoot = string(value)
+		case 6:
+			m.SummaryJSON = string(value)
+		}
+	})
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+// proto3 leaves fields at their zero value out of the encoding
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendBool(b []byte, num protowire.Number, value bool) []byte {
+	if !value {
+		return b
+	}
+	return appendVarint(b, num, 1)
+}
+
+func appendMessage(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+// decodeFields calls field for every field of an encoded message, with
+// the raw varint or the length-delimited bytes as value. Fields of other
+// wire types are skipped, as unknown fields must be.
+func decodeFields(data []byte, field func(num protowire.Number, typ protowire.Type, value []byte)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid message: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.VarintType:
+			_, n = protowire.ConsumeVarint(data)
+			value = data[:max(n, 0)]
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid field %d: %w", num, protowire.ParseError(n))
+		}
+		if typ == protowire.VarintType || typ == protowire.BytesType {
+			field(num, typ, value)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// varintValue decodes the raw varint decodeFields passes, or 0 for a field
+// sent with another wire type
+func varintValue(typ protowire.Type, value []byte) uint64 {
+	if typ != protowire.VarintType {
+		return 0
+	}
+	v, _ := protowire.ConsumeVarint(value)
+	return v
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// grpcResult is what a gRPC call answered: its response messages and status
+type grpcResult struct {
+	messages [][]byte
+	code     int
+	message  string
+}
+
+// callGRPC makes a gRPC call over cleartext HTTP/2, as a generated client would
+func callGRPC(t *testing.T, url, method, token string, req message) grpcResult {
+	t.Helper()
+
+	data := req.marshal()
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	httpReq, _ := http.NewRequest(http.MethodPost, url+grpcPrefix+method, bytes.NewReader(append(frame, data...)))
+	httpReq.Header.Set("Content-Type", "application/grpc+proto")
+	httpReq.Header.Set("TE", "trailers")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	protocols := &http.Protocols{}
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Failed to call %s: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected an HTTP/2 200 response, got %s %d", resp.Proto, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read %s response: %v", method, err)
+	}
+
+	var result grpcResult
+	for len(body) >= 5 {
+		size := binary.BigEndian.Uint32(body[1:5])
+		result.messages = append(result.messages, body[5:5+size])
+		body = body[5+size:]
+	}
+	status, msg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, msg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if result.code, err = strconv.Atoi(status); err != nil {
+		t.Fatalf("Expected a grpc-status, got %q", status)
+	}
+	result.message = msg
+	return result
+}
+
+func TestGRPC_ListNFTs(t *testing.T) {
+	ts, mint := newTestServer(t, Options{})
+
+	result := callGRPC(t, ts.URL, "ListNFTs", "", &ListNFTsRequest{})
+	if result.code != grpcOK || len(result.messages) != 1 {
+		t.Fatalf("Expected one response and OK, got %+v", result)
+	}
+	var resp ListNFTsResponse
+	if err := resp.unmarshal(result.messages[0]); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.NFTs) != 1 || resp.NFTs[0].Mint != mint.String() || resp.NFTs[0].Name != "Test NFT" {
+		t.Errorf("Expected the test NFT, got %+v", resp.NFTs)
+	}
+
+	other := solanago.NewWallet().PublicKey().String()
+	result = callGRPC(t, ts.URL, "ListNFTs", "", &ListNFTsRequest{Wallet: other})
+	resp = ListNFTsResponse{}
+	if err := resp.unmarshal(result.messages[0]); err != nil || len(resp.NFTs) != 0 {
+		t.Errorf("Expected no NFTs for another wallet, got %+v (err %v)", resp.NFTs, err)
+	}
+}
+
+func TestGRPC_Verify(t *testing.T) {
+	ts, mint := newTestServer(t, Options{})
+
+	tests := []struct {
+		name   string
+		req    *VerifyRequest
+		code   int
+		events int
+	}{
+		{"every NFT", &VerifyRequest{}, grpcOK, 1},
+		{"one mint", &VerifyRequest{Mint: mint.String()}, grpcOK, 1},
+		{"unknown mint", &VerifyRequest{Mint: solanago.NewWallet().PublicKey().String()}, grpcNotFound, 0},
+		{"invalid wallet", &VerifyRequest{Wallet: "not-a-wallet"}, grpcInvalidArgument, 0},
+	}
+	for _, test := range tests {
+		result := callGRPC(t, ts.URL, "Verify", "", test.req)
+		if result.code != test.code || len(result.messages) != test.events {
+			t.Errorf("%s: expected status %d with %d event(s), got %+v", test.name, test.code, test.events, result)
+			continue
+		}
+		for _, data := range result.messages {
+			var event VerifyEvent
+			if err := event.unmarshal(data); err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+			if event.Mint != mint.String() || event.Done != 1 || event.Total != 1 {
+				t.Errorf("%s: expected the test NFT's event, got %+v", test.name, event)
+			}
+			if !event.OK || len(event.Problems) != 0 {
+				t.Errorf("%s: expected the untouched backup verified, got %+v", test.name, event)
+			}
+		}
+	}
+}
+
+func TestGRPC_Backup(t *testing.T) {
+	auth := &Auth{Tokens: []Token{
+		{Name: "dashboard", Role: RoleViewer, Secret: "viewer-secret"},
+		{Name: "ci", Role: RoleOperator, Secret: "operator-secret"},
+	}}
+	wallet := solanago.NewWallet().PublicKey()
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	srv := New(vault, Options{
+		Auth: auth,
+		Backup: func(ctx context.Context, vault *storage.FileStorage, w solanago.PublicKey, opts backup.Options) (*backup.Summary, error) {
+			opts.Progress("🔍 Checking 2 NFT(s)")
+			opts.Advance(1, 2)
+			opts.Advance(2, 2)
+			return &backup.Summary{Wallet: w.String(), DryRun: opts.DryRun, Counts: map[string]int{"added": 2}}, nil
+		},
+	})
+	ts := httptest.NewUnstartedServer(srv.Handler())
+	ts.Config.Protocols = protocols()
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"without token", "", grpcUnauthenticated},
+		{"viewer", "viewer-secret", grpcPermissionDenied},
+		{"operator", "operator-secret", grpcOK},
+	}
+	for _, test := range tests {
+		result := callGRPC(t, ts.URL, "Backup", test.token, &BackupRequest{Wallet: wallet.String(), DryRun: true})
+		if result.code != test.code {
+			t.Errorf("%s: expected status %d, got %d (%s)", test.name, test.code, result.code, result.message)
+			continue
+		}
+		if test.code != grpcOK {
+			continue
+		}
+
+		if len(result.messages) != 4 {
+			t.Fatalf("Expected three progress events and the summary, got %d message(s)", len(result.messages))
+		}
+		var last BackupEvent
+		for i, data := range result.messages {
+			var event BackupEvent
+			if err := event.unmarshal(data); err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+			if (event.Progress != nil) != (i < 3) {
+				t.Errorf("Expected progress before the summary, got %+v at %d", event, i)
+			}
+			last = event
+		}
+		if last.Summary == nil || last.Summary.Wallet != wallet.String() || !last.Summary.DryRun ||
+			last.Summary.Counts["added"] != 2 || !strings.Contains(last.Summary.SummaryJSON, `"added":2`) {
+			t.Errorf("Expected the dry-run summary last, got %+v", last.Summary)
+		}
+	}
+
+	audit, err := os.ReadFile(filepath.Join(vault.BaseDir(), AuditFile))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(audit)), "\n"); len(lines) != 3 ||
+		!strings.Contains(lines[1], `"grpc_code":7`) || strings.Contains(lines[2], "grpc_code") {
+		t.Errorf("Expected the three Backup calls audited with their status, got:\n%s", audit)
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// mediaMaxAge is how long clients may reuse media without revalidating.
+// Media bytes never change for a given ETag, so revalidation is cheap.
+const mediaMaxAge = 3600
+
+// mediaEntry describes one media file in the listing endpoint
+type mediaEntry struct {
+	Filename    string           `json:"filename"`
+	ContentType string           `json:"content_type,omitempty"`
+	Size        int64            `json:"size"`
+	Checksum    string           `json:"checksum,omitempty"`
+	URL         string           `json:"url"`
+	Thumbnails  []thumbnailEntry `json:"thumbnails,omitempty"`
+}
+
+// thumbnailEntry describes one thumbnail of a media file
+type thumbnailEntry struct {
+	Size   int    `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// handleListMedia returns the media manifest for a mint
+func (s *Server) handleListMedia(w http.ResponseWriter, r *http.Request) {
+	mint := r.PathValue("mint")
+	nftDir, files, ok := s.mediaManifest(r, mint)
+	if !ok {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", mint)
+		return
+	}
+
+	entries := []mediaEntry{}
+	for _, file := range files {
+		entry := mediaEntry{
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			Checksum:    file.Checksum,
+			URL:         fmt.Sprintf("/api/v1/nfts/%s/media/%s", mint, file.Filename),
+		}
+		// On disk, an encrypted or compressed file's size is not the media's
+		if vault := s.vaultFor(r); !vault.Encrypted() && !vault.Compressed() {
+			if info, err := os.Stat(filepath.Join(nftDir, "media", file.Filename)); err == nil {
+				entry.Size = info.Size()
+			}
+		}
+		for _, thumb := range file.Thumbnails {
+			entry.Thumbnails = append(entry.Thumbnails, thumbnailEntry{
+				Size:   thumb.Size,
+				Width:  thumb.Width,
+				Height: thumb.Height,
+				URL:    fmt.Sprintf("/api/v1/nfts/%s/thumbnails/%s", mint, path.Base(thumb.Filename)),
+			})
+		}
+		entries = append(entries, entry)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"mint": mint, "media": entries})
+}
+
+// handleGetMedia serves one media file with Range, ETag and Last-Modified
+// support so videos can seek and galleries can revalidate instead of
+// re-downloading
+func (s *Server) handleGetMedia(w http.ResponseWriter, r *http.Request) {
+	mint := r.PathValue("mint")
+	name := r.PathValue("file")
+
+	// Only plain file names inside media/ may be served
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		writeError(w, http.StatusBadRequest, "invalid media file name")
+		return
+	}
+
+	nftDir, files, ok := s.mediaManifest(r, mint)
+	if !ok {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", mint)
+		return
+	}
+
+	// Prefer the manifest's checksum and content type; fall back to
+	// size/mtime and content sniffing for files missing from it
+	var checksum, contentType string
+	for _, media := range files {
+		if media.Filename == name {
+			checksum, contentType = media.Checksum, media.ContentType
+			break
+		}
+	}
+	serveFile(w, r, s.vaultFor(r), filepath.Join(nftDir, "media", name), name, checksum, contentType)
+}
+
+// handleGetThumbnail serves one thumbnail the way handleGetMedia serves
+// media
+func (s *Server) handleGetThumbnail(w http.ResponseWriter, r *http.Request) {
+	mint := r.PathValue("mint")
+	name := r.PathValue("file")
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		writeError(w, http.StatusBadRequest, "invalid thumbnail file name")
+		return
+	}
+
+	nftDir, files, ok := s.mediaManifest(r, mint)
+	if !ok {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", mint)
+		return
+	}
+
+	var checksum string
+	for _, media := range files {
+		for _, thumb := range media.Thumbnails {
+			if thumb.Filename == path.Join(thumbnail.Dir, name) {
+				checksum = thumb.Checksum
+			}
+		}
+	}
+	serveFile(w, r, s.vaultFor(r), filepath.Join(nftDir, "media", thumbnail.Dir, name), name, checksum, "image/jpeg")
+}
+
+// serveFile sends a file from the vault, tagged with its checksum when
+// known
+//
+// Explanation: Files in an encrypted or compressed vault are decoded into
+// memory first, so Range requests address the media itself rather than
+// its stored form.
+func serveFile(w http.ResponseWriter, r *http.Request, vault *storage.FileStorage, filePath, name, checksum, contentType string) {
+	info, err := os.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() {
+		writeError(w, http.StatusNotFound, "media file %s not found", name)
+		return
+	}
+	data, err := vault.ReadFile(filePath)
+	if errors.Is(err, crypt.ErrLocked) {
+		writeError(w, http.StatusServiceUnavailable, "vault is encrypted and no key is configured")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to read media file %s", name)
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%x-%x"`, len(data), info.ModTime().UnixNano())
+	if hash := proof.NormalizeHash(checksum); hash != "" {
+		etag = `"` + hash + `"`
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", mediaMaxAge))
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(data))
+}
+
+// mediaManifest locates a mint's backup and the media files recorded for it
+func (s *Server) mediaManifest(r *http.Request, mintAddr string) (string, []*fetcher.MediaFile, bool) {
+	vault := s.vaultFor(r)
+	entries := vault.Index().ByMint(mintAddr)
+	if len(entries) == 0 {
+		return "", nil, false
+	}
+
+	wallet, err := solanago.PublicKeyFromBase58(entries[0].Wallet)
+	if err != nil {
+		return "", nil, false
+	}
+	mint, err := solanago.PublicKeyFromBase58(mintAddr)
+	if err != nil {
+		return "", nil, false
+	}
+
+	var files []*fetcher.MediaFile
+	if stored, err := vault.GetNFT(r.Context(), wallet, mint); err == nil && stored.NFTInfo != nil {
+		files = stored.NFTInfo.MediaFiles
+	}
+	return vault.NFTDir(wallet, mint), files, true
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// newMediaServer returns a server whose vault holds one NFT with a video on disk
+func newMediaServer(t *testing.T) (*httptest.Server, solanago.PublicKey, []byte) {
+	t.Helper()
+	return newConvertedMediaServer(t, nil)
+}
+
+// newConvertedMediaServer is newMediaServer with convert applied to the
+// vault once its files are written, e.g. to encrypt them
+func newConvertedMediaServer(t *testing.T, convert func(*storage.FileStorage)) (*httptest.Server, solanago.PublicKey, []byte) {
+	t.Helper()
+
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	owner := solanago.NewWallet().PublicKey()
+	mint := solanago.NewWallet().PublicKey()
+	video := []byte("0123456789abcdefghij")
+	nft := &fetcher.NFTInfo{
+		MintAddress: mint,
+		Owner:       owner,
+		FetchedAt:   time.Now(),
+		MediaFiles: []*fetcher.MediaFile{{
+			Filename:    "clip.bin",
+			ContentType: "video/mp4",
+			Checksum:    "sha256:ABC123",
+			Size:        int64(len(video)),
+			Thumbnails:  []*thumbnail.Thumbnail{{Size: 128, Width: 128, Height: 72, Filename: "thumbnails/clip.bin-128.jpg", Checksum: "DEF456"}},
+		}},
+	}
+	if err := vault.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	mediaDir := filepath.Join(vault.NFTDir(owner, mint), "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatalf("Failed to create media dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "clip.bin"), video, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(mediaDir, thumbnail.Dir), 0755); err != nil {
+		t.Fatalf("Failed to create thumbnail dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, thumbnail.Dir, "clip.bin-128.jpg"), []byte("jpeg"), 0644); err != nil {
+		t.Fatalf("Failed to write thumbnail: %v", err)
+	}
+
+	if convert != nil {
+		convert(vault)
+	}
+
+	ts := httptest.NewServer(New(vault, Options{}).Handler())
+	t.Cleanup(ts.Close)
+	return ts, mint, video
+}
+
+func getMedia(t *testing.T, url string, headers map[string]string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to get media: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body
+}
+
+func TestGetMedia(t *testing.T) {
+	ts, mint, video := newMediaServer(t)
+	url := ts.URL + "/api/v1/nfts/" + mint.String() + "/media/clip.bin"
+
+	resp, body := getMedia(t, url, nil)
+	if resp.StatusCode != http.StatusOK || string(body) != string(video) {
+		t.Fatalf("Full GET: status %d, body %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("Content-Type = %q, want video/mp4 from the manifest", got)
+	}
+	if got := resp.Header.Get("ETag"); got != `"abc123"` {
+		t.Errorf("ETag = %q, want the normalized checksum", got)
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.Header.Get("Last-Modified") == "" {
+		t.Errorf("Missing range/caching headers: %v", resp.Header)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{"range", map[string]string{"Range": "bytes=5-9"}, http.StatusPartialContent, "56789"},
+		{"suffix range", map[string]string{"Range": "bytes=-3"}, http.StatusPartialContent, "hij"},
+		{"unsatisfiable range", map[string]string{"Range": "bytes=100-200"}, http.StatusRequestedRangeNotSatisfiable, ""},
+		{"etag match", map[string]string{"If-None-Match": `"abc123"`}, http.StatusNotModified, ""},
+		{"etag mismatch", map[string]string{"If-None-Match": `"other"`}, http.StatusOK, string(video)},
+		{"if-modified-since", map[string]string{"If-Modified-Since": resp.Header.Get("Last-Modified")}, http.StatusNotModified, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := getMedia(t, url, tt.headers)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("Body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestGetMedia_Encrypted(t *testing.T) {
+	ts, mint, video := newConvertedMediaServer(t, func(vault *storage.FileStorage) {
+		params, c, err := crypt.FromPassphrase("correct horse")
+		if err != nil {
+			t.Fatalf("Failed to set up encryption: %v", err)
+		}
+		if _, err := vault.EnableEncryption(params, c); err != nil {
+			t.Fatalf("Failed to enable encryption: %v", err)
+		}
+	})
+	url := ts.URL + "/api/v1/nfts/" + mint.String() + "/media/clip.bin"
+
+	// Media is served as backed up, not as sealed on disk
+	if resp, body := getMedia(t, url, nil); resp.StatusCode != http.StatusOK || string(body) != string(video) {
+		t.Fatalf("Full GET: status %d, body %q", resp.StatusCode, body)
+	}
+	if resp, body := getMedia(t, url, map[string]string{"Range": "bytes=5-9"}); resp.StatusCode != http.StatusPartialContent || string(body) != "56789" {
+		t.Errorf("Range GET: status %d, body %q", resp.StatusCode, body)
+	}
+	thumb := ts.URL + "/api/v1/nfts/" + mint.String() + "/thumbnails/clip.bin-128.jpg"
+	if resp, body := getMedia(t, thumb, nil); resp.StatusCode != http.StatusOK || string(body) != "jpeg" {
+		t.Errorf("Thumbnail GET: status %d, body %q", resp.StatusCode, body)
+	}
+}
+
+func TestGetMedia_Compressed(t *testing.T) {
+	ts, mint, video := newConvertedMediaServer(t, func(vault *storage.FileStorage) {
+		if _, err := vault.EnableCompression(); err != nil {
+			t.Fatalf("Failed to enable compression: %v", err)
+		}
+		stored, _ := filepath.Glob(filepath.Join(vault.BaseDir(), "wallets", "*", "nfts", "*", "media", "clip.bin"))
+		if len(stored) != 1 {
+			t.Fatalf("Found %d stored copies of the video, want 1", len(stored))
+		}
+		if data, err := os.ReadFile(stored[0]); err != nil || !storage.IsCompressed(data) {
+			t.Fatalf("Video was not compressed on disk (%v)", err)
+		}
+	})
+	url := ts.URL + "/api/v1/nfts/" + mint.String() + "/media/clip.bin"
+
+	if resp, body := getMedia(t, url, nil); resp.StatusCode != http.StatusOK || string(body) != string(video) {
+		t.Fatalf("Full GET: status %d, body %q", resp.StatusCode, body)
+	}
+	if resp, body := getMedia(t, url, map[string]string{"Range": "bytes=-3"}); resp.StatusCode != http.StatusPartialContent || string(body) != "hij" {
+		t.Errorf("Range GET: status %d, body %q", resp.StatusCode, body)
+	}
+
+	// The listing gives the media's size, not the compressed file's
+	resp, body := getMedia(t, ts.URL+"/api/v1/nfts/"+mint.String()+"/media", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"size":20`) {
+		t.Errorf("Listing: status %d, body %s", resp.StatusCode, body)
+	}
+}
+
+func TestGetMedia_NotFound(t *testing.T) {
+	ts, mint, _ := newMediaServer(t)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/api/v1/nfts/" + mint.String() + "/media/missing.png", http.StatusNotFound},
+		{"/api/v1/nfts/" + mint.String() + "/media/..%2Fnft_data.json", http.StatusBadRequest},
+		{"/api/v1/nfts/" + solanago.NewWallet().PublicKey().String() + "/media/clip.bin", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, _ := getMedia(t, ts.URL+tt.path, nil)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestListMedia(t *testing.T) {
+	ts, mint, video := newMediaServer(t)
+
+	resp, body := getMedia(t, ts.URL+"/api/v1/nfts/"+mint.String()+"/media", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Status = %d, body %s", resp.StatusCode, body)
+	}
+	want := `"url":"/api/v1/nfts/` + mint.String() + `/media/clip.bin"`
+	if !strings.Contains(string(body), want) || !strings.Contains(string(body), `"size":20`) {
+		t.Errorf("Unexpected listing %s (video %d bytes)", body, len(video))
+	}
+	if want := `"url":"/api/v1/nfts/` + mint.String() + `/thumbnails/clip.bin-128.jpg"`; !strings.Contains(string(body), want) {
+		t.Errorf("Listing %s lacks the thumbnail", body)
+	}
+}
+
+func TestGetThumbnail(t *testing.T) {
+	ts, mint, _ := newMediaServer(t)
+	base := ts.URL + "/api/v1/nfts/" + mint.String() + "/thumbnails/"
+
+	resp, body := getMedia(t, base+"clip.bin-128.jpg", nil)
+	if resp.StatusCode != http.StatusOK || string(body) != "jpeg" {
+		t.Fatalf("Status = %d, body %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "image/jpeg" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := resp.Header.Get("ETag"); !strings.Contains(strings.ToLower(got), "def456") {
+		t.Errorf("ETag = %q, want the recorded checksum", got)
+	}
+
+	for _, name := range []string{"missing.jpg", "..%2Fclip.bin", ".hidden"} {
+		if resp, _ := getMedia(t, base+name, nil); resp.StatusCode == http.StatusOK {
+			t.Errorf("Served %s", name)
+		}
+	}
+}
+
+func TestVaultFile(t *testing.T) {
+	ts, _ := newTestServer(t, Options{})
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"index.json", http.StatusOK},
+		{"tenants.json", http.StatusBadRequest},
+		{"audit.jsonl", http.StatusBadRequest},
+		{"wallets/W/nfts/.staging/nft_data.json", http.StatusBadRequest},
+		{"wallets/W/nfts/missing/nft_data.json", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		resp, err := http.Get(ts.URL + "/api/v1/vault/files/" + test.path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", test.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("GET %s: status %d, want %d", test.path, resp.StatusCode, test.status)
+		}
+	}
+}
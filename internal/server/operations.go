@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// backupRequest is the body of POST /api/v1/backups
+type backupRequest struct {
+	Wallet string `json:"wallet"`
+}
+
+// handleBackup syncs a wallet into the caller's vault through Options.Backup
+// and answers with the sync summary once it finishes
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Backup == nil {
+		writeError(w, http.StatusNotImplemented, "this server cannot run backups")
+		return
+	}
+
+	var body backupRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAttestationBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid backup request: %v", err)
+		return
+	}
+	wallet, err := solanago.PublicKeyFromBase58(body.Wallet)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid wallet %q: %v", body.Wallet, err)
+		return
+	}
+	vault := s.vaultFor(r)
+	if !vault.Limits().AllowsWallet(wallet.String()) {
+		writeError(w, http.StatusForbidden, "%v: %s", storage.ErrWalletNotAllowed, wallet)
+		return
+	}
+
+	// Explanation: Backups download media for minutes at a time and share
+	// the RPC budget, so a second request is turned away rather than queued
+	// behind the first.
+	if !s.backupMu.TryLock() {
+		writeError(w, http.StatusConflict, "a backup is already running")
+		return
+	}
+	defer s.backupMu.Unlock()
+
+	s.logf("backing up %s for %s", wallet, principalName(r))
+	summary, err := s.opts.Backup(r.Context(), vault, wallet, backup.Options{})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "backup failed: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// handleDeleteNFT removes every backup of a mint from the caller's vault,
+// or only one wallet's with ?wallet=
+func (s *Server) handleDeleteNFT(w http.ResponseWriter, r *http.Request) {
+	mintAddr := r.PathValue("mint")
+	mint, err := solanago.PublicKeyFromBase58(mintAddr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid mint %q: %v", mintAddr, err)
+		return
+	}
+
+	vault := s.vaultFor(r)
+	var primaries, linked []*storage.IndexEntry
+	for _, entry := range vault.Index().ByMint(mintAddr) {
+		if wallet := r.URL.Query().Get("wallet"); wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		if entry.PrimaryWallet != "" {
+			linked = append(linked, entry)
+		} else {
+			primaries = append(primaries, entry)
+		}
+	}
+	if len(primaries)+len(linked) == 0 {
+		writeError(w, http.StatusNotFound, "mint %s is not in this vault", mintAddr)
+		return
+	}
+
+	// Links go first, since a backup another wallet links to cannot be deleted
+	deleted := []string{}
+	for _, entry := range append(linked, primaries...) {
+		wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err == nil {
+			err = vault.DeleteNFT(r.Context(), wallet, mint)
+		}
+		if err != nil {
+			writeError(w, http.StatusConflict, "failed to delete %s from %s: %v", mintAddr, entry.Wallet, err)
+			return
+		}
+		deleted = append(deleted, entry.Wallet)
+	}
+
+	s.logf("deleted %s from %d wallet(s) for %s", mintAddr, len(deleted), principalName(r))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"mint": mintAddr, "wallets": deleted})
+}
+
+// principalName names the caller of a request, for the server log
+func principalName(r *http.Request) string {
+	if principal, ok := r.Context().Value(principalKey{}).(*Principal); ok {
+		return principal.Name
+	}
+	return anonymous.Name
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/telemetry"
+	solanago "github.com/gagliardetto/solana-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxAttestationBytes caps the size of a posted attestation body
+const maxAttestationBytes = 64 << 10
+
+// Options controls what the server accepts
+type Options struct {
+	// TrustedVerifiers restricts attestations to these signers; empty accepts any valid signature
+	TrustedVerifiers []solanago.PublicKey
+
+	// MaxClockSkew rejects attestations timestamped further than this from now
+	MaxClockSkew time.Duration
+
+	// Logf receives one line per handled request (optional)
+	Logf func(format string, args ...interface{})
+
+	// Tenants switches the server to multi-tenant mode: every /api/v1
+	// request needs a tenant's API key and is served from that tenant's
+	// vault, never the shared one
+	Tenants *storage.Tenants
+
+	// Auth requires a static token or JWT on every /api/v1 request, with
+	// the role it grants; nil leaves the API open (ignored with Tenants)
+	Auth *Auth
+
+	// Backup syncs a wallet into a vault for POST /api/v1/backups and the
+	// gRPC Backup call; nil refuses both. opts carries the request's dry-run
+	// and progress settings for the hook to add its own to.
+	Backup func(ctx context.Context, vault *storage.FileStorage, wallet solanago.PublicKey, opts backup.Options) (*backup.Summary, error)
+}
+
+// Server exposes a vault over HTTP so other SolVault installations can
+// post attestations about the NFTs it holds
+type Server struct {
+	vault *storage.FileStorage
+	opts  Options
+	mux   *http.ServeMux
+
+	// chainMu serializes read-modify-write cycles on proof_chain.json files
+	chainMu sync.Mutex
+
+	// backupMu allows one backup at a time; auditMu serializes audit log writes
+	backupMu sync.Mutex
+	auditMu  sync.Mutex
+
+	// tenantVaults caches the open vault of each tenant seen, by tenant ID
+	tenantMu     sync.Mutex
+	tenantVaults map[string]*storage.FileStorage
+}
+
+// New creates a server backed by vault
+func New(vault *storage.FileStorage, opts Options) *Server {
+	if opts.MaxClockSkew == 0 {
+		opts.MaxClockSkew = 24 * time.Hour
+	}
+
+	s := &Server{
+		vault:        vault,
+		opts:         opts,
+		mux:          http.NewServeMux(),
+		tenantVaults: make(map[string]*storage.FileStorage),
+	}
+	s.routes()
+	return s
+}
+
+// Handler returns the HTTP handler for all routes
+func (s *Server) Handler() http.Handler {
+	return s.trace(s.authorize(s.mux))
+}
+
+// trace runs each request in a server span named after its route
+func (s *Server) trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The route, not the path, so mints and wallets stay out of span names
+		_, pattern := s.mux.Handler(r)
+		if pattern == "" {
+			pattern = r.Method
+		}
+		ctx, span := telemetry.StartRequest(r, pattern,
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		var err error
+		if strings.HasPrefix(r.URL.Path, grpcPrefix) {
+			code := grpcCode(recorder.Header())
+			span.SetAttributes(attribute.Int("rpc.grpc.status_code", code))
+			if code != grpcOK {
+				err = fmt.Errorf("gRPC status %d", code)
+			}
+		} else if recorder.status >= http.StatusInternalServerError {
+			err = fmt.Errorf("HTTP %d", recorder.status)
+		}
+		telemetry.End(span, err)
+	})
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		Protocols:         protocols(),
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down server: %w", err)
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// protocols are the HTTP versions the server speaks: HTTP/1.1 for REST,
+// and cleartext HTTP/2 as well, which gRPC clients need
+func protocols() *http.Protocols {
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return protocols
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("POST /api/v1/attestations", s.handlePostAttestation)
+	s.mux.HandleFunc("GET /api/v1/attestations/{mint}", s.handleGetAttestations)
+	s.mux.HandleFunc("GET /api/v1/nfts/{mint}/media", s.handleListMedia)
+	s.mux.HandleFunc("GET /api/v1/nfts/{mint}/media/{file}", s.handleGetMedia)
+	s.mux.HandleFunc("GET /api/v1/nfts/{mint}/thumbnails/{file}", s.handleGetThumbnail)
+	s.mux.HandleFunc("DELETE /api/v1/nfts/{mint}", s.handleDeleteNFT)
+	s.mux.HandleFunc("POST /api/v1/backups", s.handleBackup)
+	s.mux.HandleFunc("GET /api/v1/usage", s.handleUsage)
+	s.mux.HandleFunc("GET /api/v1/vault/files/{path...}", s.handleVaultFile)
+	s.mux.HandleFunc("POST "+grpcPrefix+"{method}", s.handleGRPC)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.opts.Logf != nil {
+		s.opts.Logf(format, args...)
+	}
+}
+
+// errorResponse is the JSON body of every non-2xx response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, errorResponse{Error: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// newTestServer returns a server whose vault holds one NFT with a known media checksum
+func newTestServer(t *testing.T, opts Options) (*httptest.Server, solanago.PublicKey) {
+	t.Helper()
+
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	mint := solanago.NewWallet().PublicKey()
+	nft := &fetcher.NFTInfo{
+		MintAddress: mint,
+		Owner:       solanago.NewWallet().PublicKey(),
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: "Test NFT"},
+		MediaFiles:  []*fetcher.MediaFile{{Filename: "image.png", Checksum: "abcdef"}},
+	}
+	if err := vault.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(New(vault, opts).Handler())
+	ts.Config.Protocols = protocols()
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts, mint
+}
+
+func postAttestation(t *testing.T, url string, att proof.Attestation) *http.Response {
+	t.Helper()
+
+	body, _ := json.Marshal(att)
+	resp, err := http.Post(url+"/api/v1/attestations", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to post attestation: %v", err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestPostAttestation(t *testing.T) {
+	ts, mint := newTestServer(t, Options{})
+	key := solanago.NewWallet().PrivateKey
+
+	att := proof.Attestation{
+		Mint:      mint.String(),
+		MediaHash: "sha256:abcdef",
+		ProofHash: "sha256:0123",
+		Timestamp: time.Now(),
+	}
+	if err := att.Sign(key); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		att    proof.Attestation
+		status int
+	}{
+		{"valid", att, http.StatusCreated},
+		{"duplicate", att, http.StatusConflict},
+		{"bad signature", func() proof.Attestation { a := att; a.ProofHash = "sha256:ffff"; return a }(), http.StatusUnauthorized},
+		{"unknown mint", func() proof.Attestation {
+			a := att
+			a.Mint = solanago.NewWallet().PublicKey().String()
+			a.Sign(key)
+			return a
+		}(), http.StatusNotFound},
+		{"stale", func() proof.Attestation {
+			a := att
+			a.Timestamp = time.Now().Add(-48 * time.Hour)
+			a.Sign(key)
+			return a
+		}(), http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		resp := postAttestation(t, ts.URL, test.att)
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/attestations/" + mint.String())
+	if err != nil {
+		t.Fatalf("Failed to get attestations: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var chain proof.Chain
+	if err := json.NewDecoder(resp.Body).Decode(&chain); err != nil {
+		t.Fatalf("Failed to decode chain: %v", err)
+	}
+	if len(chain.Entries) != 1 || !chain.Entries[0].MatchesLocal {
+		t.Fatalf("Expected one entry matching local media, got %+v", chain.Entries)
+	}
+}
+
+func TestPostAttestation_UntrustedVerifier(t *testing.T) {
+	trusted := solanago.NewWallet().PublicKey()
+	ts, mint := newTestServer(t, Options{TrustedVerifiers: []solanago.PublicKey{trusted}})
+
+	att := proof.Attestation{
+		Mint:      mint.String(),
+		MediaHash: "sha256:abcdef",
+		ProofHash: "sha256:0123",
+		Timestamp: time.Now(),
+	}
+	if err := att.Sign(solanago.NewWallet().PrivateKey); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	if resp := postAttestation(t, ts.URL, att); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for untrusted verifier, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// vaultFor returns the vault a request reads and writes: its tenant's in
+// multi-tenant mode, the server's otherwise
+//
+// Explanation: Handlers only ever reach storage through here, so a tenant
+// cannot name a mint, media file or proof chain outside their own vault.
+func (s *Server) vaultFor(r *http.Request) *storage.FileStorage {
+	if vault, ok := r.Context().Value(vaultKey{}).(*storage.FileStorage); ok {
+		return vault
+	}
+	return s.vault
+}
+
+// tenantVault opens a tenant's vault on first use and keeps it open
+func (s *Server) tenantVault(tenant *storage.Tenant) (*storage.FileStorage, error) {
+	s.tenantMu.Lock()
+	defer s.tenantMu.Unlock()
+
+	if vault, ok := s.tenantVaults[tenant.ID]; ok {
+		return vault, nil
+	}
+	vault, err := storage.OpenTenantVault(s.vault.BaseDir(), tenant)
+	if err != nil {
+		return nil, err
+	}
+	// Tenants share the disk, so they keep the served vault's free space
+	limits := vault.Limits()
+	limits.MinFreeBytes = s.vault.Limits().MinFreeBytes
+	vault.SetLimits(limits)
+	s.tenantVaults[tenant.ID] = vault
+	return vault, nil
+}
+
+// handleUsage reports how much of its quota the caller's vault uses
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.vaultFor(r).Usage())
+}
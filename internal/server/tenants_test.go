@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/proof"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestTenants(t *testing.T) {
+	baseDir := t.TempDir()
+	shared, err := storage.NewFileStorage(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	tenants, err := storage.LoadTenants(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to load tenants: %v", err)
+	}
+	alice, aliceKey, err := tenants.Add("alice", storage.Limits{MaxBytes: 1})
+	if err != nil {
+		t.Fatalf("Failed to add tenant: %v", err)
+	}
+	_, bobKey, err := tenants.Add("bob", storage.Limits{})
+	if err != nil {
+		t.Fatalf("Failed to add tenant: %v", err)
+	}
+
+	// Alice's NFT is saved before her quota applies, leaving her over it
+	aliceVault, err := storage.NewFileStorage(storage.TenantDir(baseDir, alice.ID))
+	if err != nil {
+		t.Fatalf("Failed to create tenant storage: %v", err)
+	}
+	mint := solanago.NewWallet().PublicKey()
+	nft := &fetcher.NFTInfo{
+		MintAddress: mint,
+		Owner:       solanago.NewWallet().PublicKey(),
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: "Alice's NFT"},
+	}
+	if err := aliceVault.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	ts := httptest.NewServer(New(shared, Options{Tenants: tenants}).Handler())
+	t.Cleanup(ts.Close)
+
+	get := func(path string, headers map[string]string) int {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	attestations := "/api/v1/attestations/" + mint.String()
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{"health check needs no key", "/healthz", nil, http.StatusOK},
+		{"no key", attestations, nil, http.StatusUnauthorized},
+		{"unknown key", attestations, map[string]string{"Authorization": "Bearer svk_unknown"}, http.StatusUnauthorized},
+		{"owner", attestations, map[string]string{"Authorization": "Bearer " + aliceKey}, http.StatusOK},
+		{"owner with X-API-Key", attestations, map[string]string{"X-API-Key": aliceKey}, http.StatusOK},
+		{"other tenant", attestations, map[string]string{"Authorization": "Bearer " + bobKey}, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		if status := get(test.path, test.headers); status != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, status)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/usage", nil)
+	req.Header.Set("Authorization", "Bearer "+aliceKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to get usage: %v", err)
+	}
+	defer resp.Body.Close()
+	var usage storage.Usage
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		t.Fatalf("Failed to decode usage: %v", err)
+	}
+	if usage.NFTs != 1 || usage.MaxBytes != 1 {
+		t.Errorf("Expected alice's usage, got %+v", usage)
+	}
+
+	// Attestations are refused once the tenant is over quota
+	att := proof.Attestation{Mint: mint.String(), MediaHash: "sha256:abcdef", ProofHash: "sha256:0123", Timestamp: time.Now()}
+	if err := att.Sign(solanago.NewWallet().PrivateKey); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	body, _ := json.Marshal(att)
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/api/v1/attestations", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+aliceKey)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to post attestation: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInsufficientStorage {
+		t.Errorf("Expected 507 over quota, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// handleVaultFile serves a vault file byte for byte, as stored, so
+// 'solvault pull' can copy backups to another machine. Encrypted files stay
+// encrypted on the way.
+//
+// Explanation: Only index.json and the files under wallets/ are served.
+// Hidden names are refused too, which keeps half-written .staged files and
+// commit journals, and anything outside the backups such as tenant keys
+// and the audit log, off the wire.
+func (s *Server) handleVaultFile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	if !servableVaultPath(name) {
+		writeError(w, http.StatusBadRequest, "invalid vault path")
+		return
+	}
+
+	file, err := os.Open(filepath.Join(s.vaultFor(r).BaseDir(), filepath.FromSlash(name)))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "%s not found", name)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		writeError(w, http.StatusNotFound, "%s not found", name)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, path.Base(name), info.ModTime(), file)
+}
+
+// servableVaultPath reports whether a slash-separated vault path may be
+// served by handleVaultFile
+func servableVaultPath(name string) bool {
+	if name == "index.json" {
+		return true
+	}
+	if name == "" || path.Clean(name) != name || !strings.HasPrefix(name, "wallets/") {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
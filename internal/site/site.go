@@ -0,0 +1,351 @@
+// Package site renders a vault's proofs as a static website: an index of
+// every NFT and one page per NFT with its image, metadata, hashes,
+// verification status and a QR code linking to the mint on an explorer.
+// All links are relative, so the output can be served from GitHub Pages, any
+// web server or IPFS as it is.
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// markerFile marks a directory as written by Write, so it may be replaced
+const markerFile = ".solvault-site"
+
+// Attribute is one metadata trait
+type Attribute struct {
+	Trait string
+	Value string
+}
+
+// File is one backed-up file and its hash from integrity.json
+type File struct {
+	Path   string
+	SHA256 string
+	Size   int64
+}
+
+// Link is a named URL
+type Link struct {
+	Name string
+	URL  string
+}
+
+// Page is the proof page of one NFT
+type Page struct {
+	Mint        string
+	Name        string
+	Symbol      string
+	Collection  string
+	Description string
+	Wallet      string
+	Attributes  []Attribute
+
+	// From proof.json; Status is empty when the NFT was never verified
+	Status       string
+	VerifiedAt   string
+	VerifiedBy   string
+	ImageHash    string
+	MetadataHash string
+	MerkleRoot   string
+	Timestamp    string // Who timestamped proof.json and when, if anyone did
+
+	Files    []File
+	Metadata []byte // metadata.json, shown pretty-printed and offered for download
+
+	// The image shown on the page, e.g. a thumbnail, and optionally the
+	// original media it was made from
+	Image        []byte
+	ImageName    string
+	Original     []byte
+	OriginalName string
+
+	Proof      []byte // proof.json as written by verify
+	ProofToken []byte // proof.json.tsr, when it still matches proof.json
+}
+
+// Site is every proof page rendered together
+type Site struct {
+	Title       string
+	Cluster     string // Solana cluster for explorer links; empty for mainnet-beta
+	GeneratedAt time.Time
+	Pages       []*Page
+}
+
+// Explorers returns links to a mint on Solana block explorers
+func (s *Site) Explorers(mint string) []Link {
+	query := ""
+	if s.Cluster != "" && s.Cluster != "mainnet-beta" {
+		query = "?cluster=" + s.Cluster
+	}
+	return []Link{
+		{Name: "Solana Explorer", URL: "https://explorer.solana.com/address/" + mint + query},
+		{Name: "Solscan", URL: "https://solscan.io/token/" + mint + query},
+	}
+}
+
+// Write renders the site into dir. dir must be empty, missing, or a site
+// written by an earlier Write, whose pages are replaced.
+func Write(dir string, s *Site) error {
+	if err := prepare(dir); err != nil {
+		return err
+	}
+
+	for _, page := range s.Pages {
+		if err := writePage(dir, s, page); err != nil {
+			return fmt.Errorf("failed to write page for %s: %w", page.Mint, err)
+		}
+	}
+
+	var index bytes.Buffer
+	if err := indexTemplate.Execute(&index, s); err != nil {
+		return fmt.Errorf("failed to render index: %w", err)
+	}
+	files := map[string][]byte{
+		"index.html": index.Bytes(),
+		markerFile:   []byte(s.GeneratedAt.UTC().Format(time.RFC3339) + "\n"),
+		// GitHub Pages would otherwise run the site through Jekyll
+		".nojekyll": nil,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// prepare creates dir, or clears the pages of a site written there before.
+//
+// Explanation: Pages of NFTs that have left the vault must not linger in a
+// regenerated site, but an --out pointing at the wrong directory must not
+// delete anything, so only directories carrying the marker are cleared.
+func prepare(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, markerFile)); err != nil {
+		return fmt.Errorf("%s is not empty and was not written by SolVault; choose an empty directory", dir)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "nft")); err != nil {
+		return fmt.Errorf("failed to remove old pages: %w", err)
+	}
+	return nil
+}
+
+func writePage(dir string, s *Site, page *Page) error {
+	pageDir := filepath.Join(dir, "nft", filepath.Base(page.Mint))
+	if err := os.MkdirAll(pageDir, 0755); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{}
+	if page.ImageName != "" {
+		files[filepath.Base(page.ImageName)] = page.Image
+	}
+	if page.OriginalName != "" {
+		files[filepath.Base(page.OriginalName)] = page.Original
+	}
+	if len(page.Metadata) > 0 {
+		files["metadata.json"] = page.Metadata
+	}
+	if len(page.Proof) > 0 {
+		files["proof.json"] = page.Proof
+	}
+	if len(page.ProofToken) > 0 {
+		files["proof.json.tsr"] = page.ProofToken
+	}
+
+	var html bytes.Buffer
+	if err := pageTemplate.Execute(&html, struct {
+		*Site
+		Page *Page
+	}{s, page}); err != nil {
+		return err
+	}
+	files["index.html"] = html.Bytes()
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(pageDir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// qrSVG draws a QR code for content as an inline SVG
+func qrSVG(content string) (template.HTML, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	bitmap := code.Bitmap()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="168" height="168" shape-rendering="crispEdges" role="img" aria-label="QR code">`, len(bitmap), len(bitmap))
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	// The content is an explorer URL built from a base58 mint
+	return template.HTML(b.String()), nil
+}
+
+// prettyJSON indents JSON for display, leaving anything else as it is
+func prettyJSON(data []byte) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return out.String()
+}
+
+// statusLabel describes a proof status for readers
+func statusLabel(status string) string {
+	switch status {
+	case "authentic":
+		return "✅ Authentic"
+	case "tampered":
+		return "❌ Tampered"
+	case "incomplete":
+		return "⚠️ Incomplete"
+	case "error":
+		return "🚫 Verification error"
+	case "":
+		return "Not verified"
+	}
+	return status
+}
+
+var funcs = template.FuncMap{
+	"qr":     qrSVG,
+	"pretty": prettyJSON,
+	"status": statusLabel,
+	"date":   func(t time.Time) string { return t.UTC().Format("Jan 2, 2006 15:04 UTC") },
+	"title": func(page *Page) string {
+		if page.Name != "" {
+			return page.Name
+		}
+		return page.Mint
+	},
+}
+
+const style = `<style>
+body { font-family: system-ui, sans-serif; max-width: 880px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.5rem; } h2 { font-size: 1.1rem; margin-top: 2rem; }
+a { color: #5a3fd6; }
+code, pre { font-family: ui-monospace, monospace; font-size: .85rem; word-break: break-all; }
+pre { background: #f6f6f8; padding: 1rem; overflow-x: auto; white-space: pre-wrap; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: .35rem .5rem; border-bottom: 1px solid #eee; vertical-align: top; }
+.status { display: inline-block; padding: .2rem .6rem; border-radius: 1rem; background: #eee; }
+.status-authentic { background: #dafbe1; } .status-tampered, .status-error { background: #ffebe9; } .status-incomplete { background: #fff8c5; }
+.hero { display: flex; gap: 1.5rem; flex-wrap: wrap; align-items: flex-start; }
+.hero img { max-width: 420px; width: 100%; border-radius: .5rem; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(180px, 1fr)); gap: 1rem; }
+.card { border: 1px solid #eee; border-radius: .5rem; padding: .5rem; text-decoration: none; color: inherit; }
+.card img { width: 100%; aspect-ratio: 1; object-fit: cover; border-radius: .3rem; background: #f6f6f8; }
+small { color: #777; }
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+` + style + `
+</head>
+<body>
+<h1>🔒 {{.Title}}</h1>
+<p><small>{{len .Pages}} NFTs · generated {{date .GeneratedAt}} by SolVault</small></p>
+<div class="grid">
+{{range .Pages}}<a class="card" href="nft/{{.Mint}}/">
+{{if .ImageName}}<img src="nft/{{.Mint}}/{{.ImageName}}" alt="" loading="lazy">{{end}}
+<strong>{{title .}}</strong><br>
+<span class="status status-{{.Status}}">{{status .Status}}</span>
+</a>
+{{end}}</div>
+</body>
+</html>
+`))
+
+var pageTemplate = template.Must(template.New("page").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{with .Page}}<title>{{title .}} · proof of authenticity</title>{{end}}
+` + style + `
+</head>
+<body>
+{{$site := .Site}}{{with .Page}}
+<p><a href="../../">← {{$site.Title}}</a></p>
+<h1>{{title .}}</h1>
+<div class="hero">
+{{if .ImageName}}<img src="{{.ImageName}}" alt="{{title .}}">{{end}}
+<div>
+<p><span class="status status-{{.Status}}">{{status .Status}}</span></p>
+{{if .VerifiedAt}}<p>Verified {{.VerifiedAt}}{{if .VerifiedBy}} by {{.VerifiedBy}}{{end}}</p>{{end}}
+{{if .Timestamp}}<p>⏱️ Proof timestamped {{.Timestamp}}</p>{{end}}
+{{$explorers := $site.Explorers .Mint}}
+{{with index $explorers 0}}{{qr .URL}}{{end}}
+<p>{{range $i, $link := $explorers}}{{if $i}} · {{end}}<a href="{{$link.URL}}">{{$link.Name}}</a>{{end}}</p>
+</div>
+</div>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+
+<h2>Details</h2>
+<table>
+<tr><th>Mint</th><td><code>{{.Mint}}</code></td></tr>
+{{if .Collection}}<tr><th>Collection</th><td>{{.Collection}}</td></tr>{{end}}
+{{if .Symbol}}<tr><th>Symbol</th><td>{{.Symbol}}</td></tr>{{end}}
+{{if .Wallet}}<tr><th>Backed up for</th><td><code>{{.Wallet}}</code></td></tr>{{end}}
+{{range .Attributes}}<tr><th>{{.Trait}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>Hashes</h2>
+<table>
+{{if .ImageHash}}<tr><th>Image</th><td><code>{{.ImageHash}}</code></td></tr>{{end}}
+{{if .MetadataHash}}<tr><th>Metadata (RFC 8785)</th><td><code>{{.MetadataHash}}</code></td></tr>{{end}}
+{{if .MerkleRoot}}<tr><th>Wallet Merkle root</th><td><code>{{.MerkleRoot}}</code></td></tr>{{end}}
+{{range .Files}}<tr><th>{{.Path}}</th><td><code>sha256:{{.SHA256}}</code> <small>{{.Size}} bytes</small></td></tr>
+{{end}}</table>
+
+<h2>Downloads</h2>
+<ul>
+{{if .Proof}}<li><a href="proof.json">proof.json</a>{{if .ProofToken}} and its RFC 3161 timestamp <a href="proof.json.tsr">proof.json.tsr</a>{{end}}</li>{{end}}
+{{if .Metadata}}<li><a href="metadata.json">metadata.json</a></li>{{end}}
+{{if .OriginalName}}<li><a href="{{.OriginalName}}">Original media</a></li>{{end}}
+</ul>
+{{if .ProofToken}}<p><small>Check the timestamp with <code>openssl ts -verify -in proof.json.tsr -data proof.json -CAfile &lt;authority CA&gt;</code></small></p>{{end}}
+
+{{if .Metadata}}<h2>Metadata</h2>
+<pre>{{pretty .Metadata}}</pre>{{end}}
+{{end}}
+<p><small>Generated {{date .GeneratedAt}} by SolVault</small></p>
+</body>
+</html>
+`))
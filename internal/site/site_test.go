@@ -0,0 +1,118 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSite() *Site {
+	return &Site{
+		Title:       "My proofs",
+		GeneratedAt: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+		Pages: []*Page{
+			{
+				Mint:       "7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU",
+				Name:       "Cool Cat <script>alert(1)</script>",
+				Status:     "authentic",
+				VerifiedAt: "2026-05-01T11:00:00Z",
+				ImageHash:  "sha256:abc",
+				Attributes: []Attribute{{Trait: "Eyes", Value: "Laser"}},
+				Files:      []File{{Path: "metadata.json", SHA256: "def", Size: 42}},
+				Metadata:   []byte(`{"name":"Cool Cat"}`),
+				Image:      []byte("jpeg"),
+				ImageName:  "image.jpg",
+				Proof:      []byte(`{"status":"authentic"}`),
+				ProofToken: []byte("token"),
+			},
+			{Mint: "9sdfe1xA3sXeT4iJjzBxXNtQZiS2YMBMWaXRgSkJKX1L"},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	s := testSite()
+	if err := Write(dir, s); err != nil {
+		t.Fatalf("Failed to write site: %v", err)
+	}
+
+	for _, name := range []string{
+		"index.html", ".nojekyll", markerFile,
+		"nft/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU/index.html",
+		"nft/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU/image.jpg",
+		"nft/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU/proof.json",
+		"nft/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU/proof.json.tsr",
+		"nft/9sdfe1xA3sXeT4iJjzBxXNtQZiS2YMBMWaXRgSkJKX1L/index.html",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Missing %s: %v", name, err)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "nft/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU/index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read page: %v", err)
+	}
+	for _, want := range []string{
+		"https://explorer.solana.com/address/7xKXtg2CW87d97TXJSDpbD5jBkheTqA83TZRuJosgAsU",
+		"<svg", "✅ Authentic", "sha256:abc", "Laser", `href="proof.json.tsr"`,
+		"Cool Cat &lt;script&gt;",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Page does not contain %q", want)
+		}
+	}
+	if strings.Contains(string(page), "<script>") {
+		t.Errorf("Page contains an unescaped name")
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+	if !strings.Contains(string(index), `href="nft/9sdfe1xA3sXeT4iJjzBxXNtQZiS2YMBMWaXRgSkJKX1L/"`) || !strings.Contains(string(index), "Not verified") {
+		t.Errorf("Index does not link the unverified NFT")
+	}
+
+	// Regenerating drops pages of NFTs no longer in the site
+	s.Pages = s.Pages[:1]
+	if err := Write(dir, s); err != nil {
+		t.Fatalf("Failed to rewrite site: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nft/9sdfe1xA3sXeT4iJjzBxXNtQZiS2YMBMWaXRgSkJKX1L")); !os.IsNotExist(err) {
+		t.Errorf("Stale page was kept: %v", err)
+	}
+}
+
+func TestWrite_RefusesOtherDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := Write(dir, testSite()); err == nil {
+		t.Errorf("Expected an error writing into a directory SolVault did not create")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("Existing file was touched: %v", err)
+	}
+}
+
+func TestSite_Explorers(t *testing.T) {
+	tests := []struct {
+		cluster string
+		want    string
+	}{
+		{cluster: "", want: "https://solscan.io/token/Mint1"},
+		{cluster: "mainnet-beta", want: "https://solscan.io/token/Mint1"},
+		{cluster: "devnet", want: "https://solscan.io/token/Mint1?cluster=devnet"},
+	}
+	for _, tt := range tests {
+		links := (&Site{Cluster: tt.cluster}).Explorers("Mint1")
+		if links[1].URL != tt.want {
+			t.Errorf("Cluster %q: got %s, want %s", tt.cluster, links[1].URL, tt.want)
+		}
+	}
+}
@@ -2,16 +2,28 @@ package solana
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/NazWright/solvault/internal/telemetry"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Client wraps the Solana RPC client with our configuration
+// ErrAccountNotFound is returned when an account does not exist on chain
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrNoHolder is returned when no token account holds a balance of a mint,
+// e.g. once the NFT is burned
+var ErrNoHolder = errors.New("no wallet holds this token")
+
+// Client wraps the Solana RPC endpoints with our configuration
 type Client struct {
-	rpc    *rpc.Client
+	pool   *Pool
 	config *Config
 }
 
@@ -21,82 +33,245 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	rpcClient := rpc.New(config.RPCURL)
+	pool, err := NewPool(config.Endpoints(), time.Duration(config.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
 
 	client := &Client{
-		rpc:    rpcClient,
+		pool:   pool,
 		config: config,
 	}
 
 	return client, nil
 }
 
-// TestConnection verifies that we can connect to the Solana RPC endpoint
+// TestConnection verifies that we can connect to at least one Solana RPC
+// endpoint. Endpoints that fail are skipped until they recover.
 func (c *Client) TestConnection(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutSeconds)*time.Second)
-	defer cancel()
+	var lastErr string
+	for _, status := range c.pool.CheckHealth(ctx) {
+		if status.Healthy {
+			return nil
+		}
+		lastErr = status.LastError
+	}
+	return fmt.Errorf("failed to connect to Solana RPC: %s", lastErr)
+}
 
-	_, err := c.rpc.GetVersion(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to connect to Solana RPC: %w", err)
+// Endpoints reports the health of each configured RPC endpoint as of the
+// last call or health check
+func (c *Client) Endpoints() []EndpointStatus {
+	return c.pool.Status()
+}
+
+// do runs one call of RPC method against the pool, traced as one span
+// covering any failover
+func (c *Client) do(ctx context.Context, method string, call func(ctx context.Context, client *rpc.Client) error) error {
+	return traced(ctx, method, func(ctx context.Context) error {
+		return c.pool.Do(ctx, call)
+	})
+}
+
+// heavy runs one call of a large batch (per-NFT lookups, history paging),
+// spread over every endpoint when RPC_LOAD_BALANCE is on
+func (c *Client) heavy(ctx context.Context, method string, call func(ctx context.Context, client *rpc.Client) error) error {
+	if !c.config.LoadBalance {
+		return c.do(ctx, method, call)
 	}
+	return traced(ctx, method, func(ctx context.Context) error {
+		return c.pool.Balanced(ctx, call)
+	})
+}
 
-	return nil
+// traced runs an RPC call in a client span named after its method
+func traced(ctx context.Context, method string, run func(ctx context.Context) error) error {
+	ctx, span := telemetry.StartClient(ctx, "solana "+method,
+		attribute.String("rpc.system", "solana"),
+		attribute.String("rpc.method", method))
+	err := run(ctx)
+	telemetry.End(span, err)
+	return err
 }
 
-// GetTokenAccountsByOwner retrieves all token accounts owned by the configured wallet
+// GetTokenAccountsByOwner retrieves all token accounts owned by the primary configured wallet
 func (c *Client) GetTokenAccountsByOwner(ctx context.Context) ([]*rpc.TokenAccount, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutSeconds)*time.Second)
-	defer cancel()
-
-	// Get all token accounts for the wallet
-	result, err := c.rpc.GetTokenAccountsByOwner(
-		ctx,
-		c.config.WalletAddress,
-		&rpc.GetTokenAccountsConfig{
-			ProgramId: &solana.TokenProgramID,
-		},
-		&rpc.GetTokenAccountsOpts{
-			Encoding: solana.EncodingJSONParsed,
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token accounts: %w", err)
+	return c.GetTokenAccountsForOwner(ctx, c.config.WalletAddress)
+}
+
+// TokenProgramIDs lists the SPL token programs NFTs can live under: the
+// legacy Token program and Token-2022 (token extensions)
+var TokenProgramIDs = []solana.PublicKey{
+	solana.TokenProgramID,
+	solana.Token2022ProgramID,
+}
+
+// GetTokenAccountsForOwner retrieves all token accounts owned by an arbitrary
+// wallet across every token program
+func (c *Client) GetTokenAccountsForOwner(ctx context.Context, owner solana.PublicKey) ([]*rpc.TokenAccount, error) {
+	// The RPC filter only accepts a single program, so query each in turn
+	var accounts []*rpc.TokenAccount
+	for _, programID := range TokenProgramIDs {
+		programID := programID
+		var result *rpc.GetTokenAccountsResult
+		err := c.do(ctx, "getTokenAccountsByOwner", func(ctx context.Context, client *rpc.Client) (err error) {
+			result, err = client.GetTokenAccountsByOwner(
+				ctx,
+				owner,
+				&rpc.GetTokenAccountsConfig{
+					ProgramId: &programID,
+				},
+				&rpc.GetTokenAccountsOpts{
+					Encoding: solana.EncodingJSONParsed,
+				},
+			)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token accounts for %s: %w", owner.String(), err)
+		}
+		accounts = append(accounts, result.Value...)
 	}
 
-	return result.Value, nil
+	return accounts, nil
 }
 
 // GetAccountInfo retrieves account information for a given public key
 func (c *Client) GetAccountInfo(ctx context.Context, pubkey solana.PublicKey) (*rpc.Account, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutSeconds)*time.Second)
-	defer cancel()
-
-	result, err := c.rpc.GetAccountInfo(ctx, pubkey)
+	var result *rpc.GetAccountInfoResult
+	err := c.heavy(ctx, "getAccountInfo", func(ctx context.Context, client *rpc.Client) (err error) {
+		result, err = client.GetAccountInfo(ctx, pubkey)
+		return err
+	})
+	if errors.Is(err, rpc.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, pubkey.String())
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account info for %s: %w", pubkey.String(), err)
 	}
 
 	if result.Value == nil {
-		return nil, fmt.Errorf("account not found: %s", pubkey.String())
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, pubkey.String())
 	}
 
 	return result.Value, nil
 }
 
+// GetMintSupply returns the current supply of a token mint, or 0 if the
+// mint account no longer exists
+func (c *Client) GetMintSupply(ctx context.Context, mint solana.PublicKey) (uint64, error) {
+	account, err := c.GetAccountInfo(ctx, mint)
+	if errors.Is(err, ErrAccountNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// SPL mint layout: 36 bytes of optional mint authority, then supply (u64 LE)
+	data := account.Data.GetBinary()
+	if len(data) < 44 {
+		return 0, fmt.Errorf("account %s is not a token mint", mint.String())
+	}
+	return binary.LittleEndian.Uint64(data[36:44]), nil
+}
+
+// TokenHolder is a wallet holding some of a mint's supply
+type TokenHolder struct {
+	Owner        solana.PublicKey `json:"owner"`
+	TokenAccount solana.PublicKey `json:"token_account"`
+	Amount       uint64           `json:"amount"`
+	Delegate     solana.PublicKey `json:"delegate,omitempty"` // Zero when nothing may move the tokens for the owner
+	Frozen       bool             `json:"frozen,omitempty"`
+}
+
+// GetTokenHolders returns the wallets holding a mint, largest balance first.
+// Explanation: getTokenLargestAccounts only names token accounts (at most
+// 20), so each account with a balance is read to learn its owner. For an
+// NFT that is one extra call, and it works for any wallet, not only the
+// configured ones.
+func (c *Client) GetTokenHolders(ctx context.Context, mint solana.PublicKey) ([]TokenHolder, error) {
+	var result *rpc.GetTokenLargestAccountsResult
+	err := c.heavy(ctx, "getTokenLargestAccounts", func(ctx context.Context, client *rpc.Client) (err error) {
+		result, err = client.GetTokenLargestAccounts(ctx, mint, rpc.CommitmentConfirmed)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get largest token accounts for %s: %w", mint.String(), err)
+	}
+
+	var holders []TokenHolder
+	for _, largest := range result.Value {
+		amount, err := strconv.ParseUint(largest.Amount, 10, 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+		account, err := c.GetAccountInfo(ctx, largest.Address)
+		if errors.Is(err, ErrAccountNotFound) {
+			continue // Closed since the first call
+		}
+		if err != nil {
+			return nil, err
+		}
+		holder, err := readTokenAccount(account.Data.GetBinary())
+		if err != nil {
+			return nil, fmt.Errorf("account %s is not a token account: %w", largest.Address.String(), err)
+		}
+		holder.TokenAccount, holder.Amount = largest.Address, amount
+		holders = append(holders, *holder)
+	}
+	return holders, nil
+}
+
+// readTokenAccount reads the owner, delegate and state of an SPL token
+// account. Token-2022 accounts share the layout, with extensions after it.
+//
+// Explanation: The layout is mint (32 bytes), owner (32), amount (8), an
+// optional delegate as a 4-byte tag and 32-byte key, then the state byte,
+// where 2 is frozen.
+func readTokenAccount(data []byte) (*TokenHolder, error) {
+	if len(data) < 109 {
+		return nil, fmt.Errorf("%d bytes of data", len(data))
+	}
+	holder := &TokenHolder{Owner: solana.PublicKeyFromBytes(data[32:64])}
+	if binary.LittleEndian.Uint32(data[72:76]) == 1 {
+		holder.Delegate = solana.PublicKeyFromBytes(data[76:108])
+	}
+	holder.Frozen = data[108] == 2
+	return holder, nil
+}
+
+// GetCurrentHolder returns the wallet currently holding an NFT, or
+// ErrNoHolder when nobody does
+func (c *Client) GetCurrentHolder(ctx context.Context, mint solana.PublicKey) (*TokenHolder, error) {
+	holders, err := c.GetTokenHolders(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+	if len(holders) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoHolder, mint.String())
+	}
+	return &holders[0], nil
+}
+
 // GetTransaction retrieves transaction details by signature
 func (c *Client) GetTransaction(ctx context.Context, signature solana.Signature) (*rpc.GetTransactionResult, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutSeconds)*time.Second)
-	defer cancel()
-
-	result, err := c.rpc.GetTransaction(
-		ctx,
-		signature,
-		&rpc.GetTransactionOpts{
-			Encoding:   solana.EncodingJSONParsed,
-			Commitment: rpc.CommitmentFinalized,
-		},
-	)
+	// Binary encoding decodes into solana.Transaction; version 0 lets
+	// transactions using address lookup tables through
+	maxVersion := uint64(0)
+	var result *rpc.GetTransactionResult
+	err := c.heavy(ctx, "getTransaction", func(ctx context.Context, client *rpc.Client) (err error) {
+		result, err = client.GetTransaction(
+			ctx,
+			signature,
+			&rpc.GetTransactionOpts{
+				Encoding:                       solana.EncodingBase64,
+				Commitment:                     rpc.CommitmentFinalized,
+				MaxSupportedTransactionVersion: &maxVersion,
+			},
+		)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction %s: %w", signature.String(), err)
 	}
@@ -106,18 +281,19 @@ func (c *Client) GetTransaction(ctx context.Context, signature solana.Signature)
 
 // GetSignaturesForAddress retrieves recent transaction signatures for an address
 func (c *Client) GetSignaturesForAddress(ctx context.Context, address solana.PublicKey, limit int) ([]*rpc.TransactionSignature, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.config.TimeoutSeconds)*time.Second)
-	defer cancel()
-
 	limitUint := uint64(limit)
-	result, err := c.rpc.GetConfirmedSignaturesForAddress2(
-		ctx,
-		address,
-		&rpc.GetConfirmedSignaturesForAddress2Opts{
-			Limit:      &limitUint,
-			Commitment: rpc.CommitmentFinalized,
-		},
-	)
+	var result []*rpc.TransactionSignature
+	err := c.do(ctx, "getSignaturesForAddress", func(ctx context.Context, client *rpc.Client) (err error) {
+		result, err = client.GetConfirmedSignaturesForAddress2(
+			ctx,
+			address,
+			&rpc.GetConfirmedSignaturesForAddress2Opts{
+				Limit:      &limitUint,
+				Commitment: rpc.CommitmentFinalized,
+			},
+		)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get signatures for address %s: %w", address.String(), err)
 	}
@@ -125,6 +301,70 @@ func (c *Client) GetSignaturesForAddress(ctx context.Context, address solana.Pub
 	return result, nil
 }
 
+// GetSignatureHistory returns every signature for address newer than until
+// (or its whole history when until is zero), newest first
+func (c *Client) GetSignatureHistory(ctx context.Context, address solana.PublicKey, until solana.Signature) ([]*rpc.TransactionSignature, error) {
+	const pageSize = 1000
+	limit := pageSize
+	opts := &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Until:      until,
+		Commitment: rpc.CommitmentFinalized,
+	}
+
+	var history []*rpc.TransactionSignature
+	for {
+		var page []*rpc.TransactionSignature
+		err := c.heavy(ctx, "getSignaturesForAddress", func(ctx context.Context, client *rpc.Client) (err error) {
+			page, err = client.GetSignaturesForAddressWithOpts(ctx, address, opts)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signatures for address %s: %w", address.String(), err)
+		}
+		history = append(history, page...)
+		if len(page) < pageSize {
+			return history, nil
+		}
+		opts.Before = page[len(page)-1].Signature
+	}
+}
+
+// GetOldestSignature pages back through an address's full transaction
+// history and returns its first transaction, e.g. the mint of an NFT
+func (c *Client) GetOldestSignature(ctx context.Context, address solana.PublicKey) (*rpc.TransactionSignature, error) {
+	const pageSize = 1000
+	limit := pageSize
+	opts := &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Commitment: rpc.CommitmentFinalized,
+	}
+
+	var oldest *rpc.TransactionSignature
+	for {
+		var page []*rpc.TransactionSignature
+		err := c.heavy(ctx, "getSignaturesForAddress", func(ctx context.Context, client *rpc.Client) (err error) {
+			page, err = client.GetSignaturesForAddressWithOpts(ctx, address, opts)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signatures for address %s: %w", address.String(), err)
+		}
+		if len(page) > 0 {
+			oldest = page[len(page)-1]
+			opts.Before = oldest.Signature
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	if oldest == nil {
+		return nil, fmt.Errorf("%w: no transactions for %s", ErrAccountNotFound, address.String())
+	}
+	return oldest, nil
+}
+
 // Config returns the client's configuration
 func (c *Client) Config() *Config {
 	return c.config
@@ -0,0 +1,115 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// holderServer answers getTokenLargestAccounts with the given balances and
+// getAccountInfo with token accounts owned by owners[address]
+func holderServer(t *testing.T, balances map[solana.PublicKey]string, owners map[solana.PublicKey]solana.PublicKey) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		rpcContext := map[string]interface{}{"slot": 1}
+		switch req.Method {
+		case "getTokenLargestAccounts":
+			var value []map[string]interface{}
+			for address, amount := range balances {
+				value = append(value, map[string]interface{}{
+					"address": address.String(), "amount": amount, "decimals": 0, "uiAmountString": amount,
+				})
+			}
+			result = map[string]interface{}{"context": rpcContext, "value": value}
+		case "getAccountInfo":
+			var address string
+			json.Unmarshal(req.Params[0], &address)
+			owner, ok := owners[solana.MustPublicKeyFromBase58(address)]
+			if !ok {
+				result = map[string]interface{}{"context": rpcContext, "value": nil}
+				break
+			}
+			data := append(make([]byte, 32), owner.Bytes()...)
+			data = append(data, make([]byte, 101)...)
+			result = map[string]interface{}{"context": rpcContext, "value": map[string]interface{}{
+				"data": []string{solana.Base58(data).String(), "base58"}, "owner": solana.TokenProgramID.String(),
+				"lamports": 2039280, "executable": false, "rentEpoch": 0,
+			}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetCurrentHolder(t *testing.T) {
+	holder := solana.NewWallet().PublicKey()
+	emptied := solana.NewWallet().PublicKey()
+	account := solana.NewWallet().PublicKey()
+	oldAccount := solana.NewWallet().PublicKey()
+	closedAccount := solana.NewWallet().PublicKey()
+
+	tests := []struct {
+		name      string
+		balances  map[solana.PublicKey]string
+		owners    map[solana.PublicKey]solana.PublicKey
+		wantOwner solana.PublicKey
+		wantErr   error
+	}{
+		{
+			name:      "held",
+			balances:  map[solana.PublicKey]string{account: "1", oldAccount: "0"},
+			owners:    map[solana.PublicKey]solana.PublicKey{account: holder, oldAccount: emptied},
+			wantOwner: holder,
+		},
+		{
+			name:     "burned",
+			balances: map[solana.PublicKey]string{oldAccount: "0"},
+			owners:   map[solana.PublicKey]solana.PublicKey{oldAccount: emptied},
+			wantErr:  ErrNoHolder,
+		},
+		{
+			name:     "closed between calls",
+			balances: map[solana.PublicKey]string{closedAccount: "1"},
+			wantErr:  ErrNoHolder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := holderServer(t, tt.balances, tt.owners)
+			client, err := NewClient(&Config{RPCURL: server.URL, WalletAddress: holder, PollInterval: 1, TimeoutSeconds: 5})
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			got, err := client.GetCurrentHolder(context.Background(), solana.NewWallet().PublicKey())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to get current holder: %v", err)
+			}
+			if got.Owner != tt.wantOwner || got.Amount != 1 {
+				t.Errorf("Got holder %s with %d, want %s with 1", got.Owner, got.Amount, tt.wantOwner)
+			}
+		})
+	}
+}
@@ -4,17 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/NazWright/solvault/internal/config"
 	"github.com/gagliardetto/solana-go"
-	"github.com/joho/godotenv"
 )
 
 // Config holds all Solana-related configuration
 type Config struct {
-	RPCURL          string
+	RPCURL          string   // Primary RPC endpoint, always RPCURLs[0]
+	RPCURLs         []string // Every RPC endpoint, tried in order on failure
+	LoadBalance     bool     // Spread heavy batch calls over every endpoint
 	WebSocketURL    string
-	WalletAddress   solana.PublicKey
+	WalletAddress   solana.PublicKey   // Primary wallet, always Wallets[0]
+	Wallets         []solana.PublicKey // Every wallet this installation protects
 	PollInterval    time.Duration
 	MaxRetries      int
 	TimeoutSeconds  int
@@ -23,35 +28,86 @@ type Config struct {
 	PublishAPIKey   string
 }
 
+// LoadEnvFiles fills in unset environment variables from ./.env, the config
+// file (~/.solvault.yaml) and ~/.solvault.env, in that order; see the config
+// package. A broken config file is reported by the root command, so it is
+// ignored here.
+func LoadEnvFiles() {
+	_ = config.Load()
+}
+
+// LoadWallets returns the configured wallets without requiring the rest of
+// the configuration. WALLET_ADDRESS is the primary wallet; WALLET_ADDRESSES
+// adds more as a comma or whitespace separated list. Either may name a
+// keypair file instead of an address.
+func LoadWallets() ([]solana.PublicKey, error) {
+	LoadEnvFiles()
+
+	var raw []string
+	if walletAddr := os.Getenv("WALLET_ADDRESS"); walletAddr != "" && walletAddr != "your_wallet_address_here" {
+		raw = append(raw, walletAddr)
+	}
+	raw = append(raw, os.Getenv("WALLET_ADDRESSES"))
+
+	wallets, err := ParseWalletList(strings.Join(raw, ","))
+	if err != nil {
+		return nil, err
+	}
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("WALLET_ADDRESS or WALLET_ADDRESSES environment variable is required and must be set to a valid Solana address")
+	}
+
+	return wallets, nil
+}
+
+// ParseWalletList parses a comma or whitespace separated list of wallet
+// addresses or keypair files (see ResolveWallet), dropping duplicates while
+// keeping the original order
+func ParseWalletList(list string) ([]solana.PublicKey, error) {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+
+	var wallets []solana.PublicKey
+	seen := make(map[solana.PublicKey]bool)
+	for _, field := range fields {
+		wallet, err := ResolveWallet(field)
+		if err != nil {
+			return nil, err
+		}
+		if seen[wallet] {
+			continue
+		}
+		seen[wallet] = true
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, nil
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
-	// Try to load .env file, but don't fail if it doesn't exist
-	_ = godotenv.Load()
-
 	config := &Config{}
 
+	var err error
+	config.Wallets, err = LoadWallets()
+	if err != nil {
+		return nil, err
+	}
+	config.WalletAddress = config.Wallets[0]
+
 	// Required fields
-	config.RPCURL = os.Getenv("SOLANA_RPC_URL")
-	if config.RPCURL == "" {
+	config.RPCURLs = RPCURLsFromEnv()
+	if len(config.RPCURLs) == 0 {
 		return nil, fmt.Errorf("SOLANA_RPC_URL environment variable is required")
 	}
+	config.RPCURL = config.RPCURLs[0]
 
 	config.WebSocketURL = os.Getenv("SOLANA_WEBSOCKET_URL")
 	if config.WebSocketURL == "" {
 		return nil, fmt.Errorf("SOLANA_WEBSOCKET_URL environment variable is required")
 	}
 
-	walletAddr := os.Getenv("WALLET_ADDRESS")
-	if walletAddr == "" || walletAddr == "your_wallet_address_here" {
-		return nil, fmt.Errorf("WALLET_ADDRESS environment variable is required and must be set to a valid Solana address")
-	}
-
-	var err error
-	config.WalletAddress, err = solana.PublicKeyFromBase58(walletAddr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid wallet address format: %w", err)
-	}
-
 	config.BackupDirectory = os.Getenv("BACKUP_DIRECTORY")
 	if config.BackupDirectory == "" {
 		homeDir, err := os.UserHomeDir()
@@ -65,6 +121,13 @@ func LoadConfig() (*Config, error) {
 	config.PublishEndpoint = os.Getenv("PUBLISH_ENDPOINT")
 	config.PublishAPIKey = os.Getenv("PUBLISH_API_KEY")
 
+	if loadBalance := os.Getenv("RPC_LOAD_BALANCE"); loadBalance != "" {
+		config.LoadBalance, err = strconv.ParseBool(loadBalance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RPC_LOAD_BALANCE: %w", err)
+		}
+	}
+
 	// Parse numeric fields with defaults
 	pollInterval := os.Getenv("POLL_INTERVAL_SECONDS")
 	if pollInterval == "" {
@@ -110,6 +173,12 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("wallet address is required")
 	}
 
+	for _, wallet := range c.Wallets {
+		if wallet.IsZero() {
+			return fmt.Errorf("wallet list contains an empty address")
+		}
+	}
+
 	if c.PollInterval <= 0 {
 		return fmt.Errorf("poll interval must be positive")
 	}
@@ -124,3 +193,57 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// Endpoints lists every RPC endpoint, primary first. Configs built by hand
+// may only set RPCURL.
+func (c *Config) Endpoints() []string {
+	if len(c.RPCURLs) == 0 {
+		return []string{c.RPCURL}
+	}
+	return c.RPCURLs
+}
+
+// HasWallet reports whether wallet is one of the configured wallets
+func (c *Config) HasWallet(wallet solana.PublicKey) bool {
+	for _, configured := range c.Wallets {
+		if configured.Equals(wallet) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterFromURL guesses the Solana cluster an RPC endpoint serves from its
+// URL, returning "" when it cannot tell (e.g. a custom provider domain)
+func ClusterFromURL(rpcURL string) string {
+	url := strings.ToLower(rpcURL)
+	switch {
+	case url == "":
+		return ""
+	case strings.Contains(url, "devnet"):
+		return "devnet"
+	case strings.Contains(url, "testnet"):
+		return "testnet"
+	case strings.Contains(url, "mainnet"):
+		return "mainnet-beta"
+	case strings.Contains(url, "localhost"), strings.Contains(url, "127.0.0.1"):
+		return "localnet"
+	default:
+		return ""
+	}
+}
+
+// ClusterEndpoints returns the public RPC and websocket URLs for a cluster
+// name, falling back to mainnet for unknown clusters
+func ClusterEndpoints(cluster string) (rpcURL, webSocketURL string) {
+	switch cluster {
+	case "devnet":
+		return "https://api.devnet.solana.com", "wss://api.devnet.solana.com"
+	case "testnet":
+		return "https://api.testnet.solana.com", "wss://api.testnet.solana.com"
+	case "localnet":
+		return "http://localhost:8899", "ws://localhost:8900"
+	default:
+		return "https://api.mainnet-beta.solana.com", "wss://api.mainnet-beta.solana.com"
+	}
+}
@@ -0,0 +1,69 @@
+package solana
+
+import "testing"
+
+func TestParseWalletList(t *testing.T) {
+	const (
+		walletA = "h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP"
+		walletB = "ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3"
+	)
+
+	wallets, err := ParseWalletList(walletA + ", " + walletB + "\n" + walletA)
+	if err != nil {
+		t.Fatalf("Failed to parse wallet list: %v", err)
+	}
+	if len(wallets) != 2 {
+		t.Fatalf("Expected 2 unique wallets, got %d", len(wallets))
+	}
+	if wallets[0].String() != walletA || wallets[1].String() != walletB {
+		t.Errorf("Order not preserved: %v", wallets)
+	}
+
+	if _, err := ParseWalletList("not-a-wallet"); err == nil {
+		t.Errorf("Expected error for invalid address")
+	}
+
+	wallets, err = ParseWalletList("  ,, ")
+	if err != nil || len(wallets) != 0 {
+		t.Errorf("Expected empty list, got %v (err %v)", wallets, err)
+	}
+}
+
+func TestConfigHasWallet(t *testing.T) {
+	wallets, err := ParseWalletList("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")
+	if err != nil {
+		t.Fatalf("Failed to parse wallet list: %v", err)
+	}
+	config := &Config{WalletAddress: wallets[0], Wallets: wallets}
+
+	if !config.HasWallet(wallets[0]) {
+		t.Errorf("Expected configured wallet to be found")
+	}
+
+	other, _ := ParseWalletList("ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3")
+	if config.HasWallet(other[0]) {
+		t.Errorf("Expected unknown wallet to be rejected")
+	}
+}
+
+func TestClusterFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.mainnet-beta.solana.com", "mainnet-beta"},
+		{"https://mainnet.helius-rpc.com/?api-key=x", "mainnet-beta"},
+		{"https://api.devnet.solana.com", "devnet"},
+		{"https://api.testnet.solana.com", "testnet"},
+		{"http://localhost:8899", "localnet"},
+		{"http://127.0.0.1:8899", "localnet"},
+		{"https://rpc.example.com", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ClusterFromURL(tt.url); got != tt.want {
+			t.Errorf("ClusterFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package solana
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"gopkg.in/yaml.v3"
+)
+
+// CLIConfig is the part of the Solana CLI's config.yml that solvault reads
+type CLIConfig struct {
+	JSONRPCURL   string `yaml:"json_rpc_url"`
+	WebSocketURL string `yaml:"websocket_url"`
+	KeypairPath  string `yaml:"keypair_path"`
+}
+
+// CLIConfigPath returns where the Solana CLI keeps its config,
+// ~/.config/solana/cli/config.yml
+func CLIConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "solana", "cli", "config.yml"), nil
+}
+
+// LoadCLIConfig reads a Solana CLI config file, CLIConfigPath when path is
+// empty
+func LoadCLIConfig(path string) (*CLIConfig, error) {
+	if path == "" {
+		var err error
+		if path, err = CLIConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Solana CLI config: %w", err)
+	}
+	var cfg CLIConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Solana CLI config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// ResolveWallet turns what a user typed for a wallet into its public key:
+// a base58 address, or the path of a solana-keygen JSON keypair such as
+// ~/.config/solana/id.json.
+//
+// Explanation: Only the public half of a keypair file is kept; the secret
+// key is read to derive it and then dropped, so nothing secret reaches the
+// configuration. A hardware wallet path (usb://ledger) cannot be read
+// without the Solana CLI's Ledger support, so it gets an error telling the
+// user how to look the address up instead.
+func ResolveWallet(ref string) (solana.PublicKey, error) {
+	ref = strings.TrimSpace(ref)
+	if key, err := solana.PublicKeyFromBase58(ref); err == nil {
+		return key, nil
+	}
+	if strings.HasPrefix(ref, "usb://") {
+		return solana.PublicKey{}, fmt.Errorf("hardware wallet %q cannot be read directly: run 'solana-keygen pubkey %s' and use the address it prints", ref, ref)
+	}
+	if !looksLikePath(ref) {
+		return solana.PublicKey{}, fmt.Errorf("invalid wallet address format %q: expected a base58 address or a keypair file", ref)
+	}
+
+	path, err := expandPath(ref)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	key, err := solana.PrivateKeyFromSolanaKeygenFile(path)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("failed to read keypair %s: %w", path, err)
+	}
+	return key.PublicKey(), nil
+}
+
+// looksLikePath reports whether ref names a file rather than a mistyped
+// address
+func looksLikePath(ref string) bool {
+	if strings.ContainsRune(ref, '/') || strings.ContainsRune(ref, filepath.Separator) || strings.HasSuffix(ref, ".json") || strings.HasPrefix(ref, "~") {
+		return true
+	}
+	_, err := os.Stat(ref)
+	return err == nil
+}
+
+// expandPath resolves a leading ~ to the home directory
+func expandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	return path, nil
+}
@@ -0,0 +1,79 @@
+package solana
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestResolveWallet(t *testing.T) {
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate keypair: %v", err)
+	}
+	data, _ := json.Marshal([]byte(key))
+	dir := t.TempDir()
+	keypairPath := filepath.Join(dir, "id.json")
+	if err := os.WriteFile(keypairPath, data, 0600); err != nil {
+		t.Fatalf("Failed to write keypair: %v", err)
+	}
+	t.Setenv("HOME", dir)
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    solana.PublicKey
+		wantErr string
+	}{
+		{name: "base58 address", ref: key.PublicKey().String(), want: key.PublicKey()},
+		{name: "keypair file", ref: keypairPath, want: key.PublicKey()},
+		{name: "keypair under home", ref: "~/id.json", want: key.PublicKey()},
+		{name: "missing keypair file", ref: filepath.Join(dir, "missing.json"), wantErr: "failed to read keypair"},
+		{name: "ledger", ref: "usb://ledger?key=0", wantErr: "solana-keygen pubkey"},
+		{name: "not an address", ref: "not-a-wallet", wantErr: "invalid wallet address format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveWallet(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to resolve wallet: %v", err)
+			}
+			if !got.Equals(tt.want) {
+				t.Errorf("Got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCLIConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := `---
+json_rpc_url: "https://api.devnet.solana.com"
+websocket_url: ""
+keypair_path: /home/user/.config/solana/id.json
+address_labels:
+  "11111111111111111111111111111111": System Program
+commitment: confirmed
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadCLIConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load CLI config: %v", err)
+	}
+	if cfg.JSONRPCURL != "https://api.devnet.solana.com" || cfg.KeypairPath != "/home/user/.config/solana/id.json" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,291 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/NazWright/solvault/internal/telemetry"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// failoverCooldown is how long an endpoint that failed is skipped before it
+// is tried again
+const failoverCooldown = 30 * time.Second
+
+// ParseRPCURLs parses a comma or whitespace separated list of RPC URLs,
+// dropping duplicates while keeping the original order
+func ParseRPCURLs(list string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+
+	var urls []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		urls = append(urls, field)
+	}
+	return urls
+}
+
+// RPCURLsFromEnv returns the configured RPC endpoints, primary first:
+// SOLANA_RPC_URL (which may itself be a list), then SOLANA_RPC_URL_2,
+// SOLANA_RPC_URL_3 and so on in numeric order
+func RPCURLsFromEnv() []string {
+	type numbered struct {
+		n   int
+		url string
+	}
+	var extra []numbered
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		suffix, ok := strings.CutPrefix(key, "SOLANA_RPC_URL_")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(suffix); err == nil {
+			extra = append(extra, numbered{n, value})
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].n < extra[j].n })
+
+	raw := []string{os.Getenv("SOLANA_RPC_URL")}
+	for _, e := range extra {
+		raw = append(raw, e.url)
+	}
+	return ParseRPCURLs(strings.Join(raw, ","))
+}
+
+// PrimaryRPCURL returns the first configured RPC endpoint, or "" when none is
+func PrimaryRPCURL() string {
+	if urls := RPCURLsFromEnv(); len(urls) > 0 {
+		return urls[0]
+	}
+	return ""
+}
+
+// EndpointStatus describes one endpoint of a pool
+type EndpointStatus struct {
+	URL       string        `json:"url"`
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency,omitempty"` // Of the last health check
+	Failures  int           `json:"failures"`          // Consecutive failed calls
+	LastError string        `json:"last_error,omitempty"`
+}
+
+// endpoint is one RPC URL and what the pool has learned about it
+type endpoint struct {
+	url       string
+	host      string // For traces, which must not record the URL's API key
+	rpc       *rpc.Client
+	failures  int
+	downUntil time.Time
+	lastErr   error
+	latency   time.Duration
+}
+
+// Pool spreads RPC calls over several endpoints. Calls go to the first
+// healthy endpoint and fail over to the next when one errors or times out;
+// balanced calls rotate through the healthy endpoints instead.
+//
+// Explanation: An endpoint that fails is skipped for failoverCooldown and
+// then tried again, so a provider that recovers gets its traffic back without
+// a restart. If every endpoint is cooling down, all are tried anyway: a
+// blip on the only provider should slow a sync down, not stop it.
+type Pool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int // Where the next balanced call starts
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+// NewPool creates a pool over urls, the first being the preferred endpoint.
+// timeout bounds each attempt, so a hanging endpoint is failed over from.
+func NewPool(urls []string, timeout time.Duration) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("RPC URL is required")
+	}
+	pool := &Pool{timeout: timeout, now: time.Now}
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return nil, fmt.Errorf("invalid RPC URL %q: expected http(s)://host", raw)
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: raw, host: parsed.Host, rpc: rpc.New(raw)})
+	}
+	return pool, nil
+}
+
+// URLs lists the pool's endpoints, preferred first
+func (p *Pool) URLs() []string {
+	urls := make([]string, len(p.endpoints))
+	for i, e := range p.endpoints {
+		urls[i] = e.url
+	}
+	return urls
+}
+
+// Do runs call against the preferred healthy endpoint, failing over to the
+// others on endpoint errors. Errors in the request itself (an invalid
+// parameter) are returned without trying another endpoint.
+func (p *Pool) Do(ctx context.Context, call func(ctx context.Context, client *rpc.Client) error) error {
+	return p.do(ctx, false, call)
+}
+
+// Balanced is Do for heavy batch work: successive calls start at successive
+// healthy endpoints so the load is shared between them
+func (p *Pool) Balanced(ctx context.Context, call func(ctx context.Context, client *rpc.Client) error) error {
+	return p.do(ctx, true, call)
+}
+
+func (p *Pool) do(ctx context.Context, balance bool, call func(ctx context.Context, client *rpc.Client) error) error {
+	var lastErr error
+	for _, e := range p.order(balance) {
+		attemptCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err := call(attemptCtx, e.rpc)
+		cancel()
+
+		if err == nil {
+			p.record(e, nil)
+			return nil
+		}
+		if ctx.Err() != nil || !endpointFailed(err) {
+			return err
+		}
+		p.record(e, err)
+		telemetry.Event(ctx, "rpc endpoint failed",
+			attribute.String("server.address", e.host),
+			attribute.String("error", err.Error()))
+		lastErr = err
+	}
+	if len(p.endpoints) > 1 {
+		return fmt.Errorf("all %d RPC endpoints failed, last error: %w", len(p.endpoints), lastErr)
+	}
+	return lastErr
+}
+
+// order lists the endpoints to try: healthy ones first (rotated for
+// balanced calls), then the ones cooling down, soonest back first
+func (p *Pool) order(balance bool) []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var healthy, down []*endpoint
+	start := 0
+	if balance {
+		start = p.next % len(p.endpoints)
+		p.next++
+	}
+	for i := range p.endpoints {
+		e := p.endpoints[(start+i)%len(p.endpoints)]
+		if now.Before(e.downUntil) {
+			down = append(down, e)
+		} else {
+			healthy = append(healthy, e)
+		}
+	}
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+	return append(healthy, down...)
+}
+
+// record updates an endpoint's health after a call
+func (p *Pool) record(e *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		e.failures, e.downUntil, e.lastErr = 0, time.Time{}, nil
+		return
+	}
+	e.failures++
+	e.lastErr = err
+	e.downUntil = p.now().Add(failoverCooldown)
+}
+
+// CheckHealth asks every endpoint for its health at once and returns their
+// status, preferred first. Endpoints that fail are cooled down like a
+// failed call; ones that pass are put back in rotation.
+func (p *Pool) CheckHealth(ctx context.Context) []EndpointStatus {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+
+			started := p.now()
+			_, err := e.rpc.GetHealth(checkCtx)
+			p.mu.Lock()
+			e.latency = p.now().Sub(started)
+			p.mu.Unlock()
+			p.record(e, err)
+		}(e)
+	}
+	wg.Wait()
+	return p.Status()
+}
+
+// Status reports what the pool currently knows about each endpoint
+func (p *Pool) Status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	statuses := make([]EndpointStatus, len(p.endpoints))
+	for i, e := range p.endpoints {
+		statuses[i] = EndpointStatus{
+			URL:      e.url,
+			Healthy:  !now.Before(e.downUntil),
+			Latency:  e.latency,
+			Failures: e.failures,
+		}
+		if e.lastErr != nil {
+			statuses[i].LastError = e.lastErr.Error()
+		}
+	}
+	return statuses
+}
+
+// Solana JSON-RPC error codes that depend on the node answering rather than
+// the request, so another endpoint may succeed
+const (
+	rpcCodeBlockNotAvailable   = -32004 // Node has not got the block (yet)
+	rpcCodeNodeUnhealthy       = -32005 // Node is behind
+	rpcCodeHistoryNotAvailable = -32011 // Node keeps no transaction history
+	rpcCodeInternal            = -32603
+)
+
+// endpointFailed reports whether err says more about the endpoint than
+// about the request: transport errors, timeouts, HTTP errors (rate limits,
+// outages, a rejected API key) and node-specific RPC errors
+func endpointFailed(err error) bool {
+	if errors.Is(err, rpc.ErrNotFound) {
+		return false
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case rpcCodeBlockNotAvailable, rpcCodeNodeUnhealthy, rpcCodeHistoryNotAvailable, rpcCodeInternal:
+			return true
+		}
+		return false
+	}
+	return true
+}
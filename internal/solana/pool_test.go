@@ -0,0 +1,165 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// rpcServer answers every JSON-RPC call with result, or with HTTP status
+// when it is not 200, and counts the calls it gets
+func rpcServer(t *testing.T, status int, result interface{}, calls *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if status != http.StatusOK {
+			http.Error(w, "unavailable", status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getHealth(ctx context.Context, client *rpc.Client) error {
+	_, err := client.GetHealth(ctx)
+	return err
+}
+
+func TestPool_Failover(t *testing.T) {
+	var downCalls, upCalls int32
+	down := rpcServer(t, http.StatusServiceUnavailable, nil, &downCalls)
+	up := rpcServer(t, http.StatusOK, "ok", &upCalls)
+
+	pool, err := NewPool([]string{down.URL, up.URL}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := pool.Do(context.Background(), getHealth); err != nil {
+			t.Fatalf("Call %d failed despite a healthy endpoint: %v", i, err)
+		}
+	}
+	if downCalls != 1 || upCalls != 3 {
+		t.Errorf("Got %d calls to the failing endpoint and %d to the healthy one, want 1 and 3", downCalls, upCalls)
+	}
+	status := pool.Status()
+	if status[0].Healthy || status[0].Failures != 1 || !status[1].Healthy {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	// After the cooldown the primary is tried again
+	now = now.Add(failoverCooldown)
+	pool.Do(context.Background(), getHealth)
+	if downCalls != 2 {
+		t.Errorf("Primary tried %d times after cooldown, want 2", downCalls)
+	}
+}
+
+func TestPool_AllDown(t *testing.T) {
+	var calls int32
+	first := rpcServer(t, http.StatusTooManyRequests, nil, &calls)
+	second := rpcServer(t, http.StatusBadGateway, nil, &calls)
+
+	pool, err := NewPool([]string{first.URL, second.URL}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	if err := pool.Do(context.Background(), getHealth); err == nil {
+		t.Fatalf("Expected an error when every endpoint fails")
+	}
+	// Cooling down does not stop every endpoint being tried
+	if err := pool.Do(context.Background(), getHealth); err == nil || calls != 4 {
+		t.Errorf("Got %d calls (err %v), want every endpoint tried twice", calls, err)
+	}
+}
+
+func TestPool_RequestErrorDoesNotFailOver(t *testing.T) {
+	var firstCalls, secondCalls int32
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&firstCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid param"}}`))
+	}))
+	t.Cleanup(first.Close)
+	second := rpcServer(t, http.StatusOK, "ok", &secondCalls)
+
+	pool, err := NewPool([]string{first.URL, second.URL}, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	if err := pool.Do(context.Background(), getHealth); err == nil {
+		t.Fatalf("Expected the request error to be returned")
+	}
+	if firstCalls != 1 || secondCalls != 0 || !pool.Status()[0].Healthy {
+		t.Errorf("Request error failed over (%d, %d calls) or marked the endpoint down", firstCalls, secondCalls)
+	}
+}
+
+func TestPool_Balanced(t *testing.T) {
+	var calls [3]int32
+	var urls []string
+	for i := range calls {
+		urls = append(urls, rpcServer(t, http.StatusOK, "ok", &calls[i]).URL)
+	}
+	pool, err := NewPool(urls, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+
+	for i := 0; i < 9; i++ {
+		if err := pool.Balanced(context.Background(), getHealth); err != nil {
+			t.Fatalf("Balanced call failed: %v", err)
+		}
+	}
+	for i, n := range calls {
+		if n != 3 {
+			t.Errorf("Endpoint %d got %d calls, want 3", i, n)
+		}
+	}
+}
+
+func TestRPCURLsFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{"single", map[string]string{"SOLANA_RPC_URL": "https://a"}, []string{"https://a"}},
+		{"comma-separated", map[string]string{"SOLANA_RPC_URL": "https://a, https://b,https://a"}, []string{"https://a", "https://b"}},
+		{"numbered keys", map[string]string{"SOLANA_RPC_URL": "https://a", "SOLANA_RPC_URL_10": "https://c", "SOLANA_RPC_URL_2": "https://b"}, []string{"https://a", "https://b", "https://c"}},
+		{"unset", map[string]string{"SOLANA_RPC_URL": ""}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			got := RPCURLsFromEnv()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
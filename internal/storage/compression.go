@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressedMagic prefixes every file stored compressed so compressed and
+// plain files can share a vault while it is being converted
+var compressedMagic = []byte("SOLVAULT-ZST\x01")
+
+// zstdCodec is shared by every vault; both halves are safe for concurrent use
+var zstdCodec struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func codec() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdCodec.once.Do(func() {
+		zstdCodec.encoder, zstdCodec.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		if zstdCodec.err == nil {
+			zstdCodec.decoder, zstdCodec.err = zstd.NewReader(nil)
+		}
+	})
+	return zstdCodec.encoder, zstdCodec.decoder, zstdCodec.err
+}
+
+// IsCompressed reports whether data was stored compressed
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, compressedMagic)
+}
+
+// CompressedMagicSize is how many leading bytes IsCompressed needs to see
+func CompressedMagicSize() int {
+	return len(compressedMagic)
+}
+
+// Decompress returns the original contents of a compressed file
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	_, decoder, err := codec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decompressor: %w", err)
+	}
+	plain, err := decoder.DecodeAll(data[len(compressedMagic):], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	return plain, nil
+}
+
+// compress stores data as a zstd frame behind compressedMagic
+//
+// Explanation: Media that is already compressed (PNG, JPEG, MP4) is still
+// wrapped; zstd stores incompressible blocks raw for a few bytes each, and
+// a file's state then depends only on the vault settings, so saves never
+// have to guess whether a plain file was skipped or is new.
+func compress(data []byte) ([]byte, error) {
+	encoder, _, err := codec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compressor: %w", err)
+	}
+	out := make([]byte, 0, len(compressedMagic)+len(data)/2)
+	out = append(out, compressedMagic...)
+	return encoder.EncodeAll(data, out), nil
+}
+
+// Compressed reports whether the vault compresses metadata and media
+func (fs *FileStorage) Compressed() bool {
+	return fs.header != nil && fs.header.HasFeature(FeatureCompression)
+}
+
+// EnableCompression switches the vault to compressed storage and
+// compresses the metadata and media of every existing backup. It returns
+// how many files were rewritten.
+func (fs *FileStorage) EnableCompression() (int, error) {
+	if fs.Compressed() {
+		return 0, nil
+	}
+	return fs.reformat(FeatureCompression, true, nil, nil)
+}
+
+// DisableCompression stores every backup uncompressed again
+func (fs *FileStorage) DisableCompression() (int, error) {
+	if !fs.Compressed() {
+		return 0, nil
+	}
+	return fs.reformat(FeatureCompression, false, nil, nil)
+}
+
+// compressible reports whether a vault file is stored compressed in a
+// compressed vault: metadata (current and versioned) and media. nft_data.json
+// and the media manifest stay plain so tools that only need the index and
+// backup record can read them directly.
+func compressible(path string) bool {
+	if filepath.Base(filepath.Dir(path)) == "media" {
+		return true
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "."), stagedSuffix)
+	return name == "metadata.json" || name == RawMetadataFile || (strings.HasPrefix(name, "metadata.v") && strings.HasSuffix(name, ".json"))
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestCompressible(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/v/nfts/m/metadata.json", true},
+		{"/v/nfts/m/.metadata.json.staged", true},
+		{"/v/nfts/m/metadata.v3.json", true},
+		{"/v/nfts/m/metadata.raw.json", true},
+		{"/v/nfts/m/media/image.png", true},
+		{"/v/nfts/m/nft_data.json", false},
+		{"/v/nfts/m/.nft_data.json.staged", false},
+		{"/v/nfts/m/media_manifest.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := compressible(tt.path); got != tt.want {
+			t.Errorf("compressible(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFileStorage_Compression(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	nft.Metadata.Description = string(bytes.Repeat([]byte("a very repetitive description "), 50))
+	nft.MediaFiles = []*fetcher.MediaFile{{Filename: "image.svg"}}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	nftDir := store.NFTDir(wallet, nft.MintAddress)
+	image := bytes.Repeat([]byte("<rect/>"), 200)
+	if err := os.WriteFile(filepath.Join(nftDir, "media", "image.svg"), image, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	converted, err := store.EnableCompression()
+	if err != nil {
+		t.Fatalf("Failed to enable compression: %v", err)
+	}
+	if converted != 2 { // metadata.json and media/image.svg
+		t.Errorf("Compressed %d file(s), want 2", converted)
+	}
+
+	raw := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(nftDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return data
+	}
+	if metadata := raw("metadata.json"); !IsCompressed(metadata) || len(metadata) >= len(nft.Metadata.Description) {
+		t.Errorf("metadata.json is not compressed (%d bytes)", len(metadata))
+	}
+	if IsCompressed(raw("nft_data.json")) {
+		t.Errorf("nft_data.json should stay plain")
+	}
+	if media, err := store.ReadFile(filepath.Join(nftDir, "media", "image.svg")); err != nil || !bytes.Equal(media, image) {
+		t.Errorf("Media did not decompress: %v", err)
+	}
+
+	// A metadata change is saved, snapshotted and read back through compression
+	nft.Metadata.Name = "Revealed"
+	if err := os.WriteFile(filepath.Join(nftDir, "media", "video.mp4"), []byte("frames"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	for _, name := range []string{"metadata.json", "metadata.v1.json", "metadata.v2.json", filepath.Join("media", "video.mp4")} {
+		if !IsCompressed(raw(name)) {
+			t.Errorf("%s is not compressed", name)
+		}
+	}
+	first, err := store.MetadataAtVersion(wallet, nft.MintAddress, 1)
+	if err != nil || first.Name != "Shared NFT" {
+		t.Errorf("Failed to read version 1 through compression: %+v (%v)", first, err)
+	}
+
+	// Encryption stacks on top and keeps the content compressed inside
+	params, c, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	if _, err := store.EnableEncryption(params, c); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+	inner, err := c.Open(raw("metadata.json"))
+	if err != nil || !IsCompressed(inner) {
+		t.Errorf("Encrypted metadata should hold compressed data (%v)", err)
+	}
+
+	if _, err := store.DisableCompression(); err != nil {
+		t.Fatalf("Failed to disable compression: %v", err)
+	}
+	if inner, _ := c.Open(raw("metadata.json")); IsCompressed(inner) {
+		t.Errorf("metadata.json is still compressed")
+	}
+	stored, err := store.GetNFT(ctx, wallet, nft.MintAddress)
+	if err != nil || stored.NFTInfo.Metadata.Name != "Revealed" {
+		t.Errorf("Failed to read NFT after decompressing: %v", err)
+	}
+	if header, _ := ReadHeader(store.BaseDir()); header.HasFeature(FeatureCompression) {
+		t.Errorf("Header still marks the vault compressed")
+	}
+}
@@ -0,0 +1,40 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/storage/storagetest"
+)
+
+func TestFileStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+		backend, err := storage.NewFileStorage(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create storage: %v", err)
+		}
+		return backend
+	})
+}
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+		backend, err := storage.NewMemoryStorage()
+		if err != nil {
+			t.Fatalf("Failed to create storage: %v", err)
+		}
+		t.Cleanup(func() { backend.Discard() })
+		return backend
+	})
+}
+
+func TestReplicatedStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+		specs := []storage.BackendSpec{{Name: "file"}, {Name: "file", Location: t.TempDir()}}
+		backend, err := storage.OpenVault(specs, t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to open vault: %v", err)
+		}
+		return backend
+	})
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// seedCustody returns the custody history of a stored NFT, synthesizing a
+// single period for backups written before custody was tracked
+func seedCustody(stored *StoredNFT) []CustodyPeriod {
+	if len(stored.Custody) > 0 || stored.NFTInfo == nil {
+		return stored.Custody
+	}
+
+	period := CustodyPeriod{
+		Wallet: stored.NFTInfo.Owner.String(),
+		From:   stored.StoredAt,
+		Status: StatusHeld,
+	}
+	if stored.Status != "" && stored.Status != StatusHeld {
+		ended := stored.StatusChangedAt
+		period.To, period.Status = &ended, stored.Status
+	}
+	return []CustodyPeriod{period}
+}
+
+// openCustody makes sure wallet has an open custody period starting at now
+func openCustody(custody []CustodyPeriod, wallet string, now time.Time) []CustodyPeriod {
+	for _, period := range custody {
+		if period.Wallet == wallet && period.To == nil {
+			return custody
+		}
+	}
+	return append(custody, CustodyPeriod{Wallet: wallet, From: now, Status: StatusHeld})
+}
+
+// closeCustody ends wallet's open custody period with the given status and
+// the transaction that ended it, if known
+func closeCustody(custody []CustodyPeriod, wallet string, status HoldingStatus, departure Departure, now time.Time) []CustodyPeriod {
+	for i := range custody {
+		if custody[i].Wallet == wallet && custody[i].To == nil {
+			ended := now
+			custody[i].To, custody[i].Status = &ended, status
+			custody[i].Signature, custody[i].Recipient = departure.Signature, departure.Recipient
+		}
+	}
+	return custody
+}
+
+// linkedNFTs loads the records other wallets store on behalf of walletAddr
+func (fs *FileStorage) linkedNFTs(walletAddr solanago.PublicKey) []*StoredNFT {
+	var nfts []*StoredNFT
+	for _, entry := range fs.index.List() {
+		if entry.Wallet != walletAddr.String() || entry.PrimaryWallet == "" {
+			continue
+		}
+
+		path := filepath.Join(fs.baseDir, "wallets", entry.PrimaryWallet, "nfts", entry.DirName, "nft_data.json")
+		var storedNFT StoredNFT
+		if err := fs.loadJSON(path, &storedNFT); err != nil {
+			fmt.Printf("⚠️  Warning: failed to load linked backup %s: %v\n", path, err)
+			continue
+		}
+		nfts = append(nfts, &storedNFT)
+	}
+	return nfts
+}
+
+// MergeDuplicates folds separate backups of the same mint held under
+// different wallets into one record with a combined custody history,
+// returning the number of duplicate directories removed
+//
+// Explanation: Vaults written before custody linking may contain one full
+// copy per wallet. The earliest backup is kept as the primary record since
+// it is the closest to the original media.
+func (fs *FileStorage) MergeDuplicates(ctx context.Context) (int, error) {
+	byMint := make(map[string][]*IndexEntry)
+	for _, entry := range fs.index.List() {
+		if entry.PrimaryWallet == "" {
+			byMint[entry.Mint] = append(byMint[entry.Mint], entry)
+		}
+	}
+
+	merged := 0
+	for mint, entries := range byMint {
+		if len(entries) < 2 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return merged, err
+		}
+
+		records := make([]*StoredNFT, len(entries))
+		for i, entry := range entries {
+			var storedNFT StoredNFT
+			path := filepath.Join(fs.baseDir, "wallets", entry.Wallet, "nfts", entry.DirName, "nft_data.json")
+			if err := fs.loadJSON(path, &storedNFT); err != nil {
+				return merged, fmt.Errorf("failed to load %s: %w", path, err)
+			}
+			records[i] = &storedNFT
+		}
+
+		// Earliest backup wins
+		primary := 0
+		for i := range records {
+			if records[i].StoredAt.Before(records[primary].StoredAt) {
+				primary = i
+			}
+		}
+
+		var custody []CustodyPeriod
+		for _, record := range records {
+			custody = append(custody, seedCustody(record)...)
+		}
+		sort.SliceStable(custody, func(i, j int) bool { return custody[i].From.Before(custody[j].From) })
+
+		kept := records[primary]
+		kept.Custody = custody
+		kept.UpdatedAt = time.Now()
+		primaryEntry := entries[primary]
+		primaryDir := filepath.Join(fs.baseDir, "wallets", primaryEntry.Wallet, "nfts", primaryEntry.DirName)
+		if err := fs.saveJSON(filepath.Join(primaryDir, "nft_data.json"), kept); err != nil {
+			return merged, fmt.Errorf("failed to save merged record for %s: %w", mint, err)
+		}
+		if err := fs.refreshIntegrity(primaryDir, "nft_data.json"); err != nil {
+			return merged, err
+		}
+
+		for i, entry := range entries {
+			if i == primary {
+				continue
+			}
+			dir := filepath.Join(fs.baseDir, "wallets", entry.Wallet, "nfts", entry.DirName)
+			if err := os.RemoveAll(dir); err != nil {
+				return merged, fmt.Errorf("failed to remove duplicate %s: %w", dir, err)
+			}
+
+			link := *entry
+			link.DirName, link.PrimaryWallet = primaryEntry.DirName, primaryEntry.Wallet
+			fs.index.Put(&link)
+			merged++
+		}
+	}
+
+	if merged > 0 {
+		if err := fs.index.Save(); err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func custodyNFT(mint, owner solanago.PublicKey) *fetcher.NFTInfo {
+	return &fetcher.NFTInfo{
+		MintAddress:  mint,
+		TokenAccount: solanago.NewWallet().PublicKey(),
+		Owner:        owner,
+		Supply:       1,
+		FetchedAt:    time.Now(),
+		Metadata:     &fetcher.NFTMetadata{Name: "Shared NFT"},
+	}
+}
+
+func countNFTDirs(t *testing.T, baseDir string) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(baseDir, "wallets", "*", "nfts", "*"))
+	if err != nil {
+		t.Fatalf("Failed to glob NFT directories: %v", err)
+	}
+	return len(matches)
+}
+
+// TestFileStorage_SharedCustody checks that a mint moving between vault
+// wallets is stored once with a custody timeline
+func TestFileStorage_SharedCustody(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := NewFileStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	walletA := solanago.NewWallet().PublicKey()
+	walletB := solanago.NewWallet().PublicKey()
+	mint := solanago.NewWallet().PublicKey()
+
+	if err := storage.SaveNFT(ctx, custodyNFT(mint, walletA)); err != nil {
+		t.Fatalf("Failed to save NFT for wallet A: %v", err)
+	}
+	if err := storage.SetStatus(ctx, walletA, mint, StatusTransferred); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	if err := storage.SaveNFT(ctx, custodyNFT(mint, walletB)); err != nil {
+		t.Fatalf("Failed to save NFT for wallet B: %v", err)
+	}
+
+	if got := countNFTDirs(t, tempDir); got != 1 {
+		t.Fatalf("Expected 1 NFT directory, got %d", got)
+	}
+
+	stored, err := storage.GetNFT(ctx, walletB, mint)
+	if err != nil {
+		t.Fatalf("Failed to get NFT via linked wallet: %v", err)
+	}
+	if len(stored.Custody) != 2 {
+		t.Fatalf("Expected 2 custody periods, got %d", len(stored.Custody))
+	}
+	first, second := stored.Custody[0], stored.Custody[1]
+	if first.Wallet != walletA.String() || first.To == nil || first.Status != StatusTransferred {
+		t.Errorf("Unexpected first period: %+v", first)
+	}
+	if second.Wallet != walletB.String() || second.To != nil || second.Status != StatusHeld {
+		t.Errorf("Unexpected second period: %+v", second)
+	}
+	if stored.Status != StatusHeld {
+		t.Errorf("Status = %q, want held while wallet B holds it", stored.Status)
+	}
+
+	nfts, err := storage.ListNFTs(ctx, walletB)
+	if err != nil {
+		t.Fatalf("Failed to list NFTs: %v", err)
+	}
+	if len(nfts) != 1 {
+		t.Errorf("Expected wallet B to list 1 NFT, got %d", len(nfts))
+	}
+
+	if err := storage.DeleteNFT(ctx, walletA, mint); err == nil {
+		t.Error("Expected deleting the primary record to fail while linked")
+	}
+
+	// Rebuilding from disk restores the link
+	if _, err := storage.RebuildIndex(ctx); err != nil {
+		t.Fatalf("Failed to rebuild index: %v", err)
+	}
+	entry := storage.Index().Get(walletB.String(), mint.String())
+	if entry == nil || entry.PrimaryWallet != walletA.String() {
+		t.Fatalf("Expected rebuilt link to wallet A, got %+v", entry)
+	}
+
+	if err := storage.DeleteNFT(ctx, walletB, mint); err != nil {
+		t.Fatalf("Failed to delete linked record: %v", err)
+	}
+	if got := countNFTDirs(t, tempDir); got != 1 {
+		t.Errorf("Deleting a link removed files: %d directories left", got)
+	}
+}
+
+// TestFileStorage_MergeDuplicates folds pre-existing per-wallet copies
+func TestFileStorage_MergeDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := NewFileStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx := context.Background()
+	walletA := solanago.NewWallet().PublicKey()
+	walletB := solanago.NewWallet().PublicKey()
+	mint := solanago.NewWallet().PublicKey()
+
+	// Simulate an older vault that stored a full copy per wallet
+	if err := storage.SaveNFT(ctx, custodyNFT(mint, walletA)); err != nil {
+		t.Fatalf("Failed to save NFT for wallet A: %v", err)
+	}
+	entryA := *storage.Index().Get(walletA.String(), mint.String())
+	storage.Index().Remove(walletA.String(), mint.String())
+	if err := storage.SaveNFT(ctx, custodyNFT(mint, walletB)); err != nil {
+		t.Fatalf("Failed to save NFT for wallet B: %v", err)
+	}
+	storage.Index().Put(&entryA)
+
+	if got := countNFTDirs(t, tempDir); got != 2 {
+		t.Fatalf("Expected 2 duplicate directories, got %d", got)
+	}
+
+	merged, err := storage.MergeDuplicates(ctx)
+	if err != nil {
+		t.Fatalf("Failed to merge duplicates: %v", err)
+	}
+	if merged != 1 {
+		t.Errorf("Expected 1 merged duplicate, got %d", merged)
+	}
+	if got := countNFTDirs(t, tempDir); got != 1 {
+		t.Errorf("Expected 1 directory after merge, got %d", got)
+	}
+
+	stored, err := storage.GetNFT(ctx, walletB, mint)
+	if err != nil {
+		t.Fatalf("Failed to get merged NFT: %v", err)
+	}
+	if len(stored.Custody) != 2 {
+		t.Errorf("Expected 2 custody periods after merge, got %d", len(stored.Custody))
+	}
+
+}
@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/thumbnail"
+)
+
+// unlock prepares the cipher of an encrypted vault from the environment
+//
+// Explanation: A missing key is not an error here. The index is never
+// encrypted, so commands like list still work; reading or writing an
+// encrypted file is what fails, with crypt.ErrLocked explaining what to set.
+// A key that is configured but wrong fails straight away.
+func (fs *FileStorage) unlock() error {
+	if !fs.header.HasFeature(FeatureEncryption) {
+		return nil
+	}
+	c, err := fs.header.Encryption.FromEnv()
+	if errors.Is(err, crypt.ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unlock vault %s: %w", fs.baseDir, err)
+	}
+	fs.cipher = c
+	return nil
+}
+
+// Encrypted reports whether the vault encrypts its backups at rest
+func (fs *FileStorage) Encrypted() bool {
+	return fs.header != nil && fs.header.HasFeature(FeatureEncryption)
+}
+
+// Locked reports whether the vault is encrypted but no key was configured
+func (fs *FileStorage) Locked() bool {
+	return fs.Encrypted() && fs.cipher == nil
+}
+
+// encode prepares file contents for disk: compressed if the vault
+// compresses this kind of file, then sealed in an encrypted vault
+func (fs *FileStorage) encode(path string, data []byte) ([]byte, error) {
+	if fs.Compressed() && compressible(path) {
+		var err error
+		if data, err = compress(data); err != nil {
+			return nil, err
+		}
+	}
+	if !fs.Encrypted() {
+		return data, nil
+	}
+	if fs.cipher == nil {
+		return nil, crypt.ErrLocked
+	}
+	return fs.cipher.Seal(data)
+}
+
+// decode returns the original contents of a file read from disk. Plain
+// files pass through, so a vault part way through conversion stays readable.
+func (fs *FileStorage) decode(data []byte) ([]byte, error) {
+	if crypt.IsSealed(data) {
+		if fs.cipher == nil {
+			return nil, crypt.ErrLocked
+		}
+		var err error
+		if data, err = fs.cipher.Open(data); err != nil {
+			return nil, err
+		}
+	}
+	return Decompress(data)
+}
+
+// Decode returns the original contents of a vault file read from somewhere
+// other than the vault, such as a mirror's copy of it
+func (fs *FileStorage) Decode(data []byte) ([]byte, error) {
+	return fs.decode(data)
+}
+
+// ReadFile reads a file from the vault, decrypting and decompressing it as
+// needed
+func (fs *FileStorage) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.decode(data)
+}
+
+// EnableEncryption switches the vault to encrypted storage and encrypts
+// every existing backup in place. It returns how many files were encrypted.
+func (fs *FileStorage) EnableEncryption(params *crypt.Params, c *crypt.Cipher) (int, error) {
+	if fs.ephemeral {
+		return 0, errors.New("temporary vaults cannot be encrypted")
+	}
+	if fs.Encrypted() {
+		return 0, errors.New("vault is already encrypted")
+	}
+	return fs.reformat(FeatureEncryption, true, params, c)
+}
+
+// DisableEncryption decrypts every backup in place and marks the vault as
+// unencrypted. It returns how many files were decrypted.
+func (fs *FileStorage) DisableEncryption() (int, error) {
+	if !fs.Encrypted() {
+		return 0, errors.New("vault is not encrypted")
+	}
+	if fs.cipher == nil {
+		return 0, crypt.ErrLocked
+	}
+	return fs.reformat(FeatureEncryption, false, nil, nil)
+}
+
+// reformat turns an on-disk format feature on or off and rewrites every
+// backup file to match
+//
+// Explanation: Files say how they are stored (sealed and compressed files
+// carry a prefix), so a vault part way through conversion reads fine. The
+// header only has to announce a feature before any file uses it: it is
+// written first when turning a feature on, and last when turning one off.
+// Running the same change again finishes an interrupted conversion.
+func (fs *FileStorage) reformat(feature string, on bool, params *crypt.Params, c *crypt.Cipher) (int, error) {
+	header := *fs.header
+	header.Features = []string{}
+	for _, f := range fs.header.Features {
+		if f != feature {
+			header.Features = append(header.Features, f)
+		}
+	}
+	if on {
+		header.Features = append(header.Features, feature)
+	}
+	if feature == FeatureEncryption {
+		header.Encryption = params
+	}
+
+	if on {
+		if err := writeHeader(fs.baseDir, &header); err != nil {
+			return 0, err
+		}
+		fs.header = &header
+		if c != nil {
+			fs.cipher = c
+		}
+		return fs.convertFiles(fs.repackFile)
+	}
+
+	fs.header = &header
+	converted, err := fs.convertFiles(fs.repackFile)
+	if err != nil {
+		return converted, err
+	}
+	if err := writeHeader(fs.baseDir, &header); err != nil {
+		return converted, err
+	}
+	if feature == FeatureEncryption {
+		fs.cipher = nil
+	}
+	return converted, nil
+}
+
+// convertFiles applies convert to every encryptable file in the vault
+func (fs *FileStorage) convertFiles(convert func(path string) (bool, error)) (int, error) {
+	dirs, err := filepath.Glob(filepath.Join(fs.baseDir, "wallets", "*", "nfts", "*"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list NFT directories: %w", err)
+	}
+	tokenDirs, err := filepath.Glob(filepath.Join(fs.baseDir, TokensDir, "*", "*"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list token directories: %w", err)
+	}
+	dirs = append(dirs, tokenDirs...)
+
+	converted := 0
+	for _, dir := range dirs {
+		if err := recoverTransaction(dir); err != nil {
+			return converted, err
+		}
+		paths, err := BackupFiles(dir)
+		if err != nil {
+			return converted, err
+		}
+		for _, path := range paths {
+			changed, err := convert(path)
+			if err != nil {
+				return converted, err
+			}
+			if changed {
+				converted++
+			}
+		}
+	}
+	return converted, nil
+}
+
+// packMedia encrypts and compresses media downloaded into an NFT directory
+// since the last save, as the vault's settings require
+func (fs *FileStorage) packMedia(nftDir string) error {
+	if !fs.Encrypted() && !fs.Compressed() {
+		return nil
+	}
+	for _, name := range mediaNames(nftDir) {
+		path := filepath.Join(nftDir, "media", filepath.FromSlash(name))
+
+		// Media written by an earlier save is already packed; only its
+		// prefix needs reading to tell
+		head, err := readPrefix(path, crypt.MagicSize()+CompressedMagicSize())
+		if err != nil {
+			return err
+		}
+		if (fs.Encrypted() && crypt.IsSealed(head)) || (!fs.Encrypted() && IsCompressed(head)) {
+			continue
+		}
+		if _, err := fs.repackFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mediaNames lists the media files of an NFT directory, relative to its
+// media directory: the downloaded files and their thumbnails. Hidden
+// files, such as partial downloads, are left out.
+func mediaNames(nftDir string) []string {
+	var names []string
+	for _, sub := range []string{"", thumbnail.Dir} {
+		entries, err := os.ReadDir(filepath.Join(nftDir, "media", sub))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				names = append(names, path.Join(sub, entry.Name()))
+			}
+		}
+	}
+	return names
+}
+
+// readPrefix reads up to n bytes from the start of a file
+func readPrefix(path string, n int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+	head := make([]byte, n)
+	read, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return head[:read], nil
+}
+
+// BackupFiles lists the backup files of one NFT directory: its data,
+// metadata (current, raw and versioned), media manifest, media and
+// thumbnails. These are the files encryption and compression rewrite and
+// mirrors upload.
+func BackupFiles(dir string) ([]string, error) {
+	var paths []string
+	for _, name := range []string{"nft_data.json", TokenDataFile, "metadata.json", RawMetadataFile, "media_manifest.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+
+	snapshots, err := filepath.Glob(filepath.Join(dir, "metadata.v*.json"))
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, snapshots...)
+
+	for _, name := range mediaNames(dir) {
+		paths = append(paths, filepath.Join(dir, "media", filepath.FromSlash(name)))
+	}
+	return paths, nil
+}
+
+// repackFile rewrites a file to match the vault's encryption and
+// compression settings, reporting whether it had to change
+func (fs *FileStorage) repackFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	sealed, inner := crypt.IsSealed(data), data
+	if sealed {
+		if fs.cipher == nil {
+			return false, crypt.ErrLocked
+		}
+		if inner, err = fs.cipher.Open(data); err != nil {
+			return false, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+	}
+	if sealed == fs.Encrypted() && IsCompressed(inner) == (fs.Compressed() && compressible(path)) {
+		return false, nil
+	}
+
+	plain, err := Decompress(inner)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	packed, err := fs.encode(path, plain)
+	if err != nil {
+		return false, err
+	}
+	return true, fs.replaceFile(path, packed)
+}
+
+// replaceFile swaps a file's contents via a temp file so a crash never
+// leaves it half converted
+func (fs *FileStorage) replaceFile(path string, data []byte) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, fs.permissions); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	nft.MediaFiles = []*fetcher.MediaFile{{Filename: "image.png"}}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	nftDir := store.NFTDir(wallet, nft.MintAddress)
+	image := []byte("\x89PNG secret pixels")
+	if err := os.WriteFile(filepath.Join(nftDir, "media", "image.png"), image, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	params, c, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	converted, err := store.EnableEncryption(params, c)
+	if err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+	if converted != 4 { // nft_data.json, metadata.json, media_manifest.json, media/image.png
+		t.Errorf("Encrypted %d file(s), want 4", converted)
+	}
+	for _, name := range []string{"nft_data.json", "metadata.json", "media_manifest.json", filepath.Join("media", "image.png")} {
+		data, err := os.ReadFile(filepath.Join(nftDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if !crypt.IsSealed(data) || bytes.Contains(data, []byte("Shared NFT")) {
+			t.Errorf("%s is not encrypted", name)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		wantOpen   error // From NewFileStorage
+		wantGet    error // From GetNFT
+	}{
+		{"no passphrase", "", nil, crypt.ErrLocked},
+		{"wrong passphrase", "battery staple", crypt.ErrWrongKey, nil},
+		{"right passphrase", "correct horse", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(crypt.EnvPassphrase, tt.passphrase)
+			reopened, err := NewFileStorage(dir)
+			if tt.wantOpen != nil {
+				if !errors.Is(err, tt.wantOpen) {
+					t.Fatalf("Got %v opening the vault, want %v", err, tt.wantOpen)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to open vault: %v", err)
+			}
+
+			stored, err := reopened.GetNFT(ctx, wallet, nft.MintAddress)
+			if tt.wantGet != nil {
+				if !errors.Is(err, tt.wantGet) {
+					t.Fatalf("Got %v reading the NFT, want %v", err, tt.wantGet)
+				}
+				if err := reopened.SaveNFT(ctx, nft); !errors.Is(err, crypt.ErrLocked) {
+					t.Errorf("Got %v saving to a locked vault, want %v", err, crypt.ErrLocked)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			if stored.NFTInfo.Metadata.Name != "Shared NFT" {
+				t.Errorf("Got name %q, want %q", stored.NFTInfo.Metadata.Name, "Shared NFT")
+			}
+			media, err := reopened.ReadFile(filepath.Join(nftDir, "media", "image.png"))
+			if err != nil || !bytes.Equal(media, image) {
+				t.Errorf("Media did not decrypt: %q (%v)", media, err)
+			}
+		})
+	}
+
+	// New media is encrypted by the save that records it
+	if err := os.WriteFile(filepath.Join(nftDir, "media", "video.mp4"), []byte("frames"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(nftDir, "media", "video.mp4")); !crypt.IsSealed(data) {
+		t.Errorf("Media added after encryption was not encrypted")
+	}
+
+	if _, err := store.DisableEncryption(); err != nil {
+		t.Fatalf("Failed to disable encryption: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(nftDir, "media", "image.png")); !bytes.Equal(data, image) {
+		t.Errorf("Media was not decrypted: %q", data)
+	}
+	if header, err := ReadHeader(dir); err != nil || header.HasFeature(FeatureEncryption) || header.Encryption != nil {
+		t.Errorf("Header still marks the vault encrypted: %+v (%v)", header, err)
+	}
+}
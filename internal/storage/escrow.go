@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// SetEscrow records an NFT's custody state on its backup, or clears it when
+// escrow is nil
+//
+// Explanation: Like SetRarity this leaves UpdatedAt and the metadata
+// versions alone. Staking or listing an NFT changes where it sits, not what
+// was backed up.
+func (fs *FileStorage) SetEscrow(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, escrow *fetcher.Escrow) error {
+	nftDataPath := filepath.Join(fs.buildNFTPath(walletAddr, mintAddr), "nft_data.json")
+
+	var storedNFT StoredNFT
+	if err := fs.loadJSON(nftDataPath, &storedNFT); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("NFT not found: %s", mintAddr.String())
+		}
+		return fmt.Errorf("failed to load NFT data: %w", err)
+	}
+	if storedNFT.NFTInfo == nil {
+		return fmt.Errorf("NFT data missing for %s", mintAddr.String())
+	}
+
+	storedNFT.NFTInfo.Escrow = escrow
+	if err := fs.saveJSON(nftDataPath, &storedNFT); err != nil {
+		return fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	return fs.refreshIntegrity(filepath.Dir(nftDataPath), "nft_data.json")
+}
@@ -8,10 +8,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/NazWright/solvault/internal/crypt"
 	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/fsutil"
+	"github.com/NazWright/solvault/internal/telemetry"
 	solanago "github.com/gagliardetto/solana-go"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // FileStorage implements StorageBackend using local filesystem
@@ -22,15 +27,38 @@ import (
 //	└── wallets/
 //	    └── {wallet_address}/
 //	        └── nfts/
-//	            └── {mint_address}/
+//	            └── {mint_address}/       (or {nft_name}/ with LayoutName)
 //	                ├── nft_data.json     (StoredNFT struct)
 //	                ├── metadata.json     (off-chain metadata)
 //	                └── media/            (images, videos, etc.)
+//
+// index.json at the root maps every wallet/mint pair to its directory.
+// When several vault wallets hold the same mint over time, the backup lives
+// under the wallet that held it first and the others link to it.
 type FileStorage struct {
-	baseDir     string      // Root directory for all backups
-	permissions fs.FileMode // File permissions for created files
+	baseDir     string           // Root directory for all backups
+	permissions fs.FileMode      // File permissions for created files
+	layout      Layout           // How NFT directories are named
+	index       *Index           // Mint to directory mapping
+	header      *VaultHeader     // Contents of vault.json
+	cipher      *crypt.Cipher    // Set when an encrypted vault is unlocked
+	ephemeral   bool             // Temporary vault removed by Discard
+	replicas    []replicaBackend // Receive a copy of every save (see OpenVault)
+	limits      Limits           // What saves may add (see SetLimits)
 }
 
+// shortDirNameLength is how much of the mint address is used for the
+// shortened fallback directory name
+const shortDirNameLength = 8
+
+// Layout controls how NFT directories are named inside a wallet
+type Layout string
+
+const (
+	LayoutMint Layout = "mint" // nfts/{mint_address}/ (default)
+	LayoutName Layout = "name" // nfts/{safe NFT name}/, falls back to the mint when unnamed
+)
+
 // NewFileStorage creates a new file-based storage backend
 func NewFileStorage(baseDir string) (*FileStorage, error) {
 	// Explanation: We create the base directory structure upfront
@@ -39,72 +67,309 @@ func NewFileStorage(baseDir string) (*FileStorage, error) {
 		return nil, fmt.Errorf("failed to create base directory %s: %w", baseDir, err)
 	}
 
-	return &FileStorage{
+	// Explanation: vault.json is read before anything else so a vault
+	// written by a newer SolVault is refused instead of misread
+	header, err := EnsureHeader(baseDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := LoadIndex(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileStorage{
 		baseDir:     baseDir,
 		permissions: 0644, // Read/write for owner, read for others
+		layout:      LayoutMint,
+		index:       index,
+		header:      header,
+	}
+	if err := store.unlock(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// MemoryVault is the vault path that selects an ephemeral in-memory vault
+const MemoryVault = ":memory:"
+
+// NewMemoryStorage creates a throwaway vault for exploratory use
+//
+// Explanation: Media still needs somewhere to land, so files go to a temp
+// directory while the index stays in memory. Nothing touches the real
+// archive, and Discard removes the temp directory when the session ends.
+func NewMemoryStorage() (*FileStorage, error) {
+	baseDir, err := os.MkdirTemp("", "solvault-memory-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary vault: %w", err)
+	}
+
+	header, err := EnsureHeader(baseDir, "")
+	if err != nil {
+		os.RemoveAll(baseDir)
+		return nil, err
+	}
+
+	return &FileStorage{
+		baseDir:     baseDir,
+		permissions: 0644,
+		layout:      LayoutMint,
+		index:       NewMemoryIndex(),
+		header:      header,
+		ephemeral:   true,
 	}, nil
 }
 
-// SaveNFT stores NFT information to the filesystem
+// IsEphemeral reports whether the vault is discarded at the end of the session
+func (fs *FileStorage) IsEphemeral() bool {
+	return fs.ephemeral
+}
+
+// Discard deletes an ephemeral vault's files. It refuses to touch a
+// persistent vault.
+func (fs *FileStorage) Discard() error {
+	if !fs.ephemeral {
+		return fmt.Errorf("refusing to discard persistent vault %s", fs.baseDir)
+	}
+	if err := os.RemoveAll(fs.baseDir); err != nil {
+		return fmt.Errorf("failed to remove temporary vault: %w", err)
+	}
+	return nil
+}
+
+// Header returns the vault's metadata header
+func (fs *FileStorage) Header() *VaultHeader {
+	return fs.header
+}
+
+// RecordCluster stores the Solana cluster in the header of a vault that
+// has none yet
+func (fs *FileStorage) RecordCluster(cluster string) error {
+	header, err := EnsureHeader(fs.baseDir, cluster)
+	if err != nil {
+		return err
+	}
+	fs.header = header
+	return nil
+}
+
+// SetLayout chooses how new NFT directories are named.
+// Existing backups keep the directory recorded for them in the index.
+func (fs *FileStorage) SetLayout(layout Layout) {
+	fs.layout = layout
+}
+
+// Index returns the vault index
+func (fs *FileStorage) Index() *Index {
+	return fs.index
+}
+
+// SaveNFT stores NFT information to the filesystem in a single transaction.
+// The backup is recorded as complete, clearing any in-progress mark.
 func (fs *FileStorage) SaveNFT(ctx context.Context, nftInfo *fetcher.NFTInfo) error {
+	return fs.saveNFT(ctx, nftInfo, false)
+}
+
+// SaveNFTInProgress stores NFT information like SaveNFT, but marks the
+// backup in progress in the index until SaveNFT or CompleteBackup is called.
+// Callers that save the record first and download media into its directory
+// afterwards use it so an interrupted backup is found and redone.
+func (fs *FileStorage) SaveNFTInProgress(ctx context.Context, nftInfo *fetcher.NFTInfo) error {
+	return fs.saveNFT(ctx, nftInfo, true)
+}
+
+func (fs *FileStorage) saveNFT(ctx context.Context, nftInfo *fetcher.NFTInfo, inProgress bool) (err error) {
+	ctx, span := telemetry.Start(ctx, "storage save",
+		attribute.String("solvault.wallet", nftInfo.Owner.String()),
+		attribute.String("solvault.mint", nftInfo.MintAddress.String()),
+		attribute.Bool("solvault.in_progress", inProgress))
+	defer func() { telemetry.End(span, err) }()
+
+	txn, err := fs.beginSave(ctx, nftInfo)
+	if err != nil {
+		return err
+	}
+	txn.entry.InProgress = inProgress
+	if err := txn.Commit(); err != nil {
+		txn.Rollback()
+		return err
+	}
+	return nil
+}
+
+// CompleteBackup clears the in-progress mark left by SaveNFTInProgress
+func (fs *FileStorage) CompleteBackup(walletAddr, mintAddr solanago.PublicKey) error {
+	entry := fs.index.Get(walletAddr.String(), mintAddr.String())
+	if entry == nil || !entry.InProgress {
+		return nil
+	}
+	completed := *entry
+	completed.InProgress = false
+	fs.index.Put(&completed)
+	if err := fs.index.Save(); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+	return nil
+}
+
+// BeginSave stages every file of an NFT backup without touching the live
+// copy. Nothing is visible until Commit.
+func (fs *FileStorage) BeginSave(ctx context.Context, nftInfo *fetcher.NFTInfo) (Transaction, error) {
+	txn, err := fs.beginSave(ctx, nftInfo)
+	if err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func (fs *FileStorage) beginSave(ctx context.Context, nftInfo *fetcher.NFTInfo) (*fileTransaction, error) {
 	// Explanation: We build a path that's organized and human-readable
 	// wallet/nfts/mint/ structure makes it easy to browse backups
-	nftDir := fs.buildNFTPath(nftInfo.Owner, nftInfo.MintAddress)
+	entry, err := fs.indexEntryFor(nftInfo)
+	if err != nil {
+		return nil, err
+	}
+	nftDir := fs.EntryDir(entry)
+
+	// A new backup is built in the staging area, replacing whatever an
+	// earlier interrupted first save left there
+	txnDir, target := nftDir, ""
+	_, statErr := os.Stat(nftDir)
+	if err := fs.checkLimits(entry.Wallet, os.IsNotExist(statErr)); err != nil {
+		return nil, err
+	}
+	if os.IsNotExist(statErr) {
+		txnDir, target = fs.stagingDir(entry), nftDir
+		if err := os.RemoveAll(txnDir); err != nil {
+			return nil, fmt.Errorf("failed to clear staged backup: %w", err)
+		}
+	}
 
 	// Create directory structure
-	if err := os.MkdirAll(nftDir, 0755); err != nil {
-		return fmt.Errorf("failed to create NFT directory %s: %w", nftDir, err)
+	if err := os.MkdirAll(txnDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create NFT directory %s: %w", txnDir, fsutil.WrapPathError(txnDir, err))
+	}
+
+	// Finish or discard whatever an interrupted save left behind so the
+	// previous version we read below is complete
+	if err := recoverTransaction(txnDir); err != nil {
+		return nil, err
+	}
+
+	// Media downloaded since the last save is encrypted or compressed
+	// before the save that records it
+	if err := fs.packMedia(txnDir); err != nil {
+		return nil, err
 	}
 
 	// Create stored NFT with metadata
 	storedNFT := &StoredNFT{
-		NFTInfo:    nftInfo,
-		StoredAt:   time.Now(),
-		UpdatedAt:  time.Now(),
-		Version:    1, // Start with version 1
-		BackupPath: nftDir,
-		Verified:   false,       // Will be verified later
-		LastCheck:  time.Time{}, // Not checked yet
+		NFTInfo:         nftInfo,
+		SchemaVersion:   RecordSchemaVersion,
+		StoredAt:        time.Now(),
+		UpdatedAt:       time.Now(),
+		Version:         1, // Start with version 1
+		BackupPath:      nftDir,
+		Verified:        false,       // Will be verified later
+		LastCheck:       time.Time{}, // Not checked yet
+		Status:          StatusHeld,
+		StatusChangedAt: time.Now(),
+	}
+
+	fingerprint := MetadataFingerprint(nftInfo)
+	storedNFT.Versions = []MetadataVersion{{Version: 1, URI: nftInfo.MetadataURI, Fingerprint: fingerprint, SavedAt: storedNFT.StoredAt}}
+
+	// Re-saves keep the original backup date, custody history, mirror
+	// records and rarity, and only start a new version when the URI or off-chain JSON
+	// changed
+	nftDataPath := filepath.Join(txnDir, "nft_data.json")
+	var previous StoredNFT
+	hasPrevious := fs.loadJSON(nftDataPath, &previous) == nil && previous.NFTInfo != nil
+	metadataChanged := hasPrevious && MetadataFingerprint(previous.NFTInfo) != fingerprint
+	if hasPrevious {
+		storedNFT.StoredAt = previous.StoredAt
+		storedNFT.Version = previous.Version
+		storedNFT.Versions = seedVersions(&previous)
+		if metadataChanged {
+			storedNFT.Version = previous.Version + 1
+			storedNFT.Versions = append(storedNFT.Versions, MetadataVersion{
+				Version:     storedNFT.Version,
+				URI:         nftInfo.MetadataURI,
+				Fingerprint: fingerprint,
+				SavedAt:     storedNFT.UpdatedAt,
+			})
+		}
+		storedNFT.Custody = seedCustody(&previous)
+		storedNFT.Remote = previous.Remote
+		storedNFT.Rarity = previous.Rarity
+		if previous.Status == StatusHeld || previous.Status == "" {
+			storedNFT.StatusChangedAt = previous.StatusChangedAt
+		}
 	}
+	storedNFT.Custody = openCustody(storedNFT.Custody, entry.Wallet, time.Now())
 
 	// Calculate checksum for data integrity
 	// Explanation: This helps us detect if files get corrupted
 	checksum, err := fs.calculateChecksum(nftInfo)
 	if err != nil {
-		return fmt.Errorf("failed to calculate checksum: %w", err)
+		return nil, fmt.Errorf("failed to calculate checksum: %w", err)
 	}
 	storedNFT.Checksum = checksum
 
-	// Save main NFT data
-	nftDataPath := filepath.Join(nftDir, "nft_data.json")
-	if err := fs.saveJSON(nftDataPath, storedNFT); err != nil {
-		return fmt.Errorf("failed to save NFT data: %w", err)
+	txn := &fileTransaction{ctx: ctx, fs: fs, dir: txnDir, target: target, entry: entry, info: nftInfo}
+
+	// Stage main NFT data
+	if err := txn.stage("nft_data.json", storedNFT); err != nil {
+		txn.Rollback()
+		return nil, fmt.Errorf("failed to save NFT data: %w", err)
 	}
 
-	// Save metadata separately if available
+	// Stage metadata separately if available
 	// Explanation: Separate files make it easier to examine metadata
 	if nftInfo.Metadata != nil {
-		metadataPath := filepath.Join(nftDir, "metadata.json")
-		if err := fs.saveJSON(metadataPath, nftInfo.Metadata); err != nil {
-			return fmt.Errorf("failed to save metadata: %w", err)
+		if err := txn.stage("metadata.json", nftInfo.Metadata); err != nil {
+			txn.Rollback()
+			return nil, fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+	// The metadata as the gateway served it, for byte-exact comparison
+	if len(nftInfo.RawMetadata) > 0 {
+		if err := txn.stageRaw(RawMetadataFile, nftInfo.RawMetadata); err != nil {
+			txn.Rollback()
+			return nil, fmt.Errorf("failed to save raw metadata: %w", err)
+		}
+	}
+	if metadataChanged {
+		if err := txn.stageSnapshots(&previous, storedNFT); err != nil {
+			txn.Rollback()
+			return nil, err
 		}
 	}
 
-	// Create media directory and save media file info if available
+	// Create media directory and stage media file info if available
 	if len(nftInfo.MediaFiles) > 0 {
-		mediaDir := filepath.Join(nftDir, "media")
+		mediaDir := filepath.Join(txnDir, "media")
 		if err := os.MkdirAll(mediaDir, 0755); err != nil {
-			return fmt.Errorf("failed to create media directory: %w", err)
+			txn.Rollback()
+			return nil, fmt.Errorf("failed to create media directory: %w", err)
 		}
 
-		// Save media manifest for tracking downloaded files
-		mediaManifestPath := filepath.Join(nftDir, "media_manifest.json")
-		if err := fs.saveJSON(mediaManifestPath, nftInfo.MediaFiles); err != nil {
-			return fmt.Errorf("failed to save media manifest: %w", err)
+		// Media manifest tracks downloaded files
+		if err := txn.stage("media_manifest.json", nftInfo.MediaFiles); err != nil {
+			txn.Rollback()
+			return nil, fmt.Errorf("failed to save media manifest: %w", err)
 		}
 	}
 
-	return nil
+	// Hash everything the backup will hold once committed
+	if err := txn.stageIntegrity(); err != nil {
+		txn.Rollback()
+		return nil, err
+	}
+
+	return txn, nil
 }
 
 // GetNFT retrieves stored NFT information
@@ -128,7 +393,7 @@ func (fs *FileStorage) ListNFTs(ctx context.Context, walletAddr solanago.PublicK
 
 	// Check if wallet directory exists
 	if _, err := os.Stat(walletDir); os.IsNotExist(err) {
-		return []*StoredNFT{}, nil // Empty slice, not an error
+		return append([]*StoredNFT{}, fs.linkedNFTs(walletAddr)...), nil // Empty slice, not an error
 	}
 
 	var nfts []*StoredNFT
@@ -158,11 +423,28 @@ func (fs *FileStorage) ListNFTs(ctx context.Context, walletAddr solanago.PublicK
 		return nil, fmt.Errorf("failed to scan wallet directory: %w", err)
 	}
 
+	nfts = append(nfts, fs.linkedNFTs(walletAddr)...)
 	return nfts, nil
 }
 
 // DeleteNFT removes stored NFT data
-func (fs *FileStorage) DeleteNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) error {
+func (fs *FileStorage) DeleteNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (err error) {
+	_, span := telemetry.Start(ctx, "storage delete",
+		attribute.String("solvault.wallet", walletAddr.String()),
+		attribute.String("solvault.mint", mintAddr.String()))
+	defer func() { telemetry.End(span, err) }()
+
+	// A linked record has no files of its own; dropping the link is enough
+	if entry := fs.index.Get(walletAddr.String(), mintAddr.String()); entry != nil && entry.PrimaryWallet != "" {
+		fs.index.Remove(walletAddr.String(), mintAddr.String())
+		return fs.index.Save()
+	}
+	for _, other := range fs.index.ByMint(mintAddr.String()) {
+		if other.PrimaryWallet == walletAddr.String() {
+			return fmt.Errorf("cannot delete %s: wallet %s links to this backup", mintAddr.String(), other.Wallet)
+		}
+	}
+
 	nftDir := fs.buildNFTPath(walletAddr, mintAddr)
 
 	// Check if directory exists
@@ -175,25 +457,289 @@ func (fs *FileStorage) DeleteNFT(ctx context.Context, walletAddr, mintAddr solan
 		return fmt.Errorf("failed to delete NFT directory: %w", err)
 	}
 
+	fs.index.Remove(walletAddr.String(), mintAddr.String())
+	if err := fs.index.Save(); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	return fs.deleteFromReplicas(ctx, walletAddr, mintAddr)
+}
+
+// SetStatus records whether the wallet still holds a backed-up NFT
+// Explanation: NFTs that leave the wallet are kept, not deleted; the backup
+// is exactly what the user wants when the original is gone.
+func (fs *FileStorage) SetStatus(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, status HoldingStatus) error {
+	return fs.RecordDeparture(ctx, walletAddr, mintAddr, status, Departure{})
+}
+
+// RecordDeparture is SetStatus for an NFT whose outgoing transaction is
+// known; it is kept on the wallet's custody period
+func (fs *FileStorage) RecordDeparture(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, status HoldingStatus, departure Departure) error {
+	nftDataPath := filepath.Join(fs.buildNFTPath(walletAddr, mintAddr), "nft_data.json")
+
+	var storedNFT StoredNFT
+	if err := fs.loadJSON(nftDataPath, &storedNFT); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("NFT not found: %s", mintAddr.String())
+		}
+		return fmt.Errorf("failed to load NFT data: %w", err)
+	}
+
+	now := time.Now()
+	storedNFT.Custody = closeCustody(seedCustody(&storedNFT), walletAddr.String(), status, departure, now)
+
+	// The shared record stays held while any other vault wallet still holds it
+	overall := status
+	for _, period := range storedNFT.Custody {
+		if period.To == nil {
+			overall = StatusHeld
+		}
+	}
+	if storedNFT.Status != overall {
+		storedNFT.Status = overall
+		storedNFT.StatusChangedAt = now
+	}
+	storedNFT.UpdatedAt = now
+	if err := fs.saveJSON(nftDataPath, &storedNFT); err != nil {
+		return fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	if err := fs.refreshIntegrity(filepath.Dir(nftDataPath), "nft_data.json"); err != nil {
+		return err
+	}
+
+	if entry := fs.index.Get(walletAddr.String(), mintAddr.String()); entry != nil {
+		updated := *entry
+		updated.Status = status
+		fs.index.Put(&updated)
+		if err := fs.index.Save(); err != nil {
+			return fmt.Errorf("failed to update index: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// Close cleans up storage resources (no-op for file storage)
+// ListWallets returns every wallet that has backups in this vault
+func (fs *FileStorage) ListWallets(ctx context.Context) ([]solanago.PublicKey, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.baseDir, "wallets"))
+	if os.IsNotExist(err) {
+		return []solanago.PublicKey{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallets directory: %w", err)
+	}
+
+	var wallets []solanago.PublicKey
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		wallet, err := solanago.PublicKeyFromBase58(entry.Name())
+		if err != nil {
+			// Not a wallet directory (e.g. left behind by an older demo)
+			continue
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, nil
+}
+
+// RebuildIndex recreates index.json from the nft_data.json files on disk,
+// returning the number of NFTs indexed
+// Explanation: The index is derived data; the per-NFT files are the source
+// of truth, so it can always be rebuilt after an import or a manual copy.
+func (fs *FileStorage) RebuildIndex(ctx context.Context) (int, error) {
+	wallets, err := fs.ListWallets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	fresh := make(map[string]*IndexEntry)
+	for _, wallet := range wallets {
+		nftsDir := filepath.Join(fs.baseDir, "wallets", wallet.String(), "nfts")
+		dirs, err := os.ReadDir(nftsDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", nftsDir, err)
+		}
+
+		for _, dir := range dirs {
+			if !dir.IsDir() {
+				continue
+			}
+
+			if err := recoverTransaction(filepath.Join(nftsDir, dir.Name())); err != nil {
+				fmt.Printf("⚠️  Warning: %v\n", err)
+			}
+
+			var storedNFT StoredNFT
+			if err := fs.loadJSON(filepath.Join(nftsDir, dir.Name(), "nft_data.json"), &storedNFT); err != nil || storedNFT.NFTInfo == nil {
+				fmt.Printf("⚠️  Warning: skipping %s: no readable nft_data.json\n", filepath.Join(nftsDir, dir.Name()))
+				continue
+			}
+
+			entry := &IndexEntry{
+				Wallet:    wallet.String(),
+				Mint:      storedNFT.NFTInfo.MintAddress.String(),
+				DirName:   dir.Name(),
+				Status:    storedNFT.Status,
+				UpdatedAt: time.Now(),
+			}
+			if md := storedNFT.NFTInfo.Metadata; md != nil {
+				entry.Name, entry.Symbol, entry.Collection = md.Name, md.Symbol, md.Collection.Name
+				entry.Attributes = IndexAttributes(md)
+			}
+			// The files cannot tell whether media was still downloading
+			if previous := fs.index.Get(entry.Wallet, entry.Mint); previous != nil {
+				entry.InProgress = previous.InProgress
+			}
+			fresh[indexKey(entry.Wallet, entry.Mint)] = entry
+
+			// Other custodians link back to this directory
+			for _, period := range storedNFT.Custody {
+				status := period.Status
+				if period.To == nil {
+					status = StatusHeld
+				}
+				if period.Wallet == entry.Wallet {
+					entry.Status = status
+					continue
+				}
+				link := *entry
+				link.Wallet, link.PrimaryWallet, link.Status = period.Wallet, entry.Wallet, status
+				if _, exists := fresh[indexKey(link.Wallet, link.Mint)]; !exists {
+					fresh[indexKey(link.Wallet, link.Mint)] = &link
+				}
+			}
+		}
+	}
+
+	fs.index.mu.Lock()
+	fs.index.Entries = fresh
+	fs.index.mu.Unlock()
+
+	if err := fs.index.Save(); err != nil {
+		return 0, err
+	}
+	return len(fresh), nil
+}
+
+// NFTDir returns the directory holding an NFT's backup files
+func (fs *FileStorage) NFTDir(walletAddr, mintAddr solanago.PublicKey) string {
+	return fs.buildNFTPath(walletAddr, mintAddr)
+}
+
+// stagingDir is where a new backup for entry is built before commit
+func (fs *FileStorage) stagingDir(entry *IndexEntry) string {
+	return filepath.Join(fs.baseDir, StagingDirName, entry.StorageWallet(), entry.DirName)
+}
+
+// EntryDir returns the directory holding an indexed NFT's backup files,
+// which for a linked wallet is the primary wallet's backup
+func (fs *FileStorage) EntryDir(entry *IndexEntry) string {
+	return filepath.Join(fs.baseDir, "wallets", entry.StorageWallet(), "nfts", entry.DirName)
+}
+
+// BaseDir returns the root directory of the vault
+func (fs *FileStorage) BaseDir() string {
+	return fs.baseDir
+}
+
+// Capabilities reports what the local filesystem provides
+//
+// Explanation: Plain files are overwritten in place, nothing guards against
+// a second writer, and checksums are computed by SolVault rather than the
+// disk, so every feature is off. Object size is only bounded by free space.
+func (fs *FileStorage) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// Close cleans up storage resources: it closes any replicas, while the
+// vault itself needs no cleanup (ephemeral vaults stay usable until Discard)
 func (fs *FileStorage) Close() error {
-	return nil
+	var firstErr error
+	for _, replica := range fs.replicas {
+		if err := replica.backend.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close replica %s: %w", replica.name, err)
+		}
+	}
+	fs.replicas = nil
+	return firstErr
 }
 
 // Helper methods
 
 // buildNFTPath constructs the filesystem path for an NFT
 func (fs *FileStorage) buildNFTPath(walletAddr, mintAddr solanago.PublicKey) string {
-	return filepath.Join(
-		fs.baseDir,
-		"wallets",
-		walletAddr.String(),
-		"nfts",
-		mintAddr.String(),
-	)
+	if entry := fs.index.Get(walletAddr.String(), mintAddr.String()); entry != nil {
+		return fs.EntryDir(entry)
+	}
+	return filepath.Join(fs.baseDir, "wallets", walletAddr.String(), "nfts", mintAddr.String())
+}
+
+// indexEntryFor returns the index entry an NFT should be saved under,
+// allocating a collision-free directory name for new NFTs
+//
+// Explanation: Before anything is written we check that the deepest file the
+// backup will contain (media/<file name>) fits within the platform's path
+// limit. If it does not, we fall back to a short directory name so bulk
+// backups keep going instead of failing halfway with an opaque OS error.
+func (fs *FileStorage) indexEntryFor(nftInfo *fetcher.NFTInfo) (*IndexEntry, error) {
+	wallet := nftInfo.Owner.String()
+	mint := nftInfo.MintAddress.String()
+
+	entry := &IndexEntry{Wallet: wallet, Mint: mint}
+	if md := nftInfo.Metadata; md != nil {
+		entry.Name, entry.Symbol, entry.Collection = md.Name, md.Symbol, md.Collection.Name
+		entry.Attributes = IndexAttributes(md)
+	}
+	name := entry.Name
+
+	if existing := fs.index.Get(wallet, mint); existing != nil {
+		// Keep the directory stable across re-saves even if the name changed
+		entry.DirName, entry.PrimaryWallet = existing.DirName, existing.PrimaryWallet
+		return entry, nil
+	}
+
+	// Another vault wallet already backed this mint up: link to its record
+	// instead of storing a second copy
+	for _, other := range fs.index.ByMint(mint) {
+		if other.PrimaryWallet == "" && other.Wallet != wallet {
+			entry.DirName, entry.PrimaryWallet = other.DirName, other.Wallet
+			return entry, nil
+		}
+	}
+
+	taken := func(candidate string) bool {
+		return fs.index.DirNameTaken(wallet, candidate) || candidate == mint
+	}
+
+	dirName := mint
+	if fs.layout == LayoutName && strings.TrimSpace(name) != "" {
+		dirName = UniqueFilename(name, taken)
+	}
+
+	if err := fsutil.CheckPath(fs.deepestPath(wallet, dirName)); err != nil {
+		// Shortened fallback: the first characters of the mint are unique
+		// enough in practice, and the index keeps the full mapping anyway
+		dirName = UniqueFilename(mint[:shortDirNameLength], taken)
+		if err := fsutil.CheckPath(fs.deepestPath(wallet, dirName)); err != nil {
+			return nil, fmt.Errorf("cannot back up %s: %w", mint, err)
+		}
+		fmt.Printf("⚠️  Backup path for %s is too long; using shortened directory %q\n", mint, dirName)
+	}
+
+	entry.DirName = dirName
+	return entry, nil
+}
+
+// deepestPath returns the longest path a backup in dirName is expected to use
+func (fs *FileStorage) deepestPath(wallet, dirName string) string {
+	return filepath.Join(fs.baseDir, "wallets", wallet, "nfts", dirName, "media",
+		strings.Repeat("x", fsutil.MaxMediaFilename))
 }
 
 // saveJSON marshals and saves data as JSON
@@ -204,6 +750,9 @@ func (fs *FileStorage) saveJSON(filePath string, data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
+	if jsonData, err = fs.encode(filePath, jsonData); err != nil {
+		return err
+	}
 
 	if err := os.WriteFile(filePath, jsonData, fs.permissions); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
@@ -214,10 +763,15 @@ func (fs *FileStorage) saveJSON(filePath string, data interface{}) error {
 
 // loadJSON loads and unmarshals JSON data
 func (fs *FileStorage) loadJSON(filePath string, target interface{}) error {
-	data, err := os.ReadFile(filePath)
+	data, err := fs.ReadFile(filePath)
 	if err != nil {
 		return err
 	}
+	if filepath.Base(filePath) == "nft_data.json" {
+		if data, _, err = MigrateRecord(data); err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
 
 	if err := json.Unmarshal(data, target); err != nil {
 		return fmt.Errorf("failed to unmarshal JSON: %w", err)
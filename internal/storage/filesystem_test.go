@@ -179,3 +179,70 @@ func TestFileStorage_DirectoryStructure(t *testing.T) {
 		t.Errorf("Metadata file does not exist: %s", metadataFile)
 	}
 }
+
+// TestMemoryStorage checks that an ephemeral vault never writes an index and
+// cleans up after itself
+func TestMemoryStorage(t *testing.T) {
+	storage, err := NewMemoryStorage()
+	if err != nil {
+		t.Fatalf("Failed to create memory storage: %v", err)
+	}
+
+	wallet := solanago.NewWallet().PublicKey()
+	mint := solanago.NewWallet().PublicKey()
+	nft := &fetcher.NFTInfo{MintAddress: mint, Owner: wallet, FetchedAt: time.Now()}
+	if err := storage.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	if storage.Index().Get(wallet.String(), mint.String()) == nil {
+		t.Error("Expected NFT in the in-memory index")
+	}
+	if _, err := os.Stat(filepath.Join(storage.BaseDir(), indexFileName)); !os.IsNotExist(err) {
+		t.Error("In-memory index should not be written to disk")
+	}
+
+	if err := storage.Close(); err != nil {
+		t.Fatalf("Failed to close storage: %v", err)
+	}
+	if _, err := storage.GetNFT(context.Background(), wallet, mint); err != nil {
+		t.Errorf("Ephemeral vault should survive Close: %v", err)
+	}
+
+	if err := storage.Discard(); err != nil {
+		t.Fatalf("Failed to discard storage: %v", err)
+	}
+	if _, err := os.Stat(storage.BaseDir()); !os.IsNotExist(err) {
+		t.Error("Expected temp directory to be removed")
+	}
+
+	persistent, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := persistent.Discard(); err == nil {
+		t.Error("Discard must refuse a persistent vault")
+	}
+}
+
+func TestCapabilities_Fits(t *testing.T) {
+	tests := []struct {
+		name string
+		caps Capabilities
+		size int64
+		want bool
+	}{
+		{"unlimited", Capabilities{}, 1 << 40, true},
+		{"under limit", Capabilities{MaxObjectSize: 100}, 99, true},
+		{"at limit", Capabilities{MaxObjectSize: 100}, 100, true},
+		{"over limit", Capabilities{MaxObjectSize: 100}, 101, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.caps.Fits(tt.size); got != tt.want {
+				t.Errorf("Fits(%d) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// FsckKind is a category of problem Fsck finds
+type FsckKind string
+
+const (
+	FsckInvalidJSON      FsckKind = "invalid-json"      // A JSON file does not parse
+	FsckIncompleteCommit FsckKind = "incomplete-commit" // A save stopped after its commit point
+	FsckRecordChecksum   FsckKind = "record-checksum"   // nft_data.json does not match its checksum
+	FsckMediaMissing     FsckKind = "media-missing"     // A media file in the manifest is not on disk
+	FsckMediaChecksum    FsckKind = "media-checksum"    // A media file's bytes do not match the manifest
+	FsckFileMissing      FsckKind = "file-missing"      // metadata.json or media_manifest.json is gone
+	FsckDirMismatch      FsckKind = "dir-mismatch"      // The directory is named after a different mint
+	FsckIndexMismatch    FsckKind = "index-mismatch"    // index.json disagrees with the files on disk
+	FsckNoIntegrity      FsckKind = "no-integrity"      // integrity.json is missing, from a backup made before it existed
+)
+
+// FsckIssue is one problem found in the vault
+type FsckIssue struct {
+	Kind     FsckKind `json:"kind"`
+	Path     string   `json:"path"` // Relative to the vault
+	Wallet   string   `json:"wallet,omitempty"`
+	Mint     string   `json:"mint,omitempty"`
+	Message  string   `json:"message"`
+	Repair   string   `json:"repair,omitempty"` // What --repair does, or what to run by hand
+	Repaired bool     `json:"repaired"`
+	Error    string   `json:"error,omitempty"` // Why the repair failed
+
+	fix     func() error // Nil when only a new backup can fix it
+	reindex bool         // Repaired once the index is rebuilt after fix
+}
+
+// FsckReport is the machine-readable result of an Fsck run
+type FsckReport struct {
+	CheckedAt  time.Time    `json:"checked_at"`
+	Repair     bool         `json:"repair"`
+	NFTs       int          `json:"nfts"`  // NFT directories checked
+	Files      int          `json:"files"` // Files read and checked
+	Issues     []*FsckIssue `json:"issues"`
+	Repaired   int          `json:"repaired"`
+	Unrepaired int          `json:"unrepaired"`
+}
+
+// OK reports whether the vault is healthy, or was made healthy by repairs
+func (r *FsckReport) OK() bool {
+	return r.Unrepaired == 0
+}
+
+// Fsck walks every NFT directory in the vault and checks that each JSON
+// file parses, each record and media file matches its checksum, directory
+// names match the mints inside them and index.json agrees with the files.
+// With repair set it fixes what it can without the network.
+//
+// Explanation: Only derived data is repaired: metadata.json, the current
+// snapshot and media_manifest.json are rewritten from nft_data.json, a
+// missing integrity.json is hashed from the files on disk, stale proof.json
+// files are removed for verify to regenerate, directories are renamed to
+// their mint and the index is rebuilt. A damaged nft_data.json or media file
+// is the backup itself, so those are reported for a fresh backup instead of
+// being guessed at.
+func (fs *FileStorage) Fsck(ctx context.Context, repair bool) (*FsckReport, error) {
+	report := &FsckReport{CheckedAt: time.Now(), Repair: repair, Issues: []*FsckIssue{}}
+
+	dirs, err := filepath.Glob(filepath.Join(fs.baseDir, "wallets", "*", "nfts", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NFT directories: %w", err)
+	}
+	seen := make(map[string]bool) // Index keys backed by a directory
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		report.NFTs++
+		fs.fsckDir(dir, report, seen)
+	}
+	fs.fsckIndex(report, seen)
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Path < report.Issues[j].Path })
+
+	if repair {
+		fs.repairIssues(ctx, report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			report.Repaired++
+		} else {
+			report.Unrepaired++
+		}
+	}
+	return report, nil
+}
+
+// repairIssues applies every fix, then rebuilds the index once if any
+// issue needs it, so renamed directories are picked up
+func (fs *FileStorage) repairIssues(ctx context.Context, issues []*FsckIssue) {
+	var reindex []*FsckIssue
+	for _, issue := range issues {
+		if issue.fix == nil {
+			continue
+		}
+		if err := issue.fix(); err != nil {
+			issue.Error = err.Error()
+			continue
+		}
+		if issue.reindex {
+			reindex = append(reindex, issue)
+			continue
+		}
+		issue.Repaired = true
+	}
+	if len(reindex) == 0 {
+		return
+	}
+
+	_, err := fs.RebuildIndex(ctx)
+	for _, issue := range reindex {
+		if err != nil {
+			issue.Error = err.Error()
+			continue
+		}
+		issue.Repaired = true
+	}
+}
+
+// fsckDir checks one NFT directory
+func (fs *FileStorage) fsckDir(dir string, report *FsckReport, seen map[string]bool) {
+	wallet := filepath.Base(filepath.Dir(filepath.Dir(dir)))
+	add := func(kind FsckKind, path, message, repair string, fix func() error) *FsckIssue {
+		issue := &FsckIssue{Kind: kind, Path: fs.relPath(path), Wallet: wallet, Message: message, Repair: repair, fix: fix}
+		report.Issues = append(report.Issues, issue)
+		return issue
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, journalName)); err == nil {
+		add(FsckIncompleteCommit, dir, "a save was interrupted after its commit point", "finish the save", func() error {
+			return recoverTransaction(dir)
+		})
+	}
+
+	// Every JSON file must parse, whatever else is wrong
+	parsed := make(map[string]bool)
+	names, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range names {
+		report.Files++
+		var value interface{}
+		if err := fs.loadJSON(path, &value); err != nil {
+			parsed[filepath.Base(path)] = false
+			continue
+		}
+		parsed[filepath.Base(path)] = true
+	}
+
+	var stored StoredNFT
+	if !parsed["nft_data.json"] || fs.loadJSON(filepath.Join(dir, "nft_data.json"), &stored) != nil || stored.NFTInfo == nil {
+		add(FsckInvalidJSON, filepath.Join(dir, "nft_data.json"), "nft_data.json is missing or unreadable", "back the NFT up again", nil)
+		return
+	}
+	mint := stored.NFTInfo.MintAddress.String()
+	issueFor := func(kind FsckKind, path, message, repair string, fix func() error) *FsckIssue {
+		issue := add(kind, path, message, repair, fix)
+		issue.Mint = mint
+		return issue
+	}
+
+	for _, name := range sortedKeys(parsed) {
+		if parsed[name] {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		message := name + " does not parse"
+		switch {
+		case name == "metadata.json" || name == MetadataSnapshotName(stored.Version):
+			issueFor(FsckInvalidJSON, path, message, "rewrite it from nft_data.json", fs.writeMetadataFix(path, &stored))
+		case name == "media_manifest.json":
+			issueFor(FsckInvalidJSON, path, message, "rewrite it from nft_data.json", fs.writeManifestFix(path, &stored))
+		case name == IntegrityFile:
+			issueFor(FsckInvalidJSON, path, message, "rehash the files on disk", func() error {
+				_, err := fs.WriteIntegrity(dir)
+				return err
+			})
+		case name == "proof.json":
+			issueFor(FsckInvalidJSON, path, message, "remove it; solvault verify writes a new one", func() error {
+				return os.Remove(path)
+			})
+		default:
+			issueFor(FsckInvalidJSON, path, message, "", nil)
+		}
+	}
+
+	// Derived files a save always writes
+	if _, ok := parsed["metadata.json"]; !ok && stored.NFTInfo.Metadata != nil {
+		path := filepath.Join(dir, "metadata.json")
+		issueFor(FsckFileMissing, path, "metadata.json is missing", "rewrite it from nft_data.json", fs.writeMetadataFix(path, &stored))
+	}
+	if _, ok := parsed["media_manifest.json"]; !ok && len(stored.NFTInfo.MediaFiles) > 0 {
+		path := filepath.Join(dir, "media_manifest.json")
+		issueFor(FsckFileMissing, path, "media_manifest.json is missing", "rewrite it from nft_data.json", fs.writeManifestFix(path, &stored))
+	}
+	if _, ok := parsed[IntegrityFile]; !ok {
+		issueFor(FsckNoIntegrity, filepath.Join(dir, IntegrityFile), "integrity.json is missing", "hash the files on disk", func() error {
+			_, err := fs.WriteIntegrity(dir)
+			return err
+		})
+	}
+
+	if checksum, err := fs.calculateChecksum(stored.NFTInfo); err == nil && stored.Checksum != "" && checksum != stored.Checksum {
+		issueFor(FsckRecordChecksum, filepath.Join(dir, "nft_data.json"), "the NFT record does not match its checksum", "back the NFT up again", nil)
+	}
+
+	fs.fsckMedia(dir, &stored, report, issueFor)
+
+	// Directory naming and the index
+	dirName := filepath.Base(dir)
+	seen[indexKey(wallet, mint)] = true
+	if other, err := solanago.PublicKeyFromBase58(dirName); err == nil && other.String() != mint {
+		target := filepath.Join(filepath.Dir(dir), mint)
+		repair, fix := "", (func() error)(nil)
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			repair = "rename the directory to " + mint
+			fix = func() error { return os.Rename(dir, target) }
+		}
+		issueFor(FsckDirMismatch, dir, fmt.Sprintf("directory is named after mint %s but holds %s", dirName, mint), repair, fix).reindex = true
+		return
+	}
+	if entry := fs.index.Get(wallet, mint); entry == nil || entry.DirName != dirName || entry.PrimaryWallet != "" {
+		issueFor(FsckIndexMismatch, dir, "index.json does not point at this directory", "rebuild the index", noFix).reindex = true
+	}
+}
+
+// fsckMedia checks every media file the manifest lists against its checksum
+func (fs *FileStorage) fsckMedia(dir string, stored *StoredNFT, report *FsckReport, issueFor func(FsckKind, string, string, string, func() error) *FsckIssue) {
+	var manifest []*fetcher.MediaFile
+	if err := fs.loadJSON(filepath.Join(dir, "media_manifest.json"), &manifest); err != nil {
+		manifest = stored.NFTInfo.MediaFiles
+	}
+
+	for _, media := range manifest {
+		if media == nil || media.Filename == "" {
+			continue
+		}
+		path := filepath.Join(dir, "media", media.Filename)
+		data, err := fs.ReadFile(path)
+		if os.IsNotExist(err) {
+			issueFor(FsckMediaMissing, path, media.Filename+" is missing", "back the NFT up again to download it", nil)
+			continue
+		}
+		report.Files++
+		if err != nil {
+			issueFor(FsckMediaChecksum, path, fmt.Sprintf("failed to read %s: %v", media.Filename, err), "back the NFT up again to download it", nil)
+			continue
+		}
+		if media.Checksum == "" {
+			continue
+		}
+		if sum := fmt.Sprintf("%x", sha256.Sum256(data)); !strings.EqualFold(sum, media.Checksum) {
+			issueFor(FsckMediaChecksum, path, fmt.Sprintf("%s does not match its checksum", media.Filename), "back the NFT up again to download it", nil)
+		}
+	}
+}
+
+// fsckIndex reports index entries whose directory is gone
+func (fs *FileStorage) fsckIndex(report *FsckReport, seen map[string]bool) {
+	for _, entry := range fs.index.List() {
+		if entry.PrimaryWallet != "" || seen[indexKey(entry.Wallet, entry.Mint)] {
+			continue
+		}
+		dir := filepath.Join(fs.baseDir, "wallets", entry.Wallet, "nfts", entry.DirName)
+		report.Issues = append(report.Issues, &FsckIssue{
+			Kind:    FsckIndexMismatch,
+			Path:    fs.relPath(dir),
+			Wallet:  entry.Wallet,
+			Mint:    entry.Mint,
+			Message: "index.json lists a backup that is not on disk",
+			Repair:  "rebuild the index",
+			fix:     noFix,
+			reindex: true,
+		})
+	}
+}
+
+// writeMetadataFix rewrites metadata.json, or the current version's
+// snapshot, from the record. A record without metadata has neither.
+func (fs *FileStorage) writeMetadataFix(path string, stored *StoredNFT) func() error {
+	return func() error {
+		if stored.NFTInfo.Metadata == nil {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		} else if err := fs.saveJSON(path, stored.NFTInfo.Metadata); err != nil {
+			return err
+		}
+		return fs.refreshIntegrity(filepath.Dir(path), filepath.Base(path))
+	}
+}
+
+// writeManifestFix rewrites media_manifest.json from the record
+func (fs *FileStorage) writeManifestFix(path string, stored *StoredNFT) func() error {
+	return func() error {
+		if err := fs.saveJSON(path, stored.NFTInfo.MediaFiles); err != nil {
+			return err
+		}
+		return fs.refreshIntegrity(filepath.Dir(path), filepath.Base(path))
+	}
+}
+
+// noFix is the fix of issues the index rebuild alone repairs
+func noFix() error { return nil }
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
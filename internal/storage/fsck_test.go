@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// fsckVault creates a vault holding one NFT with one media file
+func fsckVault(t *testing.T) (*FileStorage, *fetcher.NFTInfo, string) {
+	t.Helper()
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	image := []byte("not really a png")
+	nft.MediaFiles = []*fetcher.MediaFile{{Filename: "image.png", Size: int64(len(image)), Checksum: fmt.Sprintf("%x", sha256.Sum256(image))}}
+
+	dir := store.NFTDir(wallet, nft.MintAddress)
+	if err := os.MkdirAll(filepath.Join(dir, "media"), 0755); err != nil {
+		t.Fatalf("Failed to create media directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "media", "image.png"), image, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := store.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	return store, nft, dir
+}
+
+func TestFileStorage_Fsck(t *testing.T) {
+	tests := []struct {
+		name         string
+		corrupt      func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string)
+		wantKind     FsckKind
+		wantRepaired bool
+	}{
+		{
+			name:     "healthy",
+			corrupt:  func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {},
+			wantKind: "",
+		},
+		{
+			name: "truncated metadata",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "metadata.json"), []byte(`{"name": "Sha`))
+			},
+			wantKind:     FsckInvalidJSON,
+			wantRepaired: true,
+		},
+		{
+			name: "missing manifest",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				os.Remove(filepath.Join(dir, "media_manifest.json"))
+			},
+			wantKind:     FsckFileMissing,
+			wantRepaired: true,
+		},
+		{
+			name: "backup without an integrity manifest",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				os.Remove(filepath.Join(dir, IntegrityFile))
+			},
+			wantKind:     FsckNoIntegrity,
+			wantRepaired: true,
+		},
+		{
+			name: "edited record",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				var stored StoredNFT
+				if err := store.loadJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+					t.Fatalf("Failed to load NFT data: %v", err)
+				}
+				stored.NFTInfo.Supply = 2
+				if err := store.saveJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+					t.Fatalf("Failed to save NFT data: %v", err)
+				}
+			},
+			wantKind: FsckRecordChecksum,
+		},
+		{
+			name: "corrupted media",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "media", "image.png"), []byte("bit rot"))
+			},
+			wantKind: FsckMediaChecksum,
+		},
+		{
+			name: "missing media",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				os.Remove(filepath.Join(dir, "media", "image.png"))
+			},
+			wantKind: FsckMediaMissing,
+		},
+		{
+			name: "directory named after another mint",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				other := filepath.Join(filepath.Dir(dir), solanago.NewWallet().PublicKey().String())
+				if err := os.Rename(dir, other); err != nil {
+					t.Fatalf("Failed to rename directory: %v", err)
+				}
+			},
+			wantKind:     FsckDirMismatch,
+			wantRepaired: true,
+		},
+		{
+			name: "stale index",
+			corrupt: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				store.Index().Remove(nft.Owner.String(), nft.MintAddress.String())
+			},
+			wantKind:     FsckIndexMismatch,
+			wantRepaired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, nft, dir := fsckVault(t)
+			tt.corrupt(t, store, nft, dir)
+
+			report, err := store.Fsck(ctx, false)
+			if err != nil {
+				t.Fatalf("Failed to check vault: %v", err)
+			}
+			if tt.wantKind == "" {
+				if len(report.Issues) != 0 || !report.OK() {
+					t.Fatalf("Healthy vault reported %+v", report.Issues)
+				}
+				return
+			}
+			if len(report.Issues) == 0 || report.Issues[0].Kind != tt.wantKind {
+				t.Fatalf("Got issues %+v, want %s", report.Issues, tt.wantKind)
+			}
+			if report.OK() {
+				t.Errorf("Report is OK before repairs")
+			}
+
+			repaired, err := store.Fsck(ctx, true)
+			if err != nil {
+				t.Fatalf("Failed to repair vault: %v", err)
+			}
+			if repaired.OK() != tt.wantRepaired {
+				t.Fatalf("Repair left %+v, want repaired = %v", repaired.Issues, tt.wantRepaired)
+			}
+			if !tt.wantRepaired {
+				return
+			}
+
+			again, err := store.Fsck(ctx, false)
+			if err != nil {
+				t.Fatalf("Failed to recheck vault: %v", err)
+			}
+			if len(again.Issues) != 0 {
+				t.Errorf("Issues remain after repair: %+v", again.Issues)
+			}
+			if _, err := store.GetNFT(ctx, nft.Owner, nft.MintAddress); err != nil {
+				t.Errorf("Failed to get NFT after repair: %v", err)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
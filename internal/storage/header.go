@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+)
+
+// HeaderFile is the vault metadata file at the vault root
+const HeaderFile = "vault.json"
+
+// SchemaVersion is the newest vault layout this build reads and writes
+const SchemaVersion = 1
+
+// Vault features that change the on-disk format
+const (
+	FeatureEncryption  = "encryption"
+	FeatureDedup       = "dedup"
+	FeatureCompression = "compression"
+)
+
+// supportedFeatures lists the features this build can read
+//
+// Explanation: Linked custody records are how this build deduplicates
+// mints across wallets, so a dedup vault is safe to open. Encrypted and
+// compressed files are recognised by their prefix and decoded on read.
+var supportedFeatures = map[string]bool{
+	FeatureDedup:       true,
+	FeatureEncryption:  true,
+	FeatureCompression: true,
+}
+
+// VaultHeader records how and when a vault was created
+type VaultHeader struct {
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Features      []string  `json:"features"`
+	Cluster       string    `json:"cluster,omitempty"` // Solana cluster the vault backs up
+
+	// Encryption describes how the key of an encrypted vault is derived
+	Encryption *crypt.Params `json:"encryption,omitempty"`
+}
+
+// IncompatibleVaultError explains why this build cannot open a vault
+type IncompatibleVaultError struct {
+	Dir      string
+	Reason   string
+	Guidance string
+}
+
+func (e *IncompatibleVaultError) Error() string {
+	return fmt.Sprintf("vault %s is not compatible with this version of SolVault: %s. %s", e.Dir, e.Reason, e.Guidance)
+}
+
+// ReadHeader loads a vault's header. Vaults created before headers existed
+// return os.ErrNotExist.
+func ReadHeader(baseDir string) (*VaultHeader, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, HeaderFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var header VaultHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", HeaderFile, err)
+	}
+	return &header, nil
+}
+
+// CheckVault refuses vaults this build cannot safely read. Missing vaults
+// and vaults without a header pass.
+func CheckVault(baseDir string) error {
+	header, err := ReadHeader(baseDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return header.compatible(baseDir)
+}
+
+// compatible checks the schema version and enabled features
+func (h *VaultHeader) compatible(baseDir string) error {
+	if h.SchemaVersion > SchemaVersion {
+		return &IncompatibleVaultError{
+			Dir:      baseDir,
+			Reason:   fmt.Sprintf("it uses schema version %d and this build only understands up to %d", h.SchemaVersion, SchemaVersion),
+			Guidance: "Upgrade SolVault to open it, or pass --vault to use a different vault",
+		}
+	}
+	if h.SchemaVersion < 1 {
+		return &IncompatibleVaultError{
+			Dir:      baseDir,
+			Reason:   fmt.Sprintf("%s has an invalid schema version %d", HeaderFile, h.SchemaVersion),
+			Guidance: fmt.Sprintf("Restore %s from a backup, or delete it to have SolVault recreate it", HeaderFile),
+		}
+	}
+
+	var unknown []string
+	for _, feature := range h.Features {
+		if !supportedFeatures[feature] {
+			unknown = append(unknown, feature)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &IncompatibleVaultError{
+			Dir:      baseDir,
+			Reason:   fmt.Sprintf("it has features enabled that this build does not support (%s)", strings.Join(unknown, ", ")),
+			Guidance: "Upgrade SolVault to a version with these features, or pass --vault to use a different vault",
+		}
+	}
+	if h.HasFeature(FeatureEncryption) && h.Encryption == nil {
+		return &IncompatibleVaultError{
+			Dir:      baseDir,
+			Reason:   fmt.Sprintf("%s marks the vault as encrypted but does not say how its key is derived", HeaderFile),
+			Guidance: fmt.Sprintf("Restore %s from a backup", HeaderFile),
+		}
+	}
+	return nil
+}
+
+// HasFeature reports whether a feature is enabled for the vault
+func (h *VaultHeader) HasFeature(feature string) bool {
+	for _, f := range h.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureHeader checks an existing header, or writes a fresh one for a new
+// or pre-header vault. An empty cluster on an existing header is filled in.
+func EnsureHeader(baseDir, cluster string) (*VaultHeader, error) {
+	header, err := ReadHeader(baseDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if header != nil {
+		if err := header.compatible(baseDir); err != nil {
+			return nil, err
+		}
+		if header.Cluster != "" || cluster == "" {
+			return header, nil
+		}
+		header.Cluster = cluster
+	} else {
+		header = &VaultHeader{
+			SchemaVersion: SchemaVersion,
+			CreatedAt:     time.Now().UTC(),
+			Features:      []string{},
+			Cluster:       cluster,
+		}
+	}
+
+	if err := writeHeader(baseDir, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// writeHeader saves the header via a temp file so readers never see a
+// partial vault.json
+func writeHeader(baseDir string, header *VaultHeader) error {
+	data, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault header: %w", err)
+	}
+
+	path := filepath.Join(baseDir, HeaderFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write vault header: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write vault header: %w", err)
+	}
+	return nil
+}
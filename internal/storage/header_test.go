@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/crypt"
+)
+
+func TestCheckVault(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  *VaultHeader // Nil means no vault.json
+		wantErr bool
+	}{
+		{"pre-header vault", nil, false},
+		{"current schema", &VaultHeader{SchemaVersion: SchemaVersion, Features: []string{}}, false},
+		{"supported feature", &VaultHeader{SchemaVersion: SchemaVersion, Features: []string{FeatureDedup}}, false},
+		{"encrypted", &VaultHeader{SchemaVersion: SchemaVersion, Features: []string{FeatureEncryption}, Encryption: &crypt.Params{}}, false},
+		{"encrypted without key parameters", &VaultHeader{SchemaVersion: SchemaVersion, Features: []string{FeatureEncryption}}, true},
+		{"newer schema", &VaultHeader{SchemaVersion: SchemaVersion + 1}, true},
+		{"invalid schema", &VaultHeader{SchemaVersion: 0}, true},
+		{"unknown feature", &VaultHeader{SchemaVersion: SchemaVersion, Features: []string{"sharding"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.header != nil {
+				data, err := json.Marshal(tt.header)
+				if err != nil {
+					t.Fatalf("Failed to marshal header: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, HeaderFile), data, 0644); err != nil {
+					t.Fatalf("Failed to write header: %v", err)
+				}
+			}
+
+			err := CheckVault(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckVault() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var incompatible *IncompatibleVaultError
+			if tt.wantErr && !errors.As(err, &incompatible) {
+				t.Errorf("Expected an IncompatibleVaultError, got %T", err)
+			}
+
+			// Opening the vault must refuse the same vaults
+			if _, err := NewFileStorage(dir); (err != nil) != tt.wantErr {
+				t.Errorf("NewFileStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnsureHeader(t *testing.T) {
+	dir := t.TempDir()
+
+	header, err := EnsureHeader(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to create header: %v", err)
+	}
+	if header.SchemaVersion != SchemaVersion || header.CreatedAt.IsZero() || header.Cluster != "" {
+		t.Errorf("Unexpected new header: %+v", header)
+	}
+
+	// A missing cluster is filled in later, but never overwritten
+	if header, err = EnsureHeader(dir, "devnet"); err != nil || header.Cluster != "devnet" {
+		t.Fatalf("Expected devnet cluster, got %+v (%v)", header, err)
+	}
+	if header, err = EnsureHeader(dir, "mainnet-beta"); err != nil || header.Cluster != "devnet" {
+		t.Fatalf("Cluster was overwritten: %+v (%v)", header, err)
+	}
+
+	stored, err := ReadHeader(dir)
+	if err != nil {
+		t.Fatalf("Failed to read header: %v", err)
+	}
+	if !stored.CreatedAt.Equal(header.CreatedAt) || stored.Cluster != "devnet" {
+		t.Errorf("Header on disk differs: %+v", stored)
+	}
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// indexFileName is the name of the index file kept at the vault root
+const indexFileName = "index.json"
+
+// IndexEntry describes a single stored NFT in the vault index
+type IndexEntry struct {
+	Wallet  string `json:"wallet"`
+	Mint    string `json:"mint"`
+	Name    string `json:"name"`     // Original NFT name, exactly as fetched
+	DirName string `json:"dir_name"` // On-disk directory name derived from Name or Mint
+	// Symbol and Collection let lookups find an NFT by what its owner calls it
+	Symbol     string `json:"symbol,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	// Attributes maps each trait type to its value, as in the metadata
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Status     HoldingStatus     `json:"status,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+
+	// PrimaryWallet is set when this wallet's record is linked to a backup
+	// stored under another wallet that held the same mint first
+	PrimaryWallet string `json:"primary_wallet,omitempty"`
+
+	// InProgress marks a backup whose record is saved but whose media and
+	// extras may not be; sync redoes it until CompleteBackup clears the mark
+	InProgress bool `json:"in_progress,omitempty"`
+}
+
+// Index keeps a small JSON catalogue of everything in the vault
+//
+// Explanation: Directory names are derived from NFT names when the name
+// layout is used, and that transformation is lossy. The index records the
+// original name next to the directory name so the mapping stays reversible.
+type Index struct {
+	path string // Empty for in-memory indexes, which are never written
+
+	mu      sync.Mutex
+	Entries map[string]*IndexEntry `json:"entries"` // Keyed by "wallet/mint"
+}
+
+// LoadIndex reads the index from baseDir, returning an empty index if none exists yet
+func LoadIndex(baseDir string) (*Index, error) {
+	idx := &Index{
+		path:    filepath.Join(baseDir, indexFileName),
+		Entries: make(map[string]*IndexEntry),
+	}
+
+	data, err := os.ReadFile(idx.path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", idx.path, err)
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]*IndexEntry)
+	}
+
+	return idx, nil
+}
+
+// NewMemoryIndex returns an empty index that lives only in memory
+func NewMemoryIndex() *Index {
+	return &Index{Entries: make(map[string]*IndexEntry)}
+}
+
+// Save writes the index back to disk (no-op for in-memory indexes)
+func (idx *Index) Save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	// Write to a temp file first so a crash never leaves a truncated index
+	tmpPath := idx.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmpPath, idx.path); err != nil {
+		return fmt.Errorf("failed to replace index: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the entry for a wallet/mint pair, or nil if it is not indexed
+func (idx *Index) Get(wallet, mint string) *IndexEntry {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	return idx.Entries[indexKey(wallet, mint)]
+}
+
+// Put adds or replaces an entry
+func (idx *Index) Put(entry *IndexEntry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entry.UpdatedAt = time.Now()
+	idx.Entries[indexKey(entry.Wallet, entry.Mint)] = entry
+}
+
+// Remove deletes the entry for a wallet/mint pair
+func (idx *Index) Remove(wallet, mint string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	delete(idx.Entries, indexKey(wallet, mint))
+}
+
+// DirNameTaken reports whether a directory name is already used within a wallet.
+// The comparison is case-insensitive because macOS and Windows filesystems are.
+func (idx *Index) DirNameTaken(wallet, dirName string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, entry := range idx.Entries {
+		if entry.Wallet == wallet && strings.EqualFold(entry.DirName, dirName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ByDirName reverses the name mapping for a directory inside a wallet
+func (idx *Index) ByDirName(wallet, dirName string) *IndexEntry {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, entry := range idx.Entries {
+		if entry.Wallet == wallet && entry.DirName == dirName {
+			return entry
+		}
+	}
+	return nil
+}
+
+// ByMint returns the entries for a mint across all wallets
+func (idx *Index) ByMint(mint string) []*IndexEntry {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var entries []*IndexEntry
+	for _, entry := range idx.Entries {
+		if entry.Mint == mint {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// List returns all entries sorted by wallet then name
+func (idx *Index) List() []*IndexEntry {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entries := make([]*IndexEntry, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Wallet != entries[j].Wallet {
+			return entries[i].Wallet < entries[j].Wallet
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// StorageWallet returns the wallet whose directory holds the entry's files
+func (e *IndexEntry) StorageWallet() string {
+	if e.PrimaryWallet != "" {
+		return e.PrimaryWallet
+	}
+	return e.Wallet
+}
+
+func indexKey(wallet, mint string) string {
+	return wallet + "/" + mint
+}
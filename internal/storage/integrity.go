@@ -0,0 +1,433 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/canonjson"
+	"github.com/NazWright/solvault/internal/crypt"
+)
+
+// IntegrityFile is the per-NFT manifest of file hashes written by every save
+const IntegrityFile = "integrity.json"
+
+// RawMetadataFile holds the off-chain metadata exactly as it was served
+const RawMetadataFile = "metadata.raw.json"
+
+// ExternalDir holds the snapshot of an NFT's external_url site inside its
+// backup directory
+const ExternalDir = "external"
+
+// integrityVersion is the format of integrity.json this build writes
+const integrityVersion = 1
+
+// FileDigest is the recorded hash of one backed-up file
+type FileDigest struct {
+	SHA256    string `json:"sha256"` // Hex, of the original content
+	Size      int64  `json:"size"`   // Bytes of the original content
+	Canonical bool   `json:"canonical,omitempty"`
+}
+
+// Integrity lists the hash of every file in an NFT backup
+//
+// Explanation: Files are hashed by their original content, before
+// compression and encryption, so the manifest stays valid when either is
+// turned on or off. Metadata files are hashed in their canonical JSON form
+// (Canonical is set), so reformatting them without changing a value does
+// not count as tampering. The manifest is written in the same transaction
+// as the files it describes, so it always matches the last completed save.
+type Integrity struct {
+	Version     int                   `json:"version"`
+	Algorithm   string                `json:"algorithm"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Files       map[string]FileDigest `json:"files"` // Keyed by slash-separated path within the NFT directory
+}
+
+// IntegrityStatus is the outcome of checking one file
+type IntegrityStatus string
+
+const (
+	IntegrityOK        IntegrityStatus = "ok"
+	IntegrityModified  IntegrityStatus = "modified"  // The content no longer matches its hash
+	IntegrityMissing   IntegrityStatus = "missing"   // A recorded file is gone
+	IntegrityUntracked IntegrityStatus = "untracked" // A backup file the manifest does not list
+)
+
+// FileCheck is the result of checking one file against integrity.json
+type FileCheck struct {
+	Path     string          `json:"path"`
+	Status   IntegrityStatus `json:"status"`
+	Expected string          `json:"expected,omitempty"`
+	Actual   string          `json:"actual,omitempty"`
+	Error    string          `json:"error,omitempty"` // Why the file could not be read
+}
+
+// IntegrityReport is the result of checking an NFT directory
+type IntegrityReport struct {
+	GeneratedAt time.Time   `json:"generated_at"` // When the manifest was written
+	Files       []FileCheck `json:"files"`
+}
+
+// OK reports whether every recorded file is present and unchanged.
+// Untracked files are reported but do not fail the check.
+func (r *IntegrityReport) OK() bool {
+	for _, file := range r.Files {
+		if file.Status == IntegrityModified || file.Status == IntegrityMissing {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns how many files have status
+func (r *IntegrityReport) Count(status IntegrityStatus) int {
+	n := 0
+	for _, file := range r.Files {
+		if file.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// integrityNames lists the backup files in an NFT directory that the
+// manifest covers: the JSON records, metadata snapshots, media, thumbnails
+// and archived sites. Proofs, reports and the legacy hash.txt are derived
+// from the backup rather than part of it, so they are left out.
+func integrityNames(nftDir string) ([]string, error) {
+	entries, err := os.ReadDir(nftDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", nftDir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && integrityTracked(name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range mediaNames(nftDir) {
+		names = append(names, path.Join("media", name))
+	}
+
+	// An archived site may nest directories
+	external := filepath.Join(nftDir, ExternalDir)
+	err = filepath.WalkDir(external, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != external {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			rel, err := filepath.Rel(nftDir, p)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", external, err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// integrityTracked reports whether a top-level file belongs in the manifest.
+// image.* covers the flat layout of backups made before the vault format.
+func integrityTracked(name string) bool {
+	switch {
+	case name == "nft_data.json", name == TokenDataFile, name == "metadata.json", name == RawMetadataFile, name == "media_manifest.json":
+		return true
+	case strings.HasPrefix(name, "metadata.v") && strings.HasSuffix(name, ".json"):
+		return true
+	case strings.HasPrefix(name, "image."):
+		return true
+	}
+	return false
+}
+
+// canonicalMetadata reports whether a manifest entry is hashed in its
+// canonical JSON form: the current, raw and versioned metadata
+func canonicalMetadata(name string) bool {
+	return name == "metadata.json" || name == RawMetadataFile || (strings.HasPrefix(name, "metadata.v") && strings.HasSuffix(name, ".json"))
+}
+
+// digestName hashes the file holding name for the manifest. Metadata that
+// does not parse falls back to a byte hash, so it is still recorded.
+func (fs *FileStorage) digestName(name, filePath string) (FileDigest, error) {
+	if canonicalMetadata(name) {
+		digest, err := fs.canonicalDigest(filePath)
+		if !errors.Is(err, errNotJSON) {
+			return digest, err
+		}
+	}
+	return fs.digestFile(filePath)
+}
+
+// errNotJSON marks metadata that cannot be canonicalized
+var errNotJSON = errors.New("not valid JSON")
+
+// canonicalDigest hashes a JSON file's canonical form. Size is that of the
+// file's original content.
+func (fs *FileStorage) canonicalDigest(filePath string) (FileDigest, error) {
+	data, err := fs.ReadFile(filePath)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	hash, err := canonjson.Hash(data)
+	if err != nil {
+		return FileDigest{}, fmt.Errorf("%w: %v", errNotJSON, err)
+	}
+	return FileDigest{SHA256: hash, Size: int64(len(data)), Canonical: true}, nil
+}
+
+// digestFile hashes a vault file by its original content. Plain files are
+// streamed; encrypted and compressed ones are decoded in memory first.
+func (fs *FileStorage) digestFile(filePath string) (FileDigest, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(max(crypt.MagicSize(), CompressedMagicSize()))
+	if crypt.IsSealed(head) || IsCompressed(head) {
+		data, err := fs.ReadFile(filePath)
+		if err != nil {
+			return FileDigest{}, err
+		}
+		return FileDigest{SHA256: fmt.Sprintf("%x", sha256.Sum256(data)), Size: int64(len(data))}, nil
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	return FileDigest{SHA256: fmt.Sprintf("%x", hasher.Sum(nil)), Size: size}, nil
+}
+
+// DigestCopy hashes data, a copy of a backup file kept outside the vault,
+// the same way as want was, so the copy can be checked against the manifest
+func (fs *FileStorage) DigestCopy(data []byte, want FileDigest) (FileDigest, error) {
+	data, err := fs.decode(data)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	if want.Canonical {
+		hash, err := canonjson.Hash(data)
+		if err != nil {
+			return FileDigest{}, fmt.Errorf("%w: %v", errNotJSON, err)
+		}
+		return FileDigest{SHA256: hash, Size: int64(len(data)), Canonical: true}, nil
+	}
+	return FileDigest{SHA256: fmt.Sprintf("%x", sha256.Sum256(data)), Size: int64(len(data))}, nil
+}
+
+// MatchesDigest reports whether the file at filePath, which need not be in
+// the vault yet, holds the content want records
+func (fs *FileStorage) MatchesDigest(filePath string, want FileDigest) (bool, error) {
+	var digest FileDigest
+	var err error
+	if want.Canonical {
+		digest, err = fs.canonicalDigest(filePath)
+	} else {
+		digest, err = fs.digestFile(filePath)
+	}
+	if err != nil {
+		return false, err
+	}
+	return digest.SHA256 == want.SHA256 && (want.Canonical || digest.Size == want.Size), nil
+}
+
+// buildIntegrity hashes the files in names. locate maps a name to the
+// file holding its content, which differs from the live path for files
+// staged in a transaction.
+func (fs *FileStorage) buildIntegrity(nftDir string, names []string, locate func(name string) string) (*Integrity, error) {
+	integrity := &Integrity{
+		Version:     integrityVersion,
+		Algorithm:   "sha256",
+		GeneratedAt: time.Now(),
+		Files:       make(map[string]FileDigest, len(names)),
+	}
+	for _, name := range names {
+		digest, err := fs.digestName(name, locate(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		integrity.Files[name] = digest
+	}
+	return integrity, nil
+}
+
+// stageIntegrity stages integrity.json covering the files this save
+// stages and the ones it leaves in place
+func (t *fileTransaction) stageIntegrity() error {
+	names, err := integrityNames(t.dir)
+	if err != nil {
+		return err
+	}
+	staged := make(map[string]bool, len(t.staged))
+	for _, name := range t.staged {
+		staged[name] = true
+	}
+	for _, name := range t.staged {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	integrity, err := t.fs.buildIntegrity(t.dir, names, func(name string) string {
+		if staged[name] {
+			return stagedPath(t.dir, name)
+		}
+		return filepath.Join(t.dir, filepath.FromSlash(name))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build integrity manifest: %w", err)
+	}
+	return t.stage(IntegrityFile, integrity)
+}
+
+// WriteIntegrity hashes the files an NFT directory holds now and writes
+// them to integrity.json. It migrates backups made before the manifest
+// existed; saves write their own.
+func (fs *FileStorage) WriteIntegrity(nftDir string) (*Integrity, error) {
+	names, err := integrityNames(nftDir)
+	if err != nil {
+		return nil, err
+	}
+	integrity, err := fs.buildIntegrity(nftDir, names, func(name string) string {
+		return filepath.Join(nftDir, filepath.FromSlash(name))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build integrity manifest: %w", err)
+	}
+	if err := fs.saveJSON(filepath.Join(nftDir, IntegrityFile), integrity); err != nil {
+		return nil, fmt.Errorf("failed to write integrity manifest: %w", err)
+	}
+	return integrity, nil
+}
+
+// refreshIntegrity records a deliberate change made to an NFT directory
+// outside a save: each of names is rehashed, or dropped from the manifest
+// if it was removed. Backups without a manifest are left to WriteIntegrity.
+func (fs *FileStorage) refreshIntegrity(nftDir string, names ...string) error {
+	integrity, err := fs.ReadIntegrity(nftDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		digest, err := fs.digestName(name, filepath.Join(nftDir, filepath.FromSlash(name)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			delete(integrity.Files, name)
+		case err != nil:
+			return fmt.Errorf("failed to hash %s: %w", name, err)
+		default:
+			integrity.Files[name] = digest
+		}
+	}
+	integrity.GeneratedAt = time.Now()
+	if err := fs.saveJSON(filepath.Join(nftDir, IntegrityFile), integrity); err != nil {
+		return fmt.Errorf("failed to write integrity manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadIntegrity loads an NFT directory's integrity.json. The error wraps
+// os.ErrNotExist for backups that predate the manifest.
+func (fs *FileStorage) ReadIntegrity(nftDir string) (*Integrity, error) {
+	var integrity Integrity
+	if err := fs.loadJSON(filepath.Join(nftDir, IntegrityFile), &integrity); err != nil {
+		return nil, fmt.Errorf("failed to read integrity manifest: %w", err)
+	}
+	if integrity.Version > integrityVersion {
+		return nil, fmt.Errorf("integrity manifest version %d is newer than this SolVault supports (%d)", integrity.Version, integrityVersion)
+	}
+	if integrity.Algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported integrity algorithm %q", integrity.Algorithm)
+	}
+	return &integrity, nil
+}
+
+// CheckIntegrity rehashes every file recorded in an NFT directory's
+// integrity.json and reports which are unchanged, modified or missing, and
+// which backup files the manifest does not list
+func (fs *FileStorage) CheckIntegrity(nftDir string) (*IntegrityReport, error) {
+	integrity, err := fs.ReadIntegrity(nftDir)
+	if err != nil {
+		return nil, err
+	}
+	report := &IntegrityReport{GeneratedAt: integrity.GeneratedAt, Files: []FileCheck{}}
+
+	recorded := make([]string, 0, len(integrity.Files))
+	for name := range integrity.Files {
+		recorded = append(recorded, name)
+	}
+	sort.Strings(recorded)
+	for _, name := range recorded {
+		want := integrity.Files[name]
+		check := FileCheck{Path: name, Expected: want.SHA256}
+		filePath := filepath.Join(nftDir, filepath.FromSlash(name))
+		var digest FileDigest
+		if want.Canonical {
+			digest, err = fs.canonicalDigest(filePath)
+		} else {
+			digest, err = fs.digestFile(filePath)
+		}
+		if errors.Is(err, crypt.ErrLocked) {
+			return nil, err
+		}
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			check.Status = IntegrityMissing
+		case err != nil:
+			// A file that cannot be decoded is as unusable as a changed one
+			check.Status = IntegrityModified
+			check.Error = err.Error()
+		case digest.SHA256 != want.SHA256 || (!want.Canonical && digest.Size != want.Size):
+			check.Status = IntegrityModified
+			check.Actual = digest.SHA256
+		default:
+			check.Status = IntegrityOK
+			check.Actual = digest.SHA256
+		}
+		report.Files = append(report.Files, check)
+	}
+
+	present, err := integrityNames(nftDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range present {
+		if _, ok := integrity.Files[name]; !ok {
+			report.Files = append(report.Files, FileCheck{Path: name, Status: IntegrityUntracked})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Integrity(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string)
+		wantOK   bool
+		wantFile string // File with a status other than ok
+		want     IntegrityStatus
+	}{
+		{
+			name:   "unchanged",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {},
+			wantOK: true,
+		},
+		{
+			name: "media edited",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "media", "image.png"), []byte("bit rot"))
+			},
+			wantFile: "media/image.png",
+			want:     IntegrityModified,
+		},
+		{
+			name: "metadata edited",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "metadata.json"), []byte(`{"name": "Forged"}`))
+			},
+			wantFile: "metadata.json",
+			want:     IntegrityModified,
+		},
+		{
+			name: "metadata reformatted",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "metadata.json"), []byte("{\"symbol\":\"\",\"name\":\"Shared NFT\",  \"image\":\"\",\n\"description\":\"\", \"properties\": {}, \"collection\":{\"name\":\"\",\"family\":\"\"}}"))
+			},
+			wantOK: true,
+		},
+		{
+			name: "media removed",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				os.Remove(filepath.Join(dir, "media", "image.png"))
+			},
+			wantFile: "media/image.png",
+			want:     IntegrityMissing,
+		},
+		{
+			name: "file added",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				writeFile(t, filepath.Join(dir, "media", "extra.png"), []byte("dropped in"))
+			},
+			wantOK:   true,
+			wantFile: "media/extra.png",
+			want:     IntegrityUntracked,
+		},
+		{
+			name: "status change",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				if err := store.SetStatus(context.Background(), nft.Owner, nft.MintAddress, StatusTransferred); err != nil {
+					t.Fatalf("Failed to set status: %v", err)
+				}
+			},
+			wantOK: true,
+		},
+		{
+			name: "compressed afterwards",
+			change: func(t *testing.T, store *FileStorage, nft *fetcher.NFTInfo, dir string) {
+				if _, err := store.EnableCompression(); err != nil {
+					t.Fatalf("Failed to enable compression: %v", err)
+				}
+			},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, nft, dir := fsckVault(t)
+			tt.change(t, store, nft, dir)
+
+			report, err := store.CheckIntegrity(dir)
+			if err != nil {
+				t.Fatalf("Failed to check integrity: %v", err)
+			}
+			if report.OK() != tt.wantOK {
+				t.Errorf("Got OK = %v, want %v: %+v", report.OK(), tt.wantOK, report.Files)
+			}
+			for _, file := range report.Files {
+				switch {
+				case file.Path == tt.wantFile && file.Status != tt.want:
+					t.Errorf("Got %s %s, want %s", file.Path, file.Status, tt.want)
+				case file.Path != tt.wantFile && file.Status != IntegrityOK:
+					t.Errorf("Got %s %s, want ok", file.Path, file.Status)
+				}
+			}
+			if tt.wantFile != "" && report.Count(tt.want) != 1 {
+				t.Errorf("Got %d %s files, want 1", report.Count(tt.want), tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStorage_IntegrityCoversBackup(t *testing.T) {
+	store, _, dir := fsckVault(t)
+
+	integrity, err := store.ReadIntegrity(dir)
+	if err != nil {
+		t.Fatalf("Failed to read integrity manifest: %v", err)
+	}
+	for _, name := range []string{"nft_data.json", "metadata.json", "media_manifest.json", "media/image.png"} {
+		if _, ok := integrity.Files[name]; !ok {
+			t.Errorf("Manifest lacks %s: %v", name, integrity.Files)
+		}
+	}
+	if _, ok := integrity.Files[IntegrityFile]; ok {
+		t.Errorf("Manifest lists itself")
+	}
+}
+
+func TestFileStorage_WriteIntegrity(t *testing.T) {
+	store, _, dir := fsckVault(t)
+
+	// A backup from before the manifest existed
+	if err := os.Remove(filepath.Join(dir, IntegrityFile)); err != nil {
+		t.Fatalf("Failed to remove manifest: %v", err)
+	}
+	if _, err := store.CheckIntegrity(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Got %v checking a backup without a manifest, want not exist", err)
+	}
+
+	written, err := store.WriteIntegrity(dir)
+	if err != nil {
+		t.Fatalf("Failed to write integrity manifest: %v", err)
+	}
+	if len(written.Files) != 4 {
+		t.Errorf("Got %d files, want 4: %v", len(written.Files), written.Files)
+	}
+	report, err := store.CheckIntegrity(dir)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %v", err)
+	}
+	if !report.OK() || report.Count(IntegrityOK) != 4 {
+		t.Errorf("Got %+v after migration, want 4 ok files", report.Files)
+	}
+}
+
+func TestFileStorage_RawMetadata(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		t.Run(map[bool]string{false: "plain", true: "compressed"}[compressed], func(t *testing.T) {
+			store, err := NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			if compressed {
+				if _, err := store.EnableCompression(); err != nil {
+					t.Fatalf("Failed to enable compression: %v", err)
+				}
+			}
+			nft := custodyNFT(solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey())
+			nft.RawMetadata = []byte("{\n    \"name\" : \"Shared NFT\",\n    \"extra\": [1.50, \"kept\"]\n}\n")
+			if err := store.SaveNFT(context.Background(), nft); err != nil {
+				t.Fatalf("Failed to save NFT: %v", err)
+			}
+
+			dir := store.NFTDir(nft.Owner, nft.MintAddress)
+			raw, err := store.ReadFile(filepath.Join(dir, RawMetadataFile))
+			if err != nil {
+				t.Fatalf("Failed to read raw metadata: %v", err)
+			}
+			if string(raw) != string(nft.RawMetadata) {
+				t.Errorf("Got raw metadata %q, want %q", raw, nft.RawMetadata)
+			}
+
+			integrity, err := store.ReadIntegrity(dir)
+			if err != nil {
+				t.Fatalf("Failed to read integrity manifest: %v", err)
+			}
+			digest := integrity.Files[RawMetadataFile]
+			if !digest.Canonical || digest.Size != int64(len(nft.RawMetadata)) {
+				t.Errorf("Got digest %+v, want a canonical one of %d bytes", digest, len(nft.RawMetadata))
+			}
+
+			// A gateway serving the same values compactly still matches
+			writeFile(t, filepath.Join(dir, RawMetadataFile), []byte(`{"extra":[1.5,"kept"],"name":"Shared NFT"}`))
+			report, err := store.CheckIntegrity(dir)
+			if err != nil {
+				t.Fatalf("Failed to check integrity: %v", err)
+			}
+			if !report.OK() {
+				t.Errorf("Reformatted raw metadata failed the check: %+v", report.Files)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NazWright/solvault/internal/merkle"
+)
+
+// MerkleFile records a wallet's Merkle root, next to its nfts directory
+const MerkleFile = "merkle.json"
+
+// Digest returns one hash over every file the manifest records, so two
+// manifests describing the same contents have the same digest whenever they
+// were written
+func (i *Integrity) Digest() string {
+	names := make([]string, 0, len(i.Files))
+	for name := range i.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\n", name, i.Files[name].SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MerkleLeaf is one NFT backup in a wallet's Merkle tree
+type MerkleLeaf struct {
+	Mint   string `json:"mint"`
+	Digest string `json:"digest"` // Integrity.Digest of the backup
+}
+
+// hash returns the leaf as it is hashed into the tree
+func (l MerkleLeaf) hash() []byte {
+	return merkle.LeafHash([]byte(l.Mint + "\n" + l.Digest))
+}
+
+// MerkleRecord is a root a wallet's backup had at some point
+type MerkleRecord struct {
+	Root       string    `json:"root"`
+	Leaves     int       `json:"leaves"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// WalletMerkle is a Merkle tree over every NFT backup stored for a wallet.
+// Its root changes whenever a backup is saved with different files, so the
+// root alone pins down the state of the whole wallet's backup.
+//
+// Explanation: Leaves are the per-NFT integrity digests rather than the
+// files themselves, so updating the tree only reads integrity.json files,
+// and proving one NFT needs that NFT's manifest plus a short audit path.
+// Records linked to another wallet's backup share its directory, so they
+// are covered by that wallet's root.
+type WalletMerkle struct {
+	Wallet     string         `json:"wallet"`
+	Root       string         `json:"root"`
+	ComputedAt time.Time      `json:"computed_at"`
+	Leaves     []MerkleLeaf   `json:"leaves"`             // Sorted by mint
+	Unhashed   []string       `json:"unhashed,omitempty"` // Mints whose backup has no integrity.json yet
+	History    []MerkleRecord `json:"history,omitempty"`  // Earlier roots, oldest first
+}
+
+// InclusionProof shows that one NFT backup is part of a wallet's root
+type InclusionProof struct {
+	Wallet string   `json:"wallet"`
+	Root   string   `json:"root"`
+	Mint   string   `json:"mint"`
+	Digest string   `json:"digest"`
+	Index  int      `json:"leaf_index"`
+	Size   int      `json:"tree_size"`
+	Path   []string `json:"audit_path"` // Sibling hashes, bottom up
+}
+
+// ComputeMerkle builds a wallet's Merkle tree from the integrity.json of
+// every backup stored under it
+func (fs *FileStorage) ComputeMerkle(wallet string) (*WalletMerkle, error) {
+	tree := &WalletMerkle{Wallet: wallet, ComputedAt: time.Now().UTC()}
+	for _, entry := range fs.index.List() {
+		if entry.Wallet != wallet || entry.PrimaryWallet != "" {
+			continue
+		}
+		dir := filepath.Join(fs.baseDir, "wallets", wallet, "nfts", entry.DirName)
+		integrity, err := fs.ReadIntegrity(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			tree.Unhashed = append(tree.Unhashed, entry.Mint)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", entry.Mint, err)
+		}
+		tree.Leaves = append(tree.Leaves, MerkleLeaf{Mint: entry.Mint, Digest: integrity.Digest()})
+	}
+	sort.Slice(tree.Leaves, func(i, j int) bool { return tree.Leaves[i].Mint < tree.Leaves[j].Mint })
+	sort.Strings(tree.Unhashed)
+	tree.Root = hex.EncodeToString(merkle.Root(tree.leafHashes()))
+	return tree, nil
+}
+
+// UpdateMerkle recomputes a wallet's Merkle tree and saves it to the
+// wallet's merkle.json, moving the previous root into the history when it
+// changed
+func (fs *FileStorage) UpdateMerkle(wallet string) (*WalletMerkle, error) {
+	tree, err := fs.ComputeMerkle(wallet)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := fs.LoadMerkle(wallet)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if previous != nil {
+		tree.History = previous.History
+		if previous.Root != tree.Root {
+			tree.History = append(tree.History, MerkleRecord{
+				Root:       previous.Root,
+				Leaves:     len(previous.Leaves),
+				ComputedAt: previous.ComputedAt,
+			})
+		}
+	}
+
+	path := filepath.Join(fs.baseDir, "wallets", wallet, MerkleFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+	if err := fs.saveJSON(path, tree); err != nil {
+		return nil, fmt.Errorf("failed to save Merkle root: %w", err)
+	}
+	return tree, nil
+}
+
+// LoadMerkle reads the Merkle tree last saved for a wallet. The error wraps
+// os.ErrNotExist when none has been saved yet.
+func (fs *FileStorage) LoadMerkle(wallet string) (*WalletMerkle, error) {
+	var tree WalletMerkle
+	if err := fs.loadJSON(filepath.Join(fs.baseDir, "wallets", wallet, MerkleFile), &tree); err != nil {
+		return nil, fmt.Errorf("failed to read Merkle root: %w", err)
+	}
+	return &tree, nil
+}
+
+func (m *WalletMerkle) leafHashes() [][]byte {
+	hashes := make([][]byte, len(m.Leaves))
+	for i, leaf := range m.Leaves {
+		hashes[i] = leaf.hash()
+	}
+	return hashes
+}
+
+// Prove returns the inclusion proof of a mint's backup in the tree
+func (m *WalletMerkle) Prove(mint string) (*InclusionProof, error) {
+	index := sort.Search(len(m.Leaves), func(i int) bool { return m.Leaves[i].Mint >= mint })
+	if index == len(m.Leaves) || m.Leaves[index].Mint != mint {
+		return nil, fmt.Errorf("mint %s is not in the Merkle tree of %s", mint, m.Wallet)
+	}
+	path, err := merkle.Proof(m.leafHashes(), index)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &InclusionProof{
+		Wallet: m.Wallet,
+		Root:   m.Root,
+		Mint:   mint,
+		Digest: m.Leaves[index].Digest,
+		Index:  index,
+		Size:   len(m.Leaves),
+	}
+	for _, sibling := range path {
+		proof.Path = append(proof.Path, hex.EncodeToString(sibling))
+	}
+	return proof, nil
+}
+
+// Verify reports whether the proof's audit path leads from its leaf to its
+// root
+func (p *InclusionProof) Verify() bool {
+	root, err := hex.DecodeString(p.Root)
+	if err != nil {
+		return false
+	}
+	path := make([][]byte, len(p.Path))
+	for i, sibling := range p.Path {
+		if path[i], err = hex.DecodeString(sibling); err != nil {
+			return false
+		}
+	}
+	leaf := MerkleLeaf{Mint: p.Mint, Digest: p.Digest}
+	return merkle.Verify(leaf.hash(), p.Index, p.Size, path, root)
+}
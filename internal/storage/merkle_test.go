@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Merkle(t *testing.T) {
+	store, nft, dir := fsckVault(t)
+	wallet := nft.Owner.String()
+	for i := 0; i < 4; i++ {
+		if err := store.SaveNFT(context.Background(), custodyNFT(solanago.NewWallet().PublicKey(), nft.Owner)); err != nil {
+			t.Fatalf("Failed to save NFT: %v", err)
+		}
+	}
+
+	first, err := store.UpdateMerkle(wallet)
+	if err != nil {
+		t.Fatalf("Failed to update Merkle root: %v", err)
+	}
+	if len(first.Leaves) != 5 || len(first.Unhashed) != 0 {
+		t.Fatalf("Got %d leaves and %d unhashed, want 5 and 0", len(first.Leaves), len(first.Unhashed))
+	}
+	for _, leaf := range first.Leaves {
+		proof, err := first.Prove(leaf.Mint)
+		if err != nil {
+			t.Fatalf("Failed to prove %s: %v", leaf.Mint, err)
+		}
+		if !proof.Verify() {
+			t.Errorf("Proof for %s does not verify", leaf.Mint)
+		}
+		proof.Digest = first.Leaves[0].Digest + "00"
+		if proof.Verify() {
+			t.Errorf("Proof for %s verifies with a forged digest", leaf.Mint)
+		}
+	}
+
+	// Recomputing an unchanged vault gives the same root
+	again, err := store.ComputeMerkle(wallet)
+	if err != nil {
+		t.Fatalf("Failed to compute Merkle root: %v", err)
+	}
+	if again.Root != first.Root {
+		t.Errorf("Got root %s for an unchanged vault, want %s", again.Root, first.Root)
+	}
+
+	// Any change to a backup moves the root, and the old one is kept
+	if err := store.SetStatus(context.Background(), nft.Owner, nft.MintAddress, StatusTransferred); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	second, err := store.UpdateMerkle(wallet)
+	if err != nil {
+		t.Fatalf("Failed to update Merkle root: %v", err)
+	}
+	if second.Root == first.Root {
+		t.Errorf("Root did not change after a backup changed")
+	}
+	if len(second.History) != 1 || second.History[0].Root != first.Root {
+		t.Errorf("Got history %+v, want the first root", second.History)
+	}
+
+	loaded, err := store.LoadMerkle(wallet)
+	if err != nil {
+		t.Fatalf("Failed to load Merkle root: %v", err)
+	}
+	if loaded.Root != second.Root {
+		t.Errorf("Got saved root %s, want %s", loaded.Root, second.Root)
+	}
+
+	// A backup from before integrity.json is listed rather than hashed
+	if err := os.Remove(filepath.Join(dir, IntegrityFile)); err != nil {
+		t.Fatalf("Failed to remove manifest: %v", err)
+	}
+	third, err := store.ComputeMerkle(wallet)
+	if err != nil {
+		t.Fatalf("Failed to compute Merkle root: %v", err)
+	}
+	if len(third.Leaves) != 4 || len(third.Unhashed) != 1 || third.Unhashed[0] != nft.MintAddress.String() {
+		t.Errorf("Got %d leaves and unhashed %v, want 4 and the unmanifested mint", len(third.Leaves), third.Unhashed)
+	}
+	if _, err := third.Prove(nft.MintAddress.String()); err == nil {
+		t.Errorf("Expected an error proving an unhashed mint")
+	}
+}
+
+func TestIntegrity_Digest(t *testing.T) {
+	a := &Integrity{Files: map[string]FileDigest{"a.json": {SHA256: "01"}, "media/b.png": {SHA256: "02"}}}
+	b := &Integrity{Files: map[string]FileDigest{"media/b.png": {SHA256: "02", Size: 9}, "a.json": {SHA256: "01"}}}
+	if a.Digest() != b.Digest() {
+		t.Errorf("Manifests with the same hashes have different digests")
+	}
+	b.Files["a.json"] = FileDigest{SHA256: "03"}
+	if a.Digest() == b.Digest() {
+		t.Errorf("Manifests with different hashes have the same digest")
+	}
+}
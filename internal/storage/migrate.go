@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// RecordSchemaVersion is the nft_data.json layout this build writes. It is
+// separate from StoredNFT.Version, which counts metadata versions.
+const RecordSchemaVersion = 2
+
+// Migration upgrades a stored JSON record from one schema version to the
+// next. Apply gets the record as stored and returns it upgraded; the
+// caller sets schema_version afterwards.
+//
+// Explanation: Migrations work on raw JSON rather than on StoredNFT, so one
+// can rename or reshape a field that the current struct no longer has. A
+// migration that only fills in fields may decode into the current struct, as
+// long as no later migration removes one of them.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(data []byte) ([]byte, error)
+}
+
+// RecordMigrations upgrade nft_data.json, oldest first; the last one
+// reaches RecordSchemaVersion
+var RecordMigrations = []Migration{
+	{From: 1, Description: "record the holding status, metadata versions and custody of older backups explicitly", Apply: migrateRecordV1},
+}
+
+// migrateRecordV1 fills in what readers used to infer from v1 records: an
+// empty status meant held, and backups made before metadata versions or
+// custody were tracked had one version and one custody period
+func migrateRecordV1(data []byte) ([]byte, error) {
+	var stored StoredNFT
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Status == "" {
+		stored.Status = StatusHeld
+	}
+	if stored.Version < 1 {
+		stored.Version = 1
+	}
+	if stored.NFTInfo != nil {
+		stored.Versions = seedVersions(&stored)
+		stored.Custody = seedCustody(&stored)
+	}
+	return json.Marshal(&stored)
+}
+
+// schemaVersion reads a record's schema_version; records written before
+// versioning are version 1
+func schemaVersion(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	if header.SchemaVersion == 0 {
+		return 1, nil
+	}
+	return header.SchemaVersion, nil
+}
+
+// migrate runs migrations over data until it reaches target, reporting
+// whether anything changed. Records newer than target are refused rather
+// than read with fields this build does not know.
+func migrate(data []byte, migrations []Migration, target int) ([]byte, bool, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > target {
+		return nil, false, fmt.Errorf("record is schema version %d, newer than this build reads (%d); upgrade SolVault", version, target)
+	}
+	if version == target {
+		return data, false, nil
+	}
+
+	for _, migration := range migrations {
+		if migration.From != version {
+			continue
+		}
+		if data, err = migration.Apply(data); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate from schema version %d: %w", version, err)
+		}
+		version++
+		if data, err = setSchemaVersion(data, version); err != nil {
+			return nil, false, err
+		}
+	}
+	if version != target {
+		return nil, false, fmt.Errorf("no migration from schema version %d", version)
+	}
+	return data, true, nil
+}
+
+// setSchemaVersion writes schema_version into a JSON object, keeping every
+// other field as it is
+func setSchemaVersion(data []byte, version int) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	fields["schema_version"] = json.RawMessage(strconv.Itoa(version))
+	return json.Marshal(fields)
+}
+
+// MigrateRecord upgrades raw nft_data.json to RecordSchemaVersion,
+// reporting whether it changed. Every read of a record goes through it, so
+// older records are always seen in the current layout.
+func MigrateRecord(data []byte) ([]byte, bool, error) {
+	return migrate(data, RecordMigrations, RecordSchemaVersion)
+}
+
+// MigrationReport is the outcome of rewriting a vault's records
+type MigrationReport struct {
+	Checked   int               `json:"checked"`
+	Migrated  int               `json:"migrated"` // Rewritten, or to be with a dry run
+	ByVersion map[int]int       `json:"by_version"`
+	Failed    map[string]string `json:"failed,omitempty"` // Record path to error
+}
+
+// MigrateRecords rewrites every nft_data.json below RecordSchemaVersion in
+// the current layout, refreshing the integrity manifests and Merkle roots
+// that cover them. With dryRun only the report is produced.
+//
+// Explanation: Reads migrate on the fly, so this is never required. It saves
+// doing so on every read and lets tools that read the JSON directly see the
+// current layout.
+func (fs *FileStorage) MigrateRecords(ctx context.Context, dryRun bool) (*MigrationReport, error) {
+	report := &MigrationReport{ByVersion: make(map[int]int), Failed: make(map[string]string)}
+	dirs, err := filepath.Glob(filepath.Join(fs.baseDir, "wallets", "*", "nfts", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NFT directories: %w", err)
+	}
+	sort.Strings(dirs)
+
+	wallets := make(map[string]bool)
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		path := filepath.Join(dir, "nft_data.json")
+		if !dryRun {
+			if err := recoverTransaction(dir); err != nil {
+				report.Failed[path] = err.Error()
+				continue
+			}
+		}
+		data, err := fs.ReadFile(path)
+		if err != nil {
+			continue // Not a backup, or an interrupted first save
+		}
+		report.Checked++
+		version, err := schemaVersion(data)
+		if err != nil {
+			report.Failed[path] = err.Error()
+			continue
+		}
+		report.ByVersion[version]++
+		if version >= RecordSchemaVersion {
+			continue
+		}
+
+		migrated, _, err := MigrateRecord(data)
+		if err != nil {
+			report.Failed[path] = err.Error()
+			continue
+		}
+		if dryRun {
+			report.Migrated++
+			continue
+		}
+		var stored StoredNFT
+		if err := json.Unmarshal(migrated, &stored); err != nil {
+			report.Failed[path] = err.Error()
+			continue
+		}
+		if err := fs.saveJSON(path, &stored); err != nil {
+			report.Failed[path] = err.Error()
+			continue
+		}
+		if err := fs.refreshIntegrity(dir, "nft_data.json"); err != nil {
+			report.Failed[path] = err.Error()
+			continue
+		}
+		report.Migrated++
+		wallets[filepath.Base(filepath.Dir(filepath.Dir(dir)))] = true
+	}
+
+	for wallet := range wallets {
+		if _, err := fs.UpdateMerkle(wallet); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
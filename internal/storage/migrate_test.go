@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// legacyRecord is an nft_data.json as written before records were versioned:
+// no schema_version, status, metadata versions or custody
+func legacyRecord(t *testing.T, mint, owner solanago.PublicKey, storedAt time.Time) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"nft_info":   custodyNFT(mint, owner),
+		"stored_at":  storedAt,
+		"updated_at": storedAt,
+		"version":    1,
+		"checksum":   "legacy",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal legacy record: %v", err)
+	}
+	return data
+}
+
+func TestMigrateRecord(t *testing.T) {
+	mint, owner := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	storedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	legacy := legacyRecord(t, mint, owner, storedAt)
+
+	migrated, changed, err := MigrateRecord(legacy)
+	if err != nil {
+		t.Fatalf("Failed to migrate v1 record: %v", err)
+	}
+	if !changed {
+		t.Fatalf("Expected the v1 record to change")
+	}
+	var stored StoredNFT
+	if err := json.Unmarshal(migrated, &stored); err != nil {
+		t.Fatalf("Failed to unmarshal migrated record: %v", err)
+	}
+	if stored.SchemaVersion != RecordSchemaVersion || stored.Status != StatusHeld || stored.Checksum != "legacy" {
+		t.Errorf("Expected a held v%d record with its fields kept, got %+v", RecordSchemaVersion, stored)
+	}
+	if len(stored.Versions) != 1 || stored.Versions[0].Version != 1 || stored.Versions[0].Fingerprint != MetadataFingerprint(stored.NFTInfo) {
+		t.Errorf("Expected one seeded metadata version, got %+v", stored.Versions)
+	}
+	if len(stored.Custody) != 1 || stored.Custody[0].Wallet != owner.String() || !stored.Custody[0].From.Equal(storedAt) {
+		t.Errorf("Expected one custody period from the first backup, got %+v", stored.Custody)
+	}
+
+	// Current records pass through untouched
+	again, changed, err := MigrateRecord(migrated)
+	if err != nil || changed || string(again) != string(migrated) {
+		t.Errorf("Expected a current record unchanged, got changed=%v err=%v", changed, err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"newer schema", `{"schema_version": 99}`},
+		{"not JSON", `{"nft_info":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := MigrateRecord([]byte(tt.data)); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestFileStorage_MigrateRecords(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	mint, owner := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	if err := store.SaveNFT(ctx, custodyNFT(mint, owner)); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	stored, err := store.GetNFT(ctx, owner, mint)
+	if err != nil || stored.SchemaVersion != RecordSchemaVersion {
+		t.Fatalf("Expected new records written at v%d, got %+v (err %v)", RecordSchemaVersion, stored, err)
+	}
+
+	// Replace it with a record an older version wrote
+	dir := store.NFTDir(owner, mint)
+	path := filepath.Join(dir, "nft_data.json")
+	if err := os.WriteFile(path, legacyRecord(t, mint, owner, time.Now()), 0644); err != nil {
+		t.Fatalf("Failed to write legacy record: %v", err)
+	}
+	if _, err := store.WriteIntegrity(dir); err != nil {
+		t.Fatalf("Failed to write integrity: %v", err)
+	}
+
+	// Reads see the current layout without rewriting
+	stored, err = store.GetNFT(ctx, owner, mint)
+	if err != nil || stored.Status != StatusHeld || len(stored.Custody) != 1 {
+		t.Fatalf("Expected the legacy record read in the current layout, got %+v (err %v)", stored, err)
+	}
+
+	report, err := store.MigrateRecords(ctx, true)
+	if err != nil || report.Migrated != 1 || report.ByVersion[1] != 1 {
+		t.Fatalf("Expected the dry run to find one v1 record, got %+v (err %v)", report, err)
+	}
+	if version, _ := schemaVersion(mustRead(t, path)); version != 1 {
+		t.Fatalf("Expected the dry run to leave the record alone, got v%d", version)
+	}
+
+	report, err = store.MigrateRecords(ctx, false)
+	if err != nil || report.Migrated != 1 || len(report.Failed) != 0 {
+		t.Fatalf("Failed to migrate: %+v (err %v)", report, err)
+	}
+	if version, _ := schemaVersion(mustRead(t, path)); version != RecordSchemaVersion {
+		t.Errorf("Expected the record rewritten at v%d, got v%d", RecordSchemaVersion, version)
+	}
+	check, err := store.CheckIntegrity(dir)
+	if err != nil || !check.OK() {
+		t.Errorf("Expected the integrity manifest refreshed, got %+v (err %v)", check, err)
+	}
+
+	report, err = store.MigrateRecords(ctx, false)
+	if err != nil || report.Migrated != 0 || report.Checked != 1 {
+		t.Errorf("Expected nothing left to migrate, got %+v (err %v)", report, err)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
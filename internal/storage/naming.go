@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxNameBytes caps the length of a generated directory name.
+// Explanation: Most filesystems limit a single path component to 255 bytes;
+// we stay well below that to leave room for collision suffixes.
+const maxNameBytes = 100
+
+// reservedNames are device names Windows refuses to use as file names,
+// regardless of extension (e.g. "CON.json" is also invalid).
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeFilename turns an arbitrary NFT name into a string that is safe to use
+// as a single path component on Linux, macOS and Windows.
+//
+// The name is NFC-normalized (so "é" typed two different ways maps to the same
+// directory), control characters are dropped, path separators and characters
+// Windows rejects are replaced with "_", trailing dots/spaces are trimmed and
+// reserved device names get a "_" suffix. Emoji and other printable unicode
+// are kept as-is.
+func SafeFilename(name string) string {
+	name = norm.NFC.String(name)
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == utf8.RuneError:
+			continue
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsControl(r):
+			continue
+		case strings.ContainsRune(`<>:"/\|?*`, r):
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	safe := strings.Join(strings.Fields(b.String()), " ")
+	safe = truncateUTF8(safe, maxNameBytes)
+	safe = strings.TrimRight(safe, ". ")
+	safe = strings.TrimLeft(safe, " ")
+
+	if safe == "" || safe == "." || safe == ".." {
+		return "unnamed"
+	}
+
+	// Reserved names are checked against the part before the first dot,
+	// and Windows ignores anything after it: "CON.txt" is still CON, so the
+	// stem itself must change
+	stem, rest := safe, ""
+	if idx := strings.Index(stem, "."); idx != -1 {
+		stem, rest = stem[:idx], stem[idx:]
+	}
+	if reservedNames[strings.ToUpper(stem)] {
+		safe = strings.TrimRight(truncateUTF8(stem+"_"+rest, maxNameBytes), ". ")
+	}
+
+	return safe
+}
+
+// UniqueFilename returns SafeFilename(name), adding a " (2)", " (3)", ...
+// suffix until taken reports the candidate as free.
+// Explanation: Comparison is left to the caller so it can be case-insensitive
+// on filesystems that treat "Cat" and "cat" as the same directory.
+func UniqueFilename(name string, taken func(candidate string) bool) string {
+	base := SafeFilename(name)
+	if !taken(base) {
+		return base
+	}
+
+	for i := 2; ; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate := truncateUTF8(base, maxNameBytes-len(suffix)) + suffix
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/fsutil"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestSafeFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Cool Cat #1234", "Cool Cat #1234"},
+		{"Degen/Ape: 42?", "Degen_Ape_ 42_"},
+		{"bell\x07\x00name", "bellname"},
+		{"Café", "Café"}, // NFD input is normalized to NFC
+		{"🦁 Midnight Lion", "🦁 Midnight Lion"},
+		{"  spaced\t\tout  ", "spaced out"},
+		{"trailing dots...", "trailing dots"},
+		{"CON", "CON_"},
+		{"nul.json", "nul_.json"},
+		{"CON.txt", "CON_.txt"},
+		{"com1.tar.gz", "com1_.tar.gz"},
+		{"CONSOLE.txt", "CONSOLE.txt"},
+		{"..", "unnamed"},
+		{"", "unnamed"},
+	}
+
+	for _, test := range tests {
+		result := SafeFilename(test.name)
+		if result != test.expected {
+			t.Errorf("SafeFilename(%q) = %q, want %q", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestSafeFilename_Truncation(t *testing.T) {
+	// 60 two-byte runes would be 120 bytes; the result must stay valid UTF-8
+	long := strings.Repeat("é", 60)
+	result := SafeFilename(long)
+
+	if len(result) > maxNameBytes {
+		t.Errorf("Expected at most %d bytes, got %d", maxNameBytes, len(result))
+	}
+	if !strings.HasPrefix(long, result) {
+		t.Errorf("Truncation split a rune: %q", result)
+	}
+}
+
+func TestUniqueFilename(t *testing.T) {
+	taken := map[string]bool{"cool cat": true, "cool cat (2)": true}
+
+	result := UniqueFilename("Cool Cat", func(candidate string) bool {
+		return taken[strings.ToLower(candidate)]
+	})
+
+	if result != "Cool Cat (3)" {
+		t.Errorf("Expected %q, got %q", "Cool Cat (3)", result)
+	}
+}
+
+// TestFileStorage_NameLayout verifies name-based directories and their index mapping
+func TestFileStorage_NameLayout(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "solvault_test_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	storage, err := NewFileStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	storage.SetLayout(LayoutName)
+
+	walletAddr := solanago.MustPublicKeyFromBase58("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")
+	ctx := context.Background()
+
+	// Two NFTs whose names collapse to the same directory name
+	var mints []solanago.PublicKey
+	for _, name := range []string{"Cat: #1", "Cat/ #1"} {
+		mintAddr := solanago.NewWallet().PublicKey()
+		mints = append(mints, mintAddr)
+
+		testNFT := &fetcher.NFTInfo{
+			MintAddress: mintAddr,
+			Owner:       walletAddr,
+			FetchedAt:   time.Now(),
+			Metadata:    &fetcher.NFTMetadata{Name: name},
+		}
+		if err := storage.SaveNFT(ctx, testNFT); err != nil {
+			t.Fatalf("Failed to save NFT %q: %v", name, err)
+		}
+	}
+
+	nftsDir := filepath.Join(tempDir, "wallets", walletAddr.String(), "nfts")
+	for _, dirName := range []string{"Cat_ #1", "Cat_ #1 (2)"} {
+		if _, err := os.Stat(filepath.Join(nftsDir, dirName)); err != nil {
+			t.Errorf("Expected directory %q: %v", dirName, err)
+		}
+	}
+
+	// The mapping must survive a reload and resolve back to the original name
+	reloaded, err := NewFileStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+
+	entry := reloaded.Index().ByDirName(walletAddr.String(), "Cat_ #1 (2)")
+	if entry == nil || entry.Name != "Cat/ #1" {
+		t.Fatalf("Expected reverse mapping to %q, got %+v", "Cat/ #1", entry)
+	}
+
+	stored, err := reloaded.GetNFT(ctx, walletAddr, mints[1])
+	if err != nil {
+		t.Fatalf("Failed to get NFT through index: %v", err)
+	}
+	if stored.NFTInfo.Metadata.Name != "Cat/ #1" {
+		t.Errorf("Name mismatch: got %q", stored.NFTInfo.Metadata.Name)
+	}
+}
+
+// TestFileStorage_ShortPathFallback verifies over-long NFT directories fall back to a short name
+func TestFileStorage_ShortPathFallback(t *testing.T) {
+	tempDir := t.TempDir()
+
+	storage, err := NewFileStorage(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	storage.SetLayout(LayoutName)
+
+	walletAddr := solanago.MustPublicKeyFromBase58("h6VG3SKVfCjFavPC8r5ztnSCJFFPhm6yDmzbZF8fEQP")
+	mintAddr := solanago.MustPublicKeyFromBase58("ANg3FsUmzYDzvPffk9sv6EX15Jke13gPCtEBRQm2wL3")
+
+	// Leave room for the short directory but not for a 90-byte name
+	original := fsutil.MaxPathLength
+	fsutil.MaxPathLength = len(tempDir) + len("/wallets/") + 44 + len("/nfts/") + 30 + len("/media/") + fsutil.MaxMediaFilename
+	defer func() { fsutil.MaxPathLength = original }()
+
+	testNFT := &fetcher.NFTInfo{
+		MintAddress: mintAddr,
+		Owner:       walletAddr,
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: strings.Repeat("Long Name ", 9)},
+	}
+	if err := storage.SaveNFT(context.Background(), testNFT); err != nil {
+		t.Fatalf("Expected fallback instead of error, got %v", err)
+	}
+
+	entry := storage.Index().Get(walletAddr.String(), mintAddr.String())
+	if entry == nil || entry.DirName != mintAddr.String()[:8] {
+		t.Fatalf("Expected shortened directory %q, got %+v", mintAddr.String()[:8], entry)
+	}
+
+	// A base directory that is already too long fails up front with guidance
+	fsutil.MaxPathLength = len(tempDir) + 10
+	other := &fetcher.NFTInfo{MintAddress: solanago.NewWallet().PublicKey(), Owner: walletAddr, FetchedAt: time.Now()}
+	if err := storage.SaveNFT(context.Background(), other); !fsutil.IsPathTooLong(err) {
+		t.Errorf("Expected path too long error, got %v", err)
+	}
+}
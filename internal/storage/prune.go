@@ -0,0 +1,391 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// PruneKind is a category of file prune removes
+type PruneKind string
+
+const (
+	PruneOrphanedMedia PruneKind = "orphaned-media" // Media no manifest lists
+	PrunePartial       PruneKind = "partial"        // Empty downloads, leftover temp files and never-committed saves
+	PruneBurned        PruneKind = "burned"         // Whole backup of a burned NFT
+	PruneOldVersion    PruneKind = "old-version"    // Metadata snapshot beyond the retention policy
+	PruneTransferred   PruneKind = "transferred"    // Media of an NFT transferred longer ago than the retention policy
+)
+
+// PruneOptions selects what Prune removes. Orphaned media and partial
+// downloads are always removed; burned backups, old versions and the media
+// of transferred NFTs only when asked for.
+type PruneOptions struct {
+	KeepVersions int  // Metadata versions kept per NFT, newest first; 0 keeps all
+	Burned       bool // Delete the backups of burned NFTs
+	DryRun       bool // Report without deleting anything
+
+	// KeepTransferred is how long the media of a transferred NFT is kept
+	// after it left; 0 keeps it for good. Its metadata is always kept.
+	KeepTransferred time.Duration
+}
+
+// PruneItem is one file or backup Prune removed, or would remove
+type PruneItem struct {
+	Kind   PruneKind `json:"kind"`
+	Path   string    `json:"path"` // Relative to the vault
+	Wallet string    `json:"wallet,omitempty"`
+	Mint   string    `json:"mint,omitempty"`
+	Name   string    `json:"name,omitempty"`
+	Bytes  int64     `json:"bytes"`
+}
+
+// PruneReport lists what Prune removed and how much space it reclaimed
+type PruneReport struct {
+	DryRun    bool        `json:"dry_run,omitempty"`
+	Items     []PruneItem `json:"items"`
+	Reclaimed int64       `json:"reclaimed_bytes"`
+}
+
+func (r *PruneReport) add(item PruneItem) {
+	r.Items = append(r.Items, item)
+	r.Reclaimed += item.Bytes
+}
+
+// Prune removes what the vault no longer needs: media files no manifest
+// lists, partial downloads and interrupted saves, and optionally burned
+// backups, metadata versions beyond opts.KeepVersions and the media of NFTs
+// transferred longer ago than opts.KeepTransferred
+//
+// Explanation: Burned backups are kept unless asked for, since the backup is
+// often all that is left of a burned NFT. Snapshots are trimmed from the
+// oldest, never touching the current version, and the version history in
+// nft_data.json is trimmed with them so diff only offers what is on disk.
+// A transferred NFT keeps its record, so the vault still shows it was held;
+// should it come back, sync downloads its media again.
+func (fs *FileStorage) Prune(ctx context.Context, opts PruneOptions) (*PruneReport, error) {
+	report := &PruneReport{DryRun: opts.DryRun, Items: []PruneItem{}}
+
+	dirs, err := filepath.Glob(filepath.Join(fs.baseDir, "wallets", "*", "nfts", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NFT directories: %w", err)
+	}
+	burned := make(map[string]bool) // NFT directories removed whole
+	if opts.Burned {
+		if err := fs.pruneBurned(ctx, report, opts.DryRun, burned); err != nil {
+			return report, err
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() || burned[dir] {
+			continue
+		}
+		if err := fs.pruneDir(dir, opts, report); err != nil {
+			return report, err
+		}
+	}
+
+	// New backups whose first save never finished
+	staged, err := filepath.Glob(filepath.Join(fs.baseDir, StagingDirName, "*", "*"))
+	if err != nil {
+		return report, fmt.Errorf("failed to list staged backups: %w", err)
+	}
+	for _, dir := range staged {
+		report.add(PruneItem{Kind: PrunePartial, Path: fs.relPath(dir), Wallet: filepath.Base(filepath.Dir(dir)), Bytes: dirSize(dir)})
+		if !opts.DryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return report, fmt.Errorf("failed to remove %s: %w", dir, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// pruneBurned deletes every backup of a burned NFT, links first so the
+// record they point at can go too
+func (fs *FileStorage) pruneBurned(ctx context.Context, report *PruneReport, dryRun bool, removed map[string]bool) error {
+	entries := fs.index.List()
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].PrimaryWallet != "" && entries[j].PrimaryWallet == "" })
+
+	for _, entry := range entries {
+		if entry.Status != StatusBurned {
+			continue
+		}
+		item := PruneItem{Kind: PruneBurned, Wallet: entry.Wallet, Mint: entry.Mint, Name: entry.Name}
+		if entry.PrimaryWallet == "" {
+			dir := filepath.Join(fs.baseDir, "wallets", entry.Wallet, "nfts", entry.DirName)
+			item.Path = fs.relPath(dir)
+			item.Bytes = dirSize(dir)
+			removed[dir] = true
+		} else {
+			item.Path = fs.relPath(filepath.Join(fs.baseDir, "wallets", entry.PrimaryWallet, "nfts", entry.DirName)) + " (link)"
+		}
+
+		if !dryRun {
+			wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+			if err != nil {
+				return fmt.Errorf("invalid wallet in index: %w", err)
+			}
+			mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+			if err != nil {
+				return fmt.Errorf("invalid mint in index: %w", err)
+			}
+			if err := fs.DeleteNFT(ctx, wallet, mint); err != nil {
+				// Another wallet still links to it without being burned
+				delete(removed, filepath.Join(fs.baseDir, "wallets", entry.Wallet, "nfts", entry.DirName))
+				continue
+			}
+		}
+		report.add(item)
+	}
+	return nil
+}
+
+// pruneDir removes what one NFT directory no longer needs
+func (fs *FileStorage) pruneDir(dir string, opts PruneOptions, report *PruneReport) error {
+	wallet := filepath.Base(filepath.Dir(filepath.Dir(dir)))
+	item := func(kind PruneKind, path string, size int64) PruneItem {
+		item := PruneItem{Kind: kind, Path: fs.relPath(path), Wallet: wallet, Bytes: size}
+		if entry := fs.index.ByDirName(wallet, filepath.Base(dir)); entry != nil {
+			item.Mint, item.Name = entry.Mint, entry.Name
+		}
+		return item
+	}
+	var touched []string // Backup files changed, for integrity.json
+	remove := func(found PruneItem, path string) error {
+		if !opts.DryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." {
+			touched = append(touched, filepath.ToSlash(rel))
+		}
+		report.add(found)
+		return nil
+	}
+
+	// A save in the middle of committing is finished by the next save, not pruned
+	if _, err := os.Stat(filepath.Join(dir, journalName)); err == nil {
+		return nil
+	}
+
+	var stored StoredNFT
+	if err := fs.loadJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+		if os.IsNotExist(err) {
+			// The first save never committed; nothing refers to what is here
+			return remove(item(PrunePartial, dir, dirSize(dir)), dir)
+		}
+		return fmt.Errorf("failed to load %s: %w", fs.relPath(dir), err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, stagedSuffix) || (strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp")) {
+			path := filepath.Join(dir, name)
+			if err := remove(item(PrunePartial, path, fileSize(path)), path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := fs.pruneMedia(dir, &stored, item, remove); err != nil {
+		return err
+	}
+	if opts.KeepTransferred > 0 {
+		pruned, err := fs.pruneTransferred(dir, &stored, opts, item, remove)
+		if err != nil {
+			return err
+		}
+		touched = append(touched, pruned...)
+	}
+	if opts.KeepVersions > 0 {
+		rewritten, err := fs.pruneVersions(dir, &stored, opts, item, remove)
+		if err != nil {
+			return err
+		}
+		if rewritten {
+			touched = append(touched, "nft_data.json")
+		}
+	}
+	if opts.DryRun || len(touched) == 0 {
+		return nil
+	}
+	return fs.refreshIntegrity(dir, touched...)
+}
+
+// pruneMedia removes media files that neither the manifest nor the record
+// lists, typically left by a download that was interrupted
+func (fs *FileStorage) pruneMedia(dir string, stored *StoredNFT, item func(PruneKind, string, int64) PruneItem, remove func(PruneItem, string) error) error {
+	mediaDir := filepath.Join(dir, "media")
+	entries, err := os.ReadDir(mediaDir)
+	if err != nil {
+		return nil // No media
+	}
+
+	listed := make(map[string]bool)
+	var manifest []*fetcher.MediaFile
+	if err := fs.loadJSON(filepath.Join(dir, "media_manifest.json"), &manifest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load media manifest in %s: %w", fs.relPath(dir), err)
+	}
+	if stored.NFTInfo != nil {
+		manifest = append(manifest, stored.NFTInfo.MediaFiles...)
+	}
+	for _, media := range manifest {
+		if media != nil {
+			listed[media.Filename] = true
+		}
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(mediaDir, entry.Name())
+		switch {
+		case entry.IsDir():
+			continue
+		case strings.HasPrefix(entry.Name(), "."):
+			if err := remove(item(PrunePartial, path, fileSize(path)), path); err != nil {
+				return err
+			}
+		case !listed[entry.Name()]:
+			if err := remove(item(PruneOrphanedMedia, path, fileSize(path)), path); err != nil {
+				return err
+			}
+		case fileSize(path) == 0:
+			if err := remove(item(PrunePartial, path, 0), path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneTransferred removes the media of an NFT that left the vault's
+// wallets more than KeepTransferred ago, and drops it from the record. It
+// returns the backup files it removed or rewrote.
+func (fs *FileStorage) pruneTransferred(dir string, stored *StoredNFT, opts PruneOptions, item func(PruneKind, string, int64) PruneItem, remove func(PruneItem, string) error) ([]string, error) {
+	if stored.Status != StatusTransferred || stored.StatusChangedAt.IsZero() || time.Since(stored.StatusChangedAt) < opts.KeepTransferred {
+		return nil, nil
+	}
+	mediaDir := filepath.Join(dir, "media")
+	if _, err := os.Stat(mediaDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	// integrity.json lists every file, so each one is dropped from it
+	var removed []string
+	filepath.WalkDir(mediaDir, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				removed = append(removed, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	if err := remove(item(PruneTransferred, mediaDir, dirSize(mediaDir)), mediaDir); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return nil, nil
+	}
+	manifest := filepath.Join(dir, "media_manifest.json")
+	if err := os.Remove(manifest); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove %s: %w", manifest, err)
+	}
+
+	now := time.Now().UTC()
+	if stored.NFTInfo != nil {
+		stored.NFTInfo.MediaFiles = nil
+		checksum, err := fs.calculateChecksum(stored.NFTInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate checksum: %w", err)
+		}
+		stored.Checksum = checksum
+	}
+	stored.MediaPrunedAt = &now
+	if err := fs.saveJSON(filepath.Join(dir, "nft_data.json"), stored); err != nil {
+		return nil, fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	return append(removed, "media_manifest.json", "nft_data.json"), nil
+}
+
+// pruneVersions removes metadata snapshots beyond the newest KeepVersions.
+// It reports whether nft_data.json was rewritten to match.
+func (fs *FileStorage) pruneVersions(dir string, stored *StoredNFT, opts PruneOptions, item func(PruneKind, string, int64) PruneItem, remove func(PruneItem, string) error) (bool, error) {
+	snapshots, err := filepath.Glob(filepath.Join(dir, "metadata.v*.json"))
+	if err != nil {
+		return false, err
+	}
+	oldest := stored.Version - opts.KeepVersions + 1 // Lowest version kept
+
+	pruned := false
+	for _, path := range snapshots {
+		version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "metadata.v"), ".json"))
+		if err != nil || version >= oldest || version == stored.Version {
+			continue
+		}
+		if err := remove(item(PruneOldVersion, path, fileSize(path)), path); err != nil {
+			return false, err
+		}
+		pruned = true
+	}
+
+	if !pruned || opts.DryRun {
+		return false, nil
+	}
+	kept := stored.Versions[:0]
+	for _, version := range stored.Versions {
+		if version.Version >= oldest || version.Version == stored.Version {
+			kept = append(kept, version)
+		}
+	}
+	stored.Versions = kept
+	if err := fs.saveJSON(filepath.Join(dir, "nft_data.json"), stored); err != nil {
+		return false, fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	return true, nil
+}
+
+func (fs *FileStorage) relPath(path string) string {
+	if rel, err := filepath.Rel(fs.baseDir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// dirSize adds up the size of every file below dir
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
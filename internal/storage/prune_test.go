@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Prune(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+
+	// Three metadata versions, a stray media file and a leftover temp file
+	kept := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	for _, name := range []string{"One", "Two", "Three"} {
+		kept.Metadata = &fetcher.NFTMetadata{Name: name}
+		if err := store.SaveNFT(ctx, kept); err != nil {
+			t.Fatalf("Failed to save NFT: %v", err)
+		}
+	}
+	keptDir := store.NFTDir(wallet, kept.MintAddress)
+	if err := os.MkdirAll(filepath.Join(keptDir, "media"), 0755); err != nil {
+		t.Fatalf("Failed to create media directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(keptDir, "media", "stray.png"), make([]byte, 100), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(keptDir, ".metadata.json.tmp"), make([]byte, 10), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	burned := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	if err := store.SaveNFT(ctx, burned); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if err := store.SetStatus(ctx, wallet, burned.MintAddress, StatusBurned); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+
+	// A first save that died before committing
+	abandoned := filepath.Join(store.BaseDir(), "wallets", wallet.String(), "nfts", "abandoned")
+	if err := os.MkdirAll(abandoned, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(abandoned, ".nft_data.json.staged"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("Failed to write staged file: %v", err)
+	}
+
+	opts := PruneOptions{KeepVersions: 2, Burned: true, DryRun: true}
+	plan, err := store.Prune(ctx, opts)
+	if err != nil {
+		t.Fatalf("Failed to plan prune: %v", err)
+	}
+	wantKinds := map[PruneKind]int{PruneOrphanedMedia: 1, PrunePartial: 2, PruneOldVersion: 1, PruneBurned: 1}
+	gotKinds := make(map[PruneKind]int)
+	for _, item := range plan.Items {
+		gotKinds[item.Kind]++
+	}
+	for kind, want := range wantKinds {
+		if gotKinds[kind] != want {
+			t.Errorf("Dry run found %d %s item(s), want %d (items: %+v)", gotKinds[kind], kind, want, plan.Items)
+		}
+	}
+	if plan.Reclaimed < 110 {
+		t.Errorf("Dry run reclaims %d bytes, want at least 110", plan.Reclaimed)
+	}
+	if _, err := os.Stat(filepath.Join(keptDir, "media", "stray.png")); err != nil {
+		t.Errorf("Dry run removed a file: %v", err)
+	}
+
+	opts.DryRun = false
+	report, err := store.Prune(ctx, opts)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if report.Reclaimed != plan.Reclaimed || len(report.Items) != len(plan.Items) {
+		t.Errorf("Prune reclaimed %d bytes from %d item(s), dry run planned %d from %d", report.Reclaimed, len(report.Items), plan.Reclaimed, len(plan.Items))
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(keptDir, "media", "stray.png"), false},
+		{filepath.Join(keptDir, ".metadata.json.tmp"), false},
+		{filepath.Join(keptDir, "metadata.v1.json"), false},
+		{filepath.Join(keptDir, "metadata.v2.json"), true},
+		{filepath.Join(keptDir, "metadata.v3.json"), true},
+		{store.NFTDir(wallet, burned.MintAddress), false},
+		{abandoned, false},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(tt.path)
+		if exists := err == nil; exists != tt.want {
+			t.Errorf("%s exists = %v, want %v", tt.path, exists, tt.want)
+		}
+	}
+
+	stored, err := store.GetNFT(ctx, wallet, kept.MintAddress)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	if stored.Version != 3 || len(stored.Versions) != 2 || stored.Versions[0].Version != 2 {
+		t.Errorf("Got v%d with versions %+v, want v3 with versions 2 and 3", stored.Version, stored.Versions)
+	}
+	if _, err := store.GetNFT(ctx, wallet, burned.MintAddress); err == nil {
+		t.Errorf("Burned NFT is still in the vault")
+	}
+
+	again, err := store.Prune(ctx, opts)
+	if err != nil {
+		t.Fatalf("Failed to prune again: %v", err)
+	}
+	if len(again.Items) != 0 {
+		t.Errorf("Second prune found %+v, want nothing", again.Items)
+	}
+}
+
+func TestFileStorage_PruneTransferred(t *testing.T) {
+	store, nft, dir := fsckVault(t)
+	ctx := context.Background()
+	if err := store.SetStatus(ctx, nft.Owner, nft.MintAddress, StatusTransferred); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	opts := PruneOptions{KeepTransferred: 30 * 24 * time.Hour}
+
+	// Transferred just now: still within the retention period
+	report, err := store.Prune(ctx, opts)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if len(report.Items) != 0 {
+		t.Fatalf("Expected recent transfers to keep their media, got %+v", report.Items)
+	}
+
+	var stored StoredNFT
+	if err := store.loadJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+		t.Fatalf("Failed to load NFT data: %v", err)
+	}
+	stored.StatusChangedAt = time.Now().Add(-31 * 24 * time.Hour)
+	if err := store.saveJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+		t.Fatalf("Failed to save NFT data: %v", err)
+	}
+	if err := store.refreshIntegrity(dir, "nft_data.json"); err != nil {
+		t.Fatalf("Failed to refresh integrity: %v", err)
+	}
+
+	report, err = store.Prune(ctx, opts)
+	if err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	if len(report.Items) != 1 || report.Items[0].Kind != PruneTransferred || report.Reclaimed == 0 {
+		t.Fatalf("Expected the media of the old transfer pruned, got %+v", report.Items)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "media")); !os.IsNotExist(err) {
+		t.Errorf("Expected the media directory removed, got %v", err)
+	}
+
+	got, err := store.GetNFT(ctx, nft.Owner, nft.MintAddress)
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	if got.MediaPrunedAt == nil || len(got.NFTInfo.MediaFiles) != 0 || got.NFTInfo.Metadata.Name != "Shared NFT" {
+		t.Errorf("Expected the record kept without media, got %+v", got)
+	}
+	fsck, err := store.Fsck(ctx, false)
+	if err != nil {
+		t.Fatalf("Failed to check vault: %v", err)
+	}
+	if len(fsck.Issues) != 0 {
+		t.Errorf("Expected a consistent backup after pruning, got %+v", fsck.Issues[0])
+	}
+}
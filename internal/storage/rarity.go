@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Rarity is an NFT's statistical rarity among the vault's backups of its
+// collection, computed from how often each of its trait values occurs
+type Rarity struct {
+	Collection string        `json:"collection"`
+	Rank       int           `json:"rank"` // 1 is the rarest; ties share a rank
+	Of         int           `json:"of"`   // Backups of the collection ranked
+	Score      float64       `json:"score"`
+	Traits     []TraitRarity `json:"traits"`
+	ComputedAt time.Time     `json:"computed_at"`
+}
+
+// TraitRarity is how common one of the NFT's trait values is
+type TraitRarity struct {
+	Trait   string  `json:"trait"`
+	Value   string  `json:"value"`
+	Count   int     `json:"count"` // Backups sharing the value
+	Percent float64 `json:"percent"`
+	Score   float64 `json:"score"` // This trait's part of the rarity score
+}
+
+// Same reports whether r ranks the NFT as other does, whenever each was
+// computed
+func (r *Rarity) Same(other *Rarity) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Collection != other.Collection || r.Rank != other.Rank || r.Of != other.Of || r.Score != other.Score || len(r.Traits) != len(other.Traits) {
+		return false
+	}
+	for i := range r.Traits {
+		if r.Traits[i] != other.Traits[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SetRarity records an NFT's rarity on its backup, or removes it when
+// rarity is nil
+func (fs *FileStorage) SetRarity(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, rarity *Rarity) error {
+	nftDataPath := filepath.Join(fs.buildNFTPath(walletAddr, mintAddr), "nft_data.json")
+
+	var storedNFT StoredNFT
+	if err := fs.loadJSON(nftDataPath, &storedNFT); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("NFT not found: %s", mintAddr.String())
+		}
+		return fmt.Errorf("failed to load NFT data: %w", err)
+	}
+
+	storedNFT.Rarity = rarity
+	if err := fs.saveJSON(nftDataPath, &storedNFT); err != nil {
+		return fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	return fs.refreshIntegrity(filepath.Dir(nftDataPath), "nft_data.json")
+}
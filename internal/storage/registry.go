@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory opens a backend at location, whose meaning is up to the backend:
+// a directory for "file", a bucket URL for an object store
+type Factory func(location string) (StorageBackend, error)
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a backend available under name (e.g. "s3"), for
+// --backend and OpenVault. Like database/sql drivers, backends register
+// themselves from an init function; registering a name twice panics.
+func Register(name string, factory Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if factory == nil {
+		panic("storage: Register factory is nil for " + name)
+	}
+	if _, dup := registry.factories[name]; dup {
+		panic("storage: Register called twice for " + name)
+	}
+	registry.factories[name] = factory
+}
+
+// Backends lists the registered backend names
+func Backends() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("file", func(location string) (StorageBackend, error) {
+		if location == "" {
+			return nil, fmt.Errorf("the file backend needs a directory, e.g. file:/mnt/usb/SolVault")
+		}
+		return NewFileStorage(location)
+	})
+	Register("memory", func(location string) (StorageBackend, error) {
+		return NewMemoryStorage()
+	})
+}
+
+// BackendSpec names one backend of a chain and where it keeps its data
+type BackendSpec struct {
+	Name     string
+	Location string
+}
+
+func (s BackendSpec) String() string {
+	if s.Location == "" {
+		return s.Name
+	}
+	return s.Name + ":" + s.Location
+}
+
+// ParseBackends parses a comma-separated chain of name[:location] specs,
+// e.g. "file,file:/mnt/usb/SolVault". The first is the primary, the rest
+// replicas. Every name must be registered.
+func ParseBackends(spec string) ([]BackendSpec, error) {
+	var specs []BackendSpec
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, location, _ := strings.Cut(part, ":")
+		registry.RLock()
+		_, ok := registry.factories[name]
+		registry.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown storage backend %q (available: %s)", name, strings.Join(Backends(), ", "))
+		}
+		specs = append(specs, BackendSpec{Name: name, Location: location})
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no storage backend given")
+	}
+	return specs, nil
+}
+
+// OpenBackend opens one registered backend
+func OpenBackend(spec BackendSpec) (StorageBackend, error) {
+	registry.RLock()
+	factory, ok := registry.factories[spec.Name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q (available: %s)", spec.Name, strings.Join(Backends(), ", "))
+	}
+	backend, err := factory(spec.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s backend: %w", spec, err)
+	}
+	return backend, nil
+}
+
+// OpenVault opens a chain of backends: the first holds the vault, and every
+// save and delete is copied to the rest. dir is the primary's location when
+// the spec gives none.
+//
+// Explanation: Commands rely on the vault's index, layout, encryption and
+// media directory, which only a file vault provides, so the primary must be
+// one. Other backends can still receive every backup as replicas; a new
+// backend only needs the StorageBackend methods to be useful there.
+func OpenVault(specs []BackendSpec, dir string) (*FileStorage, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no storage backend given")
+	}
+	primary := specs[0]
+	if primary.Location == "" {
+		primary.Location = dir
+	}
+	backend, err := OpenBackend(primary)
+	if err != nil {
+		return nil, err
+	}
+	vault, ok := backend.(*FileStorage)
+	if !ok {
+		backend.Close()
+		return nil, fmt.Errorf("the %s backend cannot hold the vault; list it after the primary as a replica (e.g. file,%s)", primary.Name, specs[0])
+	}
+
+	for _, spec := range specs[1:] {
+		replica, err := OpenBackend(spec)
+		if err != nil {
+			vault.Close()
+			return nil, err
+		}
+		if other, ok := replica.(*FileStorage); ok && sameDir(other.BaseDir(), vault.BaseDir()) {
+			replica.Close()
+			vault.Close()
+			return nil, fmt.Errorf("replica %s is the vault itself", spec)
+		}
+		vault.replicas = append(vault.replicas, replicaBackend{name: spec.String(), backend: replica})
+	}
+	return vault, nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestParseBackends(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []BackendSpec
+		wantErr string
+	}{
+		{"file", []BackendSpec{{Name: "file"}}, ""},
+		{"file, file:/mnt/usb/SolVault", []BackendSpec{{Name: "file"}, {Name: "file", Location: "/mnt/usb/SolVault"}}, ""},
+		{`file:C:\Backups`, []BackendSpec{{Name: "file", Location: `C:\Backups`}}, ""},
+		{"file,s3:bucket", nil, `unknown storage backend "s3"`},
+		{" , ", nil, "no storage backend"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBackends(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseBackends(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseBackends(%q) failed: %v", tt.spec, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("ParseBackends(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseBackends(%q)[%d] = %v, want %v", tt.spec, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+// countingBackend is a registered test backend that is not a file vault
+type countingBackend struct {
+	StorageBackend
+	saves int
+}
+
+func (b *countingBackend) SaveNFT(ctx context.Context, nftInfo *fetcher.NFTInfo) error {
+	b.saves++
+	return b.StorageBackend.SaveNFT(ctx, nftInfo)
+}
+
+func TestRegister(t *testing.T) {
+	var counting *countingBackend
+	Register("counting-test", func(location string) (StorageBackend, error) {
+		inner, err := NewMemoryStorage()
+		if err != nil {
+			return nil, err
+		}
+		counting = &countingBackend{StorageBackend: inner}
+		return counting, nil
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Registering a name twice should panic")
+			}
+		}()
+		Register("counting-test", func(string) (StorageBackend, error) { return nil, nil })
+	}()
+
+	// Only a file vault can hold the vault itself
+	if _, err := OpenVault([]BackendSpec{{Name: "counting-test"}}, t.TempDir()); err == nil {
+		t.Errorf("Expected a non-file primary to be rejected")
+	}
+
+	vault, err := OpenVault([]BackendSpec{{Name: "file"}, {Name: "counting-test"}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open vault: %v", err)
+	}
+	defer vault.Close()
+	if err := vault.SaveNFT(context.Background(), custodyNFT(solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey())); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if counting.saves != 1 {
+		t.Errorf("Replica received %d save(s), want 1", counting.saves)
+	}
+	if names := vault.Replicas(); len(names) != 1 || names[0] != "counting-test" {
+		t.Errorf("Got replicas %v", names)
+	}
+}
+
+func TestOpenVault_Replicas(t *testing.T) {
+	primaryDir, replicaDir := t.TempDir(), t.TempDir()
+	vault, err := OpenVault([]BackendSpec{{Name: "file"}, {Name: "file", Location: replicaDir}}, primaryDir)
+	if err != nil {
+		t.Fatalf("Failed to open vault: %v", err)
+	}
+	defer vault.Close()
+
+	// Media is copied along with the record, even from a compressed vault
+	if _, err := vault.EnableCompression(); err != nil {
+		t.Fatalf("Failed to enable compression: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	nft.MediaFiles = []*fetcher.MediaFile{{Filename: "image.png"}}
+	if err := vault.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	image := []byte("\x89PNG pixels")
+	if err := os.WriteFile(filepath.Join(vault.NFTDir(wallet, nft.MintAddress), "media", "image.png"), image, 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+	if err := vault.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to re-save NFT: %v", err)
+	}
+
+	replica, err := NewFileStorage(replicaDir)
+	if err != nil {
+		t.Fatalf("Failed to open replica: %v", err)
+	}
+	stored, err := replica.GetNFT(ctx, wallet, nft.MintAddress)
+	if err != nil || stored.NFTInfo.Metadata.Name != "Shared NFT" {
+		t.Fatalf("Replica is missing the NFT: %v", err)
+	}
+	media, err := os.ReadFile(filepath.Join(replica.NFTDir(wallet, nft.MintAddress), "media", "image.png"))
+	if err != nil || !bytes.Equal(media, image) {
+		t.Errorf("Replica media = %q (%v), want the original", media, err)
+	}
+
+	if err := vault.DeleteNFT(ctx, wallet, nft.MintAddress); err != nil {
+		t.Fatalf("Failed to delete NFT: %v", err)
+	}
+	replica, _ = NewFileStorage(replicaDir)
+	if _, err := replica.GetNFT(ctx, wallet, nft.MintAddress); err == nil {
+		t.Errorf("Delete was not replicated")
+	}
+
+	if _, err := OpenVault([]BackendSpec{{Name: "file"}, {Name: "file", Location: primaryDir}}, primaryDir); err == nil {
+		t.Errorf("Expected the vault itself to be refused as a replica")
+	}
+}
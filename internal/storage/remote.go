@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// RemoteCopy records what one cloud mirror holds of an NFT's backup
+type RemoteCopy struct {
+	Files      map[string]RemoteFile `json:"files"`       // Keyed by slash-separated path within the NFT directory
+	MirroredAt time.Time             `json:"mirrored_at"` // Last successful upload
+}
+
+// RemoteFile is one uploaded backup file
+type RemoteFile struct {
+	ID       string `json:"id"`       // Provider's file ID
+	Checksum string `json:"checksum"` // SHA-256 of what was uploaded, to skip unchanged files
+	Size     int64  `json:"size"`
+}
+
+// SetRemote records the copy a mirror holds of an NFT; nil forgets it
+//
+// Explanation: UpdatedAt is left alone. It describes the backup itself, and
+// mirrors compare it (through the record's checksum) to decide whether
+// nft_data.json needs uploading again.
+func (fs *FileStorage) SetRemote(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, provider string, remote *RemoteCopy) error {
+	nftDataPath := filepath.Join(fs.buildNFTPath(walletAddr, mintAddr), "nft_data.json")
+
+	var storedNFT StoredNFT
+	if err := fs.loadJSON(nftDataPath, &storedNFT); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("NFT not found: %s", mintAddr.String())
+		}
+		return fmt.Errorf("failed to load NFT data: %w", err)
+	}
+
+	if remote == nil {
+		delete(storedNFT.Remote, provider)
+	} else {
+		if storedNFT.Remote == nil {
+			storedNFT.Remote = make(map[string]*RemoteCopy)
+		}
+		storedNFT.Remote[provider] = remote
+	}
+	if len(storedNFT.Remote) == 0 {
+		storedNFT.Remote = nil
+	}
+
+	if err := fs.saveJSON(nftDataPath, &storedNFT); err != nil {
+		return fmt.Errorf("failed to save NFT data: %w", err)
+	}
+	return fs.refreshIntegrity(filepath.Dir(nftDataPath), "nft_data.json")
+}
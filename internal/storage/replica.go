@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// replicaBackend is a backend that receives a copy of every save
+type replicaBackend struct {
+	name    string
+	backend StorageBackend
+}
+
+// MediaReceiver is implemented by backends that keep media files with the
+// NFT record, so replicas get the media as well as the record
+type MediaReceiver interface {
+	PutMedia(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, name string, data []byte) error
+}
+
+// Replicas lists the backends that receive a copy of every save, as given
+// to OpenVault
+func (fs *FileStorage) Replicas() []string {
+	names := make([]string, len(fs.replicas))
+	for i, replica := range fs.replicas {
+		names[i] = replica.name
+	}
+	return names
+}
+
+// PutMedia stores one media file or thumbnail of a saved NFT, encrypted
+// and compressed as the vault's settings require
+func (fs *FileStorage) PutMedia(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, name string, data []byte) error {
+	// A plain file name, or one inside the thumbnails directory
+	dir, base := path.Split(name)
+	if base == "" || strings.HasPrefix(base, ".") || strings.ContainsRune(base, filepath.Separator) || (dir != "" && dir != thumbnail.Dir+"/") {
+		return fmt.Errorf("invalid media file name %q", name)
+	}
+	mediaDir := filepath.Join(fs.buildNFTPath(walletAddr, mintAddr), "media", dir)
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+	target := filepath.Join(mediaDir, base)
+	packed, err := fs.encode(target, data)
+	if err != nil {
+		return err
+	}
+	return fs.replaceFile(target, packed)
+}
+
+// replicate copies a committed save, and the NFT's media, to every replica
+//
+// Explanation: The local save has already succeeded when this runs, so a
+// failing replica is reported without undoing it. Saving again (or the
+// next sync that changes the NFT) brings the replica up to date.
+func (fs *FileStorage) replicate(ctx context.Context, nftInfo *fetcher.NFTInfo, nftDir string) error {
+	if len(fs.replicas) == 0 {
+		return nil
+	}
+
+	media := mediaNames(nftDir)
+
+	for _, replica := range fs.replicas {
+		if err := replica.backend.SaveNFT(ctx, nftInfo); err != nil {
+			return fmt.Errorf("saved to the vault but not to replica %s: %w", replica.name, err)
+		}
+		receiver, ok := replica.backend.(MediaReceiver)
+		if !ok {
+			continue
+		}
+		for _, name := range media {
+			data, err := fs.ReadFile(filepath.Join(nftDir, "media", filepath.FromSlash(name)))
+			if err != nil {
+				return fmt.Errorf("failed to read media %s for replica %s: %w", name, replica.name, err)
+			}
+			if err := receiver.PutMedia(ctx, nftInfo.Owner, nftInfo.MintAddress, name, data); err != nil {
+				return fmt.Errorf("saved to the vault but not to replica %s: %w", replica.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteFromReplicas removes an NFT from every replica that has it
+func (fs *FileStorage) deleteFromReplicas(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) error {
+	for _, replica := range fs.replicas {
+		if _, err := replica.backend.GetNFT(ctx, walletAddr, mintAddr); err != nil {
+			continue // Never replicated, e.g. added as a replica later
+		}
+		if err := replica.backend.DeleteNFT(ctx, walletAddr, mintAddr); err != nil {
+			return fmt.Errorf("deleted from the vault but not from replica %s: %w", replica.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// matchTier ranks how closely an index entry matches a lookup
+type matchTier int
+
+const (
+	noMatch    matchTier = iota
+	fuzzyMatch           // Every word matches, some only within a typo or two
+	wordMatch            // Every word starts a word of the name, symbol, collection or mint
+	exactMatch           // The mint, or the whole name or directory name
+)
+
+// Search finds the backups a user means by query, among one wallet's entries
+// when wallet is set. It accepts a mint address (or its start), an NFT's name,
+// symbol or collection, and tolerates case, punctuation and small typos, so
+// "cool cat 12", "Cool Cat #12" and "cool kats 12" all find "Cool Cat #12".
+//
+// Explanation: Only the best tier of matches is returned. An exact name
+// hides NFTs that merely share its words, and a typo-tolerant match is only
+// offered when nothing matches properly. Numbers must match whole, so "#1"
+// does not also find "#12" and "#100". Wallets linked to the same backup
+// resolve to a single entry.
+func (idx *Index) Search(query, wallet string) []*IndexEntry {
+	words := searchWords(query)
+
+	best := noMatch
+	var matches []*IndexEntry
+	seen := make(map[string]bool)
+	for _, entry := range idx.List() {
+		if wallet != "" && entry.Wallet != wallet {
+			continue
+		}
+		tier := matchEntry(entry, query, words)
+		if tier == noMatch || tier < best {
+			continue
+		}
+		if tier > best {
+			best, matches, seen = tier, nil, make(map[string]bool)
+		}
+		stored := indexKey(entry.StorageWallet(), entry.DirName)
+		if !seen[stored] {
+			seen[stored] = true
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// matchEntry ranks one entry against the query and its words
+func matchEntry(entry *IndexEntry, query string, words []string) matchTier {
+	if entry.Mint == query || strings.EqualFold(entry.Name, query) || strings.EqualFold(entry.DirName, query) {
+		return exactMatch
+	}
+	if len(words) == 0 {
+		return noMatch
+	}
+	if strings.Join(words, " ") == strings.Join(searchWords(entry.Name), " ") {
+		return exactMatch
+	}
+
+	var fields []string
+	for _, field := range []string{entry.Name, entry.Symbol, entry.Collection, entry.DirName} {
+		fields = append(fields, searchWords(field)...)
+	}
+	fields = append(fields, strings.ToLower(entry.Mint))
+
+	tier := wordMatch
+	for _, word := range words {
+		switch matchWord(word, fields) {
+		case noMatch:
+			return noMatch
+		case fuzzyMatch:
+			tier = fuzzyMatch
+		}
+	}
+	return tier
+}
+
+// matchWord ranks the best match of one query word among an entry's words
+func matchWord(word string, fields []string) matchTier {
+	numeric := isNumber(word)
+	best := noMatch
+	for _, field := range fields {
+		if field == word || (!numeric && strings.HasPrefix(field, word)) {
+			return wordMatch
+		}
+		if !numeric && withinTypos(word, field) {
+			best = fuzzyMatch
+		}
+	}
+	return best
+}
+
+// searchWords splits s into lower-case words of letters and digits
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(norm.NFC.String(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// withinTypos reports whether word is at most one edit from field, or two
+// for long words. Words under four letters must match exactly.
+func withinTypos(word, field string) bool {
+	a, b := []rune(word), []rune(field)
+	allowed := 0
+	switch {
+	case len(a) >= 8:
+		allowed = 2
+	case len(a) >= 4:
+		allowed = 1
+	}
+	if allowed == 0 || len(b)-len(a) > allowed || len(a)-len(b) > allowed {
+		return false
+	}
+	return editDistance(a, b) <= allowed
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
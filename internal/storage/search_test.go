@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewMemoryIndex()
+	idx.Put(&IndexEntry{Wallet: "walletA", Mint: "7xKXtg2CW87d", Name: "Cool Cat #1", Symbol: "CCAT", Collection: "Cool Cats", DirName: "Cool Cat #1"})
+	idx.Put(&IndexEntry{Wallet: "walletA", Mint: "9sdfe1xA3sXe", Name: "Cool Cat #12", Symbol: "CCAT", Collection: "Cool Cats", DirName: "Cool Cat #12"})
+	idx.Put(&IndexEntry{Wallet: "walletA", Mint: "4Ab3kQwz", Name: "Degen Ape 7", Symbol: "DAPE", Collection: "Degenerate Ape Academy", DirName: "4Ab3kQwz"})
+	idx.Put(&IndexEntry{Wallet: "walletB", Mint: "HMSefjtSfRV", Name: "Midnight Lion", DirName: "HMSefjtSfRV"})
+	// walletC holds the lion too, stored with walletB's backup
+	idx.Put(&IndexEntry{Wallet: "walletC", Mint: "HMSefjtSfRV", Name: "Midnight Lion", DirName: "HMSefjtSfRV", PrimaryWallet: "walletB"})
+
+	tests := []struct {
+		name   string
+		query  string
+		wallet string
+		want   []string // Mints
+	}{
+		{name: "mint", query: "9sdfe1xA3sXe", want: []string{"9sdfe1xA3sXe"}},
+		{name: "start of mint", query: "4ab3", want: []string{"4Ab3kQwz"}},
+		{name: "exact name wins", query: "cool cat #1", want: []string{"7xKXtg2CW87d"}},
+		{name: "name without punctuation", query: "Cool Cat 12", want: []string{"9sdfe1xA3sXe"}},
+		{name: "words", query: "cat 12", want: []string{"9sdfe1xA3sXe"}},
+		{name: "numbers match whole", query: "cat 2"},
+		{name: "symbol", query: "ccat", want: []string{"7xKXtg2CW87d", "9sdfe1xA3sXe"}},
+		{name: "collection", query: "degenerate ape", want: []string{"4Ab3kQwz"}},
+		{name: "typo", query: "cool kats 12", want: []string{"9sdfe1xA3sXe"}},
+		{name: "missing letter", query: "midnigt lion", want: []string{"HMSefjtSfRV"}},
+		{name: "two typos in a long word", query: "dgenerte ape", want: []string{"4Ab3kQwz"}},
+		{name: "short words need no typos", query: "cot"},
+		{name: "linked wallets resolve once", query: "lion", want: []string{"HMSefjtSfRV"}},
+		{name: "linked wallet", query: "lion", wallet: "walletC", want: []string{"HMSefjtSfRV"}},
+		{name: "other wallet", query: "cool", wallet: "walletB"},
+		{name: "no match", query: "dog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := idx.Search(tt.query, tt.wallet)
+			if len(matches) != len(tt.want) {
+				t.Fatalf("Expected %d matches, got %d", len(tt.want), len(matches))
+			}
+			for i, entry := range matches {
+				if entry.Mint != tt.want[i] {
+					t.Errorf("Match %d: expected %s, got %s", i, tt.want[i], entry.Mint)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,646 @@
+package sftp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// SFTP version 3 packet types (draft-ietf-secsh-filexfer-02), the version
+// OpenSSH and every NAS speaks
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpLstat    = 7
+	fxpFstat    = 8
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpRmdir    = 15
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// Open flags
+const (
+	flagRead   = 0x01
+	flagWrite  = 0x02
+	flagCreate = 0x08
+	flagTrunc  = 0x10
+)
+
+// Status codes
+const (
+	statusOK          = 0
+	statusEOF         = 1
+	statusNoSuchFile  = 2
+	statusDenied      = 3
+	statusUnsupported = 8
+)
+
+// Attribute flags
+const (
+	attrSize        = 0x00000001
+	attrUIDGID      = 0x00000002
+	attrPermissions = 0x00000004
+	attrTimes       = 0x00000008
+	attrExtended    = 0x80000000
+)
+
+const (
+	// maxChunk is the most data one READ or WRITE carries; servers must
+	// accept packets of at least 32KB plus headers
+	maxChunk = 32 * 1024
+
+	// maxPacket refuses absurd lengths from a broken or hostile server
+	maxPacket = 256 * 1024
+
+	// inFlight is how many writes are sent before waiting for replies,
+	// so uploads are not limited by the round trip time
+	inFlight = 16
+
+	// posixRename is the OpenSSH extension that replaces the target of a
+	// rename, which plain SFTP v3 refuses to do
+	posixRename = "posix-rename@openssh.com"
+
+	modeDir = 0040000 // S_IFDIR
+	modeFmt = 0170000 // S_IFMT
+)
+
+// errConnectionLost reports a session that ended, so the caller can
+// reconnect and try again
+var errConnectionLost = errors.New("sftp connection lost")
+
+// statusError is a failed request as the server reported it
+type statusError struct {
+	code    uint32
+	message string
+}
+
+func (e *statusError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("sftp: %s (status %d)", e.message, e.code)
+	}
+	return fmt.Sprintf("sftp: status %d", e.code)
+}
+
+// Is lets errors.Is(err, fs.ErrNotExist) and fs.ErrPermission work on
+// server errors
+func (e *statusError) Is(target error) bool {
+	switch target {
+	case fs.ErrNotExist:
+		return e.code == statusNoSuchFile
+	case fs.ErrPermission:
+		return e.code == statusDenied
+	}
+	return false
+}
+
+// fileInfo is the part of a file's attributes the backend uses
+type fileInfo struct {
+	name  string
+	size  int64
+	mode  uint32
+	isDir bool
+}
+
+// response is one reply routed to the request that asked for it
+type response struct {
+	typ  byte
+	data []byte // After the request ID
+	err  error
+}
+
+// client speaks SFTP over one SSH session's stdin and stdout. Requests may
+// be sent from several goroutines; replies are matched up by request ID.
+type client struct {
+	w          io.WriteCloser
+	writeMu    sync.Mutex
+	mu         sync.Mutex
+	nextID     uint32
+	pending    map[uint32]chan response
+	err        error // Set once the session ends
+	extensions map[string]string
+}
+
+// newClient performs the version handshake and starts reading replies
+func newClient(r io.Reader, w io.WriteCloser) (*client, error) {
+	c := &client{w: w, pending: make(map[uint32]chan response), extensions: make(map[string]string)}
+
+	var init packet
+	init = init.byte(fxpInit).uint32(3)
+	if err := c.send(init); err != nil {
+		return nil, err
+	}
+	typ, data, err := readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start sftp: %w", err)
+	}
+	if typ != fxpVersion {
+		return nil, fmt.Errorf("failed to start sftp: unexpected packet type %d", typ)
+	}
+	reply := &decoder{data: data}
+	if version := reply.uint32(); version < 3 {
+		return nil, fmt.Errorf("server speaks sftp version %d; version 3 is required", version)
+	}
+	for reply.remaining() > 0 {
+		name, value := reply.string(), reply.string()
+		if reply.err != nil {
+			break
+		}
+		c.extensions[name] = value
+	}
+
+	go c.readLoop(r)
+	return c, nil
+}
+
+// readLoop hands every reply to its waiting request until the session ends
+func (c *client) readLoop(r io.Reader) {
+	for {
+		typ, data, err := readPacket(r)
+		if err == nil && len(data) < 4 {
+			err = errors.New("short reply")
+		}
+		if err != nil {
+			c.fail(fmt.Errorf("%w: %v", errConnectionLost, err))
+			return
+		}
+		id := binary.BigEndian.Uint32(data)
+		c.mu.Lock()
+		ch := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- response{typ: typ, data: data[4:]}
+		}
+	}
+}
+
+// fail ends the session for every request still waiting
+func (c *client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	for id, ch := range c.pending {
+		ch <- response{err: c.err}
+		delete(c.pending, id)
+	}
+}
+
+// broken reports whether the session has ended
+func (c *client) broken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err != nil
+}
+
+func (c *client) Close() error {
+	c.fail(errConnectionLost)
+	return c.w.Close()
+}
+
+func (c *client) send(p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	header := binary.BigEndian.AppendUint32(nil, uint32(len(p)))
+	if _, err := c.w.Write(append(header, p...)); err != nil {
+		return fmt.Errorf("%w: %v", errConnectionLost, err)
+	}
+	return nil
+}
+
+// start sends a request built by fill and returns where its reply arrives
+func (c *client) start(typ byte, fill func(packet) packet) (<-chan response, error) {
+	ch := make(chan response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	var p packet
+	p = fill(p.byte(typ).uint32(id))
+	if err := c.send(p); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	return ch, nil
+}
+
+// request sends one request and waits for its reply
+func (c *client) request(typ byte, fill func(packet) packet) (response, error) {
+	ch, err := c.start(typ, fill)
+	if err != nil {
+		return response{}, err
+	}
+	resp := <-ch
+	return resp, resp.err
+}
+
+// expectStatus turns a STATUS reply into nil or an error
+func expectStatus(resp response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp.typ != fxpStatus {
+		return fmt.Errorf("sftp: unexpected reply type %d", resp.typ)
+	}
+	return statusOf(resp.data)
+}
+
+// statusOf decodes a STATUS payload
+func statusOf(data []byte) error {
+	d := &decoder{data: data}
+	code, message := d.uint32(), d.string()
+	if d.err != nil {
+		return d.err
+	}
+	if code == statusOK {
+		return nil
+	}
+	return &statusError{code: code, message: message}
+}
+
+// expectHandle decodes a HANDLE reply
+func expectHandle(resp response, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	switch resp.typ {
+	case fxpHandle:
+		d := &decoder{data: resp.data}
+		handle := d.string()
+		return handle, d.err
+	case fxpStatus:
+		if err := statusOf(resp.data); err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("sftp: unexpected reply type %d", resp.typ)
+}
+
+func pathRequest(name string) func(packet) packet {
+	return func(p packet) packet { return p.string(name) }
+}
+
+// Stat returns the attributes of name, following symlinks
+func (c *client) Stat(name string) (fileInfo, error) {
+	resp, err := c.request(fxpStat, pathRequest(name))
+	if err != nil {
+		return fileInfo{}, err
+	}
+	switch resp.typ {
+	case fxpAttrs:
+		d := &decoder{data: resp.data}
+		info := d.attrs()
+		info.name = path.Base(name)
+		return info, d.err
+	case fxpStatus:
+		if err := statusOf(resp.data); err != nil {
+			return fileInfo{}, err
+		}
+	}
+	return fileInfo{}, fmt.Errorf("sftp: unexpected reply type %d", resp.typ)
+}
+
+// Mkdir creates one directory
+func (c *client) Mkdir(name string) error {
+	return expectStatus(c.request(fxpMkdir, func(p packet) packet { return p.string(name).uint32(0) }))
+}
+
+// MkdirAll creates name and any missing parents
+//
+// Explanation: SFTP v3 reports an existing directory as a generic failure,
+// so a failed mkdir is only an error if the directory still is not there
+// afterwards. That also keeps concurrent saves into one wallet safe.
+func (c *client) MkdirAll(name string) error {
+	if info, err := c.Stat(name); err == nil {
+		if !info.isDir {
+			return fmt.Errorf("%s exists and is not a directory", name)
+		}
+		return nil
+	}
+	if parent := path.Dir(name); parent != name && parent != "/" && parent != "." {
+		if err := c.MkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	mkdirErr := c.Mkdir(name)
+	if mkdirErr == nil {
+		return nil
+	}
+	if info, err := c.Stat(name); err == nil && info.isDir {
+		return nil
+	}
+	return fmt.Errorf("failed to create %s: %w", name, mkdirErr)
+}
+
+// Remove deletes a file
+func (c *client) Remove(name string) error {
+	return expectStatus(c.request(fxpRemove, pathRequest(name)))
+}
+
+// RemoveAll deletes name and everything below it
+func (c *client) RemoveAll(name string) error {
+	info, err := c.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.isDir {
+		return c.Remove(name)
+	}
+	entries, err := c.ReadDir(name)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := c.RemoveAll(path.Join(name, entry.name)); err != nil {
+			return err
+		}
+	}
+	return expectStatus(c.request(fxpRmdir, pathRequest(name)))
+}
+
+// Rename moves oldName to newName, replacing newName if it exists
+func (c *client) Rename(oldName, newName string) error {
+	if _, ok := c.extensions[posixRename]; ok {
+		return expectStatus(c.request(fxpExtended, func(p packet) packet {
+			return p.string(posixRename).string(oldName).string(newName)
+		}))
+	}
+	// Without the extension the target must go first; a crash in between
+	// leaves the complete new file under oldName, and the next save
+	// writes it again
+	if err := c.Remove(newName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return expectStatus(c.request(fxpRename, func(p packet) packet { return p.string(oldName).string(newName) }))
+}
+
+// ReadDir lists a directory, without . and ..
+func (c *client) ReadDir(name string) ([]fileInfo, error) {
+	handle, err := expectHandle(c.request(fxpOpendir, pathRequest(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(handle)
+
+	var entries []fileInfo
+	for {
+		resp, err := c.request(fxpReaddir, pathRequest(handle))
+		if err != nil {
+			return nil, err
+		}
+		if resp.typ == fxpStatus {
+			err := statusOf(resp.data)
+			var status *statusError
+			if errors.As(err, &status) && status.code == statusEOF {
+				return entries, nil
+			}
+			if err == nil {
+				err = errors.New("sftp: empty directory reply")
+			}
+			return nil, err
+		}
+		if resp.typ != fxpName {
+			return nil, fmt.Errorf("sftp: unexpected reply type %d", resp.typ)
+		}
+		d := &decoder{data: resp.data}
+		for count := d.uint32(); count > 0 && d.err == nil; count-- {
+			entryName := d.string()
+			d.string() // ls -l style long name
+			info := d.attrs()
+			info.name = entryName
+			if entryName != "." && entryName != ".." {
+				entries = append(entries, info)
+			}
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+}
+
+// ReadFile returns the contents of a file
+func (c *client) ReadFile(name string) ([]byte, error) {
+	handle, err := c.open(name, flagRead)
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(handle)
+
+	var data []byte
+	for {
+		offset := uint64(len(data))
+		resp, err := c.request(fxpRead, func(p packet) packet { return p.string(handle).uint64(offset).uint32(maxChunk) })
+		if err != nil {
+			return nil, err
+		}
+		switch resp.typ {
+		case fxpData:
+			d := &decoder{data: resp.data}
+			chunk := d.string()
+			if d.err != nil {
+				return nil, d.err
+			}
+			data = append(data, chunk...)
+		case fxpStatus:
+			err := statusOf(resp.data)
+			var status *statusError
+			if errors.As(err, &status) && status.code == statusEOF {
+				return data, nil
+			}
+			if err == nil {
+				err = errors.New("sftp: empty read reply")
+			}
+			return nil, err
+		default:
+			return nil, fmt.Errorf("sftp: unexpected reply type %d", resp.typ)
+		}
+	}
+}
+
+// WriteFile replaces the contents of a file
+func (c *client) WriteFile(name string, data []byte) error {
+	handle, err := c.open(name, flagWrite|flagCreate|flagTrunc)
+	if err != nil {
+		return err
+	}
+	if err := c.writeAt(handle, data, 0, nil); err != nil {
+		c.closeHandle(handle)
+		return err
+	}
+	return c.closeHandle(handle)
+}
+
+// writeAt writes data at offset, keeping several writes in flight. stop,
+// when set, is checked between chunks so a cancelled upload ends early.
+func (c *client) writeAt(handle string, data []byte, offset int64, stop func() error) error {
+	var waiting []<-chan response
+	wait := func() error {
+		ch := waiting[0]
+		waiting = waiting[1:]
+		resp := <-ch
+		return expectStatus(resp, resp.err)
+	}
+
+	var firstErr error
+	for start := 0; start < len(data) && firstErr == nil; start += maxChunk {
+		if stop != nil {
+			if firstErr = stop(); firstErr != nil {
+				break
+			}
+		}
+		chunk := data[start:min(start+maxChunk, len(data))]
+		at := uint64(offset) + uint64(start)
+		ch, err := c.start(fxpWrite, func(p packet) packet { return p.string(handle).uint64(at).bytes(chunk) })
+		if err != nil {
+			firstErr = err
+			break
+		}
+		waiting = append(waiting, ch)
+		if len(waiting) >= inFlight {
+			firstErr = wait()
+		}
+	}
+	for len(waiting) > 0 {
+		if err := wait(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *client) open(name string, flags uint32) (string, error) {
+	return expectHandle(c.request(fxpOpen, func(p packet) packet { return p.string(name).uint32(flags).uint32(0) }))
+}
+
+func (c *client) closeHandle(handle string) error {
+	return expectStatus(c.request(fxpClose, pathRequest(handle)))
+}
+
+// readPacket reads one length-prefixed packet
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxPacket {
+		return 0, nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	return header[4], data, nil
+}
+
+// packet builds a packet body
+type packet []byte
+
+func (p packet) byte(b byte) packet { return append(p, b) }
+
+func (p packet) uint32(v uint32) packet { return binary.BigEndian.AppendUint32(p, v) }
+
+func (p packet) uint64(v uint64) packet { return binary.BigEndian.AppendUint64(p, v) }
+
+func (p packet) string(s string) packet { return append(p.uint32(uint32(len(s))), s...) }
+
+func (p packet) bytes(b []byte) packet { return append(p.uint32(uint32(len(b))), b...) }
+
+// decoder reads packet fields, remembering the first short read
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) remaining() int {
+	return len(d.data)
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.data) {
+		d.err = errors.New("sftp: truncated packet")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	return string(d.take(int(d.uint32())))
+}
+
+// attrs decodes an ATTRS block
+func (d *decoder) attrs() fileInfo {
+	var info fileInfo
+	flags := d.uint32()
+	if flags&attrSize != 0 {
+		info.size = int64(d.uint64())
+	}
+	if flags&attrUIDGID != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		info.mode = d.uint32()
+		info.isDir = info.mode&modeFmt == modeDir
+	}
+	if flags&attrTimes != 0 {
+		d.uint32()
+		d.uint32()
+	}
+	if flags&attrExtended != 0 {
+		for count := d.uint32(); count > 0 && d.err == nil; count-- {
+			d.string()
+			d.string()
+		}
+	}
+	return info
+}
@@ -0,0 +1,242 @@
+package sftp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// dialTimeout bounds connecting and the SSH handshake
+const dialTimeout = 30 * time.Second
+
+// Options says how to authenticate to the server and how to recognise it
+type Options struct {
+	// KnownHosts is the known_hosts file the server's host key must be
+	// listed in; empty means ~/.ssh/known_hosts
+	KnownHosts string
+
+	// HostKeys pins the server's key by fingerprint, e.g. "SHA256:..." as
+	// printed by ssh-keygen -lf. When set, known_hosts is not consulted.
+	HostKeys []string
+
+	// KeyFile is a private key to log in with. Without one the SSH agent
+	// and the usual ~/.ssh/id_* keys are tried.
+	KeyFile       string
+	KeyPassphrase string
+
+	// Password logs in with a password, for servers without key login
+	Password string
+}
+
+// OptionsFromEnv reads the SFTP_* settings from the environment (and so
+// from the config file, once loaded)
+func OptionsFromEnv() Options {
+	opts := Options{
+		KnownHosts:    strings.TrimSpace(os.Getenv("SFTP_KNOWN_HOSTS")),
+		KeyFile:       strings.TrimSpace(os.Getenv("SFTP_KEY_FILE")),
+		KeyPassphrase: os.Getenv("SFTP_KEY_PASSPHRASE"),
+		Password:      os.Getenv("SFTP_PASSWORD"),
+	}
+	for _, fingerprint := range strings.Split(os.Getenv("SFTP_HOST_KEY"), ",") {
+		if fingerprint = strings.TrimSpace(fingerprint); fingerprint != "" {
+			opts.HostKeys = append(opts.HostKeys, fingerprint)
+		}
+	}
+	return opts
+}
+
+// target is where a backend keeps its files
+type target struct {
+	user string
+	host string
+	port string
+	root string
+}
+
+// addr is the host:port to dial
+func (t target) addr() string {
+	return net.JoinHostPort(t.host, t.port)
+}
+
+func (t target) String() string {
+	host := t.host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if t.port != "22" {
+		host += ":" + t.port
+	}
+	return t.user + "@" + host + t.root
+}
+
+// parseLocation parses [user@]host[:port]/path, with or without a leading
+// sftp:// or //
+func parseLocation(location string) (target, error) {
+	invalid := fmt.Errorf("invalid SFTP location %q: use user@host[:port]/path/to/vault", location)
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(location), "sftp:"), "//")
+	slash := strings.Index(rest, "/")
+	if rest == "" || slash <= 0 {
+		return target{}, invalid
+	}
+	hostPart, root := rest[:slash], path.Clean(rest[slash:])
+	if root == "/" {
+		return target{}, invalid
+	}
+
+	t := target{root: root, port: "22"}
+	if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+		t.user, hostPart = hostPart[:at], hostPart[at+1:]
+	}
+	t.host = hostPart
+	if host, port, err := net.SplitHostPort(hostPart); err == nil {
+		t.host, t.port = host, port
+	} else if strings.HasPrefix(hostPart, "[") && strings.HasSuffix(hostPart, "]") {
+		t.host = hostPart[1 : len(hostPart)-1]
+	}
+	if t.host == "" || t.port == "" {
+		return target{}, invalid
+	}
+	if t.user == "" {
+		if current, err := user.Current(); err == nil {
+			t.user = current.Username
+		}
+	}
+	return t, nil
+}
+
+// clientConfig builds the SSH settings for logging in to t
+func clientConfig(t target, opts Options) (*ssh.ClientConfig, error) {
+	hostKeyCallback, err := opts.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := opts.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:            t.user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	}, nil
+}
+
+// hostKeyCallback checks the server's key against the pinned fingerprints
+// or known_hosts
+//
+// Explanation: There is deliberately no way to skip the check. A replica
+// receives every backup, so a spoofed server would receive them too; an
+// unknown host fails with its fingerprint so the user can compare it
+// against the server and pin it.
+func (opts Options) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if len(opts.HostKeys) > 0 {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			got := ssh.FingerprintSHA256(key)
+			for _, want := range opts.HostKeys {
+				if want == got {
+					return nil
+				}
+			}
+			return fmt.Errorf("host key of %s is %s, which is not a key SFTP_HOST_KEY allows", hostname, got)
+		}, nil
+	}
+
+	file := opts.KnownHosts
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find home directory: %w", err)
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	check, err := knownhosts.New(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no known_hosts file at %s; connect to the server once with ssh, or set SFTP_HOST_KEY to its fingerprint", file)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("%s is not in %s (its key is %s); connect once with ssh to add it, or set SFTP_HOST_KEY after checking the fingerprint on the server",
+					hostname, file, ssh.FingerprintSHA256(key))
+			}
+			return fmt.Errorf("host key of %s does not match %s: the server was reinstalled or someone is intercepting the connection (got %s)",
+				hostname, file, ssh.FingerprintSHA256(key))
+		}
+		return err
+	}, nil
+}
+
+// authMethods returns the ways to log in, in the order they are tried
+func (opts Options) authMethods() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if opts.KeyFile != "" {
+		signer, err := loadKey(opts.KeyFile, opts.KeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	} else {
+		if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+			if conn, err := net.Dial("unix", socket); err == nil {
+				methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			}
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			var signers []ssh.Signer
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				if signer, err := loadKey(filepath.Join(home, ".ssh", name), opts.KeyPassphrase); err == nil {
+					signers = append(signers, signer)
+				}
+			}
+			if len(signers) > 0 {
+				methods = append(methods, ssh.PublicKeys(signers...))
+			}
+		}
+	}
+
+	if opts.Password != "" {
+		methods = append(methods, ssh.Password(opts.Password))
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no SSH key or password to log in with; set SFTP_KEY_FILE or SFTP_PASSWORD, or start ssh-agent")
+	}
+	return methods, nil
+}
+
+// loadKey reads a private key, decrypting it with passphrase if needed
+func loadKey(file, passphrase string) (ssh.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("SSH key %s is protected; set SFTP_KEY_PASSPHRASE or load it into ssh-agent", file)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", file, err)
+	}
+	return signer, nil
+}
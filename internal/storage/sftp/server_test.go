@@ -0,0 +1,302 @@
+package sftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer is an SSH server on localhost whose sftp subsystem serves a
+// temporary directory
+type testServer struct {
+	addr    string
+	root    string
+	hostKey ssh.PublicKey
+	keyFile string // Client key the server accepts
+
+	// dropAfter, when positive, cuts the connection once that many bytes
+	// have been written, to interrupt an upload
+	dropAfter atomic.Int64
+	written   atomic.Int64
+
+	posixRename bool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("Failed to create host key: %v", err)
+	}
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatalf("Failed to create client key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal client key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write client key: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &testServer{addr: listener.Addr().String(), root: t.TempDir(), hostKey: hostSigner.PublicKey(), keyFile: keyFile, posixRename: true}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, config)
+		}
+	}()
+	return s
+}
+
+// location is the backend location for dir on the server
+func (s *testServer) location(dir string) string {
+	host, port, _ := net.SplitHostPort(s.addr)
+	return "tester@" + host + ":" + port + dir
+}
+
+// knownHosts writes a known_hosts file listing key for the server
+func (s *testServer) knownHosts(t *testing.T, key ssh.PublicKey) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(file, []byte(knownhosts.Line([]string{s.addr}, key)+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write known_hosts: %v", err)
+	}
+	return file
+}
+
+// options trusts the server and logs in with its client key
+func (s *testServer) options(t *testing.T) Options {
+	return Options{KnownHosts: s.knownHosts(t, s.hostKey), KeyFile: s.keyFile}
+}
+
+func (s *testServer) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					go func() {
+						s.serveSFTP(channel)
+						serverConn.Close()
+					}()
+				}
+			}
+		}()
+	}
+}
+
+// serveSFTP answers requests until the client goes away or the connection
+// is cut
+func (s *testServer) serveSFTP(rw io.ReadWriter) {
+	var writeMu sync.Mutex
+	reply := func(p packet) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		rw.Write(append(packet{}.uint32(uint32(len(p))), p...))
+	}
+
+	typ, _, err := readPacket(rw)
+	if err != nil || typ != fxpInit {
+		return
+	}
+	version := packet{}.byte(fxpVersion).uint32(3)
+	if s.posixRename {
+		version = version.string(posixRename).string("1")
+	}
+	reply(version)
+
+	files := map[string]*os.File{}
+	dirs := map[string][]os.DirEntry{}
+	nextHandle := 0
+	newHandle := func() string {
+		nextHandle++
+		return strconv.Itoa(nextHandle)
+	}
+
+	for {
+		typ, data, err := readPacket(rw)
+		if err != nil {
+			return
+		}
+		d := &decoder{data: data}
+		id := d.uint32()
+		status := func(err error) {
+			code, message := uint32(statusOK), ""
+			switch {
+			case err == nil:
+			case errors.Is(err, io.EOF):
+				code = statusEOF
+			case errors.Is(err, fs.ErrNotExist):
+				code, message = statusNoSuchFile, err.Error()
+			default:
+				code, message = 4, err.Error()
+			}
+			reply(packet{}.byte(fxpStatus).uint32(id).uint32(code).string(message).string(""))
+		}
+		local := func(name string) string {
+			return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+name)))
+		}
+
+		switch typ {
+		case fxpOpen:
+			name, flags := d.string(), d.uint32()
+			mode := os.O_RDONLY
+			if flags&flagWrite != 0 {
+				mode = os.O_WRONLY
+			}
+			if flags&flagCreate != 0 {
+				mode |= os.O_CREATE
+			}
+			if flags&flagTrunc != 0 {
+				mode |= os.O_TRUNC
+			}
+			file, err := os.OpenFile(local(name), mode, 0644)
+			if err != nil {
+				status(err)
+				continue
+			}
+			handle := newHandle()
+			files[handle] = file
+			reply(packet{}.byte(fxpHandle).uint32(id).string(handle))
+		case fxpClose:
+			handle := d.string()
+			if file, ok := files[handle]; ok {
+				delete(files, handle)
+				status(file.Close())
+			} else {
+				delete(dirs, handle)
+				status(nil)
+			}
+		case fxpRead:
+			file, offset, length := files[d.string()], int64(d.uint64()), d.uint32()
+			buf := make([]byte, length)
+			n, err := file.ReadAt(buf, offset)
+			if n > 0 {
+				reply(packet{}.byte(fxpData).uint32(id).bytes(buf[:n]))
+			} else {
+				status(err)
+			}
+		case fxpWrite:
+			file, offset, chunk := files[d.string()], int64(d.uint64()), d.string()
+			if limit := s.dropAfter.Load(); limit > 0 && s.written.Load()+int64(len(chunk)) > limit {
+				s.dropAfter.Store(0)
+				return
+			}
+			s.written.Add(int64(len(chunk)))
+			_, err := file.WriteAt([]byte(chunk), offset)
+			status(err)
+		case fxpStat, fxpLstat:
+			info, err := os.Stat(local(d.string()))
+			if err != nil {
+				status(err)
+				continue
+			}
+			mode := uint32(0100644)
+			if info.IsDir() {
+				mode = modeDir | 0755
+			}
+			reply(packet{}.byte(fxpAttrs).uint32(id).uint32(attrSize | attrPermissions).uint64(uint64(info.Size())).uint32(mode))
+		case fxpMkdir:
+			status(os.Mkdir(local(d.string()), 0755))
+		case fxpRmdir:
+			status(os.Remove(local(d.string())))
+		case fxpRemove:
+			status(os.Remove(local(d.string())))
+		case fxpRename:
+			oldName, newName := local(d.string()), local(d.string())
+			if _, err := os.Stat(newName); err == nil {
+				status(errors.New("target exists"))
+				continue
+			}
+			status(os.Rename(oldName, newName))
+		case fxpExtended:
+			if d.string() != posixRename || !s.posixRename {
+				reply(packet{}.byte(fxpStatus).uint32(id).uint32(statusUnsupported).string("unsupported").string(""))
+				continue
+			}
+			status(os.Rename(local(d.string()), local(d.string())))
+		case fxpOpendir:
+			entries, err := os.ReadDir(local(d.string()))
+			if err != nil {
+				status(err)
+				continue
+			}
+			handle := newHandle()
+			dirs[handle] = entries
+			reply(packet{}.byte(fxpHandle).uint32(id).string(handle))
+		case fxpReaddir:
+			handle := d.string()
+			entries := dirs[handle]
+			if len(entries) == 0 {
+				status(io.EOF)
+				continue
+			}
+			dirs[handle] = nil
+			p := packet{}.byte(fxpName).uint32(id).uint32(uint32(len(entries)))
+			for _, entry := range entries {
+				mode := uint32(0100644)
+				if entry.IsDir() {
+					mode = modeDir | 0755
+				}
+				p = p.string(entry.Name()).string(entry.Name()).uint32(attrPermissions).uint32(mode)
+			}
+			reply(p)
+		default:
+			reply(packet{}.byte(fxpStatus).uint32(id).uint32(statusUnsupported).string("unsupported").string(""))
+		}
+	}
+}
@@ -0,0 +1,476 @@
+// Package sftp is a storage backend that keeps a copy of every backup on a
+// NAS or any server reachable over SSH.
+//
+// It registers itself as "sftp", for use as a replica after the vault:
+//
+//	solvault sync --backend file,sftp:me@nas.lan/volume1/SolVault
+//
+// The server's host key must be in known_hosts (SFTP_KNOWN_HOSTS) or pinned
+// by fingerprint (SFTP_HOST_KEY). Backups keep the vault's layout under the
+// remote directory:
+//
+//	wallets/{wallet_address}/nfts/{mint_address}/
+//	    ├── nft_data.json
+//	    ├── metadata.json
+//	    └── media/
+//
+// Records and media are written as plain files, even from an encrypted
+// vault, so the copy stays readable on its own.
+package sftp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+	solanago "github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/ssh"
+)
+
+func init() {
+	storage.Register("sftp", func(location string) (storage.StorageBackend, error) {
+		return New(location, OptionsFromEnv())
+	})
+}
+
+const (
+	// stagedSuffix marks a file written by BeginSave and not yet committed
+	stagedSuffix = ".staged"
+
+	// partialSuffix marks a media upload that has not finished
+	partialSuffix = ".partial"
+
+	// uploadAttempts is how often a media upload reconnects and resumes
+	// after losing the connection before giving up until the next save
+	uploadAttempts = 3
+)
+
+// Backend stores backups in a directory on an SFTP server
+type Backend struct {
+	target target
+	config *ssh.ClientConfig
+
+	mu   sync.Mutex
+	conn *ssh.Client
+	sftp *client
+}
+
+// New connects to the server at location, [user@]host[:port]/path
+func New(location string, opts Options) (*Backend, error) {
+	t, err := parseLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientConfig(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	b := &Backend{target: t, config: config}
+	c, err := b.session()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.MkdirAll(t.root); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to create %s on %s: %w", t.root, t.host, err)
+	}
+	return b, nil
+}
+
+func (b *Backend) String() string {
+	return "sftp:" + b.target.String()
+}
+
+// session returns the SFTP session, connecting again if the last one was
+// lost
+func (b *Backend) session() (*client, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sftp != nil && !b.sftp.broken() {
+		return b.sftp, nil
+	}
+	b.disconnect()
+
+	conn, err := ssh.Dial("tcp", b.target.addr(), b.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", b.target.addr(), err)
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("server does not offer SFTP: %w", err)
+	}
+	c, err := newClient(stdout, stdin)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	b.conn, b.sftp = conn, c
+	return c, nil
+}
+
+// disconnect drops the current connection; b.mu must be held
+func (b *Backend) disconnect() error {
+	var err error
+	if b.sftp != nil {
+		b.sftp.Close()
+		b.sftp = nil
+	}
+	if b.conn != nil {
+		err = b.conn.Close()
+		b.conn = nil
+	}
+	return err
+}
+
+// nftDir is the remote directory of one NFT's backup
+func (b *Backend) nftDir(walletAddr, mintAddr solanago.PublicKey) string {
+	return path.Join(b.target.root, "wallets", walletAddr.String(), "nfts", mintAddr.String())
+}
+
+// SaveNFT stores an NFT's record and metadata
+func (b *Backend) SaveNFT(ctx context.Context, nftInfo *fetcher.NFTInfo) error {
+	txn, err := b.BeginSave(ctx, nftInfo)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+	return txn.Commit()
+}
+
+// BeginSave uploads the NFT's files under staged names; Commit renames them
+// into place, record last
+//
+// Explanation: Until the rename of nft_data.json the previous backup is
+// what GetNFT sees, and an abandoned save leaves only hidden .staged files
+// that the next save overwrites.
+func (b *Backend) BeginSave(ctx context.Context, nftInfo *fetcher.NFTInfo) (storage.Transaction, error) {
+	c, err := b.session()
+	if err != nil {
+		return nil, err
+	}
+	dir := b.nftDir(nftInfo.Owner, nftInfo.MintAddress)
+	if err := c.MkdirAll(dir); err != nil {
+		return nil, err
+	}
+
+	previous, err := b.GetNFT(ctx, nftInfo.Owner, nftInfo.MintAddress)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	record, err := newRecord(nftInfo, previous, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := &transaction{client: c, dir: dir}
+	if nftInfo.Metadata != nil {
+		if err := txn.stage("metadata.json", nftInfo.Metadata); err != nil {
+			txn.Rollback()
+			return nil, err
+		}
+	}
+	if err := txn.stage("nft_data.json", record); err != nil {
+		txn.Rollback()
+		return nil, err
+	}
+	return txn, nil
+}
+
+// newRecord builds the record to save, carrying over what a re-save keeps
+// from previous: the original backup date and the metadata version history
+func newRecord(nftInfo *fetcher.NFTInfo, previous *storage.StoredNFT, dir string) (*storage.StoredNFT, error) {
+	now := time.Now()
+	fingerprint := storage.MetadataFingerprint(nftInfo)
+	record := &storage.StoredNFT{
+		NFTInfo:         nftInfo,
+		SchemaVersion:   storage.RecordSchemaVersion,
+		StoredAt:        now,
+		UpdatedAt:       now,
+		Version:         1,
+		BackupPath:      dir,
+		Status:          storage.StatusHeld,
+		StatusChangedAt: now,
+		Versions:        []storage.MetadataVersion{{Version: 1, URI: nftInfo.MetadataURI, Fingerprint: fingerprint, SavedAt: now}},
+	}
+	if previous != nil && previous.NFTInfo != nil {
+		record.StoredAt = previous.StoredAt
+		record.StatusChangedAt = previous.StatusChangedAt
+		record.Version = previous.Version
+		record.Versions = previous.Versions
+		if storage.MetadataFingerprint(previous.NFTInfo) != fingerprint {
+			record.Version++
+			record.Versions = append(record.Versions, storage.MetadataVersion{
+				Version:     record.Version,
+				URI:         nftInfo.MetadataURI,
+				Fingerprint: fingerprint,
+				SavedAt:     now,
+			})
+		}
+	}
+
+	data, err := json.Marshal(nftInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	record.Checksum = sha256Hex(data)
+	return record, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetNFT reads an NFT's record; a missing one wraps fs.ErrNotExist
+func (b *Backend) GetNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (*storage.StoredNFT, error) {
+	c, err := b.session()
+	if err != nil {
+		return nil, err
+	}
+	return readRecord(c, path.Join(b.nftDir(walletAddr, mintAddr), "nft_data.json"), mintAddr.String())
+}
+
+func readRecord(c *client, file, mint string) (*storage.StoredNFT, error) {
+	data, err := c.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("NFT not found: %s: %w", mint, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load NFT data: %w", err)
+	}
+	if data, _, err = storage.MigrateRecord(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	var record storage.StoredNFT
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", file, err)
+	}
+	return &record, nil
+}
+
+// ListNFTs returns every NFT stored for a wallet
+func (b *Backend) ListNFTs(ctx context.Context, walletAddr solanago.PublicKey) ([]*storage.StoredNFT, error) {
+	c, err := b.session()
+	if err != nil {
+		return nil, err
+	}
+	walletDir := path.Join(b.target.root, "wallets", walletAddr.String(), "nfts")
+	entries, err := c.ReadDir(walletDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*storage.StoredNFT{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", walletDir, err)
+	}
+
+	nfts := []*storage.StoredNFT{}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !entry.isDir || strings.HasPrefix(entry.name, ".") {
+			continue
+		}
+		record, err := readRecord(c, path.Join(walletDir, entry.name, "nft_data.json"), entry.name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // First save not committed yet
+		}
+		if err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, record)
+	}
+	return nfts, nil
+}
+
+// DeleteNFT removes an NFT's backup and its media
+func (b *Backend) DeleteNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) error {
+	c, err := b.session()
+	if err != nil {
+		return err
+	}
+	dir := b.nftDir(walletAddr, mintAddr)
+	if _, err := c.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("NFT not found: %s", mintAddr.String())
+	} else if err != nil {
+		return err
+	}
+	if err := c.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", dir, err)
+	}
+	return nil
+}
+
+// PutMedia uploads one media file or thumbnail of a saved NFT
+//
+// Explanation: The upload goes to a hidden .partial file named after the
+// content's hash, so a dropped connection, or an interrupted run, resumes
+// from what already arrived instead of starting over, and a partial file
+// can never be mixed with different content. A .sha256 file next to each
+// finished upload lets later saves skip media that has not changed.
+func (b *Backend) PutMedia(ctx context.Context, walletAddr, mintAddr solanago.PublicKey, name string, data []byte) error {
+	dir, base := path.Split(name)
+	if base == "" || strings.HasPrefix(base, ".") || strings.Contains(base, "\\") || (dir != "" && dir != thumbnail.Dir+"/") {
+		return fmt.Errorf("invalid media file name %q", name)
+	}
+	mediaDir := path.Join(b.nftDir(walletAddr, mintAddr), "media", dir)
+	target := path.Join(mediaDir, base)
+	checksum := sha256Hex(data)
+	sidecar := path.Join(mediaDir, "."+base+".sha256")
+	partial := path.Join(mediaDir, "."+base+"."+checksum[:16]+partialSuffix)
+
+	var err error
+	for attempt := 0; attempt < uploadAttempts; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		var c *client
+		if c, err = b.session(); err != nil {
+			return err
+		}
+		if unchanged(c, target, sidecar, checksum, int64(len(data))) {
+			return nil
+		}
+		if err = c.MkdirAll(mediaDir); err == nil {
+			err = upload(ctx, c, partial, data)
+		}
+		if err == nil {
+			if err = c.Rename(partial, target); err == nil {
+				return c.WriteFile(sidecar, []byte(checksum+"\n"))
+			}
+		}
+		if !errors.Is(err, errConnectionLost) {
+			break
+		}
+	}
+	return fmt.Errorf("failed to upload %s: %w", name, err)
+}
+
+// unchanged reports whether target already holds content with checksum
+func unchanged(c *client, target, sidecar, checksum string, size int64) bool {
+	recorded, err := c.ReadFile(sidecar)
+	if err != nil || strings.TrimSpace(string(recorded)) != checksum {
+		return false
+	}
+	info, err := c.Stat(target)
+	return err == nil && info.size == size
+}
+
+// upload writes data to partial, continuing after whatever an earlier
+// attempt left there
+func upload(ctx context.Context, c *client, partial string, data []byte) error {
+	var offset int64
+	flags := uint32(flagWrite | flagCreate)
+	if info, err := c.Stat(partial); err == nil && info.size <= int64(len(data)) {
+		offset = info.size
+	} else {
+		flags |= flagTrunc
+	}
+	if offset == int64(len(data)) {
+		return nil
+	}
+
+	handle, err := c.open(partial, flags)
+	if err != nil {
+		return err
+	}
+	if err := c.writeAt(handle, data[offset:], offset, ctx.Err); err != nil {
+		c.closeHandle(handle)
+		return err
+	}
+	return c.closeHandle(handle)
+}
+
+// Capabilities reports what an SFTP server provides
+//
+// Explanation: Like local files, remote files are overwritten in place with
+// no locking or server-side checksums.
+func (b *Backend) Capabilities() storage.Capabilities {
+	return storage.Capabilities{}
+}
+
+// Close ends the connection
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.disconnect()
+}
+
+// transaction is a save staged on the server
+type transaction struct {
+	client *client
+	dir    string
+	staged []string // In commit order
+	done   bool
+}
+
+func (t *transaction) stage(name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	if err := t.client.WriteFile(t.stagedPath(name), data); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	t.staged = append(t.staged, name)
+	return nil
+}
+
+func (t *transaction) stagedPath(name string) string {
+	return path.Join(t.dir, "."+name+stagedSuffix)
+}
+
+// Commit renames every staged file into place
+func (t *transaction) Commit() error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+	for _, name := range t.staged {
+		if err := t.client.Rename(t.stagedPath(name), path.Join(t.dir, name)); err != nil {
+			return fmt.Errorf("failed to commit %s: %w", name, err)
+		}
+	}
+	t.done = true
+	return nil
+}
+
+// Rollback removes the staged files
+func (t *transaction) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	var firstErr error
+	for _, name := range t.staged {
+		if err := t.client.Remove(t.stagedPath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
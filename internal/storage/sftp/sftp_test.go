@@ -0,0 +1,253 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/storage/storagetest"
+	solanago "github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/ssh"
+)
+
+func newBackend(t *testing.T, server *testServer) *Backend {
+	t.Helper()
+	backend, err := New(server.location("/vault"), server.options(t))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return backend
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+		return newBackend(t, newTestServer(t))
+	})
+}
+
+func TestConformance_PlainRename(t *testing.T) {
+	// Servers without posix-rename need the target removed first
+	storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+		server := newTestServer(t)
+		server.posixRename = false
+		return newBackend(t, server)
+	})
+}
+
+func TestReplica(t *testing.T) {
+	server := newTestServer(t)
+	t.Setenv("SFTP_KNOWN_HOSTS", server.knownHosts(t, server.hostKey))
+	t.Setenv("SFTP_KEY_FILE", server.keyFile)
+
+	specs, err := storage.ParseBackends("file,sftp:" + server.location("/backups/SolVault"))
+	if err != nil {
+		t.Fatalf("Failed to parse backends: %v", err)
+	}
+	vault, err := storage.OpenVault(specs, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open vault: %v", err)
+	}
+	defer vault.Close()
+
+	wallet := solanago.NewWallet().PublicKey()
+	nft := storagetest.NewNFT(wallet, "Replicated")
+	if err := vault.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	record := filepath.Join(server.root, "backups", "SolVault", "wallets", wallet.String(), "nfts", nft.MintAddress.String(), "nft_data.json")
+	if _, err := os.Stat(record); err != nil {
+		t.Fatalf("Save was not replicated: %v", err)
+	}
+
+	if err := vault.DeleteNFT(context.Background(), wallet, nft.MintAddress); err != nil {
+		t.Fatalf("Failed to delete NFT: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(record)); !os.IsNotExist(err) {
+		t.Errorf("Delete was not replicated: %v", err)
+	}
+}
+
+func TestPutMedia_Resume(t *testing.T) {
+	server := newTestServer(t)
+	backend := newBackend(t, server)
+	defer backend.Close()
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := storagetest.NewNFT(wallet, "Large")
+	if err := backend.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	data := make([]byte, 20*maxChunk+123)
+	rand.Read(data)
+	media := filepath.Join(server.root, "vault", "wallets", wallet.String(), "nfts", nft.MintAddress.String(), "media")
+
+	// The connection drops partway; the upload reconnects and carries on
+	// from what arrived
+	start := server.written.Load()
+	server.dropAfter.Store(start + int64(len(data)/2))
+	if err := backend.PutMedia(ctx, wallet, nft.MintAddress, "image.png", data); err != nil {
+		t.Fatalf("Upload did not survive a dropped connection: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(media, "image.png"))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Uploaded file differs (%d of %d bytes): %v", len(got), len(data), err)
+	}
+	if written := server.written.Load() - start; written >= int64(len(data))+int64(len(data))/2 {
+		t.Errorf("Wrote %d bytes for a %d byte file; the upload started over", written, len(data))
+	}
+	if matches, _ := filepath.Glob(filepath.Join(media, ".*"+partialSuffix)); len(matches) != 0 {
+		t.Errorf("Partial files left behind: %v", matches)
+	}
+
+	// Unchanged media is not sent again
+	before := server.written.Load()
+	if err := backend.PutMedia(ctx, wallet, nft.MintAddress, "image.png", data); err != nil {
+		t.Fatalf("Failed to re-upload: %v", err)
+	}
+	if server.written.Load() != before {
+		t.Error("Unchanged media was uploaded again")
+	}
+
+	// Changed media replaces the old file
+	if err := backend.PutMedia(ctx, wallet, nft.MintAddress, "image.png", []byte("smaller")); err != nil {
+		t.Fatalf("Failed to upload changed media: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(media, "image.png")); string(got) != "smaller" {
+		t.Errorf("Changed media not uploaded: %q", got)
+	}
+
+	for _, name := range []string{"../escape.png", ".hidden", "other/x.png"} {
+		if err := backend.PutMedia(ctx, wallet, nft.MintAddress, name, data); err == nil {
+			t.Errorf("PutMedia(%q) should fail", name)
+		}
+	}
+}
+
+func TestPutMedia_ResumesEarlierRun(t *testing.T) {
+	server := newTestServer(t)
+	backend := newBackend(t, server)
+	defer backend.Close()
+
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := storagetest.NewNFT(wallet, "Interrupted")
+	if err := backend.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	// What an earlier run managed to send before it was stopped
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	c, err := backend.session()
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	media := backend.nftDir(wallet, nft.MintAddress) + "/media"
+	if err := c.MkdirAll(media); err != nil {
+		t.Fatalf("Failed to create media directory: %v", err)
+	}
+	sum := sha256Hex(data)
+	if err := c.WriteFile(media+"/.video.mp4."+sum[:16]+partialSuffix, data[:60000]); err != nil {
+		t.Fatalf("Failed to write partial upload: %v", err)
+	}
+
+	before := server.written.Load()
+	if err := backend.PutMedia(ctx, wallet, nft.MintAddress, "video.mp4", data); err != nil {
+		t.Fatalf("Failed to resume upload: %v", err)
+	}
+	if sent := server.written.Load() - before; sent != int64(len(data)-60000)+int64(len(sum)+1) {
+		t.Errorf("Sent %d bytes, want only the missing %d and the checksum", sent, len(data)-60000)
+	}
+	if got, err := c.ReadFile(media + "/video.mp4"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Resumed file differs: %v", err)
+	}
+}
+
+func TestHostKeyVerification(t *testing.T) {
+	server := newTestServer(t)
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewPublicKey(otherPub)
+	location := server.location("/vault")
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string // "" means the connection succeeds
+	}{
+		{"known host", Options{KnownHosts: server.knownHosts(t, server.hostKey)}, ""},
+		{"pinned", Options{HostKeys: []string{ssh.FingerprintSHA256(server.hostKey)}}, ""},
+		{"changed key", Options{KnownHosts: server.knownHosts(t, otherKey)}, "does not match"},
+		{"wrong pin", Options{HostKeys: []string{ssh.FingerprintSHA256(otherKey)}}, "not a key SFTP_HOST_KEY allows"},
+		{"unknown host", Options{KnownHosts: filepath.Join(t.TempDir(), "empty")}, "no known_hosts file"},
+	}
+
+	empty := filepath.Join(t.TempDir(), "known_hosts")
+	os.WriteFile(empty, nil, 0600)
+	tests = append(tests, struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{"not listed", Options{KnownHosts: empty}, ssh.FingerprintSHA256(server.hostKey)})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.KeyFile = server.keyFile
+			backend, err := New(location, test.opts)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Failed to connect: %v", err)
+				}
+				backend.Close()
+				return
+			}
+			if err == nil {
+				backend.Close()
+				t.Fatal("Connected to a server whose key was not trusted")
+			}
+			if !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Error %q does not mention %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string // target.String(); "" expects an error
+	}{
+		{"me@nas.lan/volume1/SolVault", "me@nas.lan/volume1/SolVault"},
+		{"me@nas.lan:2222/volume1/SolVault/", "me@nas.lan:2222/volume1/SolVault"},
+		{"//me@nas.lan/srv/vault", "me@nas.lan/srv/vault"},
+		{"sftp://me@nas.lan/srv/vault", "me@nas.lan/srv/vault"},
+		{"me@[fd00::2]:2222/srv/vault", "me@[fd00::2]:2222/srv/vault"},
+		{"me@nas.lan", ""},
+		{"me@nas.lan/", ""},
+		{"/srv/vault", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		got, err := parseLocation(test.location)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("parseLocation(%q): expected an error, got %s", test.location, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseLocation(%q): %v", test.location, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("parseLocation(%q) = %s, want %s", test.location, got, test.want)
+		}
+	}
+}
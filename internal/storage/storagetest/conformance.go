@@ -0,0 +1,344 @@
+// Package storagetest provides a conformance suite every StorageBackend
+// must pass, so new backends keep the integrity guarantees of FileStorage.
+//
+// A backend's own tests call Run with a constructor for a fresh, empty
+// instance:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.StorageBackend {
+//			backend, err := NewMyBackend(fakeServer(t).URL)
+//			if err != nil {
+//				t.Fatalf("Failed to create backend: %v", err)
+//			}
+//			return backend
+//		})
+//	}
+//
+// Remote backends should drive the suite against an in-process fake of
+// their service so it runs without credentials or network access.
+//
+// The suite currently runs against the file, memory and replicated backends
+// (internal/storage) and the SFTP backend, which tests against an in-process
+// SSH server (internal/storage/sftp). There are no S3, GCS or WebDAV
+// backends yet, so there are no fakes for them; whoever adds one adds its
+// httptest fake and conformance test in the same change.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// Factory returns a fresh, empty backend. The suite closes it when done.
+type Factory func(t *testing.T) storage.StorageBackend
+
+// Run executes every conformance check as a subtest
+func Run(t *testing.T, factory Factory) {
+	checks := []struct {
+		name string
+		fn   func(t *testing.T, backend storage.StorageBackend)
+	}{
+		{"SaveAndGet", testSaveAndGet},
+		{"ResaveBumpsVersion", testResaveBumpsVersion},
+		{"ChecksumTracksContent", testChecksumTracksContent},
+		{"GetMissing", testGetMissing},
+		{"ListIsolatesWallets", testListIsolatesWallets},
+		{"ListEmptyWallet", testListEmptyWallet},
+		{"Delete", testDelete},
+		{"DeleteMissing", testDeleteMissing},
+		{"AwkwardNames", testAwkwardNames},
+		{"ConcurrentSaves", testConcurrentSaves},
+		{"TransactionRollback", testTransactionRollback},
+		{"Capabilities", testCapabilities},
+	}
+
+	for _, check := range checks {
+		t.Run(check.name, func(t *testing.T) {
+			backend := factory(t)
+			t.Cleanup(func() {
+				if err := backend.Close(); err != nil {
+					t.Errorf("Failed to close backend: %v", err)
+				}
+			})
+			check.fn(t, backend)
+		})
+	}
+}
+
+// NewNFT returns a fully populated NFT owned by wallet
+func NewNFT(wallet solanago.PublicKey, name string) *fetcher.NFTInfo {
+	return &fetcher.NFTInfo{
+		MintAddress:  solanago.NewWallet().PublicKey(),
+		TokenAccount: solanago.NewWallet().PublicKey(),
+		Owner:        wallet,
+		Supply:       1,
+		FetchedAt:    time.Now().UTC().Truncate(time.Second),
+		MetadataURI:  "https://example.com/" + name + ".json",
+		Metadata: &fetcher.NFTMetadata{
+			Name:        name,
+			Symbol:      "TEST",
+			Description: "Conformance test NFT",
+			Image:       "https://example.com/" + name + ".png",
+			Attributes:  []fetcher.Attribute{{TraitType: "Background", Value: "Blue"}},
+			Collection:  fetcher.Collection{Name: "Conformance"},
+		},
+	}
+}
+
+func mustSave(t *testing.T, backend storage.StorageBackend, nft *fetcher.NFTInfo) {
+	t.Helper()
+	if err := backend.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT %s: %v", nft.MintAddress, err)
+	}
+}
+
+func mustGet(t *testing.T, backend storage.StorageBackend, wallet, mint solanago.PublicKey) *storage.StoredNFT {
+	t.Helper()
+	stored, err := backend.GetNFT(context.Background(), wallet, mint)
+	if err != nil {
+		t.Fatalf("Failed to get NFT %s: %v", mint, err)
+	}
+	return stored
+}
+
+func testSaveAndGet(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	nft := NewNFT(wallet, "Roundtrip")
+	mustSave(t, backend, nft)
+
+	stored := mustGet(t, backend, wallet, nft.MintAddress)
+	if stored.NFTInfo == nil {
+		t.Fatal("Stored NFT has no NFTInfo")
+	}
+	got := stored.NFTInfo
+	if got.MintAddress != nft.MintAddress || got.Owner != wallet || got.TokenAccount != nft.TokenAccount {
+		t.Errorf("Addresses changed in storage: %+v", got)
+	}
+	if got.MetadataURI != nft.MetadataURI || !got.FetchedAt.Equal(nft.FetchedAt) {
+		t.Errorf("Fields changed in storage: uri %q, fetched %v", got.MetadataURI, got.FetchedAt)
+	}
+	if got.Metadata == nil || got.Metadata.Name != "Roundtrip" || len(got.Metadata.Attributes) != 1 || got.Metadata.Collection.Name != "Conformance" {
+		t.Errorf("Metadata changed in storage: %+v", got.Metadata)
+	}
+	if stored.Checksum == "" {
+		t.Error("Checksum must be recorded")
+	}
+	if stored.Version != 1 {
+		t.Errorf("First save should be version 1, got %d", stored.Version)
+	}
+	if stored.StoredAt.IsZero() {
+		t.Error("StoredAt must be set")
+	}
+}
+
+func testResaveBumpsVersion(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	nft := NewNFT(wallet, "Resave")
+	mustSave(t, backend, nft)
+	first := mustGet(t, backend, wallet, nft.MintAddress)
+
+	nft.Metadata.Description = "Revealed"
+	mustSave(t, backend, nft)
+	second := mustGet(t, backend, wallet, nft.MintAddress)
+
+	if second.Version != first.Version+1 {
+		t.Errorf("Version = %d after resave, want %d", second.Version, first.Version+1)
+	}
+	if !second.StoredAt.Equal(first.StoredAt) {
+		t.Errorf("StoredAt changed on resave: %v -> %v", first.StoredAt, second.StoredAt)
+	}
+	if second.NFTInfo.Metadata.Description != "Revealed" {
+		t.Error("Resave did not store the new metadata")
+	}
+}
+
+func testChecksumTracksContent(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	nft := NewNFT(wallet, "Checksum")
+	mustSave(t, backend, nft)
+	first := mustGet(t, backend, wallet, nft.MintAddress)
+
+	if again := mustGet(t, backend, wallet, nft.MintAddress); again.Checksum != first.Checksum {
+		t.Error("Checksum must be stable across reads")
+	}
+
+	nft.Metadata.Name = "Checksum v2"
+	mustSave(t, backend, nft)
+	if changed := mustGet(t, backend, wallet, nft.MintAddress); changed.Checksum == first.Checksum {
+		t.Error("Checksum must change when the content changes")
+	}
+}
+
+func testGetMissing(t *testing.T, backend storage.StorageBackend) {
+	_, err := backend.GetNFT(context.Background(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey())
+	if err == nil {
+		t.Error("Getting an unknown NFT must fail")
+	}
+}
+
+func testListIsolatesWallets(t *testing.T, backend storage.StorageBackend) {
+	walletA := solanago.NewWallet().PublicKey()
+	walletB := solanago.NewWallet().PublicKey()
+	for i := 0; i < 3; i++ {
+		mustSave(t, backend, NewNFT(walletA, fmt.Sprintf("A%d", i)))
+	}
+	mustSave(t, backend, NewNFT(walletB, "B0"))
+
+	nfts, err := backend.ListNFTs(context.Background(), walletA)
+	if err != nil {
+		t.Fatalf("Failed to list NFTs: %v", err)
+	}
+	if len(nfts) != 3 {
+		t.Fatalf("Expected 3 NFTs for wallet A, got %d", len(nfts))
+	}
+	for _, nft := range nfts {
+		if nft.NFTInfo == nil || nft.NFTInfo.Owner != walletA {
+			t.Errorf("Wallet A listing contains a foreign NFT: %+v", nft.NFTInfo)
+		}
+	}
+}
+
+func testListEmptyWallet(t *testing.T, backend storage.StorageBackend) {
+	nfts, err := backend.ListNFTs(context.Background(), solanago.NewWallet().PublicKey())
+	if err != nil {
+		t.Fatalf("Listing an unknown wallet must not fail: %v", err)
+	}
+	if len(nfts) != 0 {
+		t.Errorf("Expected no NFTs, got %d", len(nfts))
+	}
+}
+
+func testDelete(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	keep := NewNFT(wallet, "Keep")
+	drop := NewNFT(wallet, "Drop")
+	mustSave(t, backend, keep)
+	mustSave(t, backend, drop)
+
+	if err := backend.DeleteNFT(context.Background(), wallet, drop.MintAddress); err != nil {
+		t.Fatalf("Failed to delete NFT: %v", err)
+	}
+	if _, err := backend.GetNFT(context.Background(), wallet, drop.MintAddress); err == nil {
+		t.Error("Deleted NFT is still readable")
+	}
+	mustGet(t, backend, wallet, keep.MintAddress)
+
+	nfts, err := backend.ListNFTs(context.Background(), wallet)
+	if err != nil {
+		t.Fatalf("Failed to list NFTs: %v", err)
+	}
+	if len(nfts) != 1 {
+		t.Errorf("Expected 1 NFT after delete, got %d", len(nfts))
+	}
+}
+
+func testDeleteMissing(t *testing.T, backend storage.StorageBackend) {
+	err := backend.DeleteNFT(context.Background(), solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey())
+	if err == nil {
+		t.Error("Deleting an unknown NFT must fail")
+	}
+}
+
+func testAwkwardNames(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	names := []string{"../../escape", "Slash/Name", "Ünïcødé 🦁", "CON", "", "   ", "a:b*c?d"}
+
+	for _, name := range names {
+		nft := NewNFT(wallet, name)
+		mustSave(t, backend, nft)
+		if stored := mustGet(t, backend, wallet, nft.MintAddress); stored.NFTInfo.Metadata.Name != name {
+			t.Errorf("Name %q came back as %q", name, stored.NFTInfo.Metadata.Name)
+		}
+	}
+}
+
+func testConcurrentSaves(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	const count = 16
+
+	var wg sync.WaitGroup
+	errs := make(chan error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- backend.SaveNFT(context.Background(), NewNFT(wallet, fmt.Sprintf("Concurrent %d", i)))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent save failed: %v", err)
+		}
+	}
+
+	nfts, err := backend.ListNFTs(context.Background(), wallet)
+	if err != nil {
+		t.Fatalf("Failed to list NFTs: %v", err)
+	}
+	if len(nfts) != count {
+		t.Errorf("Expected %d NFTs after concurrent saves, got %d", count, len(nfts))
+	}
+}
+
+func testTransactionRollback(t *testing.T, backend storage.StorageBackend) {
+	wallet := solanago.NewWallet().PublicKey()
+	nft := NewNFT(wallet, "Original")
+	mustSave(t, backend, nft)
+
+	nft.Metadata.Name = "Abandoned"
+	txn, err := backend.BeginSave(context.Background(), nft)
+	if err != nil {
+		t.Fatalf("Failed to begin save: %v", err)
+	}
+	if got := mustGet(t, backend, wallet, nft.MintAddress); got.NFTInfo.Metadata.Name != "Original" {
+		t.Errorf("Staged save visible before commit: %q", got.NFTInfo.Metadata.Name)
+	}
+	if err := txn.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+	if got := mustGet(t, backend, wallet, nft.MintAddress); got.NFTInfo.Metadata.Name != "Original" || got.Version != 1 {
+		t.Errorf("Rollback changed the backup: %q v%d", got.NFTInfo.Metadata.Name, got.Version)
+	}
+
+	// A fresh transaction still commits after a rollback
+	nft.Metadata.Name = "Committed"
+	txn, err = backend.BeginSave(context.Background(), nft)
+	if err != nil {
+		t.Fatalf("Failed to begin save: %v", err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if got := mustGet(t, backend, wallet, nft.MintAddress); got.NFTInfo.Metadata.Name != "Committed" || got.Version != 2 {
+		t.Errorf("Commit not visible: %q v%d", got.NFTInfo.Metadata.Name, got.Version)
+	}
+}
+
+func testCapabilities(t *testing.T, backend storage.StorageBackend) {
+	caps := backend.Capabilities()
+	if caps.MaxObjectSize < 0 {
+		t.Fatalf("MaxObjectSize must not be negative, got %d", caps.MaxObjectSize)
+	}
+
+	// Whatever the limit, a stored NFT record must fit in one object
+	wallet := solanago.NewWallet().PublicKey()
+	nft := NewNFT(wallet, "Capabilities")
+	mustSave(t, backend, nft)
+	record, err := json.Marshal(mustGet(t, backend, wallet, nft.MintAddress))
+	if err != nil {
+		t.Fatalf("Failed to marshal stored NFT: %v", err)
+	}
+	if !caps.Fits(int64(len(record))) {
+		t.Errorf("MaxObjectSize %d cannot hold a %d byte NFT record", caps.MaxObjectSize, len(record))
+	}
+}
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fsutil"
+)
+
+const (
+	// TenantsFile lists a vault's tenants at the vault root
+	TenantsFile = "tenants.json"
+
+	// TenantsDir holds one isolated vault per tenant, by tenant ID
+	TenantsDir = "tenants"
+
+	// tenantKeyPrefix marks SolVault API keys so they are recognisable in logs
+	tenantKeyPrefix = "svk_"
+)
+
+var (
+	// ErrQuotaExceeded is returned when a save would take a vault past its limits
+	ErrQuotaExceeded = errors.New("vault quota exceeded")
+
+	// ErrLowDiskSpace is returned when a save would leave the disk with less
+	// than the vault's MinFreeBytes
+	ErrLowDiskSpace = errors.New("not enough free disk space")
+
+	// ErrWalletNotAllowed is returned when a save is for a wallet outside the vault's limits
+	ErrWalletNotAllowed = errors.New("wallet is not allowed in this vault")
+
+	// ErrUnknownTenant is returned when no tenant has the given ID
+	ErrUnknownTenant = errors.New("unknown tenant")
+)
+
+// tenantIDPattern keeps tenant IDs safe to use as a directory name
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Limits restricts what a vault accepts. Zero values mean unlimited.
+type Limits struct {
+	Wallets  []string `json:"wallets,omitempty"`   // Wallets the vault may back up; empty allows any
+	MaxNFTs  int      `json:"max_nfts,omitempty"`  // Backups the vault may hold
+	MaxBytes int64    `json:"max_bytes,omitempty"` // Bytes the vault may use on disk
+
+	// MinFreeBytes is the free space saves leave on the vault's disk
+	MinFreeBytes int64 `json:"min_free_bytes,omitempty"`
+}
+
+// AllowsWallet reports whether wallet may be backed up under l
+func (l Limits) AllowsWallet(wallet string) bool {
+	if len(l.Wallets) == 0 {
+		return true
+	}
+	for _, allowed := range l.Wallets {
+		if allowed == wallet {
+			return true
+		}
+	}
+	return false
+}
+
+// Tenant is one user of a shared SolVault deployment, with a vault of
+// their own below TenantsDir
+type Tenant struct {
+	ID        string    `json:"id"`
+	KeyHash   string    `json:"key_hash"`       // SHA-256 of the API key; the key itself is never stored
+	Role      string    `json:"role,omitempty"` // What the key may do in 'serve' (viewer or operator)
+	Limits    Limits    `json:"limits"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Tenants is the tenant list of a vault, read from TenantsFile
+type Tenants struct {
+	path    string
+	mu      sync.Mutex
+	Tenants []*Tenant `json:"tenants"`
+}
+
+// LoadTenants reads the tenants of the vault at baseDir. A vault without
+// TenantsFile has no tenants.
+func LoadTenants(baseDir string) (*Tenants, error) {
+	tenants := &Tenants{path: filepath.Join(baseDir, TenantsFile)}
+	data, err := os.ReadFile(tenants.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tenants, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants: %w", err)
+	}
+	if err := json.Unmarshal(data, tenants); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", tenants.path, err)
+	}
+	return tenants, nil
+}
+
+// Save writes the tenant list back to TenantsFile, readable only by its owner
+func (t *Tenants) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenants: %w", err)
+	}
+	tmpPath := t.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tenants: %w", err)
+	}
+	if err := os.Rename(tmpPath, t.path); err != nil {
+		return fmt.Errorf("failed to replace tenants: %w", err)
+	}
+	return nil
+}
+
+// Get returns the tenant with id, or nil
+func (t *Tenants) Get(id string) *Tenant {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tenant := range t.Tenants {
+		if tenant.ID == id {
+			return tenant
+		}
+	}
+	return nil
+}
+
+// List returns the tenants sorted by ID
+func (t *Tenants) List() []*Tenant {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := append([]*Tenant(nil), t.Tenants...)
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Add creates a tenant and returns its API key, which is only ever shown
+// here: the list keeps a hash of it
+func (t *Tenants) Add(id string, limits Limits) (*Tenant, string, error) {
+	if !tenantIDPattern.MatchString(id) {
+		return nil, "", fmt.Errorf("invalid tenant ID %q: use lowercase letters, digits, '-' and '_'", id)
+	}
+	if t.Get(id) != nil {
+		return nil, "", fmt.Errorf("tenant %s already exists", id)
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := tenantKeyPrefix + hex.EncodeToString(secret)
+
+	tenant := &Tenant{ID: id, KeyHash: hashTenantKey(key), Limits: limits, CreatedAt: time.Now().UTC()}
+	t.mu.Lock()
+	t.Tenants = append(t.Tenants, tenant)
+	t.mu.Unlock()
+	return tenant, key, nil
+}
+
+// Remove drops the tenant with id from the list. Its vault is left on disk.
+func (t *Tenants) Remove(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, tenant := range t.Tenants {
+		if tenant.ID == id {
+			t.Tenants = append(t.Tenants[:i], t.Tenants[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %s", ErrUnknownTenant, id)
+}
+
+// Authenticate returns the tenant an API key belongs to, or nil
+//
+// Explanation: Every stored hash is compared in constant time, so how long a
+// lookup takes says nothing about which tenant, if any, a key came close to.
+func (t *Tenants) Authenticate(key string) *Tenant {
+	if key == "" {
+		return nil
+	}
+	hash := []byte(hashTenantKey(key))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var match *Tenant
+	for _, tenant := range t.Tenants {
+		if subtle.ConstantTimeCompare(hash, []byte(tenant.KeyHash)) == 1 {
+			match = tenant
+		}
+	}
+	return match
+}
+
+func hashTenantKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// TenantDir returns the directory of a tenant's vault inside the vault at baseDir
+func TenantDir(baseDir, id string) string {
+	return filepath.Join(baseDir, TenantsDir, id)
+}
+
+// OpenTenantVault opens a tenant's vault inside the vault at baseDir, with
+// the tenant's limits applied
+func OpenTenantVault(baseDir string, tenant *Tenant) (*FileStorage, error) {
+	vault, err := NewFileStorage(TenantDir(baseDir, tenant.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vault of tenant %s: %w", tenant.ID, err)
+	}
+	vault.SetLimits(tenant.Limits)
+	return vault, nil
+}
+
+// Usage is how much of its limits a vault uses
+type Usage struct {
+	NFTs         int      `json:"nfts"`
+	Bytes        int64    `json:"bytes"`
+	MaxNFTs      int      `json:"max_nfts,omitempty"`
+	MaxBytes     int64    `json:"max_bytes,omitempty"`
+	FreeBytes    int64    `json:"free_bytes,omitempty"` // Free space on the vault's disk, when the platform reports it
+	MinFreeBytes int64    `json:"min_free_bytes,omitempty"`
+	Wallets      []string `json:"wallets,omitempty"`
+}
+
+// SetLimits restricts what the vault accepts from now on. Backups already
+// in the vault are kept whatever the limits.
+func (fs *FileStorage) SetLimits(limits Limits) {
+	fs.limits = limits
+}
+
+// Limits returns the limits set with SetLimits
+func (fs *FileStorage) Limits() Limits {
+	return fs.limits
+}
+
+// Usage reports the backups in the vault, the bytes it takes on disk and
+// the free space left beside it
+func (fs *FileStorage) Usage() *Usage {
+	usage := &Usage{
+		NFTs:         len(fs.index.List()),
+		Bytes:        dirSize(fs.baseDir),
+		MaxNFTs:      fs.limits.MaxNFTs,
+		MaxBytes:     fs.limits.MaxBytes,
+		MinFreeBytes: fs.limits.MinFreeBytes,
+		Wallets:      fs.limits.Wallets,
+	}
+	if free, err := fsutil.FreeSpace(fs.baseDir); err == nil {
+		usage.FreeBytes = free
+	}
+	return usage
+}
+
+// CheckQuota returns ErrQuotaExceeded once the vault uses its MaxBytes, and
+// ErrLowDiskSpace once its disk is down to MinFreeBytes, for writes that do
+// not go through SaveNFT (proof chains, for one)
+func (fs *FileStorage) CheckQuota() error {
+	if err := fs.CheckFreeSpace(); err != nil {
+		return err
+	}
+	if fs.limits.MaxBytes == 0 {
+		return nil
+	}
+	if used := dirSize(fs.baseDir); used >= fs.limits.MaxBytes {
+		return fmt.Errorf("%w: %d of %d bytes", ErrQuotaExceeded, used, fs.limits.MaxBytes)
+	}
+	return nil
+}
+
+// CheckFreeSpace returns ErrLowDiskSpace once the vault's disk has no more
+// than MinFreeBytes free. Platforms that cannot report free space always
+// pass.
+func (fs *FileStorage) CheckFreeSpace() error {
+	if fs.limits.MinFreeBytes == 0 {
+		return nil
+	}
+	free, err := fsutil.FreeSpace(fs.baseDir)
+	if err != nil {
+		return nil
+	}
+	if free <= fs.limits.MinFreeBytes {
+		return fmt.Errorf("%w: %d bytes left, keeping %d free", ErrLowDiskSpace, free, fs.limits.MinFreeBytes)
+	}
+	return nil
+}
+
+// checkLimits refuses a save for wallet that the vault's limits do not
+// allow; isNew is set when the save adds a backup rather than updating one
+//
+// Explanation: Updates to existing backups are let through a full vault, so
+// reaching the quota never leaves a tenant with half-synced records. Low
+// disk space is not let through: a full disk corrupts more than it saves.
+func (fs *FileStorage) checkLimits(wallet string, isNew bool) error {
+	if !fs.limits.AllowsWallet(wallet) {
+		return fmt.Errorf("%w: %s", ErrWalletNotAllowed, wallet)
+	}
+	if !isNew {
+		return fs.CheckFreeSpace()
+	}
+	if count := len(fs.index.List()); fs.limits.MaxNFTs > 0 && count >= fs.limits.MaxNFTs {
+		return fmt.Errorf("%w: %d of %d NFTs", ErrQuotaExceeded, count, fs.limits.MaxNFTs)
+	}
+	return fs.CheckQuota()
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/fsutil"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestTenants(t *testing.T) {
+	baseDir := t.TempDir()
+	tenants, err := LoadTenants(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to load tenants: %v", err)
+	}
+
+	alice, aliceKey, err := tenants.Add("alice", Limits{MaxNFTs: 10})
+	if err != nil {
+		t.Fatalf("Failed to add tenant: %v", err)
+	}
+	if _, bobKey, err := tenants.Add("bob", Limits{}); err != nil || bobKey == aliceKey {
+		t.Fatalf("Failed to add a second tenant with its own key: %v", err)
+	}
+	for _, id := range []string{"alice", "Bob", "../escape", ""} {
+		if _, _, err := tenants.Add(id, Limits{}); err == nil {
+			t.Errorf("Expected tenant ID %q to be refused", id)
+		}
+	}
+	if err := tenants.Save(); err != nil {
+		t.Fatalf("Failed to save tenants: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, TenantsFile))
+	if err != nil {
+		t.Fatalf("Failed to read tenants: %v", err)
+	}
+	if bytes.Contains(data, []byte(aliceKey)) {
+		t.Error("Expected only a hash of the API key to be stored")
+	}
+
+	reloaded, err := LoadTenants(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to reload tenants: %v", err)
+	}
+	if got := reloaded.Authenticate(aliceKey); got == nil || got.ID != alice.ID || got.Limits.MaxNFTs != 10 {
+		t.Errorf("Expected the key to authenticate alice, got %+v", got)
+	}
+	for _, key := range []string{"", "svk_wrong", aliceKey + "x"} {
+		if got := reloaded.Authenticate(key); got != nil {
+			t.Errorf("Expected key %q to be rejected, got %s", key, got.ID)
+		}
+	}
+
+	if err := reloaded.Remove("alice"); err != nil {
+		t.Fatalf("Failed to remove tenant: %v", err)
+	}
+	if reloaded.Authenticate(aliceKey) != nil {
+		t.Error("Expected a removed tenant's key to be rejected")
+	}
+	if err := reloaded.Remove("alice"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Expected ErrUnknownTenant, got %v", err)
+	}
+}
+
+func TestFileStorage_Limits(t *testing.T) {
+	ctx := context.Background()
+	allowed, other := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	tests := []struct {
+		name   string
+		limits Limits
+		owner  solanago.PublicKey
+		want   error
+	}{
+		{"unlimited", Limits{}, other, nil},
+		{"allowed wallet", Limits{Wallets: []string{allowed.String()}}, allowed, nil},
+		{"other wallet", Limits{Wallets: []string{allowed.String()}}, other, ErrWalletNotAllowed},
+		{"NFT quota reached", Limits{MaxNFTs: 1}, allowed, ErrQuotaExceeded},
+		{"byte quota reached", Limits{MaxBytes: 1}, allowed, ErrQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			existing := custodyNFT(solanago.NewWallet().PublicKey(), allowed)
+			if err := store.SaveNFT(ctx, existing); err != nil {
+				t.Fatalf("Failed to save NFT: %v", err)
+			}
+			store.SetLimits(tt.limits)
+
+			err = store.SaveNFT(ctx, custodyNFT(solanago.NewWallet().PublicKey(), tt.owner))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+
+			// Backups already in the vault can always be updated
+			if err := store.SaveNFT(ctx, existing); err != nil {
+				t.Errorf("Expected an update to go through, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileStorage_LowDiskSpace(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if _, err := fsutil.FreeSpace(store.BaseDir()); err != nil {
+		t.Skipf("Free space is not available here: %v", err)
+	}
+	owner := solanago.NewWallet().PublicKey()
+	existing := custodyNFT(solanago.NewWallet().PublicKey(), owner)
+	if err := store.SaveNFT(ctx, existing); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+
+	// No disk has this much to spare
+	store.SetLimits(Limits{MinFreeBytes: math.MaxInt64})
+	if err := store.SaveNFT(ctx, custodyNFT(solanago.NewWallet().PublicKey(), owner)); !errors.Is(err, ErrLowDiskSpace) {
+		t.Errorf("Expected a new backup to be refused, got %v", err)
+	}
+	if err := store.SaveNFT(ctx, existing); !errors.Is(err, ErrLowDiskSpace) {
+		t.Errorf("Expected an update to be refused too, got %v", err)
+	}
+	if usage := store.Usage(); usage.FreeBytes == 0 || usage.MinFreeBytes != math.MaxInt64 {
+		t.Errorf("Expected free space in the usage, got %+v", usage)
+	}
+
+	store.SetLimits(Limits{MinFreeBytes: 1})
+	if err := store.CheckQuota(); err != nil {
+		t.Errorf("Expected a byte of headroom to be available, got %v", err)
+	}
+}
+
+func TestOpenTenantVault(t *testing.T) {
+	baseDir := t.TempDir()
+	tenant := &Tenant{ID: "alice", Limits: Limits{MaxNFTs: 1}}
+
+	vault, err := OpenTenantVault(baseDir, tenant)
+	if err != nil {
+		t.Fatalf("Failed to open tenant vault: %v", err)
+	}
+	if vault.BaseDir() != TenantDir(baseDir, "alice") {
+		t.Errorf("Expected the vault under %s, got %s", TenantDir(baseDir, "alice"), vault.BaseDir())
+	}
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey())
+	if err := vault.SaveNFT(context.Background(), nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if usage := vault.Usage(); usage.NFTs != 1 || usage.MaxNFTs != 1 || usage.Bytes == 0 {
+		t.Errorf("Expected one NFT of a quota of one, got %+v", usage)
+	}
+
+	shared, err := NewFileStorage(baseDir)
+	if err != nil {
+		t.Fatalf("Failed to open shared vault: %v", err)
+	}
+	if entries := shared.Index().ByMint(nft.MintAddress.String()); len(entries) != 0 {
+		t.Error("Expected the tenant's backup to stay out of the shared vault")
+	}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/telemetry"
+	solanago "github.com/gagliardetto/solana-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TokensDir holds backups of fungible tokens and SFTs under the vault root,
+// as tokens/<wallet>/<mint>, apart from the NFT backups and the index
+const TokensDir = "tokens"
+
+// TokenDataFile is the record of a token backup, nft_data.json's
+// counterpart
+const TokenDataFile = "token_data.json"
+
+// StoredToken is a fungible token or SFT as stored on disk
+type StoredToken struct {
+	TokenInfo *fetcher.NFTInfo      `json:"token_info"`
+	Standard  fetcher.TokenStandard `json:"standard"`
+	Amount    uint64                `json:"amount"` // Balance at the last sync, in the smallest unit
+
+	StoredAt  time.Time `json:"stored_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TokenDir returns the directory of a token backup
+func (fs *FileStorage) TokenDir(walletAddr, mintAddr solanago.PublicKey) string {
+	return filepath.Join(fs.baseDir, TokensDir, walletAddr.String(), mintAddr.String())
+}
+
+// SaveToken writes a token backup's record, keeping when it was first
+// stored, and records its files in integrity.json. Media is downloaded into
+// the directory's media/ folder beforehand.
+func (fs *FileStorage) SaveToken(ctx context.Context, token *StoredToken) (err error) {
+	info := token.TokenInfo
+	ctx, span := telemetry.Start(ctx, "storage save token",
+		attribute.String("solvault.wallet", info.Owner.String()),
+		attribute.String("solvault.mint", info.MintAddress.String()))
+	defer func() { telemetry.End(span, err) }()
+
+	dir := fs.TokenDir(info.Owner, info.MintAddress)
+	// Tokens count towards the byte quota, but not towards MaxNFTs
+	if err := fs.checkLimits(info.Owner.String(), false); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, TokenDataFile)); os.IsNotExist(err) {
+		if err := fs.CheckQuota(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+
+	now := time.Now()
+	token.StoredAt, token.UpdatedAt = now, now
+	if previous, err := fs.GetToken(ctx, info.Owner, info.MintAddress); err == nil {
+		token.StoredAt = previous.StoredAt
+	}
+	if err := fs.saveJSON(filepath.Join(dir, TokenDataFile), token); err != nil {
+		return fmt.Errorf("failed to save token data: %w", err)
+	}
+	if err := fs.packMedia(dir); err != nil {
+		return err
+	}
+	if _, err := fs.WriteIntegrity(dir); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetToken loads a token backup; the error wraps os.ErrNotExist when the
+// wallet's token has none
+func (fs *FileStorage) GetToken(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (*StoredToken, error) {
+	var token StoredToken
+	if err := fs.loadJSON(filepath.Join(fs.TokenDir(walletAddr, mintAddr), TokenDataFile), &token); err != nil {
+		return nil, fmt.Errorf("failed to load token %s: %w", mintAddr.String(), err)
+	}
+	return &token, nil
+}
+
+// ListTokens returns the token backups of a wallet, by mint
+func (fs *FileStorage) ListTokens(ctx context.Context, walletAddr solanago.PublicKey) ([]*StoredToken, error) {
+	paths, err := filepath.Glob(filepath.Join(fs.baseDir, TokensDir, walletAddr.String(), "*", TokenDataFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list token backups: %w", err)
+	}
+	sort.Strings(paths)
+
+	tokens := []*StoredToken{}
+	for _, path := range paths {
+		var token StoredToken
+		if err := fs.loadJSON(path, &token); err != nil {
+			fmt.Printf("⚠️  Warning: failed to load %s: %v\n", path, err)
+			continue
+		}
+		tokens = append(tokens, &token)
+	}
+	return tokens, nil
+}
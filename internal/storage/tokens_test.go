@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Tokens(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, mint := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+	token := &StoredToken{
+		TokenInfo: &fetcher.NFTInfo{MintAddress: mint, Owner: wallet, Decimals: 6, Metadata: &fetcher.NFTMetadata{Name: "Coin"}},
+		Standard:  fetcher.TokenFungible,
+		Amount:    42,
+	}
+	if err := store.SaveToken(ctx, token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	first := token.StoredAt
+
+	token.Amount = 7
+	if err := store.SaveToken(ctx, token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	stored, err := store.GetToken(ctx, wallet, mint)
+	if err != nil {
+		t.Fatalf("Failed to load token: %v", err)
+	}
+	if stored.Amount != 7 || !stored.StoredAt.Equal(first) {
+		t.Errorf("Expected the new balance with the first save time, got %+v", stored)
+	}
+	if _, err := os.Stat(filepath.Join(store.TokenDir(wallet, mint), IntegrityFile)); err != nil {
+		t.Errorf("Expected an integrity manifest: %v", err)
+	}
+	if nfts, err := store.ListNFTs(ctx, wallet); err != nil || len(nfts) != 0 {
+		t.Errorf("Expected tokens kept apart from NFTs, got %d NFT(s) (err %v)", len(nfts), err)
+	}
+
+	// Encrypting the vault covers token backups too
+	params, c, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	if _, err := store.EnableEncryption(params, c); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(store.TokenDir(wallet, mint), TokenDataFile))
+	if err != nil {
+		t.Fatalf("Failed to read token data: %v", err)
+	}
+	if !crypt.IsSealed(data) {
+		t.Errorf("Expected %s encrypted", TokenDataFile)
+	}
+	if tokens, err := store.ListTokens(ctx, wallet); err != nil || len(tokens) != 1 || tokens[0].Amount != 7 {
+		t.Errorf("Expected the encrypted token readable, got %+v (err %v)", tokens, err)
+	}
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// TraitFilter selects backups by the traits in their metadata
+type TraitFilter struct {
+	Traits     map[string]string // Trait type to value, all required; compared case-insensitively
+	Collection string            // Collection name or symbol; "" matches any
+	Wallet     string            // "" matches every wallet
+}
+
+// ParseTrait reads a "Trait=Value" criterion such as "Hat=Crown"
+func ParseTrait(criterion string) (string, string, error) {
+	trait, value, ok := strings.Cut(criterion, "=")
+	trait, value = strings.TrimSpace(trait), strings.TrimSpace(value)
+	if !ok || trait == "" || value == "" {
+		return "", "", fmt.Errorf("invalid trait %q: expected Trait=Value, e.g. Hat=Crown", criterion)
+	}
+	return trait, value, nil
+}
+
+// IndexAttributes returns the trait values of metadata as kept in the index,
+// or nil when it has none
+func IndexAttributes(md *fetcher.NFTMetadata) map[string]string {
+	if md == nil || len(md.Attributes) == 0 {
+		return nil
+	}
+	attributes := make(map[string]string, len(md.Attributes))
+	for _, attr := range md.Attributes {
+		if trait := strings.TrimSpace(attr.TraitType); trait != "" && attr.Value != nil {
+			attributes[trait] = fmt.Sprint(attr.Value)
+		}
+	}
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+// Matches reports whether entry meets every criterion of the filter
+func (f TraitFilter) Matches(entry *IndexEntry) bool {
+	if f.Wallet != "" && entry.Wallet != f.Wallet {
+		return false
+	}
+	if f.Collection != "" && !strings.EqualFold(entry.Collection, f.Collection) && !strings.EqualFold(entry.Symbol, f.Collection) {
+		return false
+	}
+	for trait, value := range f.Traits {
+		if !strings.EqualFold(TraitValue(entry, trait), value) {
+			return false
+		}
+	}
+	return true
+}
+
+// TraitValue returns entry's value for trait, matching the trait type
+// case-insensitively, or "" when it does not have the trait
+func TraitValue(entry *IndexEntry, trait string) string {
+	if value, ok := entry.Attributes[trait]; ok {
+		return value
+	}
+	for name, value := range entry.Attributes {
+		if strings.EqualFold(name, trait) {
+			return value
+		}
+	}
+	return ""
+}
+
+// SearchTraits returns the backups matching filter, one entry per backup
+// when several wallets are linked to it
+func (idx *Index) SearchTraits(filter TraitFilter) []*IndexEntry {
+	var matches []*IndexEntry
+	seen := make(map[string]bool)
+	for _, entry := range idx.List() {
+		if !filter.Matches(entry) {
+			continue
+		}
+		stored := indexKey(entry.StorageWallet(), entry.DirName)
+		if !seen[stored] {
+			seen[stored] = true
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// setAttributes records the traits of an indexed backup
+func (idx *Index) setAttributes(wallet, mint string, attributes map[string]string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if entry := idx.Entries[indexKey(wallet, mint)]; entry != nil {
+		entry.Attributes = attributes
+	}
+}
+
+// IndexTraits adds the traits of backups indexed before traits were, read
+// from their nft_data.json, and returns how many it added
+//
+// Explanation: This runs before every trait search rather than as a one-off
+// migration, so it also covers backups restored or imported since. Once a
+// backup's traits are indexed it is not read again; only backups whose
+// metadata has no traits at all are re-read, and there are few of those.
+func (fs *FileStorage) IndexTraits(ctx context.Context) (int, error) {
+	added := 0
+	for _, entry := range fs.index.List() {
+		if entry.Attributes != nil || entry.InProgress {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return added, err
+		}
+		wallet, err := solanago.PublicKeyFromBase58(entry.Wallet)
+		if err != nil {
+			continue
+		}
+		mint, err := solanago.PublicKeyFromBase58(entry.Mint)
+		if err != nil {
+			continue
+		}
+		stored, err := fs.GetNFT(ctx, wallet, mint)
+		if err != nil || stored.NFTInfo == nil {
+			continue
+		}
+		if attributes := IndexAttributes(stored.NFTInfo.Metadata); attributes != nil {
+			fs.index.setAttributes(entry.Wallet, entry.Mint, attributes)
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	if err := fs.index.Save(); err != nil {
+		return added, fmt.Errorf("failed to save index: %w", err)
+	}
+	return added, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestSearchTraits(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet, other := solanago.NewWallet().PublicKey(), solanago.NewWallet().PublicKey()
+
+	save := func(owner solanago.PublicKey, name, collection string, traits map[string]interface{}) solanago.PublicKey {
+		mint := solanago.NewWallet().PublicKey()
+		md := &fetcher.NFTMetadata{Name: name, Symbol: "DGOD", Collection: fetcher.Collection{Name: collection}}
+		for trait, value := range traits {
+			md.Attributes = append(md.Attributes, fetcher.Attribute{TraitType: trait, Value: value})
+		}
+		if err := store.SaveNFT(ctx, &fetcher.NFTInfo{MintAddress: mint, Owner: owner, FetchedAt: time.Now(), Metadata: md}); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+		return mint
+	}
+	crowned := save(wallet, "DeGod #1", "DeGods", map[string]interface{}{"Hat": "Crown", "Eyes": "Laser"})
+	save(wallet, "DeGod #2", "DeGods", map[string]interface{}{"Hat": "Cap", "Eyes": "Laser"})
+	save(other, "DeGod #3", "DeGods", map[string]interface{}{"Hat": "Crown", "Level": 3})
+	save(wallet, "Cat #1", "Cats", map[string]interface{}{"Hat": "Crown"})
+
+	tests := []struct {
+		name   string
+		filter TraitFilter
+		want   []string
+	}{
+		{"one trait across collections", TraitFilter{Traits: map[string]string{"Hat": "Crown"}}, []string{"Cat #1", "DeGod #1", "DeGod #3"}},
+		{"case-insensitive with collection", TraitFilter{Traits: map[string]string{"hat": "CROWN"}, Collection: "degods"}, []string{"DeGod #1", "DeGod #3"}},
+		{"every trait required", TraitFilter{Traits: map[string]string{"Hat": "Crown", "Eyes": "Laser"}}, []string{"DeGod #1"}},
+		{"numeric value", TraitFilter{Traits: map[string]string{"Level": "3"}}, []string{"DeGod #3"}},
+		{"collection symbol, one wallet", TraitFilter{Collection: "DGOD", Wallet: other.String()}, []string{"DeGod #3"}},
+		{"no match", TraitFilter{Traits: map[string]string{"Hat": "Halo"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, entry := range store.Index().SearchTraits(tt.filter) {
+				got = append(got, entry.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Got %v, want %v", got, tt.want)
+			}
+			found := make(map[string]bool)
+			for _, name := range got {
+				found[name] = true
+			}
+			for _, name := range tt.want {
+				if !found[name] {
+					t.Errorf("Got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Backups indexed before traits were are filled in from nft_data.json
+	store.index.setAttributes(wallet.String(), crowned.String(), nil)
+	added, err := store.IndexTraits(ctx)
+	if err != nil {
+		t.Fatalf("Failed to index traits: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("Expected the traits of 1 backup added, got %d", added)
+	}
+	if entry := store.Index().Get(wallet.String(), crowned.String()); TraitValue(entry, "hat") != "Crown" {
+		t.Errorf("Expected the backfilled Hat trait, got %v", entry.Attributes)
+	}
+	reloaded, err := LoadIndex(store.BaseDir())
+	if err != nil {
+		t.Fatalf("Failed to reload index: %v", err)
+	}
+	if len(reloaded.Get(wallet.String(), crowned.String()).Attributes) != 2 {
+		t.Errorf("Expected the backfilled traits saved with the index")
+	}
+}
+
+func TestParseTrait(t *testing.T) {
+	tests := []struct {
+		criterion, trait, value string
+		wantErr                 bool
+	}{
+		{criterion: "Hat=Crown", trait: "Hat", value: "Crown"},
+		{criterion: " Background = Deep Blue ", trait: "Background", value: "Deep Blue"},
+		{criterion: "Formula=a=b", trait: "Formula", value: "a=b"},
+		{criterion: "Hat", wantErr: true},
+		{criterion: "=Crown", wantErr: true},
+		{criterion: "Hat=", wantErr: true},
+	}
+	for _, tt := range tests {
+		trait, value, err := ParseTrait(tt.criterion)
+		if (err != nil) != tt.wantErr || trait != tt.trait || value != tt.value {
+			t.Errorf("ParseTrait(%q) = %q, %q, %v", tt.criterion, trait, value, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+)
+
+// Staged files sit next to the live ones until commit
+const (
+	stagedSuffix  = ".staged"
+	journalName   = ".commit.json"
+	stagedJournal = journalName + stagedSuffix
+)
+
+// StagingDirName is the directory at the vault root where new backups are
+// built before they are moved into place
+const StagingDirName = ".staging"
+
+// fileTransaction stages the files of one NFT save inside its directory
+//
+// Explanation: Each file is written as .<name>.staged first. Commit writes a
+// journal listing the staged files, renames them over the live copies and
+// then deletes the journal. Renames are atomic per file, and if the process
+// dies part way through, the journal lets the next save or index rebuild
+// finish the job. A save that dies before the journal is written leaves the
+// previous backup untouched.
+//
+// The first save of an NFT has no previous backup to protect, so it is built
+// whole in the staging area and its directory renamed into place on commit:
+// until then nothing under wallets/ shows a half-written backup.
+type fileTransaction struct {
+	ctx    context.Context
+	fs     *FileStorage
+	dir    string
+	target string // Final directory when dir is in the staging area
+	entry  *IndexEntry
+	info   *fetcher.NFTInfo // What is being saved, for replicas
+	staged []string         // Live file names with a staged copy
+	done   bool
+}
+
+// stage writes data as the pending version of name
+func (t *fileTransaction) stage(name string, data interface{}) error {
+	if err := t.fs.saveJSON(stagedPath(t.dir, name), data); err != nil {
+		return err
+	}
+	t.staged = append(t.staged, name)
+	return nil
+}
+
+// stageRaw writes data byte for byte as the pending version of name
+func (t *fileTransaction) stageRaw(name string, data []byte) error {
+	path := stagedPath(t.dir, name)
+	encoded, err := t.fs.encode(path, data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, encoded, t.fs.permissions); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	t.staged = append(t.staged, name)
+	return nil
+}
+
+// Commit publishes the staged files and records the backup in the index
+func (t *fileTransaction) Commit() error {
+	if t.done {
+		return errors.New("transaction already finished")
+	}
+
+	// The journal is the commit point: once it exists the save rolls forward
+	journal, err := json.Marshal(t.staged)
+	if err != nil {
+		return fmt.Errorf("failed to marshal commit journal: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, stagedJournal), journal, t.fs.permissions); err != nil {
+		return fmt.Errorf("failed to write commit journal: %w", err)
+	}
+	if err := os.Rename(filepath.Join(t.dir, stagedJournal), filepath.Join(t.dir, journalName)); err != nil {
+		return fmt.Errorf("failed to write commit journal: %w", err)
+	}
+	t.done = true
+
+	if err := applyJournal(t.dir, t.staged); err != nil {
+		return err
+	}
+	if t.target != "" {
+		if err := os.MkdirAll(filepath.Dir(t.target), 0755); err != nil {
+			return fmt.Errorf("failed to create wallet directory: %w", err)
+		}
+		if err := os.Rename(t.dir, t.target); err != nil {
+			return fmt.Errorf("failed to move new backup into place: %w", err)
+		}
+		t.dir = t.target
+	}
+
+	// Record the directory mapping last so the index never points at a
+	// backup that failed to write
+	t.entry.Status = StatusHeld
+	t.fs.index.Put(t.entry)
+	if err := t.fs.index.Save(); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	return t.fs.replicate(t.ctx, t.info, t.dir)
+}
+
+// Rollback removes the staged files. It does nothing once committed.
+func (t *fileTransaction) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	if t.target != "" {
+		if err := os.RemoveAll(t.dir); err != nil {
+			return fmt.Errorf("failed to remove staged backup: %w", err)
+		}
+		return nil
+	}
+
+	var firstErr error
+	for _, name := range t.staged {
+		if err := os.Remove(stagedPath(t.dir, name)); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove staged %s: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+// applyJournal moves each staged file over its live copy, then clears the
+// journal. Files already moved by an earlier attempt are skipped.
+func applyJournal(dir string, names []string) error {
+	for _, name := range names {
+		err := os.Rename(stagedPath(dir, name), filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to commit %s: %w", name, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, journalName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear commit journal: %w", err)
+	}
+	return nil
+}
+
+// recoverTransaction finishes a save that was interrupted after its commit
+// point and discards one interrupted before it
+func recoverTransaction(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, journalName))
+	if err == nil {
+		var names []string
+		if err := json.Unmarshal(data, &names); err != nil {
+			return fmt.Errorf("failed to read commit journal in %s: %w", dir, err)
+		}
+		return applyJournal(dir, names)
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read commit journal in %s: %w", dir, err)
+	}
+
+	// No journal: anything staged belongs to a save that never committed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), stagedSuffix) {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to discard staged file %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// stagedPath is where the pending version of name is written
+func stagedPath(dir, name string) string {
+	return filepath.Join(dir, "."+name+stagedSuffix)
+}
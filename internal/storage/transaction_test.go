@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_Transaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		finish      func(txn Transaction) error
+		wantName    string
+		wantVersion int
+	}{
+		{"commit publishes every file", func(txn Transaction) error { return txn.Commit() }, "Revealed", 2},
+		{"rollback keeps the previous backup", func(txn Transaction) error { return txn.Rollback() }, "Shared NFT", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewFileStorage(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			ctx := context.Background()
+			wallet := solanago.NewWallet().PublicKey()
+			nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+			if err := store.SaveNFT(ctx, nft); err != nil {
+				t.Fatalf("Failed to save NFT: %v", err)
+			}
+
+			nft.Metadata.Name = "Revealed"
+			txn, err := store.BeginSave(ctx, nft)
+			if err != nil {
+				t.Fatalf("Failed to begin save: %v", err)
+			}
+
+			// Staged changes stay invisible until commit
+			stored, err := store.GetNFT(ctx, wallet, nft.MintAddress)
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			if stored.NFTInfo.Metadata.Name != "Shared NFT" {
+				t.Fatalf("Staged save leaked into the live backup")
+			}
+
+			if err := tt.finish(txn); err != nil {
+				t.Fatalf("Failed to finish transaction: %v", err)
+			}
+			if err := txn.Rollback(); err != nil {
+				t.Fatalf("Rollback after finishing should be a no-op: %v", err)
+			}
+
+			stored, err = store.GetNFT(ctx, wallet, nft.MintAddress)
+			if err != nil {
+				t.Fatalf("Failed to get NFT: %v", err)
+			}
+			if stored.NFTInfo.Metadata.Name != tt.wantName || stored.Version != tt.wantVersion {
+				t.Errorf("Got %q v%d, want %q v%d", stored.NFTInfo.Metadata.Name, stored.Version, tt.wantName, tt.wantVersion)
+			}
+
+			var metadata struct{ Name string }
+			if err := store.loadJSON(filepath.Join(store.NFTDir(wallet, nft.MintAddress), "metadata.json"), &metadata); err != nil {
+				t.Fatalf("Failed to read metadata.json: %v", err)
+			}
+			if metadata.Name != tt.wantName {
+				t.Errorf("metadata.json has %q, want %q", metadata.Name, tt.wantName)
+			}
+
+			leftovers, _ := filepath.Glob(filepath.Join(store.NFTDir(wallet, nft.MintAddress), ".*"))
+			if len(leftovers) != 0 {
+				t.Errorf("Transaction left files behind: %v", leftovers)
+			}
+		})
+	}
+}
+
+func TestRecoverTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		journal    bool
+		wantStaged bool // Whether the staged content ends up live
+	}{
+		{"journal rolls forward", true, true},
+		{"no journal discards staged files", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			live := filepath.Join(dir, "nft_data.json")
+			if err := os.WriteFile(live, []byte("old"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			if err := os.WriteFile(stagedPath(dir, "nft_data.json"), []byte("new"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			if tt.journal {
+				if err := os.WriteFile(filepath.Join(dir, journalName), []byte(`["nft_data.json"]`), 0644); err != nil {
+					t.Fatalf("Failed to write journal: %v", err)
+				}
+			}
+
+			if err := recoverTransaction(dir); err != nil {
+				t.Fatalf("Failed to recover: %v", err)
+			}
+
+			data, err := os.ReadFile(live)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			want := "old"
+			if tt.wantStaged {
+				want = "new"
+			}
+			if string(data) != want {
+				t.Errorf("Live file has %q, want %q", data, want)
+			}
+			leftovers, _ := filepath.Glob(filepath.Join(dir, ".*"))
+			if len(leftovers) != 0 {
+				t.Errorf("Recovery left files behind: %v", leftovers)
+			}
+		})
+	}
+}
+
+func TestFileStorage_FirstSaveIsStaged(t *testing.T) {
+	tests := []struct {
+		name     string
+		finish   func(txn Transaction) error
+		wantLive bool
+	}{
+		{"commit moves the backup into place", func(txn Transaction) error { return txn.Commit() }, true},
+		{"rollback leaves no directory", func(txn Transaction) error { return txn.Rollback() }, false},
+		{"interrupted save leaves no directory", func(txn Transaction) error { return nil }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			store, err := NewFileStorage(base)
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			ctx := context.Background()
+			wallet := solanago.NewWallet().PublicKey()
+			nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+
+			txn, err := store.BeginSave(ctx, nft)
+			if err != nil {
+				t.Fatalf("Failed to begin save: %v", err)
+			}
+			nftDir := store.NFTDir(wallet, nft.MintAddress)
+			if _, err := os.Stat(nftDir); !os.IsNotExist(err) {
+				t.Fatalf("A staged first save must not create %s", nftDir)
+			}
+
+			if err := tt.finish(txn); err != nil {
+				t.Fatalf("Failed to finish transaction: %v", err)
+			}
+
+			_, err = os.Stat(filepath.Join(nftDir, "nft_data.json"))
+			if live := err == nil; live != tt.wantLive {
+				t.Errorf("Backup live = %v, want %v", live, tt.wantLive)
+			}
+			if indexed := store.Index().Get(wallet.String(), nft.MintAddress.String()) != nil; indexed != tt.wantLive {
+				t.Errorf("Backup indexed = %v, want %v", indexed, tt.wantLive)
+			}
+
+			// The next save starts over, and prune clears what an interrupted one left
+			report, err := store.Prune(ctx, PruneOptions{})
+			if err != nil {
+				t.Fatalf("Failed to prune: %v", err)
+			}
+			staged, _ := filepath.Glob(filepath.Join(base, StagingDirName, "*", "*"))
+			if len(staged) != 0 {
+				t.Errorf("Staged backups left after prune: %v (report %+v)", staged, report.Items)
+			}
+			if err := store.SaveNFT(ctx, nft); err != nil {
+				t.Fatalf("Failed to save NFT after %s: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestFileStorage_InProgress(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	inProgress := func() bool {
+		return store.Index().Get(wallet.String(), nft.MintAddress.String()).InProgress
+	}
+
+	if err := store.SaveNFTInProgress(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if !inProgress() {
+		t.Fatalf("Expected the backup to be marked in progress")
+	}
+
+	// A rebuilt index cannot tell from the files, so it keeps the mark
+	if _, err := store.RebuildIndex(ctx); err != nil {
+		t.Fatalf("Failed to rebuild index: %v", err)
+	}
+	if !inProgress() {
+		t.Errorf("Rebuilding the index lost the in-progress mark")
+	}
+
+	if err := store.CompleteBackup(wallet, nft.MintAddress); err != nil {
+		t.Fatalf("Failed to complete backup: %v", err)
+	}
+	if inProgress() {
+		t.Errorf("Expected CompleteBackup to clear the mark")
+	}
+
+	if err := store.SaveNFTInProgress(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if err := store.SaveNFT(ctx, nft); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	if inProgress() {
+		t.Errorf("Expected SaveNFT to record a complete backup")
+	}
+}
@@ -14,6 +14,10 @@ type StorageBackend interface {
 	// SaveNFT stores NFT information with metadata
 	SaveNFT(ctx context.Context, nftInfo *fetcher.NFTInfo) error
 
+	// BeginSave stages an NFT save; its files appear together on Commit
+	// or not at all
+	BeginSave(ctx context.Context, nftInfo *fetcher.NFTInfo) (Transaction, error)
+
 	// GetNFT retrieves stored NFT information
 	GetNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) (*StoredNFT, error)
 
@@ -23,28 +27,110 @@ type StorageBackend interface {
 	// DeleteNFT removes stored NFT data
 	DeleteNFT(ctx context.Context, walletAddr, mintAddr solanago.PublicKey) error
 
+	// Capabilities reports what the backend supports natively
+	Capabilities() Capabilities
+
 	// Close cleans up storage resources
 	Close() error
 }
 
+// Transaction is a staged multi-file save. Exactly one of Commit or Rollback
+// should be called; Rollback after a successful Commit is a no-op, so it can
+// be deferred.
+type Transaction interface {
+	// Commit publishes every staged file and updates the index
+	Commit() error
+
+	// Rollback discards the staged files, leaving the previous backup intact
+	Rollback() error
+}
+
+// Capabilities describes the native features of a storage backend so higher
+// layers (versioning, sync, scrub) can adapt instead of assuming filesystem
+// semantics
+type Capabilities struct {
+	Versioning         bool  `json:"versioning"`           // Backend keeps prior versions of overwritten objects
+	Locking            bool  `json:"locking"`              // Backend can lock objects against concurrent writers
+	ServerSideChecksum bool  `json:"server_side_checksum"` // Backend verifies object checksums itself
+	MaxObjectSize      int64 `json:"max_object_size"`      // Largest single object in bytes, 0 means unlimited
+}
+
+// Fits reports whether an object of size bytes is within MaxObjectSize
+func (c Capabilities) Fits(size int64) bool {
+	return c.MaxObjectSize <= 0 || size <= c.MaxObjectSize
+}
+
 // StoredNFT represents NFT data as stored on disk
 // This includes the original fetched data plus storage metadata
 type StoredNFT struct {
 	// Original NFT information
 	NFTInfo *fetcher.NFTInfo `json:"nft_info"`
 
+	// Layout of this record; see RecordMigrations
+	SchemaVersion int `json:"schema_version"`
+
 	// Storage metadata
 	StoredAt  time.Time `json:"stored_at"`  // When this was saved
 	UpdatedAt time.Time `json:"updated_at"` // Last update time
-	Version   int       `json:"version"`    // Data version for migrations
+	Version   int       `json:"version"`    // Metadata version, bumped when the URI or off-chain JSON changes
 	Checksum  string    `json:"checksum"`   // Data integrity check
 
 	// Backup metadata
 	BackupPath string    `json:"backup_path"` // Path to image/media backup
 	Verified   bool      `json:"verified"`    // Has been verified against blockchain
 	LastCheck  time.Time `json:"last_check"`  // Last verification check
+
+	// Holding status, maintained by sync
+	Status          HoldingStatus `json:"status,omitempty"`            // Empty means held (older backups)
+	StatusChangedAt time.Time     `json:"status_changed_at,omitempty"` // When Status last changed
+
+	// Custody lists every vault wallet that has held this NFT, oldest first
+	Custody []CustodyPeriod `json:"custody,omitempty"`
+
+	// Versions lists every metadata version backed up, oldest first
+	Versions []MetadataVersion `json:"versions,omitempty"`
+
+	// Remote records the copies uploaded to each cloud mirror, keyed by
+	// provider name (see solvault mirror)
+	Remote map[string]*RemoteCopy `json:"remote,omitempty"`
+
+	// Rarity ranks the NFT among the vault's backups of its collection,
+	// once enough of them are backed up (see analytics.RecordRarity)
+	Rarity *Rarity `json:"rarity,omitempty"`
+
+	// MediaPrunedAt is when prune removed the media of an NFT that left the
+	// vault's wallets, under the transferred-media retention policy
+	MediaPrunedAt *time.Time `json:"media_pruned_at,omitempty"`
+}
+
+// CustodyPeriod is a span of time during which one vault wallet held an NFT
+type CustodyPeriod struct {
+	Wallet string        `json:"wallet"`
+	From   time.Time     `json:"from"`
+	To     *time.Time    `json:"to,omitempty"` // Nil while the wallet still holds it
+	Status HoldingStatus `json:"status"`       // How the period ended, or held
+
+	// The transaction that ended the period and who received the NFT, when
+	// the watcher could tell
+	Signature string `json:"signature,omitempty"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// Departure is what is known about how an NFT left a wallet
+type Departure struct {
+	Signature string // Outgoing transaction
+	Recipient string // New owner; empty for burns
 }
 
+// HoldingStatus records whether the wallet still holds a backed-up NFT
+type HoldingStatus string
+
+const (
+	StatusHeld        HoldingStatus = "held"
+	StatusTransferred HoldingStatus = "transferred" // No longer in the wallet, mint still exists
+	StatusBurned      HoldingStatus = "burned"      // Mint supply is zero or the mint account is gone
+)
+
 // BackupStats provides statistics about stored NFT data
 type BackupStats struct {
 	TotalNFTs       int       `json:"total_nfts"`
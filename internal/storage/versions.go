@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// MetadataVersion records one version of an NFT's metadata
+type MetadataVersion struct {
+	Version     int       `json:"version"`
+	URI         string    `json:"uri"`
+	Fingerprint string    `json:"fingerprint"` // MetadataFingerprint of this version
+	SavedAt     time.Time `json:"saved_at"`    // When this version was first backed up
+}
+
+// MetadataFingerprint identifies an NFT's metadata URI and off-chain JSON.
+// A save whose fingerprint differs from the stored one starts a new version.
+func MetadataFingerprint(info *fetcher.NFTInfo) string {
+	if info == nil {
+		return ""
+	}
+	data, _ := json.Marshal(struct {
+		URI      string               `json:"uri"`
+		Metadata *fetcher.NFTMetadata `json:"metadata"`
+	}{info.MetadataURI, info.Metadata})
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// MetadataSnapshotName is the file holding the metadata of one version
+func MetadataSnapshotName(version int) string {
+	return fmt.Sprintf("metadata.v%d.json", version)
+}
+
+// seedVersions returns the version history of a stored NFT, synthesizing a
+// single entry for backups written before versions were tracked
+func seedVersions(stored *StoredNFT) []MetadataVersion {
+	if len(stored.Versions) > 0 {
+		return stored.Versions
+	}
+	return []MetadataVersion{{
+		Version:     stored.Version,
+		URI:         stored.NFTInfo.MetadataURI,
+		Fingerprint: MetadataFingerprint(stored.NFTInfo),
+		SavedAt:     stored.UpdatedAt,
+	}}
+}
+
+// stageSnapshots keeps the metadata of both sides of a version change.
+//
+// Explanation: metadata.json always holds the current version so existing
+// readers are unaffected. The version being replaced is copied to its own
+// snapshot first (unless an earlier change already did), then the new
+// version gets one too, so every version since tracking began is on disk.
+func (t *fileTransaction) stageSnapshots(previous, current *StoredNFT) error {
+	if previous.NFTInfo.Metadata != nil {
+		name := MetadataSnapshotName(previous.Version)
+		if _, err := os.Stat(filepath.Join(t.dir, name)); os.IsNotExist(err) {
+			if err := t.stage(name, previous.NFTInfo.Metadata); err != nil {
+				return fmt.Errorf("failed to snapshot metadata version %d: %w", previous.Version, err)
+			}
+		}
+	}
+	if current.NFTInfo.Metadata != nil {
+		if err := t.stage(MetadataSnapshotName(current.Version), current.NFTInfo.Metadata); err != nil {
+			return fmt.Errorf("failed to snapshot metadata version %d: %w", current.Version, err)
+		}
+	}
+	return nil
+}
+
+// MetadataAtVersion loads the off-chain metadata an NFT had at version.
+// The current version is read from metadata.json if it has no snapshot yet.
+func (fs *FileStorage) MetadataAtVersion(walletAddr, mintAddr solanago.PublicKey, version int) (*fetcher.NFTMetadata, error) {
+	dir := fs.NFTDir(walletAddr, mintAddr)
+
+	var metadata fetcher.NFTMetadata
+	err := fs.loadJSON(filepath.Join(dir, MetadataSnapshotName(version)), &metadata)
+	if err == nil {
+		return &metadata, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load metadata version %d: %w", version, err)
+	}
+
+	var stored StoredNFT
+	if err := fs.loadJSON(filepath.Join(dir, "nft_data.json"), &stored); err != nil {
+		return nil, fmt.Errorf("failed to load NFT data: %w", err)
+	}
+	if stored.Version != version || stored.NFTInfo == nil || stored.NFTInfo.Metadata == nil {
+		return nil, fmt.Errorf("no snapshot of metadata version %d for %s", version, mintAddr.String())
+	}
+	return stored.NFTInfo.Metadata, nil
+}
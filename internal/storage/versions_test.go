@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func TestFileStorage_MetadataVersions(t *testing.T) {
+	store, err := NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	ctx := context.Background()
+	wallet := solanago.NewWallet().PublicKey()
+	nft := custodyNFT(solanago.NewWallet().PublicKey(), wallet)
+	nft.MetadataURI = "https://example.com/1.json"
+
+	steps := []struct {
+		name          string
+		change        func(info *fetcher.NFTInfo)
+		wantVersion   int
+		wantSnapshots []string
+	}{
+		{"first save", func(info *fetcher.NFTInfo) {}, 1, nil},
+		{"unchanged resave", func(info *fetcher.NFTInfo) { info.Supply = 1 }, 1, nil},
+		{"off-chain JSON changed", func(info *fetcher.NFTInfo) { info.Metadata = &fetcher.NFTMetadata{Name: "Revealed"} }, 2, []string{"metadata.v1.json", "metadata.v2.json"}},
+		{"URI changed", func(info *fetcher.NFTInfo) { info.MetadataURI = "https://example.com/2.json" }, 3, []string{"metadata.v1.json", "metadata.v2.json", "metadata.v3.json"}},
+	}
+
+	for _, step := range steps {
+		step.change(nft)
+		if err := store.SaveNFT(ctx, nft); err != nil {
+			t.Fatalf("%s: Failed to save NFT: %v", step.name, err)
+		}
+
+		stored, err := store.GetNFT(ctx, wallet, nft.MintAddress)
+		if err != nil {
+			t.Fatalf("%s: Failed to get NFT: %v", step.name, err)
+		}
+		if stored.Version != step.wantVersion || len(stored.Versions) != step.wantVersion {
+			t.Errorf("%s: got v%d with %d version(s), want v%d", step.name, stored.Version, len(stored.Versions), step.wantVersion)
+		}
+
+		snapshots, _ := filepath.Glob(filepath.Join(store.NFTDir(wallet, nft.MintAddress), "metadata.v*.json"))
+		if len(snapshots) != len(step.wantSnapshots) {
+			t.Errorf("%s: got snapshots %v, want %v", step.name, snapshots, step.wantSnapshots)
+		}
+		for _, name := range step.wantSnapshots {
+			if _, err := os.Stat(filepath.Join(store.NFTDir(wallet, nft.MintAddress), name)); err != nil {
+				t.Errorf("%s: missing snapshot %s", step.name, name)
+			}
+		}
+	}
+
+	first, err := store.MetadataAtVersion(wallet, nft.MintAddress, 1)
+	if err != nil {
+		t.Fatalf("Failed to load version 1: %v", err)
+	}
+	if first.Name != "Shared NFT" {
+		t.Errorf("Version 1 has name %q, want %q", first.Name, "Shared NFT")
+	}
+	if _, err := store.MetadataAtVersion(wallet, nft.MintAddress, 4); err == nil {
+		t.Errorf("Expected an error for a version that was never saved")
+	}
+}
@@ -0,0 +1,278 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// exportTimeout bounds one batch upload, so a collector that hangs cannot
+// hold up shutdown
+const exportTimeout = 10 * time.Second
+
+// exporter sends spans to an OTLP/HTTP endpoint as a protobuf
+// ExportTraceServiceRequest.
+//
+// Explanation: The official OTLP exporter pulls in grpc-go and the generated
+// OTLP types, which the build otherwise avoids (see internal/server's
+// gRPC). The trace request is a few nested messages, so it is encoded here
+// with protowire; field numbers follow opentelemetry-proto's trace.proto.
+type exporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newExporter(cfg *Config) *exporter {
+	return &exporter{
+		endpoint: cfg.Endpoint,
+		headers:  cfg.Headers,
+		client:   &http.Client{Timeout: exportTimeout},
+	}
+}
+
+// ExportSpans uploads one batch of finished spans
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(encodeSpans(spans)))
+	if err != nil {
+		return fmt.Errorf("failed to create trace export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export %d span(s): %w", len(spans), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to export %d span(s): %s: %s", len(spans), resp.Status, bytes.TrimSpace(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Shutdown releases the exporter's connections
+func (e *exporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// encodeSpans builds an ExportTraceServiceRequest, grouping spans by
+// resource and then by instrumentation scope as OTLP expects
+func encodeSpans(spans []sdktrace.ReadOnlySpan) []byte {
+	type scopeGroup struct {
+		scope instrumentation.Scope
+		spans [][]byte
+	}
+	type resourceGroup struct {
+		resource *resource.Resource
+		scopes   []*scopeGroup
+	}
+
+	var groups []*resourceGroup
+	for _, span := range spans {
+		var group *resourceGroup
+		for _, g := range groups {
+			if g.resource.Equal(span.Resource()) {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &resourceGroup{resource: span.Resource()}
+			groups = append(groups, group)
+		}
+
+		var scope *scopeGroup
+		for _, s := range group.scopes {
+			if s.scope.Name == span.InstrumentationScope().Name && s.scope.Version == span.InstrumentationScope().Version {
+				scope = s
+				break
+			}
+		}
+		if scope == nil {
+			scope = &scopeGroup{scope: span.InstrumentationScope()}
+			group.scopes = append(group.scopes, scope)
+		}
+		scope.spans = append(scope.spans, encodeSpan(span))
+	}
+
+	var request []byte
+	for _, group := range groups {
+		var resourceSpans []byte
+		var res []byte
+		if group.resource != nil {
+			for _, kv := range group.resource.Attributes() {
+				res = appendMessage(res, 1, encodeKeyValue(kv))
+			}
+		}
+		resourceSpans = appendMessage(resourceSpans, 1, res)
+		for _, scope := range group.scopes {
+			var scopeMsg []byte
+			scopeMsg = appendString(scopeMsg, 1, scope.scope.Name)
+			scopeMsg = appendString(scopeMsg, 2, scope.scope.Version)
+
+			var scopeSpans []byte
+			scopeSpans = appendMessage(scopeSpans, 1, scopeMsg)
+			for _, span := range scope.spans {
+				scopeSpans = appendMessage(scopeSpans, 2, span)
+			}
+			resourceSpans = appendMessage(resourceSpans, 2, scopeSpans)
+		}
+		request = appendMessage(request, 1, resourceSpans)
+	}
+	return request
+}
+
+// encodeSpan encodes one opentelemetry.proto.trace.v1.Span
+func encodeSpan(span sdktrace.ReadOnlySpan) []byte {
+	var b []byte
+	sc := span.SpanContext()
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	b = appendBytes(b, 1, traceID[:])
+	b = appendBytes(b, 2, spanID[:])
+	b = appendString(b, 3, sc.TraceState().String())
+	if parent := span.Parent(); parent.IsValid() {
+		parentID := parent.SpanID()
+		b = appendBytes(b, 4, parentID[:])
+	}
+	b = appendString(b, 5, span.Name())
+	// trace.SpanKind uses the same numbering as OTLP's Span.SpanKind
+	b = appendVarint(b, 6, uint64(span.SpanKind()))
+	b = appendFixed64(b, 7, unixNano(span.StartTime()))
+	b = appendFixed64(b, 8, unixNano(span.EndTime()))
+	for _, kv := range span.Attributes() {
+		b = appendMessage(b, 9, encodeKeyValue(kv))
+	}
+	b = appendVarint(b, 10, uint64(span.DroppedAttributes()))
+	for _, event := range span.Events() {
+		var e []byte
+		e = appendFixed64(e, 1, unixNano(event.Time))
+		e = appendString(e, 2, event.Name)
+		for _, kv := range event.Attributes {
+			e = appendMessage(e, 3, encodeKeyValue(kv))
+		}
+		b = appendMessage(b, 11, e)
+	}
+	b = appendVarint(b, 12, uint64(span.DroppedEvents()))
+
+	var status []byte
+	status = appendString(status, 2, span.Status().Description)
+	status = appendVarint(status, 3, statusCode(span.Status().Code))
+	b = appendMessage(b, 15, status)
+	return b
+}
+
+// statusCode maps an OpenTelemetry status to OTLP's Status.StatusCode,
+// which numbers them differently (unset 0, ok 1, error 2)
+func statusCode(code codes.Code) uint64 {
+	switch code {
+	case codes.Ok:
+		return 1
+	case codes.Error:
+		return 2
+	}
+	return 0
+}
+
+// encodeKeyValue encodes an attribute as opentelemetry.proto.common.v1.KeyValue
+func encodeKeyValue(kv attribute.KeyValue) []byte {
+	var b []byte
+	b = appendString(b, 1, string(kv.Key))
+	return appendMessage(b, 2, encodeValue(kv.Value))
+}
+
+// encodeValue encodes an attribute value as an AnyValue
+func encodeValue(v attribute.Value) []byte {
+	var b []byte
+	switch v.Type() {
+	case attribute.BOOL:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v.AsBool()))
+	case attribute.INT64:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v.AsInt64()))
+	case attribute.FLOAT64:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v.AsFloat64()))
+	case attribute.BOOLSLICE:
+		b = appendArray(b, v.AsBoolSlice(), attribute.BoolValue)
+	case attribute.INT64SLICE:
+		b = appendArray(b, v.AsInt64Slice(), attribute.Int64Value)
+	case attribute.FLOAT64SLICE:
+		b = appendArray(b, v.AsFloat64Slice(), attribute.Float64Value)
+	case attribute.STRINGSLICE:
+		b = appendArray(b, v.AsStringSlice(), attribute.StringValue)
+	default:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v.Emit())
+	}
+	return b
+}
+
+// appendArray appends values as AnyValue.array_value
+func appendArray[T any](b []byte, values []T, value func(T) attribute.Value) []byte {
+	var array []byte
+	for _, v := range values {
+		array = protowire.AppendTag(array, 1, protowire.BytesType)
+		array = protowire.AppendBytes(array, encodeValue(value(v)))
+	}
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	return protowire.AppendBytes(b, array)
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendBytes(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendFixed64(b []byte, num protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, value)
+}
+
+func appendMessage(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
@@ -0,0 +1,137 @@
+// Package telemetry traces RPC calls, metadata fetches, media downloads and
+// storage writes with OpenTelemetry, exported over OTLP/HTTP.
+//
+// Tracing is off unless an OTLP endpoint is configured. Until Setup installs
+// a tracer provider, Start returns OpenTelemetry's no-op spans, so the
+// instrumented code costs next to nothing when tracing is off.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of every SolVault span
+const tracerName = "github.com/NazWright/solvault"
+
+// Config says where spans are exported
+type Config struct {
+	Endpoint string            // OTLP/HTTP traces URL, e.g. http://localhost:4318/v1/traces
+	Headers  map[string]string // Sent with every export, e.g. an API key
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry exporter variables:
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (used as is) or
+// OTEL_EXPORTER_OTLP_ENDPOINT (with /v1/traces appended), and
+// OTEL_EXPORTER_OTLP_HEADERS as key=value pairs. It returns nil when no
+// endpoint is set or OTEL_SDK_DISABLED is true.
+func ConfigFromEnv() (*Config, error) {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("OTEL_SDK_DISABLED")), "true") {
+		return nil, nil
+	}
+	if protocol := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")); protocol != "" && protocol != "http/protobuf" {
+		return nil, fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q: only http/protobuf is supported", protocol)
+	}
+
+	name, endpoint := "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	if endpoint == "" {
+		name, endpoint = "OTEL_EXPORTER_OTLP_ENDPOINT", strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+		if endpoint != "" {
+			endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid %s %q: expected http(s)://host:port", name, endpoint)
+	}
+
+	cfg := &Config{Endpoint: endpoint, Headers: map[string]string{}}
+	for _, field := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q: expected key=value", field)
+		}
+		// Values are URL-encoded, so they can hold commas
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = decoded
+		}
+		cfg.Headers[key] = value
+	}
+	return cfg, nil
+}
+
+// Setup installs a tracer provider that batches spans to cfg's endpoint and
+// returns the function that flushes and stops it. Spans carry the service
+// name solvault unless OTEL_SERVICE_NAME or OTEL_RESOURCE_ATTRIBUTES say
+// otherwise; OTEL_TRACES_SAMPLER chooses what is sampled.
+func Setup(cfg *Config, version string) (func(context.Context) error, error) {
+	res, err := resource.Merge(
+		resource.NewSchemaless(
+			attribute.String("service.name", "solvault"),
+			attribute.String("service.version", version),
+		),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(newExporter(cfg)),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Start begins a span as a child of any span in ctx. End it with End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartClient is Start for a call to another service (an RPC endpoint, a
+// gateway, a remote backend)
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindClient))
+}
+
+// End ends span, marking it failed when err is set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Event records a point in time on the span in ctx, e.g. an RPC failover
+func Event(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// StartRequest begins the server span of an incoming HTTP request, continuing
+// the caller's trace when it sent a traceparent header
+func StartRequest(r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindServer))
+}
@@ -0,0 +1,196 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		endpoint string // "" expects tracing off
+		headers  map[string]string
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{
+			name:     "base endpoint",
+			env:      map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318/"},
+			endpoint: "http://localhost:4318/v1/traces",
+		},
+		{
+			name: "traces endpoint wins",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://localhost:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "https://otel.example.com/traces",
+			},
+			endpoint: "https://otel.example.com/traces",
+		},
+		{
+			name: "headers",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+				"OTEL_EXPORTER_OTLP_HEADERS":  "x-api-key=abc%2C123, x-team = vault",
+			},
+			endpoint: "http://localhost:4318/v1/traces",
+			headers:  map[string]string{"x-api-key": "abc,123", "x-team": "vault"},
+		},
+		{
+			name: "disabled",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+				"OTEL_SDK_DISABLED":           "true",
+			},
+		},
+		{name: "bad endpoint", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "localhost:4318"}, wantErr: true},
+		{name: "bad header", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_EXPORTER_OTLP_HEADERS": "novalue"}, wantErr: true},
+		{name: "grpc protocol", env: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4317", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"OTEL_SDK_DISABLED", "OTEL_EXPORTER_OTLP_PROTOCOL", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_HEADERS"} {
+				t.Setenv(key, test.env[key])
+			}
+
+			cfg, err := ConfigFromEnv()
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", cfg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read config: %v", err)
+			}
+			if test.endpoint == "" {
+				if cfg != nil {
+					t.Fatalf("Expected tracing off, got %+v", cfg)
+				}
+				return
+			}
+			if cfg == nil || cfg.Endpoint != test.endpoint {
+				t.Fatalf("Expected endpoint %s, got %+v", test.endpoint, cfg)
+			}
+			for key, value := range test.headers {
+				if cfg.Headers[key] != value {
+					t.Errorf("Header %s = %q, want %q", key, cfg.Headers[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var names []string
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		names = append(names, spanNames(t, body)...)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(newExporter(&Config{
+		Endpoint: server.URL + "/v1/traces",
+		Headers:  map[string]string{"X-Api-Key": "secret"},
+	})))
+	defer provider.Shutdown(context.Background())
+
+	tracer := provider.Tracer(tracerName)
+	ctx, parent := tracer.Start(context.Background(), "backup sync")
+	_, child := tracer.Start(ctx, "media download")
+	child.SetAttributes(attribute.String("url.full", "https://arweave.net/abc"), attribute.Int("solvault.attempts", 2))
+	End(child, errors.New("gateway timeout"))
+	End(parent, nil)
+
+	if header.Get("Content-Type") != "application/x-protobuf" || header.Get("X-Api-Key") != "secret" {
+		t.Errorf("Unexpected export headers: %v", header)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "backup sync" || names[1] != "media download" {
+		t.Errorf("Expected both spans exported, got %v", names)
+	}
+}
+
+func TestExporter_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var spans []sdktrace.ReadOnlySpan
+	recorder := &recordingExporter{spans: &spans}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(recorder))
+	_, span := provider.Tracer(tracerName).Start(context.Background(), "storage save")
+	span.End()
+
+	err := newExporter(&Config{Endpoint: server.URL}).ExportSpans(context.Background(), spans)
+	if err == nil {
+		t.Fatalf("Expected the rejected export to fail")
+	}
+}
+
+// recordingExporter keeps the spans it is given
+type recordingExporter struct {
+	spans *[]sdktrace.ReadOnlySpan
+}
+
+func (r *recordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	*r.spans = append(*r.spans, spans...)
+	return nil
+}
+
+func (r *recordingExporter) Shutdown(ctx context.Context) error { return nil }
+
+// spanNames reads the span names out of an ExportTraceServiceRequest
+func spanNames(t *testing.T, request []byte) []string {
+	t.Helper()
+	var names []string
+	for _, resourceSpans := range messages(t, request, 1) {
+		for _, scopeSpans := range messages(t, resourceSpans, 2) {
+			for _, span := range messages(t, scopeSpans, 2) {
+				for _, name := range messages(t, span, 5) {
+					names = append(names, string(name))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// messages returns every length-delimited field num in data
+func messages(t *testing.T, data []byte, num protowire.Number) [][]byte {
+	t.Helper()
+	var found [][]byte
+	for len(data) > 0 {
+		n, typ, length := protowire.ConsumeTag(data)
+		if length < 0 {
+			t.Fatalf("Failed to decode tag: %v", protowire.ParseError(length))
+		}
+		data = data[length:]
+		if typ == protowire.BytesType && n == num {
+			value, m := protowire.ConsumeBytes(data)
+			if m < 0 {
+				t.Fatalf("Failed to decode field %d: %v", n, protowire.ParseError(m))
+			}
+			found = append(found, value)
+		}
+		m := protowire.ConsumeFieldValue(n, typ, data)
+		if m < 0 {
+			t.Fatalf("Failed to skip field %d: %v", n, protowire.ParseError(m))
+		}
+		data = data[m:]
+	}
+	return found
+}
@@ -0,0 +1,205 @@
+// Package thumbnail makes small JPEG previews of backed-up images and
+// video poster frames, so galleries and listings need not load
+// multi-megabyte originals.
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// Decoders for the formats NFT images come in
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Dir holds thumbnails inside an NFT's media directory
+const Dir = "thumbnails"
+
+// quality is the JPEG quality of every thumbnail
+const quality = 80
+
+// DefaultSizes are the longest edges, in pixels, of the thumbnails made
+// for each image: one for lists and one for gallery tiles
+var DefaultSizes = []int{128, 512}
+
+// ErrUnsupported means the media has no picture to preview, such as
+// audio, SVG or HTML
+var ErrUnsupported = errors.New("no thumbnail for this media type")
+
+// Thumbnail is one saved preview
+type Thumbnail struct {
+	Size     int    `json:"size"` // Requested longest edge
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Filename string `json:"filename"` // Relative to the media directory
+	Checksum string `json:"checksum"` // SHA-256 of the saved bytes, hex
+}
+
+// SizesFromEnv returns the sizes configured with THUMBNAIL_SIZES, a comma
+// separated list of pixel sizes. "off" disables thumbnails and returns nil;
+// unset or unparsable values fall back to DefaultSizes.
+func SizesFromEnv() []int {
+	setting := strings.ToLower(strings.TrimSpace(os.Getenv("THUMBNAIL_SIZES")))
+	switch setting {
+	case "":
+		return DefaultSizes
+	case "off", "false", "0", "none":
+		return nil
+	}
+	var sizes []int
+	for _, field := range strings.Split(setting, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size <= 0 {
+			return DefaultSizes
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// Supported reports whether Generate can preview media of contentType
+// here: images in a format Go decodes, and videos when ffmpeg is installed
+func Supported(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		_, err := exec.LookPath("ffmpeg")
+		return err == nil
+	case strings.HasPrefix(contentType, "image/svg"):
+		return false
+	default:
+		return strings.HasPrefix(contentType, "image/")
+	}
+}
+
+// Generate writes a thumbnail of each size for a media file into
+// mediaDir/thumbnails, named after base. Videos are previewed with a poster
+// frame, which needs ffmpeg on PATH.
+//
+// Explanation: Thumbnails are always JPEG. Go's image libraries decode WebP
+// but cannot encode it, and JPEG keeps the build free of cgo; at thumbnail
+// sizes the difference in file size is small.
+func Generate(ctx context.Context, data []byte, contentType, mediaDir, base string, sizes []int) ([]*Thumbnail, error) {
+	src, err := picture(ctx, data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(mediaDir, Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+
+	var thumbnails []*Thumbnail
+	for _, size := range sizes {
+		scaled := Scale(src, size)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: quality}); err != nil {
+			return thumbnails, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+		filename := fmt.Sprintf("%s-%d.jpg", base, size)
+		if err := os.WriteFile(filepath.Join(dir, filename), buf.Bytes(), 0644); err != nil {
+			return thumbnails, fmt.Errorf("failed to save thumbnail: %w", err)
+		}
+		thumbnails = append(thumbnails, &Thumbnail{
+			Size:     size,
+			Width:    scaled.Bounds().Dx(),
+			Height:   scaled.Bounds().Dy(),
+			Filename: filepath.ToSlash(filepath.Join(Dir, filename)),
+			Checksum: fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+		})
+	}
+	return thumbnails, nil
+}
+
+// Scale fits src within size×size, keeping its aspect ratio, on a white
+// background so transparent images stay legible as JPEG. Images already
+// small enough keep their size.
+func Scale(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > size {
+		width = max(width*size/longest, 1)
+		height = max(height*size/longest, 1)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
+
+// picture decodes an image, or the poster frame of a video
+func picture(ctx context.Context, data []byte, contentType string) (image.Image, error) {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		frame, err := posterFrame(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		data = frame
+	case strings.HasPrefix(contentType, "image/svg"):
+		return nil, ErrUnsupported
+	case !strings.HasPrefix(contentType, "image/") && contentType != "application/octet-stream" && contentType != "":
+		return nil, ErrUnsupported
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+	return img, nil
+}
+
+// posterFrame extracts a representative frame of a video as PNG
+func posterFrame(ctx context.Context, video []byte) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("video thumbnails need ffmpeg on PATH")
+	}
+
+	// ffmpeg needs to seek within most containers, so it reads a file
+	// rather than a pipe
+	file, err := os.CreateTemp("", "solvault-video-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(video)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	// The thumbnail filter picks the most representative of the first
+	// frames, which skips the black frame many videos open with
+	cmd := exec.CommandContext(ctx, ffmpeg, "-loglevel", "error", "-i", file.Name(),
+		"-vf", "thumbnail", "-frames:v", "1", "-f", "image2pipe", "-c:v", "png", "-")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to extract poster frame: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("failed to extract poster frame: the video has no frames")
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,135 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func pngData(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width/2; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          int
+		wantW, wantH  int
+	}{
+		{"landscape", 400, 200, 128, 128, 64},
+		{"portrait", 300, 600, 128, 64, 128},
+		{"square", 1000, 1000, 512, 512, 512},
+		{"already small", 50, 30, 128, 50, 30},
+		{"very wide", 2000, 3, 128, 128, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Scale(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)), tt.size).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("Got %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	mediaDir := t.TempDir()
+	thumbnails, err := Generate(context.Background(), pngData(t, 800, 400), "image/png", mediaDir, "image.png", []int{128, 512})
+	if err != nil {
+		t.Fatalf("Failed to generate thumbnails: %v", err)
+	}
+
+	want := []struct{ size, width, height int }{{128, 128, 64}, {512, 512, 256}}
+	if len(thumbnails) != len(want) {
+		t.Fatalf("Got %d thumbnails, want %d", len(thumbnails), len(want))
+	}
+	for i, thumb := range thumbnails {
+		if thumb.Size != want[i].size || thumb.Width != want[i].width || thumb.Height != want[i].height {
+			t.Errorf("Thumbnail %d is %+v, want %+v", i, thumb, want[i])
+		}
+		data, err := os.ReadFile(filepath.Join(mediaDir, filepath.FromSlash(thumb.Filename)))
+		if err != nil {
+			t.Fatalf("Failed to read thumbnail: %v", err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to decode thumbnail: %v", err)
+		}
+		if img.Bounds().Dx() != thumb.Width {
+			t.Errorf("Saved width %d, recorded %d", img.Bounds().Dx(), thumb.Width)
+		}
+		// Transparent pixels are flattened onto white
+		if r, g, b, _ := img.At(thumb.Width-1, 0).RGBA(); r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+			t.Errorf("Transparent area rendered as (%d, %d, %d), want white", r>>8, g>>8, b>>8)
+		}
+	}
+	if thumbnails[0].Filename != "thumbnails/image.png-128.jpg" {
+		t.Errorf("Unexpected filename %q", thumbnails[0].Filename)
+	}
+}
+
+func TestGenerate_Unsupported(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), "image/svg+xml"},
+		{"audio", []byte("ID3"), "audio/mpeg"},
+		{"html", []byte("<html></html>"), "text/html"},
+		{"unknown bytes", []byte("not an image"), "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(context.Background(), tt.data, tt.contentType, t.TempDir(), "media", DefaultSizes)
+			if !errors.Is(err, ErrUnsupported) {
+				t.Errorf("Got %v, want ErrUnsupported", err)
+			}
+		})
+	}
+}
+
+func TestSizesFromEnv(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    []int
+	}{
+		{"", DefaultSizes},
+		{"64", []int{64}},
+		{"96, 256,1024", []int{96, 256, 1024}},
+		{"off", nil},
+		{"small", DefaultSizes},
+		{"128,-1", DefaultSizes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			t.Setenv("THUMBNAIL_SIZES", tt.setting)
+			if got := SizesFromEnv(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
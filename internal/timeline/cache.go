@@ -0,0 +1,65 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheFile holds mint block times at the vault root
+const CacheFile = "mint_times.json"
+
+// MintTimes caches the block time of each mint's first transaction.
+// A mint is created once, so entries never go stale.
+type MintTimes struct {
+	path  string
+	Times map[string]time.Time `json:"times"`
+}
+
+// LoadMintTimes reads the cache in vaultDir, starting empty if it is missing
+func LoadMintTimes(vaultDir string) (*MintTimes, error) {
+	cache := &MintTimes{
+		path:  filepath.Join(vaultDir, CacheFile),
+		Times: make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(cache.path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mint time cache: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse mint time cache: %w", err)
+	}
+	if cache.Times == nil {
+		cache.Times = make(map[string]time.Time)
+	}
+	return cache, nil
+}
+
+// Get returns the cached mint time
+func (c *MintTimes) Get(mint string) (time.Time, bool) {
+	t, ok := c.Times[mint]
+	return t, ok
+}
+
+// Put records a mint time
+func (c *MintTimes) Put(mint string, t time.Time) {
+	c.Times[mint] = t.UTC()
+}
+
+// Save writes the cache back to the vault
+func (c *MintTimes) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mint time cache: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write mint time cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package timeline
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// exifDateLayout is how EXIF stores timestamps (no time zone)
+const exifDateLayout = "2006:01:02 15:04:05"
+
+// EXIF tags carrying a creation date, in order of preference
+const (
+	tagDateTimeOriginal  = 0x9003
+	tagDateTimeDigitized = 0x9004
+	tagDateTime          = 0x0132
+	tagExifIFD           = 0x8769
+)
+
+var errNoDate = errors.New("no creation date in media")
+
+// MediaCreated returns the creation time recorded inside an image: EXIF
+// DateTimeOriginal for JPEGs, or the tIME chunk for PNGs
+func MediaCreated(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return time.Time{}, errNoDate
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return time.Time{}, err
+	}
+
+	switch {
+	case magic[0] == 0xFF && magic[1] == 0xD8:
+		return jpegCreated(f)
+	case bytes.Equal(magic, []byte("\x89PNG\r\n\x1a\n")):
+		return pngCreated(f)
+	default:
+		return time.Time{}, errNoDate
+	}
+}
+
+// jpegCreated finds the EXIF APP1 segment and reads its dates
+func jpegCreated(r io.ReadSeeker) (time.Time, error) {
+	if _, err := r.Seek(2, io.SeekStart); err != nil {
+		return time.Time{}, err
+	}
+
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil || header[0] != 0xFF {
+			return time.Time{}, errNoDate
+		}
+		marker := header[1]
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if marker == 0xDA || length < 0 {
+			// Start of scan: metadata segments come before the image data
+			return time.Time{}, errNoDate
+		}
+
+		if marker != 0xE1 {
+			if _, err := r.Seek(int64(length), io.SeekCurrent); err != nil {
+				return time.Time{}, errNoDate
+			}
+			continue
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return time.Time{}, errNoDate
+		}
+		if bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifCreated(segment[6:])
+		}
+	}
+}
+
+// exifCreated reads the preferred creation date from a TIFF-structured
+// EXIF block
+func exifCreated(tiff []byte) (time.Time, error) {
+	if len(tiff) < 8 {
+		return time.Time{}, errNoDate
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return time.Time{}, errNoDate
+	}
+
+	dates := make(map[uint16]string)
+	exifOffset := readIFD(tiff, order, order.Uint32(tiff[4:]), dates)
+	if exifOffset != 0 {
+		readIFD(tiff, order, exifOffset, dates)
+	}
+
+	for _, tag := range []uint16{tagDateTimeOriginal, tagDateTimeDigitized, tagDateTime} {
+		if value, ok := dates[tag]; ok {
+			if t, err := time.Parse(exifDateLayout, value); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, errNoDate
+}
+
+// readIFD collects the date tags of one image file directory and returns
+// the offset of the EXIF sub-directory, if it points to one
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32, dates map[uint16]string) uint32 {
+	if int(offset)+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	var exifOffset uint32
+
+	for i := 0; i < count; i++ {
+		start := int(offset) + 2 + i*12
+		if start+12 > len(tiff) {
+			break
+		}
+		entry := tiff[start : start+12]
+		tag := order.Uint16(entry)
+		size := order.Uint32(entry[4:])
+
+		switch tag {
+		case tagExifIFD:
+			exifOffset = order.Uint32(entry[8:])
+		case tagDateTimeOriginal, tagDateTimeDigitized, tagDateTime:
+			// ASCII dates are 20 bytes, so they live at the value offset
+			valueOffset := order.Uint32(entry[8:])
+			if size < 19 || int(valueOffset)+19 > len(tiff) {
+				continue
+			}
+			dates[tag] = string(tiff[valueOffset : valueOffset+19])
+		}
+	}
+	return exifOffset
+}
+
+// pngCreated walks the PNG chunks looking for tIME
+func pngCreated(r io.ReadSeeker) (time.Time, error) {
+	if _, err := r.Seek(8, io.SeekStart); err != nil {
+		return time.Time{}, err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return time.Time{}, errNoDate
+		}
+		length := binary.BigEndian.Uint32(header)
+		switch string(header[4:]) {
+		case "tIME":
+			data := make([]byte, 7)
+			if length != 7 {
+				return time.Time{}, errNoDate
+			}
+			if _, err := io.ReadFull(r, data); err != nil {
+				return time.Time{}, errNoDate
+			}
+			return time.Date(int(binary.BigEndian.Uint16(data)), time.Month(data[2]), int(data[3]),
+				int(data[4]), int(data[5]), int(data[6]), 0, time.UTC), nil
+		case "IEND":
+			return time.Time{}, errNoDate
+		}
+		// Skip the chunk data and its CRC
+		if _, err := r.Seek(int64(length)+4, io.SeekCurrent); err != nil {
+			return time.Time{}, errNoDate
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package timeline orders a vault's NFTs into the user's collecting history,
+// dated by mint block time where known and by media creation metadata or the
+// backup date otherwise, and renders it as ASCII, HTML or JSON.
+package timeline
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateSource says which timestamp an entry is placed by
+type DateSource string
+
+const (
+	SourceMint   DateSource = "mint"   // Block time of the mint's first transaction
+	SourceMedia  DateSource = "media"  // EXIF or PNG creation time of the media
+	SourceBackup DateSource = "backup" // When the vault first stored the NFT
+)
+
+// Entry is one NFT on the timeline
+type Entry struct {
+	Mint           string     `json:"mint"`
+	Name           string     `json:"name"`
+	Collection     string     `json:"collection,omitempty"`
+	Wallet         string     `json:"wallet"`
+	MintedAt       *time.Time `json:"minted_at,omitempty"`
+	MediaCreatedAt *time.Time `json:"media_created_at,omitempty"`
+	BackedUpAt     time.Time  `json:"backed_up_at"`
+	When           time.Time  `json:"when"`
+	Source         DateSource `json:"source"`
+}
+
+// Period groups the entries of one calendar month
+type Period struct {
+	Label   string   `json:"label"` // e.g. "2024-03"
+	Entries []*Entry `json:"entries"`
+}
+
+// Timeline is the vault's collecting history, oldest first
+type Timeline struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Entries     []*Entry  `json:"entries"`
+	Periods     []Period  `json:"periods"`
+}
+
+// Build dates each entry by the best available source and orders them
+func Build(entries []*Entry) *Timeline {
+	for _, entry := range entries {
+		switch {
+		case entry.MintedAt != nil:
+			entry.When, entry.Source = *entry.MintedAt, SourceMint
+		case entry.MediaCreatedAt != nil:
+			entry.When, entry.Source = *entry.MediaCreatedAt, SourceMedia
+		default:
+			entry.When, entry.Source = entry.BackedUpAt, SourceBackup
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].When.Equal(entries[j].When) {
+			return entries[i].When.Before(entries[j].When)
+		}
+		return entries[i].Mint < entries[j].Mint
+	})
+
+	tl := &Timeline{GeneratedAt: time.Now().UTC(), Entries: entries, Periods: []Period{}}
+	for _, entry := range entries {
+		label := entry.When.UTC().Format("2006-01")
+		if n := len(tl.Periods); n == 0 || tl.Periods[n-1].Label != label {
+			tl.Periods = append(tl.Periods, Period{Label: label})
+		}
+		last := &tl.Periods[len(tl.Periods)-1]
+		last.Entries = append(last.Entries, entry)
+	}
+	return tl
+}
+
+// sourceMarker distinguishes how each entry was dated in the ASCII view
+var sourceMarker = map[DateSource]string{
+	SourceMint:   "●",
+	SourceMedia:  "◆",
+	SourceBackup: "○",
+}
+
+// RenderASCII draws the timeline as a vertical line of months with a bar
+// showing how many NFTs were collected in each
+func (tl *Timeline) RenderASCII(w io.Writer) error {
+	if len(tl.Entries) == 0 {
+		_, err := fmt.Fprintln(w, "📭 No NFTs in the vault yet")
+		return err
+	}
+
+	busiest := 0
+	for _, period := range tl.Periods {
+		busiest = max(busiest, len(period.Entries))
+	}
+
+	var b strings.Builder
+	for _, period := range tl.Periods {
+		barLen := max(1, len(period.Entries)*30/busiest)
+		fmt.Fprintf(&b, "%s ┤ %s %d\n", period.Label, strings.Repeat("█", barLen), len(period.Entries))
+		for _, entry := range period.Entries {
+			name := entry.Name
+			if name == "" {
+				name = entry.Mint
+			}
+			if entry.Collection != "" {
+				name += " (" + entry.Collection + ")"
+			}
+			fmt.Fprintf(&b, "        │  %s %s  %s\n", sourceMarker[entry.Source], entry.When.UTC().Format("Jan 02"), name)
+		}
+	}
+	fmt.Fprintf(&b, "\n%s minted   %s media date   %s backup date\n",
+		sourceMarker[SourceMint], sourceMarker[SourceMedia], sourceMarker[SourceBackup])
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderHTML writes a self-contained HTML page of the timeline
+func (tl *Timeline) RenderHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, tl)
+}
+
+var htmlTemplate = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format("Jan 2, 2006") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>SolVault collecting timeline</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 760px; margin: 2rem auto; color: #222; }
+h1 { font-size: 1.4rem; }
+.period { border-left: 3px solid #7b5cff; margin-left: 1rem; padding: 0 0 1rem 1.5rem; }
+.period h2 { font-size: 1rem; margin: 0 0 .5rem -2.05rem; }
+.period h2::before { content: "●"; color: #7b5cff; margin-right: .6rem; }
+.entry { margin: .3rem 0; }
+.entry small { color: #777; }
+.source-mint { color: #1a7f37; } .source-media { color: #9a6700; } .source-backup { color: #777; }
+</style>
+</head>
+<body>
+<h1>🔒 Collecting timeline</h1>
+<p><small>{{len .Entries}} NFTs · generated {{date .GeneratedAt}}</small></p>
+{{range .Periods}}<div class="period">
+<h2>{{.Label}} · {{len .Entries}}</h2>
+{{range .Entries}}<div class="entry"><span class="source-{{.Source}}" title="dated by {{.Source}}">{{date .When}}</span> — <strong>{{if .Name}}{{.Name}}{{else}}{{.Mint}}{{end}}</strong>{{if .Collection}} <small>{{.Collection}}</small>{{end}}<br><small>{{.Mint}}</small></div>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
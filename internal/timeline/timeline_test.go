@@ -0,0 +1,170 @@
+package timeline
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func at(s string) *time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestBuild(t *testing.T) {
+	entries := []*Entry{
+		{Mint: "backup-only", BackedUpAt: *at("2024-05-01")},
+		{Mint: "media", MediaCreatedAt: at("2023-02-10"), BackedUpAt: *at("2024-05-01")},
+		{Mint: "minted", MintedAt: at("2023-02-01"), MediaCreatedAt: at("2020-01-01"), BackedUpAt: *at("2024-05-01")},
+	}
+
+	tl := Build(entries)
+
+	wantOrder := []string{"minted", "media", "backup-only"}
+	wantSource := []DateSource{SourceMint, SourceMedia, SourceBackup}
+	for i, entry := range tl.Entries {
+		if entry.Mint != wantOrder[i] || entry.Source != wantSource[i] {
+			t.Errorf("Entry %d = %s (%s), want %s (%s)", i, entry.Mint, entry.Source, wantOrder[i], wantSource[i])
+		}
+	}
+	if len(tl.Periods) != 2 || tl.Periods[0].Label != "2023-02" || len(tl.Periods[0].Entries) != 2 {
+		t.Errorf("Unexpected periods: %+v", tl.Periods)
+	}
+
+	var ascii, html bytes.Buffer
+	if err := tl.RenderASCII(&ascii); err != nil {
+		t.Fatalf("Failed to render ASCII: %v", err)
+	}
+	if !strings.Contains(ascii.String(), "2023-02 ┤") || !strings.Contains(ascii.String(), "backup-only") {
+		t.Errorf("ASCII timeline missing entries:\n%s", ascii.String())
+	}
+	if err := tl.RenderHTML(&html); err != nil {
+		t.Fatalf("Failed to render HTML: %v", err)
+	}
+	if !strings.Contains(html.String(), "2024-05") {
+		t.Error("HTML timeline missing a period")
+	}
+}
+
+// jpegWithExif builds a minimal JPEG whose EXIF block has the given tags
+func jpegWithExif(order binary.AppendByteOrder, dates map[uint16]string) []byte {
+	tiff := []byte("II")
+	if order == binary.AppendByteOrder(binary.BigEndian) {
+		tiff = []byte("MM")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+
+	// Single IFD with every date inline after it
+	tags := []uint16{tagDateTime, tagDateTimeOriginal}
+	var present []uint16
+	for _, tag := range tags {
+		if _, ok := dates[tag]; ok {
+			present = append(present, tag)
+		}
+	}
+	valueOffset := 8 + 2 + len(present)*12 + 4
+	tiff = order.AppendUint16(tiff, uint16(len(present)))
+	var values []byte
+	for _, tag := range present {
+		tiff = order.AppendUint16(tiff, tag)
+		tiff = order.AppendUint16(tiff, 2) // ASCII
+		tiff = order.AppendUint32(tiff, 20)
+		tiff = order.AppendUint32(tiff, uint32(valueOffset+len(values)))
+		values = append(values, dates[tag]+"\x00"...)
+	}
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, values...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 0x00, 0x00} // SOI + empty APP0
+	data = append(data, 0xFF, 0xE1)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func pngWithTime(year int, month, day, hour byte) []byte {
+	data := []byte("\x89PNG\r\n\x1a\n")
+	chunk := func(kind string, body []byte) {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(body)))
+		data = append(data, kind...)
+		data = append(data, body...)
+		data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(append([]byte(kind), body...)))
+	}
+	chunk("IHDR", make([]byte, 13))
+	body := binary.BigEndian.AppendUint16(nil, uint16(year))
+	chunk("tIME", append(body, month, day, hour, 30, 0))
+	chunk("IEND", nil)
+	return data
+}
+
+func TestMediaCreated(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{"jpeg prefers original date", jpegWithExif(binary.LittleEndian, map[uint16]string{
+			tagDateTime: "2024:01:01 00:00:00", tagDateTimeOriginal: "2022:06:15 10:20:30",
+		}), "2022-06-15 10:20:30", false},
+		{"big endian jpeg", jpegWithExif(binary.BigEndian, map[uint16]string{
+			tagDateTime: "2021:03:04 05:06:07",
+		}), "2021-03-04 05:06:07", false},
+		{"jpeg without dates", jpegWithExif(binary.LittleEndian, nil), "", true},
+		{"png time chunk", pngWithTime(2023, 7, 8, 9), "2023-07-08 09:30:00", false},
+		{"not an image", []byte("plain text file"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "media")
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatalf("Failed to write media: %v", err)
+			}
+
+			got, err := MediaCreated(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected no date, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read creation date: %v", err)
+			}
+			if got.Format("2006-01-02 15:04:05") != tt.want {
+				t.Errorf("MediaCreated() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMintTimes(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := LoadMintTimes(dir)
+	if err != nil {
+		t.Fatalf("Failed to load empty cache: %v", err)
+	}
+	cache.Put("mint", *at("2022-01-01"))
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	reloaded, err := LoadMintTimes(dir)
+	if err != nil {
+		t.Fatalf("Failed to reload cache: %v", err)
+	}
+	if got, ok := reloaded.Get("mint"); !ok || !got.Equal(*at("2022-01-01")) {
+		t.Errorf("Cached time = %v (%v), want 2022-01-01", got, ok)
+	}
+}
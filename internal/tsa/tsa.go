@@ -0,0 +1,406 @@
+// Package tsa requests and checks RFC 3161 timestamp tokens, which prove a
+// document existed at a point in time without an on-chain transaction: a
+// timestamp authority signs the document's hash together with the time.
+package tsa
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is a free public timestamp authority
+const DefaultURL = "https://freetsa.org/tsr"
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidRSAPSS        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+// ASN.1 structures from RFC 3161 and RFC 5652 (CMS)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       accuracy      `asn1:"optional"`
+	Ordering       bool          `asn1:"optional,default:false"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// Info describes a checked timestamp token
+type Info struct {
+	Time      time.Time `json:"time"`                 // When the authority saw the hash
+	Authority string    `json:"authority"`            // Subject of the signing certificate
+	Serial    string    `json:"serial"`               // The authority's serial number for the token
+	Policy    string    `json:"policy"`               // The authority's policy OID
+	Trusted   bool      `json:"trusted"`              // The signing certificate chains to a trusted root
+	TrustNote string    `json:"trust_note,omitempty"` // Why it does not, when it does not
+}
+
+// Client requests timestamps from one authority
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// NewClient creates a client for the authority at url
+func NewClient(url string) *Client {
+	return &Client{url: url, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Stamp asks the authority to timestamp a SHA-256 digest and returns the
+// DER-encoded response, ready to be saved, with what it attests
+func (c *Client) Stamp(ctx context.Context, digest []byte) ([]byte, *Info, error) {
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create nonce: %w", err)
+	}
+	query, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode timestamp request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reach timestamp authority: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("timestamp authority returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read timestamp response: %w", err)
+	}
+
+	tst, info, err := verify(body, digest, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Explanation: The nonce ties the response to this request, so an old
+	// token for the same hash cannot be replayed as a fresh one
+	if tst.Nonce == nil || tst.Nonce.Cmp(nonce) != 0 {
+		return nil, nil, errors.New("timestamp response does not answer this request (nonce mismatch)")
+	}
+	return body, info, nil
+}
+
+// Verify checks a saved timestamp response: the authority granted it, it
+// covers the given SHA-256 digest and its signature is valid. Trust in the
+// signing certificate is reported in Info rather than failing, since many
+// authorities use roots outside the system pool; roots replaces that pool
+// when set.
+func Verify(response, digest []byte, roots *x509.CertPool) (*Info, error) {
+	_, info, err := verify(response, digest, roots)
+	return info, err
+}
+
+func verify(response, digest []byte, roots *x509.CertPool) (*tstInfo, *Info, error) {
+	var resp timeStampResp
+	rest, err := asn1.Unmarshal(response, &resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse timestamp response: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, nil, errors.New("failed to parse timestamp response: unexpected data after it")
+	}
+	// 0 is granted, 1 granted with modifications
+	if resp.Status.Status > 1 {
+		return nil, nil, fmt.Errorf("timestamp authority refused the request (status %d): %s",
+			resp.Status.Status, strings.Join(resp.Status.StatusString, "; "))
+	}
+	if len(resp.TimeStampToken.FullBytes) == 0 {
+		return nil, nil, errors.New("timestamp response has no token")
+	}
+
+	tst, signer, intermediates, err := parseToken(resp.TimeStampToken.FullBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !tst.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(tst.MessageImprint.HashedMessage, digest) {
+		return nil, nil, errors.New("timestamp token is for a different document")
+	}
+
+	info := &Info{
+		Time:      tst.GenTime.UTC(),
+		Authority: signer.Subject.String(),
+		Serial:    tst.SerialNumber.String(),
+		Policy:    tst.Policy.String(),
+	}
+	info.Trusted, info.TrustNote = trusted(signer, intermediates, roots, tst.GenTime)
+	return tst, info, nil
+}
+
+// parseToken checks the CMS signature on a timestamp token and returns its
+// content, the certificate that signed it and the other certificates it
+// carries
+func parseToken(token []byte) (*tstInfo, *x509.Certificate, []*x509.Certificate, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse timestamp token: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, nil, nil, errors.New("timestamp token is not signed data")
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse timestamp token: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, nil, nil, errors.New("timestamp token does not contain timestamp info")
+	}
+	var tst tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &tst); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse timestamp info: %w", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, nil, fmt.Errorf("timestamp token has %d signers, want 1", len(sd.SignerInfos))
+	}
+	if len(sd.Certificates.Bytes) == 0 {
+		return nil, nil, nil, errors.New("timestamp token carries no certificate to check its signature with")
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse timestamp certificates: %w", err)
+	}
+
+	si := sd.SignerInfos[0]
+	signer := findSigner(si.SID, certs)
+	if signer == nil {
+		return nil, nil, nil, errors.New("timestamp token does not include its signing certificate")
+	}
+	if err := checkSignature(si, signer, sd.EncapContentInfo.EContent); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var others []*x509.Certificate
+	for _, cert := range certs {
+		if cert != signer {
+			others = append(others, cert)
+		}
+	}
+	return &tst, signer, others, nil
+}
+
+// findSigner picks the certificate a SignerInfo names, by issuer and
+// serial number or by subject key identifier
+func findSigner(sid asn1.RawValue, certs []*x509.Certificate) *x509.Certificate {
+	for _, cert := range certs {
+		switch {
+		case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+			var ias issuerAndSerial
+			if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err == nil &&
+				ias.Serial.Cmp(cert.SerialNumber) == 0 && bytes.Equal(ias.Issuer.FullBytes, cert.RawIssuer) {
+				return cert
+			}
+		case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+			if bytes.Equal(sid.Bytes, cert.SubjectKeyId) {
+				return cert
+			}
+		}
+	}
+	return nil
+}
+
+// checkSignature verifies a SignerInfo over its signed attributes, and that
+// those attributes carry the digest of the signed content
+//
+// Explanation: Timestamp authorities always sign attributes (RFC 3161
+// requires the signing certificate as one), so the signature covers the
+// DER of the attribute SET rather than the content itself.
+func checkSignature(si signerInfo, signer *x509.Certificate, content []byte) error {
+	hash, err := hashFor(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return errors.New("timestamp token has no signed attributes")
+	}
+
+	var messageDigest []byte
+	for rest := si.SignedAttrs.Bytes; len(rest) > 0; {
+		var attr attribute
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return fmt.Errorf("failed to parse signed attributes: %w", err)
+		}
+		if attr.Type.Equal(oidMessageDigest) {
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+				return fmt.Errorf("failed to parse message digest: %w", err)
+			}
+		}
+	}
+	h := hash.New()
+	h.Write(content)
+	if messageDigest == nil || !bytes.Equal(messageDigest, h.Sum(nil)) {
+		return errors.New("timestamp token signature does not cover its content")
+	}
+
+	signed := append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...) // [0] IMPLICIT becomes SET OF
+	h = hash.New()
+	h.Write(signed)
+	sum := h.Sum(nil)
+
+	valid := false
+	switch key := signer.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if si.SignatureAlgorithm.Algorithm.Equal(oidRSAPSS) {
+			valid = rsa.VerifyPSS(key, hash, sum, si.Signature, nil) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(key, hash, sum, si.Signature) == nil
+		}
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, sum, si.Signature)
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, signed, si.Signature)
+	default:
+		return fmt.Errorf("unsupported timestamp signing key %T", signer.PublicKey)
+	}
+	if !valid {
+		return errors.New("timestamp token signature is invalid")
+	}
+	return nil
+}
+
+func hashFor(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, nil
+	}
+	return 0, fmt.Errorf("unsupported digest algorithm %s", oid)
+}
+
+// trusted reports whether the signing certificate chains to roots (the
+// system pool when nil) as a timestamping certificate valid at genTime
+func trusted(signer *x509.Certificate, intermediates []*x509.Certificate, roots *x509.CertPool, genTime time.Time) (bool, string) {
+	pool := x509.NewCertPool()
+	for _, cert := range intermediates {
+		pool.AddCert(cert)
+	}
+	_, err := signer.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: pool,
+		CurrentTime:   genTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	})
+	if err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
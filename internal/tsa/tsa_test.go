@@ -0,0 +1,206 @@
+package tsa
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAuthority is a minimal RFC 3161 timestamp authority
+type fakeAuthority struct {
+	key    *ecdsa.PrivateKey
+	cert   *x509.Certificate
+	status int
+	change func(info *tstInfo) // Alters the token before it is signed
+}
+
+func newFakeAuthority(t *testing.T) *fakeAuthority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(7),
+		Subject:               pkix.Name{CommonName: "Test TSA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return &fakeAuthority{key: key, cert: cert}
+}
+
+func (a *fakeAuthority) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req timeStampReq
+	if _, err := asn1.Unmarshal(body, &req); err != nil || r.Header.Get("Content-Type") != "application/timestamp-query" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	resp, err := a.respond(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/timestamp-reply")
+	w.Write(resp)
+}
+
+func (a *fakeAuthority) respond(req timeStampReq) ([]byte, error) {
+	if a.status > 1 {
+		return asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: a.status, StatusString: []string{"bad alg"}}})
+	}
+
+	info := tstInfo{
+		Version:        1,
+		Policy:         asn1.ObjectIdentifier{1, 2, 3, 4},
+		MessageImprint: req.MessageImprint,
+		SerialNumber:   big.NewInt(42),
+		GenTime:        time.Now().UTC().Truncate(time.Second),
+		Nonce:          req.Nonce,
+	}
+	if a.change != nil {
+		a.change(&info)
+	}
+	content, err := asn1.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	contentDigest := sha256.Sum256(content)
+	digestValue, _ := asn1.Marshal(contentDigest[:])
+	attr, err := asn1.Marshal(attribute{
+		Type:   oidMessageDigest,
+		Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: digestValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	signedSet, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attr})
+	sum := sha256.Sum256(signedSet)
+	signature, err := a.key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	sid, _ := asn1.Marshal(issuerAndSerial{Issuer: asn1.RawValue{FullBytes: a.cert.RawIssuer}, Serial: a.cert.SerialNumber})
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+	algs, _ := asn1.Marshal(sha256Alg)
+	sd, err := asn1.Marshal(signedData{
+		Version:          3,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: algs},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: oidTSTInfo, EContent: content},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: a.cert.Raw},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    sha256Alg,
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attr},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	token, err := asn1.Marshal(contentInfo{ContentType: oidSignedData, Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd}})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: a.status}, TimeStampToken: asn1.RawValue{FullBytes: token}})
+}
+
+func TestStampAndVerify(t *testing.T) {
+	authority := newFakeAuthority(t)
+	server := httptest.NewServer(authority)
+	defer server.Close()
+
+	digest := sha256.Sum256([]byte(`{"status":"authentic"}`))
+	response, info, err := NewClient(server.URL).Stamp(context.Background(), digest[:])
+	if err != nil {
+		t.Fatalf("Failed to stamp: %v", err)
+	}
+	if info.Serial != "42" || !strings.Contains(info.Authority, "Test TSA") || time.Since(info.Time) > time.Minute {
+		t.Errorf("Got %+v, want serial 42 from Test TSA just now", info)
+	}
+	if info.Trusted {
+		t.Errorf("A self-signed authority is trusted without being given as a root")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.cert)
+	checked, err := Verify(response, digest[:], roots)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if !checked.Trusted || !checked.Time.Equal(info.Time) {
+		t.Errorf("Got %+v, want a trusted token from %s", checked, info.Time)
+	}
+
+	other := sha256.Sum256([]byte(`{"status":"tampered"}`))
+	if _, err := Verify(response, other[:], roots); err == nil {
+		t.Errorf("Expected an error verifying the token against another document")
+	}
+
+	// Flip a byte of the signed content; the signature must no longer hold
+	forged := append([]byte(nil), response...)
+	index := strings.Index(string(forged), "\x02\x01\x2a") // SerialNumber 42
+	if index < 0 {
+		t.Fatalf("Serial number not found in response")
+	}
+	forged[index+2] = 0x2b
+	if _, err := Verify(forged, digest[:], roots); err == nil {
+		t.Errorf("Expected an error verifying a forged token")
+	}
+}
+
+func TestStamp_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		change func(info *tstInfo)
+	}{
+		{name: "refused", status: 2},
+		{name: "replayed nonce", change: func(info *tstInfo) { info.Nonce = big.NewInt(1) }},
+		{name: "other document", change: func(info *tstInfo) { info.MessageImprint.HashedMessage = make([]byte, 32) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authority := newFakeAuthority(t)
+			authority.status = tt.status
+			authority.change = tt.change
+			server := httptest.NewServer(authority)
+			defer server.Close()
+
+			digest := sha256.Sum256([]byte("proof"))
+			if _, _, err := NewClient(server.URL).Stamp(context.Background(), digest[:]); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
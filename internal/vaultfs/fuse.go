@@ -0,0 +1,127 @@
+//go:build linux || darwin
+
+package vaultfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// Supported reports whether FUSE mounts are available on this platform
+const Supported = true
+
+// vnode exposes one Node through go-fuse
+type vnode struct {
+	fs.Inode
+	node *Node
+}
+
+var (
+	_ fs.NodeGetattrer = (*vnode)(nil)
+	_ fs.NodeOpener    = (*vnode)(nil)
+	_ fs.NodeReader    = (*vnode)(nil)
+	_ fs.NodeOnAdder   = (*vnode)(nil)
+)
+
+// OnAdd builds the whole inode tree up front; the snapshot never changes
+func (v *vnode) OnAdd(ctx context.Context) {
+	v.addChildren(ctx)
+}
+
+func (v *vnode) addChildren(ctx context.Context) {
+	for _, child := range v.node.SortedChildren() {
+		mode := uint32(fuse.S_IFREG)
+		if child.IsDir() {
+			mode = fuse.S_IFDIR
+		}
+		childNode := &vnode{node: child}
+		inode := v.NewPersistentInode(ctx, childNode, fs.StableAttr{Mode: mode})
+		v.AddChild(child.Name, inode, false)
+		if child.IsDir() {
+			childNode.addChildren(ctx)
+		}
+	}
+}
+
+func (v *vnode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	if v.node.IsDir() {
+		out.Mode = fuse.S_IFDIR | 0555
+	} else {
+		out.Mode = fuse.S_IFREG | 0444
+		out.Size = uint64(v.node.Size)
+	}
+	mtime := v.node.ModTime
+	out.SetTimes(&mtime, &mtime, &mtime)
+	return 0
+}
+
+// decodedFile holds the contents of an encrypted or compressed file for
+// as long as it is open, so reads do not decode it again for every chunk
+type decodedFile struct {
+	data []byte
+}
+
+func (v *vnode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_APPEND|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+	if v.node.Encoded() {
+		data, err := v.node.ReadAll()
+		if err != nil {
+			return nil, 0, syscall.EIO
+		}
+		return &decodedFile{data: data}, fuse.FOPEN_KEEP_CACHE, 0
+	}
+	return nil, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+func (v *vnode) Read(ctx context.Context, f fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if decoded, ok := f.(*decodedFile); ok {
+		if off >= int64(len(decoded.data)) {
+			return fuse.ReadResultData(nil), 0
+		}
+		return fuse.ReadResultData(decoded.data[off:min(off+int64(len(dest)), int64(len(decoded.data)))]), 0
+	}
+
+	file, err := os.Open(v.node.Source)
+	if err != nil {
+		return nil, fs.ToErrno(err)
+	}
+	defer file.Close()
+
+	n, err := file.ReadAt(dest, off)
+	if err != nil && n == 0 && off < v.node.Size {
+		return nil, fs.ToErrno(err)
+	}
+	return fuse.ReadResultData(dest[:n]), 0
+}
+
+// Mount serves root read-only at mountpoint until ctx is cancelled
+func Mount(ctx context.Context, root *Node, mountpoint string) error {
+	timeout := time.Minute
+	server, err := fs.Mount(mountpoint, &vnode{node: root}, &fs.Options{
+		EntryTimeout: &timeout,
+		AttrTimeout:  &timeout,
+		MountOptions: fuse.MountOptions{
+			FsName:  "solvault",
+			Name:    "solvault",
+			Options: []string{"ro"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mount %s: %w", mountpoint, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Unmount()
+	}()
+	server.Wait()
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package vaultfs
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// Supported reports whether FUSE mounts are available on this platform
+const Supported = false
+
+// Mount is not available on this platform
+func Mount(ctx context.Context, root *Node, mountpoint string) error {
+	return fmt.Errorf("mounting the vault is not supported on %s", runtime.GOOS)
+}
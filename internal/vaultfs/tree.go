@@ -0,0 +1,228 @@
+// Package vaultfs presents a vault as a read-only tree organized by
+// collection and NFT name, for browsing through a FUSE mount.
+package vaultfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/storage"
+)
+
+// UncollectedDir holds NFTs whose metadata names no collection
+const UncollectedDir = "Uncollected"
+
+// Node is a directory or a file in the virtual tree. Files are backed by a
+// file in the vault; directories only exist in memory.
+type Node struct {
+	Name     string
+	Source   string // Backing file on disk, empty for directories
+	Size     int64  // Of the original contents, not the stored form
+	ModTime  time.Time
+	Children map[string]*Node
+
+	vault *storage.FileStorage // Set when Source is encrypted or compressed
+}
+
+// IsDir reports whether the node is a directory
+func (n *Node) IsDir() bool {
+	return n.Source == ""
+}
+
+// Encoded reports whether the backing file is encrypted or compressed, so
+// it must be decoded whole rather than read in place
+func (n *Node) Encoded() bool {
+	return n.vault != nil
+}
+
+// ReadAll returns the file's original contents
+func (n *Node) ReadAll() ([]byte, error) {
+	if n.vault != nil {
+		return n.vault.ReadFile(n.Source)
+	}
+	return os.ReadFile(n.Source)
+}
+
+// Lookup walks a slash-separated path from n
+func (n *Node) Lookup(path string) *Node {
+	node := n
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		if node.Children == nil {
+			return nil
+		}
+		if node = node.Children[part]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// SortedChildren returns the node's children ordered by name
+func (n *Node) SortedChildren() []*Node {
+	children := make([]*Node, 0, len(n.Children))
+	for _, child := range n.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	return children
+}
+
+func newDir(name string) *Node {
+	return &Node{Name: name, Children: make(map[string]*Node), ModTime: time.Now()}
+}
+
+// Build snapshots the vault into a tree of
+// <collection>/<nft name>/{nft_data.json, metadata.json, proof.json, media/...}
+//
+// Explanation: Each backup appears once even when several wallets link to
+// it. Two NFTs with the same name in one collection are told apart by a
+// short mint suffix.
+func Build(vault *storage.FileStorage) (*Node, error) {
+	root := newDir("")
+
+	seen := make(map[string]bool)
+	for _, entry := range vault.Index().List() {
+		dir := vault.EntryDir(entry)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		var stored storage.StoredNFT
+		data, err := vault.ReadFile(filepath.Join(dir, "nft_data.json"))
+		if err != nil {
+			continue // Index entry without files; skip rather than fail the mount
+		}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+		}
+
+		collection := root.child(collectionName(&stored))
+		name := sanitize(entry.Name)
+		if name == "" {
+			name = entry.Mint
+		}
+		if _, taken := collection.Children[name]; taken {
+			name = fmt.Sprintf("%s (%s)", name, entry.Mint[:min(6, len(entry.Mint))])
+		}
+
+		nftNode := collection.child(name)
+		if err := addFiles(vault, nftNode, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// child returns the named subdirectory, creating it if needed
+func (n *Node) child(name string) *Node {
+	if existing, ok := n.Children[name]; ok {
+		return existing
+	}
+	dir := newDir(name)
+	n.Children[name] = dir
+	return dir
+}
+
+// addFiles mirrors the regular files under dir into node
+//
+// Explanation: The size of an encrypted or compressed file is only known
+// once it is decoded, so those are decoded here once; plain files are
+// sized from the directory listing.
+func addFiles(vault *storage.FileStorage, node *Node, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if err := addFiles(vault, node.child(entry.Name()), path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		file := &Node{
+			Name:    entry.Name(),
+			Source:  path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		encoded, err := isEncoded(path)
+		if err != nil {
+			continue
+		}
+		if encoded {
+			file.vault = vault
+			data, err := file.ReadAll()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			file.Size = int64(len(data))
+		}
+		node.Children[entry.Name()] = file
+	}
+	return nil
+}
+
+// isEncoded reports whether the file at path is stored encrypted or
+// compressed
+func isEncoded(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return crypt.IsSealed(header[:n]) || storage.IsCompressed(header[:n]), nil
+}
+
+func collectionName(stored *storage.StoredNFT) string {
+	if stored.NFTInfo == nil || stored.NFTInfo.Metadata == nil {
+		return UncollectedDir
+	}
+	collection := stored.NFTInfo.Metadata.Collection
+	for _, name := range []string{collection.Name, collection.Family} {
+		if clean := sanitize(name); clean != "" {
+			return clean
+		}
+	}
+	return UncollectedDir
+}
+
+// sanitize makes a name usable as a single path component
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == 0 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,133 @@
+package vaultfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/crypt"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/storage"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+func saveTestNFT(t *testing.T, vault *storage.FileStorage, wallet solanago.PublicKey, name, collection string) solanago.PublicKey {
+	t.Helper()
+	mint := solanago.NewWallet().PublicKey()
+	info := &fetcher.NFTInfo{
+		MintAddress: mint,
+		Owner:       wallet,
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: name, Collection: fetcher.Collection{Name: collection}},
+	}
+	if err := vault.SaveNFT(context.Background(), info); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	return mint
+}
+
+func TestBuild(t *testing.T) {
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	walletA := solanago.NewWallet().PublicKey()
+	walletB := solanago.NewWallet().PublicKey()
+	lion := saveTestNFT(t, vault, walletA, "Midnight Lion #01", "Midnight Lions")
+	saveTestNFT(t, vault, walletA, "Twin", "Twins")
+	saveTestNFT(t, vault, walletB, "Twin", "Twins")
+	saveTestNFT(t, vault, walletA, "Loner/1", "")
+
+	// A second wallet linking to the same backup must not duplicate it
+	linked := &fetcher.NFTInfo{MintAddress: lion, Owner: walletB, FetchedAt: time.Now(),
+		Metadata: &fetcher.NFTMetadata{Name: "Midnight Lion #01", Collection: fetcher.Collection{Name: "Midnight Lions"}}}
+	if err := vault.SaveNFT(context.Background(), linked); err != nil {
+		t.Fatalf("Failed to save linked NFT: %v", err)
+	}
+
+	mediaDir := filepath.Join(vault.NFTDir(walletA, lion), "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatalf("Failed to create media dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "image.png"), []byte("png-bytes"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	root, err := Build(vault)
+	if err != nil {
+		t.Fatalf("Failed to build tree: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+	}{
+		{"Midnight Lions", true},
+		{"Midnight Lions/Midnight Lion #01/nft_data.json", false},
+		{"Midnight Lions/Midnight Lion #01/media/image.png", false},
+		{"Twins/Twin", true},
+		{UncollectedDir + "/Loner_1", true},
+	}
+	for _, tt := range tests {
+		node := root.Lookup(tt.path)
+		if node == nil {
+			t.Errorf("Lookup(%q) = nil", tt.path)
+			continue
+		}
+		if node.IsDir() != tt.isDir {
+			t.Errorf("Lookup(%q).IsDir() = %v, want %v", tt.path, node.IsDir(), tt.isDir)
+		}
+	}
+
+	if got := len(root.Lookup("Midnight Lions").Children); got != 1 {
+		t.Errorf("Expected linked backup to appear once, got %d entries", got)
+	}
+	if got := len(root.Lookup("Twins").Children); got != 2 {
+		t.Errorf("Expected both same-named NFTs to be listed, got %d", got)
+	}
+	if image := root.Lookup("Midnight Lions/Midnight Lion #01/media/image.png"); image != nil && image.Size != int64(len("png-bytes")) {
+		t.Errorf("Image size = %d, want %d", image.Size, len("png-bytes"))
+	}
+}
+
+func TestBuild_Encrypted(t *testing.T) {
+	vault, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	wallet := solanago.NewWallet().PublicKey()
+	lion := saveTestNFT(t, vault, wallet, "Midnight Lion #01", "Midnight Lions")
+	mediaDir := filepath.Join(vault.NFTDir(wallet, lion), "media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		t.Fatalf("Failed to create media dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "image.png"), []byte("png-bytes"), 0644); err != nil {
+		t.Fatalf("Failed to write media: %v", err)
+	}
+
+	params, c, err := crypt.FromPassphrase("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to set up encryption: %v", err)
+	}
+	if _, err := vault.EnableEncryption(params, c); err != nil {
+		t.Fatalf("Failed to enable encryption: %v", err)
+	}
+
+	root, err := Build(vault)
+	if err != nil {
+		t.Fatalf("Failed to build tree: %v", err)
+	}
+	image := root.Lookup("Midnight Lions/Midnight Lion #01/media/image.png")
+	if image == nil {
+		t.Fatalf("Encrypted NFT is missing from the tree")
+	}
+	if !image.Encoded() || image.Size != int64(len("png-bytes")) {
+		t.Errorf("Image encoded = %v, size = %d, want a decoded size of %d", image.Encoded(), image.Size, len("png-bytes"))
+	}
+	if data, err := image.ReadAll(); err != nil || string(data) != "png-bytes" {
+		t.Errorf("ReadAll() = %q (%v), want the original bytes", data, err)
+	}
+}
@@ -0,0 +1,381 @@
+// Package webarchive snapshots a web page and the assets it links to, so
+// the site behind an NFT's external_url can still be opened after it goes
+// offline.
+package webarchive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// IndexFile is the saved copy of the archived page
+const IndexFile = "index.html"
+
+// Limits bound how much of a site is archived
+type Limits struct {
+	MaxDepth int   // Links followed from the page; 0 saves the page and its assets only
+	MaxBytes int64 // Total size of every saved file
+	MaxFiles int   // Pages and assets saved
+}
+
+// DefaultLimits archive the page, its assets and the same-site pages it links to
+var DefaultLimits = Limits{MaxDepth: 1, MaxBytes: 50 << 20, MaxFiles: 200}
+
+// File is one saved page or asset
+type File struct {
+	URL         string `json:"url"`
+	Path        string `json:"path"` // Relative to the snapshot directory
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum"` // SHA-256 of the saved bytes, hex
+}
+
+// Snapshot records what was archived, for the NFT's backup record
+type Snapshot struct {
+	URL        string    `json:"url"`
+	Index      string    `json:"index"` // The page itself, relative to the snapshot directory
+	ArchivedAt time.Time `json:"archived_at"`
+	Files      []*File   `json:"files"`
+	Size       int64     `json:"size"`
+	Truncated  bool      `json:"truncated,omitempty"` // A limit stopped the crawl
+	Skipped    []string  `json:"skipped,omitempty"`   // URLs that failed to download
+}
+
+// Archiver downloads snapshots
+type Archiver struct {
+	client *http.Client
+	limits Limits
+}
+
+// New creates an Archiver with the given limits
+func New(limits Limits) *Archiver {
+	return &Archiver{
+		client: &http.Client{Timeout: 30 * time.Second},
+		limits: limits,
+	}
+}
+
+// resource is a URL queued for download
+type resource struct {
+	url   *url.URL
+	depth int
+	page  bool // Reached by a link rather than embedded
+	local string
+}
+
+// fetched is a downloaded page or stylesheet whose links still need
+// rewriting to their local copies
+type fetched struct {
+	resource *resource
+	body     []byte
+	css      bool
+	file     *File
+}
+
+// Snapshot archives pageURL into dir. Links in the saved HTML and CSS
+// point at the local copies, so index.html can be opened offline.
+//
+// Explanation: Pages and stylesheets are held in memory until the crawl
+// ends and only then written, so their links are rewritten only to the
+// assets that actually downloaded; anything else keeps its original URL.
+func (a *Archiver) Snapshot(ctx context.Context, pageURL, dir string) (*Snapshot, error) {
+	root, err := url.Parse(pageURL)
+	if err != nil || (root.Scheme != "http" && root.Scheme != "https") {
+		return nil, fmt.Errorf("cannot archive %q: only http and https URLs are supported", pageURL)
+	}
+	root.Fragment = ""
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	snapshot := &Snapshot{URL: root.String(), Index: IndexFile, ArchivedAt: time.Now()}
+	queued := map[string]*resource{root.String(): {url: root, page: true, local: IndexFile}}
+	queue := []*resource{queued[root.String()]}
+	saved := make(map[string]string) // URL to local file
+	var documents []*fetched
+
+	enqueue := func(from *resource, ref string, page bool) {
+		target, ok := resolve(from.url, ref)
+		if !ok || queued[target.String()] != nil {
+			return
+		}
+		depth := from.depth
+		if page {
+			// Only pages on the same site are followed, up to MaxDepth links away
+			if target.Host != root.Host || from.depth >= a.limits.MaxDepth {
+				return
+			}
+			depth++
+		}
+		if len(queued) >= a.limits.MaxFiles {
+			snapshot.Truncated = true
+			return
+		}
+		r := &resource{url: target, depth: depth, page: page, local: localName(target, page)}
+		queued[target.String()] = r
+		queue = append(queue, r)
+	}
+
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		r := queue[0]
+		queue = queue[1:]
+
+		doc, err := a.fetch(ctx, r, dir, snapshot.Size)
+		if err == errOverBudget && r.local != IndexFile {
+			snapshot.Truncated = true
+			continue
+		}
+		if err != nil {
+			if r.local == IndexFile {
+				return nil, err
+			}
+			snapshot.Skipped = append(snapshot.Skipped, r.url.String())
+			continue
+		}
+		snapshot.Size += doc.file.Size
+		snapshot.Files = append(snapshot.Files, doc.file)
+		saved[r.url.String()] = r.local
+
+		switch {
+		case doc.body == nil:
+			// An asset, already on disk
+		case doc.css:
+			rewriteCSS(doc.body, func(ref string) string {
+				enqueue(r, ref, false)
+				return ref
+			})
+			documents = append(documents, doc)
+		default:
+			rewriteHTML(doc.body, func(ref string, page bool) string {
+				enqueue(r, ref, page)
+				return ref
+			})
+			documents = append(documents, doc)
+		}
+	}
+
+	// Point links at the local copies now that every download is known
+	for _, doc := range documents {
+		local := func(ref string) string {
+			if target, ok := resolve(doc.resource.url, ref); ok {
+				if name, ok := saved[target.String()]; ok {
+					return name
+				}
+			}
+			return ref
+		}
+		var body []byte
+		if doc.css {
+			body = rewriteCSS(doc.body, local)
+		} else {
+			body = rewriteHTML(doc.body, func(ref string, page bool) string { return local(ref) })
+		}
+		if err := os.WriteFile(filepath.Join(dir, doc.resource.local), body, 0644); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", doc.resource.url, err)
+		}
+		doc.file.Size = int64(len(body))
+		doc.file.Checksum = fmt.Sprintf("%x", sha256.Sum256(body))
+	}
+
+	snapshot.Size = 0
+	for _, file := range snapshot.Files {
+		snapshot.Size += file.Size
+	}
+	return snapshot, nil
+}
+
+// errOverBudget means a download would exceed Limits.MaxBytes
+var errOverBudget = errors.New("archive size limit reached")
+
+// fetch downloads one resource. Assets are written straight to dir; HTML
+// and CSS are returned in memory for link rewriting.
+func (a *Archiver) fetch(ctx context.Context, r *resource, dir string, used int64) (*fetched, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", r.url.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "SolVault/1.0 NFT-Backup-Tool")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d downloading %s", resp.StatusCode, r.url)
+	}
+
+	budget := a.limits.MaxBytes - used
+	if resp.ContentLength > budget {
+		return nil, errOverBudget
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, budget+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", r.url, err)
+	}
+	if int64(len(body)) > budget {
+		return nil, errOverBudget
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	doc := &fetched{
+		resource: r,
+		css:      mediaType == "text/css",
+		file: &File{
+			URL:         r.url.String(),
+			Path:        r.local,
+			ContentType: contentType,
+			Size:        int64(len(body)),
+			Checksum:    fmt.Sprintf("%x", sha256.Sum256(body)),
+		},
+	}
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" || doc.css {
+		doc.body = body
+		return doc, nil
+	}
+	if r.local == IndexFile {
+		return nil, fmt.Errorf("%s is %s, not a web page", r.url, mediaType)
+	}
+	if err := os.WriteFile(filepath.Join(dir, r.local), body, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", r.url, err)
+	}
+	return doc, nil
+}
+
+// resolve turns a reference found in a document into an absolute http(s)
+// URL without its fragment
+func resolve(base *url.URL, ref string) (*url.URL, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	target, err := base.Parse(ref)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, false
+	}
+	target.Fragment = ""
+	return target, true
+}
+
+// localName names the saved copy of a URL: a hash of the URL, keeping its
+// extension so browsers can tell the type, and .html for pages without
+// one. Every file sits directly in the snapshot directory, so rewritten
+// links are plain file names.
+func localName(u *url.URL, page bool) string {
+	ext := strings.ToLower(path.Ext(u.Path))
+	if len(ext) > 6 || strings.ContainsAny(ext, "?&=%") {
+		ext = ""
+	}
+	if page && ext == "" {
+		ext = ".html"
+	}
+	sum := sha256.Sum256([]byte(u.String()))
+	return fmt.Sprintf("%x%s", sum[:8], ext)
+}
+
+// linkAttrs lists the attributes that reference other resources, and
+// whether following them leads to another page rather than an asset
+var linkAttrs = map[string]map[string]bool{
+	"a":      {"href": true},
+	"iframe": {"src": true},
+	"img":    {"src": false},
+	"script": {"src": false},
+	"link":   {"href": false},
+	"source": {"src": false},
+	"video":  {"src": false, "poster": false},
+	"audio":  {"src": false},
+	"embed":  {"src": false},
+}
+
+// rewriteHTML passes every link in an HTML document through rewrite and
+// returns the document with the results substituted. Everything else is
+// copied byte for byte.
+func rewriteHTML(body []byte, rewrite func(ref string, page bool) string) []byte {
+	var out bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return out.Bytes()
+		}
+		raw := z.Raw()
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.Write(raw)
+			continue
+		}
+		token := z.Token()
+		attrs, ok := linkAttrs[token.Data]
+		if !ok {
+			out.Write(raw)
+			continue
+		}
+		changed := false
+		for i, attr := range token.Attr {
+			page, ok := attrs[attr.Key]
+			if !ok || (token.Data == "link" && !assetLink(token)) {
+				continue
+			}
+			if value := rewrite(attr.Val, page); value != attr.Val {
+				token.Attr[i].Val = value
+				changed = true
+			}
+		}
+		if changed {
+			out.WriteString(token.String())
+		} else {
+			out.Write(raw)
+		}
+	}
+}
+
+// assetLink reports whether a <link> loads something the page needs
+// (stylesheets, icons, preloads) rather than pointing elsewhere
+func assetLink(token html.Token) bool {
+	for _, attr := range token.Attr {
+		if attr.Key == "rel" {
+			for _, rel := range strings.Fields(strings.ToLower(attr.Val)) {
+				switch rel {
+				case "stylesheet", "icon", "shortcut", "apple-touch-icon", "preload", "modulepreload", "manifest":
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+var cssURL = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)|@import\s+(['"])([^'"]+)(['"])`)
+
+// rewriteCSS passes every url(...) and @import in a stylesheet through
+// rewrite
+func rewriteCSS(body []byte, rewrite func(ref string) string) []byte {
+	return cssURL.ReplaceAllFunc(body, func(match []byte) []byte {
+		groups := cssURL.FindSubmatch(match)
+		if len(groups[2]) > 0 {
+			return []byte(fmt.Sprintf("url(%s%s%s)", groups[1], rewrite(string(groups[2])), groups[3]))
+		}
+		return []byte(fmt.Sprintf("@import %s%s%s", groups[4], rewrite(string(groups[5])), groups[6]))
+	})
+}
@@ -0,0 +1,131 @@
+package webarchive
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func siteServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	pages := map[string]struct{ contentType, body string }{
+		"/": {"text/html", `<html><head><link rel="stylesheet" href="/style.css"><link rel="canonical" href="/"></head>
+<body><img src="logo.png" alt="logo"><script src="/missing.js"></script>
+<a href="/about">About</a> <a href="https://elsewhere.example/">Elsewhere</a> <a href="#top">Top</a></body></html>`},
+		"/style.css": {"text/css", `body { background: url("bg.png") }`},
+		"/logo.png":  {"image/png", "PNGDATA"},
+		"/bg.png":    {"image/png", strings.Repeat("B", 100)},
+		"/about":     {"text/html", `<p>About <img src="/team.png"></p>`},
+		"/team.png":  {"image/png", "TEAM"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", page.contentType)
+		w.Write([]byte(page.body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSnapshot(t *testing.T) {
+	server := siteServer(t)
+
+	tests := []struct {
+		name          string
+		limits        Limits
+		wantFiles     []string // URL paths saved
+		wantTruncated bool
+	}{
+		{"default", DefaultLimits, []string{"/", "/style.css", "/logo.png", "/about", "/bg.png", "/team.png"}, false},
+		{"page only", Limits{MaxDepth: 0, MaxBytes: 1 << 20, MaxFiles: 100}, []string{"/", "/style.css", "/logo.png", "/bg.png"}, false},
+		{"byte limit", Limits{MaxDepth: 1, MaxBytes: 420, MaxFiles: 100}, []string{"/", "/style.css", "/logo.png", "/about", "/team.png"}, true},
+		{"file limit", Limits{MaxDepth: 1, MaxBytes: 1 << 20, MaxFiles: 2}, []string{"/", "/style.css"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			snapshot, err := New(tt.limits).Snapshot(context.Background(), server.URL+"/#intro", dir)
+			if err != nil {
+				t.Fatalf("Failed to archive site: %v", err)
+			}
+
+			got := make(map[string]*File)
+			for _, file := range snapshot.Files {
+				got[strings.TrimPrefix(file.URL, server.URL)] = file
+				if _, err := os.Stat(filepath.Join(dir, file.Path)); err != nil {
+					t.Errorf("%s recorded but not saved: %v", file.URL, err)
+				}
+			}
+			if len(got) != len(tt.wantFiles) {
+				t.Errorf("Saved %d files, want %d: %v", len(got), len(tt.wantFiles), snapshot.Files)
+			}
+			for _, want := range tt.wantFiles {
+				if got[want] == nil {
+					t.Errorf("%s was not archived", want)
+				}
+			}
+			if snapshot.Truncated != tt.wantTruncated {
+				t.Errorf("Truncated = %v, want %v", snapshot.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestSnapshotRewritesLinks(t *testing.T) {
+	server := siteServer(t)
+	dir := t.TempDir()
+	snapshot, err := New(DefaultLimits).Snapshot(context.Background(), server.URL+"/", dir)
+	if err != nil {
+		t.Fatalf("Failed to archive site: %v", err)
+	}
+	local := make(map[string]string)
+	for _, file := range snapshot.Files {
+		local[strings.TrimPrefix(file.URL, server.URL)] = file.Path
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("Failed to read archived page: %v", err)
+	}
+	for _, want := range []string{
+		`href="` + local["/style.css"] + `"`,
+		`src="` + local["/logo.png"] + `"`,
+		`href="` + local["/about"] + `"`,
+		`src="/missing.js"`,                 // Failed downloads keep their URL
+		`href="https://elsewhere.example/"`, // Other sites are not followed
+		`<link rel="canonical" href="/">`,   // Not an asset
+	} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Archived page lacks %s:\n%s", want, index)
+		}
+	}
+
+	css, err := os.ReadFile(filepath.Join(dir, local["/style.css"]))
+	if err != nil {
+		t.Fatalf("Failed to read archived stylesheet: %v", err)
+	}
+	if want := `url("` + local["/bg.png"] + `")`; !strings.Contains(string(css), want) {
+		t.Errorf("Stylesheet %q lacks %s", css, want)
+	}
+	if len(snapshot.Skipped) != 1 || !strings.HasSuffix(snapshot.Skipped[0], "/missing.js") {
+		t.Errorf("Skipped = %v, want the missing script", snapshot.Skipped)
+	}
+}
+
+func TestSnapshotRejects(t *testing.T) {
+	server := siteServer(t)
+	for _, pageURL := range []string{"ipfs://bafy/page", "not a url", server.URL + "/logo.png", server.URL + "/nowhere"} {
+		if _, err := New(DefaultLimits).Snapshot(context.Background(), pageURL, t.TempDir()); err == nil {
+			t.Errorf("Expected an error archiving %q", pageURL)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package solvault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/fetcher"
+	"github.com/NazWright/solvault/internal/solana"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// ClientOptions configures a Client
+type ClientOptions struct {
+	// RPCURLs are the Solana RPC endpoints, tried in order on failure
+	RPCURLs []string
+
+	// Wallets are the wallets this client backs up; at least one is required
+	Wallets []string
+
+	// Timeout bounds each RPC call (default 60s)
+	Timeout time.Duration
+
+	// MaxRetries is how often a failed RPC call is retried (default 3)
+	MaxRetries int
+}
+
+// Client reads NFTs from Solana: what a wallet holds, and each NFT's
+// metadata and media when a Vault backs it up
+type Client struct {
+	rpc     *solana.Client
+	source  *backup.ChainSource
+	wallets []solanago.PublicKey
+}
+
+// NewClient connects to the RPC endpoints in opts
+func NewClient(opts ClientOptions) (*Client, error) {
+	if len(opts.RPCURLs) == 0 {
+		return nil, fmt.Errorf("at least one RPC URL is required")
+	}
+	if len(opts.Wallets) == 0 {
+		return nil, fmt.Errorf("at least one wallet is required")
+	}
+	config := &solana.Config{
+		RPCURL:         opts.RPCURLs[0],
+		RPCURLs:        opts.RPCURLs,
+		PollInterval:   30 * time.Second,
+		MaxRetries:     3,
+		TimeoutSeconds: 60,
+	}
+	if opts.Timeout > 0 {
+		config.TimeoutSeconds = int(opts.Timeout.Seconds())
+	}
+	if opts.MaxRetries > 0 {
+		config.MaxRetries = opts.MaxRetries
+	}
+	for _, addr := range opts.Wallets {
+		wallet, err := parseAddress("wallet", addr)
+		if err != nil {
+			return nil, err
+		}
+		config.Wallets = append(config.Wallets, wallet)
+	}
+	config.WalletAddress = config.Wallets[0]
+	return newClient(config)
+}
+
+// NewClientFromEnv configures a client as the CLI does: from SOLANA_RPC_URL,
+// WALLET_ADDRESS and the other settings in the environment, ./.env and
+// ~/.solvault.env, including the HTTP options and metadata cross-check used
+// to fetch off-chain metadata and media
+func NewClientFromEnv() (*Client, error) {
+	solana.LoadEnvFiles()
+	config, err := solana.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	httpOpts, err := fetcher.HTTPOptionsFromEnv()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	client.source.SetHTTPOptions(httpOpts)
+	client.source.SetCrossCheck(fetcher.CrossCheckFromEnv())
+	return client, nil
+}
+
+func newClient(config *solana.Config) (*Client, error) {
+	rpc, err := solana.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solana client: %w", err)
+	}
+	return &Client{rpc: rpc, source: backup.NewChainSource(rpc), wallets: config.Wallets}, nil
+}
+
+// Wallets returns the wallets the client was configured with
+func (c *Client) Wallets() []string {
+	wallets := make([]string, len(c.wallets))
+	for i, wallet := range c.wallets {
+		wallets[i] = wallet.String()
+	}
+	return wallets
+}
+
+// Holdings lists the mints of the NFTs wallet holds right now
+func (c *Client) Holdings(ctx context.Context, wallet string) ([]string, error) {
+	owner, err := parseAddress("wallet", wallet)
+	if err != nil {
+		return nil, err
+	}
+	mints, err := c.source.ListMints(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	holdings := make([]string, len(mints))
+	for i, mint := range mints {
+		holdings[i] = mint.String()
+	}
+	return holdings, nil
+}
+
+// Close releases the client's connections
+func (c *Client) Close() error {
+	c.source.Close()
+	return c.rpc.Close()
+}
+
+// parseAddress reads a base58 Solana address
+func parseAddress(what, addr string) (solanago.PublicKey, error) {
+	key, err := solanago.PublicKeyFromBase58(addr)
+	if err != nil {
+		return solanago.PublicKey{}, fmt.Errorf("invalid %s %q: %w", what, addr, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,37 @@
+// Package solvault is the Go API of SolVault: back up a wallet's Solana
+// NFTs into a vault, look them up and verify the backups, from your own
+// program rather than the solvault CLI.
+//
+// A Client reads from Solana, a Vault holds the backups on disk and a
+// Verifier checks them against the integrity manifest written with every
+// backup:
+//
+//	client, err := solvault.NewClientFromEnv()
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	vault, err := solvault.OpenVault("/srv/solvault")
+//	if err != nil {
+//		return err
+//	}
+//	defer vault.Close()
+//
+//	summary, err := vault.Backup(ctx, client, wallet, solvault.BackupOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(summary.Counts)
+//
+//	verifier := solvault.NewVerifier(vault)
+//	err = verifier.VerifyAll(ctx, wallet, func(v *solvault.Verification) error {
+//		fmt.Println(v.NFT.Name, v.Status)
+//		return nil
+//	})
+//
+// The vault is the same directory the CLI uses, so both can work on it.
+// This package is the stable API: the packages under internal/ that it is
+// built on may change between releases, but its exported names will not
+// change within a major version.
+package solvault
@@ -0,0 +1,136 @@
+package solvault
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NazWright/solvault/internal/fetcher"
+	solanago "github.com/gagliardetto/solana-go"
+)
+
+// newTestVault opens a vault holding one NFT
+func newTestVault(t *testing.T) (*Vault, NFT) {
+	t.Helper()
+
+	vault, err := OpenVault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open vault: %v", err)
+	}
+	t.Cleanup(func() { vault.Close() })
+
+	info := &fetcher.NFTInfo{
+		MintAddress: solanago.NewWallet().PublicKey(),
+		Owner:       solanago.NewWallet().PublicKey(),
+		FetchedAt:   time.Now(),
+		Metadata:    &fetcher.NFTMetadata{Name: "Cool Cat #12", Symbol: "CAT"},
+	}
+	if err := vault.store.SaveNFT(context.Background(), info); err != nil {
+		t.Fatalf("Failed to save NFT: %v", err)
+	}
+	nft, err := vault.Get(info.Owner.String(), info.MintAddress.String())
+	if err != nil {
+		t.Fatalf("Failed to get NFT: %v", err)
+	}
+	return vault, *nft
+}
+
+func TestVault(t *testing.T) {
+	vault, nft := newTestVault(t)
+
+	if nft.Name != "Cool Cat #12" || nft.Symbol != "CAT" || nft.Dir == "" {
+		t.Errorf("Expected the saved NFT, got %+v", nft)
+	}
+	if nfts := vault.NFTs(""); len(nfts) != 1 || nfts[0].Mint != nft.Mint {
+		t.Errorf("Expected one NFT listed, got %+v", nfts)
+	}
+	if nfts := vault.NFTs(solanago.NewWallet().PublicKey().String()); len(nfts) != 0 {
+		t.Errorf("Expected no NFTs for another wallet, got %+v", nfts)
+	}
+	if found := vault.Find("cool cat 12", ""); len(found) != 1 || found[0].Mint != nft.Mint {
+		t.Errorf("Expected the NFT found by name, got %+v", found)
+	}
+
+	other := solanago.NewWallet().PublicKey().String()
+	if _, err := vault.Get(other, nft.Mint); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for another wallet, got %v", err)
+	}
+	if err := vault.Delete(context.Background(), other, nft.Mint); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting another wallet's NFT, got %v", err)
+	}
+	if err := vault.Delete(context.Background(), nft.Wallet, nft.Mint); err != nil {
+		t.Fatalf("Failed to delete NFT: %v", err)
+	}
+	if nfts := vault.NFTs(""); len(nfts) != 0 {
+		t.Errorf("Expected the vault empty after the delete, got %+v", nfts)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	vault, nft := newTestVault(t)
+	verifier := NewVerifier(vault)
+
+	result, err := verifier.Verify(nft)
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if result.Status != StatusAuthentic || len(result.Files) == 0 {
+		t.Fatalf("Expected a fresh backup authentic, got %+v", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(nft.Dir, filepath.FromSlash(result.Files[0].Path)), []byte("tampered"), 0644); err != nil {
+		t.Fatalf("Failed to tamper with backup: %v", err)
+	}
+	var results []*Verification
+	err = verifier.VerifyAll(context.Background(), "", func(v *Verification) error {
+		results = append(results, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to verify all: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusTampered {
+		t.Fatalf("Expected the tampered backup reported, got %+v", results)
+	}
+
+	// Without a manifest a backup is unverified until a baseline is taken
+	if err := os.Remove(filepath.Join(nft.Dir, "integrity.json")); err != nil {
+		t.Fatalf("Failed to remove manifest: %v", err)
+	}
+	if result, err := verifier.Verify(nft); err != nil || result.Status != StatusUnverified {
+		t.Fatalf("Expected the backup unverified, got %+v (err %v)", result, err)
+	}
+	verifier.Baseline = true
+	if result, err := verifier.Verify(nft); err != nil || result.Status != StatusAuthentic || !result.Baselined {
+		t.Errorf("Expected a baseline taken, got %+v (err %v)", result, err)
+	}
+}
+
+func TestNewClient_Validation(t *testing.T) {
+	wallet := solanago.NewWallet().PublicKey().String()
+	tests := []struct {
+		name string
+		opts ClientOptions
+	}{
+		{"no RPC URL", ClientOptions{Wallets: []string{wallet}}},
+		{"no wallet", ClientOptions{RPCURLs: []string{"https://api.devnet.solana.com"}}},
+		{"invalid wallet", ClientOptions{RPCURLs: []string{"https://api.devnet.solana.com"}, Wallets: []string{"nope"}}},
+	}
+	for _, test := range tests {
+		if _, err := NewClient(test.opts); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+
+	client, err := NewClient(ClientOptions{RPCURLs: []string{"https://api.devnet.solana.com"}, Wallets: []string{wallet}})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	if wallets := client.Wallets(); len(wallets) != 1 || wallets[0] != wallet {
+		t.Errorf("Expected the configured wallet, got %v", wallets)
+	}
+}
@@ -0,0 +1,214 @@
+package solvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NazWright/solvault/internal/backup"
+	"github.com/NazWright/solvault/internal/storage"
+	"github.com/NazWright/solvault/internal/thumbnail"
+)
+
+// ErrNotFound is returned for an NFT that is not in the vault
+var ErrNotFound = errors.New("NFT not found in vault")
+
+// Vault is a directory of NFT backups, the one 'solvault' keeps under
+// BACKUP_DIRECTORY
+type Vault struct {
+	store *storage.FileStorage
+}
+
+// OpenVault opens the vault in dir, creating it if it does not exist yet.
+// An encrypted vault is unlocked with SOLVAULT_PASSPHRASE or
+// SOLVAULT_KEY_FILE; a vault written by a newer SolVault is refused.
+func OpenVault(dir string) (*Vault, error) {
+	store, err := storage.NewFileStorage(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{store: store}, nil
+}
+
+// Dir returns the vault's directory
+func (v *Vault) Dir() string {
+	return v.store.BaseDir()
+}
+
+// Close releases the vault
+func (v *Vault) Close() error {
+	return v.store.Close()
+}
+
+// NFT is one backed-up NFT of one wallet
+type NFT struct {
+	Mint       string
+	Wallet     string
+	Name       string
+	Symbol     string
+	Collection string
+	Attributes map[string]string // Trait type to value
+
+	// Status is held, transferred, burned or escrowed; backups of NFTs that
+	// left the wallet are kept
+	Status string
+
+	UpdatedAt time.Time // When the backup was last written
+
+	// LinkedTo is the wallet whose backup this one shares, when another
+	// wallet held the same mint first
+	LinkedTo string
+
+	// InProgress marks an interrupted backup the next Backup redoes
+	InProgress bool
+
+	// Dir is the backup's directory inside the vault
+	Dir string
+}
+
+func (v *Vault) nft(entry *storage.IndexEntry) NFT {
+	return NFT{
+		Mint:       entry.Mint,
+		Wallet:     entry.Wallet,
+		Name:       entry.Name,
+		Symbol:     entry.Symbol,
+		Collection: entry.Collection,
+		Attributes: entry.Attributes,
+		Status:     string(entry.Status),
+		UpdatedAt:  entry.UpdatedAt,
+		LinkedTo:   entry.PrimaryWallet,
+		InProgress: entry.InProgress,
+		Dir:        v.store.EntryDir(entry),
+	}
+}
+
+// NFTs lists the vault's NFTs, sorted by wallet then name; an empty wallet
+// lists every wallet's
+func (v *Vault) NFTs(wallet string) []NFT {
+	var nfts []NFT
+	for _, entry := range v.store.Index().List() {
+		if wallet == "" || entry.Wallet == wallet {
+			nfts = append(nfts, v.nft(entry))
+		}
+	}
+	return nfts
+}
+
+// Find looks NFTs up by mint, name, symbol or collection the way 'solvault
+// info' does, tolerating case, punctuation and small typos. An empty wallet
+// searches every wallet.
+func (v *Vault) Find(query, wallet string) []NFT {
+	var nfts []NFT
+	for _, entry := range v.store.Index().Search(query, wallet) {
+		nfts = append(nfts, v.nft(entry))
+	}
+	return nfts
+}
+
+// Get returns one wallet's backup of a mint, or ErrNotFound
+func (v *Vault) Get(wallet, mint string) (*NFT, error) {
+	entry := v.store.Index().Get(wallet, mint)
+	if entry == nil {
+		return nil, fmt.Errorf("%w: %s in wallet %s", ErrNotFound, mint, wallet)
+	}
+	nft := v.nft(entry)
+	return &nft, nil
+}
+
+// Delete removes one wallet's backup of a mint. A backup other wallets are
+// linked to is only removed once their links are.
+func (v *Vault) Delete(ctx context.Context, wallet, mint string) error {
+	walletKey, err := parseAddress("wallet", wallet)
+	if err != nil {
+		return err
+	}
+	mintKey, err := parseAddress("mint", mint)
+	if err != nil {
+		return err
+	}
+	if v.store.Index().Get(wallet, mint) == nil {
+		return fmt.Errorf("%w: %s in wallet %s", ErrNotFound, mint, wallet)
+	}
+	return v.store.DeleteNFT(ctx, walletKey, mintKey)
+}
+
+// BackupOptions controls a Backup
+type BackupOptions struct {
+	DryRun    bool // Report what would change without writing anything
+	NewOnly   bool // Skip NFTs already backed up
+	Fungibles bool // Also back up fungible tokens and SFTs under tokens/
+
+	// NoThumbnails skips the JPEG previews made for images and videos
+	NoThumbnails bool
+
+	// Progress receives one line per step, e.g. for a log (optional)
+	Progress func(msg string)
+
+	// Advance is called after each NFT is checked (optional)
+	Advance func(done, total int)
+}
+
+// Change is what a Backup did with one NFT
+type Change struct {
+	Mint  string
+	Name  string
+	Kind  string // added, updated, unchanged, returned, resumed, transferred, burned, escrowed or failed
+	Error string // Why a failed change failed
+}
+
+// BackupSummary reports the outcome of backing up one wallet
+type BackupSummary struct {
+	Wallet     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	DryRun     bool
+	Partial    bool           // The listing was incomplete, so departures were not checked
+	Counts     map[string]int // Changes by kind
+	Changes    []Change
+	MerkleRoot string // Root over the wallet's whole backup after the run
+}
+
+// Backup brings the vault's copy of wallet up to date with what it holds
+// on chain, like 'solvault sync': new NFTs are backed up, changed ones
+// updated and ones that left the wallet marked, never deleted. It is safe
+// to run repeatedly.
+func (v *Vault) Backup(ctx context.Context, client *Client, wallet string, opts BackupOptions) (*BackupSummary, error) {
+	owner, err := parseAddress("wallet", wallet)
+	if err != nil {
+		return nil, err
+	}
+	syncOpts := backup.Options{
+		DryRun:    opts.DryRun,
+		NewOnly:   opts.NewOnly,
+		Fungibles: opts.Fungibles,
+		Progress:  opts.Progress,
+		Advance:   opts.Advance,
+	}
+	if !opts.NoThumbnails {
+		syncOpts.Thumbnails = thumbnail.DefaultSizes
+	}
+
+	summary, err := backup.Sync(ctx, v.store, client.source, owner, syncOpts)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackupSummary{
+		Wallet:     summary.Wallet,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+		DryRun:     summary.DryRun,
+		Partial:    summary.Partial,
+		Counts:     summary.Counts,
+		MerkleRoot: summary.MerkleRoot,
+	}
+	for _, change := range summary.Changes {
+		result.Changes = append(result.Changes, Change{
+			Mint:  change.Mint,
+			Name:  change.Name,
+			Kind:  string(change.Kind),
+			Error: change.Error,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package solvault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Status is the outcome of verifying one backup
+type Status string
+
+const (
+	StatusAuthentic  Status = "authentic"  // Every recorded file is unchanged
+	StatusTampered   Status = "tampered"   // A recorded file was modified or is missing
+	StatusUnverified Status = "unverified" // The backup has no integrity manifest to check against
+)
+
+// FileCheck is the result of rehashing one file of a backup
+type FileCheck struct {
+	Path     string // Relative to the backup's directory, with forward slashes
+	Status   string // ok, modified, missing or untracked (not in the manifest)
+	Expected string // SHA-256 recorded at backup time
+	Actual   string // SHA-256 now
+}
+
+// Verification is the result of verifying one backup
+type Verification struct {
+	NFT        NFT
+	Status     Status
+	Files      []FileCheck
+	VerifiedAt time.Time
+
+	// Baselined is set when this verification wrote the backup's first
+	// integrity manifest
+	Baselined bool
+}
+
+// Verifier checks backups against the integrity manifest (integrity.json)
+// written with each one, rehashing every file recorded in it
+type Verifier struct {
+	vault *Vault
+
+	// Baseline writes a manifest from the files as they are now for backups
+	// that predate manifests, instead of reporting them unverified. Backups
+	// that still carry the legacy hash.txt are left to 'solvault verify',
+	// which checks the image against it first.
+	Baseline bool
+}
+
+// NewVerifier creates a read-only verifier for vault
+func NewVerifier(vault *Vault) *Verifier {
+	return &Verifier{vault: vault}
+}
+
+// Verify rehashes one backup's files
+func (v *Verifier) Verify(nft NFT) (*Verification, error) {
+	result := &Verification{NFT: nft, VerifiedAt: time.Now()}
+	store := v.vault.store
+
+	report, err := store.CheckIntegrity(nft.Dir)
+	if errors.Is(err, os.ErrNotExist) && v.Baseline && !fileExists(filepath.Join(nft.Dir, "hash.txt")) {
+		if _, err := store.WriteIntegrity(nft.Dir); err != nil {
+			return nil, fmt.Errorf("failed to write integrity manifest for %s: %w", nft.Mint, err)
+		}
+		result.Baselined = true
+		report, err = store.CheckIntegrity(nft.Dir)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		result.Status = StatusUnverified
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", nft.Mint, err)
+	}
+
+	result.Status = StatusTampered
+	if report.OK() {
+		result.Status = StatusAuthentic
+	}
+	for _, file := range report.Files {
+		result.Files = append(result.Files, FileCheck{
+			Path:     file.Path,
+			Status:   string(file.Status),
+			Expected: file.Expected,
+			Actual:   file.Actual,
+		})
+	}
+	return result, nil
+}
+
+// VerifyAll verifies every backup of wallet, or of every wallet when it is
+// empty, calling fn with each result. An error from fn stops the run and is
+// returned.
+func (v *Verifier) VerifyAll(ctx context.Context, wallet string, fn func(*Verification) error) error {
+	for _, nft := range v.vault.NFTs(wallet) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := v.Verify(nft)
+		if err != nil {
+			return err
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}